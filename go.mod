@@ -2,4 +2,10 @@ module github.com/jamiefdhurst/journal
 
 go 1.16
 
-require github.com/mattn/go-sqlite3 v1.14.6
+require (
+	github.com/fsnotify/fsnotify v1.5.4
+	github.com/go-sql-driver/mysql v1.7.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.6
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
+)
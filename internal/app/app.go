@@ -1,11 +1,32 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/jamiefdhurst/journal/pkg/assets"
+	"github.com/jamiefdhurst/journal/pkg/configfile"
+	"github.com/jamiefdhurst/journal/pkg/database"
 	"github.com/jamiefdhurst/journal/pkg/database/rows"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	"github.com/jamiefdhurst/journal/web"
 )
 
 // Database Define same interface as database
@@ -21,60 +42,1109 @@ type GiphyAdapter interface {
 	SearchForID(s string) (string, error)
 }
 
+// Sealer Interface for encrypting values at rest and decrypting them again
+type Sealer interface {
+	Open(value string) (string, error)
+	Seal(value string) string
+}
+
+// Store Interface for a backend holding the journal's entries in place of the database
+type Store interface {
+	Name() string
+}
+
+// TenantOpener Interface for opening the isolated journal of a hosted tenant, preparing its database on first use
+type TenantOpener interface {
+	OpenTenant(name string, title string) (*Container, error)
+}
+
 // Container Define the main container for the application
 type Container struct {
+	BasePath      string
 	Configuration Configuration
+	CSRFToken     string
 	Db            Database
 	Giphy         GiphyAdapter
+	Locale        string
+	Logger        *logging.Logger
+	Maintenance   *Maintenance
+	Queue         Database
+	Replica       Database
+	Sealer        Sealer
+	Settings      *Settings
+	SetupCode     string
+	Site          SiteData
+	Store         Store
+	TemplateCache *TemplateCache
+	Tenant        string
+	TenantOpener  TenantOpener
 	Version       string
 }
 
+// Maintenance Whether the journal is in maintenance, refusing changes while it is backed up or migrated. Copies of a
+// container share it, so that it can be switched on and off while the journal runs.
+type Maintenance struct {
+	on int32
+}
+
+// NewMaintenance Create the switch, starting in maintenance when asked to
+func NewMaintenance(on bool) *Maintenance {
+	m := &Maintenance{}
+	m.Switch(on)
+
+	return m
+}
+
+// On Check whether the journal is in maintenance, never being so without a switch
+func (m *Maintenance) On() bool {
+	if m == nil {
+		return false
+	}
+
+	return atomic.LoadInt32(&m.on) == 1
+}
+
+// Switch Put the journal into maintenance, or take it out
+func (m *Maintenance) Switch(on bool) {
+	value := int32(0)
+	if on {
+		value = 1
+	}
+	atomic.StoreInt32(&m.on, value)
+}
+
+// Toggle Switch maintenance the other way, giving whether the journal is now in it
+func (m *Maintenance) Toggle() bool {
+	for {
+		old := atomic.LoadInt32(&m.on)
+		if atomic.CompareAndSwapInt32(&m.on, old, 1-old) {
+			return old == 0
+		}
+	}
+}
+
+// Names of settings saved in the database, being those of the env variables they take the place of without their J_
+// prefix, as in configuration files
+const (
+	SettingArticlesPerPage = "articles_per_page"
+	SettingDescription     = "description"
+	SettingFeedEntries     = "feed_entries"
+	SettingFeedSummaries   = "feed_summaries"
+	SettingLanguage        = "language"
+	SettingTheme           = "theme"
+	SettingTitle           = "title"
+	SettingURL             = "url"
+)
+
+// EditableSettings Settings that can be saved in the database and changed while the journal runs, in the order they
+// are shown
+var EditableSettings = []string{SettingTitle, SettingDescription, SettingURL, SettingArticlesPerPage, SettingTheme, SettingLanguage, SettingFeedEntries, SettingFeedSummaries}
+
+// Settings Settings saved in the journal's database, such as the title chosen while setting it up, taking the place of
+// those configured. Copies of a container share them, so that a setting saved while serving one request is seen by
+// every request after it.
+type Settings struct {
+	mutex  sync.RWMutex
+	values map[string]string
+}
+
+// NewSettings Hold settings read from the database, by their names
+func NewSettings(values map[string]string) *Settings {
+	s := &Settings{values: map[string]string{}}
+	for name, value := range values {
+		s.values[name] = value
+	}
+
+	return s
+}
+
+// Get Get a setting by its name, or nothing when it has not been saved
+func (s *Settings) Get(name string) string {
+	if s == nil {
+		return ""
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.values[name]
+}
+
+// Set Keep a setting once it has been saved, or forget it once removed when empty
+func (s *Settings) Set(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if value == "" {
+		delete(s.values, name)
+		return
+	}
+	s.values[name] = value
+}
+
+// Apply Get a configuration with the settings saved taking the place of those configured, read as they would be from
+// the env and leaving alone any that are empty or not valid
+func (s *Settings) Apply(configuration Configuration) Configuration {
+	if s == nil {
+		return configuration
+	}
+	named := map[string]interface{}{}
+	s.mutex.RLock()
+	for _, name := range EditableSettings {
+		if value, ok := s.values[name]; ok {
+			named["J_"+strings.ToUpper(name)] = value
+		}
+	}
+	s.mutex.RUnlock()
+	if len(named) > 0 {
+		ApplySettings(&configuration, named)
+	}
+
+	return configuration
+}
+
+// CheckSettings Check settings can be saved in place of those configured, giving any problem they would cause. Empty
+// settings are removed rather than saved, so are never a problem.
+func (c Configuration) CheckSettings(values map[string]string) []error {
+	problems := []error{}
+	for _, name := range []string{SettingArticlesPerPage, SettingFeedEntries} {
+		if value := values[name]; value != "" {
+			if number, err := strconv.Atoi(value); err != nil || number < 1 {
+				problems = append(problems, fmt.Errorf("J_%s must be a number of at least 1, not '%s'", strings.ToUpper(name), value))
+			}
+		}
+	}
+	if value := values[SettingFeedSummaries]; value != "" && value != "0" && value != "1" {
+		problems = append(problems, fmt.Errorf("J_FEED_SUMMARIES must be 1 or 0, not '%s'", value))
+	}
+
+	// Anything else they break is found by validating the configuration they make, ignoring what was already broken
+	known := map[string]bool{}
+	for _, problem := range c.Validate() {
+		known[problem.Error()] = true
+	}
+	for _, problem := range NewSettings(values).Apply(c).Validate() {
+		if !known[problem.Error()] {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// URL Build the absolute URL of a path within the journal being served, or an empty string when no site URL is configured
+func (c *Container) URL(path string) string {
+	if c.Configuration.URL == "" {
+		return ""
+	}
+	site, err := url.Parse(c.Configuration.URL)
+	if err != nil {
+		return ""
+	}
+	if c.Tenant != "" && c.Configuration.TenantMode == TenantModeSubdomain {
+		site.Host = c.Tenant + "." + c.Configuration.TenantDomain
+	}
+
+	return strings.TrimSuffix(site.String(), "/") + c.BasePath + path
+}
+
+// CleanBasePath Tidy a path prefix to serve the journal beneath into the form kept in a container, e.g. /journal from
+// journal/, or an empty string to serve it from the root
+func CleanBasePath(prefix string) string {
+	prefix = path.Clean("/" + prefix)
+	if prefix == "/" {
+		return ""
+	}
+
+	return prefix
+}
+
+// CSRFField Name of the form field carrying the token that shows a form was posted from the journal's own pages
+const CSRFField = "csrf_token"
+
+// CSRFInput Hidden field for templates to add to each form that changes anything, carrying the request's token
+func (c *Container) CSRFInput() template.HTML {
+	return template.HTML(`<input type="hidden" name="` + CSRFField + `" value="` + template.HTMLEscapeString(c.CSRFToken) + `" />`)
+}
+
+// CSRFQuery Query for templates to add to the action of forms uploading files, carrying the request's token
+func (c *Container) CSRFQuery() template.URL {
+	return template.URL(CSRFField + "=" + url.QueryEscape(c.CSRFToken))
+}
+
+// Templates Parse templates of pages by their paths within web/templates, such as _layout/default.tmpl, from the files
+// built into the binary or any overriding them, able to call the functions of TemplateFuncs. Pages are taken from the
+// TemplateCache once parsed, when the container has one.
+func (c *Container) Templates(names ...string) (*template.Template, error) {
+	if c != nil && c.TemplateCache != nil {
+		return c.TemplateCache.Get(c, names...)
+	}
+
+	return parseTemplates(c.templateFiles(), c.TemplateFuncs(), names)
+}
+
+// Log Get the logger for what the container is used for, such as one carrying the ID of the request being served
+func (c *Container) Log() *logging.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+
+	return logging.Default()
+}
+
+// WithContext Copy the container for serving a request, running its statements under the request's context so they
+// stop when it is cancelled, each given no longer than the configured timeout, logging with the request's logger and
+// using any settings saved in place of those configured
+func (c *Container) WithContext(ctx context.Context) *Container {
+	bound := *c
+	bound.Configuration = c.Settings.Apply(c.Configuration)
+	bound.Logger = logging.FromContext(ctx)
+	timeout := time.Duration(c.Configuration.DatabaseTimeout) * time.Second
+	if c.Db != nil {
+		bound.Db = database.WithContext(c.Db, ctx, timeout)
+	}
+	if c.Queue != nil {
+		bound.Queue = database.WithContext(c.Queue, ctx, timeout)
+	}
+	if c.Replica != nil {
+		bound.Replica = database.WithContext(c.Replica, ctx, timeout)
+	}
+
+	return &bound
+}
+
+// Transaction Run fn with a copy of the container whose statements are made together in a transaction, kept when fn
+// returns nothing and rolled back when it fails
+func (c *Container) Transaction(fn func(*Container) error) error {
+	return database.Transaction(c.Db, func(db database.Database) error {
+		tx := *c
+		tx.Db = db
+
+		return fn(&tx)
+	})
+}
+
+// ReadOnly Copy the container for serving a request that only reads, whose rows are read from the replica when one is
+// configured while any change is still made to the database
+func (c *Container) ReadOnly() *Container {
+	replicated := *c
+	if c.Db != nil && c.Replica != nil {
+		replicated.Db = database.WithReplica(c.Db, c.Replica)
+	}
+
+	return &replicated
+}
+
+// MediaPath Directory holding the files uploaded to the journal being served, kept apart for each hosted journal
+func (c *Container) MediaPath() string {
+	if c.Tenant != "" {
+		return filepath.Join(c.Configuration.MediaPath, c.Tenant)
+	}
+
+	return c.Configuration.MediaPath
+}
+
+// Tenant modes, deciding how each hosted journal is found from a request
+const (
+	TenantModeSubdomain = "subdomain"
+	TenantModePath      = "path"
+)
+
 // Configuration can be modified through environment variables
 type Configuration struct {
-	ArticlesPerPage int
-	DatabasePath    string
-	EnableCreate    bool
-	EnableEdit      bool
-	Port            string
-	Title           string
+	AccessLog                      string
+	AccessLogFormat                string
+	ActivityPubUser                string
+	AdminToken                     string
+	ArticlesPerPage                int
+	AttachmentLimit                int
+	BackupInterval                 int
+	BackupKeep                     int
+	BackupPath                     string
+	BasePath                       string
+	BlogrollInterval               int
+	CanonicalRedirect              bool
+	ContentSecurityPolicy          string
+	DatabaseBusyTimeout            int
+	DatabaseForeignKeys            bool
+	DatabaseJournalMode            string
+	DatabaseMaxIdle                int
+	DatabaseMaxLifetime            int
+	DatabaseMaxOpen                int
+	DatabasePath                   string
+	DatabaseRetries                int
+	DatabaseSynchronous            string
+	DatabaseTimeout                int
+	Description                    string
+	Dev                            bool
+	EnableCreate                   bool
+	EnableEdit                     bool
+	EntriesPath                    string
+	FeedEntries                    int
+	FeedSummaries                  bool
+	GiphyAPIKey                    string
+	HSTSMaxAge                     int
+	IndexNowEndpoint               string
+	IndexNowKey                    string
+	IndieAuthAuthorizationEndpoint string
+	IndieAuthTokenEndpoint         string
+	Language                       string
+	LockoutAttempts                int
+	LockoutMinutes                 int
+	LogFile                        string
+	LogFormat                      string
+	LogKeep                        int
+	LogLevel                       string
+	LogMaxSize                     int
+	LogRotateInterval              int
+	MailFrom                       string
+	MailPort                       string
+	Maintenance                    bool
+	MediaPath                      string
+	OIDCClientID                   string
+	OIDCClientSecret               string
+	OIDCProvider                   string
+	Passphrase                     string
+	Port                           string
+	RateLimit                      int
+	RateLimitLogin                 int
+	RobotsPath                     string
+	ShutdownTimeout                int
+	Socket                         string
+	SocketMode                     string
+	SpamAPIEndpoint                string
+	SpamAPIKey                     string
+	StaticMaxAge                   int
+	TelegramChatID                 string
+	TelegramEndpoint               string
+	TelegramToken                  string
+	TenantDomain                   string
+	TenantMaxEntries               int
+	TenantMode                     string
+	TenantPath                     string
+	Theme                          string
+	Title                          string
+	TLS                            bool
+	TLSCache                       string
+	TLSCert                        string
+	TLSDomain                      string
+	TLSEmail                       string
+	TLSHTTPPort                    string
+	TLSKey                         string
+	TrashRetention                 int
+	TrustedProxies                 string
+	URL                            string
+	WebPath                        string
+	WebSubHub                      string
+	Workers                        int
+}
+
+// DatabasePool Settings for the connections kept open to each database
+func (c Configuration) DatabasePool() database.Pool {
+	return database.Pool{
+		BusyTimeout:     time.Duration(c.DatabaseBusyTimeout) * time.Millisecond,
+		ConnMaxLifetime: time.Duration(c.DatabaseMaxLifetime) * time.Second,
+		MaxIdleConns:    c.DatabaseMaxIdle,
+		MaxOpenConns:    c.DatabaseMaxOpen,
+		Retries:         c.DatabaseRetries,
+	}
+}
+
+// SqlitePragmas Pragmas set on each connection to an SQLite database
+func (c Configuration) SqlitePragmas() database.Pragmas {
+	return database.Pragmas{
+		ForeignKeys: c.DatabaseForeignKeys,
+		JournalMode: c.DatabaseJournalMode,
+		Synchronous: c.DatabaseSynchronous,
+	}
+}
+
+// SqliteReplicaPragmas Pragmas set on each connection to a replica of an SQLite database, which refuses writes and
+// leaves its journal as it is kept by whatever replicates it
+func (c Configuration) SqliteReplicaPragmas() database.Pragmas {
+	return database.Pragmas{
+		ForeignKeys: c.DatabaseForeignKeys,
+		QueryOnly:   true,
+	}
+}
+
+// Files Templates and static files, being those built into the binary with any in the directory at J_WEB_PATH, laid
+// out like web/, taking the place of the ones at the same paths, and those of the theme at themes/ in turn taking the
+// place of both
+func (c Configuration) Files() fs.FS {
+	var files fs.FS = web.Files
+	if c.WebPath != "" {
+		files = assets.Overlay{Dir: c.WebPath, Base: files}
+	}
+	if c.Theme == "" || !themeName.MatchString(c.Theme) {
+		return files
+	}
+	if info, err := fs.Stat(files, "themes/"+c.Theme); err == nil && info.IsDir() {
+		theme, _ := fs.Sub(files, "themes/"+c.Theme)
+		files = assets.Overlay{Top: theme, Base: files}
+	}
+
+	return files
+}
+
+// Themes Names of the themes that can be chosen, in order, being the one built in, each bundle of templates and static
+// files in themes/ and each stylesheet in static/css
+func (c Configuration) Themes() []string {
+	c.Theme = ""
+	files := c.Files()
+	found := map[string]bool{"default": true}
+	entries, _ := fs.ReadDir(files, "themes")
+	for _, entry := range entries {
+		if entry.IsDir() && themeName.MatchString(entry.Name()) {
+			found[entry.Name()] = true
+		}
+	}
+	names, _ := fs.Glob(files, "static/css/*.min.css")
+	for _, name := range names {
+		found[strings.TrimSuffix(path.Base(name), ".min.css")] = true
+	}
+	themes := []string{}
+	for theme := range found {
+		themes = append(themes, theme)
+	}
+	sort.Strings(themes)
+
+	return themes
+}
+
+// Stylesheet Path of the stylesheet pages are styled with, being the one named after the theme in its static/css or
+// the one built in when it has none
+func (c Configuration) Stylesheet() string {
+	if _, err := fs.Stat(c.Files(), "static/css/"+c.Theme+".min.css"); c.Theme != "" && err == nil {
+		return "static/css/" + c.Theme + ".min.css"
+	}
+
+	return "static/css/default.min.css"
+}
+
+// MissingTemplates Templates the theme leaves out, which pages are rendered with the ones built in for instead
+func (c Configuration) MissingTemplates() []string {
+	missing := []string{}
+	if c.Theme == "" || c.Theme == "default" || !themeName.MatchString(c.Theme) {
+		return missing
+	}
+	base := c
+	base.Theme = ""
+	theme, err := fs.Sub(base.Files(), "themes/"+c.Theme)
+	if _, statErr := fs.Stat(theme, "."); err != nil || statErr != nil {
+		return missing
+	}
+	for _, name := range web.Templates {
+		if _, err := fs.Stat(theme, "templates/"+name); err != nil {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+// hasTheme Check the theme can be found, as a bundle in themes/ or a stylesheet in static/css
+func (c Configuration) hasTheme() bool {
+	for _, theme := range c.Themes() {
+		if theme == c.Theme {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OpenLog Open a log file to write to, rotated once it reaches J_LOG_MAX_SIZE megabytes or each J_LOG_ROTATE_INTERVAL
+// hours, keeping the newest J_LOG_KEEP rotated
+func (c Configuration) OpenLog(path string) (*logging.File, error) {
+	return logging.OpenFile(path, int64(c.LogMaxSize)*1024*1024, time.Duration(c.LogRotateInterval)*time.Hour, c.LogKeep)
+}
+
+// SocketFileMode Permissions given to the unix domain socket served on, such as 0660 to let a proxy in the same group
+// connect to it
+func (c Configuration) SocketFileMode() os.FileMode {
+	mode, _ := strconv.ParseUint(c.SocketMode, 8, 32)
+
+	return os.FileMode(mode) & os.ModePerm
+}
+
+// TLSDomains Hosts to obtain certificates for when serving over TLS
+func (c Configuration) TLSDomains() []string {
+	domains := []string{}
+	for _, domain := range strings.Split(c.TLSDomain, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, strings.ToLower(domain))
+		}
+	}
+
+	return domains
+}
+
+// maxSocketPath Longest path a unix domain socket can be given on every system, most allowing a few more characters
+const maxSocketPath = 103
+
+// hostName Host names certificates may be obtained for
+var hostName = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// themeName Names a theme may be given, being that of its directory in web/themes or its stylesheet in web/static/css
+// without .min.css
+var themeName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Validate Check that the settings can be used, and used together, giving back each problem found
+func (c Configuration) Validate() []error {
+	problems := []error{}
+	invalid := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	ports := []struct{ name, value string }{{"J_PORT", c.Port}, {"J_MAIL_PORT", c.MailPort}}
+	if c.TLS && c.TLSHTTPPort != "0" {
+		ports = append(ports, struct{ name, value string }{"J_TLS_HTTP_PORT", c.TLSHTTPPort})
+	}
+	for _, setting := range ports {
+		port, err := strconv.Atoi(setting.value)
+		if (setting.value != "" || setting.name == "J_PORT") && (err != nil || port < 1 || port > 65535) {
+			invalid("%s must be a port number from 1 to 65535, not '%s'", setting.name, setting.value)
+		}
+	}
+	if mode, err := strconv.ParseUint(c.SocketMode, 8, 32); err != nil || mode > 0777 {
+		invalid("J_SOCKET_MODE must be permissions in octal such as 0660, not '%s'", c.SocketMode)
+	}
+	if len(c.Socket) > maxSocketPath {
+		invalid("J_SOCKET must be a path of at most %d characters, not '%s'", maxSocketPath, c.Socket)
+	}
+	if c.URL != "" {
+		site, err := url.Parse(c.URL)
+		if err != nil || (site.Scheme != "http" && site.Scheme != "https") || site.Host == "" || site.RawQuery != "" || site.Fragment != "" {
+			invalid("J_URL must be an http or https address such as https://journal.example.com, not '%s'", c.URL)
+		} else if c.BasePath != "" && strings.TrimSuffix(site.Path, "/") == c.BasePath {
+			invalid("J_URL must leave out the base path %s, which is added to it", c.BasePath)
+		}
+	}
+	for _, setting := range []struct{ name, value string }{
+		{"J_ACTIVITYPUB_USER", c.ActivityPubUser},
+		{"J_INDEXNOW_KEY", c.IndexNowKey},
+		{"J_INDIEAUTH_TOKEN_ENDPOINT", c.IndieAuthTokenEndpoint},
+		{"J_WEBSUB_HUB", c.WebSubHub},
+	} {
+		if setting.value != "" && c.URL == "" {
+			invalid("%s requires J_URL to be set", setting.name)
+		}
+	}
+	if _, ok := Languages[c.Language]; c.Language != "" && !ok {
+		invalid("J_LANGUAGE must be one of %s, or empty to follow each reader's browser, not '%s'", strings.Join(Languages.Locales(), ", "), c.Language)
+	}
+	if c.LogFormat != logging.FormatText && c.LogFormat != logging.FormatJSON {
+		invalid("J_LOG_FORMAT must be text or json, not '%s'", c.LogFormat)
+	}
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		invalid("J_LOG_LEVEL must be debug, info, warn or error, not '%s'", c.LogLevel)
+	}
+	if c.AccessLogFormat != "" && c.AccessLogFormat != logging.FormatText && c.AccessLogFormat != logging.FormatJSON &&
+		c.AccessLogFormat != logging.FormatCombined {
+		invalid("J_ACCESS_LOG_FORMAT must be text, json or combined, not '%s'", c.AccessLogFormat)
+	}
+	if c.EntriesPath != "" && c.Passphrase != "" {
+		invalid("Entries cannot be kept as files with J_ENTRIES_PATH, which hold them as plain text, while J_PASSPHRASE encrypts them")
+	}
+	if c.OIDCClientID != "" && c.OIDCProvider == "" {
+		invalid("J_OIDC_CLIENT_ID requires J_OIDC_PROVIDER to be set")
+	}
+	if c.OIDCProvider != "" && c.OIDCProvider != "google" && c.OIDCProvider != "github" && !strings.HasPrefix(c.OIDCProvider, "https://") {
+		invalid("J_OIDC_PROVIDER must be google, github or the https address of an issuer, not '%s'", c.OIDCProvider)
+	}
+	if c.TenantMode == TenantModeSubdomain && c.TenantDomain == "" {
+		invalid("J_TENANT_DOMAIN must be set to host journals on its subdomains")
+	}
+	for _, entry := range strings.Split(c.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if _, _, err := net.ParseCIDR(entry); entry != "" && err != nil && net.ParseIP(entry) == nil {
+			invalid("J_TRUSTED_PROXIES must list addresses or ranges such as 10.0.0.0/8, not '%s'", entry)
+		}
+	}
+	if c.TLS {
+		switch {
+		case c.TLSCert != "" || c.TLSKey != "":
+			if c.TLSCert == "" || c.TLSKey == "" {
+				invalid("J_TLS_CERT and J_TLS_KEY must be set together")
+			} else if c.TLSDomain != "" {
+				invalid("Certificates can be read from J_TLS_CERT and J_TLS_KEY or obtained for J_TLS_DOMAIN, not both")
+			} else if _, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey); err != nil {
+				invalid("J_TLS_CERT and J_TLS_KEY could not be read: %s", err)
+			}
+		case c.TLSDomain == "":
+			invalid("J_TLS requires J_TLS_DOMAIN, or J_TLS_CERT and J_TLS_KEY, to be set")
+		}
+		for _, domain := range c.TLSDomains() {
+			if !hostName.MatchString(domain) {
+				invalid("J_TLS_DOMAIN must list host names such as journal.example.com, not '%s'", domain)
+			}
+		}
+		if c.TLSHTTPPort == c.Port {
+			invalid("J_TLS_HTTP_PORT must differ from J_PORT, which serves HTTPS")
+		}
+		if strings.HasPrefix(c.URL, "http://") {
+			invalid("J_URL must be an https address when serving over TLS, not '%s'", c.URL)
+		}
+	} else if c.TLSDomain != "" || c.TLSCert != "" || c.TLSKey != "" {
+		invalid("J_TLS_DOMAIN, J_TLS_CERT and J_TLS_KEY are only used once J_TLS is set")
+	}
+	if info, err := os.Stat(c.WebPath); c.WebPath != "" && (err != nil || !info.IsDir()) {
+		invalid("J_WEB_PATH must be a directory of templates and static files laid out like web/, not '%s'", c.WebPath)
+	}
+	if !themeName.MatchString(c.Theme) {
+		invalid("J_THEME must be the name of a theme in web/themes or a stylesheet in web/static/css, not '%s'", c.Theme)
+	} else if !c.hasTheme() {
+		invalid("J_THEME %s has no theme at web/themes/%s or stylesheet at web/static/css/%s.min.css", c.Theme, c.Theme, c.Theme)
+	}
+
+	return problems
 }
 
 // DefaultConfiguration returns the default settings for the app
 func DefaultConfiguration() Configuration {
 	return Configuration{
-		ArticlesPerPage: 20,
-		DatabasePath:    os.Getenv("GOPATH") + "/data/journal.db",
-		EnableCreate:    true,
-		EnableEdit:      true,
-		Port:            "3000",
-		Title:           "Jamie's Journal",
+		ArticlesPerPage:     20,
+		AttachmentLimit:     20,
+		BackupInterval:      24,
+		BackupKeep:          7,
+		BlogrollInterval:    60,
+		DatabaseBusyTimeout: 5000,
+		DatabaseForeignKeys: true,
+		DatabaseJournalMode: "WAL",
+		DatabaseMaxIdle:     5,
+		DatabaseMaxLifetime: 1800,
+		DatabaseMaxOpen:     10,
+		DatabasePath:        os.Getenv("GOPATH") + "/data/journal.db",
+		DatabaseRetries:     3,
+		DatabaseSynchronous: "NORMAL",
+		DatabaseTimeout:     30,
+		EnableCreate:        true,
+		EnableEdit:          true,
+		FeedEntries:         20,
+		HSTSMaxAge:          31536000,
+		IndexNowEndpoint:    "https://api.indexnow.org/indexnow",
+		LockoutAttempts:     5,
+		LockoutMinutes:      15,
+		LogFormat:           logging.FormatText,
+		LogKeep:             7,
+		LogLevel:            "info",
+		LogMaxSize:          100,
+		LogRotateInterval:   24,
+		MediaPath:           os.Getenv("GOPATH") + "/data/media",
+		Port:                "3000",
+		RateLimit:           60,
+		RateLimitLogin:      10,
+		ShutdownTimeout:     30,
+		SocketMode:          "0660",
+		SpamAPIEndpoint:     "https://rest.akismet.com/1.1",
+		StaticMaxAge:        86400,
+		TelegramEndpoint:    "https://api.telegram.org",
+		TenantPath:          os.Getenv("GOPATH") + "/data/tenants",
+		Theme:               "default",
+		Title:               "Jamie's Journal",
+		TLSCache:            os.Getenv("GOPATH") + "/data/certs",
+		TLSHTTPPort:         "80",
+		Workers:             1,
 	}
 }
 
 // ApplyEnvConfiguration applys the env variables on top of existing config
 func ApplyEnvConfiguration(config *Configuration) {
-	articles, _ := strconv.Atoi(os.Getenv("J_ARTICLES_PER_PAGE"))
+	applyConfiguration(config, os.Getenv)
+}
+
+// ApplyFileConfiguration applys the settings of a TOML or YAML file on top of existing config, each named like its env
+// variable without the J_ prefix, e.g. port or db_path, or db.path, or path within a db table or mapping
+func ApplyFileConfiguration(config *Configuration, path string) error {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	settings, err := configfile.Parse(path, string(source))
+	if err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	named := map[string]interface{}{}
+	for name, value := range settings {
+		named["J_"+strings.ToUpper(name)] = value
+	}
+	if unknown := ApplySettings(config, named); len(unknown) > 0 {
+		return fmt.Errorf("%s: unknown settings %s", path, strings.ToLower(strings.Join(unknown, ", ")))
+	}
+
+	return nil
+}
+
+// ApplySettings applys settings named like the env variables, e.g. J_PORT, on top of existing config, read as they
+// would be from the env with switches as 1 or 0 and lists separated by commas. The names of any settings that are not
+// known are given back without their J_ prefix, in order.
+func ApplySettings(config *Configuration, settings map[string]interface{}) []string {
+	known := map[string]bool{}
+	applyConfiguration(config, func(name string) string {
+		known[name] = true
+		switch value := settings[name].(type) {
+		case nil:
+			return ""
+		case bool:
+			if value {
+				return "1"
+			}
+			return "0"
+		case []string:
+			return strings.Join(value, ",")
+		default:
+			return fmt.Sprint(value)
+		}
+	})
+
+	unknown := []string{}
+	for name := range settings {
+		if !known[name] {
+			unknown = append(unknown, strings.TrimPrefix(name, "J_"))
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+// applyConfiguration applys settings looked up by the name of their env variable on top of existing config, leaving
+// alone any that are not given or not valid
+func applyConfiguration(config *Configuration, lookup func(name string) string) {
+	accessLog := lookup("J_ACCESS_LOG")
+	if accessLog != "" {
+		config.AccessLog = accessLog
+	}
+	accessLogFormat := lookup("J_ACCESS_LOG_FORMAT")
+	if accessLogFormat != "" {
+		config.AccessLogFormat = accessLogFormat
+	}
+	activityPubUser := lookup("J_ACTIVITYPUB_USER")
+	if activityPubUser != "" {
+		config.ActivityPubUser = activityPubUser
+	}
+	adminToken := lookup("J_ADMIN_TOKEN")
+	if adminToken != "" {
+		config.AdminToken = adminToken
+	}
+	articles, _ := strconv.Atoi(lookup("J_ARTICLES_PER_PAGE"))
 	if articles > 0 {
 		config.ArticlesPerPage = articles
 	}
-	database := os.Getenv("J_DB_PATH")
-	if database != "" {
-		config.DatabasePath = database
+	attachmentLimit, _ := strconv.Atoi(lookup("J_ATTACHMENT_LIMIT"))
+	if attachmentLimit > 0 {
+		config.AttachmentLimit = attachmentLimit
+	}
+	backupInterval, _ := strconv.Atoi(lookup("J_BACKUP_INTERVAL"))
+	if backupInterval > 0 {
+		config.BackupInterval = backupInterval
+	}
+	backupKeep, err := strconv.Atoi(lookup("J_BACKUP_KEEP"))
+	if err == nil && backupKeep >= 0 {
+		config.BackupKeep = backupKeep
+	}
+	backupPath := lookup("J_BACKUP_PATH")
+	if backupPath != "" {
+		config.BackupPath = backupPath
+	}
+	basePath := lookup("J_BASE_PATH")
+	if basePath != "" {
+		config.BasePath = CleanBasePath(basePath)
+	}
+	blogrollInterval, err := strconv.Atoi(lookup("J_BLOGROLL_INTERVAL"))
+	if err == nil && blogrollInterval >= 0 {
+		config.BlogrollInterval = blogrollInterval
+	}
+	if lookup("J_CANONICAL_REDIRECT") == "1" {
+		config.CanonicalRedirect = true
+	}
+	contentSecurityPolicy := lookup("J_CONTENT_SECURITY_POLICY")
+	if contentSecurityPolicy != "" {
+		config.ContentSecurityPolicy = contentSecurityPolicy
+	}
+	busyTimeout, err := strconv.Atoi(lookup("J_DB_BUSY_TIMEOUT"))
+	if err == nil && busyTimeout >= 0 {
+		config.DatabaseBusyTimeout = busyTimeout
+	}
+	if lookup("J_DB_FOREIGN_KEYS") == "0" {
+		config.DatabaseForeignKeys = false
+	}
+	journalMode := strings.ToUpper(lookup("J_DB_JOURNAL_MODE"))
+	if database.IsMode(journalMode, database.JournalModes) {
+		config.DatabaseJournalMode = journalMode
+	}
+	maxIdle, err := strconv.Atoi(lookup("J_DB_MAX_IDLE"))
+	if err == nil && maxIdle >= 0 {
+		config.DatabaseMaxIdle = maxIdle
 	}
-	enableCreate := os.Getenv("J_CREATE")
+	maxLifetime, err := strconv.Atoi(lookup("J_DB_MAX_LIFETIME"))
+	if err == nil && maxLifetime >= 0 {
+		config.DatabaseMaxLifetime = maxLifetime
+	}
+	maxOpen, err := strconv.Atoi(lookup("J_DB_MAX_OPEN"))
+	if err == nil && maxOpen >= 0 {
+		config.DatabaseMaxOpen = maxOpen
+	}
+	databasePath := lookup("J_DB_PATH")
+	if databasePath != "" {
+		config.DatabasePath = databasePath
+	}
+	retries, err := strconv.Atoi(lookup("J_DB_RETRIES"))
+	if err == nil && retries >= 0 {
+		config.DatabaseRetries = retries
+	}
+	synchronous := strings.ToUpper(lookup("J_DB_SYNCHRONOUS"))
+	if database.IsMode(synchronous, database.SynchronousModes) {
+		config.DatabaseSynchronous = synchronous
+	}
+	timeout, err := strconv.Atoi(lookup("J_DB_TIMEOUT"))
+	if err == nil && timeout >= 0 {
+		config.DatabaseTimeout = timeout
+	}
+	description := lookup("J_DESCRIPTION")
+	if description != "" {
+		config.Description = description
+	}
+	if lookup("J_DEV") == "1" {
+		config.Dev = true
+	}
+	enableCreate := lookup("J_CREATE")
 	if enableCreate == "0" {
 		config.EnableCreate = false
 	}
-	enableEdit := os.Getenv("J_EDIT")
+	enableEdit := lookup("J_EDIT")
 	if enableEdit == "0" {
 		config.EnableEdit = false
 	}
-	port := os.Getenv("J_PORT")
+	entriesPath := lookup("J_ENTRIES_PATH")
+	if entriesPath != "" {
+		config.EntriesPath = entriesPath
+	}
+	feedEntries, _ := strconv.Atoi(lookup("J_FEED_ENTRIES"))
+	if feedEntries > 0 {
+		config.FeedEntries = feedEntries
+	}
+	switch lookup("J_FEED_SUMMARIES") {
+	case "1":
+		config.FeedSummaries = true
+	case "0":
+		config.FeedSummaries = false
+	}
+	giphyAPIKey := lookup("J_GIPHY_API_KEY")
+	if giphyAPIKey != "" {
+		config.GiphyAPIKey = giphyAPIKey
+	}
+	hstsMaxAge, err := strconv.Atoi(lookup("J_HSTS_MAX_AGE"))
+	if err == nil && hstsMaxAge >= 0 {
+		config.HSTSMaxAge = hstsMaxAge
+	}
+	indexNowEndpoint := lookup("J_INDEXNOW_ENDPOINT")
+	if indexNowEndpoint != "" {
+		config.IndexNowEndpoint = indexNowEndpoint
+	}
+	indexNowKey := lookup("J_INDEXNOW_KEY")
+	if indexNowKey != "" {
+		config.IndexNowKey = indexNowKey
+	}
+	indieAuthAuthorizationEndpoint := lookup("J_INDIEAUTH_AUTHORIZATION_ENDPOINT")
+	if indieAuthAuthorizationEndpoint != "" {
+		config.IndieAuthAuthorizationEndpoint = indieAuthAuthorizationEndpoint
+	}
+	indieAuthTokenEndpoint := lookup("J_INDIEAUTH_TOKEN_ENDPOINT")
+	if indieAuthTokenEndpoint != "" {
+		config.IndieAuthTokenEndpoint = indieAuthTokenEndpoint
+	}
+	language := lookup("J_LANGUAGE")
+	if language != "" {
+		config.Language = language
+	}
+	lockoutAttempts, err := strconv.Atoi(lookup("J_LOCKOUT_ATTEMPTS"))
+	if err == nil && lockoutAttempts >= 0 {
+		config.LockoutAttempts = lockoutAttempts
+	}
+	lockoutMinutes, err := strconv.Atoi(lookup("J_LOCKOUT_MINUTES"))
+	if err == nil && lockoutMinutes > 0 {
+		config.LockoutMinutes = lockoutMinutes
+	}
+	logFile := lookup("J_LOG_FILE")
+	if logFile != "" {
+		config.LogFile = logFile
+	}
+	logFormat := lookup("J_LOG_FORMAT")
+	if logFormat != "" {
+		config.LogFormat = logFormat
+	}
+	logKeep, err := strconv.Atoi(lookup("J_LOG_KEEP"))
+	if err == nil && logKeep >= 0 {
+		config.LogKeep = logKeep
+	}
+	logLevel := lookup("J_LOG_LEVEL")
+	if logLevel != "" {
+		config.LogLevel = logLevel
+	}
+	logMaxSize, err := strconv.Atoi(lookup("J_LOG_MAX_SIZE"))
+	if err == nil && logMaxSize >= 0 {
+		config.LogMaxSize = logMaxSize
+	}
+	logRotateInterval, err := strconv.Atoi(lookup("J_LOG_ROTATE_INTERVAL"))
+	if err == nil && logRotateInterval >= 0 {
+		config.LogRotateInterval = logRotateInterval
+	}
+	mailFrom := lookup("J_MAIL_FROM")
+	if mailFrom != "" {
+		config.MailFrom = mailFrom
+	}
+	mailPort := lookup("J_MAIL_PORT")
+	if mailPort != "" {
+		config.MailPort = mailPort
+	}
+	if lookup("J_MAINTENANCE") == "1" {
+		config.Maintenance = true
+	}
+	mediaPath := lookup("J_MEDIA_PATH")
+	if mediaPath != "" {
+		config.MediaPath = mediaPath
+	}
+	oidcClientID := lookup("J_OIDC_CLIENT_ID")
+	if oidcClientID != "" {
+		config.OIDCClientID = oidcClientID
+	}
+	oidcClientSecret := lookup("J_OIDC_CLIENT_SECRET")
+	if oidcClientSecret != "" {
+		config.OIDCClientSecret = oidcClientSecret
+	}
+	oidcProvider := lookup("J_OIDC_PROVIDER")
+	if oidcProvider != "" {
+		config.OIDCProvider = oidcProvider
+	}
+	passphrase := lookup("J_PASSPHRASE")
+	if passphrase != "" {
+		config.Passphrase = passphrase
+	}
+	port := lookup("J_PORT")
 	if port != "" {
 		config.Port = port
 	}
-	title := os.Getenv("J_TITLE")
+	rateLimit, err := strconv.Atoi(lookup("J_RATE_LIMIT"))
+	if err == nil && rateLimit >= 0 {
+		config.RateLimit = rateLimit
+	}
+	rateLimitLogin, err := strconv.Atoi(lookup("J_RATE_LIMIT_LOGIN"))
+	if err == nil && rateLimitLogin >= 0 {
+		config.RateLimitLogin = rateLimitLogin
+	}
+	robotsPath := lookup("J_ROBOTS_PATH")
+	if robotsPath != "" {
+		config.RobotsPath = robotsPath
+	}
+	shutdownTimeout, err := strconv.Atoi(lookup("J_SHUTDOWN_TIMEOUT"))
+	if err == nil && shutdownTimeout >= 0 {
+		config.ShutdownTimeout = shutdownTimeout
+	}
+	socket := lookup("J_SOCKET")
+	if socket != "" {
+		config.Socket = socket
+	}
+	socketMode := lookup("J_SOCKET_MODE")
+	if socketMode != "" {
+		config.SocketMode = socketMode
+	}
+	spamAPIEndpoint := lookup("J_SPAM_API_ENDPOINT")
+	if spamAPIEndpoint != "" {
+		config.SpamAPIEndpoint = spamAPIEndpoint
+	}
+	spamAPIKey := lookup("J_SPAM_API_KEY")
+	if spamAPIKey != "" {
+		config.SpamAPIKey = spamAPIKey
+	}
+	staticMaxAge, err := strconv.Atoi(lookup("J_STATIC_MAX_AGE"))
+	if err == nil && staticMaxAge >= 0 {
+		config.StaticMaxAge = staticMaxAge
+	}
+	telegramChatID := lookup("J_TELEGRAM_CHAT_ID")
+	if telegramChatID != "" {
+		config.TelegramChatID = telegramChatID
+	}
+	telegramEndpoint := lookup("J_TELEGRAM_ENDPOINT")
+	if telegramEndpoint != "" {
+		config.TelegramEndpoint = telegramEndpoint
+	}
+	telegramToken := lookup("J_TELEGRAM_TOKEN")
+	if telegramToken != "" {
+		config.TelegramToken = telegramToken
+	}
+	tenantDomain := lookup("J_TENANT_DOMAIN")
+	if tenantDomain != "" {
+		config.TenantDomain = tenantDomain
+	}
+	tenantMaxEntries, _ := strconv.Atoi(lookup("J_TENANT_MAX_ENTRIES"))
+	if tenantMaxEntries > 0 {
+		config.TenantMaxEntries = tenantMaxEntries
+	}
+	tenantMode := lookup("J_TENANT_MODE")
+	if tenantMode == TenantModeSubdomain || tenantMode == TenantModePath {
+		config.TenantMode = tenantMode
+	}
+	tenantPath := lookup("J_TENANT_PATH")
+	if tenantPath != "" {
+		config.TenantPath = tenantPath
+	}
+	theme := lookup("J_THEME")
+	if theme != "" {
+		config.Theme = theme
+	}
+	title := lookup("J_TITLE")
 	if title != "" {
 		config.Title = title
 	}
+	if lookup("J_TLS") == "1" {
+		config.TLS = true
+	}
+	tlsCache := lookup("J_TLS_CACHE")
+	if tlsCache != "" {
+		config.TLSCache = tlsCache
+	}
+	tlsCert := lookup("J_TLS_CERT")
+	if tlsCert != "" {
+		config.TLSCert = tlsCert
+	}
+	tlsDomain := lookup("J_TLS_DOMAIN")
+	if tlsDomain != "" {
+		config.TLSDomain = tlsDomain
+	}
+	tlsEmail := lookup("J_TLS_EMAIL")
+	if tlsEmail != "" {
+		config.TLSEmail = tlsEmail
+	}
+	tlsHTTPPort := lookup("J_TLS_HTTP_PORT")
+	if tlsHTTPPort != "" {
+		config.TLSHTTPPort = tlsHTTPPort
+	}
+	tlsKey := lookup("J_TLS_KEY")
+	if tlsKey != "" {
+		config.TLSKey = tlsKey
+	}
+	trashRetention, _ := strconv.Atoi(lookup("J_TRASH_RETENTION"))
+	if trashRetention > 0 {
+		config.TrashRetention = trashRetention
+	}
+	trustedProxies := lookup("J_TRUSTED_PROXIES")
+	if trustedProxies != "" {
+		config.TrustedProxies = trustedProxies
+	}
+	siteURL := lookup("J_URL")
+	if siteURL != "" {
+		config.URL = siteURL
+	}
+	webPath := lookup("J_WEB_PATH")
+	if webPath != "" {
+		config.WebPath = webPath
+	}
+	webSubHub := lookup("J_WEBSUB_HUB")
+	if webSubHub != "" {
+		config.WebSubHub = webSubHub
+	}
+	workers, err := strconv.Atoi(lookup("J_WORKERS"))
+	if err == nil && workers >= 0 {
+		config.Workers = workers
+	}
 }
@@ -0,0 +1,523 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+	"github.com/jamiefdhurst/journal/web"
+)
+
+func TestContainer_MediaPath(t *testing.T) {
+	container := &Container{Configuration: Configuration{MediaPath: "/data/media"}}
+	if container.MediaPath() != "/data/media" {
+		t.Errorf("Expected media path to be used as it is, got '%s'", container.MediaPath())
+	}
+	container.Tenant = "alice"
+	if container.MediaPath() != "/data/media/alice" {
+		t.Errorf("Expected media to be kept apart for each tenant, got '%s'", container.MediaPath())
+	}
+}
+
+func TestContainer_CSRFInput(t *testing.T) {
+	container := &Container{CSRFToken: "abc123"}
+	if string(container.CSRFInput()) != `<input type="hidden" name="csrf_token" value="abc123" />` {
+		t.Errorf("Expected hidden field carrying the token, got '%s'", container.CSRFInput())
+	}
+	if string(container.CSRFQuery()) != "csrf_token=abc123" {
+		t.Errorf("Expected query carrying the token, got '%s'", container.CSRFQuery())
+	}
+}
+
+func TestContainer_URL(t *testing.T) {
+	container := &Container{Configuration: DefaultConfiguration()}
+	if container.URL("/test") != "" {
+		t.Error("Expected no URL without a site URL configured")
+	}
+
+	tables := []struct {
+		siteURL  string
+		mode     string
+		tenant   string
+		basePath string
+		output   string
+	}{
+		{"https://example.com", "", "", "", "https://example.com/test"},
+		{"https://example.com/", "", "", "", "https://example.com/test"},
+		{"https://example.com", TenantModePath, "alice", "/alice", "https://example.com/alice/test"},
+		{"https://example.com", TenantModeSubdomain, "alice", "", "https://alice.example.com/test"},
+		{"https://example.com", "", "", "/journal", "https://example.com/journal/test"},
+		{"https://example.com", TenantModePath, "alice", "/journal/alice", "https://example.com/journal/alice/test"},
+	}
+
+	for _, table := range tables {
+		container.Configuration.URL = table.siteURL
+		container.Configuration.TenantDomain = "example.com"
+		container.Configuration.TenantMode = table.mode
+		container.Tenant = table.tenant
+		container.BasePath = table.basePath
+		actual := container.URL("/test")
+		if actual != table.output {
+			t.Errorf("Expected URL() to produce result of '%s', got '%s'", table.output, actual)
+		}
+	}
+}
+
+func TestCleanBasePath(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"journal", "/journal"},
+		{"/journal/", "/journal"},
+		{"//sites//journal/", "/sites/journal"},
+	}
+
+	for _, table := range tables {
+		actual := CleanBasePath(table.input)
+		if actual != table.output {
+			t.Errorf("Expected CleanBasePath(%s) to produce result of '%s', got '%s'", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestConfiguration_DatabasePool(t *testing.T) {
+	configuration := DefaultConfiguration()
+	configuration.DatabaseBusyTimeout = 2500
+	configuration.DatabaseMaxLifetime = 60
+	pool := configuration.DatabasePool()
+	if pool.BusyTimeout != 2500*time.Millisecond || pool.ConnMaxLifetime != time.Minute || pool.MaxOpenConns != configuration.DatabaseMaxOpen || pool.Retries != configuration.DatabaseRetries {
+		t.Errorf("Expected pool to follow the configuration, got %+v", pool)
+	}
+}
+
+func TestApplyFileConfiguration(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "config")
+	defer os.RemoveAll(dir)
+
+	// Test settings are read and switches and lists are understood as in the env
+	file := filepath.Join(dir, "journal.toml")
+	ioutil.WriteFile(file, []byte("title = \"Test Journal\"\nport = 8080\ncreate = false\ntrusted_proxies = [\"10.0.0.0/8\", \"192.168.1.1\"]\n\n[db]\npath = \"/data/test.db\"\nmax_open = 4\n"), 0644)
+	configuration := DefaultConfiguration()
+	if err := ApplyFileConfiguration(&configuration, file); err != nil {
+		t.Fatalf("Expected settings to be read, got %s", err)
+	}
+	if configuration.Title != "Test Journal" || configuration.Port != "8080" || configuration.EnableCreate || !configuration.EnableEdit ||
+		configuration.TrustedProxies != "10.0.0.0/8,192.168.1.1" || configuration.DatabasePath != "/data/test.db" || configuration.DatabaseMaxOpen != 4 {
+		t.Errorf("Expected settings from the file to be applied, got %+v", configuration)
+	}
+
+	// Test the env overrides the file
+	os.Setenv("J_TITLE", "Env Journal")
+	defer os.Unsetenv("J_TITLE")
+	ApplyEnvConfiguration(&configuration)
+	if configuration.Title != "Env Journal" || configuration.Port != "8080" {
+		t.Error("Expected the env to override only the settings it gives")
+	}
+
+	// Test YAML, unknown settings, bad files and missing files
+	file = filepath.Join(dir, "journal.yml")
+	ioutil.WriteFile(file, []byte("base_path: journal/\ntitel: Typo\nsmtp:\n  host: example.com\n"), 0644)
+	configuration = DefaultConfiguration()
+	err := ApplyFileConfiguration(&configuration, file)
+	if err == nil || err.Error() != file+": unknown settings smtp_host, titel" || configuration.BasePath != "/journal" {
+		t.Errorf("Expected unknown settings to be reported, got %v", err)
+	}
+	ioutil.WriteFile(file, []byte("  title: Indented"), 0644)
+	if err := ApplyFileConfiguration(&configuration, file); err == nil || err.Error() != file+": Line 1: indented beneath nothing" {
+		t.Errorf("Expected file that cannot be read to be reported, got %v", err)
+	}
+	if err := ApplyFileConfiguration(&configuration, filepath.Join(dir, "missing.toml")); err == nil {
+		t.Error("Expected missing file to be reported")
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	configuration := DefaultConfiguration()
+	unknown := ApplySettings(&configuration, map[string]interface{}{"J_PORT": "4000", "J_EDIT": false, "J_CANONICAL_REDIRECT": true, "J_MAINTENANCE": true, "J_DEV": true, "J_LANGUAGE": "fr", "J_PROT": "1"})
+	if configuration.Port != "4000" || configuration.Language != "fr" || configuration.EnableEdit || !configuration.CanonicalRedirect || !configuration.Maintenance || !configuration.Dev || len(unknown) != 1 || unknown[0] != "PROT" {
+		t.Errorf("Expected settings to be applied and unknown ones given back, got %v", unknown)
+	}
+}
+
+func TestConfiguration_Validate(t *testing.T) {
+	if problems := DefaultConfiguration().Validate(); len(problems) > 0 {
+		t.Errorf("Expected default settings to be valid, got %v", problems)
+	}
+
+	configuration := DefaultConfiguration()
+	configuration.Port = "http"
+	configuration.MailPort = "70000"
+	configuration.SocketMode = "rw"
+	configuration.Socket = "/run/" + strings.Repeat("journal/", 13) + "journal.sock"
+	configuration.URL = "ftp://example.com"
+	configuration.WebSubHub = "https://hub.example.com"
+	configuration.Language = "de"
+	configuration.LogFormat = "xml"
+	configuration.LogLevel = "verbose"
+	configuration.AccessLogFormat = "apache"
+	configuration.EntriesPath = "/data/entries"
+	configuration.Passphrase = "secret"
+	configuration.OIDCProvider = "http://issuer.example.com"
+	configuration.TenantMode = TenantModeSubdomain
+	configuration.TrustedProxies = "10.0.0.0/8, proxy"
+	configuration.WebPath = "/missing/web"
+	configuration.Theme = "missing"
+	expected := []string{
+		"J_PORT must be a port number from 1 to 65535, not 'http'",
+		"J_MAIL_PORT must be a port number from 1 to 65535, not '70000'",
+		"J_SOCKET_MODE must be permissions in octal such as 0660, not 'rw'",
+		"J_SOCKET must be a path of at most 103 characters, not '/run/" + strings.Repeat("journal/", 13) + "journal.sock'",
+		"J_URL must be an http or https address such as https://journal.example.com, not 'ftp://example.com'",
+		"J_LANGUAGE must be one of en, fr, or empty to follow each reader's browser, not 'de'",
+		"J_LOG_FORMAT must be text or json, not 'xml'",
+		"J_LOG_LEVEL must be debug, info, warn or error, not 'verbose'",
+		"J_ACCESS_LOG_FORMAT must be text, json or combined, not 'apache'",
+		"Entries cannot be kept as files with J_ENTRIES_PATH, which hold them as plain text, while J_PASSPHRASE encrypts them",
+		"J_OIDC_PROVIDER must be google, github or the https address of an issuer, not 'http://issuer.example.com'",
+		"J_TENANT_DOMAIN must be set to host journals on its subdomains",
+		"J_TRUSTED_PROXIES must list addresses or ranges such as 10.0.0.0/8, not 'proxy'",
+		"J_WEB_PATH must be a directory of templates and static files laid out like web/, not '/missing/web'",
+		"J_THEME missing has no theme at web/themes/missing or stylesheet at web/static/css/missing.min.css",
+	}
+	problems := configuration.Validate()
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, problem := range problems {
+		if problem.Error() != expected[i] {
+			t.Errorf("Expected '%s', got '%s'", expected[i], problem)
+		}
+	}
+
+	configuration = DefaultConfiguration()
+	configuration.WebSubHub = "https://hub.example.com"
+	configuration.OIDCClientID = "client"
+	configuration.BasePath = "/journal"
+	configuration.Theme = "../default"
+	problems = configuration.Validate()
+	if len(problems) != 3 || problems[0].Error() != "J_WEBSUB_HUB requires J_URL to be set" ||
+		problems[1].Error() != "J_OIDC_CLIENT_ID requires J_OIDC_PROVIDER to be set" || problems[2].Error() != "J_THEME must be the name of a theme in web/themes or a stylesheet in web/static/css, not '../default'" {
+		t.Errorf("Expected settings needing others to be reported, got %v", problems)
+	}
+	configuration = DefaultConfiguration()
+	configuration.URL = "https://example.com/journal/"
+	configuration.BasePath = "/journal"
+	if problems = configuration.Validate(); len(problems) != 1 || problems[0].Error() != "J_URL must leave out the base path /journal, which is added to it" {
+		t.Errorf("Expected base path within the URL to be reported, got %v", problems)
+	}
+
+	configuration = DefaultConfiguration()
+	configuration.TLS = true
+	configuration.Port = "443"
+	configuration.TLSDomain = "journal.example.com, www.journal.example.com"
+	if problems = configuration.Validate(); len(problems) > 0 {
+		t.Errorf("Expected domains to obtain certificates for to be valid, got %v", problems)
+	}
+	configuration.TLSDomain = "https://journal.example.com"
+	configuration.TLSCert = "/missing/cert.pem"
+	configuration.TLSHTTPPort = "443"
+	configuration.URL = "http://journal.example.com"
+	expected = []string{
+		"J_TLS_CERT and J_TLS_KEY must be set together",
+		"J_TLS_DOMAIN must list host names such as journal.example.com, not 'https://journal.example.com'",
+		"J_TLS_HTTP_PORT must differ from J_PORT, which serves HTTPS",
+		"J_URL must be an https address when serving over TLS, not 'http://journal.example.com'",
+	}
+	problems = configuration.Validate()
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, problem := range problems {
+		if problem.Error() != expected[i] {
+			t.Errorf("Expected '%s', got '%s'", expected[i], problem)
+		}
+	}
+	configuration = DefaultConfiguration()
+	configuration.TLS = true
+	configuration.TLSHTTPPort = "0"
+	if problems = configuration.Validate(); len(problems) != 1 || problems[0].Error() != "J_TLS requires J_TLS_DOMAIN, or J_TLS_CERT and J_TLS_KEY, to be set" {
+		t.Errorf("Expected certificates to be required, got %v", problems)
+	}
+	configuration.TLSKey = "/missing/key.pem"
+	configuration.TLSCert = "/missing/cert.pem"
+	if problems = configuration.Validate(); len(problems) != 1 || !strings.HasPrefix(problems[0].Error(), "J_TLS_CERT and J_TLS_KEY could not be read") {
+		t.Errorf("Expected certificates that cannot be read to be reported, got %v", problems)
+	}
+	configuration.TLS = false
+	if problems = configuration.Validate(); len(problems) != 1 || problems[0].Error() != "J_TLS_DOMAIN, J_TLS_CERT and J_TLS_KEY are only used once J_TLS is set" {
+		t.Errorf("Expected certificates without TLS to be reported, got %v", problems)
+	}
+}
+
+func TestContainer_Templates(t *testing.T) {
+	var container *Container
+	template, err := container.Templates("_layout/default.tmpl", "error.tmpl")
+	if err != nil || template.Lookup("layout") == nil {
+		t.Fatalf("Expected the templates built in to be parsed, got %v", err)
+	}
+
+	// Test templates on disk take the place of those built in, and themes can be added beside them
+	dir, _ := ioutil.TempDir("", "web")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "templates"), 0755)
+	os.MkdirAll(filepath.Join(dir, "static", "css"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "error.tmpl"), []byte(`{{define "content"}}Lost{{end}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "static", "css", "dark.min.css"), []byte("body{color:white}"), 0644)
+	container = &Container{Configuration: DefaultConfiguration()}
+	container.Configuration.WebPath = dir
+	container.Configuration.Theme = "dark"
+	template, err = container.Templates("_layout/default.tmpl", "error.tmpl")
+	output := strings.Builder{}
+	if err != nil || template.ExecuteTemplate(&output, "content", nil) != nil || output.String() != "Lost" {
+		t.Errorf("Expected the template on disk to be used, got %v and %s", err, output.String())
+	}
+	if problems := container.Configuration.Validate(); len(problems) > 0 {
+		t.Errorf("Expected a theme on disk to be valid, got %v", problems)
+	}
+}
+
+func TestConfiguration_OpenLog(t *testing.T) {
+	configuration := DefaultConfiguration()
+	ApplySettings(&configuration, map[string]interface{}{"J_LOG_MAX_SIZE": 5, "J_LOG_ROTATE_INTERVAL": 0, "J_LOG_KEEP": 3})
+	f, err := configuration.OpenLog(filepath.Join(t.TempDir(), "journal.log"))
+	if err != nil {
+		t.Fatalf("Expected log file to be opened, got %s", err)
+	}
+	defer f.Close()
+	if f.MaxSize != 5*1024*1024 || f.Interval != 0 || f.Keep != 3 {
+		t.Errorf("Expected log file to be rotated as configured, got %+v", f)
+	}
+}
+
+func TestConfiguration_SocketFileMode(t *testing.T) {
+	configuration := DefaultConfiguration()
+	if mode := configuration.SocketFileMode(); mode != 0660 {
+		t.Errorf("Expected the socket to be shared with the group by default, got %o", mode)
+	}
+	configuration.SocketMode = "666"
+	if mode := configuration.SocketFileMode(); mode != 0666 {
+		t.Errorf("Expected the permissions given, got %o", mode)
+	}
+}
+
+func TestConfiguration_TLSDomains(t *testing.T) {
+	configuration := Configuration{TLSDomain: " Journal.example.com,,www.example.com "}
+	if domains := configuration.TLSDomains(); !reflect.DeepEqual(domains, []string{"journal.example.com", "www.example.com"}) {
+		t.Errorf("Expected domains to be listed in lowercase, got %v", domains)
+	}
+}
+
+func TestContainer_WithContext(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &Container{Configuration: DefaultConfiguration(), Db: db}
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := container.WithContext(ctx)
+	if container.Db != db || bound.Queue != nil {
+		t.Error("Expected the container to be left as it is")
+	}
+	cancel()
+	if _, err := bound.Db.Query("SELECT 1"); err != context.Canceled || db.Queries != 0 {
+		t.Errorf("Expected statements to stop with the request, got %v", err)
+	}
+
+	logger := logging.New(ioutil.Discard, logging.FormatText, logging.LevelInfo)
+	if container.Log() != logging.Default() || container.WithContext(logging.NewContext(ctx, logger)).Log() != logger {
+		t.Error("Expected the default logger, or that of the request once bound to it")
+	}
+}
+
+func TestSettings(t *testing.T) {
+	var unsaved *Settings
+	if unsaved.Get(SettingTitle) != "" || unsaved.Apply(DefaultConfiguration()).Title != "Jamie's Journal" {
+		t.Error("Expected the configuration to be kept without any settings")
+	}
+
+	container := &Container{Configuration: DefaultConfiguration(), Settings: NewSettings(map[string]string{})}
+	bound := container.WithContext(context.Background())
+	container.Settings.Set(SettingTitle, "Alice's Journal")
+	if bound.Configuration.Title != "Jamie's Journal" || container.WithContext(context.Background()).Configuration.Title != "Alice's Journal" {
+		t.Error("Expected requests after a setting is saved to use it")
+	}
+	if container.Configuration.Title != "Jamie's Journal" {
+		t.Error("Expected the configuration of the journal to be left as it is")
+	}
+
+	container.Settings.Set(SettingArticlesPerPage, "5")
+	container.Settings.Set(SettingDescription, "Notes from Alice")
+	container.Settings.Set(SettingFeedSummaries, "1")
+	container.Settings.Set(SettingTitle, "")
+	bound = container.WithContext(context.Background())
+	if bound.Configuration.ArticlesPerPage != 5 || bound.Configuration.Description != "Notes from Alice" || !bound.Configuration.FeedSummaries || bound.Configuration.Title != "Jamie's Journal" {
+		t.Errorf("Expected saved settings to be applied and emptied ones removed, got %+v", bound.Configuration)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	var unswitched *Maintenance
+	if unswitched.On() {
+		t.Error("Expected the journal not to be in maintenance without a switch")
+	}
+
+	container := &Container{Configuration: DefaultConfiguration(), Maintenance: NewMaintenance(false)}
+	bound := container.WithContext(context.Background())
+	if bound.Maintenance.On() || !bound.Maintenance.Toggle() || !container.Maintenance.On() {
+		t.Error("Expected maintenance switched while serving a request to be shared with the journal")
+	}
+	if container.Maintenance.Toggle() || bound.Maintenance.On() {
+		t.Error("Expected maintenance to be toggled off again")
+	}
+	container.Maintenance.Switch(true)
+	container.Maintenance.Switch(true)
+	if !bound.Maintenance.On() || !NewMaintenance(true).On() {
+		t.Error("Expected maintenance to be switched on")
+	}
+}
+
+func TestConfiguration_CheckSettings(t *testing.T) {
+	configuration := DefaultConfiguration()
+	if problems := configuration.CheckSettings(map[string]string{SettingTitle: "Alice's Journal", SettingArticlesPerPage: "5", SettingFeedSummaries: "0", SettingURL: "", SettingLanguage: "fr"}); len(problems) != 0 {
+		t.Errorf("Expected settings to be accepted, got %v", problems)
+	}
+
+	tables := []struct {
+		name  string
+		value string
+	}{
+		{SettingArticlesPerPage, "0"},
+		{SettingFeedEntries, "lots"},
+		{SettingFeedSummaries, "yes"},
+		{SettingURL, "journal.example.com"},
+		{SettingLanguage, "de"},
+	}
+	for _, table := range tables {
+		if problems := configuration.CheckSettings(map[string]string{table.name: table.value}); len(problems) != 1 {
+			t.Errorf("Expected one problem with %s of '%s', got %v", table.name, table.value, problems)
+		}
+	}
+
+	// Test problems with the configuration are left to be reported as it is loaded
+	configuration.IndexNowKey = "key"
+	if problems := configuration.CheckSettings(map[string]string{SettingTitle: "Alice's Journal"}); len(problems) != 0 {
+		t.Errorf("Expected problems already configured to be ignored, got %v", problems)
+	}
+}
+
+func TestConfiguration_Themes(t *testing.T) {
+	themes := DefaultConfiguration().Themes()
+	if len(themes) == 0 || themes[0] != "default" {
+		t.Errorf("Expected the default theme to be found, got %v", themes)
+	}
+
+	// Test bundles in themes/ are found beside stylesheets, and theirs are used when they have one
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "static", "css"), 0755)
+	os.MkdirAll(filepath.Join(dir, "themes", "paper", "templates"), 0755)
+	os.MkdirAll(filepath.Join(dir, "themes", "paper", "static", "css"), 0755)
+	os.MkdirAll(filepath.Join(dir, "themes", "bare", "templates"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "static", "css", "dark.min.css"), []byte("body{color:white}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "themes", "paper", "static", "css", "paper.min.css"), []byte("body{color:sepia}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "themes", "paper", "templates", "index.tmpl"), []byte(`{{define "content"}}Paper{{end}}`), 0644)
+	configuration := DefaultConfiguration()
+	configuration.WebPath = dir
+	if themes := configuration.Themes(); !reflect.DeepEqual(themes, []string{"bare", "dark", "default", "paper"}) {
+		t.Errorf("Expected bundles and stylesheets to be found, got %v", themes)
+	}
+	configuration.Theme = "paper"
+	if problems := configuration.Validate(); len(problems) > 0 {
+		t.Errorf("Expected bundle to be a valid theme, got %v", problems)
+	}
+	if stylesheet := configuration.Stylesheet(); stylesheet != "static/css/paper.min.css" {
+		t.Errorf("Expected the theme's stylesheet to be used, got %s", stylesheet)
+	}
+	missing := configuration.MissingTemplates()
+	if len(missing) != len(web.Templates)-1 || missing[0] != "_layout/default.tmpl" {
+		t.Errorf("Expected templates left out of the theme to be listed, got %v", missing)
+	}
+
+	// Test templates the theme leaves out fall back to those built in
+	container := &Container{Configuration: configuration}
+	for name, expected := range map[string]string{"index.tmpl": "Paper", "error.tmpl": "Page Not Found"} {
+		template, err := container.Templates("_layout/default.tmpl", name)
+		output := strings.Builder{}
+		if err != nil || template.ExecuteTemplate(&output, "content", map[string]interface{}{"Container": container}) != nil || !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected %s to render %s, got %v and %s", name, expected, err, output.String())
+		}
+	}
+	configuration.Theme = "bare"
+	if stylesheet := configuration.Stylesheet(); stylesheet != "static/css/default.min.css" {
+		t.Errorf("Expected the stylesheet built in for a theme without one, got %s", stylesheet)
+	}
+	configuration.Theme = "dark"
+	if stylesheet := configuration.Stylesheet(); stylesheet != "static/css/dark.min.css" || len(configuration.MissingTemplates()) != 0 {
+		t.Errorf("Expected a stylesheet to be a theme of its own, got %s", stylesheet)
+	}
+
+	// Test every template built in is one themes are rendered with
+	names := []string{}
+	fs.WalkDir(web.Files, "templates", func(name string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			names = append(names, strings.TrimPrefix(name, "templates/"))
+		}
+		return err
+	})
+	if !reflect.DeepEqual(names, web.Templates) {
+		t.Errorf("Expected the templates built in to be listed, got %v", names)
+	}
+}
+
+func TestContainer_Transaction(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &Container{Configuration: DefaultConfiguration(), Db: db}
+	failure := errors.New("Simulated failure")
+	err := container.Transaction(func(tx *Container) error {
+		if tx == container || tx.Db != db {
+			t.Error("Expected a copy of the container, running statements as they are without transactions")
+		}
+		return failure
+	})
+	if err != failure {
+		t.Errorf("Expected failure to be returned, got %v", err)
+	}
+}
+
+func TestContainer_ReadOnly(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &Container{Configuration: DefaultConfiguration(), Db: db}
+	if container.ReadOnly().Db != db {
+		t.Error("Expected rows to be read from the database without a replica")
+	}
+
+	replica := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	container.Replica = replica
+	readOnly := container.WithContext(context.Background()).ReadOnly()
+	if container.Db != db {
+		t.Error("Expected the container to be left as it is")
+	}
+	readOnly.Db.Query("SELECT 1")
+	readOnly.Db.Exec("DELETE FROM `journal`")
+	if replica.Queries != 1 || db.Queries != 1 {
+		t.Errorf("Expected rows to be read from the replica and changes made to the database, got %d and %d queries", replica.Queries, db.Queries)
+	}
+}
+
+func TestConfiguration_SqlitePragmas(t *testing.T) {
+	pragmas := DefaultConfiguration().SqlitePragmas()
+	if !pragmas.ForeignKeys || pragmas.JournalMode != "WAL" || pragmas.Synchronous != "NORMAL" {
+		t.Errorf("Expected WAL, foreign keys and normal syncing by default, got %+v", pragmas)
+	}
+	if pragmas := DefaultConfiguration().SqliteReplicaPragmas(); !pragmas.QueryOnly || pragmas.JournalMode != "" {
+		t.Errorf("Expected replicas to refuse writes and keep their journal, got %+v", pragmas)
+	}
+}
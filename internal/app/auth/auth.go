@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// BearerToken Extract the token from an Authorization header
+func BearerToken(request *http.Request) string {
+	header := request.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// SessionCookie Name of the cookie remembering a user signed in through the login form
+const SessionCookie = "journal_session"
+
+// Authenticated Check whether the request carries the credentials of a user, either their
+// session cookie, their username and password over HTTP Basic, one of their API tokens or
+// the bootstrap admin token
+func Authenticated(request *http.Request, container *app.Container) bool {
+	return CurrentUser(request, container).Role != ""
+}
+
+// CurrentUser Get the user whose credentials the request carries, or an admin without an ID for
+// the bootstrap admin token, as long as any API token sent has the scope the request needs
+func CurrentUser(request *http.Request, container *app.Container) model.User {
+	user, token := identify(request, container)
+	if token.ID > 0 && !token.HasScope(Scope(request)) {
+		return model.User{}
+	}
+
+	return user
+}
+
+// Scope Get the scope an API token needs for a request, reading for GET and HEAD and writing for anything else
+func Scope(request *http.Request) string {
+	if request.Method == http.MethodGet || request.Method == http.MethodHead {
+		return model.ScopeRead
+	}
+
+	return model.ScopeWrite
+}
+
+// identify Get the user whose credentials the request carries, along with the API token it was sent with, if any
+func identify(request *http.Request, container *app.Container) (model.User, model.Token) {
+	if user := SessionUser(request, container); user.ID > 0 {
+		return user, model.Token{}
+	}
+	if username, password, ok := request.BasicAuth(); ok {
+		return basicUser(request, container, username, password), model.Token{}
+	}
+
+	plain := BearerToken(request)
+	if plain == "" {
+		return model.User{}, model.Token{}
+	}
+	adminToken := container.Configuration.AdminToken
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(plain), []byte(adminToken)) == 1 {
+		return model.User{Username: "admin", Role: model.RoleAdmin}, model.Token{}
+	}
+	ts := model.Tokens{Container: container}
+	token := ts.Authenticate(plain)
+	if token.ID == 0 {
+		return model.User{}, token
+	}
+
+	us := model.Users{Container: container}
+
+	return us.FindByID(token.UserID), token
+}
+
+// SessionUser Get the user signed in by the session cookie the request carries, if any
+func SessionUser(request *http.Request, container *app.Container) model.User {
+	cookie, err := request.Cookie(SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return model.User{}
+	}
+	ss := model.Sessions{Container: container}
+	session := ss.Authenticate(cookie.Value)
+	if session.ID == 0 {
+		return model.User{}
+	}
+	us := model.Users{Container: container}
+
+	return us.FindByID(session.UserID)
+}
+
+// StartSession Sign a user in, setting the cookie that keeps them signed in
+func StartSession(response http.ResponseWriter, request *http.Request, container *app.Container, user model.User) error {
+	ss := model.Sessions{Container: container}
+	session, plain, err := ss.Start(user.ID)
+	if err != nil {
+		return err
+	}
+	cookie := sessionCookie(request, container, plain)
+	cookie.Expires, _ = time.Parse("2006-01-02 15:04:05", session.ExpiresAt)
+	http.SetCookie(response, cookie)
+
+	return nil
+}
+
+// EndSession Sign out whoever the session cookie belongs to, removing the cookie
+func EndSession(response http.ResponseWriter, request *http.Request, container *app.Container) error {
+	cookie := sessionCookie(request, container, "")
+	cookie.MaxAge = -1
+	http.SetCookie(response, cookie)
+	if current, err := request.Cookie(SessionCookie); err == nil && current.Value != "" {
+		ss := model.Sessions{Container: container}
+		return ss.End(current.Value)
+	}
+
+	return nil
+}
+
+// sessionCookie Build the session cookie, kept from scripts and other sites and only sent over HTTPS when signed in
+// over HTTPS
+func sessionCookie(request *http.Request, container *app.Container, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookie,
+		Value:    value,
+		Path:     container.BasePath + "/",
+		HttpOnly: true,
+		Secure:   proxy.Secure(request),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
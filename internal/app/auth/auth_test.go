@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestBearerToken(t *testing.T) {
+	tables := []struct {
+		header string
+		output string
+	}{
+		{"Bearer abc123", "abc123"},
+		{"bearer abc123 ", "abc123"},
+		{"Basic abc123", ""},
+		{"Bearer", ""},
+		{"", ""},
+	}
+
+	for _, table := range tables {
+		request, _ := http.NewRequest("GET", "/", nil)
+		request.Header.Add("Authorization", table.header)
+		actual := BearerToken(request)
+		if actual != table.output {
+			t.Errorf("Expected BearerToken() to produce result of '%s', got '%s'", table.output, actual)
+		}
+	}
+}
+
+func TestAuthenticated(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.AdminToken = "admin-secret"
+	configuration.LockoutAttempts = 0
+	container := &app.Container{Configuration: configuration, Db: db}
+
+	// Test no credentials
+	request, _ := http.NewRequest("GET", "/", nil)
+	if Authenticated(request, container) || db.Queries != 0 {
+		t.Error("Expected request without credentials to be refused")
+	}
+
+	// Test admin token
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	if !Authenticated(request, container) {
+		t.Error("Expected admin token to be accepted")
+	}
+
+	// Test unknown token and user
+	db.Rows = &database.MockRowsEmpty{}
+	request.Header.Set("Authorization", "Bearer unknown")
+	if Authenticated(request, container) {
+		t.Error("Expected unknown token to be refused")
+	}
+	db.Rows = &database.MockRowsEmpty{}
+	request.Header.Del("Authorization")
+	request.SetBasicAuth("nobody", "password")
+	if Authenticated(request, container) {
+		t.Error("Expected unknown user to be refused")
+	}
+
+	// Test user's password
+	db.AppendResult(&database.MockToken_SingleRow{Scopes: model.ScopeRead})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleEditor})
+	post, _ := http.NewRequest("POST", "/", nil)
+	post.Header.Set("Authorization", "Bearer known")
+	if user := CurrentUser(post, container); user.ID != 0 {
+		t.Error("Expected API token without the write scope to be refused for a change")
+	}
+	u := model.User{}
+	u.SetPassword("correct horse")
+	db.Rows = &database.MockUser_SingleRow{PasswordHash: u.PasswordHash}
+	request.SetBasicAuth("jamie", "correct horse")
+	if !Authenticated(request, container) {
+		t.Error("Expected user's password to be accepted")
+	}
+	db.Rows = &database.MockUser_SingleRow{PasswordHash: u.PasswordHash}
+	request.SetBasicAuth("jamie", "wrong")
+	if Authenticated(request, container) {
+		t.Error("Expected wrong password to be refused")
+	}
+
+	// Test API token belonging to a user
+	request.Header.Del("Authorization")
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	request.Header.Set("Authorization", "Bearer known")
+	if !Authenticated(request, container) {
+		t.Error("Expected API token to be accepted")
+	}
+}
+
+func TestCurrentUser(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.AdminToken = "admin-secret"
+	configuration.LockoutAttempts = 0
+	container := &app.Container{Configuration: configuration, Db: db}
+
+	// Test the admin token acts as an admin without an ID
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	if user := CurrentUser(request, container); user.ID != 0 || user.Role != model.RoleAdmin {
+		t.Error("Expected admin token to act as an admin")
+	}
+
+	// Test API tokens and passwords give their user
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleEditor})
+	request.Header.Set("Authorization", "Bearer known")
+	if user := CurrentUser(request, container); user.ID != 1 || user.Role != model.RoleEditor {
+		t.Error("Expected API token to give its user")
+	}
+	u := model.User{}
+	u.SetPassword("correct horse")
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: u.PasswordHash})
+	request.Header.Del("Authorization")
+	request.SetBasicAuth("jamie", "correct horse")
+	if user := CurrentUser(request, container); user.Username != "jamie" {
+		t.Error("Expected password to give its user")
+	}
+}
+
+func TestScope(t *testing.T) {
+	for method, scope := range map[string]string{"GET": model.ScopeRead, "HEAD": model.ScopeRead, "POST": model.ScopeWrite, "PUT": model.ScopeWrite, "DELETE": model.ScopeWrite} {
+		request, _ := http.NewRequest(method, "/", nil)
+		if Scope(request) != scope {
+			t.Errorf("Expected %s to need the %s scope", method, scope)
+		}
+	}
+}
+
+func TestSessionUser(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// Test no cookie never queries
+	request, _ := http.NewRequest("GET", "/", nil)
+	if SessionUser(request, container).ID > 0 || Authenticated(request, container) || db.Queries != 0 {
+		t.Error("Expected request without a session cookie to be refused")
+	}
+
+	// Test unknown or expired session
+	db.Rows = &database.MockRowsEmpty{}
+	request.AddCookie(&http.Cookie{Name: SessionCookie, Value: "unknown"})
+	if SessionUser(request, container).ID > 0 || db.Queries != 1 {
+		t.Error("Expected unknown session to be refused")
+	}
+
+	// Test session signs in its user
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	if user := SessionUser(request, container); user.Username != "jamie" {
+		t.Error("Expected session to sign in its user")
+	}
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	if !Authenticated(request, container) {
+		t.Error("Expected session to be accepted")
+	}
+}
+
+func TestStartSession(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db, BasePath: "/jamie"}
+	response := controller.NewMockResponse()
+	request, _ := http.NewRequest("POST", "/login", nil)
+	if err := StartSession(response, request, container, model.User{ID: 1}); err != nil || db.Queries != 1 {
+		t.Error("Expected session to be started")
+	}
+	cookie := response.Headers.Get("Set-Cookie")
+	if !strings.HasPrefix(cookie, SessionCookie+"=") || !strings.Contains(cookie, "Path=/jamie/; Expires=") || !strings.Contains(cookie, "HttpOnly; SameSite=Lax") || strings.Contains(cookie, "Secure") {
+		t.Errorf("Expected session cookie to be set, got %s", cookie)
+	}
+
+	db.ErrorMode = true
+	response.Reset()
+	if err := StartSession(response, request, container, model.User{ID: 1}); err == nil || response.Headers.Get("Set-Cookie") != "" {
+		t.Error("Expected no cookie when the session cannot be started")
+	}
+}
+
+func TestEndSession(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+
+	// Test the cookie is removed even without a session
+	request, _ := http.NewRequest("POST", "/logout", nil)
+	if err := EndSession(response, request, container); err != nil || db.Queries != 0 || !strings.Contains(response.Headers.Get("Set-Cookie"), "Max-Age=0") {
+		t.Error("Expected cookie to be removed")
+	}
+
+	// Test the session is ended
+	db.ExpectedArgument = model.HashToken("known")
+	request.AddCookie(&http.Cookie{Name: SessionCookie, Value: "known"})
+	if err := EndSession(response, request, container); err != nil || db.Queries != 1 {
+		t.Error("Expected session to be ended")
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"mime"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// CSRFCookie Name of the cookie holding the token each form posted from the journal must repeat
+const CSRFCookie = "journal_csrf"
+
+// CSRFHeader Header scripts may send the token in rather than a form field
+const CSRFHeader = "X-CSRF-Token"
+
+type csrfKey struct{}
+
+// CSRF Refuses posts another site could have made a browser send, such as forms posted as whoever is signed in, unless
+// they repeat the token the journal gave the browser in a cookie
+type CSRF struct {
+	Router    *pkgrouter.Router
+	Forbidden controller.Controller
+}
+
+// NewCSRF Create the protection for the journals served by the given router, showing refused posts the forbidden controller
+func NewCSRF(router *pkgrouter.Router, forbidden controller.Controller) *CSRF {
+	return &CSRF{Router: router, Forbidden: forbidden}
+}
+
+// Middleware Give each browser a token when it has none, and refuse posts that need one without it
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served := c.Router.ContainerFor(request)
+		container, _ := served.(*app.Container)
+		token := ""
+		if cookie, err := request.Cookie(CSRFCookie); err == nil && validCSRFToken(cookie.Value) {
+			token = cookie.Value
+		} else {
+			random := make([]byte, 32)
+			rand.Read(random)
+			token = hex.EncodeToString(random)
+			path := "/"
+			if container != nil {
+				path = container.BasePath + "/"
+			}
+			http.SetCookie(response, &http.Cookie{
+				Name:     CSRFCookie,
+				Value:    token,
+				Path:     path,
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				Secure:   proxy.Secure(request),
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		if CSRFNeeded(request) && !CSRFValid(request, token) {
+			forbidden := controller.New(c.Forbidden)
+			forbidden.Init(served, []string{})
+			forbidden.Run(response, request)
+			return
+		}
+
+		next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), csrfKey{}, token)))
+	})
+}
+
+// csrfExempt Paths whose posts carry credentials of their own rather than relying on cookies, such as the access tokens of
+// Micropub clients and the signatures of ActivityPub servers
+var csrfExempt = map[string]bool{"/activitypub/inbox": true, "/micropub": true}
+
+// CSRFNeeded Whether a request is a post a browser would send from another site without asking first, carrying the
+// cookies of whoever is signed in. Scripts sending a token of their own are trusted, but not Basic credentials, which
+// browsers remember and send again as they do cookies.
+func CSRFNeeded(request *http.Request) bool {
+	if request.Method != http.MethodPost || BearerToken(request) != "" || csrfExempt[request.URL.Path] {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	switch mediaType {
+	case "", "application/x-www-form-urlencoded", "multipart/form-data", "text/plain":
+		return true
+	}
+
+	return false
+}
+
+// CSRFValid Check a request repeats the token, in the header, the query or the form posted. Uploads carry it in the
+// query so their bodies are only read by controllers limiting their size.
+func CSRFValid(request *http.Request, token string) bool {
+	sent := request.Header.Get(CSRFHeader)
+	if sent == "" {
+		sent = request.URL.Query().Get(app.CSRFField)
+	}
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if sent == "" && mediaType == "application/x-www-form-urlencoded" {
+		request.ParseForm()
+		sent = request.PostForm.Get(app.CSRFField)
+	}
+
+	return token != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+// validCSRFToken Whether a cookie holds a token as given out, so nothing else sent in one reaches a page
+func validCSRFToken(value string) bool {
+	decoded, err := hex.DecodeString(value)
+
+	return err == nil && len(decoded) == 32
+}
+
+// CSRFToken Get the token the middleware found or gave the browser making a request, for the forms it is shown
+func CSRFToken(request *http.Request) string {
+	token, _ := request.Context().Value(csrfKey{}).(string)
+
+	return token
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestCSRF_Middleware(t *testing.T) {
+	container := &app.Container{BasePath: "/jamie"}
+	forbidden := &controller.MockController{}
+	csrf := NewCSRF(&pkgrouter.Router{Container: container}, forbidden)
+	token := strings.Repeat("ab", 32)
+	served := ""
+	handler := csrf.Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served = CSRFToken(request)
+	}))
+	response := controller.NewMockResponse()
+
+	// Test browsers without a token are given one
+	request, _ := http.NewRequest("GET", "/new", nil)
+	handler.ServeHTTP(response, request)
+	cookie := response.Headers.Get("Set-Cookie")
+	if len(served) != 64 || !strings.Contains(cookie, CSRFCookie+"="+served) || !strings.Contains(cookie, "Path=/jamie/") || !strings.Contains(cookie, "HttpOnly") {
+		t.Errorf("Expected new token to be given in a cookie, got %s", cookie)
+	}
+
+	// Test browsers keep their token, and cookies not holding one are replaced
+	response.Reset()
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	handler.ServeHTTP(response, request)
+	if served != token || response.Headers.Get("Set-Cookie") != "" {
+		t.Error("Expected token to be kept")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/new", nil)
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: strings.Repeat("<>", 32)})
+	handler.ServeHTTP(response, request)
+	if served == strings.Repeat("<>", 32) || response.Headers.Get("Set-Cookie") == "" {
+		t.Error("Expected cookie not holding a token to be replaced")
+	}
+
+	// Test forms posted without the token are forbidden
+	response.Reset()
+	served = ""
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader(url.Values{"title": {"Forged"}}.Encode()))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	handler.ServeHTTP(response, request)
+	if served != "" || !forbidden.HasRun || forbidden.Container != container {
+		t.Error("Expected form posted without the token to be forbidden")
+	}
+
+	// Test forms posted with Basic credentials a browser remembered are forbidden without the token
+	forbidden.HasRun = false
+	served = ""
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader(url.Values{"title": {"Forged"}}.Encode()))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth("jamie", "password")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	handler.ServeHTTP(response, request)
+	if served != "" || !forbidden.HasRun {
+		t.Error("Expected form posted with Basic credentials but without the token to be forbidden")
+	}
+
+	// Test forms posted with the token are served
+	forbidden.HasRun = false
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader(url.Values{"title": {"Real"}, app.CSRFField: {token}}.Encode()))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.AddCookie(&http.Cookie{Name: CSRFCookie, Value: token})
+	handler.ServeHTTP(response, request)
+	if served != token || forbidden.HasRun || request.FormValue("title") != "Real" {
+		t.Error("Expected form posted with the token to be served")
+	}
+}
+
+func TestCSRFNeeded(t *testing.T) {
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		header      string
+		expected    bool
+	}{
+		{"GET", "/new", "", "", false},
+		{"POST", "/new", "application/x-www-form-urlencoded", "", true},
+		{"POST", "/upload", "multipart/form-data; boundary=abc", "", true},
+		{"POST", "/api/journals", "text/plain", "", true},
+		{"POST", "/api/journals", "", "", true},
+		{"POST", "/api/journals", "application/json", "", false},
+		{"POST", "/new", "application/x-www-form-urlencoded", "Bearer jt_abc", false},
+		{"POST", "/new", "application/x-www-form-urlencoded", "Basic amFtaWU6cGFzc3dvcmQ=", true},
+		{"POST", "/micropub", "application/x-www-form-urlencoded", "", false},
+		{"POST", "/activitypub/inbox", "", "", false},
+		{"DELETE", "/api/journals/test/autosave", "", "", false},
+	}
+	for _, test := range tests {
+		request, _ := http.NewRequest(test.method, test.path, nil)
+		if test.contentType != "" {
+			request.Header.Add("Content-Type", test.contentType)
+		}
+		if test.header != "" {
+			request.Header.Add("Authorization", test.header)
+		}
+		if actual := CSRFNeeded(request); actual != test.expected {
+			t.Errorf("Expected %s %s as %s to need a token to be %v", test.method, test.path, test.contentType, test.expected)
+		}
+	}
+}
+
+func TestCSRFValid(t *testing.T) {
+	token := strings.Repeat("ab", 32)
+
+	// Test token in the header
+	request, _ := http.NewRequest("POST", "/new", nil)
+	request.Header.Add(CSRFHeader, token)
+	if !CSRFValid(request, token) {
+		t.Error("Expected token in the header to be accepted")
+	}
+
+	// Test token in the query, leaving uploads unread
+	body := strings.NewReader("--abc--")
+	request, _ = http.NewRequest("POST", "/upload?"+app.CSRFField+"="+token, body)
+	request.Header.Add("Content-Type", "multipart/form-data; boundary=abc")
+	if !CSRFValid(request, token) || body.Len() == 0 {
+		t.Error("Expected token in the query to be accepted without reading the upload")
+	}
+
+	// Test wrong or missing tokens
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader(app.CSRFField+"=wrong"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	if CSRFValid(request, token) {
+		t.Error("Expected wrong token to be refused")
+	}
+	request, _ = http.NewRequest("POST", "/new", nil)
+	if CSRFValid(request, "") {
+		t.Error("Expected missing token to be refused")
+	}
+}
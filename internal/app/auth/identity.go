@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/adapter/oidc"
+)
+
+// IdentityUser Get the user an account with an external provider signs in as, linking it the first time to the only
+// user with the same email, as long as the provider has verified it
+func IdentityUser(container *app.Container, provider string, identity oidc.Identity) (model.User, error) {
+	us := model.Users{Container: container}
+	is := model.Identities{Container: container}
+	if linked := is.FindBySubject(provider, identity.Subject); linked.ID > 0 {
+		user := us.FindByID(linked.UserID)
+		if user.ID == 0 {
+			return user, errors.New("The user linked to this account no longer exists")
+		}
+		return user, nil
+	}
+
+	if !identity.EmailVerified {
+		return model.User{}, errors.New("The provider has not verified the email of this account")
+	}
+	user := us.FindByEmail(identity.Email)
+	if user.ID == 0 {
+		return user, errors.New("No user, or more than one, has the email of this account")
+	}
+	if _, err := is.Link(user.ID, provider, identity.Subject); err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
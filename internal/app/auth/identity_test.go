@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/adapter/oidc"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestIdentityUser(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	identity := oidc.Identity{Subject: "248289761001", Email: "jamie@example.com", EmailVerified: true}
+
+	// Test account already linked
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockIdentity_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	if user, err := IdentityUser(container, "google", identity); err != nil || user.ID != 1 {
+		t.Error("Expected linked account to sign in as its user")
+	}
+	db.AppendResult(&database.MockIdentity_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if _, err := IdentityUser(container, "google", identity); err == nil {
+		t.Error("Expected error when the linked user has been removed")
+	}
+
+	// Test account linked by email
+	db.Queries = 0
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	if user, err := IdentityUser(container, "google", identity); err != nil || user.ID != 1 || db.Queries != 3 {
+		t.Error("Expected account to be linked to the user with its email")
+	}
+
+	// Test unverified and unknown emails
+	db.AppendResult(&database.MockRowsEmpty{})
+	if _, err := IdentityUser(container, "google", oidc.Identity{Subject: "1", Email: "jamie@example.com"}); err == nil {
+		t.Error("Expected unverified email to be refused")
+	}
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if _, err := IdentityUser(container, "google", identity); err == nil {
+		t.Error("Expected email nobody has to be refused")
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// Audit Record a security event, such as signing in or changing a password, from the address making the request
+func Audit(request *http.Request, container *app.Container, event model.SecurityEvent) error {
+	event.IP = proxy.ClientIP(request, proxy.ParseProxies(container.Configuration.TrustedProxies))
+	es := model.SecurityEvents{Container: container}
+	_, err := es.Record(event)
+
+	return err
+}
+
+// SignInLocked Whether signing in to an account, or from the address making the request, has been locked after too
+// many failed attempts
+func SignInLocked(request *http.Request, container *app.Container, username string) bool {
+	if container.Configuration.LockoutAttempts == 0 {
+		return false
+	}
+	es := model.SecurityEvents{Container: container}
+
+	return es.LockedOut(username, proxy.ClientIP(request, proxy.ParseProxies(container.Configuration.TrustedProxies)), lockoutWindow(container))
+}
+
+// SignInFailed Record a failed attempt to sign in, locking the account or the address making the request once either has
+// failed as many times as allowed within the window, and say whether it did
+func SignInFailed(request *http.Request, container *app.Container, username string, user model.User) bool {
+	ip := proxy.ClientIP(request, proxy.ParseProxies(container.Configuration.TrustedProxies))
+	es := model.SecurityEvents{Container: container}
+	es.Record(model.SecurityEvent{Kind: model.SecurityLoginFailed, UserID: user.ID, Username: username, IP: ip})
+	attempts := container.Configuration.LockoutAttempts
+	if attempts == 0 {
+		return false
+	}
+
+	account, address := es.Failures(username, ip, lockoutWindow(container))
+	locked := false
+	if account >= attempts {
+		es.Record(model.SecurityEvent{Kind: model.SecurityLockout, UserID: user.ID, Username: username, IP: ip, Detail: model.LockoutAccount})
+		locked = true
+	}
+	if address >= attempts {
+		es.Record(model.SecurityEvent{Kind: model.SecurityLockout, UserID: user.ID, Username: username, IP: ip, Detail: model.LockoutAddress})
+		locked = true
+	}
+
+	return locked
+}
+
+func lockoutWindow(container *app.Container) time.Duration {
+	return time.Duration(container.Configuration.LockoutMinutes) * time.Minute
+}
+
+type basicKey struct{}
+
+// basicAttempt The outcome of checking the username and password a request sent over HTTP Basic, kept so that they are
+// only checked, and only counted as a failed attempt, once however often the request asks who is signed in
+type basicAttempt struct {
+	checked bool
+	user    model.User
+}
+
+// BasicAttempts Middleware keeping the outcome of checking the username and password each request sends over HTTP Basic
+func BasicAttempts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), basicKey{}, &basicAttempt{})))
+	})
+}
+
+// basicUser Get the user whose username and password a request sent over HTTP Basic, checked as signing in with the
+// login form would be, so that they are refused while signing in is locked and recorded when wrong
+func basicUser(request *http.Request, container *app.Container, username string, password string) model.User {
+	attempt, _ := request.Context().Value(basicKey{}).(*basicAttempt)
+	if attempt != nil && attempt.checked {
+		return attempt.user
+	}
+
+	user := model.User{}
+	if !SignInLocked(request, container, username) {
+		us := model.Users{Container: container}
+		found := us.FindByUsername(username)
+		if found.ID > 0 && found.CheckPassword(password) {
+			user = found
+		} else {
+			SignInFailed(request, container, username, found)
+		}
+	}
+	if attempt != nil {
+		attempt.checked, attempt.user = true, user
+	}
+
+	return user
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSignInLocked(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	request, _ := http.NewRequest("POST", "/login", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	db.ExpectedArgument = "192.0.2.1"
+	if !SignInLocked(request, container, "jamie") {
+		t.Error("Expected sign in to be locked after a recent lockout")
+	}
+
+	// Test lockouts can be turned off
+	container.Configuration.LockoutAttempts = 0
+	db.Queries = 0
+	if SignInLocked(request, container, "jamie") || db.Queries != 0 {
+		t.Error("Expected sign in never to be locked when lockouts are off")
+	}
+}
+
+func TestSignInFailed(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	request, _ := http.NewRequest("POST", "/login", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	// Test failures below the limit are only recorded
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	if SignInFailed(request, container, "jamie", model.User{ID: 1}) || db.Queries != 3 {
+		t.Error("Expected failure to be recorded without locking")
+	}
+
+	// Test reaching the limit locks both the account and the address
+	db.Queries = 0
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 5})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 9})
+	if !SignInFailed(request, container, "jamie", model.User{ID: 1}) || db.Queries != 5 {
+		t.Error("Expected account and address to be locked")
+	}
+
+	// Test only recording when lockouts are off
+	container.Configuration.LockoutAttempts = 0
+	db.Queries = 0
+	if SignInFailed(request, container, "jamie", model.User{}) || db.Queries != 1 {
+		t.Error("Expected failure to be recorded without counting")
+	}
+}
+
+func TestAudit(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.TrustedProxies = "10.0.0.1"
+	request, _ := http.NewRequest("POST", "/login", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Forwarded-For", "192.0.2.1")
+	db.ExpectedArgument = "192.0.2.1"
+	if err := Audit(request, container, model.SecurityEvent{Kind: model.SecurityLogin, UserID: 1, Username: "jamie"}); err != nil || db.Queries != 1 {
+		t.Error("Expected event to be recorded from the forwarded address")
+	}
+}
+
+func TestBasicUser(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	u := model.User{}
+	u.SetPassword("correct horse")
+	db.EnableMultiMode()
+
+	// Test passwords are checked once for each request, however often it asks who is signed in
+	var request *http.Request
+	BasicAttempts(http.HandlerFunc(func(response http.ResponseWriter, r *http.Request) {
+		request = r
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	db.AppendResult(&database.MockPagination_Result{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: u.PasswordHash})
+	if user := basicUser(request, container, "jamie", "correct horse"); user.Username != "jamie" || db.Queries != 2 {
+		t.Error("Expected the right password to give its user")
+	}
+	if user := basicUser(request, container, "jamie", "correct horse"); user.Username != "jamie" || db.Queries != 2 {
+		t.Error("Expected the password to be checked only once for the request")
+	}
+
+	// Test wrong passwords count towards a lockout, and are refused while locked
+	request, _ = http.NewRequest("GET", "/", nil)
+	db.Queries = 0
+	db.AppendResult(&database.MockPagination_Result{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: u.PasswordHash})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	if user := basicUser(request, container, "jamie", "wrong"); user.ID != 0 || db.Queries != 5 {
+		t.Errorf("Expected the wrong password to be refused and recorded, got %d queries", db.Queries)
+	}
+	db.Queries = 0
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	if user := basicUser(request, container, "jamie", "correct horse"); user.ID != 0 || db.Queries != 1 {
+		t.Error("Expected the right password to be refused while signing in is locked")
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// required A controller only run for requests from a signed in user holding a role, sending anyone signed out to the
+// login form and anyone without the role to the forbidden controller
+type required struct {
+	controller.Controller
+	role      string
+	forbidden controller.Controller
+	container interface{}
+	params    []string
+}
+
+// Required Wrap a controller so only signed in users holding a role or one allowed more than it reach it, such as
+// editors writing entries or admins managing the journal
+func Required(role string, c controller.Controller, forbidden controller.Controller) controller.Controller {
+	return &required{Controller: c, role: role, forbidden: forbidden}
+}
+
+// Clone Copy the guard for a request along with the controllers it wraps
+func (r *required) Clone() controller.Controller {
+	return &required{Controller: controller.New(r.Controller), role: r.role, forbidden: controller.New(r.forbidden)}
+}
+
+// Init Initialise the wrapped controller, keeping the container to check the request against
+func (r *required) Init(app interface{}, params []string) {
+	r.container = app
+	r.params = params
+	r.Controller.Init(app, params)
+}
+
+// Run Run the wrapped controller when the request is from a signed in user holding the role
+func (r *required) Run(response http.ResponseWriter, request *http.Request) {
+	container, ok := r.container.(*app.Container)
+	if !ok || container == nil {
+		// Without the journal to check the request against, nobody can be known to hold the role
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	user := CurrentUser(request, container)
+	if user.Role == "" {
+		target := container.BasePath + "/login"
+		if request.Method == http.MethodGet {
+			target += "?next=" + url.QueryEscape(request.URL.Path)
+		}
+		http.Redirect(response, request, target, 302)
+		return
+	}
+	if !user.HasRole(r.role) {
+		r.forbidden.Init(r.container, r.params)
+		r.forbidden.Run(response, request)
+		return
+	}
+
+	r.Controller.Run(response, request)
+}
+
+// apiRequired An API controller only run for requests carrying the credentials of a user holding a role, answering
+// anyone else with 401 or 403 rather than sending them to the login form
+type apiRequired struct {
+	controller.Controller
+	role      string
+	container interface{}
+}
+
+// APIRequired Wrap an API controller so only users holding a role or one allowed more than it reach it, with an API
+// token sent having the scope the request needs
+func APIRequired(role string, c controller.Controller) controller.Controller {
+	return &apiRequired{Controller: c, role: role}
+}
+
+// Clone Copy the guard for a request along with the controller it wraps
+func (r *apiRequired) Clone() controller.Controller {
+	return &apiRequired{Controller: controller.New(r.Controller), role: r.role}
+}
+
+// Init Initialise the wrapped controller, keeping the container to check the request against
+func (r *apiRequired) Init(app interface{}, params []string) {
+	r.container = app
+	r.Controller.Init(app, params)
+}
+
+// Run Run the wrapped controller when the request carries the credentials of a user holding the role
+func (r *apiRequired) Run(response http.ResponseWriter, request *http.Request) {
+	container, ok := r.container.(*app.Container)
+	if !ok || container == nil {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	user, token := identify(request, container)
+	if user.Role == "" {
+		response.Header().Set("WWW-Authenticate", "Bearer")
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if (token.ID > 0 && !token.HasScope(Scope(request))) || !user.HasRole(r.role) {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	r.Controller.Run(response, request)
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	pkgcontroller "github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestRequired(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db, BasePath: "/jamie"}
+	wrapped := &controller.MockController{}
+	forbidden := &controller.MockController{}
+	guard := Required(model.RoleEditor, wrapped, forbidden)
+	guard.Init(container, []string{})
+	response := controller.NewMockResponse()
+	if wrapped.Container != container {
+		t.Error("Expected wrapped controller to have been initialised")
+	}
+
+	// Test anonymous requests are sent to the login form, returning to pages that were asked for
+	request, _ := http.NewRequest("GET", "/test/edit", nil)
+	guard.Run(response, request)
+	if wrapped.HasRun || response.Headers.Get("Location") != "/jamie/login?next=%2Ftest%2Fedit" {
+		t.Errorf("Expected anonymous request to be sent to the login form, got %s", response.Headers.Get("Location"))
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/test/delete", nil)
+	guard.Run(response, request)
+	if wrapped.HasRun || response.Headers.Get("Location") != "/jamie/login" {
+		t.Errorf("Expected anonymous post to be sent to the login form, got %s", response.Headers.Get("Location"))
+	}
+
+	// Test signed in requests without the role are forbidden
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleReader})
+	request.AddCookie(&http.Cookie{Name: SessionCookie, Value: "known"})
+	guard.Run(response, request)
+	if wrapped.HasRun || !forbidden.HasRun || forbidden.Container != container {
+		t.Error("Expected signed in request without the role to be forbidden")
+	}
+
+	// Test signed in requests with the role, or one allowed more, reach the controller
+	for _, role := range []string{model.RoleEditor, model.RoleAdmin} {
+		wrapped.HasRun = false
+		db.AppendResult(&database.MockSession_SingleRow{})
+		db.AppendResult(&database.MockUser_SingleRow{Role: role})
+		guard.Run(response, request)
+		if !wrapped.HasRun {
+			t.Errorf("Expected signed in request as %s to reach the controller", role)
+		}
+	}
+
+	// Test requests are forbidden without the journal to check them against
+	wrapped.HasRun = false
+	response.Reset()
+	guard.Init(nil, []string{})
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 403 {
+		t.Error("Expected request to be forbidden without the journal's container")
+	}
+}
+
+func TestAPIRequired(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	wrapped := &controller.MockController{}
+	guard := APIRequired(model.RoleEditor, wrapped)
+	guard.Init(container, []string{})
+	response := controller.NewMockResponse()
+
+	// Test anonymous requests and unknown tokens are unauthorised
+	request, _ := http.NewRequest("POST", "/api/journals", nil)
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 401 || response.Headers.Get("WWW-Authenticate") != "Bearer" {
+		t.Error("Expected anonymous request to be unauthorised")
+	}
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	request.Header.Set("Authorization", "Bearer unknown")
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 401 {
+		t.Error("Expected unknown token to be unauthorised")
+	}
+
+	// Test tokens without the scope, or users without the role, are forbidden
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockToken_SingleRow{Scopes: model.ScopeRead})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleEditor})
+	request.Header.Set("Authorization", "Bearer known")
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 403 {
+		t.Error("Expected token without the write scope to be forbidden")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleReader})
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 403 {
+		t.Error("Expected reader to be forbidden")
+	}
+
+	// Test tokens with the scope and the admin token reach the controller
+	response.Reset()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleEditor})
+	guard.Run(response, request)
+	if !wrapped.HasRun {
+		t.Error("Expected token with the write scope to reach the controller")
+	}
+	wrapped.HasRun = false
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	guard.Run(response, request)
+	if !wrapped.HasRun {
+		t.Error("Expected admin token to reach the controller")
+	}
+
+	// Test requests are forbidden without the journal to check them against
+	wrapped.HasRun = false
+	response.Reset()
+	guard.Init(nil, []string{})
+	guard.Run(response, request)
+	if wrapped.HasRun || response.StatusCode != 403 {
+		t.Error("Expected API request to be forbidden without the journal's container")
+	}
+}
+
+func TestRequired_Clone(t *testing.T) {
+	wrapped := &pkgcontroller.Super{}
+	forbidden := &pkgcontroller.Super{}
+	guard := Required(model.RoleEditor, wrapped, forbidden)
+	copied := pkgcontroller.New(guard).(*required)
+	if copied == guard || copied.Controller == wrapped || copied.forbidden == forbidden || copied.role != model.RoleEditor {
+		t.Error("Expected guard to be copied along with the controllers it wraps")
+	}
+
+	apiGuard := APIRequired(model.RoleEditor, wrapped)
+	apiCopied := pkgcontroller.New(apiGuard).(*apiRequired)
+	if apiCopied == apiGuard || apiCopied.Controller == wrapped || apiCopied.role != model.RoleEditor {
+		t.Error("Expected API guard to be copied along with the controller it wraps")
+	}
+}
@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/cron"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// JobType Name of the background job that backs up the database
+const JobType = "backup"
+
+// Errors refusing a backup or a restore
+var (
+	ErrNotSqlite = errors.New("Only SQLite databases can be backed up, use the database's own tools for others")
+	ErrNotBackup = errors.New("Not an SQLite database")
+)
+
+// header How every SQLite database file starts
+var header = []byte("SQLite format 3\x00")
+
+// nameFormat Time each backup is named after, so that they sort oldest first
+const nameFormat = "20060102-150405"
+
+// Enabled Whether a directory to keep backups in has been configured for an SQLite database
+func Enabled(container *app.Container) bool {
+	return container.Configuration.BackupPath != "" && database.DialectOf(container.Db) == database.DialectSqlite
+}
+
+// Interval How long to wait between each backup
+func Interval(container *app.Container) time.Duration {
+	return time.Duration(container.Configuration.BackupInterval) * time.Hour
+}
+
+// Backup Write a consistent copy of the database into a directory, using VACUUM INTO so the journal keeps serving
+// while it is taken, then remove all but the newest backups kept. Hosted journals are kept apart beneath tenants/.
+func Backup(container *app.Container, dir string) (string, error) {
+	if database.DialectOf(container.Db) != database.DialectSqlite {
+		return "", ErrNotSqlite
+	}
+	prefix := "journal"
+	if container.Tenant != "" {
+		dir = filepath.Join(dir, "tenants")
+		prefix = container.Tenant
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, prefix+"-"+time.Now().UTC().Format(nameFormat)+".db")
+	if _, err := container.Db.Exec("VACUUM INTO '" + strings.Replace(path, "'", "''", -1) + "'"); err != nil {
+		return "", err
+	}
+	container.Log().Info("Backed up the database", "path", path)
+
+	return path, Rotate(dir, prefix, container.Configuration.BackupKeep)
+}
+
+// Rotate Remove all but the newest backups of a database kept in a directory, keeping them all when keep is 0
+func Rotate(dir string, prefix string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	backups, err := List(dir, prefix)
+	if err != nil || len(backups) <= keep {
+		return err
+	}
+	for _, path := range backups[:len(backups)-keep] {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List Get the backups of a database kept in a directory, oldest first
+func List(dir string, prefix string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, prefix+"-*.db"))
+	if err != nil {
+		return []string{}, err
+	}
+	backups := []string{}
+	for _, path := range paths {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix+"-"), ".db")
+		if _, err := time.Parse(nameFormat, stamp); err == nil {
+			backups = append(backups, path)
+		}
+	}
+	sort.Strings(backups)
+
+	return backups, nil
+}
+
+// Restore Replace a database file with a backup, which must not be open. The file being replaced, and its write-ahead
+// log, are kept alongside it with .before-restore added to their names until the next restore.
+func Restore(path string, dbFile string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	start := make([]byte, len(header))
+	if _, err := io.ReadFull(source, start); err != nil || !bytes.Equal(start, header) {
+		return ErrNotBackup
+	}
+	if _, err := source.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Copy next to the database first, so that it is replaced in one step
+	temp, err := ioutil.TempFile(filepath.Dir(dbFile), filepath.Base(dbFile)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := io.Copy(temp, source); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	// The write-ahead log of the database being replaced goes with it, so it is not applied to the backup
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if _, err := os.Stat(dbFile + suffix); err != nil {
+			continue
+		}
+		if err := os.Rename(dbFile+suffix, dbFile+".before-restore"+suffix); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(temp.Name(), dbFile)
+}
+
+// Task Build the task backing up the journal and, when open is given, each hosted journal it opens, every interval
+func Task(open func(model.Tenant) (*app.Container, error)) cron.Task {
+	return cron.Task{
+		Name:     JobType,
+		Enabled:  Enabled,
+		Interval: Interval,
+		Run:      func(container *app.Container) error { return backupAll(container, open) },
+	}
+}
+
+// Handler Build the job handler backing up the journal and, when open is given, each hosted journal it opens, for a
+// backup queued to run straight away
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		return backupAll(container, open)
+	}
+}
+
+// backupAll Back up the journal and each hosted journal opened
+func backupAll(container *app.Container, open func(model.Tenant) (*app.Container, error)) error {
+	dir := container.Configuration.BackupPath
+	if _, err := Backup(container, dir); err != nil {
+		return err
+	}
+	if open == nil {
+		return nil
+	}
+	ts := model.Tenants{Container: container}
+	for _, t := range ts.FetchAll() {
+		hosted, err := open(t)
+		if err != nil {
+			return err
+		}
+		if _, err := Backup(hosted, dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
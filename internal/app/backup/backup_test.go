@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+// postgres A database speaking another dialect than SQLite's
+type postgres struct {
+	database.MockSqlite
+}
+
+func (p *postgres) Dialect() string {
+	return "postgres"
+}
+
+func tempDir(t *testing.T) string {
+	dir, _ := ioutil.TempDir("", "backup")
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: &database.MockSqlite{}}
+	if Enabled(container) {
+		t.Error("Expected backups to be disabled by default")
+	}
+	container.Configuration.BackupPath = "/backups"
+	if !Enabled(container) {
+		t.Error("Expected backups to be enabled with a directory")
+	}
+	container.Db = &postgres{}
+	if Enabled(container) {
+		t.Error("Expected only SQLite to be backed up")
+	}
+}
+
+func TestTask(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	task := Task(nil)
+
+	if task.Name != JobType || task.Enabled(container) {
+		t.Error("Expected backups not to be run when disabled")
+	}
+
+	container.Configuration.BackupPath = tempDir(t)
+	container.Configuration.BackupInterval = 6
+	if !task.Enabled(container) || task.Interval(container) != 6*time.Hour {
+		t.Error("Expected backups to be run every interval")
+	}
+	if err := task.Run(container); err != nil || db.Queries != 1 {
+		t.Errorf("Expected database to be backed up, got %d queries", db.Queries)
+	}
+	if err := Handler(nil)(container, model.Job{}); err != nil || db.Queries != 2 {
+		t.Error("Expected database to be backed up when queued")
+	}
+}
+
+func TestBackup(t *testing.T) {
+	dir := tempDir(t)
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	path, err := Backup(container, dir)
+	if err != nil || filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "journal-") || db.Queries != 1 {
+		t.Errorf("Expected database to be backed up into the directory, got %s and %v", path, err)
+	}
+
+	container.Tenant = "alice"
+	if path, _ := Backup(container, dir); filepath.Dir(path) != filepath.Join(dir, "tenants") || !strings.HasPrefix(filepath.Base(path), "alice-") {
+		t.Errorf("Expected hosted journal to be kept apart, got %s", path)
+	}
+
+	db.ErrorMode = true
+	if _, err := Backup(container, dir); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	if _, err := Backup(&app.Container{Db: &postgres{}}, dir); err != ErrNotSqlite {
+		t.Errorf("Expected other databases to be refused, got %v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := tempDir(t)
+	for _, name := range []string{"journal-20200101-100000.db", "journal-20200102-100000.db", "journal-20200103-100000.db", "journal-notes.db", "alice-20200101-100000.db"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0644)
+	}
+
+	if err := Rotate(dir, "journal", 0); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if backups, _ := List(dir, "journal"); len(backups) != 3 {
+		t.Errorf("Expected every backup to be kept without a limit, got %v", backups)
+	}
+	if err := Rotate(dir, "journal", 2); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	backups, _ := List(dir, "journal")
+	if len(backups) != 2 || filepath.Base(backups[0]) != "journal-20200102-100000.db" {
+		t.Errorf("Expected the oldest backup to be removed, got %v", backups)
+	}
+	for _, name := range []string{"journal-notes.db", "alice-20200101-100000.db"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be left alone", name)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := tempDir(t)
+	dbFile := filepath.Join(dir, "journal.db")
+	source := filepath.Join(dir, "backup.db")
+	ioutil.WriteFile(dbFile, []byte("SQLite format 3\x00current"), 0644)
+	ioutil.WriteFile(source, []byte("SQLite format 3\x00backup"), 0644)
+	ioutil.WriteFile(dbFile+"-wal", []byte("log"), 0644)
+
+	if err := Restore(source, dbFile); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	restored, _ := ioutil.ReadFile(dbFile)
+	previous, _ := ioutil.ReadFile(dbFile + ".before-restore")
+	if !strings.HasSuffix(string(restored), "backup") || !strings.HasSuffix(string(previous), "current") {
+		t.Errorf("Expected backup to replace the database, got %s", restored)
+	}
+	if _, err := os.Stat(dbFile + "-wal"); err == nil {
+		t.Error("Expected the write-ahead log of the replaced database to go with it")
+	}
+	if _, err := os.Stat(dbFile + ".before-restore-wal"); err != nil {
+		t.Error("Expected the write-ahead log to be kept alongside the replaced database")
+	}
+
+	ioutil.WriteFile(source, []byte("not a database"), 0644)
+	if err := Restore(source, dbFile); err != ErrNotBackup {
+		t.Errorf("Expected file that is not a database to be refused, got %v", err)
+	}
+	if err := Restore(filepath.Join(dir, "missing.db"), dbFile); err == nil {
+		t.Error("Expected missing backup to be refused")
+	}
+}
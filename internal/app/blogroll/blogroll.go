@@ -0,0 +1,341 @@
+package blogroll
+
+import (
+	"errors"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/cron"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/pkg/feed"
+	"github.com/jamiefdhurst/journal/pkg/opml"
+)
+
+// JobType Name of the background job that fetches followed feeds
+const JobType = "blogroll"
+
+// MaxItems Number of the newest items kept from each feed
+const MaxItems = 50
+
+// MaxSize Largest feed, in bytes, that will be read
+const MaxSize = 5 << 20
+
+// summaryWords Number of words kept from the summary of each item
+const summaryWords = 50
+
+// Timeout How long to wait for a feed before giving up on it until the next fetch
+var Timeout = 20 * time.Second
+
+// Errors refusing a feed
+var (
+	ErrExists     = errors.New("The feed is already followed")
+	ErrInvalidURL = errors.New("The feed needs a web address")
+)
+
+var tags = regexp.MustCompile("<[^>]*>")
+
+// Payload Which journal to fetch the feeds of, stored with the queued job
+type Payload struct {
+	Tenant string `json:"tenant,omitempty"`
+	// Once Fetch only the journal named, rather than every journal
+	Once bool `json:"once,omitempty"`
+}
+
+// Item An entry shown on the reading page, either from a followed feed or written in the journal
+type Item struct {
+	Title     string
+	URL       string
+	Summary   string
+	Source    string
+	SourceURL string
+	Published time.Time
+	Own       bool
+}
+
+// GetDate Get the friendly date the item was published
+func (i Item) GetDate() string {
+	if i.Published.IsZero() {
+		return ""
+	}
+
+	return i.Published.Format("January 2, 2006")
+}
+
+// Enabled Whether feeds are fetched in the background, which they are unless the interval has been set to 0
+func Enabled(container *app.Container) bool {
+	return container.Configuration.BlogrollInterval > 0
+}
+
+// Interval How long to wait between each fetch of the followed feeds
+func Interval(container *app.Container) time.Duration {
+	return time.Duration(container.Configuration.BlogrollInterval) * time.Minute
+}
+
+// Queue Fetch the feeds of a journal in the background straight away, such as after importing a list of them
+func Queue(container *app.Container) error {
+	js := model.Jobs{Container: container}
+	_, err := js.Enqueue(JobType, Payload{Tenant: container.Tenant, Once: true})
+
+	return err
+}
+
+// Subscribe Follow a feed, which will be fetched for the first time by the caller or on the next run
+func Subscribe(container *app.Container, address string) (model.Subscription, error) {
+	address = strings.TrimSpace(address)
+	if !model.IsValidLinkURL(address) || len(address) > 255 {
+		return model.Subscription{}, ErrInvalidURL
+	}
+	ss := model.Subscriptions{Container: container}
+	if ss.FindByURL(address).ID > 0 {
+		return model.Subscription{}, ErrExists
+	}
+
+	return ss.Save(model.Subscription{URL: address})
+}
+
+// Import Follow every feed listed in an OPML file that is not followed already, returning how many were added
+func Import(container *app.Container, r io.Reader) (int, error) {
+	doc, err := opml.Parse(r)
+	if err != nil {
+		return 0, err
+	}
+
+	ss := model.Subscriptions{Container: container}
+	added := 0
+	for _, o := range doc.Feeds() {
+		address := strings.TrimSpace(o.XMLURL)
+		if !model.IsValidLinkURL(address) || len(address) > 255 || ss.FindByURL(address).ID > 0 {
+			continue
+		}
+		s := model.Subscription{URL: address, Title: strings.TrimSpace(o.Name())}
+		if model.IsValidLinkURL(o.HTMLURL) {
+			s.SiteURL = o.HTMLURL
+		}
+		if _, err := ss.Save(s); err != nil {
+			return added, err
+		}
+		added++
+	}
+
+	return added, nil
+}
+
+// Export List every followed feed as an OPML outline
+func Export(container *app.Container) opml.Document {
+	doc := opml.Document{Title: container.Configuration.Title + " Blogroll"}
+	ss := model.Subscriptions{Container: container}
+	for _, s := range ss.FetchAll() {
+		doc.Outlines = append(doc.Outlines, opml.Outline{Text: s.Name(), Title: s.Name(), Type: "rss", XMLURL: s.URL, HTMLURL: s.SiteURL})
+	}
+
+	return doc
+}
+
+// Fetch Read a followed feed, storing the items not seen before and recording how the fetch went, and returning how
+// many items were new
+func Fetch(container *app.Container, s model.Subscription) (int, error) {
+	ss := model.Subscriptions{Container: container}
+	f, err := download(s.URL)
+	s.LastFetchedAt = time.Now().UTC().Format("2006-01-02 15:04:05")
+	if err != nil {
+		s.LastError = err.Error()
+		ss.Save(s)
+		return 0, err
+	}
+
+	s.LastError = ""
+	if f.Title != "" {
+		s.Title = strings.TrimSpace(html.UnescapeString(f.Title))
+	}
+	if model.IsValidLinkURL(f.Link) {
+		s.SiteURL = f.Link
+	}
+	if _, err := ss.Save(s); err != nil {
+		return 0, err
+	}
+
+	is := model.SubscriptionItems{Container: container}
+	added := 0
+	for n, e := range f.Entries {
+		if n >= MaxItems {
+			break
+		}
+		// Links are shown on the page, so only those to the web are kept
+		if !model.IsValidLinkURL(e.Link) {
+			continue
+		}
+		published := e.Published
+		if published.IsZero() {
+			published = time.Now()
+		}
+		title := strings.TrimSpace(html.UnescapeString(e.Title))
+		if title == "" {
+			title = e.Link
+		}
+		summary := e.Summary
+		if summary == "" {
+			summary = e.Content
+		}
+		ok, err := is.Add(model.SubscriptionItem{SubscriptionID: s.ID, Title: title, URL: e.Link, Summary: summarise(summary), PublishedAt: published.UTC().Format("2006-01-02 15:04:05")})
+		if err != nil {
+			return added, err
+		}
+		if ok {
+			added++
+		}
+	}
+
+	return added, is.Prune(s.ID, MaxItems)
+}
+
+// Refresh Fetch every followed feed, recording any that fail against them rather than stopping the rest
+func Refresh(container *app.Container) int {
+	ss := model.Subscriptions{Container: container}
+	added := 0
+	for _, s := range ss.FetchAll() {
+		n, err := Fetch(container, s)
+		if err != nil {
+			container.Log().Warn("Could not fetch the feed", "url", s.URL, "err", err)
+		}
+		added += n
+	}
+
+	return added
+}
+
+// Reading The latest items from followed feeds alongside the latest entries in the journal, newest first
+func Reading(container *app.Container, limit int) []Item {
+	items := []Item{}
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	for _, j := range js.FetchLatest(limit) {
+		items = append(items, Item{
+			Title:     j.Title,
+			URL:       container.BasePath + "/" + j.Slug,
+			Summary:   j.GetExcerpt(),
+			Source:    container.Configuration.Title,
+			SourceURL: container.BasePath + "/",
+			Published: j.GetTime(),
+			Own:       true,
+		})
+	}
+	is := model.SubscriptionItems{Container: container}
+	for _, i := range is.FetchLatest(limit) {
+		items = append(items, Item{Title: i.Title, URL: i.URL, Summary: i.Summary, Source: i.Source, SourceURL: i.SourceURL, Published: i.GetTime()})
+	}
+
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].Published.After(items[b].Published)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+// Task Build the task fetching the feeds of the journal and, when open is given, each hosted journal it opens, every
+// interval
+func Task(open func(model.Tenant) (*app.Container, error)) cron.Task {
+	return cron.Task{
+		Name:     JobType,
+		Enabled:  Enabled,
+		Interval: Interval,
+		Run:      func(container *app.Container) error { return refreshAll(container, open) },
+	}
+}
+
+// Handler Build the job handler fetching the feeds of one journal, or of the journal and, when open is given, each
+// hosted journal it opens
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		payload := Payload{}
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Once {
+			if payload.Tenant != "" && open != nil {
+				ts := model.Tenants{Container: container}
+				t := ts.FindByName(payload.Tenant)
+				if t.ID == 0 {
+					return nil
+				}
+				hosted, err := open(t)
+				if err != nil {
+					return err
+				}
+				container = hosted
+			}
+			Refresh(container)
+			return nil
+		}
+
+		return refreshAll(container, open)
+	}
+}
+
+// refreshAll Fetch the feeds of the journal and each hosted journal opened
+func refreshAll(container *app.Container, open func(model.Tenant) (*app.Container, error)) error {
+	Refresh(container)
+	if open == nil {
+		return nil
+	}
+	ts := model.Tenants{Container: container}
+	for _, t := range ts.FetchAll() {
+		hosted, err := open(t)
+		if err != nil {
+			return err
+		}
+		Refresh(hosted)
+	}
+
+	return nil
+}
+
+// download Fetch and read a feed
+func download(address string) (feed.Feed, error) {
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return feed.Feed{}, err
+	}
+	request.Header.Add("User-Agent", "Journal")
+	request.Header.Add("Accept", "application/atom+xml, application/rss+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+
+	client := http.Client{Timeout: Timeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return feed.Feed{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return feed.Feed{}, errors.New("The feed could not be fetched: " + response.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, MaxSize+1))
+	if err != nil {
+		return feed.Feed{}, err
+	}
+	if len(data) > MaxSize {
+		return feed.Feed{}, errors.New("The feed is larger than allowed")
+	}
+
+	return feed.Parse(data)
+}
+
+// summarise Reduce the HTML summary of an item to the start of its text
+func summarise(source string) string {
+	text := html.UnescapeString(tags.ReplaceAllString(source, " "))
+	words := strings.Fields(text)
+	if len(words) > summaryWords {
+		return strings.Join(words[:summaryWords], " ") + "..."
+	}
+
+	return strings.Join(words, " ")
+}
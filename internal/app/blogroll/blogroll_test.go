@@ -0,0 +1,183 @@
+package blogroll
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+const rss = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Alice &amp; Friends</title><link>https://alice.example.com/</link>
+<item><title>First</title><link>https://alice.example.com/first</link><description>&lt;p&gt;Hello &lt;b&gt;world&lt;/b&gt;&lt;/p&gt;</description><pubDate>Thu, 15 Feb 2018 12:00:00 GMT</pubDate></item>
+<item><title></title><link>https://alice.example.com/untitled</link></item>
+<item><title>Sneaky</title><link>javascript:alert(1)</link></item>
+</channel></rss>`
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if !Enabled(container) || Interval(container).Minutes() != 60 {
+		t.Error("Expected feeds to be fetched hourly by default")
+	}
+	container.Configuration.BlogrollInterval = 0
+	if Enabled(container) {
+		t.Error("Expected fetching to be disabled without an interval")
+	}
+}
+
+func TestTask(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	task := Task(nil)
+
+	if task.Name != JobType || !task.Enabled(container) || task.Interval(container) != time.Hour {
+		t.Error("Expected feeds to be fetched hourly")
+	}
+	if err := task.Run(container); err != nil || db.Queries != 1 {
+		t.Errorf("Expected feeds to be fetched, got %d queries", db.Queries)
+	}
+
+	container.Configuration.BlogrollInterval = 0
+	if task.Enabled(container) {
+		t.Error("Expected feeds not to be fetched when disabled")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	if _, err := Subscribe(container, "javascript:alert(1)"); err != ErrInvalidURL {
+		t.Error("Expected an address that is not on the web to be refused")
+	}
+	s, err := Subscribe(container, " https://alice.example.com/feed ")
+	if err != nil || s.URL != "https://alice.example.com/feed" || db.Queries != 2 {
+		t.Errorf("Expected feed to be followed, got %d queries", db.Queries)
+	}
+
+	db.Rows = &database.MockSubscription_SingleRow{}
+	if _, err := Subscribe(container, "https://alice.example.com/feed"); err != ErrExists {
+		t.Error("Expected a feed already followed to be refused")
+	}
+}
+
+func TestImport(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockSubscription_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+
+	document := `<opml version="2.0"><body>
+<outline text="Alice" xmlUrl="https://alice.example.com/feed"/>
+<outline text="Friends"><outline text="Bob" xmlUrl="https://bob.example.com/atom.xml" htmlUrl="https://bob.example.com/"/></outline>
+<outline text="Broken" xmlUrl="file:///etc/passwd"/>
+</body></opml>`
+	added, err := Import(container, strings.NewReader(document))
+	if err != nil || added != 1 || db.Queries != 3 {
+		t.Errorf("Expected only the new feed to be imported, got %d added and %d queries", added, db.Queries)
+	}
+
+	if _, err := Import(container, strings.NewReader("<html></html>")); err == nil {
+		t.Error("Expected a file that is not OPML to be refused")
+	}
+}
+
+func TestExport(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockSubscription_MultipleRows{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	doc := Export(container)
+	if doc.Title != "Jamie's Journal Blogroll" || len(doc.Outlines) != 2 {
+		t.Fatalf("Expected both feeds to be exported, got %v", doc)
+	}
+	if doc.Outlines[0].Text != "Alice's Blog" || doc.Outlines[0].HTMLURL != "https://alice.example.com/" || doc.Outlines[1].Text != "https://bob.example.com/atom.xml" {
+		t.Errorf("Expected feeds to be named, got %v", doc.Outlines)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "Journal" {
+			t.Error("Expected the journal to name itself")
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// Saving the feed, adding the two items with web links and pruning
+	added, err := Fetch(container, model.Subscription{ID: 1, URL: server.URL + "/feed"})
+	if err != nil || added != 2 || db.Queries != 4 {
+		t.Errorf("Expected items to be added, got %d added and %d queries", added, db.Queries)
+	}
+
+	// Failures are recorded against the feed
+	db.Queries = 0
+	if _, err := Fetch(container, model.Subscription{ID: 1, URL: server.URL + "/missing"}); err == nil || db.Queries != 1 {
+		t.Error("Expected error to be returned and recorded")
+	}
+}
+
+func TestReading(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockSubscriptionItem_MultipleRows{})
+
+	items := Reading(container, 3)
+	if len(items) != 3 {
+		t.Fatalf("Expected the limit to be kept to, got %d items", len(items))
+	}
+	if items[0].Title != "Title 2" || !items[0].Own || items[0].URL != "/slug-2" {
+		t.Errorf("Expected the newest entry first, got %v", items[0])
+	}
+	if items[1].Title != "Alice's Post" || items[1].Own || items[1].Source != "Alice's Blog" {
+		t.Errorf("Expected items from feeds alongside entries, got %v", items[1])
+	}
+	if items[2].Title != "Title" || items[2].GetDate() != "February 1, 2018" {
+		t.Errorf("Expected the oldest entry last, got %v", items[2])
+	}
+}
+
+func TestHandler(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// Every journal's feeds are fetched
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Handler(nil)(container, model.Job{Payload: "null"}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected fetch to run, got %d queries", db.Queries)
+	}
+
+	// A single journal's feeds are fetched
+	db.Queries = 0
+	if err := Handler(nil)(container, model.Job{Payload: `{"once":true}`}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected a single fetch, got %d queries", db.Queries)
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	if s := summarise("<p>Fish &amp; <b>chips</b></p>\n<p>Tonight</p>"); s != "Fish & chips Tonight" {
+		t.Errorf("Expected markup to be removed, got %s", s)
+	}
+	if s := summarise(strings.Repeat("word ", 60)); !strings.HasSuffix(s, "...") || len(strings.Fields(s)) != 50 {
+		t.Errorf("Expected summary to be shortened, got %s", s)
+	}
+}
@@ -0,0 +1,62 @@
+package canonical
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// Host Sends pages asked for at another address than the journal's own, such as over HTTP or without www., to the same
+// page at its own address, so that each page is only known to search engines by one
+type Host struct {
+	Router *pkgrouter.Router
+}
+
+// NewHost Create the redirects for the journals served by the given router, each to the address it is configured with
+func NewHost(router *pkgrouter.Router) *Host {
+	return &Host{Router: router}
+}
+
+// Middleware Redirect pages asked for at another address before they are served, when the journal is configured to.
+// Probes are answered wherever they are made, as they are usually made at an internal address.
+func (h *Host) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		container, ok := h.Router.ContainerFor(request).(*app.Container)
+		if ok && container != nil && container.Configuration.CanonicalRedirect && (request.Method == http.MethodGet || request.Method == http.MethodHead) && !web.IsProbe(request.URL.Path) {
+			if target := Redirect(request, container.Configuration); target != "" {
+				http.Redirect(response, request, target, http.StatusMovedPermanently)
+				return
+			}
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// Redirect Get the address of the page asked for at the journal's own scheme and host, or nothing when it was already
+// asked for there or the journal has no address. Journals hosted on subdomains only have their scheme changed.
+func Redirect(request *http.Request, config app.Configuration) string {
+	own, err := url.Parse(config.URL)
+	if err != nil || own.Scheme == "" || own.Host == "" {
+		return ""
+	}
+	host := own.Host
+	if config.TenantMode == app.TenantModeSubdomain {
+		host = request.Host
+	}
+	if strings.EqualFold(proxy.Scheme(request), own.Scheme) && strings.EqualFold(request.Host, host) {
+		return ""
+	}
+
+	uri := request.RequestURI
+	if uri == "" {
+		uri = request.URL.RequestURI()
+	}
+
+	return strings.ToLower(own.Scheme) + "://" + host + uri
+}
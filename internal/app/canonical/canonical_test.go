@@ -0,0 +1,102 @@
+package canonical
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestHost_Middleware(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	container.Configuration.URL = "https://journal.example.com"
+	served := false
+	handler := NewHost(&pkgrouter.Router{Container: container}).Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served = true
+	}))
+
+	// Test nothing is redirected unless configured to
+	response := controller.NewMockResponse()
+	request, _ := http.NewRequest("GET", "http://www.example.com/test?page=2", nil)
+	handler.ServeHTTP(response, request)
+	if !served || response.Headers.Get("Location") != "" {
+		t.Error("Expected request to be served when not redirecting")
+	}
+
+	// Test pages elsewhere are sent to the journal's own address
+	container.Configuration.CanonicalRedirect = true
+	served = false
+	request.RequestURI = "/test?page=2"
+	handler.ServeHTTP(response, request)
+	if served || response.StatusCode != http.StatusMovedPermanently || response.Headers.Get("Location") != "https://journal.example.com/test?page=2" {
+		t.Errorf("Expected redirect to the journal's own address, got %d and '%s'", response.StatusCode, response.Headers.Get("Location"))
+	}
+
+	// Test posts are left alone, as redirecting would lose what they send
+	response.Reset()
+	request, _ = http.NewRequest("POST", "http://www.example.com/new", nil)
+	handler.ServeHTTP(response, request)
+	if !served || response.Headers.Get("Location") != "" {
+		t.Error("Expected post to be served")
+	}
+
+	// Test probes are answered at any address
+	response.Reset()
+	served = false
+	request, _ = http.NewRequest("GET", "http://10.0.0.5:3000/readyz", nil)
+	handler.ServeHTTP(response, request)
+	if !served || response.Headers.Get("Location") != "" {
+		t.Error("Expected probe to be served")
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	config := app.DefaultConfiguration()
+	config.URL = "https://journal.example.com"
+	config.TrustedProxies = "10.0.0.0/8"
+
+	tables := []struct {
+		url      string
+		tls      bool
+		remote   string
+		proto    string
+		tenants  string
+		expected string
+	}{
+		{"https://journal.example.com/test", true, "1.2.3.4:5000", "", "", ""},
+		{"http://journal.example.com/test", false, "1.2.3.4:5000", "", "", "https://journal.example.com/test"},
+		{"http://journal.example.com/test", false, "1.2.3.4:5000", "https", "", "https://journal.example.com/test"},
+		{"http://journal.example.com/test", false, "10.0.0.1:5000", "https", "", ""},
+		{"http://JOURNAL.example.com/test", false, "10.0.0.1:5000", "HTTPS", "", ""},
+		{"https://www.example.com/a%20b?x=1", true, "1.2.3.4:5000", "", "", "https://journal.example.com/a%20b?x=1"},
+		{"https://jamie.example.com/test", true, "1.2.3.4:5000", "", app.TenantModeSubdomain, ""},
+		{"http://jamie.example.com/test", false, "1.2.3.4:5000", "", app.TenantModeSubdomain, "https://jamie.example.com/test"},
+	}
+
+	for _, table := range tables {
+		request, _ := http.NewRequest("GET", table.url, nil)
+		request.RemoteAddr = table.remote
+		if table.tls {
+			request.TLS = &tls.ConnectionState{}
+		}
+		if table.proto != "" {
+			request.Header.Set("X-Forwarded-Proto", table.proto)
+		}
+		config.TenantMode = table.tenants
+		request = proxy.Forward(request, proxy.ParseProxies(config.TrustedProxies))
+		if actual := Redirect(request, config); actual != table.expected {
+			t.Errorf("Expected %s to be sent to '%s', got '%s'", table.url, table.expected, actual)
+		}
+	}
+
+	// Test journals without an address are never redirected
+	config.URL = ""
+	request, _ := http.NewRequest("GET", "http://www.example.com/test", nil)
+	if actual := Redirect(request, config); actual != "" {
+		t.Errorf("Expected no redirect without an address, got '%s'", actual)
+	}
+}
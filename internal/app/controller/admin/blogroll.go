@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/blogroll"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// maxOPMLSize Largest OPML file accepted for import
+const maxOPMLSize = 1 << 20
+
+// blogrollError Why the blogroll may not have been updated
+const blogrollError = "The blogroll could not be updated. Feeds need a web address and can only be followed once, and imports need an OPML file."
+
+// Blogroll Follow and unfollow feeds, import and export them as OPML and fetch them on demand
+type Blogroll struct {
+	controller.Super
+	Subscriptions []model.Subscription
+}
+
+// Run Blogroll action
+func (c *Blogroll) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "POST" {
+		c.post(container, response, request)
+		return
+	}
+
+	ss := model.Subscriptions{Container: container}
+	c.Subscriptions = ss.FetchAll()
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/blogroll.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+func (c *Blogroll) post(container *app.Container, response http.ResponseWriter, request *http.Request) {
+	redirect := container.BasePath + "/admin/blogroll"
+	ss := model.Subscriptions{Container: container}
+
+	switch request.FormValue("action") {
+	case "add":
+		s, err := blogroll.Subscribe(container, request.FormValue("url"))
+		if err != nil {
+			flash.Error(response, request, container, blogrollError)
+			http.Redirect(response, request, redirect, 302)
+			return
+		}
+		// Fetch straight away so the feed is named and its items can be read, keeping it to retry later on failure
+		if _, err := blogroll.Fetch(container, s); err != nil {
+			container.Log().Warn("Could not fetch the feed", "url", s.URL, "err", err)
+		}
+	case "delete":
+		id, _ := strconv.Atoi(request.FormValue("id"))
+		s := ss.FindByID(id)
+		if s.ID == 0 || ss.Delete(s) != nil {
+			flash.Error(response, request, container, blogrollError)
+			http.Redirect(response, request, redirect, 302)
+			return
+		}
+	case "import":
+		request.Body = http.MaxBytesReader(response, request.Body, maxOPMLSize+1<<20)
+		file, _, err := request.FormFile("opml")
+		if err != nil {
+			flash.Error(response, request, container, blogrollError)
+			http.Redirect(response, request, redirect, 302)
+			return
+		}
+		defer file.Close()
+		added, err := blogroll.Import(container, file)
+		if err != nil {
+			flash.Error(response, request, container, blogrollError)
+			http.Redirect(response, request, redirect, 302)
+			return
+		}
+		if added > 0 {
+			blogroll.Queue(container)
+			flash.Add(response, request, container, app.Flash{Kind: app.FlashSuccess, Text: container.N(added, "Imported %d feeds, which will be fetched shortly.")})
+		} else {
+			flash.Info(response, request, container, "No feeds were imported, as the file has none that are not already followed.")
+		}
+		http.Redirect(response, request, redirect, 302)
+		return
+	case "refresh":
+		if err := blogroll.Queue(container); err != nil {
+			flash.Error(response, request, container, blogrollError)
+			http.Redirect(response, request, redirect, 302)
+			return
+		}
+		flash.Info(response, request, container, "Feeds will be fetched shortly.")
+		http.Redirect(response, request, redirect, 302)
+		return
+	default:
+		flash.Error(response, request, container, blogrollError)
+		http.Redirect(response, request, redirect, 302)
+		return
+	}
+
+	flash.Success(response, request, container, "Blogroll updated.")
+	http.Redirect(response, request, redirect, 302)
+}
@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestBlogroll_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Blogroll{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/blogroll", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test feeds are listed
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockSubscription_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Alice&#39;s Blog") || !strings.Contains(response.Content, "https://bob.example.com/atom.xml") || !strings.Contains(response.Content, "Never") {
+		t.Error("Expected feeds to be displayed with when they were fetched")
+	}
+
+	// Test empty list
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "No feeds are followed yet") {
+		t.Error("Expected empty list to be displayed")
+	}
+
+	// Test following a feed, kept even when it cannot be fetched yet
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	response.Reset()
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/blogroll", strings.NewReader("action=add&url="+server.URL+"/feed"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashSuccess) || db.Queries != 3 {
+		t.Errorf("Expected feed to be followed, got %d queries", db.Queries)
+	}
+
+	// Test an invalid address is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/blogroll", strings.NewReader("action=add&url=ftp://example.com/feed"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashError) {
+		t.Error("Expected invalid address to be refused")
+	}
+
+	// Test an unknown feed cannot be unfollowed
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/blogroll", strings.NewReader("action=delete&id=9"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashError) {
+		t.Error("Expected unknown feed to be refused")
+	}
+
+	// Test unfollowing a feed removes its items too
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockSubscription_SingleRow{}
+	request, _ = http.NewRequest("POST", "/admin/blogroll", strings.NewReader("action=delete&id=1"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashSuccess) || db.Queries != 3 {
+		t.Errorf("Expected feed to be unfollowed, got %d queries", db.Queries)
+	}
+
+	// Test importing an OPML file queues the new feeds to be fetched
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("action", "import")
+	part, _ := writer.CreateFormFile("opml", "feeds.opml")
+	part.Write([]byte(`<opml version="2.0"><body><outline text="Alice" xmlUrl="https://alice.example.com/feed"/></body></opml>`))
+	writer.Close()
+	request, _ = http.NewRequest("POST", "/admin/blogroll", body)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+	controller.Run(response, request)
+	if left := flash.Left(response); response.Headers.Get("Location") != "/admin/blogroll" || len(left) != 1 || left[0].Text != "Imported 1 feed, which will be fetched shortly." {
+		t.Errorf("Expected feed to be imported, got %s", response.Headers.Get("Location"))
+	}
+
+	// Test a file that is not OPML is refused
+	response.Reset()
+	body = &bytes.Buffer{}
+	writer = multipart.NewWriter(body)
+	writer.WriteField("action", "import")
+	part, _ = writer.CreateFormFile("opml", "feeds.opml")
+	part.Write([]byte("Not OPML"))
+	writer.Close()
+	request, _ = http.NewRequest("POST", "/admin/blogroll", body)
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashError) {
+		t.Error("Expected file that is not OPML to be refused")
+	}
+
+	// Test fetching every feed on demand
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/blogroll", strings.NewReader("action=refresh"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/blogroll" || !flashed(response, app.FlashInfo) {
+		t.Error("Expected feeds to be queued for fetching")
+	}
+}
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// categoryError Why a category may not have been saved
+const categoryError = "The category could not be saved. It needs a name and cannot be nested beneath itself."
+
+// Categories Add, rename, nest and remove the categories entries are filed in
+type Categories struct {
+	controller.Super
+	Categories []model.Category
+}
+
+// Run Categories action
+func (c *Categories) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	cs := model.Categories{Container: container}
+	if request.Method == "POST" {
+		id, _ := strconv.Atoi(request.FormValue("id"))
+		parentID, _ := strconv.Atoi(request.FormValue("parent_id"))
+		category := model.Category{}
+		if id > 0 {
+			category = cs.FindByID(id)
+			if category.ID == 0 {
+				flash.Error(response, request, container, categoryError)
+				http.Redirect(response, request, container.BasePath+"/admin/categories", 302)
+				return
+			}
+		}
+
+		if request.FormValue("action") == "delete" {
+			cs.Delete(category)
+		} else {
+			category.Name = request.FormValue("name")
+			category.ParentID = parentID
+			if _, err := cs.Save(category); err != nil {
+				flash.Error(response, request, container, categoryError)
+				http.Redirect(response, request, container.BasePath+"/admin/categories", 302)
+				return
+			}
+		}
+		flash.Success(response, request, container, "Categories updated.")
+		http.Redirect(response, request, container.BasePath+"/admin/categories", 302)
+		return
+	}
+
+	c.Categories = cs.FetchTree()
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/categories.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
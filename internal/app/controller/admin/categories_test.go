@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCategories_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Categories{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/categories", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test categories are listed as a tree
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockCategory_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `— <input type="text" name="name" value="Europe"`) || !strings.Contains(response.Content, "Add category") {
+		t.Error("Expected nested categories to be displayed with the form to add more")
+	}
+
+	// Test empty list
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There are no categories yet") {
+		t.Error("Expected empty list to be displayed")
+	}
+
+	// Test adding a category
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("POST", "/admin/categories", strings.NewReader("name=Travel&parent_id=0&action=save"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/categories" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected category to be added")
+	}
+
+	// Test a category without a name is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/categories", strings.NewReader("name=&action=save"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/categories" || !flashed(response, app.FlashError) {
+		t.Error("Expected error when name is missing")
+	}
+
+	// Test deleting a category
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockCategory_SingleRow{}
+	request, _ = http.NewRequest("POST", "/admin/categories", strings.NewReader("id=1&action=delete"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/categories" || !flashed(response, app.FlashSuccess) || db.Queries != 4 {
+		t.Error("Expected category to be deleted")
+	}
+
+	// Test unknown category
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("POST", "/admin/categories", strings.NewReader("id=9&action=delete"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/categories" || !flashed(response, app.FlashError) {
+		t.Error("Expected error when category not found")
+	}
+}
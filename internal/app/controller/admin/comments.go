@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/spam"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Comments Moderate comments left by readers, approving them, marking them as spam or deleting them
+type Comments struct {
+	controller.Super
+	Comments []model.Comment
+	Status   string
+	Statuses []string
+}
+
+// Run Comments action
+func (c *Comments) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	cs := model.Comments{Container: container}
+	c.Statuses = []string{model.CommentStatusPending, model.CommentStatusSpam, model.CommentStatusApproved}
+	c.Status = model.CommentStatusPending
+	for _, status := range c.Statuses {
+		if request.FormValue("status") == status {
+			c.Status = status
+		}
+	}
+
+	if request.Method == "POST" {
+		id, _ := strconv.Atoi(request.FormValue("id"))
+		comment := cs.FindByID(id)
+		if comment.ID > 0 {
+			moderate(container, cs, comment, request.FormValue("action"))
+		}
+		http.Redirect(response, request, container.BasePath+"/admin/comments?status="+c.Status, 302)
+		return
+	}
+
+	c.Comments = cs.FetchByStatus(c.Status)
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/comments.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// moderate Apply a moderator's decision, letting the spam API learn from any it got wrong
+func moderate(container *app.Container, cs model.Comments, comment model.Comment, action string) {
+	switch action {
+	case "approve":
+		cs.SetStatus(comment, model.CommentStatusApproved)
+		if comment.Status == model.CommentStatusSpam && spam.Enabled(container) {
+			spam.Report(container, comment.SpamCheck(container.URL("/"+comment.JournalSlug)), false)
+		}
+	case "spam":
+		cs.SetStatus(comment, model.CommentStatusSpam)
+		if comment.Status != model.CommentStatusSpam && spam.Enabled(container) {
+			spam.Report(container, comment.SpamCheck(container.URL("/"+comment.JournalSlug)), true)
+		}
+	case "delete":
+		cs.Delete(comment)
+	}
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestComments_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Comments{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/comments", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test moderation queue is listed and escaped
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.ExpectedArgument = "pending"
+	db.Rows = &database.MockComment_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Great post") || !strings.Contains(response.Content, `href="/slug">Title</a>`) || strings.Contains(response.Content, "<script>") {
+		t.Error("Expected comments awaiting moderation to be displayed")
+	}
+	if !strings.Contains(response.Content, `value="spam"`) || !strings.Contains(response.Content, `value="delete"`) {
+		t.Error("Expected moderation actions to be offered")
+	}
+
+	// Test spam folder and empty message
+	response.Reset()
+	db.ExpectedArgument = "spam"
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("GET", "/admin/comments?status=spam", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There are no comments here") {
+		t.Error("Expected empty spam folder message")
+	}
+
+	// Test approving a comment
+	response.Reset()
+	db.Queries = 0
+	db.ExpectedArgument = ""
+	db.Rows = &database.MockComment_SingleRow{}
+	request, _ = http.NewRequest("POST", "/admin/comments", strings.NewReader("id=4&action=approve&status=pending"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/comments?status=pending" || db.Queries != 2 {
+		t.Error("Expected comment to be approved and redirect back to the queue")
+	}
+
+	// Test deleting from the spam folder
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockComment_SingleRow{Status: "spam"}
+	request, _ = http.NewRequest("POST", "/admin/comments", strings.NewReader("id=4&action=delete&status=spam"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/comments?status=spam" || db.Queries != 2 {
+		t.Error("Expected comment to be deleted")
+	}
+
+	// Test unknown comment does nothing
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("POST", "/admin/comments", strings.NewReader("id=9&action=delete"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if db.Queries != 1 {
+		t.Error("Expected nothing to happen for an unknown comment")
+	}
+}
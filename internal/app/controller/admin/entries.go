@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Bulk actions that may be applied to the selected entries
+const (
+	bulkTrash    = "trash"
+	bulkCategory = "category"
+	bulkTag      = "tag"
+	bulkPublish  = "publish"
+	bulkDraft    = "draft"
+)
+
+// Entries List every entry to manage them in bulk, moving many to the trash, filing or tagging them or publishing them
+// at once
+type Entries struct {
+	controller.Super
+	Categories []model.Category
+	Journals   []model.Journal
+	Pages      []int
+	Pagination database.PaginationInformation
+}
+
+// Run Entries action
+func (c *Entries) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	cs := model.Categories{Container: container}
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	if page, err := strconv.Atoi(request.FormValue("page")); err == nil && page > 0 {
+		pagination.Page = page
+	}
+	back := container.BasePath + "/admin/entries?page=" + strconv.Itoa(pagination.Page)
+
+	if request.Method == "POST" {
+		updated, ok := applyBulk(container, js, cs, request)
+		if !ok {
+			flash.Error(response, request, container, "Choose an action to apply to the selected entries.")
+			http.Redirect(response, request, back, 302)
+			return
+		}
+		if updated > 0 {
+			flash.Add(response, request, container, app.Flash{Kind: app.FlashSuccess, Text: container.N(updated, "%d entries updated.")})
+		}
+		http.Redirect(response, request, back, 302)
+		return
+	}
+
+	c.Categories = cs.FetchTree()
+	c.Journals, c.Pagination = js.FetchPaginatedAll(pagination)
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/entries.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// applyBulk Apply the chosen action to every selected entry, returning how many were changed, or false if the
+// action could not be understood
+func applyBulk(container *app.Container, js model.Journals, cs model.Categories, request *http.Request) (int, bool) {
+	store := model.Store(container)
+	action := request.FormValue("action")
+	categoryID := 0
+	tags := model.ParseTags(request.FormValue("tags"))
+	switch action {
+	case bulkTrash, bulkPublish, bulkDraft:
+	case bulkCategory:
+		id, _ := strconv.Atoi(request.FormValue("category_id"))
+		if id > 0 {
+			if categoryID = cs.FindByID(id).ID; categoryID == 0 {
+				return 0, false
+			}
+		}
+	case bulkTag:
+		if len(tags) == 0 {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	updated := 0
+	for _, value := range request.Form["id"] {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		journal := js.FindByID(id)
+		if journal.ID == 0 {
+			continue
+		}
+
+		if action == bulkTag {
+			ts := model.JournalTags{Container: container}
+			journal = ts.Load(journal)
+		}
+		previous := journal
+		switch action {
+		case bulkTrash:
+			if js.Trash(journal) != nil {
+				continue
+			}
+			webhook.Deleted(container, journal)
+			updated++
+			continue
+		case bulkCategory:
+			journal.CategoryID = categoryID
+		case bulkTag:
+			journal.Tags = model.ParseTags(strings.Join(append(journal.Tags, tags...), ","))
+		case bulkPublish:
+			journal.Status = model.JournalStatusPublished
+		case bulkDraft:
+			journal.Status = model.JournalStatusDraft
+		}
+		if action == bulkTag {
+			journal, err = tagJournal(container, journal)
+		} else {
+			journal, err = store.Update(journal)
+		}
+		if err != nil {
+			continue
+		}
+		if action == bulkPublish {
+			ping.Notify(container, journal)
+			federation.Notify(container, journal)
+		}
+		webhook.Updated(container, previous, journal)
+		updated++
+	}
+
+	return updated, true
+}
+
+// tagJournal Save an entry along with the tags it now carries, in one transaction so that its file and tags agree
+func tagJournal(container *app.Container, journal model.Journal) (model.Journal, error) {
+	err := container.Transaction(func(tx *app.Container) error {
+		var err error
+		if journal, err = model.Store(tx).Update(journal); err != nil {
+			return err
+		}
+		ts := model.JournalTags{Container: tx}
+
+		return ts.Save(journal)
+	})
+
+	return journal, err
+}
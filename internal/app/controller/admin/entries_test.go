@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func bulkRequest(body string) *http.Request {
+	request, _ := http.NewRequest("POST", "/admin/entries", strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	return request
+}
+
+func TestEntries_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Entries{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/entries", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test every entry is listed with a checkbox, the actions and pages
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/admin/entries", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="id" value="1"`) || !strings.Contains(response.Content, `name="id" value="2"`) || !strings.Contains(response.Content, "data-select-all") {
+		t.Error("Expected entries to be listed with checkboxes")
+	}
+	if !strings.Contains(response.Content, `value="trash"`) || !strings.Contains(response.Content, `value="tag"`) || !strings.Contains(response.Content, `<option value="3">Cooking</option>`) || !strings.Contains(response.Content, "/admin/entries?page=2") {
+		t.Error("Expected bulk actions, categories and pages to be shown")
+	}
+	// Test selected entries are moved to the trash, skipping any not found
+	response.Reset()
+	db.Queries = 0
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, bulkRequest("action=trash&id=1&id=2&id=abc&page=2"))
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/entries?page=2" || !flashed(response, app.FlashSuccess) || db.Queries != 4 {
+		t.Errorf("Expected found entry to be moved to the trash, got %s", response.Headers.Get("Location"))
+	}
+
+	// Test entries are filed under a category, published and returned to drafts
+	response.Reset()
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, bulkRequest("action=category&category_id=1&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected entry to be filed under the category")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.Queries = 0
+	controller.Run(response, bulkRequest("action=tag&tags=travel%2C+Family&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected entry to be tagged")
+	}
+	if db.Queries != 8 {
+		t.Errorf("Expected entry to keep its tags and be given the new one, got %d queries", db.Queries)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{Status: "draft"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockWebhook_SingleRow{})
+	db.Queries = 0
+	controller.Run(response, bulkRequest("action=publish&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected entry to be published")
+	}
+	if db.Queries != 5 {
+		t.Errorf("Expected entry to be saved and the published event to be queued, got %d queries", db.Queries)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, bulkRequest("action=draft&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected entry to be returned to drafts")
+	}
+
+	// Test unknown actions and categories, and tagging without tags, are refused
+	response.Reset()
+	db.Queries = 0
+	controller.Run(response, bulkRequest("action=explode&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashError) || db.Queries != 0 {
+		t.Error("Expected unknown action to be refused")
+	}
+	response.Reset()
+	controller.Run(response, bulkRequest("action=tag&tags=+%2C+&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashError) || db.Queries != 0 {
+		t.Error("Expected tagging without tags to be refused")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, bulkRequest("action=category&category_id=9&id=1"))
+	if response.Headers.Get("Location") != "/admin/entries?page=1" || !flashed(response, app.FlashError) || db.Queries != 1 {
+		t.Error("Expected unknown category to be refused")
+	}
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Jobs Display the background job queue and the tasks run on an interval, allowing failed jobs to be retried and tasks
+// to be run now
+type Jobs struct {
+	controller.Super
+	Jobs       []model.Job
+	Tasks      []model.ScheduledTask
+	Pages      []int
+	Pagination database.PaginationInformation
+}
+
+// Run Jobs action
+func (c *Jobs) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	js := model.Jobs{Container: container}
+	ts := model.ScheduledTasks{Container: container}
+
+	// The queue is shared by every hosted journal, so only the journal itself may see or retry its jobs
+	if request.Method == "POST" {
+		id, err := strconv.Atoi(request.FormValue("retry"))
+		if err == nil && container.Tenant == "" && js.Retry(id) == nil {
+			flash.Info(response, request, container, "Job %d will be run again.", id)
+		}
+		if name := request.FormValue("run"); name != "" && container.Tenant == "" && ts.FindByName(name).Name != "" {
+			if _, err := js.Enqueue(name, nil); err != nil {
+				container.Log().Warn("Could not queue task to run now", "task", name, "err", err)
+			} else {
+				flash.Info(response, request, container, "The %s task will be run now.", name)
+			}
+		}
+		http.Redirect(response, request, container.BasePath+"/admin/jobs", 302)
+		return
+	}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+
+	// Tasks are only scheduled for the journal itself, running for each hosted journal in turn
+	if container.Tenant == "" {
+		c.Tasks = ts.FetchAll()
+		c.Jobs, c.Pagination = js.FetchPaginated(pagination)
+	}
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/jobs.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
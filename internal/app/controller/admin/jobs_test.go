@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJobs_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Jobs{}
+
+	// Test listing jobs
+	controller.Init(container, []string{""})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockScheduledTask_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJob_MultipleRows{})
+	request, _ := http.NewRequest("GET", "/admin/jobs", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "export") || !strings.Contains(response.Content, "Simulated failure") {
+		t.Error("Expected jobs to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, "name=\"retry\" value=\"2\"") {
+		t.Error("Expected retry option for failed job")
+	}
+	if !strings.Contains(response.Content, "Every day") || !strings.Contains(response.Content, "disk full") {
+		t.Error("Expected scheduled tasks to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, "name=\"run\" value=\"backup\"") {
+		t.Error("Expected option to run task now")
+	}
+
+	// Test empty queue
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	request, _ = http.NewRequest("GET", "/admin/jobs", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "no jobs") {
+		t.Error("Expected empty queue message to be displayed")
+	}
+
+	// Test retry redirects
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/jobs", strings.NewReader("retry=2"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/jobs" {
+		t.Error("Expected redirect back to jobs page")
+	}
+
+	// Test running a task now queues it
+	response.Reset()
+	db.MultiMode = false
+	db.Rows = &database.MockScheduledTask_SingleRow{}
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/jobs", strings.NewReader("run=backup"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || db.Queries != 2 || !flashed(response, app.FlashInfo) {
+		t.Errorf("Expected task to be found and queued, got %d queries", db.Queries)
+	}
+
+	// Test hosted journals do not list, retry or run the jobs and tasks they share
+	response.Reset()
+	container.Tenant = "alice"
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/jobs", strings.NewReader("run=backup"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if db.Queries != 0 {
+		t.Error("Expected hosted journal not to run tasks")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/jobs", strings.NewReader("retry=2"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if db.Queries != 0 || flashed(response, app.FlashInfo) {
+		t.Error("Expected hosted journal not to retry jobs")
+	}
+	response.Reset()
+	db.Rows = &database.MockJob_MultipleRows{}
+	request, _ = http.NewRequest("GET", "/admin/jobs", strings.NewReader(""))
+	controller.Run(response, request)
+	if db.Queries != 0 || strings.Contains(response.Content, "Simulated failure") {
+		t.Error("Expected hosted journal not to list jobs")
+	}
+}
+
+// flashed Check whether a response leaves a message of a kind for the next page
+func flashed(response http.ResponseWriter, kind string) bool {
+	left := flash.Left(response)
+
+	return len(left) == 1 && left[0].Kind == kind
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/maintenance"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Maintenance Put the journal into maintenance, refusing changes during backups and migrations, or take it out again.
+// Hosted journals share their host's maintenance, so only its own admins may switch it.
+type Maintenance struct {
+	controller.Super
+	On bool
+}
+
+// Run Maintenance action
+func (c *Maintenance) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if container.Maintenance == nil || container.Tenant != "" {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "POST" {
+		on := request.FormValue("on") == "1"
+		maintenance.Switch(container, on)
+		if on {
+			flash.Success(response, request, container, "Maintenance switched on.")
+		} else {
+			flash.Success(response, request, container, "Maintenance switched off.")
+		}
+		http.Redirect(response, request, container.BasePath+"/admin/maintenance", 302)
+		return
+	}
+
+	c.On = container.Maintenance.On()
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/maintenance.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestMaintenance_Run(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	response := controller.NewMockResponse()
+	controller := &Maintenance{}
+	controller.Init(container, []string{""})
+
+	// Test not found without a switch
+	request, _ := http.NewRequest("GET", "/admin/maintenance", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 without a maintenance switch")
+	}
+
+	// Test the switch is shown
+	response.Reset()
+	container.Maintenance = app.NewMaintenance(false)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Switch On") || strings.Contains(response.Content, `class="maintenance"`) {
+		t.Error("Expected maintenance to be shown as off")
+	}
+
+	// Test switching maintenance on
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/maintenance", strings.NewReader("on=1"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/maintenance" || !flashed(response, app.FlashSuccess) || !container.Maintenance.On() {
+		t.Error("Expected maintenance to be switched on")
+	}
+	if left := flash.Left(response); len(left) != 1 || left[0].Text != "Maintenance switched on." {
+		t.Errorf("Expected maintenance switched on to be shown next, got %v", left)
+	}
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/admin/maintenance", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Switch Off") || !strings.Contains(response.Content, `class="maintenance"`) {
+		t.Error("Expected maintenance to be shown as on, with its notice")
+	}
+
+	// Test switching maintenance off
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/maintenance", strings.NewReader("on=0"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if container.Maintenance.On() {
+		t.Error("Expected maintenance to be switched off")
+	}
+
+	// Test hosted journals cannot switch their host's maintenance
+	response.Reset()
+	container.Tenant = "alice"
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 for a hosted journal")
+	}
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// securityKinds Kinds of security event admins may list on their own
+var securityKinds = []string{model.SecurityLogin, model.SecurityLoginFailed, model.SecurityLockout, model.SecurityPasswordChanged}
+
+// Security Display the audit log of sign ins, failed attempts, lockouts and password changes
+type Security struct {
+	controller.Super
+	Events     []model.SecurityEvent
+	Kind       string
+	Kinds      []string
+	Pages      []int
+	Pagination database.PaginationInformation
+}
+
+// Run Security action
+func (c *Security) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	es := model.SecurityEvents{Container: container}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+	c.Kinds = securityKinds
+	c.Kind = ""
+	for _, kind := range securityKinds {
+		if query.Get("kind") == kind {
+			c.Kind = kind
+		}
+	}
+
+	c.Events, c.Pagination = es.FetchPaginated(c.Kind, pagination)
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/security.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
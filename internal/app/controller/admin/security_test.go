@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSecurity_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Security{}
+
+	// Test listing events, escaping what was typed as a username
+	controller.Init(container, []string{""})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockSecurityEvent_MultipleRows{})
+	request, _ := http.NewRequest("GET", "/admin/security", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "security-lockout") || !strings.Contains(response.Content, "192.0.2.1") || !strings.Contains(response.Content, "&lt;nobody&gt; <small>(unknown)</small>") {
+		t.Error("Expected events to be displayed on screen")
+	}
+
+	// Test filtering by a known kind only
+	response.Reset()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	db.ExpectedArgument = "lockout"
+	request, _ = http.NewRequest("GET", "/admin/security?kind=lockout", strings.NewReader(""))
+	controller.Run(response, request)
+	if controller.Kind != "lockout" || !strings.Contains(response.Content, "Nothing has been recorded") {
+		t.Error("Expected events to be filtered by kind")
+	}
+	response.Reset()
+	db.ExpectedArgument = ""
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	request, _ = http.NewRequest("GET", "/admin/security?kind=unknown", strings.NewReader(""))
+	controller.Run(response, request)
+	if controller.Kind != "" {
+		t.Error("Expected unknown kind to be ignored")
+	}
+}
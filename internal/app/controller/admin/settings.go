@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/i18n"
+)
+
+// settingsError Why settings may not have been saved
+const settingsError = "The settings could not be saved, as they would leave the journal misconfigured. Numbers must be at least 1 and the URL an http or https address, which some features need. The log gives the details."
+
+// Settings Change the title, description, address, paging, theme, language and feeds of the journal while it runs, saving them
+// in its database in place of those configured
+type Settings struct {
+	controller.Super
+	Settings  map[string]string
+	Themes    []string
+	Languages []*i18n.Catalog
+}
+
+// Run Settings action
+func (c *Settings) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "POST" {
+		values := map[string]string{}
+		for _, name := range app.EditableSettings {
+			values[name] = strings.TrimSpace(request.FormValue(name))
+		}
+		if problems := container.Configuration.CheckSettings(values); len(problems) > 0 {
+			for _, problem := range problems {
+				container.Log().Warn("Could not save settings", "err", problem)
+			}
+			flash.Error(response, request, container, settingsError)
+			http.Redirect(response, request, container.BasePath+"/admin/settings", 302)
+			return
+		}
+
+		ss := model.Settings{Container: container}
+		for _, name := range app.EditableSettings {
+			if err := ss.Save(name, values[name]); err != nil {
+				flash.Error(response, request, container, settingsError)
+				http.Redirect(response, request, container.BasePath+"/admin/settings", 302)
+				return
+			}
+		}
+		flash.Success(response, request, container, "Settings saved.")
+		http.Redirect(response, request, container.BasePath+"/admin/settings", 302)
+		return
+	}
+
+	c.Settings = map[string]string{}
+	for _, name := range app.EditableSettings {
+		c.Settings[name] = container.Settings.Get(name)
+	}
+	c.Themes = container.Configuration.Themes()
+	c.Languages = []*i18n.Catalog{}
+	for _, locale := range app.Languages.Locales() {
+		c.Languages = append(c.Languages, app.Languages[locale])
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/settings.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSettings_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db, Settings: app.NewSettings(map[string]string{app.SettingFeedSummaries: "1", app.SettingTitle: "Alice's Journal"})}
+	response := controller.NewMockResponse()
+	controller := &Settings{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/settings", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test saved settings are shown alongside those configured
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="title" value="Alice&#39;s Journal"`) || !strings.Contains(response.Content, `placeholder="20"`) ||
+		!strings.Contains(response.Content, `<option value="1" selected>Summaries only</option>`) || !strings.Contains(response.Content, `<option value="default">default</option>`) {
+		t.Error("Expected saved settings, configured values and themes to be displayed")
+	}
+
+	// Test saving settings, removing those left empty
+	response.Reset()
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/settings", strings.NewReader("title=+Bob%27s+Journal+&articles_per_page=5&theme=default&feed_summaries="))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/settings" || !flashed(response, app.FlashSuccess) || db.Queries != len(app.EditableSettings) {
+		t.Error("Expected settings to be saved")
+	}
+	if container.Settings.Get(app.SettingTitle) != "Bob's Journal" || container.Settings.Get(app.SettingArticlesPerPage) != "5" || container.Settings.Get(app.SettingFeedSummaries) != "" {
+		t.Error("Expected saved settings to be used from then on")
+	}
+
+	// Test settings that would misconfigure the journal are refused
+	response.Reset()
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/settings", strings.NewReader("articles_per_page=none&url=journal.example.com"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/settings" || !flashed(response, app.FlashError) || db.Queries != 0 {
+		t.Error("Expected error when settings are invalid")
+	}
+
+	// Test error saving
+	response.Reset()
+	db.ErrorMode = true
+	request, _ = http.NewRequest("POST", "/admin/settings", strings.NewReader("title=Bob"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/settings" || !flashed(response, app.FlashError) {
+		t.Error("Expected error when settings cannot be saved")
+	}
+}
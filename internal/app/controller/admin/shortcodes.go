@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// shortcodeError Why a shortcode may not have been saved
+const shortcodeError = "The shortcode could not be saved. Its name can only use lower case letters, numbers, dashes and underscores, must not be built in or already in use, and it needs something to show."
+
+// Shortcodes Add, change and remove the custom :shortcodes: that can be written in entries
+type Shortcodes struct {
+	controller.Super
+	Shortcodes []model.Shortcode
+}
+
+// Run Shortcodes action
+func (c *Shortcodes) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	ss := model.Shortcodes{Container: container}
+	if request.Method == "POST" {
+		id, _ := strconv.Atoi(request.FormValue("id"))
+		shortcode := model.Shortcode{}
+		if id > 0 {
+			shortcode = ss.FindByID(id)
+			if shortcode.ID == 0 {
+				flash.Error(response, request, container, shortcodeError)
+				http.Redirect(response, request, container.BasePath+"/admin/shortcodes", 302)
+				return
+			}
+		}
+
+		if request.FormValue("action") == "delete" {
+			ss.Delete(shortcode)
+		} else {
+			shortcode.Name = request.FormValue("name")
+			shortcode.Value = request.FormValue("value")
+			if _, err := ss.Save(shortcode); err != nil {
+				flash.Error(response, request, container, shortcodeError)
+				http.Redirect(response, request, container.BasePath+"/admin/shortcodes", 302)
+				return
+			}
+		}
+		flash.Success(response, request, container, "Shortcodes updated.")
+		http.Redirect(response, request, container.BasePath+"/admin/shortcodes", 302)
+		return
+	}
+
+	c.Shortcodes = ss.FetchAll()
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/shortcodes.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
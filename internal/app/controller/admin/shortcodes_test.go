@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestShortcodes_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Shortcodes{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/shortcodes", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test shortcodes are listed
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockShortcode_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="name" value="company"`) || !strings.Contains(response.Content, `value="Acme &amp; Sons"`) || !strings.Contains(response.Content, "Add shortcode") {
+		t.Error("Expected shortcodes to be displayed with the form to add more")
+	}
+
+	// Test empty list
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There are no custom shortcodes yet") {
+		t.Error("Expected empty list to be displayed")
+	}
+
+	// Test adding a shortcode
+	response.Reset()
+	db.Queries = 0
+	request, _ = http.NewRequest("POST", "/admin/shortcodes", strings.NewReader("name=shipit&value=%F0%9F%90%BF&action=save"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/admin/shortcodes" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected shortcode to be added")
+	}
+
+	// Test a built-in name is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/shortcodes", strings.NewReader("name=smile&value=x&action=save"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/shortcodes" || !flashed(response, app.FlashError) {
+		t.Error("Expected built-in name to be refused")
+	}
+
+	// Test an unknown shortcode is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/shortcodes", strings.NewReader("id=9&action=delete"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/shortcodes" || !flashed(response, app.FlashError) {
+		t.Error("Expected unknown shortcode to be refused")
+	}
+
+	// Test deleting a shortcode
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockShortcode_SingleRow{}
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/shortcodes" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected shortcode to be deleted")
+	}
+}
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Stats Display a report of the journal's entries
+type Stats struct {
+	controller.Super
+	Stats model.Stats
+}
+
+// Run Stats action
+func (c *Stats) Run(response http.ResponseWriter, request *http.Request) {
+	ss := model.Statistics{Container: c.Super.Container.(*app.Container)}
+	c.Stats = ss.Fetch()
+
+	template, err := c.Super.Container.(*app.Container).Templates("_layout/default.tmpl", "admin/stats.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestStats_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Stats{}
+
+	// Test report
+	controller.Init(container, []string{""})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockStatsTotals_Row{})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018", "2019"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018-01"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"1"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"09"}})
+	db.AppendResult(&database.MockStatsDays_Rows{Days: []string{"2018-01-01", "2018-01-02"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"Road Trip", "Travel"}})
+	request, _ := http.NewRequest("GET", "/admin/stats", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "1000") || !strings.Contains(response.Content, "2 days") || !strings.Contains(response.Content, "width: 50%") || !strings.Contains(response.Content, "09:00") {
+		t.Error("Expected report to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, `<a href="/tag/road-trip">Road Trip</a>`) {
+		t.Error("Expected busiest tags to be linked")
+	}
+
+	// Test empty journal
+	response.Reset()
+	db.MultiMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There are no entries to report on yet.") {
+		t.Error("Expected empty message to be shown")
+	}
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// userError Why a user may not have been saved
+const userError = "The user could not be saved. Usernames must be 2-64 letters, numbers, hyphens or underscores and not already taken, passwords at least 8 characters, and the last admin must stay an admin."
+
+// Users Add users and assign each of them a role
+type Users struct {
+	controller.Super
+	Roles []string
+	Users []model.User
+}
+
+// Run Users action
+func (c *Users) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		web.RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	us := model.Users{Container: container}
+	if request.Method == "POST" {
+		if err := c.save(us, request); err != nil {
+			flash.Error(response, request, container, userError)
+			http.Redirect(response, request, container.BasePath+"/admin/users", 302)
+			return
+		}
+		flash.Success(response, request, container, "Users updated.")
+		http.Redirect(response, request, container.BasePath+"/admin/users", 302)
+		return
+	}
+
+	c.Roles = model.Roles
+	c.Users = us.FetchAll()
+
+	template, err := container.Templates("_layout/default.tmpl", "admin/users.tmpl")
+	if err != nil {
+		web.RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// save Change an existing user's role, never leaving the journal without an admin, or add a new user
+func (c *Users) save(us model.Users, request *http.Request) error {
+	id, _ := strconv.Atoi(request.FormValue("id"))
+	role := request.FormValue("role")
+	if id > 0 {
+		user := us.FindByID(id)
+		if user.ID == 0 {
+			return errors.New("User not found")
+		}
+		if user.Role == model.RoleAdmin && role != model.RoleAdmin && us.CountAdmins() <= 1 {
+			return errors.New("The last admin cannot be given another role")
+		}
+		user.Role = role
+		_, err := us.Save(user)
+
+		return err
+	}
+
+	user := model.User{Username: request.FormValue("username"), Email: request.FormValue("email"), Role: role}
+	if err := user.SetPassword(request.FormValue("password")); err != nil {
+		return err
+	}
+	_, err := us.Save(user)
+
+	return err
+}
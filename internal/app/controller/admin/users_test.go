@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUsers_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableEdit = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Users{}
+
+	// Test not found when editing is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/admin/users", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test users are listed with their roles
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockUser_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<td>friend</td>") || !strings.Contains(response.Content, `<option value="editor" selected>editor</option>`) || !strings.Contains(response.Content, "Add user") {
+		t.Error("Expected users to be displayed with their roles and the form to add more")
+	}
+
+	// Test an unknown user is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/users", strings.NewReader("id=9&role=editor"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/users" || !flashed(response, app.FlashError) {
+		t.Error("Expected unknown user to be refused")
+	}
+
+	// Test the last admin keeps their role
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	request, _ = http.NewRequest("POST", "/admin/users", strings.NewReader("id=1&role=reader"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/users" || !flashed(response, app.FlashError) {
+		t.Error("Expected last admin to keep their role")
+	}
+
+	// Test assigning a role
+	response.Reset()
+	db.EnableMultiMode()
+	db.Queries = 0
+	db.AppendResult(&database.MockUser_SingleRow{Role: "reader", PasswordHash: "hash"})
+	request, _ = http.NewRequest("POST", "/admin/users", strings.NewReader("id=1&role=editor"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/users" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected role to be assigned")
+	}
+
+	// Test adding a user with a short password is refused
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/admin/users", strings.NewReader("username=friend&email=friend%40example.com&password=short&role=editor"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/users" || !flashed(response, app.FlashError) {
+		t.Error("Expected short password to be refused")
+	}
+
+	// Test adding a user
+	response.Reset()
+	db.EnableMultiMode()
+	db.Queries = 0
+	db.AppendResult(&database.MockRowsEmpty{})
+	request, _ = http.NewRequest("POST", "/admin/users", strings.NewReader("username=friend&email=friend%40example.com&password=password123&role=editor"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/admin/users" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected user to be added")
+	}
+}
@@ -0,0 +1,42 @@
+package apiadmin
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+// authorise Check the request carries the bootstrap admin token or a token
+// belonging to an admin with the scope needed for the method, responding with
+// 401 or 403 when it does not
+func authorise(response http.ResponseWriter, request *http.Request, container *app.Container) bool {
+	response.Header().Set("Content-Type", "application/json")
+	plain := auth.BearerToken(request)
+	adminToken := container.Configuration.AdminToken
+	if plain != "" && adminToken != "" && subtle.ConstantTimeCompare([]byte(plain), []byte(adminToken)) == 1 {
+		return true
+	}
+
+	ts := model.Tokens{Container: container}
+	token := ts.Authenticate(plain)
+	if token.ID == 0 {
+		response.Header().Set("WWW-Authenticate", "Bearer")
+		response.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	scope := model.ScopeWrite
+	if request.Method == "GET" {
+		scope = model.ScopeRead
+	}
+	us := model.Users{Container: container}
+	if us.FindByID(token.UserID).Role != model.RoleAdmin || !token.HasScope(scope) {
+		response.WriteHeader(http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
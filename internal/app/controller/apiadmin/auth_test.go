@@ -0,0 +1,73 @@
+package apiadmin
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+const testAdminToken = "bootstrap-secret"
+
+func adminRequest(method string, url string, body string) *http.Request {
+	var reader io.Reader = strings.NewReader(body)
+	request, _ := http.NewRequest(method, url, reader)
+	request.Header.Add("Authorization", "Bearer "+testAdminToken)
+	request.Header.Add("Content-Type", "application/json")
+	return request
+}
+
+func adminContainer(db *database.MockSqlite) *app.Container {
+	configuration := app.DefaultConfiguration()
+	configuration.AdminToken = testAdminToken
+	return &app.Container{Configuration: configuration, Db: db}
+}
+
+func TestAuthorise(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+
+	// Test bootstrap token
+	if !authorise(response, adminRequest("GET", "/api/admin/users", ""), container) {
+		t.Error("Expected bootstrap admin token to be accepted")
+	}
+
+	// Test missing token
+	request, _ := http.NewRequest("GET", "/api/admin/users", nil)
+	if authorise(response, request, container) || response.StatusCode != 401 {
+		t.Error("Expected 401 when no token is provided")
+	}
+
+	// Test admin user token
+	response.Reset()
+	request.Header.Set("Authorization", "Bearer jt_user")
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: "admin"})
+	if !authorise(response, request, container) {
+		t.Error("Expected admin user token to be accepted")
+	}
+
+	// Test non-admin user token
+	response.Reset()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: "editor"})
+	if authorise(response, request, container) || response.StatusCode != 403 {
+		t.Error("Expected 403 for a user who is not an admin")
+	}
+
+	// Test read-only token used to write
+	response.Reset()
+	request, _ = http.NewRequest("PUT", "/api/admin/users", nil)
+	request.Header.Set("Authorization", "Bearer jt_user")
+	db.AppendResult(&database.MockToken_SingleRow{Scopes: "read"})
+	db.AppendResult(&database.MockUser_SingleRow{Role: "admin"})
+	if authorise(response, request, container) || response.StatusCode != 403 {
+		t.Error("Expected 403 for a read-only token used to write")
+	}
+}
@@ -0,0 +1,56 @@
+package apiadmin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+type userFromJSON struct {
+	Username string
+	Email    string
+	Password string
+	Role     string
+}
+
+type tokenFromJSON struct {
+	Name   string
+	Scopes []string
+}
+
+type tokenWithValue struct {
+	model.Token
+	Value string `json:"token"`
+}
+
+type webhookFromJSON struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+type webhookWithSecret struct {
+	model.Webhook
+	Secret string `json:"secret"`
+}
+
+type webhookWithDeliveries struct {
+	model.Webhook
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+}
+
+type passwordReset struct {
+	Password string `json:"password"`
+}
+
+type errorMessage struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(response http.ResponseWriter, status int, v interface{}) {
+	response.WriteHeader(status)
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(v)
+}
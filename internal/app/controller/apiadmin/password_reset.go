@@ -0,0 +1,56 @@
+package apiadmin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// PasswordReset Reset a user's password, generating one when none is provided
+type PasswordReset struct {
+	controller.Super
+}
+
+// Run PasswordReset action
+func (c *PasswordReset) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// An empty body asks for a generated password
+	reset := passwordReset{}
+	json.NewDecoder(request.Body).Decode(&reset)
+	if reset.Password == "" {
+		random := make([]byte, 12)
+		if _, err := rand.Read(random); err != nil {
+			response.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		reset.Password = base64.RawURLEncoding.EncodeToString(random)
+	}
+
+	if err := user.SetPassword(reset.Password); err != nil {
+		writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+		return
+	}
+	if _, err := us.Save(user); err != nil {
+		writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+		return
+	}
+	auth.Audit(request, container, model.SecurityEvent{Kind: model.SecurityPasswordChanged, UserID: user.ID, Username: user.Username, Detail: "Reset through the admin API"})
+	writeJSON(response, http.StatusOK, reset)
+}
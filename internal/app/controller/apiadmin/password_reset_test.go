@@ -0,0 +1,57 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestPasswordReset_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &PasswordReset{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie/password", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test weak password
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie/password", "{\"password\":\"short\"}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when password is too short")
+	}
+
+	// Test chosen password
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie/password", "{\"password\":\"password123\"}"))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "password123") {
+		t.Error("Expected password to have been reset")
+	}
+
+	// Test generated password
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie/password", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "\"password\":\"") {
+		t.Error("Expected password to have been generated")
+	}
+
+	// Test failure to save
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie/password", ""))
+	if response.StatusCode != 400 {
+		t.Error("Expected error when password cannot be saved")
+	}
+}
@@ -0,0 +1,53 @@
+package apiadmin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// TokenCreate Issue a new API token to a user
+type TokenCreate struct {
+	controller.Super
+}
+
+// Run TokenCreate action
+func (c *TokenCreate) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var tokenRequest = tokenFromJSON{}
+	if err := json.NewDecoder(request.Body).Decode(&tokenRequest); err != nil || tokenRequest.Name == "" {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(tokenRequest.Scopes) == 0 {
+		tokenRequest.Scopes = []string{model.ScopeRead}
+	}
+	for _, scope := range tokenRequest.Scopes {
+		if scope != model.ScopeRead && scope != model.ScopeWrite {
+			writeJSON(response, http.StatusBadRequest, errorMessage{"Scope " + scope + " is not valid"})
+			return
+		}
+	}
+
+	ts := model.Tokens{Container: container}
+	token, plain, err := ts.Issue(user.ID, tokenRequest.Name, tokenRequest.Scopes)
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(response, http.StatusCreated, tokenWithValue{token, plain})
+}
@@ -0,0 +1,65 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTokenCreate_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &TokenCreate{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{\"name\":\"Script\"}"))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test missing name
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when name is missing")
+	}
+
+	// Test invalid scope
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{\"name\":\"Script\",\"scopes\":[\"delete\"]}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when scope is invalid")
+	}
+
+	// Test issued with plain value returned once
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{\"name\":\"Script\",\"scopes\":[\"read\",\"write\"]}"))
+	if response.StatusCode != 201 || !strings.Contains(response.Content, "\"token\":\"jt_") || !strings.Contains(response.Content, "\"scopes\":\"read,write\"") {
+		t.Error("Expected token to have been issued")
+	}
+
+	// Test default scope
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{\"name\":\"Script\"}"))
+	if response.StatusCode != 201 || !strings.Contains(response.Content, "\"scopes\":\"read\"") {
+		t.Error("Expected token to have been issued with read scope")
+	}
+
+	// Test failure
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, adminRequest("PUT", "/api/admin/users/jamie/tokens", "{\"name\":\"Script\"}"))
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when token cannot be stored")
+	}
+}
@@ -0,0 +1,32 @@
+package apiadmin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// TokenList Display the API tokens issued to a user
+type TokenList struct {
+	controller.Super
+}
+
+// Run TokenList action
+func (c *TokenList) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ts := model.Tokens{Container: container}
+	writeJSON(response, http.StatusOK, ts.FetchByUser(user.ID))
+}
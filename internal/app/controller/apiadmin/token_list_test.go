@@ -0,0 +1,34 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTokenList_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &TokenList{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("GET", "/api/admin/users/jamie/tokens", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test tokens returned without hashes
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockToken_SingleRow{})
+	controller.Run(response, adminRequest("GET", "/api/admin/users/jamie/tokens", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "Script") || strings.Contains(response.Content, "hash") {
+		t.Error("Expected tokens to be returned without their hashes")
+	}
+}
@@ -0,0 +1,38 @@
+package apiadmin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// TokenRevoke Revoke one of a user's API tokens
+type TokenRevoke struct {
+	controller.Super
+}
+
+// Run TokenRevoke action
+func (c *TokenRevoke) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Params[2])
+	ts := model.Tokens{Container: container}
+	if err := ts.Revoke(user.ID, id); err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.WriteHeader(http.StatusNoContent)
+}
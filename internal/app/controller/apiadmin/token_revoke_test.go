@@ -0,0 +1,40 @@
+package apiadmin
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTokenRevoke_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &TokenRevoke{}
+	controller.Init(container, []string{"", "jamie", "1"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie/tokens/1", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test failure
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie/tokens/1", ""))
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when revoking fails")
+	}
+
+	// Test revoked
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie/tokens/1", ""))
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when token is revoked")
+	}
+}
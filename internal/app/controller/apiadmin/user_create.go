@@ -0,0 +1,46 @@
+package apiadmin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// UserCreate Create a new user via API
+type UserCreate struct {
+	controller.Super
+}
+
+// Run UserCreate action
+func (c *UserCreate) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	var userRequest = userFromJSON{}
+	if err := json.NewDecoder(request.Body).Decode(&userRequest); err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user := model.User{Username: userRequest.Username, Email: userRequest.Email, Role: userRequest.Role}
+	if user.Role == "" {
+		user.Role = model.RoleReader
+	}
+	if err := user.SetPassword(userRequest.Password); err != nil {
+		writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+		return
+	}
+
+	us := model.Users{Container: container}
+	user, err := us.Save(user)
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+		return
+	}
+	writeJSON(response, http.StatusCreated, user)
+}
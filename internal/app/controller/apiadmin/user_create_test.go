@@ -0,0 +1,45 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUserCreate_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &UserCreate{}
+	controller.Init(container, []string{""})
+
+	// Test invalid JSON
+	controller.Run(response, adminRequest("PUT", "/api/admin/users", "{\"not\":\"valid\":\"json\"}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when invalid JSON provided")
+	}
+
+	// Test weak password
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/users", "{\"username\":\"friend\",\"password\":\"short\"}"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "at least 8") {
+		t.Error("Expected 400 error when password is too short")
+	}
+
+	// Test invalid role
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/users", "{\"username\":\"friend\",\"password\":\"password123\",\"role\":\"owner\"}"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "Role") {
+		t.Error("Expected 400 error when role is invalid")
+	}
+
+	// Test created with default role
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/users", "{\"username\":\"friend\",\"email\":\"friend@example.com\",\"password\":\"password123\"}"))
+	if response.StatusCode != 201 || !strings.Contains(response.Content, "\"role\":\"reader\"") {
+		t.Error("Expected user to have been created as a reader")
+	}
+}
@@ -0,0 +1,38 @@
+package apiadmin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// UserDelete Remove a user and revoke their tokens, never leaving the journal without an admin
+type UserDelete struct {
+	controller.Super
+}
+
+// Run UserDelete action
+func (c *UserDelete) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if user.Role == model.RoleAdmin && us.CountAdmins() <= 1 {
+		writeJSON(response, http.StatusBadRequest, errorMessage{"The last admin cannot be deleted"})
+		return
+	}
+	if err := us.Delete(user); err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.WriteHeader(http.StatusNoContent)
+}
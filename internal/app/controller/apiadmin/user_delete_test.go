@@ -0,0 +1,62 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUserDelete_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &UserDelete{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test the last admin is kept
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	queries := db.Queries
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "last admin") || db.Queries != queries+2 {
+		t.Error("Expected last admin not to be deleted")
+	}
+
+	// Test an admin is deleted while another is left
+	response.Reset()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when an admin is deleted while another is left")
+	}
+
+	// Test failure
+	response.Reset()
+	db.MultiMode = false
+	db.Rows = &database.MockUser_SingleRow{Role: "editor"}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when deletion fails")
+	}
+
+	// Test deleted
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{Role: "editor"}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when user is deleted")
+	}
+}
@@ -0,0 +1,25 @@
+package apiadmin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// UserList Display all users as JSON
+type UserList struct {
+	controller.Super
+}
+
+// Run UserList action
+func (c *UserList) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	writeJSON(response, http.StatusOK, us.FetchAll())
+}
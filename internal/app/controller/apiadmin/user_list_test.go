@@ -0,0 +1,33 @@
+package apiadmin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUserList_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &UserList{}
+	controller.Init(container, []string{""})
+
+	// Test unauthorised
+	request, _ := http.NewRequest("GET", "/api/admin/users", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 401 {
+		t.Error("Expected 401 when not authenticated")
+	}
+
+	// Test showing all users without password hashes
+	response.Reset()
+	db.Rows = &database.MockUser_MultipleRows{}
+	controller.Run(response, adminRequest("GET", "/api/admin/users", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "friend@example.com") || strings.Contains(response.Content, "hash") {
+		t.Error("Expected all users to be returned without their hashes")
+	}
+}
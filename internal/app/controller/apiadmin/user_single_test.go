@@ -0,0 +1,32 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUserSingle_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &UserSingle{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("GET", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test return
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{}
+	controller.Run(response, adminRequest("GET", "/api/admin/users/jamie", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "jamie@example.com") {
+		t.Error("Expected user to be returned")
+	}
+}
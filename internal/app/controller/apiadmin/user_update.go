@@ -0,0 +1,65 @@
+package apiadmin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// UserUpdate Update the email, role or password of an existing user, never leaving the journal without an admin
+type UserUpdate struct {
+	controller.Super
+}
+
+// Run UserUpdate action
+func (c *UserUpdate) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	us := model.Users{Container: container}
+	user := us.FindByUsername(c.Params[1])
+	if user.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var userRequest = userFromJSON{}
+	if err := json.NewDecoder(request.Body).Decode(&userRequest); err != nil {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Update only fields that are present
+	if userRequest.Email != "" {
+		user.Email = userRequest.Email
+	}
+	if userRequest.Role != "" {
+		if user.Role == model.RoleAdmin && userRequest.Role != model.RoleAdmin && us.CountAdmins() <= 1 {
+			writeJSON(response, http.StatusBadRequest, errorMessage{"The last admin cannot be given another role"})
+			return
+		}
+		user.Role = userRequest.Role
+	}
+	if userRequest.Password != "" {
+		if err := user.SetPassword(userRequest.Password); err != nil {
+			writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+			return
+		}
+	}
+
+	user, err := us.Save(user)
+	if err != nil {
+		writeJSON(response, http.StatusBadRequest, errorMessage{err.Error()})
+		return
+	}
+	if userRequest.Password != "" {
+		auth.Audit(request, container, model.SecurityEvent{Kind: model.SecurityPasswordChanged, UserID: user.ID, Username: user.Username, Detail: "Changed through the admin API"})
+	}
+	writeJSON(response, http.StatusOK, user)
+}
@@ -0,0 +1,69 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUserUpdate_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &UserUpdate{}
+	controller.Init(container, []string{"", "jamie"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{}"))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when user not found")
+	}
+
+	// Test invalid JSON
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{PasswordHash: "hash"}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{\"not\":\"valid\":\"json\"}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when invalid JSON provided")
+	}
+
+	// Test weak password
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{PasswordHash: "hash"}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{\"password\":\"short\"}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when password is too short")
+	}
+
+	// Test invalid role
+	response.Reset()
+	db.Rows = &database.MockUser_SingleRow{PasswordHash: "hash", Role: "editor"}
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{\"role\":\"owner\"}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when role is invalid")
+	}
+
+	// Test the last admin keeps their role
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: "hash"})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	queries := db.Queries
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{\"role\":\"editor\"}"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "last admin") || db.Queries != queries+2 {
+		t.Error("Expected last admin to keep their role")
+	}
+
+	// Test role assignment
+	response.Reset()
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: "hash"})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, adminRequest("POST", "/api/admin/users/jamie", "{\"role\":\"editor\",\"email\":\"new@example.com\"}"))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "\"role\":\"editor\"") || !strings.Contains(response.Content, "new@example.com") {
+		t.Error("Expected role and email to have been updated")
+	}
+}
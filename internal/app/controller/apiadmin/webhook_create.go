@@ -0,0 +1,49 @@
+package apiadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// WebhookCreate Add a webhook, returning the secret its requests are signed with
+type WebhookCreate struct {
+	controller.Super
+}
+
+// Run WebhookCreate action
+func (c *WebhookCreate) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	var webhookRequest = webhookFromJSON{}
+	if err := json.NewDecoder(request.Body).Decode(&webhookRequest); err != nil || webhookRequest.URL == "" {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !model.IsValidLinkURL(webhookRequest.URL) || len(webhookRequest.URL) > 255 {
+		writeJSON(response, http.StatusBadRequest, errorMessage{"URL " + webhookRequest.URL + " is not valid"})
+		return
+	}
+	for _, event := range webhookRequest.Events {
+		if !webhook.IsEvent(event) {
+			writeJSON(response, http.StatusBadRequest, errorMessage{"Event " + event + " is not valid"})
+			return
+		}
+	}
+
+	ws := model.Webhooks{Container: container}
+	w, err := ws.Save(model.Webhook{URL: webhookRequest.URL, Secret: webhookRequest.Secret, Events: strings.Join(webhookRequest.Events, ",")})
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeJSON(response, http.StatusCreated, webhookWithSecret{w, w.Secret})
+}
@@ -0,0 +1,59 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWebhookCreate_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &WebhookCreate{}
+	controller.Init(container, []string{""})
+
+	// Test missing URL
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{}"))
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when URL is missing")
+	}
+
+	// Test invalid URL
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{\"url\":\"ftp://example.com\"}"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "is not valid") {
+		t.Error("Expected 400 error when URL is invalid")
+	}
+
+	// Test invalid event
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{\"url\":\"https://hooks.example.com/1\",\"events\":[\"entry.read\"]}"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "Event entry.read is not valid") {
+		t.Error("Expected 400 error when event is invalid")
+	}
+
+	// Test created with the secret returned
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{\"url\":\"https://hooks.example.com/1\",\"events\":[\"entry.created\",\"entry.deleted\"]}"))
+	if response.StatusCode != 201 || !strings.Contains(response.Content, "\"secret\":\"") || !strings.Contains(response.Content, "\"events\":\"entry.created,entry.deleted\"") {
+		t.Errorf("Expected webhook to have been created, got %s", response.Content)
+	}
+
+	// Test secret given is kept
+	response.Reset()
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{\"url\":\"https://hooks.example.com/1\",\"secret\":\"chosen\"}"))
+	if response.StatusCode != 201 || !strings.Contains(response.Content, "\"secret\":\"chosen\"") {
+		t.Error("Expected webhook to have been created with the secret given")
+	}
+
+	// Test failure
+	response.Reset()
+	db.ErrorMode = true
+	controller.Run(response, adminRequest("PUT", "/api/admin/webhooks", "{\"url\":\"https://hooks.example.com/1\"}"))
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when webhook cannot be stored")
+	}
+}
@@ -0,0 +1,36 @@
+package apiadmin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// WebhookDelete Remove a webhook so it is no longer told about changes
+type WebhookDelete struct {
+	controller.Super
+}
+
+// Run WebhookDelete action
+func (c *WebhookDelete) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Params[1])
+	ws := model.Webhooks{Container: container}
+	w := ws.FindByID(id)
+	if w.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := ws.Delete(w); err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package apiadmin
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWebhookDelete_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &WebhookDelete{}
+	controller.Init(container, []string{"", "1"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/webhooks/1", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when webhook not found")
+	}
+
+	// Test failure
+	response.Reset()
+	db.Rows = &database.MockWebhook_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, adminRequest("DELETE", "/api/admin/webhooks/1", ""))
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when deleting fails")
+	}
+
+	// Test deleted
+	response.Reset()
+	db.Rows = &database.MockWebhook_SingleRow{}
+	controller.Run(response, adminRequest("DELETE", "/api/admin/webhooks/1", ""))
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when webhook is deleted")
+	}
+}
@@ -0,0 +1,25 @@
+package apiadmin
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// WebhookList Display the webhooks told about changes to entries
+type WebhookList struct {
+	controller.Super
+}
+
+// Run WebhookList action
+func (c *WebhookList) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	ws := model.Webhooks{Container: container}
+	writeJSON(response, http.StatusOK, ws.FetchAll())
+}
@@ -0,0 +1,24 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWebhookList_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &WebhookList{}
+	controller.Init(container, []string{""})
+
+	// Test webhooks returned without their secrets
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	controller.Run(response, adminRequest("GET", "/api/admin/webhooks", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "https://hooks.example.com/2") || strings.Contains(response.Content, "secret") {
+		t.Error("Expected webhooks to be returned without their secrets")
+	}
+}
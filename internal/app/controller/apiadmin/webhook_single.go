@@ -0,0 +1,37 @@
+package apiadmin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// webhookDeliveryLimit Number of recent deliveries shown with a webhook
+const webhookDeliveryLimit = 20
+
+// WebhookSingle Display a webhook along with its most recent deliveries
+type WebhookSingle struct {
+	controller.Super
+}
+
+// Run WebhookSingle action
+func (c *WebhookSingle) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !authorise(response, request, container) {
+		return
+	}
+
+	id, _ := strconv.Atoi(c.Params[1])
+	ws := model.Webhooks{Container: container}
+	w := ws.FindByID(id)
+	if w.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	ds := model.WebhookDeliveries{Container: container}
+	writeJSON(response, http.StatusOK, webhookWithDeliveries{w, ds.FetchByWebhook(w.ID, webhookDeliveryLimit)})
+}
@@ -0,0 +1,34 @@
+package apiadmin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWebhookSingle_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := adminContainer(db)
+	response := controller.NewMockResponse()
+	controller := &WebhookSingle{}
+	controller.Init(container, []string{"", "1"})
+
+	// Test not found
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, adminRequest("GET", "/api/admin/webhooks/1", ""))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when webhook not found")
+	}
+
+	// Test returned with recent deliveries
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockWebhook_SingleRow{})
+	db.AppendResult(&database.MockWebhookDelivery_MultipleRows{})
+	controller.Run(response, adminRequest("GET", "/api/admin/webhooks/1", ""))
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "\"url\":\"https://hooks.example.com/1\"") || !strings.Contains(response.Content, "\"status_code\":500") {
+		t.Errorf("Expected webhook to be returned with its deliveries, got %s", response.Content)
+	}
+}
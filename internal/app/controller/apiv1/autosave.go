@@ -0,0 +1,98 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// newEntryAutosave Slug standing in for an entry that has not been saved yet, which can never be taken by one
+const newEntryAutosave = "new"
+
+type autosaveFromJSON struct {
+	Title   string
+	Date    string
+	Content string
+	Excerpt string
+}
+
+// Autosave Keep, fetch or discard the work in progress of the current user from the editor via API
+type Autosave struct {
+	controller.Super
+}
+
+// Run Autosave action
+func (c *Autosave) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	user := auth.CurrentUser(request, container)
+	journalID := 0
+	if c.Params[1] == newEntryAutosave {
+		if !container.Configuration.EnableCreate {
+			response.WriteHeader(http.StatusForbidden)
+			return
+		}
+	} else {
+		if !container.Configuration.EnableEdit {
+			response.WriteHeader(http.StatusForbidden)
+			return
+		}
+		store := model.Store(container)
+		journal := store.GetBySlug(c.Params[1])
+		if journal.ID == 0 {
+			response.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !journal.CanEdit(user) {
+			response.WriteHeader(http.StatusForbidden)
+			return
+		}
+		journalID = journal.ID
+	}
+
+	as := model.JournalAutosaves{Container: container}
+	response.Header().Add("Content-Type", "application/json")
+	switch request.Method {
+	case "GET":
+		autosave := as.FindByJournal(user.ID, journalID)
+		if autosave.SavedAt == "" {
+			response.WriteHeader(http.StatusNotFound)
+			return
+		}
+		encoder := json.NewEncoder(response)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(autosave)
+	case "DELETE":
+		if err := as.DeleteByJournal(user.ID, journalID); err != nil {
+			response.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		response.WriteHeader(http.StatusNoContent)
+	default:
+		autosaveRequest := autosaveFromJSON{}
+		decoder := json.NewDecoder(request.Body)
+		if err := decoder.Decode(&autosaveRequest); err != nil || (strings.TrimSpace(autosaveRequest.Title) == "" && strings.TrimSpace(autosaveRequest.Content) == "") {
+			response.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		autosave, err := as.Save(model.JournalAutosave{
+			UserID:    user.ID,
+			JournalID: journalID,
+			Title:     autosaveRequest.Title,
+			Date:      autosaveRequest.Date,
+			Content:   autosaveRequest.Content,
+			Excerpt:   autosaveRequest.Excerpt,
+		})
+		if err != nil {
+			response.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		encoder := json.NewEncoder(response)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(autosave)
+	}
+}
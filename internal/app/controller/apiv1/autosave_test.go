@@ -0,0 +1,113 @@
+package apiv1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestAutosave_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	response := controller.NewMockResponse()
+	controller := &Autosave{}
+
+	// Test forbidden for new and existing entries
+	container.Configuration.EnableCreate = false
+	container.Configuration.EnableEdit = false
+	for _, slug := range []string{"new", "slug"} {
+		response.Reset()
+		controller.Init(container, []string{"", slug})
+		request, _ := http.NewRequest("GET", "/api/journals/"+slug+"/autosave", nil)
+		controller.Run(response, request)
+		if response.StatusCode != 403 {
+			t.Errorf("Expected 403 error for %s when changes are disabled", slug)
+		}
+	}
+	container.Configuration.EnableCreate = true
+	container.Configuration.EnableEdit = true
+
+	// Test unknown entry
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/api/journals/slug/autosave", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when journal not found")
+	}
+
+	// Test nothing kept for a new entry
+	response.Reset()
+	controller.Init(container, []string{"", "new"})
+	db.ExpectedArgument = "0"
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("GET", "/api/journals/new/autosave", nil)
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when nothing has been kept")
+	}
+
+	// Test work in progress returned
+	response.Reset()
+	db.ExpectedArgument = ""
+	db.Rows = &database.MockJournalAutosave_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, `"content":"Unsaved content"`) || !strings.Contains(response.Content, `"saved_at":"2018-02-02 10:00:00"`) {
+		t.Error("Expected work in progress to be returned")
+	}
+
+	// Test invalid and empty work in progress
+	for _, body := range []string{"{", `{"title":" ","content":""}`} {
+		response.Reset()
+		request, _ = http.NewRequest("POST", "/api/journals/new/autosave", strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer admin-secret")
+		controller.Run(response, request)
+		if response.StatusCode != 400 {
+			t.Errorf("Expected 400 error for %s", body)
+		}
+	}
+
+	// Test work in progress on an entry is kept from those who may not edit it
+	response.Reset()
+	controller.Init(container, []string{"", "slug"})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	request, _ = http.NewRequest("GET", "/api/journals/slug/autosave", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error for someone who may not edit the entry")
+	}
+
+	// Test work in progress kept against an existing entry
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	request, _ = http.NewRequest("POST", "/api/journals/slug/autosave", strings.NewReader(`{"title":"Title","date":"2018-02-01","content":"Half written"}`))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, `"journal_id":1`) || !strings.Contains(response.Content, `"content":"Half written"`) {
+		t.Error("Expected work in progress to be kept")
+	}
+
+	// Test failure and discarding
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.ErrorAtQuery = db.Queries + 2
+	request, _ = http.NewRequest("DELETE", "/api/journals/slug/autosave", nil)
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when discarding fails")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, request)
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when work in progress is discarded")
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"net/http"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
@@ -31,9 +35,27 @@ func (c *Create) Run(response http.ResponseWriter, request *http.Request) {
 		if journalRequest.Title == "" || journalRequest.Content == "" || journalRequest.Date == "" {
 			response.WriteHeader(http.StatusBadRequest)
 		} else {
-			journal := model.Journal{ID: 0, Slug: model.Slugify(journalRequest.Title), Title: journalRequest.Title, Date: journalRequest.Date, Content: journalRequest.Content}
 			js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
-			journal = js.Save(journal)
+			if js.QuotaReached() {
+				response.WriteHeader(http.StatusForbidden)
+				return
+			}
+			journal := model.Journal{ID: 0, Slug: model.Slugify(journalRequest.Title), Title: journalRequest.Title, Date: journalRequest.Date, Content: journalRequest.Content, AuthorID: auth.CurrentUser(request, container).ID}
+			if !journalRequest.applyLinks(&journal) || !journalRequest.applyMeta(&journal) {
+				response.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			journalRequest.applyExcerpt(&journal)
+			journalRequest.applyVisibility(&journal)
+			journalRequest.applyTags(&journal)
+			journal, err := model.SaveJournal(container, model.Journal{}, journal)
+			if err != nil {
+				response.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			ping.Notify(container, journal)
+			federation.Notify(container, journal)
+			webhook.Created(container, journal)
 			response.WriteHeader(http.StatusCreated)
 			encoder := json.NewEncoder(response)
 			encoder.SetEscapeHTML(false)
@@ -2,7 +2,6 @@ package apiv1
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -19,7 +18,6 @@ func TestCreate_Run(t *testing.T) {
 	response := controller.NewMockResponse()
 	response.Reset()
 	controller := &Create{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test forbidden
 	controller.Init(container, []string{"", "0"})
@@ -50,6 +48,15 @@ func TestCreate_Run(t *testing.T) {
 		t.Error("Expected 400 error when missing JSON provided")
 	}
 
+	// Test invalid links
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("{\"title\":\"Something New\",\"date\":\"2018-01-01\",\"content\":\"New\",\"syndication\":[\"not-a-url\"]}"))
+	request.Header.Add("Content-Type", "application/json")
+	controller.Run(response, request)
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when syndication URL is invalid")
+	}
+
 	// Test Journal is retrieved on save
 	response.Reset()
 	request, _ = http.NewRequest("POST", "/new", strings.NewReader("{\"title\":\"Something New\",\"date\":\"2018-01-01\",\"content\":\"New\"}"))
@@ -59,4 +66,16 @@ func TestCreate_Run(t *testing.T) {
 	if response.StatusCode != 201 || !strings.Contains(response.Content, "Something New") {
 		t.Error("Expected new title to be within content")
 	}
+
+	// Test quota reached within a tenant
+	response.Reset()
+	container.Tenant = "alice"
+	container.Configuration.TenantMaxEntries = 1
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("{\"title\":\"Something New\",\"date\":\"2018-01-01\",\"content\":\"New\"}"))
+	request.Header.Add("Content-Type", "application/json")
+	controller.Run(response, request)
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error when quota is reached")
+	}
 }
@@ -1,7 +1,82 @@
 package apiv1
 
+import (
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
 type journalFromJSON struct {
-	Title   string
-	Date    string
-	Content string
+	Title        string
+	Date         string
+	Content      string
+	Excerpt      *string           `json:"excerpt"`
+	CanonicalURL *string           `json:"canonical_url"`
+	Syndication  []string          `json:"syndication"`
+	Visibility   *string           `json:"visibility"`
+	Meta         map[string]string `json:"meta"`
+	Tags         []string          `json:"tags"`
+}
+
+// applyExcerpt Copy the excerpt onto the entry when one is provided, an empty string removing it
+func (j journalFromJSON) applyExcerpt(journal *model.Journal) {
+	if j.Excerpt != nil {
+		journal.Excerpt = strings.TrimSpace(*j.Excerpt)
+	}
+}
+
+// applyVisibility Copy who may see the entry onto it when provided, anything unrecognised making it public
+func (j journalFromJSON) applyVisibility(journal *model.Journal) {
+	if j.Visibility != nil {
+		journal.Visibility = *j.Visibility
+	}
+}
+
+// applyTags Replace the tags of the entry when provided, an empty list removing them all
+func (j journalFromJSON) applyTags(journal *model.Journal) {
+	if j.Tags != nil {
+		journal.Tags = model.ParseTags(strings.Join(j.Tags, ","))
+	}
+}
+
+// applyMeta Set the custom fields provided on the entry, keeping any others it has and removing those given no value,
+// returning false if any name is invalid
+func (j journalFromJSON) applyMeta(journal *model.Journal) bool {
+	meta := map[string]string{}
+	for key, value := range journal.Meta {
+		meta[key] = value
+	}
+	for key, value := range j.Meta {
+		if !model.IsValidMetaKey(key) {
+			return false
+		}
+		if value == "" {
+			delete(meta, key)
+		} else {
+			meta[key] = value
+		}
+	}
+	journal.Meta = meta
+
+	return true
+}
+
+// applyLinks Copy any canonical and syndication URLs provided onto the entry, returning false if any are invalid
+func (j journalFromJSON) applyLinks(journal *model.Journal) bool {
+	if j.CanonicalURL != nil {
+		if *j.CanonicalURL != "" && !model.IsValidLinkURL(*j.CanonicalURL) {
+			return false
+		}
+		journal.CanonicalURL = *j.CanonicalURL
+	}
+	if j.Syndication != nil {
+		for _, u := range j.Syndication {
+			if !model.IsValidLinkURL(u) {
+				return false
+			}
+		}
+		journal.Syndication = j.Syndication
+	}
+
+	return true
 }
@@ -0,0 +1,44 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Delete Move an existing entry to the trash via API
+type Delete struct {
+	controller.Super
+}
+
+// Run Delete action
+func (c *Delete) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if journal.ID == 0 {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !journal.CanEdit(auth.CurrentUser(request, container)) {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := js.Trash(journal); err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	webhook.Deleted(container, journal)
+	response.WriteHeader(http.StatusNoContent)
+}
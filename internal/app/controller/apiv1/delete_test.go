@@ -0,0 +1,68 @@
+package apiv1
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestDelete_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	response := controller.NewMockResponse()
+	controller := &Delete{}
+	controller.Init(container, []string{"", "slug"})
+	request, _ := http.NewRequest("DELETE", "/api/journals/slug", nil)
+	request.Header.Set("Authorization", "Bearer admin-secret")
+
+	// Test forbidden
+	container.Configuration.EnableEdit = false
+	controller.Run(response, request)
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error when editing is disabled")
+	}
+
+	// Test not found
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when journal not found")
+	}
+
+	// Test entries written by someone else are refused
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{AuthorID: 2})
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: "editor"})
+	other, _ := http.NewRequest("DELETE", "/api/journals/slug", nil)
+	other.Header.Set("Authorization", "Bearer known")
+	controller.Run(response, other)
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error when the entry was written by someone else")
+	}
+	db.MultiMode = false
+
+	// Test failure
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, request)
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 error when deletion fails")
+	}
+
+	// Test deleted
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 204 {
+		t.Error("Expected 204 when journal is moved to the trash")
+	}
+}
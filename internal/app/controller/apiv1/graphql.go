@@ -0,0 +1,58 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/graphql"
+)
+
+// graphqlSchema The schema served at /graphql, built once as it does not change
+var graphqlSchema = newGraphQLSchema()
+
+// GraphQL Answer GraphQL queries over entries, categories and comments, and mutations of entries, in one request
+type GraphQL struct {
+	controller.Super
+}
+
+// Run GraphQL action
+func (c *GraphQL) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	response.Header().Add("Content-Type", "application/json")
+
+	graphqlRequest := graphql.Request{}
+	if request.Method == "GET" {
+		// Queries may be sent in the address, but never mutations
+		query := request.URL.Query()
+		graphqlRequest.Query = query.Get("query")
+		graphqlRequest.OperationName = query.Get("operationName")
+		graphqlRequest.QueryOnly = true
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &graphqlRequest.Variables); err != nil {
+				writeGraphQLError(response, "Variables must be a JSON object")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(request.Body).Decode(&graphqlRequest); err != nil {
+		writeGraphQLError(response, "Request body must be a JSON object with a query")
+		return
+	}
+	if graphqlRequest.Query == "" {
+		writeGraphQLError(response, "Must provide a query")
+		return
+	}
+
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(graphqlSchema.Do(graphqlRequest, &graphqlContext{container: container, request: request}))
+}
+
+// writeGraphQLError Refuse a request that could not be read as GraphQL
+func writeGraphQLError(response http.ResponseWriter, message string) {
+	response.WriteHeader(http.StatusBadRequest)
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(graphql.Response{Errors: []graphql.Error{{Message: message}}})
+}
@@ -0,0 +1,464 @@
+package apiv1
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/graphql"
+)
+
+// graphqlMaxFirst The most entries or comments one page of a connection may hold
+const graphqlMaxFirst = 100
+
+const graphqlCursorPrefix = "cursor:"
+
+// graphqlContext What every resolver needs from the request being served
+type graphqlContext struct {
+	container *app.Container
+	request   *http.Request
+}
+
+// connection One page of a list, with where in the list it starts and how long the list is
+type connection struct {
+	nodes  []interface{}
+	offset int
+	total  int
+}
+
+// edge A node in a connection along with the cursor that pages on from it
+type edge struct {
+	cursor string
+	node   interface{}
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(graphqlCursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(decoded), graphqlCursorPrefix) {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), graphqlCursorPrefix)); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+
+	return 0, errors.New("Invalid cursor \"" + cursor + "\"")
+}
+
+// pageArgs Read the first and after arguments of a connection into an offset and limit
+func pageArgs(p graphql.Params, defaultFirst int) (int, int, error) {
+	first := defaultFirst
+	if value, ok := p.Int("first"); ok {
+		first = value
+	} else if p.Args["first"] != nil {
+		return 0, 0, errors.New("Argument \"first\" must be an Int")
+	}
+	if first < 0 || first > graphqlMaxFirst {
+		return 0, 0, errors.New("Argument \"first\" must be between 0 and " + strconv.Itoa(graphqlMaxFirst))
+	}
+
+	offset := 0
+	if after, ok := p.String("after"); ok {
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = cursor + 1
+	}
+
+	return offset, first, nil
+}
+
+func graphqlContainer(p graphql.Params) *app.Container {
+	return p.Context.(*graphqlContext).container
+}
+
+// graphqlUser Get the user whose credentials the request carries, who must be an editor to change entries
+func graphqlUser(p graphql.Params) model.User {
+	ctx := p.Context.(*graphqlContext)
+
+	return auth.CurrentUser(ctx.request, ctx.container)
+}
+
+// errGraphQLEditor Refusal of a change by anyone not signed in as an editor or admin
+var errGraphQLEditor = errors.New("Changing entries needs the credentials of an editor, with a token given the write scope")
+
+// connectionObject Build the type of a page of nodes, with edges carrying cursors and details of the page
+func connectionObject(name string, node *graphql.Object, pageInfo *graphql.Object) *graphql.Object {
+	edgeObject := &graphql.Object{Name: name + "Edge", Fields: map[string]*graphql.Field{
+		"cursor": {Resolve: func(p graphql.Params) (interface{}, error) { return p.Source.(edge).cursor, nil }},
+		"node":   {Type: node, Resolve: func(p graphql.Params) (interface{}, error) { return p.Source.(edge).node, nil }},
+	}}
+
+	return &graphql.Object{Name: name + "Connection", Fields: map[string]*graphql.Field{
+		"edges": {Type: edgeObject, Resolve: func(p graphql.Params) (interface{}, error) {
+			c := p.Source.(connection)
+			edges := []edge{}
+			for i, n := range c.nodes {
+				edges = append(edges, edge{cursor: encodeCursor(c.offset + i), node: n})
+			}
+			return edges, nil
+		}},
+		"nodes":      {Type: node, Resolve: func(p graphql.Params) (interface{}, error) { return p.Source.(connection).nodes, nil }},
+		"pageInfo":   {Type: pageInfo, Resolve: func(p graphql.Params) (interface{}, error) { return p.Source, nil }},
+		"totalCount": {Resolve: func(p graphql.Params) (interface{}, error) { return p.Source.(connection).total, nil }},
+	}}
+}
+
+func journalConnection(journals []model.Journal, offset int, total int) connection {
+	c := connection{nodes: []interface{}{}, offset: offset, total: total}
+	for _, j := range journals {
+		c.nodes = append(c.nodes, j)
+	}
+
+	return c
+}
+
+// journalField Build a field read straight from an entry
+func journalField(resolve func(j model.Journal) interface{}) *graphql.Field {
+	return &graphql.Field{Resolve: func(p graphql.Params) (interface{}, error) { return resolve(p.Source.(model.Journal)), nil }}
+}
+
+// categoryField Build a field read straight from a category
+func categoryField(resolve func(c model.Category) interface{}) *graphql.Field {
+	return &graphql.Field{Resolve: func(p graphql.Params) (interface{}, error) { return resolve(p.Source.(model.Category)), nil }}
+}
+
+// tagField Build a field read straight from a tag
+func tagField(resolve func(t model.Tag) interface{}) *graphql.Field {
+	return &graphql.Field{Resolve: func(p graphql.Params) (interface{}, error) { return resolve(p.Source.(model.Tag)), nil }}
+}
+
+// commentField Build a field read straight from a comment
+func commentField(resolve func(c model.Comment) interface{}) *graphql.Field {
+	return &graphql.Field{Resolve: func(p graphql.Params) (interface{}, error) { return resolve(p.Source.(model.Comment)), nil }}
+}
+
+// fetchJournals Get a page of the published, listed entries, optionally only those filed in a category or beneath it
+// and those given a tag
+func fetchJournals(p graphql.Params, category *model.Category, tag string) (interface{}, error) {
+	container := graphqlContainer(p)
+	offset, first, err := pageArgs(p, container.Configuration.ArticlesPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	js := model.Journals{Container: container}
+	categoryIDs := []int{}
+	if category != nil {
+		cs := model.Categories{Container: container}
+		categoryIDs = cs.Descendants(*category)
+	}
+	journals, total := js.FetchRange(categoryIDs, tag, offset, first)
+
+	return journalConnection(journals, offset, total), nil
+}
+
+// journalFromInput Copy the fields given in an input object onto an entry, ignoring any not given
+func journalFromInput(p graphql.Params, journal *model.Journal) error {
+	input, ok := p.Object("input")
+	if !ok {
+		return errors.New("Argument \"input\" must be an object")
+	}
+	fields := map[string]*string{"title": &journal.Title, "date": &journal.Date, "content": &journal.Content, "excerpt": &journal.Excerpt, "visibility": &journal.Visibility}
+	for name, value := range input {
+		if name == "tags" {
+			names, ok := value.([]interface{})
+			if !ok {
+				return errors.New("Field \"tags\" in input must be a list of String")
+			}
+			journal.Tags = []string{}
+			for _, n := range names {
+				text, ok := n.(string)
+				if !ok {
+					return errors.New("Field \"tags\" in input must be a list of String")
+				}
+				journal.Tags = append(journal.Tags, text)
+			}
+			journal.Tags = model.ParseTags(strings.Join(journal.Tags, ","))
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			return errors.New("Unknown field \"" + name + "\" in input")
+		}
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("Field \"" + name + "\" in input must be a String")
+		}
+		*field = text
+	}
+	journal.Excerpt = strings.TrimSpace(journal.Excerpt)
+
+	return nil
+}
+
+// newGraphQLSchema Build the schema of entries, the categories they are filed in, their tags and their comments
+func newGraphQLSchema() *graphql.Schema {
+	pageInfo := &graphql.Object{Name: "PageInfo", Fields: map[string]*graphql.Field{
+		"hasNextPage": {Resolve: func(p graphql.Params) (interface{}, error) {
+			c := p.Source.(connection)
+			return c.offset+len(c.nodes) < c.total, nil
+		}},
+		"hasPreviousPage": {Resolve: func(p graphql.Params) (interface{}, error) { return p.Source.(connection).offset > 0, nil }},
+		"startCursor": {Resolve: func(p graphql.Params) (interface{}, error) {
+			c := p.Source.(connection)
+			if len(c.nodes) == 0 {
+				return nil, nil
+			}
+			return encodeCursor(c.offset), nil
+		}},
+		"endCursor": {Resolve: func(p graphql.Params) (interface{}, error) {
+			c := p.Source.(connection)
+			if len(c.nodes) == 0 {
+				return nil, nil
+			}
+			return encodeCursor(c.offset + len(c.nodes) - 1), nil
+		}},
+	}}
+
+	comment := &graphql.Object{Name: "Comment", Fields: map[string]*graphql.Field{
+		"id":        commentField(func(c model.Comment) interface{} { return c.ID }),
+		"author":    commentField(func(c model.Comment) interface{} { return c.Author }),
+		"url":       commentField(func(c model.Comment) interface{} { return c.URL }),
+		"content":   commentField(func(c model.Comment) interface{} { return c.Content }),
+		"createdAt": commentField(func(c model.Comment) interface{} { return c.CreatedAt }),
+	}}
+	commentConnection := connectionObject("Comment", comment, pageInfo)
+
+	journal := &graphql.Object{Name: "Journal", Fields: map[string]*graphql.Field{
+		"id":           journalField(func(j model.Journal) interface{} { return j.ID }),
+		"slug":         journalField(func(j model.Journal) interface{} { return j.Slug }),
+		"title":        journalField(func(j model.Journal) interface{} { return j.Title }),
+		"date":         journalField(func(j model.Journal) interface{} { return j.Date }),
+		"content":      journalField(func(j model.Journal) interface{} { return j.Content }),
+		"excerpt":      journalField(func(j model.Journal) interface{} { return j.GetExcerpt() }),
+		"wordCount":    journalField(func(j model.Journal) interface{} { return j.WordCount }),
+		"readingTime":  journalField(func(j model.Journal) interface{} { return j.ReadingTime }),
+		"pinned":       journalField(func(j model.Journal) interface{} { return j.Pinned }),
+		"visibility":   journalField(func(j model.Journal) interface{} { return j.Visibility }),
+		"commentsOpen": journalField(func(j model.Journal) interface{} { return j.CommentsOpen() }),
+		"html": {Resolve: func(p graphql.Params) (interface{}, error) {
+			container := graphqlContainer(p)
+			js := model.Journals{Container: container}
+			ss := model.Shortcodes{Container: container}
+			gs := model.Giphys{}
+			return gs.ConvertIDsToIframes(ss.Replace(js.LinkWikiLinks(p.Source.(model.Journal).GetHTML()))), nil
+		}},
+		"comments": {Type: commentConnection, Args: []string{"first", "after"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			offset, first, err := pageArgs(p, graphqlMaxFirst)
+			if err != nil {
+				return nil, err
+			}
+			cs := model.Comments{Container: graphqlContainer(p)}
+			comments := cs.FetchApproved(p.Source.(model.Journal).ID)
+			c := connection{nodes: []interface{}{}, offset: offset, total: len(comments)}
+			for i := offset; i < len(comments) && i < offset+first; i++ {
+				c.nodes = append(c.nodes, comments[i])
+			}
+			return c, nil
+		}},
+	}}
+	journalConnectionObject := connectionObject("Journal", journal, pageInfo)
+
+	category := &graphql.Object{Name: "Category"}
+	findCategory := func(p graphql.Params, id int) (interface{}, error) {
+		if id == 0 {
+			return nil, nil
+		}
+		cs := model.Categories{Container: graphqlContainer(p)}
+		if c := cs.FindByID(id); c.ID > 0 {
+			return c, nil
+		}
+		return nil, nil
+	}
+	category.Fields = map[string]*graphql.Field{
+		"id":   categoryField(func(c model.Category) interface{} { return c.ID }),
+		"slug": categoryField(func(c model.Category) interface{} { return c.Slug }),
+		"name": categoryField(func(c model.Category) interface{} { return c.Name }),
+		"parent": {Type: category, Resolve: func(p graphql.Params) (interface{}, error) {
+			return findCategory(p, p.Source.(model.Category).ParentID)
+		}},
+		"children": {Type: category, Resolve: func(p graphql.Params) (interface{}, error) {
+			cs := model.Categories{Container: graphqlContainer(p)}
+			return cs.Children(p.Source.(model.Category)), nil
+		}},
+		"journals": {Type: journalConnectionObject, Args: []string{"first", "after"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			c := p.Source.(model.Category)
+			return fetchJournals(p, &c, "")
+		}},
+	}
+	journal.Fields["category"] = &graphql.Field{Type: category, Resolve: func(p graphql.Params) (interface{}, error) {
+		return findCategory(p, p.Source.(model.Journal).CategoryID)
+	}}
+
+	tag := &graphql.Object{Name: "Tag", Fields: map[string]*graphql.Field{
+		"slug":  tagField(func(t model.Tag) interface{} { return t.Slug }),
+		"name":  tagField(func(t model.Tag) interface{} { return t.Name }),
+		"count": tagField(func(t model.Tag) interface{} { return t.Count }),
+		"journals": {Type: journalConnectionObject, Args: []string{"first", "after"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			return fetchJournals(p, nil, p.Source.(model.Tag).Slug)
+		}},
+	}}
+	journal.Fields["tags"] = &graphql.Field{Type: tag, Resolve: func(p graphql.Params) (interface{}, error) {
+		j := p.Source.(model.Journal)
+		if j.Tags != nil {
+			return j.GetTags(), nil
+		}
+		ts := model.JournalTags{Container: graphqlContainer(p)}
+		return ts.FetchByJournal(j.ID), nil
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"journals": {Type: journalConnectionObject, Args: []string{"first", "after", "category", "tag"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			tagSlug := ""
+			if name, ok := p.String("tag"); ok {
+				if tagSlug = model.TagSlug(name); tagSlug == "" {
+					return nil, errors.New("Tag \"" + name + "\" not found")
+				}
+			}
+			slug, ok := p.String("category")
+			if !ok {
+				return fetchJournals(p, nil, tagSlug)
+			}
+			cs := model.Categories{Container: graphqlContainer(p)}
+			c := cs.FindBySlug(slug)
+			if c.ID == 0 {
+				return nil, errors.New("Category \"" + slug + "\" not found")
+			}
+			return fetchJournals(p, &c, tagSlug)
+		}},
+		"journal": {Type: journal, Args: []string{"slug"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			ctx := p.Context.(*graphqlContext)
+			slug, _ := p.String("slug")
+			store := model.Store(ctx.container)
+			j := store.GetBySlug(slug)
+			if j.ID == 0 || !j.IsPublished() || ((j.IsPrivate() || j.IsProtected()) && !auth.Authenticated(ctx.request, ctx.container)) {
+				return nil, nil
+			}
+			return j, nil
+		}},
+		"categories": {Type: category, Resolve: func(p graphql.Params) (interface{}, error) {
+			cs := model.Categories{Container: graphqlContainer(p)}
+			return cs.FetchTree(), nil
+		}},
+		"category": {Type: category, Args: []string{"slug"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			slug, _ := p.String("slug")
+			cs := model.Categories{Container: graphqlContainer(p)}
+			if c := cs.FindBySlug(slug); c.ID > 0 {
+				return c, nil
+			}
+			return nil, nil
+		}},
+		"tags": {Type: tag, Resolve: func(p graphql.Params) (interface{}, error) {
+			ts := model.JournalTags{Container: graphqlContainer(p)}
+			return ts.FetchAll(), nil
+		}},
+		"tag": {Type: tag, Args: []string{"slug"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			slug, _ := p.String("slug")
+			ts := model.JournalTags{Container: graphqlContainer(p)}
+			if t := ts.FindBySlug(model.TagSlug(slug)); t.Slug != "" {
+				return t, nil
+			}
+			return nil, nil
+		}},
+	}}
+
+	mutation := &graphql.Object{Name: "Mutation", Fields: map[string]*graphql.Field{
+		"createJournal": {Type: journal, Args: []string{"input"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			container := graphqlContainer(p)
+			if !container.Configuration.EnableCreate {
+				return nil, errors.New("Creating entries is disabled")
+			}
+			user := graphqlUser(p)
+			if !user.HasRole(model.RoleEditor) {
+				return nil, errGraphQLEditor
+			}
+			js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+			if js.QuotaReached() {
+				return nil, errors.New("This journal has reached its limit of entries")
+			}
+			j := model.Journal{}
+			if err := journalFromInput(p, &j); err != nil {
+				return nil, err
+			}
+			if j.Title == "" || j.Date == "" || j.Content == "" {
+				return nil, errors.New("An entry needs a title, date and content")
+			}
+			j.Slug = model.Slugify(j.Title)
+			j.AuthorID = user.ID
+			j, err := model.SaveJournal(container, model.Journal{}, j)
+			if err != nil {
+				return nil, err
+			}
+			ping.Notify(container, j)
+			federation.Notify(container, j)
+			webhook.Created(container, j)
+			return j, nil
+		}},
+		"updateJournal": {Type: journal, Args: []string{"slug", "input"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			container := graphqlContainer(p)
+			if !container.Configuration.EnableEdit {
+				return nil, errors.New("Editing entries is disabled")
+			}
+			slug, _ := p.String("slug")
+			store := model.Store(container)
+			j := store.GetBySlug(slug)
+			if j.ID == 0 {
+				return nil, errors.New("Entry \"" + slug + "\" not found")
+			}
+			if !j.CanEdit(graphqlUser(p)) {
+				return nil, errGraphQLEditor
+			}
+			ls := model.JournalLinks{Container: container}
+			j = ls.Load(j)
+			previous := j
+			if err := journalFromInput(p, &j); err != nil {
+				return nil, err
+			}
+			if j.Title == "" || j.Date == "" || j.Content == "" {
+				return nil, errors.New("An entry needs a title, date and content")
+			}
+			j, err := model.SaveJournal(container, previous, j)
+			if err != nil {
+				return nil, err
+			}
+			ping.Notify(container, j)
+			federation.Notify(container, j)
+			webhook.Updated(container, previous, j)
+			return j, nil
+		}},
+		"deleteJournal": {Args: []string{"slug"}, Resolve: func(p graphql.Params) (interface{}, error) {
+			container := graphqlContainer(p)
+			if !container.Configuration.EnableEdit {
+				return nil, errors.New("Editing entries is disabled")
+			}
+			slug, _ := p.String("slug")
+			js := model.Journals{Container: container}
+			j := model.Store(container).GetBySlug(slug)
+			if j.ID == 0 {
+				return nil, errors.New("Entry \"" + slug + "\" not found")
+			}
+			if !j.CanEdit(graphqlUser(p)) {
+				return nil, errGraphQLEditor
+			}
+			if err := js.Trash(j); err != nil {
+				return nil, err
+			}
+			webhook.Deleted(container, j)
+			return true, nil
+		}},
+	}}
+
+	return &graphql.Schema{Query: query, Mutation: mutation}
+}
@@ -0,0 +1,189 @@
+package apiv1
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func graphqlRequest(body string) *http.Request {
+	request, _ := http.NewRequest("POST", "/graphql", strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer admin-secret")
+
+	return request
+}
+
+func TestGraphQL_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableCreate = false
+	configuration.EnableEdit = false
+	configuration.AdminToken = "admin-secret"
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &GraphQL{}
+	controller.Init(container, []string{""})
+
+	// Test requests that cannot be read
+	controller.Run(response, graphqlRequest("not json"))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "must be a JSON object") {
+		t.Error("Expected bad request for invalid body")
+	}
+	response.Reset()
+	controller.Run(response, graphqlRequest(`{"query": ""}`))
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "Must provide a query") {
+		t.Error("Expected bad request without a query")
+	}
+	response.Reset()
+	request, _ := http.NewRequest("GET", "/graphql?query=%7Bjournals%7BtotalCount%7D%7D&variables=nope", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 400 || !strings.Contains(response.Content, "Variables must be a JSON object") {
+		t.Error("Expected bad request for invalid variables")
+	}
+
+	// Test a page of entries with cursors, sent as a GET request
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 5})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	query := url.Values{"query": {`query ($first: Int) { journals(first: $first, after: "Y3Vyc29yOjA=") { totalCount edges { cursor node { slug title } } pageInfo { hasNextPage hasPreviousPage endCursor } } }`}, "variables": {`{"first": 2}`}}
+	request, _ = http.NewRequest("GET", "/graphql?"+query.Encode(), nil)
+	controller.Run(response, request)
+	expected := `{"data":{"journals":{"totalCount":5,"edges":[{"cursor":"Y3Vyc29yOjE=","node":{"slug":"slug","title":"Title"}},{"cursor":"Y3Vyc29yOjI=","node":{"slug":"slug-2","title":"Title 2"}}],"pageInfo":{"hasNextPage":true,"hasPreviousPage":true,"endCursor":"Y3Vyc29yOjI="}}}}`
+	if response.StatusCode != 200 || strings.TrimSpace(response.Content) != expected {
+		t.Errorf("Expected page of entries, got %s", response.Content)
+	}
+
+	// Test invalid cursors and page sizes
+	response.Reset()
+	controller.Run(response, graphqlRequest(`{"query": "{ a: journals(after: \"bad\") { totalCount } b: journals(first: 500) { totalCount } }"}`))
+	if !strings.Contains(response.Content, `"a":null,"b":null`) || !strings.Contains(response.Content, `Invalid cursor \"bad\"`) || !strings.Contains(response.Content, "must be between 0 and 100") {
+		t.Errorf("Expected cursor and page size errors, got %s", response.Content)
+	}
+
+	// Test a single entry with its category and comments
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{CategoryID: 1})
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	db.AppendResult(&database.MockComment_MultipleRows{})
+	controller.Run(response, graphqlRequest(`{"query": "query One($slug: String!) { journal(slug: $slug) { title category { name } comments(first: 1) { totalCount nodes { author } } } }", "variables": {"slug": "slug"}, "operationName": "One"}`))
+	if !strings.Contains(response.Content, `{"title":"Title","category":{"name":"Travel"},"comments":{"totalCount":2,"nodes":[{"author":`) {
+		t.Errorf("Expected entry with its category and comments, got %s", response.Content)
+	}
+
+	// Test entries that are missing or private
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{Visibility: "private"})
+	request = graphqlRequest(`{"query": "{ journal(slug: \"slug\") { title } }"}`)
+	request.Header.Del("Authorization")
+	controller.Run(response, request)
+	if strings.TrimSpace(response.Content) != `{"data":{"journal":null}}` {
+		t.Errorf("Expected private entry to be hidden, got %s", response.Content)
+	}
+
+	// Test categories
+	response.Reset()
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	controller.Run(response, graphqlRequest(`{"query": "{ categories { slug } }"}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"categories":[{"slug":"cooking"},{"slug":"travel"},{"slug":"europe"}]}}` {
+		t.Errorf("Expected categories, got %s", response.Content)
+	}
+
+	// Test tags, with the entries given them and the tags of an entry
+	response.Reset()
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	controller.Run(response, graphqlRequest(`{"query": "{ tags { slug count } tag(slug: \"Road Trip\") { name journals(first: 2) { totalCount nodes { slug } } } }"}`))
+	expected = `{"data":{"tags":[{"slug":"road-trip","count":3},{"slug":"travel","count":1}],"tag":{"name":"Road Trip","journals":{"totalCount":3,"nodes":[{"slug":"slug"},{"slug":"slug-2"}]}}}}`
+	if strings.TrimSpace(response.Content) != expected {
+		t.Errorf("Expected tags and the entries given them, got %s", response.Content)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	controller.Run(response, graphqlRequest(`{"query": "{ journal(slug: \"slug\") { tags { name slug } } }"}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"journal":{"tags":[{"name":"Road Trip","slug":"road-trip"},{"name":"Travel","slug":"travel"}]}}}` {
+		t.Errorf("Expected tags of entry, got %s", response.Content)
+	}
+
+	// Test mutations are refused when disabled and over GET
+	response.Reset()
+	controller.Run(response, graphqlRequest(`{"query": "mutation { createJournal(input: {title: \"A\", date: \"2018-01-01\", content: \"B\"}) { slug } deleteJournal(slug: \"slug\") }"}`))
+	if !strings.Contains(response.Content, "Creating entries is disabled") || !strings.Contains(response.Content, "Editing entries is disabled") {
+		t.Errorf("Expected mutations to be refused, got %s", response.Content)
+	}
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/graphql?query=mutation%7BdeleteJournal(slug%3A%22slug%22)%7D", nil)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Mutations can only be sent with POST") {
+		t.Error("Expected mutation to be refused over GET")
+	}
+
+	// Test changes need the credentials of an editor
+	response.Reset()
+	container.Configuration.EnableCreate = true
+	container.Configuration.EnableEdit = true
+	request = graphqlRequest(`{"query": "mutation { createJournal(input: {title: \"A\", date: \"2018-01-01\", content: \"B\"}) { slug } }"}`)
+	request.Header.Del("Authorization")
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "needs the credentials of an editor") {
+		t.Errorf("Expected anonymous mutation to be refused, got %s", response.Content)
+	}
+
+	// Test creating, updating and deleting entries
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, graphqlRequest(`{"query": "mutation ($input: JournalInput!) { createJournal(input: $input) { slug title } }", "variables": {"input": {"title": "A New Entry", "date": "2018-01-01", "content": "Written"}}}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"createJournal":{"slug":"a-new-entry","title":"A New Entry"}}}` {
+		t.Errorf("Expected entry to be created, got %s", response.Content)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, graphqlRequest(`{"query": "mutation { createJournal(input: {title: \"Tagged\", date: \"2018-01-01\", content: \"Written\", tags: [\"Road Trip\", \"road trip\", \"Travel\"]}) { tags { slug } } }"}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"createJournal":{"tags":[{"slug":"road-trip"},{"slug":"travel"}]}}}` {
+		t.Errorf("Expected entry to be created with its tags, got %s", response.Content)
+	}
+	response.Reset()
+	controller.Run(response, graphqlRequest(`{"query": "mutation { createJournal(input: {title: \"A\", tags: \"Travel\"}) { slug } }"}`))
+	if !strings.Contains(response.Content, `Field \"tags\" in input must be a list of String`) {
+		t.Errorf("Expected tags that are not a list to be refused, got %s", response.Content)
+	}
+	response.Reset()
+	controller.Run(response, graphqlRequest(`{"query": "mutation { createJournal(input: {title: \"A\", colour: \"red\"}) { slug } }"}`))
+	if !strings.Contains(response.Content, `Unknown field \"colour\" in input`) {
+		t.Errorf("Expected unknown input to be refused, got %s", response.Content)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, graphqlRequest(`{"query": "mutation { updateJournal(slug: \"slug\", input: {title: \"Renamed\"}) { slug title content } }"}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"updateJournal":{"slug":"slug","title":"Renamed","content":"Content"}}}` {
+		t.Errorf("Expected entry to be updated, got %s", response.Content)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, graphqlRequest(`{"query": "mutation { deleteJournal(slug: \"missing\") }"}`))
+	if !strings.Contains(response.Content, `Entry \"missing\" not found`) {
+		t.Errorf("Expected missing entry to be reported, got %s", response.Content)
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, graphqlRequest(`{"query": "mutation { deleteJournal(slug: \"slug\") }"}`))
+	if strings.TrimSpace(response.Content) != `{"data":{"deleteJournal":true}}` {
+		t.Errorf("Expected entry to be moved to the trash, got %s", response.Content)
+	}
+}
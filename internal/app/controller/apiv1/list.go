@@ -18,7 +18,12 @@ type List struct {
 func (c *List) Run(response http.ResponseWriter, request *http.Request) {
 
 	js := model.Journals{Container: c.Super.Container.(*app.Container), Gs: model.GiphyAdapter(c.Super.Container.(*app.Container))}
-	journals := js.FetchAll()
+	var journals []model.Journal
+	if tag := request.URL.Query().Get("tag"); tag != "" {
+		journals = js.FindByTag(tag)
+	} else {
+		journals = js.FindAll(model.JournalOptions{})
+	}
 	response.Header().Add("Content-Type", "application/json")
 	encoder := json.NewEncoder(response)
 	encoder.SetEscapeHTML(false)
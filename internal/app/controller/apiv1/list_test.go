@@ -2,7 +2,6 @@ package apiv1
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -17,7 +16,6 @@ func TestList_Run(t *testing.T) {
 	response := &controller.MockResponse{}
 	response.Reset()
 	controller := &List{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test showing all Journals
 	controller.Init(container, []string{"", "0"})
@@ -27,4 +25,14 @@ func TestList_Run(t *testing.T) {
 	if !strings.Contains(response.Content, "Title 2") {
 		t.Error("Expected all journals to be returned")
 	}
+
+	// Test filtering by tag
+	response.Reset()
+	db.Rows = &database.MockJournal_MultipleRows{}
+	db.ExpectedArgument = "road-trip"
+	request, _ = http.NewRequest("GET", "/?tag=Road+Trip", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Title 2") || db.Queries != 2 {
+		t.Error("Expected journals given the tag to be returned")
+	}
 }
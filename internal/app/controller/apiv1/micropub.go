@@ -0,0 +1,191 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/micropub"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// Micropub Create and update entries from IndieWeb clients using the Micropub protocol
+type Micropub struct {
+	controller.Super
+}
+
+// Run Micropub action
+func (c *Micropub) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if request.Method == "GET" {
+		c.query(container, response, request)
+		return
+	}
+
+	request.Body = http.MaxBytesReader(response, request.Body, micropub.MaxSize(request))
+	r, err := micropub.ParseRequest(request)
+	if err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+	accessToken := auth.BearerToken(request)
+	if accessToken == "" {
+		accessToken = request.PostForm.Get("access_token")
+	}
+
+	switch r.Action {
+	case "create":
+		c.create(container, response, request, r, accessToken)
+	case "update", "delete", "undelete":
+		c.change(container, response, r, accessToken)
+	default:
+		micropub.WriteError(response, &micropub.Error{Status: http.StatusBadRequest, Code: "invalid_request", Description: "Unknown action " + r.Action})
+	}
+}
+
+func (c *Micropub) query(container *app.Container, response http.ResponseWriter, request *http.Request) {
+	if _, err := micropub.Authorize(container, auth.BearerToken(request), ""); err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+
+	var body interface{}
+	switch request.URL.Query().Get("q") {
+	case "config":
+		body = micropub.Config()
+	case "syndicate-to":
+		body = map[string][]string{"syndicate-to": {}}
+	case "category":
+		body = map[string][]string{"categories": micropub.Categories(container)}
+	case "source":
+		store := model.Store(container)
+		journal := store.GetBySlug(micropub.SlugFromURL(container, request.URL.Query().Get("url")))
+		if journal.ID == 0 {
+			micropub.WriteError(response, &micropub.Error{Status: http.StatusBadRequest, Code: "invalid_request", Description: "No entry was found at that URL"})
+			return
+		}
+		ls := model.JournalLinks{Container: container}
+		body = micropub.Source(container, ls.Load(journal), request.URL.Query()["properties[]"])
+	default:
+		micropub.WriteError(response, &micropub.Error{Status: http.StatusBadRequest, Code: "invalid_request", Description: "Unknown query"})
+		return
+	}
+
+	response.Header().Add("Content-Type", "application/json")
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(body)
+}
+
+func (c *Micropub) create(container *app.Container, response http.ResponseWriter, request *http.Request, r micropub.Request, accessToken string) {
+	if !container.Configuration.EnableCreate {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+	user, err := micropub.Authorize(container, accessToken, micropub.ScopeCreate)
+	if err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	if js.QuotaReached() {
+		micropub.WriteError(response, &micropub.Error{Status: http.StatusForbidden, Code: "forbidden", Description: "This journal has no room for more entries"})
+		return
+	}
+
+	journal, err := micropub.NewEntry(container, r)
+	if err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+	journal.AuthorID = user.ID
+	journal, err = model.SaveJournal(container, model.Journal{}, journal)
+	if err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+	ping.Notify(container, journal)
+	federation.Notify(container, journal)
+	webhook.Created(container, journal)
+
+	location := container.URL("/" + journal.Slug)
+	if location == "" {
+		location = proxy.Scheme(request) + "://" + request.Host + container.BasePath + "/" + journal.Slug
+	}
+	response.Header().Add("Location", location)
+	response.WriteHeader(http.StatusCreated)
+}
+
+func (c *Micropub) change(container *app.Container, response http.ResponseWriter, r micropub.Request, accessToken string) {
+	if !container.Configuration.EnableEdit {
+		response.WriteHeader(http.StatusForbidden)
+		return
+	}
+	scope := micropub.ScopeUpdate
+	if r.Action != "update" {
+		scope = micropub.ScopeDelete
+	}
+	user, err := micropub.Authorize(container, accessToken, scope)
+	if err != nil {
+		micropub.WriteError(response, err)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
+	slug := micropub.SlugFromURL(container, r.URL)
+	var journal model.Journal
+	if r.Action == "undelete" {
+		journal = js.FindDeletedBySlug(slug)
+	} else {
+		journal = store.GetBySlug(slug)
+	}
+	if journal.ID == 0 {
+		micropub.WriteError(response, &micropub.Error{Status: http.StatusBadRequest, Code: "invalid_request", Description: "No entry was found at that URL"})
+		return
+	}
+	if !journal.CanEdit(user) {
+		micropub.WriteError(response, &micropub.Error{Status: http.StatusForbidden, Code: "forbidden", Description: "The entry was written by someone else"})
+		return
+	}
+
+	switch r.Action {
+	case "delete":
+		err := js.Trash(journal)
+		if err != nil {
+			micropub.WriteError(response, err)
+			return
+		}
+		webhook.Deleted(container, journal)
+	case "undelete":
+		err := js.Restore(journal)
+		if err != nil {
+			micropub.WriteError(response, err)
+			return
+		}
+	default:
+		ls := model.JournalLinks{Container: container}
+		journal = ls.Load(journal)
+		previous := journal
+		if err := micropub.Update(container, &journal, r); err != nil {
+			micropub.WriteError(response, err)
+			return
+		}
+		saved, err := model.SaveJournal(container, previous, journal)
+		if err != nil {
+			micropub.WriteError(response, err)
+			return
+		}
+		ping.Notify(container, saved)
+		federation.Notify(container, saved)
+		webhook.Updated(container, previous, saved)
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
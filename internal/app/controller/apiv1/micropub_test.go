@@ -0,0 +1,163 @@
+package apiv1
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/micropub"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestMicropub_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Result = &database.MockResult{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin"
+	response := controller.NewMockResponse()
+	controller := &Micropub{}
+	controller.Init(container, []string{""})
+	post := func(body string, contentType string, token string) {
+		response.Reset()
+		request, _ := http.NewRequest("POST", "http://example.com/micropub", strings.NewReader(body))
+		request.Header.Add("Content-Type", contentType)
+		if token != "" {
+			request.Header.Add("Authorization", "Bearer "+token)
+		}
+		controller.Run(response, request)
+	}
+	form := "application/x-www-form-urlencoded"
+
+	// Test unauthorised
+	post(url.Values{"h": {"entry"}, "content": {"Hello"}}.Encode(), form, "")
+	if response.StatusCode != 401 || !strings.Contains(response.Content, `"error":"unauthorized"`) {
+		t.Errorf("Expected 401 without a token, got %d", response.StatusCode)
+	}
+
+	// Test JSON larger than allowed is refused
+	post(`{"type":["h-entry"],"properties":{"content":["`+strings.Repeat("a", micropub.MaxJSONSize)+`"]}}`, "application/json", "admin")
+	if response.StatusCode != 400 || db.Queries != 0 {
+		t.Errorf("Expected 400 for JSON larger than allowed, got %d", response.StatusCode)
+	}
+
+	// Test forbidden
+	container.Configuration.EnableCreate = false
+	post(url.Values{"h": {"entry"}, "content": {"Hello"}}.Encode(), form, "admin")
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error when creation is disabled")
+	}
+	container.Configuration.EnableCreate = true
+
+	// Test created, with the token sent in the form
+	post(url.Values{"h": {"entry"}, "name": {"Hello"}, "content": {"Hello there"}, "access_token": {"admin"}}.Encode(), form, "")
+	if response.StatusCode != 201 || response.Headers.Get("Location") != "http://example.com/hello" {
+		t.Errorf("Expected entry to be created, got %d at %s", response.StatusCode, response.Headers.Get("Location"))
+	}
+	container.Configuration.URL = "https://journal.example.com"
+	post(`{"type":["h-entry"],"properties":{"content":["Hello there"]}}`, "application/json", "admin")
+	if response.StatusCode != 201 || response.Headers.Get("Location") != "https://journal.example.com/hello-there" {
+		t.Errorf("Expected entry to be created at the site URL, got %d at %s", response.StatusCode, response.Headers.Get("Location"))
+	}
+
+	// Test invalid entry
+	post(`{"type":["h-entry"],"properties":{"name":["Empty"]}}`, "application/json", "admin")
+	if response.StatusCode != 400 || !strings.Contains(response.Content, `"error":"invalid_request"`) {
+		t.Error("Expected 400 error for an entry without content")
+	}
+
+	// Test unknown action
+	post(`{"action":"archive","url":"https://journal.example.com/slug"}`, "application/json", "admin")
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error for an unknown action")
+	}
+
+	// Test update of a missing entry
+	post(`{"action":"update","url":"https://journal.example.com/slug","replace":{"name":["New"]}}`, "application/json", "admin")
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error when updating a missing entry")
+	}
+
+	// Test update
+	db.Rows = &database.MockJournal_SingleRow{}
+	post(`{"action":"update","url":"https://journal.example.com/slug","replace":{"name":["New"]}}`, "application/json", "admin")
+	if response.StatusCode != 204 {
+		t.Errorf("Expected entry to be updated, got %d", response.StatusCode)
+	}
+
+	// Test forbidden update
+	container.Configuration.EnableEdit = false
+	db.Rows = &database.MockJournal_SingleRow{}
+	post(`{"action":"delete","url":"https://journal.example.com/slug"}`, "application/json", "admin")
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 error when editing is disabled")
+	}
+	container.Configuration.EnableEdit = true
+
+	// Test delete and undelete
+	db.Rows = &database.MockJournal_SingleRow{}
+	post(url.Values{"action": {"delete"}, "url": {"https://journal.example.com/slug"}}.Encode(), form, "admin")
+	if response.StatusCode != 204 {
+		t.Errorf("Expected entry to be deleted, got %d", response.StatusCode)
+	}
+	db.Rows = &database.MockJournal_SingleRow{DeletedAt: "2018-02-01 00:00:00"}
+	post(`{"action":"undelete","url":"https://journal.example.com/slug"}`, "application/json", "admin")
+	if response.StatusCode != 204 {
+		t.Errorf("Expected entry to be restored, got %d", response.StatusCode)
+	}
+}
+
+func TestMicropub_Run_Query(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin"
+	container.Configuration.URL = "https://journal.example.com"
+	response := controller.NewMockResponse()
+	controller := &Micropub{}
+	controller.Init(container, []string{""})
+	get := func(query string, token string) {
+		response.Reset()
+		request, _ := http.NewRequest("GET", "/micropub?"+query, strings.NewReader(""))
+		if token != "" {
+			request.Header.Add("Authorization", "Bearer "+token)
+		}
+		controller.Run(response, request)
+	}
+
+	get("q=config", "")
+	if response.StatusCode != 401 {
+		t.Error("Expected 401 without a token")
+	}
+
+	get("q=config", "admin")
+	if !strings.Contains(response.Content, `"syndicate-to":[]`) || !strings.Contains(response.Content, `"post-types"`) {
+		t.Errorf("Expected configuration, got %s", response.Content)
+	}
+
+	db.Rows = &database.MockCategory_MultipleRows{}
+	get("q=category", "admin")
+	if !strings.Contains(response.Content, `"categories":["Cooking","Europe","Travel"]`) {
+		t.Errorf("Expected categories, got %s", response.Content)
+	}
+
+	db.Rows = &database.MockJournal_SingleRow{}
+	get("q=source&url=https://journal.example.com/slug&properties[]=name", "admin")
+	if response.Content != "{\"properties\":{\"name\":[\"Title\"]}}\n" {
+		t.Errorf("Expected name of the entry, got %s", response.Content)
+	}
+
+	db.Rows = &database.MockRowsEmpty{}
+	get("q=source&url=https://journal.example.com/missing", "admin")
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error for a missing entry")
+	}
+
+	get("q=unknown", "admin")
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 error for an unknown query")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
 	"github.com/jamiefdhurst/journal/internal/app/model"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
@@ -17,13 +18,19 @@ type Single struct {
 // Run Single action
 func (c *Single) Run(response http.ResponseWriter, request *http.Request) {
 
-	js := model.Journals{Container: c.Super.Container.(*app.Container), Gs: model.GiphyAdapter(c.Super.Container.(*app.Container))}
-	journal := js.FindBySlug(c.Params[1])
+	store := model.Store(c.Super.Container.(*app.Container))
+	journal := store.GetBySlug(c.Params[1])
 
 	response.Header().Add("Content-Type", "application/json")
-	if journal.ID == 0 {
+	if journal.ID == 0 || !journal.IsPublished() || ((journal.IsPrivate() || journal.IsProtected()) && !auth.Authenticated(request, c.Super.Container.(*app.Container))) {
 		response.WriteHeader(http.StatusNotFound)
 	} else {
+		ls := model.JournalLinks{Container: c.Super.Container.(*app.Container)}
+		journal = ls.Load(journal)
+		ms := model.JournalMetas{Container: c.Super.Container.(*app.Container)}
+		journal = ms.Load(journal)
+		ts := model.JournalTags{Container: c.Super.Container.(*app.Container)}
+		journal = ts.Load(journal)
 		encoder := json.NewEncoder(response)
 		encoder.SetEscapeHTML(false)
 		encoder.Encode(journal)
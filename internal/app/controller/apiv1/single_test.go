@@ -2,7 +2,6 @@ package apiv1
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -17,7 +16,6 @@ func TestSingle_Run(t *testing.T) {
 	response := &controller.MockResponse{}
 	response.Reset()
 	controller := &Single{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test not found/error with GET
 	controller.Init(container, []string{"", "0"})
@@ -33,7 +31,50 @@ func TestSingle_Run(t *testing.T) {
 	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
 	db.Rows = &database.MockJournal_SingleRow{}
 	controller.Run(response, request)
-	if !strings.Contains(response.Content, "Title") {
+	if !strings.Contains(response.Content, "Title") || strings.Contains(response.Content, "canonical_url") {
 		t.Error("Expected content to be returned")
 	}
+
+	// Test drafts are not found
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{Status: "draft"}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when journal is a draft")
+	}
+
+	// Test private entries are not found unless authenticated
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{Visibility: "private"}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 error when journal is private")
+	}
+	response.Reset()
+	container.Configuration.AdminToken = "secret"
+	request.Header.Set("Authorization", "Bearer secret")
+	db.Rows = &database.MockJournal_SingleRow{Visibility: "private"}
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "Title") {
+		t.Error("Expected private entry to be returned when authenticated")
+	}
+	request.Header.Del("Authorization")
+
+	// Test return with links
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalLink_MultipleRows{})
+	db.AppendResult(&database.MockJournalMeta_MultipleRows{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "\"canonical_url\":\"https://example.com/original\"") || !strings.Contains(response.Content, "\"syndication\":[\"https://mastodon.example/@jamie/1\"]") {
+		t.Error("Expected links to be returned")
+	}
+	if !strings.Contains(response.Content, "\"meta\":{\"mood\":\"Happy\",\"weather\":\"Sunny\"}") {
+		t.Error("Expected custom fields to be returned")
+	}
+	if !strings.Contains(response.Content, "\"tags\":[\"Road Trip\",\"Travel\"]") {
+		t.Error("Expected tags to be returned")
+	}
 }
@@ -0,0 +1,26 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Stats Display statistics about the journal's entries
+type Stats struct {
+	controller.Super
+}
+
+// Run Stats action
+func (c *Stats) Run(response http.ResponseWriter, request *http.Request) {
+	ss := model.Statistics{Container: c.Super.Container.(*app.Container)}
+	stats := ss.Fetch()
+
+	response.Header().Add("Content-Type", "application/json")
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(stats)
+}
@@ -0,0 +1,45 @@
+package apiv1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestStats_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	response := controller.NewMockResponse()
+	controller := &Stats{}
+	controller.Init(container, []string{""})
+
+	// Test empty journal
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/api/stats", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "\"total_entries\":0") || !strings.Contains(response.Content, "\"months\":[]") || !strings.Contains(response.Content, "\"tags\":[]") {
+		t.Error("Expected empty statistics to be returned")
+	}
+
+	// Test statistics
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockStatsTotals_Row{})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018-01"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"1"}})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockStatsDays_Rows{Days: []string{"2018-01-01"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"Road Trip", "Travel"}})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "\"average_words\":250") || !strings.Contains(response.Content, "{\"label\":\"2018-01\",\"entries\":1,\"words\":100}") || !strings.Contains(response.Content, "Monday") {
+		t.Error("Expected statistics to be returned")
+	}
+	if !strings.Contains(response.Content, "\"tags\":[{\"label\":\"Road Trip\",\"entries\":1,\"words\":100,\"slug\":\"road-trip\"},{\"label\":\"Travel\",\"entries\":2,\"words\":200,\"slug\":\"travel\"}]") {
+		t.Error("Expected busiest tags to be returned")
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"net/http"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
@@ -22,19 +26,29 @@ func (c *Update) Run(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
-	journal := js.FindBySlug(c.Params[1])
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	ls := model.JournalLinks{Container: container}
+	ms := model.JournalMetas{Container: container}
+	ts := model.JournalTags{Container: container}
 
 	response.Header().Add("Content-Type", "application/json")
 	if journal.ID == 0 {
 		response.WriteHeader(http.StatusNotFound)
+	} else if !journal.CanEdit(auth.CurrentUser(request, container)) {
+		response.WriteHeader(http.StatusForbidden)
 	} else {
+		journal = ls.Load(journal)
+		journal = ms.Load(journal)
+		journal = ts.Load(journal)
 		var journalRequest = journalFromJSON{}
 		decoder := json.NewDecoder(request.Body)
 		err := decoder.Decode(&journalRequest)
-		if err != nil {
+		if err != nil || !journalRequest.applyLinks(&journal) || !journalRequest.applyMeta(&journal) {
 			response.WriteHeader(http.StatusBadRequest)
 		} else {
+			previous := journal
+
 			// Update only fields that are present
 			if journalRequest.Title != "" {
 				journal.Title = journalRequest.Title
@@ -45,7 +59,17 @@ func (c *Update) Run(response http.ResponseWriter, request *http.Request) {
 			if journalRequest.Content != "" {
 				journal.Content = journalRequest.Content
 			}
-			journal = js.Save(journal)
+			journalRequest.applyExcerpt(&journal)
+			journalRequest.applyVisibility(&journal)
+			journalRequest.applyTags(&journal)
+			journal, err = model.SaveJournal(container, previous, journal)
+			if err != nil {
+				response.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			ping.Notify(container, journal)
+			federation.Notify(container, journal)
+			webhook.Updated(container, previous, journal)
 			encoder := json.NewEncoder(response)
 			encoder.SetEscapeHTML(false)
 			encoder.Encode(journal)
@@ -2,7 +2,6 @@ package apiv1
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -14,15 +13,16 @@ import (
 func TestUpdate_Run(t *testing.T) {
 	db := &database.MockSqlite{}
 	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
 	response := &controller.MockResponse{}
 	response.Reset()
 	controller := &Update{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test forbidden
 	controller.Init(container, []string{"", "0"})
 	container.Configuration.EnableEdit = false
 	request, _ := http.NewRequest("POST", "/slug/edit", strings.NewReader("{\"not\":\"valid\":\"json\"}"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
 	request.Header.Add("Content-Type", "application/json")
 	controller.Run(response, request)
 	if response.StatusCode != 403 {
@@ -42,6 +42,7 @@ func TestUpdate_Run(t *testing.T) {
 	// Test for bad request on invalid JSON
 	response.Reset()
 	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("{\"not\":\"valid\":\"json\"}"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
 	request.Header.Add("Content-Type", "application/json")
 	db.Rows = &database.MockJournal_SingleRow{}
 	controller.Run(response, request)
@@ -52,10 +53,22 @@ func TestUpdate_Run(t *testing.T) {
 	// Test Journal is retrieved on save
 	response.Reset()
 	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("{\"title\":\"Something New\",\"date\":\"2018-01-01\",\"content\":\"New\"}"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
 	request.Header.Add("Content-Type", "application/json")
 	db.Rows = &database.MockJournal_SingleRow{}
 	controller.Run(response, request)
 	if response.StatusCode != 200 || !strings.Contains(response.Content, "Something New") {
 		t.Error("Expected new title to be within content")
 	}
+
+	// Test tags are replaced when given
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("{\"tags\":[\"Travel\",\"travel\",\"Road Trip\"]}"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/json")
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "\"tags\":[\"Travel\",\"Road Trip\"]") {
+		t.Error("Expected tags to be replaced, ignoring repeats")
+	}
 }
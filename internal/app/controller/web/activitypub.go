@@ -0,0 +1,219 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/activitypub"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// maxActivitySize Largest activity accepted into the inbox
+const maxActivitySize = 1 << 20
+
+// WebFinger Point fediverse servers looking up the journal's account to its actor
+type WebFinger struct {
+	controller.Super
+}
+
+// Run WebFinger action
+func (c *WebFinger) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	resource := request.URL.Query().Get("resource")
+	if !federation.Enabled(container) || (resource != "acct:"+federation.Account(container) && resource != federation.ActorID(container)) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeActivityJSON(response, "application/jrd+json", activitypub.WebFinger{
+		Subject: "acct:" + federation.Account(container),
+		Aliases: []string{federation.ActorID(container)},
+		Links: []activitypub.Link{
+			{Rel: "self", Type: activitypub.ContentType, Href: federation.ActorID(container)},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: container.URL("/")},
+		},
+	})
+}
+
+// ActivityPubActor Describe the journal as an account that can be followed
+type ActivityPubActor struct {
+	controller.Super
+}
+
+// Run ActivityPubActor action
+func (c *ActivityPubActor) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !federation.Enabled(container) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	actor, err := federation.Actor(container)
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writeActivityJSON(response, activitypub.ContentType, actor)
+}
+
+// ActivityPubOutbox List the journal's latest published entries for followers
+type ActivityPubOutbox struct {
+	controller.Super
+}
+
+// Run ActivityPubOutbox action
+func (c *ActivityPubOutbox) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !federation.Enabled(container) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeActivityJSON(response, activitypub.ContentType, federation.Outbox(container))
+}
+
+// ActivityPubFollowers Give the number of followers, without listing who they are
+type ActivityPubFollowers struct {
+	controller.Super
+}
+
+// Run ActivityPubFollowers action
+func (c *ActivityPubFollowers) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !federation.Enabled(container) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	fs := model.Followers{Container: container}
+	writeActivityJSON(response, activitypub.ContentType, activitypub.OrderedCollection{
+		Context:    activitypub.Context,
+		ID:         container.URL(federation.FollowersPath),
+		Type:       "OrderedCollection",
+		TotalItems: fs.Count(),
+	})
+}
+
+// ActivityPubArticle Serve a published entry as the article followers were sent
+type ActivityPubArticle struct {
+	controller.Super
+}
+
+// Run ActivityPubArticle action
+func (c *ActivityPubArticle) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if !federation.Enabled(container) || journal.ID == 0 || !journal.IsPublished() || !journal.IsListed() {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	article := federation.Article(container, journal)
+	article.Context = activitypub.Context
+	writeActivityJSON(response, activitypub.ContentType, article)
+}
+
+// ActivityPubInbox Receive activities from other servers, acting on follows and unfollows of the journal
+type ActivityPubInbox struct {
+	controller.Super
+}
+
+// Run ActivityPubInbox action
+func (c *ActivityPubInbox) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !federation.Enabled(container) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(response, request.Body, maxActivitySize))
+	activity := activitypub.Activity{}
+	if err != nil || json.Unmarshal(body, &activity) != nil || activity.Actor == "" {
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Accounts that no longer exist cannot be checked, and have nothing left to follow with
+	if activity.Type == "Delete" && activity.ObjectID() == activity.Actor {
+		response.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	sender, err := verifySender(container, request, body, activity)
+	if err != nil {
+		container.Log().Warn("Refused activity", "actor", activity.Actor, "err", err)
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case activity.Type == "Follow" && activity.ObjectID() == federation.ActorID(container):
+		err = federation.Accept(container, activity, sender)
+	case activity.Type == "Undo" && activity.ObjectType() == "Follow":
+		err = federation.Unfollow(container, sender.ID)
+	}
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+}
+
+// verifySender Fetch the actor said to have sent the activity from their own server, and check they signed the request
+// with their key. The key must be served from the same origin as the actor, so that a document hosted elsewhere cannot
+// claim to be them.
+func verifySender(container *app.Container, request *http.Request, body []byte, activity activitypub.Activity) (activitypub.Actor, error) {
+	signature, err := activitypub.ParseSignature(request)
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+	if !sameOrigin(signature.KeyID, activity.Actor) {
+		return activitypub.Actor{}, errors.New("The signing key is not served from the origin of " + activity.Actor)
+	}
+	client, err := federation.Client(container)
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+	sender, err := client.FetchActor(activity.Actor)
+	if err != nil {
+		return sender, err
+	}
+	if sender.ID != activity.Actor || sender.PublicKey.ID != signature.KeyID {
+		return sender, errors.New("The signing key does not belong to " + activity.Actor)
+	}
+	key, err := activitypub.ParsePublicKey(sender.PublicKey.PublicKeyPem)
+	if err != nil {
+		return sender, err
+	}
+
+	return sender, signature.Verify(request, body, key)
+}
+
+// sameOrigin Check two addresses share their scheme and host
+func sameOrigin(a string, b string) bool {
+	first, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	second, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return first.Scheme != "" && first.Host != "" && first.Scheme == second.Scheme && strings.EqualFold(first.Host, second.Host)
+}
+
+func writeActivityJSON(response http.ResponseWriter, contentType string, document interface{}) {
+	response.Header().Add("Content-Type", contentType+"; charset=utf-8")
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(document)
+}
@@ -0,0 +1,231 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/activitypub"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func federatedContainer(db *database.MockSqlite) *app.Container {
+	configuration := app.DefaultConfiguration()
+	configuration.URL = "https://example.com"
+	configuration.ActivityPubUser = "journal"
+
+	return &app.Container{Configuration: configuration, Db: db}
+}
+
+func actorKeyRow() *database.MockActorKey_SingleRow {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	return &database.MockActorKey_SingleRow{PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))}
+}
+
+func TestWebFinger_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &WebFinger{}
+	controller.Init(container, []string{""})
+
+	// Test not found when disabled
+	request, _ := http.NewRequest("GET", "/.well-known/webfinger?resource=acct:journal@example.com", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when federation is disabled")
+	}
+
+	// Test account found
+	response.Reset()
+	controller.Init(federatedContainer(db), []string{""})
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "application/jrd+json; charset=utf-8" || !strings.Contains(response.Content, `"subject":"acct:journal@example.com"`) || !strings.Contains(response.Content, `"rel":"self","type":"application/activity+json","href":"https://example.com/activitypub/actor"`) {
+		t.Errorf("Expected account to point to the actor, got %s", response.Content)
+	}
+
+	// Test other accounts
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/.well-known/webfinger?resource=acct:someone@example.com", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 for another account")
+	}
+}
+
+func TestActivityPubActor_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = actorKeyRow()
+	response := controller.NewMockResponse()
+	controller := &ActivityPubActor{}
+	controller.Init(federatedContainer(db), []string{""})
+	request, _ := http.NewRequest("GET", "/activitypub/actor", strings.NewReader(""))
+	controller.Run(response, request)
+	actor := activitypub.Actor{}
+	json.Unmarshal([]byte(response.Content), &actor)
+	if response.Headers.Get("Content-Type") != "application/activity+json; charset=utf-8" || actor.PreferredUsername != "journal" || !strings.HasPrefix(actor.PublicKey.PublicKeyPem, "-----BEGIN PUBLIC KEY-----") {
+		t.Errorf("Expected actor with its public key, got %s", response.Content)
+	}
+
+	// Test key error
+	response.Reset()
+	db.ErrorMode = true
+	controller.Run(response, request)
+	if response.StatusCode != 500 {
+		t.Error("Expected 500 when the key cannot be loaded")
+	}
+}
+
+func TestActivityPubOutbox_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockJournal_MultipleRows{}
+	response := controller.NewMockResponse()
+	controller := &ActivityPubOutbox{}
+	controller.Init(federatedContainer(db), []string{""})
+	request, _ := http.NewRequest("GET", "/activitypub/outbox", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `"totalItems":2`) || !strings.Contains(response.Content, `"id":"https://example.com/activitypub/entries/slug-2"`) {
+		t.Errorf("Expected entries in the outbox, got %s", response.Content)
+	}
+}
+
+func TestActivityPubFollowers_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockPagination_Result{TotalResults: 3}
+	response := controller.NewMockResponse()
+	controller := &ActivityPubFollowers{}
+	controller.Init(federatedContainer(db), []string{""})
+	request, _ := http.NewRequest("GET", "/activitypub/followers", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `"totalItems":3`) || strings.Contains(response.Content, "orderedItems") {
+		t.Errorf("Expected number of followers only, got %s", response.Content)
+	}
+}
+
+func TestActivityPubArticle_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	response := controller.NewMockResponse()
+	controller := &ActivityPubArticle{}
+	controller.Init(federatedContainer(db), []string{"", "slug"})
+	request, _ := http.NewRequest("GET", "/activitypub/entries/slug", strings.NewReader(""))
+
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `"type":"Article"`) || !strings.Contains(response.Content, `"url":"https://example.com/slug"`) {
+		t.Errorf("Expected entry as an article, got %s", response.Content)
+	}
+
+	// Test unlisted entries are not found
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{Visibility: "unlisted"}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 for an unlisted entry")
+	}
+}
+
+func TestActivityPubInbox_Run(t *testing.T) {
+	remoteKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	remotePem, _ := activitypub.EncodePublicKey(&remoteKey.PublicKey)
+	var remote *httptest.Server
+	remote = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := activitypub.ParseSignature(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(activitypub.Actor{
+			ID:        remote.URL + "/actor",
+			Type:      "Person",
+			Inbox:     remote.URL + "/inbox",
+			PublicKey: activitypub.PublicKey{ID: remote.URL + "/actor#main-key", Owner: remote.URL + "/actor", PublicKeyPem: remotePem},
+		})
+	}))
+	defer remote.Close()
+
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	response := controller.NewMockResponse()
+	controller := &ActivityPubInbox{}
+	controller.Init(federatedContainer(db), []string{""})
+	post := func(activity string, key *rsa.PrivateKey) {
+		response.Reset()
+		db.Queries = 0
+		db.Rows = actorKeyRow()
+		request, _ := http.NewRequest("POST", "https://example.com/activitypub/inbox", strings.NewReader(activity))
+		if key != nil {
+			activitypub.Sign(request, []byte(activity), remote.URL+"/actor#main-key", key)
+		}
+		controller.Run(response, request)
+	}
+	follow := `{"id":"` + remote.URL + `/follows/1","type":"Follow","actor":"` + remote.URL + `/actor","object":"https://example.com/activitypub/actor"}`
+
+	// Test invalid activity
+	post("{", remoteKey)
+	if response.StatusCode != 400 {
+		t.Error("Expected 400 for an invalid activity")
+	}
+
+	// Test unsigned and wrongly signed activities are refused
+	post(follow, nil)
+	if response.StatusCode != 401 {
+		t.Error("Expected 401 for an unsigned activity")
+	}
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	post(follow, otherKey)
+	if response.StatusCode != 401 {
+		t.Error("Expected 401 for an activity signed with another key")
+	}
+	post(strings.Replace(follow, remote.URL+`/actor","object`, `https://elsewhere.example.com/actor","object`, 1), remoteKey)
+	if response.StatusCode != 401 {
+		t.Error("Expected 401 for an activity sent on behalf of someone else")
+	}
+
+	// Test activities signed with a key served from another origin are refused, even when its document claims the actor
+	evilKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	evilPem, _ := activitypub.EncodePublicKey(&evilKey.PublicKey)
+	var evil *httptest.Server
+	evil = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(activitypub.Actor{
+			ID:        remote.URL + "/actor",
+			Type:      "Person",
+			Inbox:     evil.URL + "/inbox",
+			PublicKey: activitypub.PublicKey{ID: evil.URL + "/actor#main-key", Owner: remote.URL + "/actor", PublicKeyPem: evilPem},
+		})
+	}))
+	defer evil.Close()
+	for _, activity := range []string{follow, `{"type":"Undo","actor":"` + remote.URL + `/actor","object":{"type":"Follow","object":"https://example.com/activitypub/actor"}}`} {
+		response.Reset()
+		db.Queries = 0
+		request, _ := http.NewRequest("POST", "https://example.com/activitypub/inbox", strings.NewReader(activity))
+		activitypub.Sign(request, []byte(activity), evil.URL+"/actor#main-key", evilKey)
+		controller.Run(response, request)
+		if response.StatusCode != 401 || db.Queries != 0 {
+			t.Errorf("Expected 401 for an activity signed with a key from another origin, got %d", response.StatusCode)
+		}
+	}
+
+	// Test follow is accepted, saving the follower and queueing the reply
+	post(follow, remoteKey)
+	if response.StatusCode != 202 || db.Queries != 3 {
+		t.Errorf("Expected follow to be accepted, got %d with %d queries", response.StatusCode, db.Queries)
+	}
+
+	// Test unfollow
+	post(`{"type":"Undo","actor":"`+remote.URL+`/actor","object":{"type":"Follow","object":"https://example.com/activitypub/actor"}}`, remoteKey)
+	if response.StatusCode != 202 || db.Queries != 2 {
+		t.Errorf("Expected follower to be removed, got %d with %d queries", response.StatusCode, db.Queries)
+	}
+
+	// Test deleted accounts are acknowledged without being checked
+	post(`{"type":"Delete","actor":"https://gone.example.com/actor","object":"https://gone.example.com/actor"}`, nil)
+	if response.StatusCode != 202 || db.Queries != 0 {
+		t.Error("Expected deleted account to be acknowledged")
+	}
+}
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Attachments Handle listing the files attached to an entry and attaching new ones
+type Attachments struct {
+	controller.Super
+	Attachments []model.Attachment
+	Journal     model.Journal
+	Limit       int
+}
+
+// Run Attachments action
+func (c *Attachments) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	store := model.Store(container)
+	c.Journal = store.GetBySlug(c.Params[1])
+	if c.Journal.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if !c.Journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	as := model.Attachments{Container: container}
+	if request.Method == "POST" {
+		c.attach(response, request, as)
+		return
+	}
+
+	c.Limit = container.Configuration.AttachmentLimit
+	c.Attachments = as.FetchByJournal(c.Journal.ID)
+
+	template, err := container.Templates("_layout/default.tmpl", "attachments.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+func (c *Attachments) attach(response http.ResponseWriter, request *http.Request, as model.Attachments) {
+	container := c.Super.Container.(*app.Container)
+	page := container.BasePath + "/" + c.Journal.Slug + "/attachments"
+
+	request.Body = http.MaxBytesReader(response, request.Body, media.AttachmentLimit(container)+1<<20)
+	file, header, err := request.FormFile("file")
+	if err != nil {
+		flash.Error(response, request, container, "Choose a file to attach.")
+		http.Redirect(response, request, page, 302)
+		return
+	}
+	defer file.Close()
+
+	stored, size, contentType, err := media.StoreAttachment(container, header.Filename, file)
+	switch err {
+	case nil:
+	case media.ErrTooLarge:
+		flash.Error(response, request, container, "Attachments must be %dMB or smaller.", container.Configuration.AttachmentLimit)
+		http.Redirect(response, request, page, 302)
+		return
+	default:
+		flash.Error(response, request, container, "Choose a file to attach.")
+		http.Redirect(response, request, page, 302)
+		return
+	}
+
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	attachment, err := as.Save(model.Attachment{JournalID: c.Journal.ID, Name: name, File: stored, ContentType: contentType, Size: int(size)})
+	if err != nil {
+		media.RemoveAttachment(container, stored)
+		flash.Error(response, request, container, "Choose a file to attach.")
+		http.Redirect(response, request, page, 302)
+		return
+	}
+
+	flash.Success(response, request, container, "%s attached.", attachment.Name)
+	http.Redirect(response, request, page, 302)
+}
+
+// AttachmentFile Handle downloading a file attached to an entry, for those who may read the entry
+type AttachmentFile struct {
+	controller.Super
+}
+
+// Run AttachmentFile action
+func (c *AttachmentFile) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+
+	// Files attached to drafts and entries waiting to be published are hidden as the entries are
+	if journal.ID == 0 || (!journal.IsPublished() && !journal.CanEdit(auth.CurrentUser(request, container))) {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+	if journal.IsPrivate() && !auth.Authenticated(request, container) {
+		RunUnauthorised(response, request, c.Super.Container)
+		return
+	}
+	if !isUnlocked(request, journal) && !auth.Authenticated(request, container) {
+		http.Redirect(response, request, container.BasePath+"/"+journal.Slug, 302)
+		return
+	}
+
+	as := model.Attachments{Container: container}
+	id, _ := strconv.Atoi(c.Params[2])
+	attachment := as.FindByID(journal.ID, id)
+	path, ok := media.FindAttachment(container, attachment.File)
+	if attachment.ID == 0 || !ok {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	response.Header().Set("Content-Disposition", disposition)
+	response.Header().Set("Content-Type", attachment.ContentType)
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(response, request, path)
+}
+
+// AttachmentDelete Handle removing a file attached to an entry
+type AttachmentDelete struct {
+	controller.Super
+}
+
+// Run AttachmentDelete action
+func (c *AttachmentDelete) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if journal.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+	if !journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	as := model.Attachments{Container: container}
+	id, _ := strconv.Atoi(c.Params[2])
+	attachment := as.FindByID(journal.ID, id)
+	if attachment.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	as.Delete(attachment)
+	flash.Success(response, request, container, "Attachment removed.")
+	http.Redirect(response, request, container.BasePath+"/"+journal.Slug+"/attachments", 302)
+}
@@ -0,0 +1,207 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestAttachments_Run(t *testing.T) {
+	container := mediaContainer(t)
+	container.Configuration.EnableEdit = false
+	container.Configuration.AdminToken = "admin-secret"
+	db := container.Db.(*database.MockSqlite)
+	db.Result = &database.MockResult{}
+	response := controller.NewMockResponse()
+	controller := &Attachments{}
+
+	// Test not found when editing is disabled or the entry is missing
+	controller.Init(container, []string{"", "slug"})
+	request, _ := http.NewRequest("GET", "/slug/attachments", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when journal not found")
+	}
+
+	// Test attachments are not listed to those who may not edit the entry
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug/attachments", strings.NewReader(""))
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, request)
+	if response.StatusCode != 403 || strings.Contains(response.Content, "Report &lt;1&gt;.pdf") {
+		t.Error("Expected attachments to be forbidden to readers")
+	}
+
+	// Test attachments listed with the upload form
+	response.Reset()
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockAttachment_MultipleRows{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `enctype="multipart/form-data"`) || !strings.Contains(response.Content, "up to 20MB") {
+		t.Error("Expected upload form with the size limit")
+	}
+	if !strings.Contains(response.Content, `<a href="/slug/attachments/1">Report &lt;1&gt;.pdf</a>`) || !strings.Contains(response.Content, `action="/slug/attachments/2/delete"`) {
+		t.Error("Expected attachments to be listed with remove buttons")
+	}
+
+	// Test attaching a file
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, uploadRequest("../Report.pdf", []byte("%PDF-1.4 report")))
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/attachments" || !flashed(response, app.FlashSuccess) {
+		t.Errorf("Expected redirect back to attachments, got %s", response.Headers.Get("Location"))
+	}
+	if flash.Left(response)[0].Text != "Report.pdf attached." {
+		t.Error("Expected the attached file to be named")
+	}
+
+	// Test size limit and missing files
+	response.Reset()
+	container.Configuration.AttachmentLimit = 1
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, uploadRequest("big.zip", make([]byte, 1<<20+1)))
+	if response.Headers.Get("Location") != "/slug/attachments" || !flashed(response, app.FlashError) {
+		t.Error("Expected size error for large file")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, uploadRequest("", nil))
+	if response.Headers.Get("Location") != "/slug/attachments" || !flashed(response, app.FlashError) {
+		t.Error("Expected upload error when no file is sent")
+	}
+
+	// Test stored file is removed if it cannot be recorded
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.ErrorAtQuery = db.Queries + 2
+	controller.Run(response, uploadRequest("notes.txt", []byte("Notes")))
+	if response.Headers.Get("Location") != "/slug/attachments" || !flashed(response, app.FlashError) {
+		t.Error("Expected upload error when the attachment cannot be saved")
+	}
+}
+
+func TestAttachmentFile_Run(t *testing.T) {
+	container := mediaContainer(t)
+	db := container.Db.(*database.MockSqlite)
+	response := controller.NewMockResponse()
+	controller := &AttachmentFile{}
+	stored, _, _, _ := media.StoreAttachment(container, "report.pdf", strings.NewReader("%PDF-1.4 report"))
+
+	// Test not found entry and attachment
+	controller.Init(container, []string{"", "slug", "1"})
+	request, _ := http.NewRequest("GET", "/slug/attachments/1", strings.NewReader(""))
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when journal not found")
+	}
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when attachment not found")
+	}
+
+	// Test file is downloaded under its original name
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockAttachment_SingleRow{File: stored})
+	controller.Run(response, request)
+	if response.Content != "%PDF-1.4 report" || response.Headers.Get("Content-Type") != "application/pdf" {
+		t.Errorf("Expected file to be served, got %s", response.Content)
+	}
+	if response.Headers.Get("Content-Disposition") != `attachment; filename="Report <1>.pdf"` || response.Headers.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected file to be downloaded, got %s", response.Headers.Get("Content-Disposition"))
+	}
+
+	// Test drafts and scheduled entries keep their attachments from those who may not edit them
+	for _, status := range []string{model.JournalStatusDraft, model.JournalStatusScheduled} {
+		response.Reset()
+		db.AppendResult(&database.MockJournal_SingleRow{Status: status})
+		controller.Run(response, request)
+		if response.StatusCode != 404 || response.Content == "%PDF-1.4 report" {
+			t.Errorf("Expected %s entry's attachment not to be found", status)
+		}
+	}
+
+	// Test private and protected entries keep their attachments to themselves
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{Visibility: "private"})
+	controller.Run(response, request)
+	if response.StatusCode != 401 || response.Content == "%PDF-1.4 report" {
+		t.Error("Expected private entry's attachment to require authentication")
+	}
+	protected := model.Journal{ID: 1}
+	protected.SetPassword("secret")
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash})
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug" {
+		t.Error("Expected protected entry's attachment to send reader to unlock it")
+	}
+}
+
+func TestAttachmentDelete_Run(t *testing.T) {
+	container := mediaContainer(t)
+	container.Configuration.EnableEdit = false
+	container.Configuration.AdminToken = "admin-secret"
+	db := container.Db.(*database.MockSqlite)
+	db.Result = &database.MockResult{}
+	response := controller.NewMockResponse()
+	controller := &AttachmentDelete{}
+	stored, _, _, _ := media.StoreAttachment(container, "report.pdf", strings.NewReader("%PDF-1.4 report"))
+
+	// Test not found when editing is disabled, or the entry or attachment is missing
+	controller.Init(container, []string{"", "slug", "1"})
+	request, _ := http.NewRequest("POST", "/slug/attachments/1/delete", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when journal not found")
+	}
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when attachment not found")
+	}
+
+	// Test attachment removed with its file
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockAttachment_SingleRow{File: stored})
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/attachments" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect back to attachments")
+	}
+	if _, ok := media.FindAttachment(container, stored); ok {
+		t.Error("Expected attached file to be removed")
+	}
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Author Handle listing the entries written by a user
+type Author struct {
+	controller.Super
+	Author     model.User
+	Journals   []model.Journal
+	Pages      []int
+	Pagination database.PaginationInformation
+}
+
+// Run Author action
+func (c *Author) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	us := model.Users{Container: container}
+	c.Author = us.FindByUsername(c.Params[1])
+	if c.Author.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+
+	js := model.Journals{Container: container}
+	c.Journals, c.Pagination = js.FetchPaginatedByAuthor(c.Author.ID, pagination)
+	for i := range c.Journals {
+		c.Journals[i].Author = c.Author.Username
+	}
+
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "author.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestAuthor_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Author{}
+
+	// Test not found
+	controller.Init(container, []string{"", "nobody"})
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/author/nobody", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || !strings.Contains(response.Content, "Page Not Found") {
+		t.Error("Expected 404 when author not found")
+	}
+
+	// Test entries written by the author are listed
+	response.Reset()
+	controller.Init(container, []string{"", "jamie"})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/author/jamie", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Entries by jamie</h2>") || !strings.Contains(response.Content, "Title 2") {
+		t.Error("Expected author's entries to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, "/author/jamie?page=2") {
+		t.Error("Expected pagination links to stay with the author")
+	}
+
+	// Test empty message
+	response.Reset()
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "jamie has not published any entries yet") {
+		t.Error("Expected message when the author has no entries")
+	}
+}
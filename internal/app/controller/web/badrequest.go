@@ -2,8 +2,8 @@ package web
 
 import (
 	"net/http"
-	"text/template"
 
+	"github.com/jamiefdhurst/journal/internal/app"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
@@ -14,11 +14,13 @@ type BadRequest struct {
 
 // Run BadRequest
 func (c *BadRequest) Run(response http.ResponseWriter, request *http.Request) {
+	container, _ := c.Super.Container.(*app.Container)
+	template, err := container.Templates("_layout/default.tmpl", "error.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
 	response.WriteHeader(http.StatusNotFound)
-
-	template, _ := template.ParseFiles(
-		"./web/templates/_layout/default.tmpl",
-		"./web/templates/error.tmpl")
 	template.ExecuteTemplate(response, "layout", c)
 }
 
@@ -2,7 +2,6 @@ package web
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -14,7 +13,6 @@ func TestError_Run(t *testing.T) {
 	response := &controller.MockResponse{}
 	controller := &BadRequest{}
 	controller.Init(&app.Container{}, []string{})
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test header and response
 	controller.Run(response, &http.Request{})
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Category Handle listing the entries filed in a category and any nested beneath it
+type Category struct {
+	controller.Super
+	Ancestors  []model.Category
+	Category   model.Category
+	Children   []model.Category
+	Journals   []model.Journal
+	Pages      []int
+	Pagination database.PaginationInformation
+}
+
+// Run Category action
+func (c *Category) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	cs := model.Categories{Container: container}
+	c.Category = cs.FindBySlug(c.Params[1])
+	if c.Category.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+
+	js := model.Journals{Container: container}
+	c.Ancestors = cs.Ancestors(c.Category)
+	c.Children = cs.Children(c.Category)
+	c.Journals, c.Pagination = js.FetchPaginatedByCategory(cs.Descendants(c.Category), pagination)
+	us := model.Users{Container: container}
+	c.Journals = us.LoadAuthors(c.Journals)
+
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "category.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCategory_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Category{}
+
+	// Test not found
+	controller.Init(container, []string{"", "missing"})
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/category/missing", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || !strings.Contains(response.Content, "Page Not Found") {
+		t.Error("Expected 404 when category not found")
+	}
+
+	// Test entries from the category and those nested beneath it are listed
+	response.Reset()
+	controller.Init(container, []string{"", "travel"})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/category/travel", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Travel</h2>") || !strings.Contains(response.Content, "Title 2") {
+		t.Error("Expected category entries to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, "/category/travel?page=2") {
+		t.Error("Expected pagination links to stay within the category")
+	}
+
+	// Test breadcrumb, children and empty message
+	response.Reset()
+	db.AppendResult(&database.MockCategory_SingleRow{ParentID: 2})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `href="/category/europe">Europe</a> &rsaquo;`) || !strings.Contains(response.Content, "category-children") {
+		t.Error("Expected parent categories and children to be linked")
+	}
+	if !strings.Contains(response.Content, "There are no entries in this category yet") {
+		t.Error("Expected empty category message")
+	}
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/spam"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Limits on the length of each comment field, in characters
+const (
+	commentMaxAuthor  = 100
+	commentMaxContent = 5000
+)
+
+// Comment Handle a reader leaving a comment on an entry, which is held for moderation
+type Comment struct {
+	controller.Super
+}
+
+// Run Comment action
+func (c *Comment) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if journal.ID == 0 || !journal.IsPublished() || journal.IsPrivate() || !isUnlocked(request, journal) || !journal.CommentsOpen() {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	comment := model.Comment{
+		JournalID: journal.ID,
+		Author:    strings.TrimSpace(request.FormValue("author")),
+		Email:     strings.TrimSpace(request.FormValue("email")),
+		URL:       strings.TrimSpace(request.FormValue("url")),
+		Content:   strings.TrimSpace(request.FormValue("content")),
+		UserAgent: request.UserAgent(),
+	}
+	if comment.Author == "" || comment.Content == "" ||
+		utf8.RuneCountInString(comment.Author) > commentMaxAuthor || utf8.RuneCountInString(comment.Content) > commentMaxContent ||
+		(comment.URL != "" && !model.IsValidLinkURL(comment.URL)) {
+		flash.Error(response, request, container, "Please enter your name and a comment, and check any website is a full web address.")
+		http.Redirect(response, request, container.BasePath+"/"+journal.Slug, 302)
+		return
+	}
+	comment.IP, _, _ = net.SplitHostPort(request.RemoteAddr)
+
+	// Spam is kept out of the queue but the reader is told the same either way
+	if spam.Check(container, comment.SpamCheck(container.URL("/"+journal.Slug))) {
+		comment.Status = model.CommentStatusSpam
+	}
+	cs := model.Comments{Container: container}
+	cs.Save(comment)
+
+	flash.Success(response, request, container, "Thank you, your comment will appear once it has been approved.")
+	http.Redirect(response, request, container.BasePath+"/"+journal.Slug, 302)
+}
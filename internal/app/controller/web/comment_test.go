@@ -0,0 +1,88 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestComment_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Comment{}
+	controller.Init(container, []string{"", "slug"})
+	post := func(body string) *http.Request {
+		request, _ := http.NewRequest("POST", "/slug/comments", strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		request.RemoteAddr = "127.0.0.1:1234"
+		return request
+	}
+
+	// Test unknown, draft, private and closed entries cannot be commented on
+	for _, rows := range []*database.MockJournal_SingleRow{nil, {Status: "draft"}, {Visibility: "private"}, {Comments: "closed"}} {
+		response.Reset()
+		db.Queries = 0
+		db.Rows = &database.MockRowsEmpty{}
+		if rows != nil {
+			db.Rows = rows
+		}
+		controller.Run(response, post("author=Reader&content=Hi"))
+		if response.StatusCode != 404 || db.Queries != 1 {
+			t.Error("Expected 404 when comments cannot be left")
+		}
+	}
+
+	// Test protected entries cannot be commented on until unlocked
+	protected := model.Journal{ID: 1}
+	protected.SetPassword("secret")
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash}
+	controller.Run(response, post("author=Reader&content=Hi"))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when a protected entry has not been unlocked")
+	}
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash}
+	request := post("author=Reader&content=Hi")
+	request.AddCookie(&http.Cookie{Name: "journal_unlock_1", Value: protected.UnlockToken()})
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/slug" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected comment to be held once a protected entry is unlocked")
+	}
+
+	// Test missing fields and invalid website
+	for _, body := range []string{"author=Reader", "content=Hi", "author=Reader&content=Hi&url=javascript:alert(1)"} {
+		response.Reset()
+		db.Queries = 0
+		db.Rows = &database.MockJournal_SingleRow{}
+		controller.Run(response, post(body))
+		if response.Headers.Get("Location") != "/slug" || !flashed(response, app.FlashError) || db.Queries != 1 {
+			t.Errorf("Expected error redirect for %s", body)
+		}
+	}
+
+	// Test comment held for moderation
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, post("author=Reader&content=Great+post&url=https%3A%2F%2Freader.example.com"))
+	if response.Headers.Get("Location") != "/slug" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected comment to be saved and held for moderation")
+	}
+
+	// Test spam is saved with the same response
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, post("author=Reader&content=Cheap+pills+here"))
+	if response.Headers.Get("Location") != "/slug" || !flashed(response, app.FlashSuccess) || db.Queries != 2 {
+		t.Error("Expected spam to be saved without telling the sender")
+	}
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Delete Handle moving an entry to the trash
+type Delete struct {
+	controller.Super
+}
+
+// Run Delete action
+func (c *Delete) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if journal.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+	if !journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	if js.Trash(journal) == nil {
+		webhook.Deleted(container, journal)
+	}
+	flash.Success(response, request, container, "Journal moved to the trash.")
+	http.Redirect(response, request, container.BasePath+"/", 302)
+}
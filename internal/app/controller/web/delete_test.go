@@ -0,0 +1,72 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestDelete_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	container.Configuration.AdminToken = "admin-secret"
+	controller := &Delete{}
+	controller.Init(container, []string{"", "slug"})
+	request, _ := http.NewRequest("POST", "/slug/delete", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+
+	// Test not found when editing is disabled
+	container.Configuration.EnableEdit = false
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test not found
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when journal not found")
+	}
+
+	// Test moved to trash
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) || db.Queries != 3 {
+		t.Error("Expected redirect back to home with deleted flag")
+	}
+
+	// Test only the author or an admin may delete an entry
+	user := model.User{}
+	user.SetPassword("password123")
+	request, _ = http.NewRequest("POST", "/slug/delete", strings.NewReader(""))
+	request.SetBasicAuth("jamie", "password123")
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{AuthorID: 2})
+	db.AppendResult(&database.MockPagination_Result{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: user.PasswordHash, Role: model.RoleEditor})
+	controller.Run(response, request)
+	if response.StatusCode != 403 {
+		t.Error("Expected 403 when deleting the entry of another author")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{AuthorID: 1})
+	db.AppendResult(&database.MockPagination_Result{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: user.PasswordHash, Role: model.RoleEditor})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected author to be able to delete their own entry")
+	}
+}
@@ -0,0 +1,34 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Drafts Handle listing the entries that have not been published yet
+type Drafts struct {
+	controller.Super
+	Journals []model.Journal
+}
+
+// Run Drafts action
+func (c *Drafts) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableCreate {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	c.Journals = js.FetchDrafts()
+
+	template, err := container.Templates("_layout/default.tmpl", "drafts.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestDrafts_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.EnableCreate = false
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Drafts{}
+
+	// Test not found when creating is disabled
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/drafts", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when creating is disabled")
+	}
+
+	// Test no drafts
+	response.Reset()
+	container.Configuration.EnableCreate = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There are no drafts") {
+		t.Error("Expected empty message to be displayed on screen")
+	}
+
+	// Test drafts listed
+	response.Reset()
+	db.Rows = &database.MockJournal_MultipleRows{}
+	request, _ = http.NewRequest("GET", "/drafts", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Title 2") || !strings.Contains(response.Content, "/slug-2/edit") {
+		t.Error("Expected drafts to be displayed on screen")
+	}
+
+	// Test scheduled entries show when they will be published
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{Status: "scheduled", PublishAt: "2030-01-02 09:00:00"}
+	request, _ = http.NewRequest("GET", "/drafts", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Scheduled for Wednesday January 2, 2030") {
+		t.Error("Expected scheduled entry to be displayed on screen")
+	}
+}
@@ -2,18 +2,22 @@ package web
 
 import (
 	"net/http"
-	"text/template"
+	"strings"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
 // Edit Handle updating an existing entry
 type Edit struct {
 	controller.Super
-	Error   bool
-	Journal model.Journal
+	Categories []model.Category
+	Journal    model.Journal
 }
 
 // Run Edit action
@@ -23,35 +27,81 @@ func (c *Edit) Run(response http.ResponseWriter, request *http.Request) {
 		RunBadRequest(response, request, c.Super.Container)
 	}
 
-	js := model.Journals{Container: c.Super.Container.(*app.Container), Gs: model.GiphyAdapter(c.Super.Container.(*app.Container))}
-	c.Journal = js.FindBySlug(c.Params[1])
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
+	c.Journal = store.GetBySlug(c.Params[1])
 
 	if c.Journal.ID == 0 {
 		RunBadRequest(response, request, c.Super.Container)
+	} else if !c.Journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
 	} else {
 
+		ls := model.JournalLinks{Container: container}
+		ms := model.JournalMetas{Container: container}
+		ts := model.JournalTags{Container: container}
+		cs := model.Categories{Container: container}
 		if request.Method == "GET" {
-			query := request.URL.Query()
-			if query["error"] != nil {
-				c.Error = true
-			}
-			template, _ := template.ParseFiles(
-				"./web/templates/_layout/default.tmpl",
-				"./web/templates/edit.tmpl",
-				"./web/templates/_partial/form.tmpl")
+			c.Journal = ls.Load(c.Journal)
+			c.Journal = ms.Load(c.Journal)
+			c.Journal = ts.Load(c.Journal)
+			c.Categories = cs.FetchTree()
+			template, err := container.Templates("_layout/default.tmpl", "edit.tmpl", "_partial/form.tmpl")
+			if err != nil {
+				RunServerError(response, request, c.Super.Container, err)
+				return
+			}
 			template.ExecuteTemplate(response, "layout", c)
 		} else {
 			if request.FormValue("title") == "" || request.FormValue("date") == "" || request.FormValue("content") == "" {
-				http.Redirect(response, request, "/"+c.Journal.Slug+"/edit?error=1", 302)
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entryError)
 				return
 			}
 
+			previous := c.Journal
 			c.Journal.Title = request.FormValue("title")
 			c.Journal.Date = request.FormValue("date")
 			c.Journal.Content = request.FormValue("content")
-			js.Save(c.Journal)
+			c.Journal.Excerpt = strings.TrimSpace(request.FormValue("excerpt"))
+			c.Journal.Status = statusFromForm(request)
+			c.Journal.Comments = commentsFromForm(request)
+			c.Journal.Pinned = request.FormValue("pinned") == "1"
+			c.Journal.Visibility = visibilityFromForm(request)
+			c.Journal.CategoryID = categoryFromForm(request, cs)
+			if !linksFromForm(request, &c.Journal) {
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entryLinkError)
+				return
+			}
+			if !metaFromForm(request, &c.Journal) {
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entryMetaError)
+				return
+			}
+			tagsFromForm(request, &c.Journal)
+			if !scheduleFromForm(request, &c.Journal) {
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entryScheduleError)
+				return
+			}
+			if !passwordFromForm(request, &c.Journal) {
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entryPasswordError)
+				return
+			}
+			if !slugFromForm(request, js, &c.Journal) {
+				redirectFailed(response, request, container, container.BasePath+"/"+c.Journal.Slug+"/edit", entrySlugError)
+				return
+			}
+			saved, err := model.SaveJournal(container, previous, c.Journal)
+			if err != nil {
+				redirectFailed(response, request, container, container.BasePath+"/"+previous.Slug+"/edit", entrySaveError)
+				return
+			}
+			c.Journal = saved
+			ping.Notify(container, c.Journal)
+			federation.Notify(container, c.Journal)
+			webhook.Updated(container, previous, c.Journal)
+			as := model.JournalAutosaves{Container: container}
+			as.DeleteByJournal(auth.CurrentUser(request, container).ID, c.Journal.ID)
 
-			http.Redirect(response, request, "/?saved=1", 302)
+			redirectSaved(response, request, container, c.Journal)
 		}
 	}
 
@@ -2,7 +2,6 @@ package web
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -14,9 +13,9 @@ import (
 func TestEdit_Run(t *testing.T) {
 	db := &database.MockSqlite{}
 	container := &app.Container{Db: db}
+	container.Configuration.AdminToken = "admin-secret"
 	response := controller.NewMockResponse()
 	controller := &Edit{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test not found/error with GET/POST
 	controller.Init(container, []string{"", "0"})
@@ -34,42 +33,153 @@ func TestEdit_Run(t *testing.T) {
 		t.Error("Expected 404 error when journal not found")
 	}
 
-	// Display error when passed through
+	// Display form
 	response.Reset()
-	request, _ = http.NewRequest("GET", "/test/edit?error=1", strings.NewReader(""))
-	db.Rows = &database.MockJournal_SingleRow{}
+	request, _ = http.NewRequest("GET", "/slug/edit", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.Rows = &database.MockJournal_SingleRow{Excerpt: "A summary"}
 	controller.Run(response, request)
-	if !controller.Error || !strings.Contains(response.Content, "div class=\"error\"") {
-		t.Error("Expected error to be shown in form")
+	if !strings.Contains(response.Content, `name="excerpt" class="form-excerpt">A summary</textarea>`) {
+		t.Error("Expected excerpt to be shown in form")
 	}
 
-	// Display no error
+	// Redirect if empty content on POST
 	response.Reset()
-	request, _ = http.NewRequest("GET", "/slug/edit", strings.NewReader(""))
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=&date=&content="))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	db.Rows = &database.MockJournal_SingleRow{}
-	controller.Error = false
 	controller.Run(response, request)
-	if controller.Error || strings.Contains(response.Content, "div class=\"error\"") {
-		t.Error("Expected no error to be shown in form")
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to same page")
 	}
 
-	// Redirect if empty content on POST
+	// Redirect if links are invalid
 	response.Reset()
-	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=&date=&content="))
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&canonical_url=javascript%3Aalert(1)"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	db.Rows = &database.MockJournal_SingleRow{}
 	controller.Run(response, request)
-	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit?error=1" {
-		t.Error("Expected redirect back to same page")
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form with link error")
 	}
 
 	// Redirect on success
 	response.Reset()
 	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	db.Rows = &database.MockJournal_SingleRow{}
 	controller.Run(response, request)
-	if response.StatusCode != 302 || response.Headers.Get("Location") != "/?saved=1" {
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) {
 		t.Error("Expected redirect back to home with saved flag")
 	}
+
+	// Redirect back to the form when the links cannot be saved along with the entry
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.Rows = &database.MockJournal_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 4
+	controller.Run(response, request)
+	db.ErrorAtQuery = 0
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Errorf("Expected redirect back to form with save error, got %s", response.Headers.Get("Location"))
+	}
+
+	// Redirect to drafts when saving a draft
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&status=draft"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/drafts" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect to drafts with saved flag")
+	}
+
+	// Scheduled entries show their publish time
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug/edit", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.Rows = &database.MockJournal_SingleRow{Status: "scheduled", PublishAt: "2030-01-02 09:00:00"}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="publish_at" value="2030-01-02T`) || !strings.Contains(response.Content, "Publish now") {
+		t.Error("Expected scheduled time to be shown in the form")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&status=scheduled&publish_at=tomorrow"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect with schedule error")
+	}
+
+	// Custom fields must be named properly
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&meta=not+a+field"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.Rows = &database.MockJournal_SingleRow{}
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect with custom field error")
+	}
+
+	// Categories are offered with the current one selected, and saved with the entry, along with its tags
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug/edit", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{CategoryID: 2})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<option value="2" selected>— Europe</option>`) || !strings.Contains(response.Content, `<option value="3">Cooking</option>`) {
+		t.Error("Expected category picker to list nested categories")
+	}
+	if !strings.Contains(response.Content, `name="tags" id="form-tags" value="Road Trip, Travel"`) {
+		t.Error("Expected tags to be written out to be edited")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&category_id=1"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) || controller.Journal.CategoryID != 1 {
+		t.Error("Expected entry to be saved in the chosen category")
+	}
+
+	// Slugs already used by another entry are refused
+	response.Reset()
+	db.EnableMultiMode()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&slug=taken"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/edit" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect with slug error")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/slug/edit", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&slug=renamed"))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) || controller.Journal.Slug != "renamed" {
+		t.Error("Expected entry to be saved with its new slug")
+	}
 }
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/pkg/conditional"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/feed"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// RSS Serve the latest published entries as an RSS feed
+type RSS struct {
+	controller.Super
+}
+
+// Run RSS action
+func (c *RSS) Run(response http.ResponseWriter, request *http.Request) {
+	serveFeed(c.Super.Container.(*app.Container), response, request, "/feed.rss", feed.RSSFormat)
+}
+
+// Atom Serve the latest published entries as an Atom feed, the one announced to the WebSub hub
+type Atom struct {
+	controller.Super
+}
+
+// Run Atom action
+func (c *Atom) Run(response http.ResponseWriter, request *http.Request) {
+	serveFeed(c.Super.Container.(*app.Container), response, request, ping.FeedPath, feed.AtomFormat)
+}
+
+// JSONFeed Serve the latest published entries as a JSON Feed
+type JSONFeed struct {
+	controller.Super
+}
+
+// Run JSONFeed action
+func (c *JSONFeed) Run(response http.ResponseWriter, request *http.Request) {
+	serveFeed(c.Super.Container.(*app.Container), response, request, "/feed.json", feed.JSONFormat)
+}
+
+func serveFeed(container *app.Container, response http.ResponseWriter, request *http.Request, self string, format feed.Format) {
+	f := buildFeed(container, request, self)
+	output, err := format.Render(f)
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Readers polling for changes are told when nothing has
+	response.Header().Add("Content-Type", format.ContentType)
+	conditional.Serve(response, request, output, f.Updated)
+}
+
+// absoluteURL Build the full address of a path, taken from the request when no site URL is configured
+func absoluteURL(container *app.Container, request *http.Request, path string) string {
+	if url := container.URL(path); url != "" {
+		return url
+	}
+	return proxy.Scheme(request) + "://" + request.Host + container.BasePath + path
+}
+
+func buildFeed(container *app.Container, request *http.Request, self string) feed.Feed {
+	// Readers need absolute links
+	absolute := func(path string) string {
+		return absoluteURL(container, request, path)
+	}
+
+	js := model.Journals{Container: container}
+	ss := model.Shortcodes{Container: container}
+	f := feed.Feed{
+		Title:       container.Configuration.Title,
+		Description: container.Configuration.Description,
+		Link:        absolute("/"),
+		Self:        absolute(self),
+		Hub:         container.Configuration.WebSubHub,
+	}
+	rs := model.JournalRevisions{Container: container}
+	latest := js.FetchLatest(container.Configuration.FeedEntries)
+	for _, j := range latest {
+		entry := feed.Entry{
+			Title:     j.Title,
+			Link:      absolute("/" + j.Slug),
+			Published: j.GetTime(),
+			Summary:   j.GetExcerpt(),
+		}
+
+		// Readers are sent to the journal for the rest of each entry when feeds only carry summaries
+		if container.Configuration.FeedSummaries {
+			entry.Content = "<p>" + entry.Summary + "</p>"
+		} else {
+			entry.Content = ss.Replace(model.StripWikiLinks(j.GetHTML()))
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+	f.Updated = rs.LastModified(latest...)
+	if f.Updated.IsZero() {
+		f.Updated = time.Now().UTC()
+	}
+
+	return f
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestRSS_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &RSS{}
+
+	// Test links are built from the request without a site URL
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/feed.rss", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "application/rss+xml; charset=utf-8" {
+		t.Error("Expected RSS content type")
+	}
+	if !strings.Contains(response.Content, `<guid isPermaLink="true">http://journal.local/slug-2</guid>`) || !strings.Contains(response.Content, "<pubDate>Thu, 01 Mar 2018 00:00:00 +0000</pubDate>") {
+		t.Errorf("Expected entries with absolute GUIDs and dates, got:\n%s", response.Content)
+	}
+	if !strings.Contains(response.Content, "<lastBuildDate>Thu, 01 Mar 2018 00:00:00 +0000</lastBuildDate>") {
+		t.Error("Expected feed to be dated by its newest entry")
+	}
+
+	// Test configured site URL and hub are used
+	response.Reset()
+	container.Configuration.URL = "https://example.com"
+	container.Configuration.WebSubHub = "https://hub.example.com"
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<atom:link href="https://example.com/feed.rss" rel="self"`) || !strings.Contains(response.Content, `<atom:link href="https://hub.example.com" rel="hub">`) {
+		t.Errorf("Expected self and hub links, got:\n%s", response.Content)
+	}
+}
+
+func TestAtom_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.URL = "https://example.com"
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Atom{}
+
+	// Test empty feed
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/feed.atom", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "application/atom+xml; charset=utf-8" || !strings.Contains(response.Content, "<id>https://example.com/feed.atom</id>") || strings.Contains(response.Content, "<entry>") {
+		t.Errorf("Expected empty Atom feed, got:\n%s", response.Content)
+	}
+
+	// Test entries with rendered content
+	response.Reset()
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<id>https://example.com/slug</id>") || !strings.Contains(response.Content, `<content type="html">&lt;p&gt;Content&lt;/p&gt;</content>`) {
+		t.Errorf("Expected entries with rendered content, got:\n%s", response.Content)
+	}
+
+	// Test readers already holding the feed are not sent it again
+	etag := response.Headers.Get("ETag")
+	if etag == "" || response.Headers.Get("Last-Modified") != "Thu, 01 Mar 2018 00:00:00 GMT" {
+		t.Errorf("Expected feed to be tagged and dated by its newest entry, got %v", response.Headers)
+	}
+	response.Reset()
+	db.Rows = &database.MockJournal_MultipleRows{}
+	request.Header.Set("If-None-Match", etag)
+	controller.Run(response, request)
+	if response.StatusCode != 304 || response.Content != "" {
+		t.Errorf("Expected unchanged feed not to be sent again, got %d", response.StatusCode)
+	}
+}
+
+func TestJSONFeed_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.URL = "https://example.com"
+	configuration.WebSubHub = "https://hub.example.com"
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &JSONFeed{}
+
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/feed.json", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "application/feed+json; charset=utf-8" {
+		t.Error("Expected JSON Feed content type")
+	}
+	expected := []string{
+		`"feed_url": "https://example.com/feed.json"`,
+		`"url": "https://example.com/slug-2"`,
+		`"content_html": "<p>Content</p>"`,
+		`"date_published": "2018-03-01T00:00:00Z"`,
+		`"type": "WebSub"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(response.Content, e) {
+			t.Errorf("Expected JSON Feed to contain %s, got:\n%s", e, response.Content)
+		}
+	}
+
+	// Test the description is given and only summaries are carried once configured
+	response.Reset()
+	container.Configuration.Description = "Thoughts and notes"
+	container.Configuration.FeedSummaries = true
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `"description": "Thoughts and notes"`) || !strings.Contains(response.Content, `"content_html": "<p>Content 2</p>"`) {
+		t.Errorf("Expected the description and summaries only, got:\n%s", response.Content)
+	}
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Forbidden Display a 403 page for a signed in user who may not do what they asked
+type Forbidden struct {
+	controller.Super
+}
+
+// Run Forbidden
+func (c *Forbidden) Run(response http.ResponseWriter, request *http.Request) {
+	container, _ := c.Super.Container.(*app.Container)
+	template, err := container.Templates("_layout/default.tmpl", "forbidden.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	response.WriteHeader(http.StatusForbidden)
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// RunForbidden calls the forbidden page from an existing controller
+func RunForbidden(response http.ResponseWriter, request *http.Request, container interface{}) {
+	errorController := Forbidden{}
+	errorController.Init(container, []string{})
+	errorController.Run(response, request)
+}
@@ -0,0 +1,22 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestForbidden_Run(t *testing.T) {
+	response := &controller.MockResponse{}
+	response.Reset()
+	controller := &Forbidden{}
+	controller.Init(&app.Container{Configuration: app.DefaultConfiguration()}, []string{})
+
+	controller.Run(response, &http.Request{})
+	if response.StatusCode != 403 || !strings.Contains(response.Content, "Not Allowed") {
+		t.Error("Expected 403 error explaining who may change entries")
+	}
+}
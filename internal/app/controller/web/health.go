@@ -0,0 +1,104 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Paths probes ask on, left out of redirects to the journal's own address so that probes made at its internal one are
+// answered
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// probeResponse The status given to probes, with the outcome of each check made when asked whether ready
+type probeResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// IsProbe Check whether a path is one asked on by probes
+func IsProbe(path string) bool {
+	return path == HealthPath || path == ReadyPath
+}
+
+// Health Tell probes the journal is running, such as a liveness probe restarting it when it stops answering
+type Health struct {
+	controller.Super
+}
+
+// Run Health action
+func (c *Health) Run(response http.ResponseWriter, request *http.Request) {
+	writeProbe(response, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+// Ready Tell probes whether the journal can serve pages, checking its database answers and its templates can be read,
+// such as a readiness probe only sending it requests once it can
+type Ready struct {
+	controller.Super
+}
+
+// Run Ready action
+func (c *Ready) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	checks := map[string]error{
+		"database":  checkDatabase(container),
+		"templates": checkTemplates(container),
+	}
+
+	status := http.StatusOK
+	result := probeResponse{Status: "ok", Checks: map[string]string{}}
+	for name, err := range checks {
+		if err == nil {
+			result.Checks[name] = "ok"
+			continue
+		}
+		container.Log().Warn("Not ready to serve requests", "check", name, "err", err)
+		result.Checks[name] = "failed"
+		result.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+	writeProbe(response, status, result)
+}
+
+// checkDatabase Run the simplest of statements to check the database answers
+func checkDatabase(container *app.Container) error {
+	if container.Db == nil {
+		return errors.New("no database connected")
+	}
+	rows, err := container.Db.Query("SELECT 1")
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+// checkTemplates Parse each template of pages to check they can all be read, including any overriding those built in
+func checkTemplates(container *app.Container) error {
+	files := container.Configuration.Files()
+
+	return fs.WalkDir(files, "templates", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+			return err
+		}
+		_, err = template.New(entry.Name()).Funcs(container.TemplateFuncs()).ParseFS(files, path)
+
+		return err
+	})
+}
+
+func writeProbe(response http.ResponseWriter, status int, result probeResponse) {
+	response.Header().Add("Content-Type", "application/json; charset=utf-8")
+	response.Header().Add("Cache-Control", "no-store")
+	response.WriteHeader(status)
+	json.NewEncoder(response).Encode(result)
+}
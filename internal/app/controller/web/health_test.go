@@ -0,0 +1,60 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestHealth_Run(t *testing.T) {
+	response := controller.NewMockResponse()
+	controller := &Health{}
+	request, _ := http.NewRequest("GET", "/healthz", nil)
+	controller.Init(&app.Container{}, []string{""})
+	controller.Run(response, request)
+	if response.StatusCode != 200 || response.Content != "{\"status\":\"ok\"}\n" || response.Headers.Get("Cache-Control") != "no-store" {
+		t.Errorf("Expected the journal to be running, got %d and %s", response.StatusCode, response.Content)
+	}
+}
+
+func TestReady_Run(t *testing.T) {
+	db := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Ready{}
+	request, _ := http.NewRequest("GET", "/readyz", nil)
+	controller.Init(container, []string{""})
+
+	// Test ready when the database answers and templates are read
+	controller.Run(response, request)
+	if response.StatusCode != 200 || response.Content != "{\"status\":\"ok\",\"checks\":{\"database\":\"ok\",\"templates\":\"ok\"}}\n" {
+		t.Errorf("Expected the journal to be ready, got %d and %s", response.StatusCode, response.Content)
+	}
+
+	// Test unavailable when the database fails
+	response.Reset()
+	db.ErrorMode = true
+	controller.Run(response, request)
+	if response.StatusCode != 503 || response.Content != "{\"status\":\"unavailable\",\"checks\":{\"database\":\"failed\",\"templates\":\"ok\"}}\n" {
+		t.Errorf("Expected the journal to be unavailable, got %d and %s", response.StatusCode, response.Content)
+	}
+
+	// Test unavailable when a template overriding one built in cannot be parsed
+	db.ErrorMode = false
+	dir, _ := ioutil.TempDir("", "templates")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "templates"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "templates", "index.tmpl"), []byte("{{ define \"content\" }}"), 0644)
+	container.Configuration.WebPath = dir
+	response.Reset()
+	controller.Run(response, request)
+	if response.StatusCode != 503 || response.Content != "{\"status\":\"unavailable\",\"checks\":{\"database\":\"ok\",\"templates\":\"failed\"}}\n" {
+		t.Errorf("Expected the journal to be unavailable with a broken template, got %d and %s", response.StatusCode, response.Content)
+	}
+}
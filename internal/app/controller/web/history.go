@@ -0,0 +1,47 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// History Handle listing the earlier versions of an entry to those who may edit it
+type History struct {
+	controller.Super
+	Journal   model.Journal
+	Revisions []model.JournalRevision
+}
+
+// Run History action
+func (c *History) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	store := model.Store(container)
+	c.Journal = store.GetBySlug(c.Params[1])
+	if c.Journal.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+	if !c.Journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	rs := model.JournalRevisions{Container: container}
+	c.Revisions = rs.FetchByJournal(c.Journal.ID)
+
+	template, err := container.Templates("_layout/default.tmpl", "history.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestHistory_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	response := controller.NewMockResponse()
+	controller := &History{}
+	controller.Init(container, []string{"", "slug"})
+	request, _ := http.NewRequest("GET", "/slug/history", strings.NewReader(""))
+
+	// Test not found when editing is disabled
+	container.Configuration.EnableEdit = false
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test not found
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when journal not found")
+	}
+
+	// Test forbidden to those who may not edit the entry
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	controller.Run(response, request)
+	if response.StatusCode != 403 || strings.Contains(response.Content, "has not been changed") {
+		t.Error("Expected 403 when the entry may not be edited")
+	}
+
+	// Test no revisions
+	response.Reset()
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "has not been changed") {
+		t.Error("Expected empty message to be displayed on screen")
+	}
+
+	// Test revisions listed
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalRevision_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/slug/history", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "First Title") || !strings.Contains(response.Content, "/slug/history/2") {
+		t.Error("Expected revisions to be displayed on screen")
+	}
+}
@@ -3,7 +3,6 @@ package web
 import (
 	"net/http"
 	"strconv"
-	"text/template"
 
 	"github.com/jamiefdhurst/journal/internal/app"
 	"github.com/jamiefdhurst/journal/internal/app/model"
@@ -17,14 +16,13 @@ type Index struct {
 	Journals   []model.Journal
 	Pages      []int
 	Pagination database.PaginationInformation
-	Saved      bool
 }
 
 // Run Index action
 func (c *Index) Run(response http.ResponseWriter, request *http.Request) {
 
 	container := c.Super.Container.(*app.Container)
-	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
 
 	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
 	query := request.URL.Query()
@@ -35,19 +33,19 @@ func (c *Index) Run(response http.ResponseWriter, request *http.Request) {
 		}
 	}
 
-	c.Journals, c.Pagination = js.FetchPaginated(pagination)
-	c.Saved = false
-	if query["saved"] != nil {
-		c.Saved = true
-	}
+	c.Journals, c.Pagination = store.List(pagination)
+	us := model.Users{Container: container}
+	c.Journals = us.LoadAuthors(c.Journals)
 
 	c.Pages = make([]int, c.Pagination.TotalPages)
 	for i := range c.Pages {
 		c.Pages[i] = i + 1
 	}
 
-	template, _ := template.ParseFiles(
-		"./web/templates/_layout/default.tmpl",
-		"./web/templates/index.tmpl")
+	template, err := container.Templates("_layout/default.tmpl", "index.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
 	template.ExecuteTemplate(response, "layout", c)
 }
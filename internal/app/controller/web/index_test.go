@@ -2,7 +2,6 @@ package web
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
@@ -18,7 +17,6 @@ func TestIndex_Run(t *testing.T) {
 	container := &app.Container{Configuration: configuration, Db: db}
 	response := controller.NewMockResponse()
 	controller := &Index{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test showing all Journals
 	controller.Init(container, []string{"", "0"})
@@ -44,21 +42,13 @@ func TestIndex_Run(t *testing.T) {
 		t.Error("Expected pagination to work")
 	}
 
-	// Test saved banner showing
+	// Test pinned entries are marked
 	response.Reset()
-	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
-	db.AppendResult(&database.MockJournal_MultipleRows{})
-	request, _ = http.NewRequest("GET", "/?saved=1", strings.NewReader(""))
-	controller.Run(response, request)
-	if !strings.Contains(response.Content, "Journal saved") {
-		t.Error("Expected saved banner to be displayed on screen")
-	}
-	response.Reset()
-	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
-	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	db.AppendResult(&database.MockJournal_SingleRow{Pinned: true})
 	request, _ = http.NewRequest("GET", "/", strings.NewReader(""))
 	controller.Run(response, request)
-	if strings.Contains(response.Content, "Journal saved") {
-		t.Error("Expected saved banner to be hidden, but it is showing")
+	if !strings.Contains(response.Content, `<article class="pinned">`) || !strings.Contains(response.Content, "pinned-marker") {
+		t.Error("Expected pinned entry to be marked")
 	}
 }
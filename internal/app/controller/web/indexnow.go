@@ -0,0 +1,26 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// IndexNowKey Serve the key file proving ownership of the site to IndexNow
+type IndexNowKey struct {
+	controller.Super
+}
+
+// Run IndexNowKey action
+func (c *IndexNowKey) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	key := container.Configuration.IndexNowKey
+	if key == "" || c.Params[1] != key {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	response.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	response.Write([]byte(key))
+}
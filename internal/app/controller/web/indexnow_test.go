@@ -0,0 +1,39 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestIndexNowKey_Run(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	response := controller.NewMockResponse()
+	controller := &IndexNowKey{}
+	request, _ := http.NewRequest("GET", "/abc123.txt", nil)
+
+	// Test not found when disabled
+	controller.Init(container, []string{"", "abc123"})
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when IndexNow is disabled")
+	}
+
+	// Test not found for another key
+	response.Reset()
+	container.Configuration.IndexNowKey = "xyz789"
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when key does not match")
+	}
+
+	// Test key served
+	response.Reset()
+	container.Configuration.IndexNowKey = "abc123"
+	controller.Run(response, request)
+	if response.StatusCode != 200 || response.Content != "abc123" {
+		t.Error("Expected key to be served")
+	}
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+// linksFromForm Read the canonical and syndication URLs submitted with an entry, returning false if any are invalid
+func linksFromForm(request *http.Request, journal *model.Journal) bool {
+	canonical := strings.TrimSpace(request.FormValue("canonical_url"))
+	if canonical != "" && !model.IsValidLinkURL(canonical) {
+		return false
+	}
+	syndication, err := model.ParseLinkURLs(request.FormValue("syndication"))
+	if err != nil {
+		return false
+	}
+	journal.CanonicalURL = canonical
+	journal.Syndication = syndication
+
+	return true
+}
+
+// metaFromForm Read the custom fields written one to a line as name: value, returning false if any name is invalid
+func metaFromForm(request *http.Request, journal *model.Journal) bool {
+	meta, err := model.ParseMeta(request.FormValue("meta"))
+	if err != nil {
+		return false
+	}
+	journal.Meta = meta
+
+	return true
+}
+
+// tagsFromForm Read the tags written separated by commas
+func tagsFromForm(request *http.Request, journal *model.Journal) {
+	journal.Tags = model.ParseTags(request.FormValue("tags"))
+}
+
+// statusFromForm Read which of the save buttons was used, publishing unless a draft was asked for
+func statusFromForm(request *http.Request) string {
+	if request.FormValue("status") == model.JournalStatusDraft {
+		return model.JournalStatusDraft
+	}
+
+	return model.JournalStatusPublished
+}
+
+// scheduleFromForm Read the time an entry was scheduled to be published at, returning false if it is missing or invalid
+func scheduleFromForm(request *http.Request, journal *model.Journal) bool {
+	if request.FormValue("status") != model.JournalStatusScheduled {
+		return true
+	}
+	at, err := time.ParseInLocation("2006-01-02T15:04", request.FormValue("publish_at"), time.Local)
+	if err != nil {
+		return false
+	}
+	journal.Schedule(at)
+
+	return true
+}
+
+// commentsFromForm Read whether comments were left open on an entry
+func commentsFromForm(request *http.Request) string {
+	if request.FormValue("comments") == model.JournalCommentsOpen {
+		return model.JournalCommentsOpen
+	}
+
+	return model.JournalCommentsClosed
+}
+
+// visibilityFromForm Read who may see an entry, leaving anything unrecognised to be made public on save
+func visibilityFromForm(request *http.Request) string {
+	return request.FormValue("visibility")
+}
+
+// categoryFromForm Read the category an entry was filed in, ignoring any that do not exist
+func categoryFromForm(request *http.Request, cs model.Categories) int {
+	id, err := strconv.Atoi(request.FormValue("category_id"))
+	if err != nil || id <= 0 {
+		return 0
+	}
+
+	return cs.FindByID(id).ID
+}
+
+// passwordFromForm Read a password set on or removed from an entry, returning false if it could not be stored
+func passwordFromForm(request *http.Request, journal *model.Journal) bool {
+	if request.FormValue("remove_password") == "1" {
+		journal.PasswordHash = ""
+	}
+	if password := request.FormValue("password"); password != "" {
+		return journal.SetPassword(password) == nil
+	}
+
+	return true
+}
+
+// slugFromForm Read the slug chosen for an entry, returning false if it is invalid or used by another entry
+func slugFromForm(request *http.Request, js model.Journals, journal *model.Journal) bool {
+	slug := strings.TrimSpace(request.FormValue("slug"))
+	if slug == "" || slug == journal.Slug {
+		return true
+	}
+	if !model.IsValidSlug(slug) || js.SlugTaken(slug, journal.ID) {
+		return false
+	}
+	journal.Slug = slug
+
+	return true
+}
+
+// Why an entry could not be saved, shown on the form it is sent back to
+const (
+	entryError         = "Make sure all the fields are filled in before saving."
+	entryLinkError     = "Links must be full web addresses, starting with http:// or https://."
+	entryMetaError     = "Write each custom field on its own line as a name and a value, such as mood: happy. Names may only use lower case letters, numbers, dashes and underscores."
+	entryPasswordError = "The password could not be set, try a shorter one."
+	entrySaveError     = "The post could not be saved, so nothing has been changed. Please try again."
+	entryScheduleError = "Choose a date and time to publish at before scheduling."
+	entrySlugError     = "The address can only use lower case letters, numbers and dashes, and must not already be in use."
+)
+
+// redirectFailed Send the user back to the form an entry was sent from, telling them why it could not be saved
+func redirectFailed(response http.ResponseWriter, request *http.Request, container *app.Container, page string, message string) {
+	flash.Error(response, request, container, message)
+	http.Redirect(response, request, page, 302)
+}
+
+// redirectSaved Send the user on once an entry has been saved, to the drafts for one not yet published, telling them
+// it was saved
+func redirectSaved(response http.ResponseWriter, request *http.Request, container *app.Container, journal model.Journal) {
+	if journal.IsScheduled() {
+		flash.Success(response, request, container, "Entry scheduled, it will be published automatically.")
+		http.Redirect(response, request, container.BasePath+"/drafts", 302)
+		return
+	}
+	if journal.IsDraft() {
+		flash.Success(response, request, container, "Draft saved.")
+		http.Redirect(response, request, container.BasePath+"/drafts", 302)
+		return
+	}
+
+	flash.Success(response, request, container, "Journal saved.")
+	http.Redirect(response, request, container.BasePath+"/", 302)
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Login Handle a user signing in with their username and password, remembering them in a session cookie
+type Login struct {
+	controller.Super
+	Next string
+	OIDC string
+	User model.User
+}
+
+// Run Login action
+func (c *Login) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	c.Next = safeNext(request.FormValue("next"))
+
+	if request.Method == "GET" {
+		if container.SetupCode != "" && model.NeedsSetup(container) {
+			http.Redirect(response, request, container.BasePath+"/setup", 302)
+			return
+		}
+		c.OIDC = oidcName(container)
+		c.User = auth.SessionUser(request, container)
+
+		template, err := container.Templates("_layout/default.tmpl", "login.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
+		template.ExecuteTemplate(response, "layout", c)
+		return
+	}
+
+	username := request.FormValue("username")
+	if auth.SignInLocked(request, container, username) {
+		signInFailed(response, request, container, c.Next, lockedError, container.Configuration.LockoutMinutes)
+		return
+	}
+	us := model.Users{Container: container}
+	user := us.FindByUsername(username)
+	if user.ID == 0 || !user.CheckPassword(request.FormValue("password")) {
+		if auth.SignInFailed(request, container, username, user) {
+			signInFailed(response, request, container, c.Next, lockedError, container.Configuration.LockoutMinutes)
+			return
+		}
+		signInFailed(response, request, container, c.Next, loginError)
+		return
+	}
+	if err := auth.StartSession(response, request, container, user); err != nil {
+		signInFailed(response, request, container, c.Next, loginError)
+		return
+	}
+	auth.Audit(request, container, model.SecurityEvent{Kind: model.SecurityLogin, UserID: user.ID, Username: user.Username})
+
+	http.Redirect(response, request, container.BasePath+c.Next, 302)
+}
+
+// loginError Why a user could not be signed in with their username and password
+const loginError = "That username and password do not match, please try again."
+
+// lockedError Why a user could not be signed in once too many attempts have failed, given the minutes until they can
+const lockedError = "Signing in has been locked after too many failed attempts, please try again in %d minutes."
+
+// signInFailed Send the user back to sign in, telling them why they could not with any values filled in, on to the
+// page they were after once they do
+func signInFailed(response http.ResponseWriter, request *http.Request, container *app.Container, next string, message string, args ...interface{}) {
+	flash.Error(response, request, container, message, args...)
+	http.Redirect(response, request, container.BasePath+"/login?next="+next, 302)
+}
+
+// Logout Handle a user signing out, ending their session
+type Logout struct {
+	controller.Super
+}
+
+// Run Logout action
+func (c *Logout) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	auth.EndSession(response, request, container)
+
+	http.Redirect(response, request, container.BasePath+"/", 302)
+}
+
+// reSafeNext A path within the journal, as pages are found at, without anything a link could use to leave it
+var reSafeNext = regexp.MustCompile("^(/[\\w\\-.]+)*/?$")
+
+// safeNext Keep the page to go to once signed in within the journal, rather than letting a link send users elsewhere
+func safeNext(next string) string {
+	if next == "" || !reSafeNext.MatchString(next) {
+		return "/"
+	}
+
+	return next
+}
@@ -0,0 +1,138 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestLogin_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Login{}
+	controller.Init(container, []string{})
+	post := func(body string) *http.Request {
+		request, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return request
+	}
+	user := model.User{}
+	user.SetPassword("password123")
+
+	// Test form keeps the page to return to, only when it is within the journal
+	request, _ := http.NewRequest("GET", "/login?next=/test/edit", nil)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="next" value="/test/edit"`) {
+		t.Error("Expected login form to be shown with the page to return to")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/login?next=//example.com", nil)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="next" value="/"`) {
+		t.Error("Expected pages outside the journal to be ignored")
+	}
+
+	// Test signing in with a provider is offered once configured
+	response.Reset()
+	container.Configuration.OIDCProvider = "google"
+	container.Configuration.OIDCClientID = "journal"
+	request, _ = http.NewRequest("GET", "/login?next=/new", nil)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `href="/login/oidc?next=%2fnew"`) || !strings.Contains(response.Content, "Sign in with Google") {
+		t.Error("Expected signing in with Google to be offered")
+	}
+
+	// Test unknown user and wrong password, which are each recorded
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.Queries = 0
+	controller.Run(response, post("username=nobody&password=password123&next=/new"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) || strings.Contains(strings.Join(response.Headers.Values("Set-Cookie"), "\n"), auth.SessionCookie) || db.Queries != 5 {
+		t.Error("Expected unknown user to be refused and the failure recorded")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: user.PasswordHash})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, post("username=jamie&password=wrong&next=/new"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) || strings.Contains(strings.Join(response.Headers.Values("Set-Cookie"), "\n"), auth.SessionCookie) {
+		t.Error("Expected wrong password to be refused")
+	}
+
+	// Test the failure reaching the limit locks the account
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: user.PasswordHash})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 5})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.Queries = 0
+	controller.Run(response, post("username=jamie&password=wrong&next=/new"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) || db.Queries != 6 {
+		t.Error("Expected account to be locked once it has failed too often")
+	}
+
+	// Test a locked account is refused without checking the password
+	response.Reset()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	db.Queries = 0
+	controller.Run(response, post("username=jamie&password=password123&next=/new"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) || strings.Contains(strings.Join(response.Headers.Values("Set-Cookie"), "\n"), auth.SessionCookie) || db.Queries != 1 {
+		t.Error("Expected locked account to be refused")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "locked after too many failed attempts") {
+		t.Error("Expected lockout to be explained")
+	}
+
+	// Test right password signs the user in, recording it
+	response.Reset()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockUser_SingleRow{PasswordHash: user.PasswordHash})
+	db.Queries = 0
+	controller.Run(response, post("username=jamie&password=password123&next=/new"))
+	if response.Headers.Get("Location") != "/new" || !strings.HasPrefix(response.Headers.Get("Set-Cookie"), auth.SessionCookie+"=") || db.Queries != 4 {
+		t.Error("Expected user to be signed in, recorded and returned to the page asked for")
+	}
+
+	// Test signing in is sent to setting the journal up until it has an admin
+	response.Reset()
+	container.SetupCode = "abc123"
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	request, _ = http.NewRequest("GET", "/login?next=/new", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/setup" {
+		t.Error("Expected a journal without an admin to be set up first")
+	}
+	response.Reset()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<form") {
+		t.Error("Expected the login form once the journal has an admin")
+	}
+}
+
+func TestLogout_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Logout{}
+	controller.Init(container, []string{})
+	request, _ := http.NewRequest("POST", "/logout", nil)
+	request.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: "known"})
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/" || db.Queries != 1 || !strings.Contains(response.Headers.Get("Set-Cookie"), "Max-Age=0") {
+		t.Error("Expected session to be ended and the user sent home")
+	}
+}
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Media Handle browsing uploaded images and uploading new ones
+type Media struct {
+	controller.Super
+	Files []media.File
+}
+
+// Run Media action
+func (c *Media) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableCreate {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	c.Files = media.List(container)
+
+	template, err := container.Templates("_layout/default.tmpl", "media.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// MediaFile Handle serving a single uploaded file
+type MediaFile struct {
+	controller.Super
+}
+
+// Run MediaFile action
+func (c *MediaFile) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	path, ok := media.Find(container, c.Params[1])
+	if !ok {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(response, request, path)
+}
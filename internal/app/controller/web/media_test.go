@@ -0,0 +1,86 @@
+package web
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+var testImage = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func mediaContainer(t *testing.T) *app.Container {
+	dir, err := ioutil.TempDir("", "media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	configuration := app.DefaultConfiguration()
+	configuration.MediaPath = dir
+
+	return &app.Container{Configuration: configuration, Db: &database.MockSqlite{}}
+}
+
+func TestMedia_Run(t *testing.T) {
+	container := mediaContainer(t)
+	container.Configuration.EnableCreate = false
+	response := controller.NewMockResponse()
+	controller := &Media{}
+
+	// Test not found when creating is disabled
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/media", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when creating is disabled")
+	}
+
+	// Test empty library
+	response.Reset()
+	container.Configuration.EnableCreate = true
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "No images have been uploaded") || !strings.Contains(response.Content, `enctype="multipart/form-data"`) {
+		t.Error("Expected empty library with upload form")
+	}
+
+	// Test uploaded images listed with their snippet
+	response.Reset()
+	media.Store(container, "photo.png", bytes.NewReader(testImage))
+	request, _ = http.NewRequest("GET", "/media", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `src="/media/photo.png"`) || !strings.Contains(response.Content, "![photo](/media/photo.png)") {
+		t.Error("Expected uploaded image to be listed with its Markdown")
+	}
+}
+
+func TestMediaFile_Run(t *testing.T) {
+	container := mediaContainer(t)
+	response := controller.NewMockResponse()
+	controller := &MediaFile{}
+	media.Store(container, "photo.png", bytes.NewReader(testImage))
+
+	// Test file is served
+	controller.Init(container, []string{"", "photo.png"})
+	request, _ := http.NewRequest("GET", "/media/photo.png", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.Content != string(testImage) || response.Headers.Get("Content-Type") != "image/png" {
+		t.Error("Expected image to be served")
+	}
+
+	// Test missing and unsafe names are not found
+	for _, name := range []string{"missing.png", "../journal.db"} {
+		response.Reset()
+		controller.Init(container, []string{"", name})
+		controller.Run(response, request)
+		if response.StatusCode != 404 {
+			t.Errorf("Expected 404 for %s", name)
+		}
+	}
+}
@@ -2,19 +2,27 @@ package web
 
 import (
 	"net/http"
-	"text/template"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
 // New Handle creating a new entry
 type New struct {
 	controller.Super
-	Error   bool
-	Journal model.Journal
+	Categories   []model.Category
+	Duplicate    model.Journal
+	Fields       url.Values
+	Journal      model.Journal
+	QuotaReached bool
 }
 
 // Run New action
@@ -24,30 +32,87 @@ func (c *New) Run(response http.ResponseWriter, request *http.Request) {
 		RunBadRequest(response, request, c.Super.Container)
 	}
 
-	if request.Method == "GET" {
-		query := request.URL.Query()
-		c.Error = false
-		if query["error"] != nil {
-			c.Error = true
-		}
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	c.QuotaReached = js.QuotaReached()
+	cs := model.Categories{Container: container}
 
+	if request.Method == "GET" {
 		c.Journal.Date = time.Now().Format("2006-01-02")
+		c.Categories = cs.FetchTree()
 
-		template, _ := template.ParseFiles(
-			"./web/templates/_layout/default.tmpl",
-			"./web/templates/new.tmpl",
-			"./web/templates/_partial/form.tmpl")
+		template, err := container.Templates("_layout/default.tmpl", "new.tmpl", "_partial/form.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
 		template.ExecuteTemplate(response, "layout", c)
 	} else {
 		if request.FormValue("title") == "" || request.FormValue("date") == "" || request.FormValue("content") == "" {
-			http.Redirect(response, request, "/new?error=1", 302)
+			redirectFailed(response, request, container, container.BasePath+"/new", entryError)
+			return
+		}
+		if c.QuotaReached {
+			http.Redirect(response, request, container.BasePath+"/new", 302)
+			return
+		}
+
+		journal := model.Journal{ID: 0, Slug: model.Slugify(request.FormValue("title")), Title: request.FormValue("title"), Date: request.FormValue("date"), Content: request.FormValue("content"), Excerpt: strings.TrimSpace(request.FormValue("excerpt")), Status: statusFromForm(request), Comments: commentsFromForm(request), Pinned: request.FormValue("pinned") == "1", Visibility: visibilityFromForm(request), CategoryID: categoryFromForm(request, cs), AuthorID: auth.CurrentUser(request, container).ID}
+		if !linksFromForm(request, &journal) {
+			redirectFailed(response, request, container, container.BasePath+"/new", entryLinkError)
+			return
+		}
+		if !metaFromForm(request, &journal) {
+			redirectFailed(response, request, container, container.BasePath+"/new", entryMetaError)
+			return
+		}
+		tagsFromForm(request, &journal)
+		if !scheduleFromForm(request, &journal) {
+			redirectFailed(response, request, container, container.BasePath+"/new", entryScheduleError)
+			return
+		}
+		if !passwordFromForm(request, &journal) {
+			redirectFailed(response, request, container, container.BasePath+"/new", entryPasswordError)
 			return
 		}
+		if !slugFromForm(request, js, &journal) {
+			redirectFailed(response, request, container, container.BasePath+"/new", entrySlugError)
+			return
+		}
+		if request.FormValue("confirm_duplicate") != "1" {
+			if c.Duplicate = js.FindDuplicate(journal); c.Duplicate.ID > 0 {
+				c.showDuplicate(response, request, journal)
+				return
+			}
+		}
+		journal, err := model.SaveJournal(container, model.Journal{}, journal)
+		if err != nil {
+			redirectFailed(response, request, container, container.BasePath+"/new", entrySaveError)
+			return
+		}
+		ping.Notify(container, journal)
+		federation.Notify(container, journal)
+		webhook.Created(container, journal)
+		as := model.JournalAutosaves{Container: container}
+		as.DeleteByJournal(auth.CurrentUser(request, container).ID, 0)
+
+		redirectSaved(response, request, container, journal)
+	}
+}
 
-		js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
-		journal := model.Journal{ID: 0, Slug: model.Slugify(request.FormValue("title")), Title: request.FormValue("title"), Date: request.FormValue("date"), Content: request.FormValue("content")}
-		js.Save(journal)
+// showDuplicate Ask whether to edit the existing entry instead, carrying the submitted form over so it can be saved anyway
+func (c *New) showDuplicate(response http.ResponseWriter, request *http.Request, journal model.Journal) {
+	c.Journal = journal
+	c.Fields = url.Values{}
+	for name, values := range request.PostForm {
+		if name != "confirm_duplicate" && name != app.CSRFField {
+			c.Fields[name] = values
+		}
+	}
 
-		http.Redirect(response, request, "/?saved=1", 302)
+	template, err := c.Super.Container.(*app.Container).Templates("_layout/default.tmpl", "duplicate.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
 	}
+	template.ExecuteTemplate(response, "layout", c)
 }
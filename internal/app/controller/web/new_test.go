@@ -2,11 +2,11 @@ package web
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
 	"github.com/jamiefdhurst/journal/test/mocks/controller"
 	"github.com/jamiefdhurst/journal/test/mocks/database"
 )
@@ -18,40 +18,151 @@ func TestNew_Run(t *testing.T) {
 	container := &app.Container{Db: db}
 	response := controller.NewMockResponse()
 	controller := &New{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Display form
 	controller.Init(container, []string{"", "0"})
 	request, _ := http.NewRequest("GET", "/new", strings.NewReader(""))
 	controller.Run(response, request)
-	if controller.Error || !strings.Contains(response.Content, "<form") {
+	if !strings.Contains(response.Content, "<form") {
 		t.Error("Expected form to be shown")
 	}
 
-	// Display error
+	// Redirect if empty content on POST
 	response.Reset()
-	request, _ = http.NewRequest("GET", "/new?error=1", strings.NewReader(""))
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=&date=&content="))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	controller.Run(response, request)
-	if !strings.Contains(response.Content, "<form") || !strings.Contains(response.Content, "error") {
-		t.Error("Expected form and error to be shown")
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to same page")
 	}
 
-	// Redirect if empty content on POST
+	// Redirect if links are invalid
 	response.Reset()
-	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=&date=&content="))
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&syndication=not-a-url"))
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	controller.Run(response, request)
-	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new?error=1" {
-		t.Error("Expected redirect back to same page")
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form with link error")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "full web addresses") {
+		t.Error("Expected link error to be explained")
 	}
 
 	// Redirect on success
 	response.Reset()
 	db.Result = &database.MockResult{}
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&canonical_url=https%3A%2F%2Fexample.com%2Foriginal&syndication=https%3A%2F%2Fmastodon.example%2F1"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect back to home with saved message")
+	}
+
+	// Redirect back to the form when the entry cannot be saved
+	response.Reset()
+	db.ErrorMode = true
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	db.ErrorMode = false
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form with save error")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "could not be saved") {
+		t.Error("Expected save error to be explained")
+	}
+
+	// Redirect to drafts when saving a draft
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&status=draft"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/drafts" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect to drafts with saved message")
+	}
+
+	// Scheduling needs a time to publish at
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&status=scheduled"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect with schedule error")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "Choose a date and time to publish at") {
+		t.Error("Expected schedule error to be explained")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&status=scheduled&publish_at=2030-01-02T09%3A00"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/drafts" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect to drafts with scheduled message")
+	}
+
+	// Custom slugs are validated
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&slug=Not+Valid"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect with slug error")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "must not already be in use") {
+		t.Error("Expected slug error to be explained")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again&slug=my-own-slug"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected entry with a custom slug to be saved")
+	}
+
+	// Nearly identical entries are confirmed before saving
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{}
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title%21&date=2018-02-01&content=Test+%22again%22"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode == 302 || controller.Duplicate.ID != 1 || !strings.Contains(response.Content, "/slug/edit") {
+		t.Error("Expected duplicate to be shown with a link to edit it")
+	}
+	if !strings.Contains(response.Content, `name="content" value="Test &#34;again&#34;"`) || !strings.Contains(response.Content, `name="confirm_duplicate" value="1"`) {
+		t.Error("Expected submitted fields to be carried over")
+	}
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title%21&date=2018-02-01&content=Test+again&confirm_duplicate=1"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected confirmed entry to be saved")
+	}
+
+	// Quota reached within a tenant
+	response.Reset()
+	container.Tenant = "alice"
+	container.Configuration.TenantMaxEntries = 1
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	request, _ = http.NewRequest("GET", "/new", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "reached its limit of 1 entries") {
+		t.Error("Expected quota message to be shown")
+	}
+	response.Reset()
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
 	request, _ = http.NewRequest("POST", "/new", strings.NewReader("title=Title&date=2018-02-01&content=Test+again"))
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	controller.Run(response, request)
-	if response.StatusCode != 302 || response.Headers.Get("Location") != "/?saved=1" {
-		t.Error("Expected redirect back to home with saved flag")
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/new" {
+		t.Error("Expected redirect back to form when quota is reached")
 	}
 }
+
+// flashed Check whether a response leaves a message of a kind for the next page
+func flashed(response http.ResponseWriter, kind string) bool {
+	left := flash.Left(response)
+
+	return len(left) == 1 && left[0].Kind == kind
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// oEmbedWidth Width of an embedded entry when the consumer allows it
+const oEmbedWidth = 550
+
+// oEmbedResponse The rich preview of an entry returned to consumers, following the oEmbed 1.0 specification
+type oEmbedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       *int   `json:"height"`
+}
+
+// OEmbed Describe an entry given by its address so that other sites can embed a preview of it
+type OEmbed struct {
+	controller.Super
+}
+
+// Run OEmbed action
+func (c *OEmbed) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	query := request.URL.Query()
+	if format := query.Get("format"); format != "" && format != "json" {
+		response.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	store := model.Store(container)
+	slug := oEmbedSlug(container, request, query.Get("url"))
+	if slug == "" {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	journal := store.GetBySlug(slug)
+	if journal.ID == 0 || !embeddable(journal) {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	width := oEmbedWidth
+	if maxWidth, _ := strconv.Atoi(query.Get("maxwidth")); maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	address := absoluteURL(container, request, "/"+journal.Slug)
+	home := absoluteURL(container, request, "/")
+	title := container.Configuration.Title
+	embed := "<blockquote class=\"journal-embed\" cite=\"" + html.EscapeString(address) + "\">" +
+		"<p><a href=\"" + html.EscapeString(address) + "\">" + html.EscapeString(journal.Title) + "</a></p>" +
+		"<p>" + html.EscapeString(journal.GetDescription()) + "</p>" +
+		"<p>&mdash; <a href=\"" + html.EscapeString(home) + "\">" + html.EscapeString(title) + "</a>, " + journal.GetDate() + "</p>" +
+		"</blockquote>"
+
+	response.Header().Add("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(response)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(oEmbedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        journal.Title,
+		ProviderName: title,
+		ProviderURL:  home,
+		HTML:         embed,
+		Width:        width,
+	})
+}
+
+// embeddable Whether an entry may be previewed on other sites, which it can once published unless it needs signing in
+// or a password to read
+func embeddable(journal model.Journal) bool {
+	return journal.IsPublished() && !journal.IsPrivate() && !journal.IsProtected()
+}
+
+// oEmbedSlug Find the slug of the entry an address points to, when it is one of the journal's own
+func oEmbedSlug(container *app.Container, request *http.Request, address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	home, err := url.Parse(absoluteURL(container, request, "/"))
+	if err != nil || (!strings.EqualFold(u.Host, home.Host) && !strings.EqualFold(u.Host, request.Host)) {
+		return ""
+	}
+	prefix := strings.TrimSuffix(home.Path, "/") + "/"
+	if !strings.HasPrefix(u.Path, prefix) {
+		return ""
+	}
+	slug := strings.TrimSuffix(strings.TrimPrefix(u.Path, prefix), "/")
+	if strings.Contains(slug, "/") {
+		return ""
+	}
+
+	return slug
+}
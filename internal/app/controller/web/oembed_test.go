@@ -0,0 +1,68 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestOEmbed_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &OEmbed{}
+	controller.Init(container, []string{"", "0"})
+
+	// Test addresses that are not entries of the journal are not found
+	for _, address := range []string{"", "/slug", "https://elsewhere.example.com/slug", "http://journal.local/category/slug", "http://journal.local/"} {
+		response.Reset()
+		request, _ := http.NewRequest("GET", "/oembed?url="+address, strings.NewReader(""))
+		request.Host = "journal.local"
+		controller.Run(response, request)
+		if response.StatusCode != 404 || db.Queries != 0 {
+			t.Errorf("Expected %s not to be found", address)
+		}
+	}
+
+	// Test only JSON is supported
+	response.Reset()
+	request, _ := http.NewRequest("GET", "/oembed?url=http://journal.local/slug&format=xml", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	if response.StatusCode != 501 {
+		t.Error("Expected XML not to be implemented")
+	}
+
+	// Test entries needing a password are not embedded
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{PasswordHash: "hash"}
+	request, _ = http.NewRequest("GET", "/oembed?url=http://journal.local/slug", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected protected entry not to be embedded")
+	}
+
+	// Test a rich preview of the entry, escaped and kept to the width allowed
+	response.Reset()
+	container.Configuration.URL = "https://example.com"
+	db.Rows = &database.MockJournal_SingleRow{Content: "<p>Fish &amp; <b>chips</b></p>"}
+	request, _ = http.NewRequest("GET", "/oembed?url=https://example.com/slug/&maxwidth=400", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	embed := oEmbedResponse{}
+	if err := json.Unmarshal([]byte(response.Content), &embed); err != nil || response.Headers.Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("Expected oEmbed JSON, got %s", response.Content)
+	}
+	if embed.Version != "1.0" || embed.Type != "rich" || embed.Title != "Title" || embed.ProviderURL != "https://example.com/" || embed.Width != 400 || embed.Height != nil {
+		t.Errorf("Expected entry to be described, got %v", embed)
+	}
+	if !strings.Contains(embed.HTML, `<a href="https://example.com/slug">Title</a>`) || !strings.Contains(embed.HTML, "<p>Fish &amp; chips</p>") || !strings.Contains(embed.HTML, "Thursday February 1, 2018") {
+		t.Errorf("Expected entry to be previewed, got %s", embed.HTML)
+	}
+}
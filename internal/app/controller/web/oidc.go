@@ -0,0 +1,159 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/adapter/oidc"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// oidcStateCookie Name of the cookie holding the state sent to the provider, and the page to return to once signed in
+const oidcStateCookie = "journal_oidc"
+
+// OIDCLogin Send a user to sign in with the configured provider
+type OIDCLogin struct {
+	controller.Super
+}
+
+// Run OIDCLogin action
+func (c *OIDCLogin) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if oidcName(container) == "" {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+	next := safeNext(request.FormValue("next"))
+	client, err := oidcClient(container)
+	if err != nil {
+		container.Log().Warn("Could not sign in", "provider", container.Configuration.OIDCProvider, "err", err)
+		signInFailed(response, request, container, next, oidcError, oidcName(container))
+		return
+	}
+
+	random := make([]byte, 16)
+	rand.Read(random)
+	state := hex.EncodeToString(random)
+	cookie := oidcCookie(request, container, url.Values{"state": {state}, "next": {next}}.Encode())
+	cookie.MaxAge = 600
+	http.SetCookie(response, cookie)
+
+	http.Redirect(response, request, client.AuthorizationURL(state, absoluteURL(container, request, "/login/oidc/callback")), 302)
+}
+
+// OIDCCallback Sign in the user the provider sent back, as long as they were sent there by this journal
+type OIDCCallback struct {
+	controller.Super
+}
+
+// Run OIDCCallback action
+func (c *OIDCCallback) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if oidcName(container) == "" {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	saved := url.Values{}
+	if cookie, err := request.Cookie(oidcStateCookie); err == nil {
+		saved, _ = url.ParseQuery(cookie.Value)
+	}
+	expired := oidcCookie(request, container, "")
+	expired.MaxAge = -1
+	http.SetCookie(response, expired)
+	next := safeNext(saved.Get("next"))
+
+	if err := c.signIn(response, request, container, saved.Get("state")); err != nil {
+		container.Log().Warn("Could not sign in", "provider", container.Configuration.OIDCProvider, "err", err)
+		signInFailed(response, request, container, next, oidcError, oidcName(container))
+		return
+	}
+
+	http.Redirect(response, request, container.BasePath+next, 302)
+}
+
+// signIn Check the state sent back matches the one sent, then trade the code for who signed in and start their session
+func (c *OIDCCallback) signIn(response http.ResponseWriter, request *http.Request, container *app.Container, state string) error {
+	query := request.URL.Query()
+	if state == "" || subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+		return errors.New("The state sent back does not match the one sent")
+	}
+	if query.Get("code") == "" {
+		return errors.New("The provider did not send back a code: " + query.Get("error"))
+	}
+
+	client, err := oidcClient(container)
+	if err != nil {
+		return err
+	}
+	accessToken, err := client.Exchange(query.Get("code"), absoluteURL(container, request, "/login/oidc/callback"))
+	if err != nil {
+		return err
+	}
+	identity, err := client.Identify(accessToken)
+	if err != nil {
+		return err
+	}
+	user, err := auth.IdentityUser(container, container.Configuration.OIDCProvider, identity)
+	if err != nil {
+		return err
+	}
+	if err := auth.StartSession(response, request, container, user); err != nil {
+		return err
+	}
+	auth.Audit(request, container, model.SecurityEvent{Kind: model.SecurityLogin, UserID: user.ID, Username: user.Username, Detail: container.Configuration.OIDCProvider})
+
+	return nil
+}
+
+// oidcError Why a user could not be signed in with the provider, given its name
+const oidcError = "You could not be signed in with %s. Ask an admin to add a user with the same email as your account, which it must have verified."
+
+// oidcName Name of the provider users may sign in with, or nothing when signing in with one is not configured
+func oidcName(container *app.Container) string {
+	if container.Configuration.OIDCProvider == "" || container.Configuration.OIDCClientID == "" {
+		return ""
+	}
+	switch container.Configuration.OIDCProvider {
+	case "google":
+		return "Google"
+	case "github":
+		return "GitHub"
+	}
+
+	return "Single Sign-On"
+}
+
+// oidcClient Find the configured provider, discovering where to send users when it is an OpenID Connect issuer
+func oidcClient(container *app.Container) (oidc.Client, error) {
+	provider, err := oidc.Discover(nil, container.Configuration.OIDCProvider)
+	if err != nil {
+		return oidc.Client{}, err
+	}
+
+	return oidc.Client{
+		ClientID:     container.Configuration.OIDCClientID,
+		ClientSecret: container.Configuration.OIDCClientSecret,
+		Provider:     provider,
+	}, nil
+}
+
+// oidcCookie Build the state cookie, only sent back to the callback
+func oidcCookie(request *http.Request, container *app.Container, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     container.BasePath + "/login/oidc",
+		HttpOnly: true,
+		Secure:   proxy.Secure(request),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
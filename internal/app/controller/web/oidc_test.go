@@ -0,0 +1,136 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+// oidcIssuer A provider vouching for anyone sending the code "code123"
+func oidcIssuer() *httptest.Server {
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			w.Write([]byte(`{"authorization_endpoint":"` + issuer.URL + `/authorize","token_endpoint":"` + issuer.URL + `/token","userinfo_endpoint":"` + issuer.URL + `/userinfo"}`))
+		case "/token":
+			if r.FormValue("code") != "code123" {
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"token123"}`))
+		case "/userinfo":
+			w.Write([]byte(`{"sub":"248289761001","email":"jamie@example.com","email_verified":true}`))
+		}
+	}))
+
+	return issuer
+}
+
+func TestOIDCLogin_Run(t *testing.T) {
+	issuer := oidcIssuer()
+	defer issuer.Close()
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: &database.MockSqlite{}}
+	response := controller.NewMockResponse()
+	controller := &OIDCLogin{}
+	controller.Init(container, []string{})
+
+	// Test not found when no provider is configured
+	request, _ := http.NewRequest("GET", "/login/oidc?next=/new", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when no provider is configured")
+	}
+
+	// Test error when the provider cannot be found
+	response.Reset()
+	container.Configuration.OIDCClientID = "journal"
+	container.Configuration.OIDCProvider = "example"
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected error when the provider cannot be found")
+	}
+
+	// Test user sent to the provider, remembering the state and page to return to
+	response.Reset()
+	container.Configuration.OIDCProvider = issuer.URL
+	request.Host = "journal.example.com"
+	controller.Run(response, request)
+	location, _ := url.Parse(response.Headers.Get("Location"))
+	cookie := response.Headers.Get("Set-Cookie")
+	if !strings.HasPrefix(location.String(), issuer.URL+"/authorize?") || location.Query().Get("redirect_uri") != "http://journal.example.com/login/oidc/callback" || location.Query().Get("client_id") != "journal" {
+		t.Errorf("Expected user to be sent to the provider, got %s", location)
+	}
+	if !strings.Contains(cookie, "state="+location.Query().Get("state")) || !strings.Contains(cookie, "next=%2Fnew") || !strings.Contains(cookie, "Path=/login/oidc") || !strings.Contains(cookie, "HttpOnly") {
+		t.Errorf("Expected state to be kept in a cookie, got %s", cookie)
+	}
+}
+
+func TestOIDCCallback_Run(t *testing.T) {
+	issuer := oidcIssuer()
+	defer issuer.Close()
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &OIDCCallback{}
+	controller.Init(container, []string{})
+	callback := func(query string, state string) *http.Request {
+		request, _ := http.NewRequest("GET", "/login/oidc/callback?"+query, nil)
+		request.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: url.Values{"state": {state}, "next": {"/new"}}.Encode()})
+		return request
+	}
+
+	// Test not found when no provider is configured
+	controller.Run(response, callback("code=code123&state=abc", "abc"))
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when no provider is configured")
+	}
+
+	// Test state must match the one sent
+	response.Reset()
+	container.Configuration.OIDCClientID = "journal"
+	container.Configuration.OIDCProvider = issuer.URL
+	controller.Run(response, callback("code=code123&state=forged", "abc"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) || strings.Contains(strings.Join(response.Headers.Values("Set-Cookie"), "\n"), auth.SessionCookie) {
+		t.Error("Expected forged state to be refused")
+	}
+	response.Reset()
+	request, _ := http.NewRequest("GET", "/login/oidc/callback?code=code123&state=", nil)
+	controller.Run(response, request)
+	if response.Headers.Get("Location") != "/login?next=/" || !flashed(response, app.FlashError) {
+		t.Error("Expected missing state to be refused")
+	}
+
+	// Test provider refusing the code
+	response.Reset()
+	controller.Run(response, callback("code=stolen&state=abc", "abc"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected refused code to not sign in")
+	}
+
+	// Test account without a user
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, callback("code=code123&state=abc", "abc"))
+	if response.Headers.Get("Location") != "/login?next=/new" || !flashed(response, app.FlashError) {
+		t.Error("Expected account without a user to not sign in")
+	}
+
+	// Test linked account signs in
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockIdentity_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	controller.Run(response, callback("code=code123&state=abc", "abc"))
+	cookies := strings.Join(response.Headers.Values("Set-Cookie"), "\n")
+	if response.Headers.Get("Location") != "/new" || !strings.Contains(cookies, auth.SessionCookie+"=") || !strings.Contains(cookies, oidcStateCookie+"=; Path=/login/oidc; Max-Age=0") {
+		t.Error("Expected linked account to sign in, forgetting the state, and return to the page asked for")
+	}
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/blogroll"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// readingItems Number of items shown on the reading page
+const readingItems = 30
+
+// Reading Handle showing the latest items from followed feeds alongside the latest entries, with the blogroll
+type Reading struct {
+	controller.Super
+	Items         []blogroll.Item
+	Subscriptions []model.Subscription
+}
+
+// Run Reading action
+func (c *Reading) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	ss := model.Subscriptions{Container: container}
+	c.Subscriptions = ss.FetchAll()
+	c.Items = blogroll.Reading(container, readingItems)
+
+	template, err := container.Templates("_layout/default.tmpl", "reading.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// BlogrollOPML Serve the followed feeds as an OPML file, ready to import into a feed reader
+type BlogrollOPML struct {
+	controller.Super
+}
+
+// Run BlogrollOPML action
+func (c *BlogrollOPML) Run(response http.ResponseWriter, request *http.Request) {
+	output, err := blogroll.Export(c.Super.Container.(*app.Container)).Render()
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Add("Content-Type", "text/x-opml; charset=utf-8")
+	response.Write(output)
+}
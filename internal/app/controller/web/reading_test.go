@@ -0,0 +1,56 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestReading_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Reading{}
+
+	// Test nothing to read
+	controller.Init(container, []string{"", "0"})
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/reading", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "There is nothing to read yet") || !strings.Contains(response.Content, "No feeds are followed yet") {
+		t.Error("Expected empty messages to be displayed on screen")
+	}
+
+	// Test items from feeds shown alongside entries, with the blogroll
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockSubscription_MultipleRows{})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockSubscriptionItem_MultipleRows{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Alice&#39;s Post") || !strings.Contains(response.Content, "https://alice.example.com/post") || !strings.Contains(response.Content, "/slug-2") {
+		t.Error("Expected items and entries to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, `<a href="https://alice.example.com/" rel="noopener">Alice&#39;s Blog</a></li>`) || !strings.Contains(response.Content, "https://bob.example.com/atom.xml</a></li>") {
+		t.Error("Expected blogroll to be displayed on screen")
+	}
+}
+
+func TestBlogrollOPML_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &BlogrollOPML{}
+	controller.Init(container, []string{"", "0"})
+
+	db.Rows = &database.MockSubscription_MultipleRows{}
+	request, _ := http.NewRequest("GET", "/blogroll.opml", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "text/x-opml; charset=utf-8" || !strings.Contains(response.Content, `xmlUrl="https://alice.example.com/feed"`) || !strings.Contains(response.Content, `xmlUrl="https://bob.example.com/atom.xml"`) {
+		t.Errorf("Expected feeds to be exported, got %s", response.Content)
+	}
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// registerError Why a journal may not have been signed up for
+const registerError = "Make sure you have provided a title and a name that is not already taken, using only lowercase letters, numbers and hyphens, along with a username of 2-64 letters, numbers, hyphens or underscores and a password of at least 8 characters entered the same twice."
+
+// Register Handle signing up for a new hosted journal, adding whoever signs up as the admin who manages it
+type Register struct {
+	controller.Super
+	Tenant model.Tenant
+}
+
+// Run Register action
+func (c *Register) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if container.Configuration.TenantMode == "" || container.Tenant != "" {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "GET" {
+		template, err := container.Templates("_layout/default.tmpl", "register.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
+		template.ExecuteTemplate(response, "layout", c)
+	} else {
+		ts := model.Tenants{Container: container}
+		tenant := model.Tenant{Name: strings.ToLower(request.FormValue("name")), Title: request.FormValue("title")}
+		admin := model.User{Username: request.FormValue("username"), Email: request.FormValue("email"), Role: model.RoleAdmin}
+		password := request.FormValue("password")
+		if tenant.Title == "" || !ts.IsAvailable(tenant.Name) || password != request.FormValue("confirm") || admin.SetPassword(password) != nil {
+			flash.Error(response, request, container, registerError)
+			http.Redirect(response, request, container.BasePath+"/register", 302)
+			return
+		}
+
+		tenant, err := ts.Save(tenant)
+		if err != nil {
+			flash.Error(response, request, container, registerError)
+			http.Redirect(response, request, container.BasePath+"/register", 302)
+			return
+		}
+
+		// The tenant is saved first so that its name is claimed, and removed again should its journal not be given
+		// its admin, as the setup page is never offered to hosted journals
+		if err := c.addAdmin(container, tenant, admin); err != nil {
+			container.Log().Warn("Could not add the admin of a hosted journal", "tenant", tenant.Name, "err", err)
+			ts.Delete(tenant)
+			flash.Error(response, request, container, registerError)
+			http.Redirect(response, request, container.BasePath+"/register", 302)
+			return
+		}
+
+		if container.Configuration.TenantMode == app.TenantModeSubdomain {
+			http.Redirect(response, request, "//"+tenant.Name+"."+container.Configuration.TenantDomain+"/login", 302)
+		} else {
+			http.Redirect(response, request, container.BasePath+"/"+tenant.Name+"/login", 302)
+		}
+	}
+}
+
+// addAdmin Add the admin who manages a newly hosted journal to its own database
+func (c *Register) addAdmin(container *app.Container, tenant model.Tenant, admin model.User) error {
+	if container.TenantOpener == nil {
+		return errors.New("Hosted journals cannot be opened")
+	}
+	journal, err := container.TenantOpener.OpenTenant(tenant.Name, tenant.Title)
+	if err != nil {
+		return err
+	}
+	us := model.Users{Container: journal}
+	_, err = us.Save(admin)
+
+	return err
+}
@@ -0,0 +1,134 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+type mockTenantOpener struct {
+	container *app.Container
+}
+
+func (m *mockTenantOpener) OpenTenant(name string, title string) (*app.Container, error) {
+	if m.container == nil {
+		return nil, errors.New("Simulating error")
+	}
+
+	return m.container, nil
+}
+
+func TestRegister_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	configuration := app.DefaultConfiguration()
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Register{}
+
+	// Test not found when hosting is disabled
+	controller.Init(container, []string{""})
+	request, _ := http.NewRequest("GET", "/register", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when multi-tenant hosting is disabled")
+	}
+
+	// Test not found within a tenant
+	response.Reset()
+	container.Configuration.TenantMode = app.TenantModePath
+	container.Tenant = "alice"
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when already within a tenant")
+	}
+
+	// Display form
+	response.Reset()
+	container.Tenant = ""
+	request, _ = http.NewRequest("GET", "/register", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<form") {
+		t.Error("Expected form to be shown")
+	}
+
+	// Redirect when name is taken
+	response.Reset()
+	db.Rows = &database.MockTenant_SingleRow{}
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=alice&title=Mine&username=alice&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/register" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form when name is taken")
+	}
+
+	// Redirect when an entry of the journal itself has the name
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{}
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=slug&title=Mine&username=bob&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/register" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form when an entry has the name")
+	}
+
+	// Redirect when the passwords do not match
+	response.Reset()
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=bob&title=Mine&username=bob&password=password123&confirm=password456"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/register" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form when the passwords do not match")
+	}
+
+	// Redirect when saving fails, after the name is checked against entries and journals
+	response.Reset()
+	db.ErrorAtQuery = db.Queries + 3
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=bob&title=Mine&username=bob&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/register" || !flashed(response, app.FlashError) {
+		t.Error("Expected redirect back to form when saving fails")
+	}
+
+	// Remove the tenant again when its journal cannot be given its admin
+	response.Reset()
+	container.TenantOpener = &mockTenantOpener{}
+	queries := db.Queries
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=bob&title=Mine&username=bob&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/register" || !flashed(response, app.FlashError) || db.Queries != queries+4 {
+		t.Errorf("Expected tenant to be removed and redirect back to form when its admin cannot be added, got %d queries", db.Queries-queries)
+	}
+
+	// Redirect to new journal by path, with the admin added to its own database
+	response.Reset()
+	tenantDb := &database.MockSqlite{Result: &database.MockResult{}, Rows: &database.MockRowsEmpty{}}
+	container.TenantOpener = &mockTenantOpener{container: &app.Container{Configuration: configuration, Db: tenantDb, Tenant: "bob"}}
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=Bob&title=Mine&username=bob&email=bob@example.com&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/bob/login" {
+		t.Error("Expected redirect to sign in to the new journal")
+	}
+	if tenantDb.Queries != 2 {
+		t.Errorf("Expected the admin to be added to the new journal, got %d queries", tenantDb.Queries)
+	}
+
+	// Redirect to new journal by subdomain
+	response.Reset()
+	container.Configuration.TenantMode = app.TenantModeSubdomain
+	container.Configuration.TenantDomain = "example.com"
+	request, _ = http.NewRequest("POST", "/register", strings.NewReader("name=bob&title=Mine&username=bob&password=password123&confirm=password123"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "//bob.example.com/login" {
+		t.Error("Expected redirect to the new journal's subdomain")
+	}
+}
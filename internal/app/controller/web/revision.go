@@ -0,0 +1,76 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/diff"
+)
+
+// Revision Handle comparing an earlier version of an entry with the current one, and restoring it
+type Revision struct {
+	controller.Super
+	Diff     []diff.Line
+	Journal  model.Journal
+	Revision model.JournalRevision
+}
+
+// Run Revision action
+func (c *Revision) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	store := model.Store(container)
+	rs := model.JournalRevisions{Container: container}
+	c.Journal = store.GetBySlug(c.Params[1])
+	id, _ := strconv.Atoi(c.Params[2])
+	if c.Journal.ID > 0 {
+		c.Revision = rs.FindByID(c.Journal.ID, id)
+	}
+	if c.Revision.ID == 0 {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	// Earlier versions may hold what an entry no longer shows, so only those who may edit it see or restore them
+	if !c.Journal.CanEdit(auth.CurrentUser(request, container)) {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "POST" {
+		ls := model.JournalLinks{Container: container}
+		previous := ls.Load(c.Journal)
+		restored := previous
+		restored.Title = c.Revision.Title
+		restored.Date = c.Revision.Date
+		restored.Content = c.Revision.Content
+		saved, err := model.SaveJournal(container, previous, restored)
+		if err != nil {
+			redirectFailed(response, request, container, container.BasePath+"/"+previous.Slug+"/history", entrySaveError)
+			return
+		}
+		c.Journal = saved
+
+		flash.Success(response, request, container, "Earlier version restored.")
+		http.Redirect(response, request, container.BasePath+"/"+c.Journal.Slug+"/history", 302)
+		return
+	}
+
+	c.Diff = diff.Lines(c.Revision.Content, c.Journal.Content)
+
+	template, err := container.Templates("_layout/default.tmpl", "revision.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestRevision_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	response := controller.NewMockResponse()
+	controller := &Revision{}
+	controller.Init(container, []string{"", "slug", "2"})
+	request, _ := http.NewRequest("GET", "/slug/history/2", strings.NewReader(""))
+
+	// Test not found when editing is disabled
+	container.Configuration.EnableEdit = false
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test not found
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 when revision not found")
+	}
+
+	// Test forbidden to those who may not edit the entry
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalRevision_SingleRow{})
+	controller.Run(response, request)
+	if response.StatusCode != 403 || strings.Contains(response.Content, "Old content") {
+		t.Error("Expected 403 when the entry may not be edited")
+	}
+
+	// Test differences shown and escaped
+	response.Reset()
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalRevision_SingleRow{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<span class="diff-removed">Old content</span>`) ||
+		!strings.Contains(response.Content, `<span class="diff-added">Content</span>`) ||
+		!strings.Contains(response.Content, "&lt;script&gt;") ||
+		!strings.Contains(response.Content, "<del>Old Title</del>") {
+		t.Error("Expected differences to be displayed on screen")
+	}
+
+	// Test restore
+	response.Reset()
+	db.Queries = 0
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalRevision_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	request, _ = http.NewRequest("POST", "/slug/history/2", strings.NewReader(""))
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/history" || !flashed(response, app.FlashSuccess) || db.Queries != 6 {
+		t.Errorf("Expected revision to be restored and redirect to history, got %d queries", db.Queries)
+	}
+
+	// Test an entry that could not be saved is left as it was
+	response.Reset()
+	db.Queries = 0
+	db.ErrorAtQuery = 4
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalRevision_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/slug/history" || !flashed(response, app.FlashError) {
+		t.Error("Expected failure restoring the revision to be shown")
+	}
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Search Handle finding entries by their title and content
+type Search struct {
+	controller.Super
+	Journals   []model.Journal
+	Pages      []int
+	Pagination database.PaginationInformation
+	Query      string
+}
+
+// Run Search action
+func (c *Search) Run(response http.ResponseWriter, request *http.Request) {
+
+	container := c.Super.Container.(*app.Container)
+	search := model.JournalSearch{Container: container}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+
+	c.Query = strings.TrimSpace(query.Get("q"))
+	c.Journals, c.Pagination = search.Search(c.Query, pagination)
+	us := model.Users{Container: container}
+	c.Journals = us.LoadAuthors(c.Journals)
+
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "search.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
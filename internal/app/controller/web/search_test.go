@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSearch_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Search{}
+
+	// Test empty search shows the form only
+	controller.Init(container, []string{"", "0"})
+	request, _ := http.NewRequest("GET", "/search", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="q"`) || strings.Contains(response.Content, "result") || db.Queries != 0 {
+		t.Error("Expected search form to be displayed without running a search")
+	}
+
+	// Test results are shown
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/search?q=title", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "2 results for") || !strings.Contains(response.Content, "Title 2") {
+		t.Error("Expected matching journals to be displayed on screen")
+	}
+
+	// Test pagination keeps the query and the query is escaped
+	response.Reset()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/search?q=%3Cb%3E+x&page=2", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "q=%3cb%3e%20x&amp;page=1") || strings.Contains(response.Content, "<b>") {
+		t.Error("Expected pagination links to keep the escaped query")
+	}
+}
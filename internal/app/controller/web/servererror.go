@@ -0,0 +1,55 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// ServerError Display a 500 page when something has gone wrong serving a request
+type ServerError struct {
+	controller.Super
+	Error error
+}
+
+// Run ServerError, rendering the page in full before writing it so that a broken template still gives a plain error.
+// What went wrong is only shown with -dev, when it is most likely a template being worked on.
+func (c *ServerError) Run(response http.ResponseWriter, request *http.Request) {
+	page := bytes.Buffer{}
+	container, _ := c.Super.Container.(*app.Container)
+	template, err := container.Templates("_layout/default.tmpl", "servererror.tmpl")
+	if err == nil {
+		err = template.ExecuteTemplate(&page, "layout", c)
+	}
+	if err != nil {
+		message := http.StatusText(http.StatusInternalServerError)
+		if container != nil && container.Configuration.Dev {
+			if c.Error != nil {
+				message += "\n\n" + c.Error.Error()
+			}
+			message += "\n\n" + err.Error()
+		}
+		http.Error(response, message, http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	response.WriteHeader(http.StatusInternalServerError)
+	page.WriteTo(response)
+}
+
+// RunServerError calls the server error from an existing controller, logging what went wrong
+func RunServerError(response http.ResponseWriter, request *http.Request, container interface{}, err error) {
+	if bound, ok := container.(*app.Container); ok && bound != nil {
+		path := ""
+		if request.URL != nil {
+			path = request.URL.Path
+		}
+		bound.Log().Error("Could not render page", "path", path, "err", err)
+	}
+	errorController := ServerError{Error: err}
+	errorController.Init(container, []string{})
+	errorController.Run(response, request)
+}
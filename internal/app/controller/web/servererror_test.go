@@ -0,0 +1,51 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestServerError_Run(t *testing.T) {
+	response := &controller.MockResponse{}
+	response.Reset()
+	controller := &ServerError{}
+	controller.Init(&app.Container{Configuration: app.DefaultConfiguration()}, []string{})
+
+	controller.Run(response, &http.Request{})
+	if response.StatusCode != 500 || !strings.Contains(response.Content, "Something Went Wrong") || !strings.Contains(response.Content, "</html>") {
+		t.Error("Expected themed 500 page")
+	}
+
+	// Without a container the page cannot be rendered, so a plain error is given instead
+	response.Reset()
+	controller.Init(nil, []string{})
+	controller.Run(response, &http.Request{})
+	if response.StatusCode != 500 || strings.Contains(response.Content, "<html") || !strings.Contains(response.Content, "Internal Server Error") {
+		t.Errorf("Expected plain 500 error, got %s", response.Content)
+	}
+}
+
+func TestRunServerError(t *testing.T) {
+	response := &controller.MockResponse{}
+	response.Reset()
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	request, _ := http.NewRequest("GET", "/", nil)
+
+	RunServerError(response, request, container, errors.New("template: index.tmpl:1: unexpected EOF"))
+	if response.StatusCode != 500 || strings.Contains(response.Content, "unexpected EOF") {
+		t.Error("Expected 500 page without what went wrong")
+	}
+
+	// While developing, what went wrong is shown
+	response.Reset()
+	container.Configuration.Dev = true
+	RunServerError(response, request, container, errors.New("template: index.tmpl:1: unexpected EOF"))
+	if response.StatusCode != 500 || !strings.Contains(response.Content, "unexpected EOF") || !strings.Contains(response.Content, "</html>") {
+		t.Errorf("Expected 500 page showing what went wrong, got %s", response.Content)
+	}
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// setupError Why the journal may not have been set up
+const setupError = "The journal could not be set up. Usernames must be 2-64 letters, numbers, hyphens or underscores, and passwords at least 8 characters and entered the same twice."
+
+// Setup Set the journal up as it is first run, choosing its title and adding the admin who manages it, asking for the
+// code logged as it started so that nobody reaching it first can take it over
+type Setup struct {
+	controller.Super
+	Title string
+}
+
+// Run Setup action
+func (c *Setup) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if container.SetupCode == "" || !model.NeedsSetup(container) {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if request.Method == "GET" {
+		c.Title = container.Configuration.Title
+
+		template, err := container.Templates("_layout/default.tmpl", "setup.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
+		template.ExecuteTemplate(response, "layout", c)
+		return
+	}
+
+	code := strings.TrimSpace(request.FormValue("code"))
+	if subtle.ConstantTimeCompare([]byte(code), []byte(container.SetupCode)) != 1 {
+		flash.Error(response, request, container, "That setup code does not match the one logged as the journal started, please try again.")
+		http.Redirect(response, request, container.BasePath+"/setup", 302)
+		return
+	}
+	password := request.FormValue("password")
+	if password != request.FormValue("confirm") {
+		flash.Error(response, request, container, setupError)
+		http.Redirect(response, request, container.BasePath+"/setup", 302)
+		return
+	}
+	admin := model.User{Username: request.FormValue("username"), Email: request.FormValue("email")}
+	admin, err := model.Setup(container, strings.TrimSpace(request.FormValue("title")), admin, password)
+	if err != nil {
+		container.Log().Warn("Could not set the journal up", "err", err)
+		flash.Error(response, request, container, setupError)
+		http.Redirect(response, request, container.BasePath+"/setup", 302)
+		return
+	}
+	container.Log().Info("Set the journal up", "admin", admin.Username)
+
+	if err := auth.StartSession(response, request, container, admin); err != nil {
+		http.Redirect(response, request, container.BasePath+"/login", 302)
+		return
+	}
+	auth.Audit(request, container, model.SecurityEvent{Kind: model.SecurityLogin, UserID: admin.ID, Username: admin.Username})
+
+	http.Redirect(response, request, container.BasePath+"/", 302)
+}
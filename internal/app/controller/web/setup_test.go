@@ -0,0 +1,80 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSetup_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db, Settings: app.NewSettings(nil)}
+	response := controller.NewMockResponse()
+	controller := &Setup{}
+	controller.Init(container, []string{})
+	post := func(body string) *http.Request {
+		request, _ := http.NewRequest("POST", "/setup", strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return request
+	}
+
+	// Test not found unless the journal started needing to be set up
+	request, _ := http.NewRequest("GET", "/setup", nil)
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when no setup code was logged")
+	}
+	response.Reset()
+	container.SetupCode = "abc123"
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	controller.Run(response, request)
+	if response.StatusCode != 404 {
+		t.Error("Expected 404 once the journal has an admin")
+	}
+
+	// Test form is shown with the configured title
+	response.Reset()
+	db.Rows = &database.MockPagination_Result{TotalResults: 0}
+	request, _ = http.NewRequest("GET", "/setup", nil)
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `name="title" value="Jamie&#39;s Journal"`) {
+		t.Error("Expected setup form to be shown")
+	}
+
+	// Test a wrong code or passwords that differ are refused before anything is saved
+	response.Reset()
+	db.Queries = 0
+	controller.Run(response, post("code=wrong&title=Mine&username=alice&password=password1&confirm=password1"))
+	if response.Headers.Get("Location") != "/setup" || !flashed(response, app.FlashError) || db.Queries != 1 {
+		t.Error("Expected a wrong code to be refused")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "setup code does not match") {
+		t.Error("Expected the wrong code to be explained")
+	}
+	response.Reset()
+	db.Rows = &database.MockPagination_Result{TotalResults: 0}
+	controller.Run(response, post("code=abc123&title=Mine&username=alice&password=password1&confirm=password2"))
+	if response.Headers.Get("Location") != "/setup" || !flashed(response, app.FlashError) || db.Queries != 2 {
+		t.Error("Expected passwords that differ to be refused")
+	}
+
+	// Test the journal is set up, signing the admin in
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, post("code=+abc123+&title=Alice%27s+Journal&username=alice&email=alice%40example.com&password=password1&confirm=password1"))
+	if response.Headers.Get("Location") != "/" || !strings.HasPrefix(response.Headers.Get("Set-Cookie"), auth.SessionCookie+"=") {
+		t.Error("Expected the admin to be added, signed in and sent home")
+	}
+	if container.Settings.Get(app.SettingTitle) != "Alice's Journal" {
+		t.Error("Expected the title to have been saved")
+	}
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/sitemap"
+)
+
+// robotsDisallowed Paths crawlers are asked to stay out of when no robots.txt is configured
+var robotsDisallowed = []string{"/admin", "/api/", "/drafts", "/new", "/search", "/trash"}
+
+// Sitemap Serve every published entry as a sitemap, with when each was last changed
+type Sitemap struct {
+	controller.Super
+}
+
+// Run Sitemap action
+func (c *Sitemap) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	js := model.Journals{Container: container}
+
+	// The home page changes whenever an entry does
+	urls := []sitemap.URL{{Loc: absoluteURL(container, request, "/")}}
+	for _, e := range js.FetchSitemap() {
+		urls = append(urls, sitemap.URL{Loc: absoluteURL(container, request, "/"+e.Slug), LastMod: e.LastModified()})
+		if e.LastModified().After(urls[0].LastMod) {
+			urls[0].LastMod = e.LastModified()
+		}
+	}
+
+	output, err := sitemap.Render(urls)
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Add("Content-Type", sitemap.ContentType)
+	response.Write(output)
+}
+
+// Robots Serve the robots.txt configured, or one keeping crawlers out of the pages for writing, always pointing to the
+// sitemap
+type Robots struct {
+	controller.Super
+}
+
+// Run Robots action
+func (c *Robots) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	content := ""
+	if path := container.Configuration.RobotsPath; path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			container.Log().Warn("Could not read robots.txt", "path", path, "err", err)
+		}
+		content = string(data)
+	}
+	if content == "" {
+		content = "User-agent: *\n"
+		for _, path := range robotsDisallowed {
+			content += "Disallow: " + container.BasePath + path + "\n"
+		}
+	}
+	if !strings.Contains(strings.ToLower(content), "sitemap:") {
+		content = strings.TrimRight(content, "\n") + "\n\nSitemap: " + absoluteURL(container, request, "/sitemap.xml") + "\n"
+	}
+
+	response.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	response.Write([]byte(content))
+}
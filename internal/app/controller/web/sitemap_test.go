@@ -0,0 +1,90 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSitemap_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Sitemap{}
+	controller.Init(container, []string{"", "0"})
+
+	// Test only the home page is listed without entries
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/sitemap.xml", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "application/xml; charset=utf-8" || strings.Count(response.Content, "<url>") != 1 || !strings.Contains(response.Content, "<loc>http://journal.local/</loc>") {
+		t.Errorf("Expected home page to be listed, got:\n%s", response.Content)
+	}
+
+	// Test entries are listed with when they last changed
+	response.Reset()
+	container.Configuration.URL = "https://example.com"
+	db.Rows = &database.MockSitemap_MultipleRows{}
+	controller.Run(response, request)
+	expected := []string{
+		"<loc>https://example.com/</loc>\n    <lastmod>2018-03-04T10:00:00Z</lastmod>",
+		"<loc>https://example.com/slug-2</loc>\n    <lastmod>2018-03-04T10:00:00Z</lastmod>",
+		"<loc>https://example.com/slug</loc>\n    <lastmod>2018-02-01T00:00:00Z</lastmod>",
+	}
+	for _, e := range expected {
+		if !strings.Contains(response.Content, e) {
+			t.Errorf("Expected sitemap to contain %s, got:\n%s", e, response.Content)
+		}
+	}
+}
+
+func TestRobots_Run(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	response := controller.NewMockResponse()
+	controller := &Robots{}
+	controller.Init(container, []string{"", "0"})
+
+	// Test crawlers are kept out of the pages for writing by default
+	request, _ := http.NewRequest("GET", "/robots.txt", strings.NewReader(""))
+	request.Host = "journal.local"
+	controller.Run(response, request)
+	if response.Headers.Get("Content-Type") != "text/plain; charset=utf-8" || !strings.HasPrefix(response.Content, "User-agent: *\nDisallow: /admin\n") || !strings.HasSuffix(response.Content, "\n\nSitemap: http://journal.local/sitemap.xml\n") {
+		t.Errorf("Expected default robots.txt, got:\n%s", response.Content)
+	}
+
+	// Test a configured file is served, pointing to the sitemap
+	file, _ := ioutil.TempFile("", "robots")
+	defer os.Remove(file.Name())
+	file.WriteString("User-agent: *\nDisallow: /\n")
+	file.Close()
+	response.Reset()
+	container.Configuration.RobotsPath = file.Name()
+	container.Configuration.URL = "https://example.com"
+	controller.Run(response, request)
+	if response.Content != "User-agent: *\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n" {
+		t.Errorf("Expected configured robots.txt, got:\n%s", response.Content)
+	}
+
+	// Test a sitemap given in the file is left alone
+	ioutil.WriteFile(file.Name(), []byte("User-agent: *\nSitemap: https://cdn.example.com/sitemap.xml\n"), 0644)
+	response.Reset()
+	controller.Run(response, request)
+	if strings.Count(response.Content, "Sitemap:") != 1 {
+		t.Errorf("Expected the configured sitemap to be kept, got:\n%s", response.Content)
+	}
+
+	// Test the default is used when the file cannot be read
+	response.Reset()
+	container.Configuration.RobotsPath = file.Name() + "-missing"
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Disallow: /trash") {
+		t.Errorf("Expected default robots.txt, got:\n%s", response.Content)
+	}
+}
@@ -0,0 +1,25 @@
+package web
+
+import (
+	"io/fs"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/assets"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Static Serve the stylesheets, scripts and images the pages load, letting browsers keep them
+type Static struct {
+	controller.Super
+}
+
+// Run Static action
+func (c *Static) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	static, _ := fs.Sub(container.Configuration.Files(), "static")
+	files := assets.Assets{Files: static, MaxAge: container.Configuration.StaticMaxAge}
+	if !files.Serve(response, request, c.Params[1]) {
+		RunBadRequest(response, request, c.Super.Container)
+	}
+}
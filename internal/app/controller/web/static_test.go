@@ -0,0 +1,33 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestStatic_Run(t *testing.T) {
+	response := controller.NewMockResponse()
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	controller := &Static{}
+
+	// Test stylesheet is served with caching headers
+	request, _ := http.NewRequest("GET", "/static/css/default.min.css", nil)
+	controller.Init(container, []string{"/static/css/default.min.css", "css/default.min.css"})
+	controller.Run(response, request)
+	if response.StatusCode != 200 || response.Headers.Get("ETag") == "" || response.Headers.Get("Cache-Control") != "public, max-age=86400" ||
+		!strings.HasPrefix(response.Headers.Get("Content-Type"), "text/css") {
+		t.Errorf("Expected stylesheet with caching headers, got %d and %v", response.StatusCode, response.Headers)
+	}
+
+	// Test anything outside the directory is not found
+	response.Reset()
+	controller.Init(container, []string{"/static/../journal.go", "../../journal.go"})
+	controller.Run(response, request)
+	if response.StatusCode != 404 || strings.Contains(response.Content, "package main") {
+		t.Error("Expected file outside the directory not to be found")
+	}
+}
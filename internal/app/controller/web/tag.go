@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Tag Handle listing the entries given a tag
+type Tag struct {
+	controller.Super
+	Journals   []model.Journal
+	Pages      []int
+	Pagination database.PaginationInformation
+	Tag        model.Tag
+}
+
+// Run Tag action
+func (c *Tag) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	ts := model.JournalTags{Container: container}
+	c.Tag = ts.FindBySlug(c.Params[1])
+	if c.Tag.Slug == "" {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	pagination := database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage}
+	query := request.URL.Query()
+	if query["page"] != nil {
+		page, err := strconv.Atoi(query["page"][0])
+		if err == nil {
+			pagination.Page = page
+		}
+	}
+
+	js := model.Journals{Container: container}
+	c.Journals, c.Pagination = js.FetchPaginatedByTag(c.Tag.Slug, pagination)
+	us := model.Users{Container: container}
+	c.Journals = us.LoadAuthors(c.Journals)
+
+	c.Pages = make([]int, c.Pagination.TotalPages)
+	for i := range c.Pages {
+		c.Pages[i] = i + 1
+	}
+
+	template, err := container.Templates("_layout/default.tmpl", "tag.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTag_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	configuration := app.DefaultConfiguration()
+	configuration.ArticlesPerPage = 2
+	container := &app.Container{Configuration: configuration, Db: db}
+	response := controller.NewMockResponse()
+	controller := &Tag{}
+
+	// Test not found
+	controller.Init(container, []string{"", "missing"})
+	db.Rows = &database.MockRowsEmpty{}
+	request, _ := http.NewRequest("GET", "/tag/missing", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || !strings.Contains(response.Content, "Page Not Found") {
+		t.Error("Expected 404 when tag not found")
+	}
+
+	// Test entries given the tag are listed
+	response.Reset()
+	controller.Init(container, []string{"", "road-trip"})
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	request, _ = http.NewRequest("GET", "/tag/road-trip", strings.NewReader(""))
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Tagged Road Trip</h2>") || !strings.Contains(response.Content, "Title 2") {
+		t.Error("Expected entries given the tag to be displayed on screen")
+	}
+	if !strings.Contains(response.Content, "/tag/road-trip?page=2") {
+		t.Error("Expected pagination links to stay within the tag")
+	}
+}
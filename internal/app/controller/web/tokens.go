@@ -0,0 +1,81 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// tokenError Why a token may not have been saved
+const tokenError = "The token could not be saved. It needs a name of up to 255 characters."
+
+// Tokens Let a signed in user issue and revoke their own personal access tokens for the API
+type Tokens struct {
+	controller.Super
+	Issued string
+	Tokens []model.Token
+}
+
+// Run Tokens action
+func (c *Tokens) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	user := auth.CurrentUser(request, container)
+	if user.ID == 0 {
+		RunForbidden(response, request, c.Super.Container)
+		return
+	}
+
+	ts := model.Tokens{Container: container}
+	if request.Method == "POST" {
+		if request.FormValue("action") == "revoke" {
+			id, _ := strconv.Atoi(request.FormValue("id"))
+			if err := ts.Revoke(user.ID, id); err != nil {
+				flash.Error(response, request, container, tokenError)
+				http.Redirect(response, request, container.BasePath+"/settings/tokens", 302)
+				return
+			}
+			flash.Success(response, request, container, "Token revoked.")
+			http.Redirect(response, request, container.BasePath+"/settings/tokens", 302)
+			return
+		}
+
+		name := strings.TrimSpace(request.FormValue("name"))
+		scopes := request.Form["scope"]
+		if len(scopes) == 0 {
+			scopes = []string{model.ScopeRead}
+		}
+		for _, scope := range scopes {
+			if scope != model.ScopeRead && scope != model.ScopeWrite {
+				name = ""
+			}
+		}
+		if name == "" || len(name) > 255 {
+			flash.Error(response, request, container, tokenError)
+			http.Redirect(response, request, container.BasePath+"/settings/tokens", 302)
+			return
+		}
+		_, plain, err := ts.Issue(user.ID, name, scopes)
+		if err != nil {
+			flash.Error(response, request, container, tokenError)
+			http.Redirect(response, request, container.BasePath+"/settings/tokens", 302)
+			return
+		}
+
+		// The plain token is only ever shown now, so the page is shown rather than redirecting to it
+		c.Issued = plain
+	}
+	c.Tokens = ts.FetchByUser(user.ID)
+
+	template, err := container.Templates("_layout/default.tmpl", "tokens.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
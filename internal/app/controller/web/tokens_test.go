@@ -0,0 +1,83 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTokens_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin-secret"
+	response := controller.NewMockResponse()
+	controller := &Tokens{}
+	controller.Init(container, []string{})
+	signedIn := func(method string, body string) *http.Request {
+		request, _ := http.NewRequest(method, "/settings/tokens", strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		request.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: "known"})
+		return request
+	}
+
+	// Test the admin token has no tokens of its own
+	request, _ := http.NewRequest("GET", "/settings/tokens", nil)
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	controller.Run(response, request)
+	if response.StatusCode != 403 {
+		t.Error("Expected admin token to be refused tokens of its own")
+	}
+
+	// Test tokens are listed
+	response.Reset()
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockToken_SingleRow{Scopes: "read"})
+	controller.Run(response, signedIn("GET", ""))
+	if !strings.Contains(response.Content, "<td>Script</td>") || !strings.Contains(response.Content, "<td>read</td>") || !strings.Contains(response.Content, "<td>Never</td>") || strings.Contains(response.Content, "will not be shown again") {
+		t.Error("Expected tokens to be listed")
+	}
+
+	// Test a token without a name is refused
+	response.Reset()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	controller.Run(response, signedIn("POST", "name=+&scope=read"))
+	if response.Headers.Get("Location") != "/settings/tokens" || !flashed(response, app.FlashError) {
+		t.Error("Expected token without a name to be refused")
+	}
+
+	// Test an unknown scope is refused
+	response.Reset()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	controller.Run(response, signedIn("POST", "name=Script&scope=admin"))
+	if response.Headers.Get("Location") != "/settings/tokens" || !flashed(response, app.FlashError) {
+		t.Error("Expected unknown scope to be refused")
+	}
+
+	// Test issuing a token shows it once
+	response.Reset()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	db.AppendResult(&database.MockToken_SingleRow{})
+	controller.Run(response, signedIn("POST", "name=Backup&scope=read&scope=write"))
+	if !strings.Contains(response.Content, "Your new token is <code>jt_") || !strings.Contains(response.Content, "will not be shown again") {
+		t.Error("Expected issued token to be shown")
+	}
+
+	// Test revoking a token
+	response.Reset()
+	db.AppendResult(&database.MockSession_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{})
+	controller.Run(response, signedIn("POST", "action=revoke&id=1"))
+	if response.Headers.Get("Location") != "/settings/tokens" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected token to be revoked")
+	}
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Trash Handle listing deleted entries so they can be restored or removed for good
+type Trash struct {
+	controller.Super
+	Journals []model.Journal
+}
+
+// Run Trash action
+func (c *Trash) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableEdit {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	store := model.Store(container)
+
+	if request.Method == "POST" {
+		journal := js.FindDeletedBySlug(request.FormValue("slug"))
+		if journal.ID > 0 {
+			switch request.FormValue("action") {
+			case "restore":
+				js.Restore(journal)
+			case "delete":
+				store.Delete(journal)
+			}
+		}
+		http.Redirect(response, request, container.BasePath+"/trash", 302)
+		return
+	}
+
+	c.Journals = js.FetchDeleted()
+
+	template, err := container.Templates("_layout/default.tmpl", "trash.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTrash_Run(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Trash{}
+	controller.Init(container, []string{"", "0"})
+
+	// Test not found when editing is disabled
+	container.Configuration.EnableEdit = false
+	request, _ := http.NewRequest("GET", "/trash", strings.NewReader(""))
+	controller.Run(response, request)
+	if response.StatusCode != 404 || db.Queries != 0 {
+		t.Error("Expected 404 when editing is disabled")
+	}
+
+	// Test empty trash
+	response.Reset()
+	container.Configuration.EnableEdit = true
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "The trash is empty") {
+		t.Error("Expected empty message to be displayed on screen")
+	}
+
+	// Test deleted journals listed
+	response.Reset()
+	db.Rows = &database.MockJournal_MultipleRows{}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Title 2") || !strings.Contains(response.Content, `value="slug-2"`) {
+		t.Error("Expected deleted journals to be displayed on screen")
+	}
+
+	// Test restore
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{DeletedAt: "2018-02-02 10:00:00"}
+	request, _ = http.NewRequest("POST", "/trash", strings.NewReader("slug=slug&action=restore"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/trash" || db.Queries != 2 {
+		t.Error("Expected journal to be restored and redirect back to trash")
+	}
+
+	// Test permanent delete removes links, revisions, comments, attachments, custom fields, tags and entry
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockJournal_SingleRow{DeletedAt: "2018-02-02 10:00:00"}
+	request, _ = http.NewRequest("POST", "/trash", strings.NewReader("slug=slug&action=delete"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	controller.Run(response, request)
+	if response.StatusCode != 302 || db.Queries != 9 {
+		t.Error("Expected journal to be deleted permanently")
+	}
+
+	// Test unknown entry does nothing
+	response.Reset()
+	db.Queries = 0
+	db.Rows = &database.MockRowsEmpty{}
+	controller.Run(response, request)
+	if response.StatusCode != 302 || db.Queries != 1 {
+		t.Error("Expected nothing to happen for an unknown entry")
+	}
+}
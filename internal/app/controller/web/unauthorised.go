@@ -0,0 +1,35 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Unauthorised Display a 401 page asking the reader to sign in
+type Unauthorised struct {
+	controller.Super
+}
+
+// Run Unauthorised
+func (c *Unauthorised) Run(response http.ResponseWriter, request *http.Request) {
+	realm := strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(c.Super.Container.(*app.Container).Configuration.Title)
+	response.Header().Set("WWW-Authenticate", "Basic realm=\""+realm+"\", charset=\"UTF-8\"")
+	container, _ := c.Super.Container.(*app.Container)
+	template, err := container.Templates("_layout/default.tmpl", "unauthorised.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	response.WriteHeader(http.StatusUnauthorized)
+	template.ExecuteTemplate(response, "layout", c)
+}
+
+// RunUnauthorised calls the unauthorised page from an existing controller
+func RunUnauthorised(response http.ResponseWriter, request *http.Request, container interface{}) {
+	errorController := Unauthorised{}
+	errorController.Init(container, []string{})
+	errorController.Run(response, request)
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestUnauthorised_Run(t *testing.T) {
+	response := &controller.MockResponse{}
+	response.Reset()
+	configuration := app.DefaultConfiguration()
+	configuration.Title = "A \"Quoted\" Journal"
+	controller := &Unauthorised{}
+	controller.Init(&app.Container{Configuration: configuration}, []string{})
+
+	// Test header and response
+	controller.Run(response, &http.Request{})
+	if response.StatusCode != 401 || !strings.Contains(response.Content, "Sign In Required") {
+		t.Error("Expected 401 error asking to sign in")
+	}
+	if response.Headers.Get("WWW-Authenticate") != "Basic realm=\"A \\\"Quoted\\\" Journal\", charset=\"UTF-8\"" {
+		t.Errorf("Expected challenge for the journal, got %s", response.Headers.Get("WWW-Authenticate"))
+	}
+}
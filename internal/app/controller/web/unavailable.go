@@ -0,0 +1,26 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Unavailable Display a 503 page for a change asked for while the journal is in maintenance
+type Unavailable struct {
+	controller.Super
+}
+
+// Run Unavailable
+func (c *Unavailable) Run(response http.ResponseWriter, request *http.Request) {
+	response.Header().Set("Retry-After", "60")
+	container, _ := c.Super.Container.(*app.Container)
+	template, err := container.Templates("_layout/default.tmpl", "unavailable.tmpl")
+	if err != nil {
+		RunServerError(response, request, c.Super.Container, err)
+		return
+	}
+	response.WriteHeader(http.StatusServiceUnavailable)
+	template.ExecuteTemplate(response, "layout", c)
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestUnavailable_Run(t *testing.T) {
+	response := &controller.MockResponse{}
+	response.Reset()
+	controller := &Unavailable{}
+	controller.Init(&app.Container{Configuration: app.DefaultConfiguration(), Maintenance: app.NewMaintenance(true)}, []string{})
+
+	controller.Run(response, &http.Request{})
+	if response.StatusCode != 503 || response.Headers.Get("Retry-After") != "60" || !strings.Contains(response.Content, "Down for Maintenance") ||
+		!strings.Contains(response.Content, `<div class="maintenance">`) {
+		t.Error("Expected 503 error explaining the journal is in maintenance, with its notice")
+	}
+}
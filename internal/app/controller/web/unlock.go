@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Unlock Handle a reader giving the password for a protected entry, remembering it in a cookie
+type Unlock struct {
+	controller.Super
+}
+
+// Run Unlock action
+func (c *Unlock) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	store := model.Store(container)
+	journal := store.GetBySlug(c.Params[1])
+	if journal.ID == 0 || !journal.IsProtected() {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	if !journal.CheckPassword(request.FormValue("password")) {
+		flash.Error(response, request, container, "That password is not right, please try again.")
+		http.Redirect(response, request, container.BasePath+"/"+journal.Slug, 302)
+		return
+	}
+
+	http.SetCookie(response, &http.Cookie{
+		Name:     unlockCookieName(journal),
+		Value:    journal.UnlockToken(),
+		Path:     container.BasePath + "/" + journal.Slug,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(response, request, container.BasePath+"/"+journal.Slug, 302)
+}
+
+// unlockCookieName Get the name of the cookie remembering a reader unlocked an entry
+func unlockCookieName(journal model.Journal) string {
+	return "journal_unlock_" + strconv.Itoa(journal.ID)
+}
+
+// isUnlocked Check whether a reader may see an entry, either as it has no password or as they have given it
+func isUnlocked(request *http.Request, journal model.Journal) bool {
+	if !journal.IsProtected() {
+		return true
+	}
+	cookie, err := request.Cookie(unlockCookieName(journal))
+
+	return err == nil && journal.CheckUnlockToken(cookie.Value)
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUnlock_Run(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	response := controller.NewMockResponse()
+	controller := &Unlock{}
+	controller.Init(container, []string{"", "slug"})
+	post := func(body string) *http.Request {
+		request, _ := http.NewRequest("POST", "/slug/unlock", strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return request
+	}
+	protected := model.Journal{ID: 1}
+	protected.SetPassword("secret")
+
+	// Test unknown and unprotected entries cannot be unlocked
+	for _, rows := range []*database.MockJournal_SingleRow{nil, {}} {
+		response.Reset()
+		db.Rows = &database.MockRowsEmpty{}
+		if rows != nil {
+			db.Rows = rows
+		}
+		controller.Run(response, post("password=secret"))
+		if response.StatusCode != 404 {
+			t.Error("Expected 404 when there is nothing to unlock")
+		}
+	}
+
+	// Test wrong password
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash}
+	controller.Run(response, post("password=wrong"))
+	if response.Headers.Get("Location") != "/slug" || !flashed(response, app.FlashError) || strings.Contains(strings.Join(response.Headers.Values("Set-Cookie"), "\n"), "journal_unlock_") {
+		t.Error("Expected error redirect without a cookie for the wrong password")
+	}
+
+	// Test right password sets the unlock cookie
+	response.Reset()
+	db.Rows = &database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash}
+	controller.Run(response, post("password=secret"))
+	cookie := response.Headers.Get("Set-Cookie")
+	if response.Headers.Get("Location") != "/slug" || !strings.HasPrefix(cookie, "journal_unlock_1="+protected.UnlockToken()+"; Path=/slug; HttpOnly") {
+		t.Errorf("Expected unlock cookie to be set, got %s", cookie)
+	}
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+)
+
+// Upload Handle storing an image sent as a multipart form
+type Upload struct {
+	controller.Super
+}
+
+type uploadResponse struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Markdown string `json:"markdown"`
+}
+
+// Run Upload action
+func (c *Upload) Run(response http.ResponseWriter, request *http.Request) {
+	container := c.Super.Container.(*app.Container)
+	if !container.Configuration.EnableCreate {
+		RunBadRequest(response, request, c.Super.Container)
+		return
+	}
+
+	// Scripts embedding images ask for JSON, the media page expects to be sent back
+	wantsJSON := strings.Contains(request.Header.Get("Accept"), "application/json")
+	fail := func(status int, message string) {
+		if wantsJSON {
+			response.WriteHeader(status)
+			return
+		}
+		flash.Error(response, request, container, message)
+		http.Redirect(response, request, container.BasePath+media.Path, 302)
+	}
+
+	request.Body = http.MaxBytesReader(response, request.Body, media.MaxSize+1<<20)
+	file, header, err := request.FormFile("file")
+	if err != nil {
+		fail(http.StatusBadRequest, "Choose an image to upload.")
+		return
+	}
+	defer file.Close()
+
+	stored, err := media.Store(container, header.Filename, file)
+	switch err {
+	case nil:
+	case media.ErrTooLarge:
+		fail(http.StatusRequestEntityTooLarge, "Images must be 10MB or smaller.")
+		return
+	case media.ErrUnsupported:
+		fail(http.StatusUnsupportedMediaType, "Only GIF, JPEG, PNG and WebP images can be uploaded.")
+		return
+	default:
+		fail(http.StatusInternalServerError, "Choose an image to upload.")
+		return
+	}
+
+	if wantsJSON {
+		response.Header().Add("Content-Type", "application/json")
+		response.WriteHeader(http.StatusCreated)
+		json.NewEncoder(response).Encode(uploadResponse{Name: stored.Name, URL: stored.URL, Markdown: stored.Markdown()})
+		return
+	}
+	flash.Success(response, request, container, "Image uploaded as %s.", stored.Name)
+	http.Redirect(response, request, container.BasePath+media.Path, 302)
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func uploadRequest(filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if filename != "" {
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write(content)
+	}
+	writer.Close()
+	request, _ := http.NewRequest("POST", "/upload", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Authorization", "Bearer admin-secret")
+
+	return request
+}
+
+func TestUpload_Run(t *testing.T) {
+	container := mediaContainer(t)
+	container.Configuration.EnableCreate = false
+	response := controller.NewMockResponse()
+	controller := &Upload{}
+
+	// Test not found when creating is disabled
+	controller.Init(container, []string{"", "0"})
+	controller.Run(response, uploadRequest("photo.png", testImage))
+	if response.StatusCode != 404 || len(media.List(container)) != 0 {
+		t.Error("Expected 404 when creating is disabled")
+	}
+
+	// Test successful upload redirects to the library
+	response.Reset()
+	container.Configuration.EnableCreate = true
+	controller.Run(response, uploadRequest("photo.png", testImage))
+	if response.StatusCode != 302 || response.Headers.Get("Location") != "/media" || !flashed(response, app.FlashSuccess) {
+		t.Error("Expected redirect to media library after upload")
+	}
+
+	// Test unsupported and missing files
+	response.Reset()
+	controller.Run(response, uploadRequest("page.png", []byte("<html></html>")))
+	if response.Headers.Get("Location") != "/media" || !flashed(response, app.FlashError) {
+		t.Error("Expected type error for non-image upload")
+	}
+	if !strings.Contains(flash.Left(response)[0].Text, "Only GIF, JPEG, PNG and WebP") {
+		t.Error("Expected unsupported type error to be explained")
+	}
+	response.Reset()
+	controller.Run(response, uploadRequest("", nil))
+	if response.Headers.Get("Location") != "/media" || !flashed(response, app.FlashError) {
+		t.Error("Expected upload error when no file is sent")
+	}
+
+	// Test JSON response
+	response.Reset()
+	request := uploadRequest("photo.png", testImage)
+	request.Header.Set("Accept", "application/json")
+	controller.Run(response, request)
+	if response.StatusCode != 201 || !strings.Contains(response.Content, `"url":"/media/photo-1.png"`) || !strings.Contains(response.Content, `"markdown":"![photo-1](/media/photo-1.png)"`) {
+		t.Errorf("Expected JSON description of the upload, got %s", response.Content)
+	}
+	response.Reset()
+	request = uploadRequest("page.png", []byte("<html></html>"))
+	request.Header.Set("Accept", "application/json")
+	controller.Run(response, request)
+	if response.StatusCode != 415 {
+		t.Error("Expected 415 for unsupported JSON upload")
+	}
+}
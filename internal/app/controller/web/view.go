@@ -1,40 +1,127 @@
 package web
 
 import (
+	"bytes"
+	"html/template"
 	"net/http"
-	"text/template"
+	"net/url"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/conditional"
 	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
+// relatedEntries How many related entries are suggested beneath an entry
+const relatedEntries = 3
+
 // View Handle displaying individual entry
 type View struct {
 	controller.Super
-	Journal model.Journal
-	Next    model.Journal
-	Prev    model.Journal
+	Attachments  []model.Attachment
+	Backlinks    []model.Journal
+	Category     model.Category
+	CategoryPath []model.Category
+	Comments     []model.Comment
+	Content      template.HTML
+	Image        string
+	Journal      model.Journal
+	Next         model.Journal
+	OEmbed       string
+	Prev         model.Journal
+	Related      []model.Journal
+	URL          string
 }
 
 // Run View action
 func (c *View) Run(response http.ResponseWriter, request *http.Request) {
 
 	js := model.Journals{Container: c.Super.Container.(*app.Container), Gs: model.GiphyAdapter(c.Super.Container.(*app.Container))}
-	c.Journal = js.FindBySlug(c.Params[1])
+	store := model.Store(c.Super.Container.(*app.Container))
+	c.Journal = store.GetBySlug(c.Params[1])
 
-	if c.Journal.ID == 0 {
+	// Drafts and entries waiting to be published are only seen by those who may edit them, as if they were not there
+	if c.Journal.ID == 0 || (!c.Journal.IsPublished() && !c.Journal.CanEdit(auth.CurrentUser(request, c.Super.Container.(*app.Container)))) {
 		errorController := BadRequest{}
 		errorController.Init(c.Super.Container, []string{})
 		errorController.Run(response, request)
+	} else if c.Journal.IsPrivate() && !auth.Authenticated(request, c.Super.Container.(*app.Container)) {
+		RunUnauthorised(response, request, c.Super.Container)
+	} else if !isUnlocked(request, c.Journal) && !auth.Authenticated(request, c.Super.Container.(*app.Container)) {
+		template, err := c.Super.Container.(*app.Container).Templates("_layout/default.tmpl", "unlock.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
+		response.WriteHeader(http.StatusForbidden)
+		template.ExecuteTemplate(response, "layout", c)
 	} else {
+		ls := model.JournalLinks{Container: c.Super.Container.(*app.Container)}
+		c.Journal = ls.Load(c.Journal)
+		ms := model.JournalMetas{Container: c.Super.Container.(*app.Container)}
+		c.Journal = ms.Load(c.Journal)
+		ts := model.JournalTags{Container: c.Super.Container.(*app.Container)}
+		c.Journal = ts.Load(c.Journal)
+		us := model.Users{Container: c.Super.Container.(*app.Container)}
+		c.Journal = us.LoadAuthor(c.Journal)
 		c.Next = js.FindNext(c.Journal.ID)
 		c.Prev = js.FindPrev(c.Journal.ID)
+		c.Category = model.Category{}
+		c.CategoryPath = []model.Category{}
+		if c.Journal.CategoryID > 0 {
+			cats := model.Categories{Container: c.Super.Container.(*app.Container)}
+			c.Category = cats.FindByID(c.Journal.CategoryID)
+			if c.Category.ID > 0 {
+				c.CategoryPath = cats.Ancestors(c.Category)
+			}
+		}
+		cs := model.Comments{Container: c.Super.Container.(*app.Container)}
+		c.Comments = cs.FetchApproved(c.Journal.ID)
+		c.Related = js.FetchRelated(c.Journal, relatedEntries)
+		c.Backlinks = js.FetchBacklinks(c.Journal)
 		gs := model.Giphys{}
-		c.Journal.Content = gs.ConvertIDsToIframes(c.Journal.Content)
-		template, _ := template.ParseFiles(
-			"./web/templates/_layout/default.tmpl",
-			"./web/templates/view.tmpl")
-		template.ExecuteTemplate(response, "layout", c)
+		ss := model.Shortcodes{Container: c.Super.Container.(*app.Container)}
+		c.Content = template.HTML(gs.ConvertIDsToIframes(ss.Replace(js.LinkWikiLinks(c.Journal.GetHTML()))))
+		as := model.Attachments{Container: c.Super.Container.(*app.Container)}
+		c.Attachments = as.FetchByJournal(c.Journal.ID)
+		c.URL = c.Journal.CanonicalURL
+		if c.URL == "" {
+			c.URL = absoluteURL(c.Super.Container.(*app.Container), request, "/"+c.Journal.Slug)
+		}
+		c.Image = previewImage(c.URL, c.Journal.GetImage())
+		c.OEmbed = ""
+		if embeddable(c.Journal) {
+			own := absoluteURL(c.Super.Container.(*app.Container), request, "/"+c.Journal.Slug)
+			c.OEmbed = absoluteURL(c.Super.Container.(*app.Container), request, "/oembed?url="+url.QueryEscape(own))
+		}
+		template, err := c.Super.Container.(*app.Container).Templates("_layout/default.tmpl", "view.tmpl")
+		if err != nil {
+			RunServerError(response, request, c.Super.Container, err)
+			return
+		}
+		var page bytes.Buffer
+		template.ExecuteTemplate(&page, "layout", c)
+
+		// Answer readers already holding the page, as it was when the entry last changed, without sending it again
+		rs := model.JournalRevisions{Container: c.Super.Container.(*app.Container)}
+		conditional.Serve(response, request, page.Bytes(), rs.LastModified(c.Journal))
+	}
+}
+
+// previewImage Make the address of an image shown in an entry absolute, so that link previews can load it
+func previewImage(page string, image string) string {
+	if image == "" {
+		return ""
+	}
+	base, err := url.Parse(page)
+	if err != nil {
+		return ""
 	}
+	resolved, err := base.Parse(image)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+
+	return resolved.String()
 }
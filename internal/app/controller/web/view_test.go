@@ -2,11 +2,11 @@ package web
 
 import (
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
 	"github.com/jamiefdhurst/journal/test/mocks/controller"
 	"github.com/jamiefdhurst/journal/test/mocks/database"
 )
@@ -16,7 +16,6 @@ func TestView_Run(t *testing.T) {
 	container := &app.Container{Db: db}
 	response := controller.NewMockResponse()
 	controller := &View{}
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 
 	// Test not found/error with GET/POST
 	controller.Init(container, []string{"", "0"})
@@ -41,10 +40,339 @@ func TestView_Run(t *testing.T) {
 	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
 	db.EnableMultiMode()
 	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
 	db.AppendResult(&database.MockJournal_SingleRow{})
 	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
 	controller.Run(response, request)
 	if !strings.Contains(response.Content, ">Previous<") || !strings.Contains(response.Content, ">Next<") {
 		t.Error("Expected previous and next links to be shown in page")
 	}
+
+	// Display canonical and syndication links
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournalLink_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<link rel=\"canonical\" href=\"https://example.com/original\"") || !strings.Contains(response.Content, "class=\"u-syndication\" rel=\"syndication\" href=\"https://mastodon.example/@jamie/1\"") {
+		t.Error("Expected canonical and syndication links to be shown in page")
+	}
+	if !strings.Contains(response.Content, `<meta property="og:url" content="https://example.com/original" />`) || strings.Contains(response.Content, "og:image") || !strings.Contains(response.Content, `<meta name="twitter:card" content="summary" />`) {
+		t.Error("Expected link previews to point to the canonical link without an image")
+	}
+
+	// Display link previews with the first image made absolute
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	request.Host = "journal.local"
+	db.AppendResult(&database.MockJournal_SingleRow{Content: "Fish &amp; \"chips\" by the sea\n\n![Beach](/media/beach.jpg)"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	expected := []string{
+		`<meta property="og:title" content="Title" />`,
+		`<meta property="og:description" content="Fish &amp; &#34;chips&#34; by the sea" />`,
+		`<meta property="og:url" content="http://journal.local/slug" />`,
+		`<meta property="og:image" content="http://journal.local/media/beach.jpg" />`,
+		`<meta name="twitter:card" content="summary_large_image" />`,
+		`<meta name="twitter:image" content="http://journal.local/media/beach.jpg" />`,
+		`<link rel="alternate" type="application/json+oembed" href="http://journal.local/oembed?url=http%3A%2F%2Fjournal.local%2Fslug" title="Title" />`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(response.Content, e) {
+			t.Errorf("Expected page to contain %s", e)
+		}
+	}
+
+	// Display approved comments escaped, with the form
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockComment_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "2 comments") || !strings.Contains(response.Content, `href="https://reader.example.com" rel="nofollow ugc">Reader</a>`) {
+		t.Error("Expected approved comments to be shown in page")
+	}
+	if strings.Contains(response.Content, "<script>") || !strings.Contains(response.Content, "&lt;b&gt;Another&lt;/b&gt;") {
+		t.Error("Expected comments to be escaped")
+	}
+	if !strings.Contains(response.Content, `action="/slug/comments"`) {
+		t.Error("Expected comment form")
+	}
+
+	// Closed comments hide the form
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	db.AppendResult(&database.MockJournal_SingleRow{Comments: "closed"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if strings.Contains(response.Content, "comment-form") {
+		t.Error("Expected comment form to be hidden when comments are closed")
+	}
+
+	// Category is linked beneath its parents
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{CategoryID: 1})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockCategory_SingleRow{ParentID: 3})
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `Filed under <a href="/category/cooking">Cooking</a> &rsaquo; <a class="p-category" href="/category/travel">Travel</a>`) {
+		t.Error("Expected category and its parents to be linked")
+	}
+
+	// Related entries are suggested at the bottom
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "Related entries") || !strings.Contains(response.Content, `<a href="/slug-2">Title 2</a>`) {
+		t.Error("Expected related entries to be shown in page")
+	}
+
+	// Drafts and scheduled entries are not found by those who may not edit them
+	for _, status := range []string{model.JournalStatusDraft, model.JournalStatusScheduled} {
+		response.Reset()
+		request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+		db.AppendResult(&database.MockJournal_SingleRow{Status: status})
+		controller.Run(response, request)
+		if response.StatusCode != 404 || strings.Contains(response.Content, "Content") {
+			t.Errorf("Expected %s entry not to be found, got %d", status, response.StatusCode)
+		}
+	}
+
+	// Private entries ask for credentials
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{Visibility: "private"})
+	controller.Run(response, request)
+	if response.StatusCode != 401 || response.Headers.Get("WWW-Authenticate") == "" || strings.Contains(response.Content, "Content") {
+		t.Error("Expected private entry to require authentication")
+	}
+
+	// Private entries are shown once authenticated, kept out of search engines
+	response.Reset()
+	container.Configuration.AdminToken = "secret"
+	request.Header.Set("Authorization", "Bearer secret")
+	db.AppendResult(&database.MockJournal_SingleRow{Visibility: "private"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "This entry is private") || !strings.Contains(response.Content, `<meta name="robots" content="noindex" />`) {
+		t.Error("Expected private entry to be shown when authenticated")
+	}
+
+	// Protected entries ask for their password, then show once unlocked
+	protected := model.Journal{ID: 1}
+	protected.SetPassword("secret")
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	db.AppendResult(&database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash})
+	controller.Run(response, request)
+	if response.StatusCode != 403 || !strings.Contains(response.Content, `action="/slug/unlock"`) || strings.Contains(response.Content, "Content") {
+		t.Error("Expected protected entry to ask for its password")
+	}
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	request.AddCookie(&http.Cookie{Name: "journal_unlock_1", Value: protected.UnlockToken()})
+	db.AppendResult(&database.MockJournal_SingleRow{PasswordHash: protected.PasswordHash})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if response.StatusCode != 200 || !strings.Contains(response.Content, "Content") {
+		t.Error("Expected protected entry to be shown once unlocked")
+	}
+
+	// Word count and reading time are shown with the date
+	response.Reset()
+	request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+	db.AppendResult(&database.MockJournal_SingleRow{WordCount: 450, ReadingTime: 3})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "450 words &middot; 3 min read") {
+		t.Error("Expected word count and reading time to be shown in page")
+	}
+
+	// Wiki links are resolved and entries linking here are listed
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{Content: "See [[slug]]"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockJournal_SingleRow{Content: "Links to [[Title]]"})
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<a class="wikilink" href="/slug">Title</a>`) {
+		t.Error("Expected wiki link to be resolved in page")
+	}
+	if !strings.Contains(response.Content, "Linked from") {
+		t.Error("Expected backlinks to be shown in page")
+	}
+
+	// Custom fields are listed escaped
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockJournalMeta_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, "<dt>mood</dt><dd>Happy</dd><dt>weather</dt><dd>Sunny</dd>") {
+		t.Error("Expected custom fields to be shown in page")
+	}
+
+	// Tags are linked to the entries given them
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockJournalTag_MultipleRows{})
+	for i := 0; i < 7; i++ {
+		db.AppendResult(&database.MockRowsEmpty{})
+	}
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<a class="p-category" rel="tag" href="/tag/road-trip">Road Trip</a>`) {
+		t.Error("Expected tags to be linked in page")
+	}
+
+	// Attachments are listed with download links
+	response.Reset()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockAttachment_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	controller.Run(response, request)
+	if !strings.Contains(response.Content, `<a href="/slug/attachments/1" download>Report &lt;1&gt;.pdf</a> <span>2.0 MB</span>`) || !strings.Contains(response.Content, "notes.txt") {
+		t.Error("Expected attachments to be listed in page")
+	}
+
+	etag := ""
+
+	// Pages are tagged and dated, and not sent again to readers holding them
+	for _, header := range []string{"", "If-None-Match", "If-Modified-Since"} {
+		response.Reset()
+		request, _ = http.NewRequest("GET", "/slug", strings.NewReader(""))
+		if header == "If-None-Match" {
+			request.Header.Set(header, etag)
+		} else if header == "If-Modified-Since" {
+			request.Header.Set(header, "Fri, 02 Feb 2018 10:00:00 GMT")
+		}
+		db.AppendResult(&database.MockJournal_SingleRow{})
+		for i := 0; i < 9; i++ {
+			db.AppendResult(&database.MockRowsEmpty{})
+		}
+		db.AppendResult(&database.MockJournalRevision_LastModified{CreatedAt: "2018-02-02 10:00:00"})
+		controller.Run(response, request)
+		if header == "" {
+			etag = response.Headers.Get("ETag")
+			if response.StatusCode != 200 || etag == "" || response.Headers.Get("Last-Modified") != "Fri, 02 Feb 2018 10:00:00 GMT" {
+				t.Errorf("Expected page to be tagged and dated by its latest revision, got %v", response.Headers)
+			}
+		} else if response.StatusCode != 304 || response.Content != "" {
+			t.Errorf("Expected page not to be sent again with %s, got %d", header, response.StatusCode)
+		}
+	}
 }
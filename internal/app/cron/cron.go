@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+// Task Work run in the background each time its interval passes, for as long as it is enabled, such as backing up.
+// Its name is also the type of the job that runs it straight away from the queue.
+type Task struct {
+	Name     string
+	Enabled  func(container *app.Container) bool
+	Interval func(container *app.Container) time.Duration
+	Run      func(container *app.Container) error
+}
+
+// Scheduler Runs each task as it falls due, keeping when each last ran and is next due in the database so that a
+// restart neither runs them early nor forgets them
+type Scheduler struct {
+	Container *app.Container
+	Now       func() time.Time
+	Tick      time.Duration
+	tasks     []Task
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewScheduler Create a scheduler for the given container, checking for tasks due each minute
+func NewScheduler(container *app.Container) *Scheduler {
+	return &Scheduler{
+		Container: container,
+		Now:       time.Now,
+		Tick:      time.Minute,
+	}
+}
+
+// Add Add a task to be run
+func (s *Scheduler) Add(task Task) {
+	s.tasks = append(s.tasks, task)
+}
+
+// Sync Save the interval of each enabled task, due at once when it has never run or otherwise an interval after it last
+// did, and forget every other task
+func (s *Scheduler) Sync() error {
+	ts := model.ScheduledTasks{Container: s.Container}
+	enabled := map[string]bool{}
+	for _, task := range s.tasks {
+		if !task.Enabled(s.Container) {
+			continue
+		}
+		enabled[task.Name] = true
+		if err := ts.Plan(task.Name, task.Interval(s.Container), s.Now()); err != nil {
+			return err
+		}
+	}
+	for _, saved := range ts.FetchAll() {
+		if !enabled[saved.Name] {
+			if err := ts.Delete(saved.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunDue Run every enabled task that is due, giving how many were run
+func (s *Scheduler) RunDue() int {
+	ts := model.ScheduledTasks{Container: s.Container}
+	ran := 0
+	for _, task := range s.tasks {
+		if !task.Enabled(s.Container) {
+			continue
+		}
+		now := s.Now()
+		saved := ts.FindByName(task.Name)
+		if saved.Name == "" || !saved.IsDue(now) {
+			continue
+		}
+		saved, claimed := ts.Claim(saved, now)
+		if !claimed {
+			continue
+		}
+
+		// Everything the task logs carries its name
+		container := *s.Container
+		container.Logger = s.Container.Log().With("task", task.Name)
+		err := task.Run(&container)
+		if err != nil {
+			container.Log().Warn("Scheduled task failed", "err", err)
+		} else {
+			container.Log().Debug("Ran scheduled task", "duration_ms", float64(s.Now().Sub(now).Microseconds())/1000)
+		}
+		ts.Ran(saved, now, err)
+		ran++
+	}
+
+	return ran
+}
+
+// Start Save the tasks and run them as they fall due until stopped
+func (s *Scheduler) Start() error {
+	if err := s.Sync(); err != nil {
+		return err
+	}
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.RunDue()
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(s.Tick):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop Stop running tasks, waiting for any being run
+func (s *Scheduler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+	s.stop = nil
+}
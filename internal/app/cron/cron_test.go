@@ -0,0 +1,104 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+// task A task counting its runs, enabled as given
+func task(name string, enabled bool, runs *int, err error) Task {
+	return Task{
+		Name:     name,
+		Enabled:  func(*app.Container) bool { return enabled },
+		Interval: func(*app.Container) time.Duration { return time.Hour },
+		Run: func(container *app.Container) error {
+			if container.Logger == nil {
+				return errors.New("Expected a logger for the task")
+			}
+			*runs++
+			return err
+		},
+	}
+}
+
+func TestScheduler_Sync(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	scheduler := NewScheduler(&app.Container{Db: db})
+	runs := 0
+	scheduler.Add(task("publish", true, &runs, nil))
+	scheduler.Add(task("backup", false, &runs, nil))
+
+	// Enabled tasks are planned and every other one forgotten
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockScheduledTask_MultipleRows{})
+	if err := scheduler.Sync(); err != nil || db.Queries != 4 || runs != 0 {
+		t.Errorf("Expected enabled task to be planned and disabled one deleted, got %d queries", db.Queries)
+	}
+
+	db.ErrorMode = true
+	if err := scheduler.Sync(); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	scheduler := NewScheduler(&app.Container{Db: db})
+	scheduler.Now = func() time.Time { return time.Date(2018, 2, 1, 0, 0, 30, 0, time.UTC) }
+	runs, disabledRuns := 0, 0
+	scheduler.Add(task("backup", true, &runs, errors.New("Simulated failure")))
+	scheduler.Add(task("purge", false, &disabledRuns, nil))
+
+	// Due tasks are claimed, run and recorded, even when they fail
+	db.Rows = &database.MockScheduledTask_SingleRow{}
+	if ran := scheduler.RunDue(); ran != 1 || runs != 1 || disabledRuns != 0 || db.Queries != 3 {
+		t.Errorf("Expected due task to be run, got %d queries", db.Queries)
+	}
+
+	// Tasks not yet due, never planned or claimed by another process are left
+	db.Rows = &database.MockScheduledTask_SingleRow{NextRunAt: "2018-02-01 01:00:00"}
+	if ran := scheduler.RunDue(); ran != 0 || runs != 1 {
+		t.Error("Expected task not yet due to be left")
+	}
+	db.Rows = &database.MockRowsEmpty{}
+	if ran := scheduler.RunDue(); ran != 0 || runs != 1 {
+		t.Error("Expected task never planned to be left")
+	}
+	db.Rows = &database.MockScheduledTask_SingleRow{}
+	db.Result = &database.MockResult{Affected: 0}
+	if ran := scheduler.RunDue(); ran != 0 || runs != 1 {
+		t.Error("Expected task claimed elsewhere to be left")
+	}
+}
+
+func TestScheduler_Start(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockScheduledTask_SingleRow{})
+	scheduler := NewScheduler(&app.Container{Db: db})
+	scheduler.Tick = time.Hour
+	runs := 0
+	scheduler.Add(task("backup", true, &runs, nil))
+
+	scheduler.Stop()
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Expected scheduler to start, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	scheduler.Stop()
+	if runs != 1 {
+		t.Error("Expected due task to have been run as the scheduler started")
+	}
+
+	db.ErrorMode = true
+	if err := scheduler.Start(); err == nil {
+		t.Error("Expected error when tasks cannot be planned")
+	}
+}
@@ -0,0 +1,130 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/smtpd"
+)
+
+// Errors refusing a message, sent back to the client that delivered it
+var (
+	ErrEmpty        = errors.New("The message has no content")
+	ErrMaintenance  = errors.New("The journal is in maintenance")
+	ErrNoSubject    = errors.New("A subject is needed for the title of the entry")
+	ErrQuota        = errors.New("The journal has no room for more entries")
+	ErrUnauthorized = errors.New("The sender is not allowed to post")
+)
+
+// Enabled Whether a port to receive mail on and the addresses allowed to post have both been configured
+func Enabled(container *app.Container) bool {
+	return container.Configuration.MailPort != "" && container.Configuration.MailFrom != ""
+}
+
+// Authorized Check whether an address is one of those allowed to post, ignoring case
+func Authorized(container *app.Container, address string) bool {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return false
+	}
+	for _, allowed := range strings.Split(container.Configuration.MailFrom, ",") {
+		if strings.EqualFold(strings.TrimSpace(allowed), address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Post Save a message as a draft entry, titled by its subject, embedding the images it carries from the media library
+// and attaching any other files
+func Post(container *app.Container, message smtpd.Message) (model.Journal, error) {
+	if message.Subject == "" {
+		return model.Journal{}, ErrNoSubject
+	}
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	if js.QuotaReached() {
+		return model.Journal{}, ErrQuota
+	}
+
+	content := message.Text
+	if content == "" {
+		content = message.HTML
+	}
+	files := []smtpd.File{}
+	for _, f := range message.Attachments {
+		image, err := media.Store(container, f.Name, bytes.NewReader(f.Data))
+		switch err {
+		case nil:
+			content = strings.TrimSpace(content + "\n\n" + image.Markdown())
+		case media.ErrUnsupported:
+			files = append(files, f)
+		default:
+			container.Log().Warn("Could not save a file from an email to the media library", "name", f.Name, "err", err)
+		}
+	}
+	if content == "" && len(files) == 0 {
+		return model.Journal{}, ErrEmpty
+	}
+
+	j, err := model.Store(container).CreateJournal(model.Journal{Title: message.Subject, Date: time.Now().Format("2006-01-02"), Content: content, Status: model.JournalStatusDraft})
+	if err != nil {
+		return j, err
+	}
+	webhook.Created(container, j)
+	as := model.Attachments{Container: container}
+	for _, f := range files {
+		stored, size, contentType, err := media.StoreAttachment(container, f.Name, bytes.NewReader(f.Data))
+		if err != nil {
+			container.Log().Warn("Could not attach a file from an email", "name", f.Name, "err", err)
+			continue
+		}
+		name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(f.Name, "\\", "/")))
+		if name == "" || name == "." || name == "/" {
+			name = "attachment"
+		}
+		if len(name) > 255 {
+			name = name[:255]
+		}
+		if _, err := as.Save(model.Attachment{JournalID: j.ID, Name: name, File: stored, ContentType: contentType, Size: int(size)}); err != nil {
+			media.RemoveAttachment(container, stored)
+			return j, err
+		}
+	}
+
+	return j, nil
+}
+
+// Handler Build the handler for messages received over SMTP, posting those from an allowed sender as drafts, and
+// deferring them while the journal is in maintenance so they are delivered again later
+func Handler(container *app.Container) func(smtpd.Envelope) error {
+	return func(envelope smtpd.Envelope) error {
+		message, err := smtpd.ParseMessage(envelope.Data)
+		if err != nil {
+			return err
+		}
+		if !Authorized(container, message.From) {
+			container.Log().Warn("Refused an email from a sender not allowed to post", "from", message.From)
+			return ErrUnauthorized
+		}
+		if container.Maintenance.On() {
+			container.Log().Info("Deferred an email while in maintenance", "from", message.From)
+			return smtpd.Defer(ErrMaintenance)
+		}
+
+		j, err := Post(container, message)
+		if err != nil {
+			return err
+		}
+		container.Log().Info("Created draft from an email", "slug", j.Slug, "from", message.From)
+
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package email
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/pkg/smtpd"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func testContainer(t *testing.T) (*app.Container, *database.MockSqlite) {
+	dir, err := ioutil.TempDir("", "email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	configuration := app.DefaultConfiguration()
+	configuration.MediaPath = dir
+	configuration.MailFrom = "me@example.com, Other@Example.com"
+
+	return &app.Container{Configuration: configuration, Db: db}, db
+}
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if Enabled(container) {
+		t.Error("Expected email to be disabled by default")
+	}
+	container.Configuration.MailPort = "2525"
+	if Enabled(container) {
+		t.Error("Expected email to be disabled without any allowed senders")
+	}
+	container.Configuration.MailFrom = "me@example.com"
+	if !Enabled(container) {
+		t.Error("Expected email to be enabled")
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	container, _ := testContainer(t)
+	tables := []struct {
+		address string
+		output  bool
+	}{
+		{"me@example.com", true},
+		{"other@example.com", true},
+		{"someone@example.com", false},
+		{"", false},
+	}
+
+	for _, table := range tables {
+		if Authorized(container, table.address) != table.output {
+			t.Errorf("Expected Authorized(%s) to be %t", table.address, table.output)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	container, db := testContainer(t)
+
+	j, err := Post(container, smtpd.Message{Subject: "Trip", Text: "Went *away*", Attachments: []smtpd.File{
+		{Name: "View.png", ContentType: "image/png", Data: png},
+		{Name: "../tickets.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if j.Title != "Trip" || !j.IsDraft() || j.Content != "Went *away*\n\n![view](/media/view.png)" {
+		t.Errorf("Expected draft with the image embedded, got %v", j)
+	}
+	if _, ok := media.Find(container, "view.png"); !ok {
+		t.Error("Expected image to be saved to the media library")
+	}
+	files, _ := ioutil.ReadDir(filepath.Join(container.MediaPath(), media.AttachmentDir))
+	if len(files) != 1 || db.Queries != 4 {
+		t.Errorf("Expected other file to be attached, got %d file(s) and %d queries", len(files), db.Queries)
+	}
+
+	// Test HTML used when there is no text
+	j, err = Post(container, smtpd.Message{Subject: "Trip", HTML: "<p>Away</p>"})
+	if err != nil || j.Content != "<p>Away</p>" {
+		t.Errorf("Expected HTML content, got %v, %v", j, err)
+	}
+
+	if _, err := Post(container, smtpd.Message{Text: "Away"}); err != ErrNoSubject {
+		t.Errorf("Expected error without a subject, got %v", err)
+	}
+	if _, err := Post(container, smtpd.Message{Subject: "Trip"}); err != ErrEmpty {
+		t.Errorf("Expected error without content, got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	container, db := testContainer(t)
+	handler := Handler(container)
+
+	if err := handler(smtpd.Envelope{Data: []byte("From: someone@example.com\r\nSubject: Hi\r\n\r\nHello\r\n")}); err != ErrUnauthorized || db.Queries != 0 {
+		t.Errorf("Expected message from another sender to be refused, got %v", err)
+	}
+	if err := handler(smtpd.Envelope{Data: []byte("From: Me <ME@example.com>\r\nSubject: Hi\r\n\r\nHello\r\n")}); err != nil || db.Queries != 3 {
+		t.Errorf("Expected message to be posted, got %v", err)
+	}
+	if err := handler(smtpd.Envelope{Data: []byte("not a message")}); err == nil || strings.Contains(err.Error(), "sender") {
+		t.Errorf("Expected invalid message to be refused, got %v", err)
+	}
+
+	// Test messages are deferred while in maintenance
+	container.Maintenance = app.NewMaintenance(true)
+	db.Queries = 0
+	if err := handler(smtpd.Envelope{Data: []byte("From: me@example.com\r\nSubject: Hi\r\n\r\nHello\r\n")}); err == nil || err.Error() != ErrMaintenance.Error() || db.Queries != 0 {
+		t.Errorf("Expected message to be deferred while in maintenance, got %v", err)
+	}
+}
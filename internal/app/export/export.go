@@ -0,0 +1,164 @@
+package export
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+var pageLink = regexp.MustCompile(`href="([^"?]*)\?page=(\d+)"`)
+
+// Static Render every published entry, the index, category archives, feeds, media and attachments into a directory
+// of plain files, through the same routes that serve them, so it can be hosted on any web server. Creating and
+// editing are switched off for the container to keep their controls out of the pages. Returns the number of files
+// written.
+func Static(container *app.Container, handler http.Handler, dir string) (int, error) {
+	container.Configuration.EnableCreate = false
+	container.Configuration.EnableEdit = false
+	s := site{container: container, handler: handler, dir: dir}
+
+	if err := s.copyStatic(); err != nil {
+		return s.written, err
+	}
+
+	js := model.Journals{Container: container}
+	journals := js.FetchAll()
+	if err := s.paginated("/", pages(len(journals), container.Configuration.ArticlesPerPage)); err != nil {
+		return s.written, err
+	}
+	for _, feed := range []string{"/feed.atom", "/feed.json", "/feed.rss"} {
+		if err := s.render(feed, feed); err != nil {
+			return s.written, err
+		}
+	}
+
+	as := model.Attachments{Container: container}
+	for _, j := range journals {
+		if err := s.render("/"+j.Slug, "/"+j.Slug+"/index.html"); err != nil {
+			return s.written, err
+		}
+		for _, a := range as.FetchByJournal(j.ID) {
+			path := "/" + j.Slug + "/attachments/" + strconv.Itoa(a.ID)
+			if err := s.render(path, path); err != nil {
+				return s.written, err
+			}
+		}
+	}
+
+	cs := model.Categories{Container: container}
+	for _, c := range cs.FetchAll() {
+		_, pagination := js.FetchPaginatedByCategory(cs.Descendants(c), database.PaginationQuery{Page: 1, ResultsPerPage: container.Configuration.ArticlesPerPage})
+		if err := s.paginated("/category/"+c.Slug, pagination.TotalPages); err != nil {
+			return s.written, err
+		}
+	}
+
+	for _, f := range media.List(container) {
+		path := media.Path + "/" + f.Name
+		if err := s.render(path, path); err != nil {
+			return s.written, err
+		}
+	}
+
+	return s.written, nil
+}
+
+// pages How many pages a list of entries is split over, there always being at least one
+func pages(total int, perPage int) int {
+	if perPage <= 0 || total <= perPage {
+		return 1
+	}
+
+	return (total + perPage - 1) / perPage
+}
+
+// site The directory being exported to, counting the files written into it
+type site struct {
+	container *app.Container
+	handler   http.Handler
+	dir       string
+	written   int
+}
+
+// paginated Render a list of entries as its index page, followed by every page of it beneath page/
+func (s *site) paginated(path string, total int) error {
+	base := strings.TrimSuffix(path, "/")
+	if err := s.render(path, base+"/index.html"); err != nil {
+		return err
+	}
+	for page := 1; page <= total; page++ {
+		if err := s.render(path+"?page="+strconv.Itoa(page), base+"/page/"+strconv.Itoa(page)+"/index.html"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render Request a path from the handler and write what it responds with into a file, pointing links to other pages
+// of a list at the files they are exported to
+func (s *site) render(path string, file string) error {
+	request := httptest.NewRequest("GET", path, nil)
+	request.Host = "localhost"
+	if u, err := url.Parse(s.container.Configuration.URL); err == nil && u.Host != "" {
+		request.Host = u.Host
+	}
+	response := httptest.NewRecorder()
+	s.handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		return errors.New("Could not export " + path + ", got status " + strconv.Itoa(response.Code))
+	}
+
+	body := response.Body.Bytes()
+	if strings.HasSuffix(file, ".html") {
+		body = pageLink.ReplaceAllFunc(body, func(link []byte) []byte {
+			parts := pageLink.FindSubmatch(link)
+			return []byte(`href="` + strings.TrimSuffix(string(parts[1]), "/") + `/page/` + string(parts[2]) + `/"`)
+		})
+	}
+
+	return s.write(file, body)
+}
+
+func (s *site) write(file string, body []byte) error {
+	destination := filepath.Join(s.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(destination, body, 0644); err != nil {
+		return err
+	}
+	s.written++
+
+	return nil
+}
+
+// copyStatic Copy the stylesheets and scripts the pages load beneath static/, where they are linked from
+func (s *site) copyStatic() error {
+	files := s.container.Configuration.Files()
+
+	return fs.WalkDir(files, "static", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		body, err := fs.ReadFile(files, path)
+		if err != nil {
+			return err
+		}
+
+		return s.write(path, body)
+	})
+}
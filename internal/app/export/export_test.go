@@ -0,0 +1,79 @@
+package export
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestPages(t *testing.T) {
+	tables := []struct {
+		total   int
+		perPage int
+		output  int
+	}{
+		{0, 20, 1},
+		{20, 20, 1},
+		{21, 20, 2},
+		{60, 20, 3},
+		{5, 0, 1},
+	}
+
+	for _, table := range tables {
+		if actual := pages(table.total, table.perPage); actual != table.output {
+			t.Errorf("Expected pages(%d, %d) to be %d, got %d", table.total, table.perPage, table.output, actual)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockRowsEmpty{}
+	configuration := app.DefaultConfiguration()
+	configuration.MediaPath, _ = ioutil.TempDir("", "media")
+	defer os.RemoveAll(configuration.MediaPath)
+	container := &app.Container{Configuration: configuration, Db: db}
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	requested := []string{}
+	handler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		requested = append(requested, request.URL.String())
+		response.Write([]byte(`<a href="/?page=2">2</a> <a href="/search?q=a&amp;page=2">2</a>`))
+	})
+
+	written, err := Static(container, handler, dir)
+	if err != nil || written != 7 || len(requested) != 5 {
+		t.Fatalf("Expected the stylesheet, script, index, one page and three feeds to be exported, got %d file(s), %v", written, requested)
+	}
+	if container.Configuration.EnableCreate || container.Configuration.EnableEdit {
+		t.Error("Expected creating and editing to be switched off")
+	}
+	for _, file := range []string{"static/css/default.min.css", "static/js/default.min.js", "index.html", "page/1/index.html", "feed.atom", "feed.json", "feed.rss"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Expected %s to be written", file)
+		}
+	}
+	index, _ := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if !strings.Contains(string(index), `<a href="/page/2/">2</a>`) || !strings.Contains(string(index), `/search?q=a&amp;page=2`) {
+		t.Errorf("Expected links to pages of the index to point to their files, got %s", index)
+	}
+	feed, _ := ioutil.ReadFile(filepath.Join(dir, "feed.atom"))
+	if !strings.Contains(string(feed), "/?page=2") {
+		t.Error("Expected feeds to be written as they were served")
+	}
+
+	// Failing pages stop the export
+	handler = http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusInternalServerError)
+	})
+	if _, err := Static(container, handler, dir); err == nil || !strings.Contains(err.Error(), "Could not export /") {
+		t.Errorf("Expected error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/frontmatter"
+)
+
+// markdownBatch How many entries are read at a time
+const markdownBatch = 100
+
+// Markdown Write every entry outside the trash into a directory as a Markdown file named like 2020-01-02-slug.md,
+// with front matter that Jekyll and Hugo both understand. Its tags are listed as its tags and its category, after the
+// categories it is nested beneath, as its categories, and anything unpublished is marked as a draft. Returns the
+// number of files written.
+func Markdown(container *app.Container, dir string) (int, error) {
+	s := site{container: container, dir: dir}
+	js := model.Journals{Container: container}
+	cs := model.Categories{Container: container}
+	ts := model.JournalTags{Container: container}
+	categories := map[int]model.Category{}
+	for _, c := range cs.FetchAll() {
+		categories[c.ID] = c
+	}
+
+	query := database.PaginationQuery{Page: 1, ResultsPerPage: markdownBatch}
+	for {
+		journals, pagination := js.FetchPaginatedAll(query)
+		for _, j := range ts.LoadAll(journals) {
+			if err := s.write(j.GetEditableDate()+"-"+j.Slug+".md", []byte(markdownEntry(j, categories))); err != nil {
+				return s.written, err
+			}
+		}
+		if query.Page >= pagination.TotalPages {
+			break
+		}
+		query.Page++
+	}
+
+	return s.written, nil
+}
+
+// markdownEntry Write an entry as front matter followed by the Markdown it was written in
+func markdownEntry(j model.Journal, categories map[int]model.Category) string {
+	fields := []frontmatter.Field{
+		{Key: "title", Value: j.Title},
+		{Key: "date", Value: j.GetEditableDate()},
+		{Key: "slug", Value: j.Slug},
+	}
+	if len(j.Tags) > 0 {
+		fields = append(fields, frontmatter.Field{Key: "tags", Value: j.Tags})
+	}
+	if category, ok := categories[j.CategoryID]; ok {
+		path := []string{category.Name}
+		seen := map[int]bool{category.ID: true}
+		for parent, ok := categories[category.ParentID]; ok && !seen[parent.ID]; parent, ok = categories[parent.ParentID] {
+			seen[parent.ID] = true
+			path = append([]string{parent.Name}, path...)
+		}
+		fields = append(fields, frontmatter.Field{Key: "categories", Value: path})
+	}
+	if j.Excerpt != "" {
+		fields = append(fields, frontmatter.Field{Key: "excerpt", Value: j.Excerpt})
+	}
+	if !j.IsPublished() {
+		fields = append(fields, frontmatter.Field{Key: "draft", Value: true})
+	}
+
+	return frontmatter.Format(fields, j.Content)
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestMarkdown(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockJournalTagged_MultipleRows{})
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	written, err := Markdown(container, dir)
+	if err != nil || written != 2 {
+		t.Fatalf("Expected every entry to be written, got %d file(s), %v", written, err)
+	}
+	output, _ := ioutil.ReadFile(filepath.Join(dir, "2018-03-01-slug-2.md"))
+	expected := "---\ntitle: Title 2\ndate: 2018-03-01\nslug: slug-2\ntags: [Travel]\n---\n\nContent 2\n"
+	if string(output) != expected {
+		t.Errorf("Expected entry to be written as:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestMarkdownEntry(t *testing.T) {
+	categories := map[int]model.Category{
+		1: {ID: 1, Name: "Travel"},
+		2: {ID: 2, Name: "Europe", ParentID: 1},
+	}
+	j := model.Journal{Title: "Trip: Rome", Date: "2019-05-04T00:00:00Z", Slug: "trip-to-rome", Content: "We went to *Rome*.", Excerpt: "A trip", CategoryID: 2, Status: model.JournalStatusDraft, Tags: []string{"Italy", "Road Trip"}}
+	expected := "---\n" +
+		"title: \"Trip: Rome\"\n" +
+		"date: 2019-05-04\n" +
+		"slug: trip-to-rome\n" +
+		"tags: [Italy, Road Trip]\n" +
+		"categories: [Travel, Europe]\n" +
+		"excerpt: A trip\n" +
+		"draft: true\n" +
+		"---\n\n" +
+		"We went to *Rome*.\n"
+	if actual := markdownEntry(j, categories); actual != expected {
+		t.Errorf("Expected entry to be written as:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,248 @@
+package federation
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/pkg/activitypub"
+)
+
+// JobType Name of the background job that delivers activities to followers
+const JobType = "activitypub"
+
+// Paths of the documents describing the journal's actor
+const (
+	ActorPath     = "/activitypub/actor"
+	InboxPath     = "/activitypub/inbox"
+	OutboxPath    = "/activitypub/outbox"
+	FollowersPath = "/activitypub/followers"
+	EntryPath     = "/activitypub/entries/"
+)
+
+// Payload A signed delivery of one activity to one inbox, stored with the queued job
+type Payload struct {
+	Tenant   string          `json:"tenant,omitempty"`
+	KeyID    string          `json:"key_id"`
+	Inbox    string          `json:"inbox"`
+	Activity json.RawMessage `json:"activity"`
+}
+
+// Enabled Whether a site URL and the name of the account followers see have been configured
+func Enabled(container *app.Container) bool {
+	return container.Configuration.URL != "" && container.Configuration.ActivityPubUser != ""
+}
+
+// Account Get the account the journal is followed as, such as journal@example.com
+func Account(container *app.Container) string {
+	site, err := url.Parse(container.URL("/"))
+	if err != nil || site.Host == "" {
+		return ""
+	}
+
+	return container.Configuration.ActivityPubUser + "@" + site.Host
+}
+
+// ActorID Get the address of the journal's actor
+func ActorID(container *app.Container) string {
+	return container.URL(ActorPath)
+}
+
+// KeyID Get the address of the key the journal signs its requests with
+func KeyID(container *app.Container) string {
+	return ActorID(container) + "#main-key"
+}
+
+// Client Get a client signing its requests as the journal
+func Client(container *app.Container) (activitypub.Client, error) {
+	ks := model.ActorKeys{Container: container}
+	key, err := ks.Fetch()
+	if err != nil {
+		return activitypub.Client{}, err
+	}
+
+	return activitypub.Client{KeyID: KeyID(container), Key: key}, nil
+}
+
+// Actor Describe the journal as an actor others can follow
+func Actor(container *app.Container) (activitypub.Actor, error) {
+	ks := model.ActorKeys{Container: container}
+	key, err := ks.Fetch()
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+	publicKey, err := activitypub.EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		return activitypub.Actor{}, err
+	}
+
+	id := ActorID(container)
+	return activitypub.Actor{
+		Context:           activitypub.Context,
+		ID:                id,
+		Type:              "Service",
+		PreferredUsername: container.Configuration.ActivityPubUser,
+		Name:              container.Configuration.Title,
+		URL:               container.URL("/"),
+		Inbox:             container.URL(InboxPath),
+		Outbox:            container.URL(OutboxPath),
+		Followers:         container.URL(FollowersPath),
+		PublicKey:         activitypub.PublicKey{ID: KeyID(container), Owner: id, PublicKeyPem: publicKey},
+	}, nil
+}
+
+// Article Describe an entry as the article followers are sent
+func Article(container *app.Container, journal model.Journal) activitypub.Object {
+	ss := model.Shortcodes{Container: container}
+	return activitypub.Object{
+		ID:           container.URL(EntryPath + journal.Slug),
+		Type:         "Article",
+		AttributedTo: ActorID(container),
+		Name:         journal.Title,
+		Content:      ss.Replace(model.StripWikiLinks(journal.GetHTML())),
+		URL:          container.URL("/" + journal.Slug),
+		Published:    journal.GetTime().Format(time.RFC3339),
+		To:           []string{activitypub.Public},
+		Cc:           []string{container.URL(FollowersPath)},
+	}
+}
+
+// Create Wrap an entry in the activity announcing it was published
+func Create(container *app.Container, journal model.Journal) activitypub.Activity {
+	article := Article(container, journal)
+	return activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      article.ID + "#create",
+		Type:    "Create",
+		Actor:   article.AttributedTo,
+		Object:  article,
+		To:      article.To,
+		Cc:      article.Cc,
+	}
+}
+
+// Outbox List the latest published entries, as the activities that created them
+func Outbox(container *app.Container) activitypub.OrderedCollection {
+	js := model.Journals{Container: container}
+	outbox := activitypub.OrderedCollection{
+		Context:      activitypub.Context,
+		ID:           container.URL(OutboxPath),
+		Type:         "OrderedCollection",
+		OrderedItems: []interface{}{},
+	}
+	for _, j := range js.FetchLatest(container.Configuration.FeedEntries) {
+		create := Create(container, j)
+		create.Context = nil
+		outbox.OrderedItems = append(outbox.OrderedItems, create)
+	}
+	outbox.TotalItems = len(outbox.OrderedItems)
+
+	return outbox
+}
+
+// Notify Queue delivery of a listed entry that has been published or updated to every follower, sent as a new article
+// the first time and as an update after that
+func Notify(container *app.Container, journal model.Journal) error {
+	if !Enabled(container) || journal.Slug == "" || !journal.IsPublished() || !journal.IsListed() {
+		return nil
+	}
+	fs := model.Followers{Container: container}
+	inboxes := fs.Inboxes()
+	if len(inboxes) == 0 {
+		return nil
+	}
+
+	fe := model.FederatedEntries{Container: container}
+	sent, err := fe.Add(journal.ID)
+	if err != nil {
+		return err
+	}
+	activity := Create(container, journal)
+	if sent {
+		now := time.Now().UTC()
+		article := activity.Object.(activitypub.Object)
+		article.Updated = now.Format(time.RFC3339)
+		activity.ID = article.ID + "#update-" + strconv.FormatInt(now.Unix(), 10)
+		activity.Type = "Update"
+		activity.Object = article
+	}
+
+	for _, inbox := range inboxes {
+		if err := enqueue(container, inbox, activity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Accept Add the sender of a follow request as a follower and queue the reply accepting it
+func Accept(container *app.Container, follow activitypub.Activity, follower activitypub.Actor) error {
+	fs := model.Followers{Container: container}
+	if _, err := fs.Save(model.Follower{Actor: follower.ID, Inbox: follower.DeliveryInbox()}); err != nil {
+		return err
+	}
+
+	follow.Context = nil
+	return enqueue(container, follower.Inbox, activitypub.Activity{
+		Context: activitypub.Context,
+		ID:      ActorID(container) + "#accept-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		Type:    "Accept",
+		Actor:   ActorID(container),
+		Object:  follow,
+	})
+}
+
+// Unfollow Remove a follower at their request
+func Unfollow(container *app.Container, actor string) error {
+	fs := model.Followers{Container: container}
+	return fs.Remove(actor)
+}
+
+func enqueue(container *app.Container, inbox string, activity activitypub.Activity) error {
+	encoded, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	js := model.Jobs{Container: container}
+	_, err = js.Enqueue(JobType, Payload{Tenant: container.Tenant, KeyID: KeyID(container), Inbox: inbox, Activity: encoded})
+
+	return err
+}
+
+// Handler Build the job handler delivering a queued activity, signed with the key of the journal that sent it, which
+// when open is given may be one of the hosted journals
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		payload := Payload{}
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Tenant != "" && open != nil {
+			ts := model.Tenants{Container: container}
+			t := ts.FindByName(payload.Tenant)
+			if t.ID == 0 {
+				return nil
+			}
+			hosted, err := open(t)
+			if err != nil {
+				return err
+			}
+			container = hosted
+		}
+
+		ks := model.ActorKeys{Container: container}
+		key, err := ks.Fetch()
+		if err != nil {
+			return err
+		}
+		client := activitypub.Client{KeyID: payload.KeyID, Key: key}
+
+		return client.Deliver(payload.Inbox, payload.Activity)
+	}
+}
@@ -0,0 +1,226 @@
+package federation
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/activitypub"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func storedKey() (*rsa.PrivateKey, *database.MockActorKey_SingleRow) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	encoded := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	return key, &database.MockActorKey_SingleRow{PrivateKey: encoded}
+}
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if Enabled(container) {
+		t.Error("Expected federation to be disabled by default")
+	}
+	container.Configuration.ActivityPubUser = "journal"
+	if Enabled(container) {
+		t.Error("Expected federation to be disabled without a site URL")
+	}
+	container.Configuration.URL = "https://example.com/"
+	if !Enabled(container) || Account(container) != "journal@example.com" {
+		t.Errorf("Expected federation to be enabled as journal@example.com, got %s", Account(container))
+	}
+	if ActorID(container) != "https://example.com/activitypub/actor" || KeyID(container) != "https://example.com/activitypub/actor#main-key" {
+		t.Error("Expected actor and key addresses within the site")
+	}
+}
+
+func TestActor(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+	container.Configuration.ActivityPubUser = "journal"
+	key, row := storedKey()
+	db.Rows = row
+
+	actor, err := Actor(container)
+	if err != nil || actor.PreferredUsername != "journal" || actor.Inbox != "https://example.com/activitypub/inbox" || actor.PublicKey.Owner != actor.ID {
+		t.Fatalf("Expected actor to be described, got %v, %v", actor, err)
+	}
+	published, err := activitypub.ParsePublicKey(actor.PublicKey.PublicKeyPem)
+	if err != nil || published.N.Cmp(key.N) != 0 {
+		t.Error("Expected the stored key to be published")
+	}
+
+	db.ErrorMode = true
+	if _, err := Actor(container); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+
+	create := Create(container, model.Journal{Slug: "trip", Title: "Trip", Date: "2018-02-01T00:00:00Z", Content: "Went *away*"})
+	article := create.Object.(activitypub.Object)
+	if create.Type != "Create" || create.ID != "https://example.com/activitypub/entries/trip#create" || create.Actor != ActorID(container) {
+		t.Errorf("Expected activity creating the article, got %v", create)
+	}
+	if article.Name != "Trip" || article.URL != "https://example.com/trip" || article.Published != "2018-02-01T00:00:00Z" || !strings.Contains(article.Content, "<em>away</em>") {
+		t.Errorf("Expected article describing the entry, got %v", article)
+	}
+	if article.To[0] != activitypub.Public || article.Cc[0] != "https://example.com/activitypub/followers" {
+		t.Error("Expected article to be public and sent to followers")
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockJournal_MultipleRows{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+
+	outbox := Outbox(container)
+	if outbox.TotalItems != 2 || outbox.OrderedItems[0].(activitypub.Activity).ObjectID() != "https://example.com/activitypub/entries/slug" {
+		t.Errorf("Expected latest entries in the outbox, got %v", outbox)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	journal := model.Journal{ID: 1, Slug: "test", Title: "Test"}
+
+	// Test nothing queued when disabled
+	if err := Notify(container, journal); err != nil || db.Queries != 0 {
+		t.Error("Expected nothing to be queued when disabled")
+	}
+	container.Configuration.URL = "https://example.com"
+	container.Configuration.ActivityPubUser = "journal"
+
+	// Test drafts and unlisted entries are not sent
+	for _, j := range []model.Journal{{Slug: "test", Status: model.JournalStatusDraft}, {Slug: "test", Visibility: model.JournalVisibilityUnlisted}} {
+		if err := Notify(container, j); err != nil || db.Queries != 0 {
+			t.Error("Expected nothing to be queued for an entry that is not listed")
+		}
+	}
+
+	// Test nothing queued without followers
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Notify(container, journal); err != nil || db.Queries != 1 {
+		t.Error("Expected nothing to be queued without followers")
+	}
+
+	// Test new entry queued once for each inbox
+	db.Queries = 0
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockFollower_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if err := Notify(container, journal); err != nil || db.Queries != 5 {
+		t.Errorf("Expected entry to be recorded and queued for two inboxes, got %d queries", db.Queries)
+	}
+
+	// Test entry already sent is queued as an update
+	db.Queries = 0
+	db.AppendResult(&database.MockFollower_MultipleRows{})
+	db.AppendResult(&database.MockFederatedEntry_SingleRow{})
+	if err := Notify(container, journal); err != nil || db.Queries != 4 {
+		t.Errorf("Expected update to be queued for two inboxes, got %d queries", db.Queries)
+	}
+
+	db.Queries = 0
+	db.ErrorAtQuery = 2
+	db.AppendResult(&database.MockFollower_MultipleRows{})
+	if err := Notify(container, journal); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestAccept(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+	follower := activitypub.Actor{ID: "https://social.example.com/users/reader", Inbox: "https://social.example.com/users/reader/inbox", Endpoints: &activitypub.Endpoints{SharedInbox: "https://social.example.com/inbox"}}
+	follow := activitypub.Activity{ID: "https://social.example.com/follows/1", Type: "Follow", Actor: follower.ID, Object: ActorID(container)}
+
+	if err := Accept(container, follow, follower); err != nil || db.Queries != 2 {
+		t.Errorf("Expected follower to be saved and the reply queued, got %v", err)
+	}
+	if err := Unfollow(container, follower.ID); err != nil || db.Queries != 3 {
+		t.Errorf("Expected follower to be removed, got %v", err)
+	}
+
+	db.ErrorMode = true
+	if err := Accept(container, follow, follower); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	key, row := storedKey()
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		signature, err := activitypub.ParseSignature(r)
+		if err != nil || signature.Verify(r, body, &key.PublicKey) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	db := &database.MockSqlite{}
+	db.Rows = row
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	payload, _ := json.Marshal(Payload{KeyID: "https://example.com/activitypub/actor#main-key", Inbox: server.URL + "/inbox", Activity: json.RawMessage(`{"type":"Create"}`)})
+
+	// Test invalid payload
+	if err := Handler(nil)(container, model.Job{Payload: "{"}); err == nil {
+		t.Error("Expected error for invalid payload")
+	}
+
+	// Test activity delivered, signed with the stored key
+	if err := Handler(nil)(container, model.Job{Payload: string(payload)}); err != nil || received["/inbox"] != `{"type":"Create"}` {
+		t.Errorf("Expected activity to be delivered, got %v", err)
+	}
+
+	// Test hosted journals sign with their own key
+	hostedKey, hostedRow := storedKey()
+	key = hostedKey
+	hostedDb := &database.MockSqlite{}
+	hostedDb.Rows = hostedRow
+	open := func(tenant model.Tenant) (*app.Container, error) {
+		return &app.Container{Configuration: container.Configuration, Db: hostedDb, Tenant: tenant.Name}, nil
+	}
+	db.Rows = &database.MockTenant_SingleRow{}
+	payload, _ = json.Marshal(Payload{Tenant: "alice", KeyID: "https://alice.example.com/activitypub/actor#main-key", Inbox: server.URL + "/hosted", Activity: json.RawMessage(`{"type":"Update"}`)})
+	if err := Handler(open)(container, model.Job{Payload: string(payload)}); err != nil || received["/hosted"] != `{"type":"Update"}` {
+		t.Errorf("Expected activity to be delivered for the hosted journal, got %v", err)
+	}
+
+	// Test hosted journals that have since gone are skipped
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Handler(open)(container, model.Job{Payload: string(payload)}); err != nil {
+		t.Errorf("Expected job for a missing journal to be dropped, got %v", err)
+	}
+
+	// Test refused delivery
+	db.Rows = &database.MockActorKey_SingleRow{PrivateKey: row.PrivateKey}
+	payload, _ = json.Marshal(Payload{Inbox: server.URL + "/inbox", Activity: json.RawMessage(`{}`)})
+	if err := Handler(nil)(container, model.Job{Payload: string(payload)}); err == nil {
+		t.Error("Expected error for a refused delivery")
+	}
+}
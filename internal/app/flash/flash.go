@@ -0,0 +1,120 @@
+package flash
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// Cookie Name of the cookie carrying messages to show on the next page served, such as the one redirected to once a
+// form is saved
+const Cookie = "journal_flash"
+
+type contextKey string
+
+const messagesKey contextKey = "flash"
+
+// Add Leave messages to show on the next page served, in place of any left before
+func Add(response http.ResponseWriter, request *http.Request, container *app.Container, messages ...app.Flash) {
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		return
+	}
+	cookie := flashCookie(request, container.BasePath, base64.RawURLEncoding.EncodeToString(encoded))
+	http.SetCookie(response, cookie)
+}
+
+// Success Leave a message that something was done, such as a form saved, to show on the next page served. Messages are
+// written in the language the request is served in, filling in any values as fmt does.
+func Success(response http.ResponseWriter, request *http.Request, container *app.Container, text string, args ...interface{}) {
+	Add(response, request, container, app.Flash{Kind: app.FlashSuccess, Text: container.T(text, args...)})
+}
+
+// Error Leave a message that something could not be done, and why, to show on the next page served
+func Error(response http.ResponseWriter, request *http.Request, container *app.Container, text string, args ...interface{}) {
+	Add(response, request, container, app.Flash{Kind: app.FlashError, Text: container.T(text, args...)})
+}
+
+// Info Leave a message to show on the next page served that is neither a success nor an error, such as that something
+// will be done shortly
+func Info(response http.ResponseWriter, request *http.Request, container *app.Container, text string, args ...interface{}) {
+	Add(response, request, container, app.Flash{Kind: app.FlashInfo, Text: container.T(text, args...)})
+}
+
+// Reader Takes any messages left for each request to the journals served by its router, removing them so that they are
+// only shown once, for Messages to give to the page served
+type Reader struct {
+	Router *pkgrouter.Router
+}
+
+// NewReader Create the reader for the journals served by the given router
+func NewReader(router *pkgrouter.Router) *Reader {
+	return &Reader{Router: router}
+}
+
+// Middleware Take the messages left for a request, if any
+func (r *Reader) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		cookie, err := request.Cookie(Cookie)
+		if err != nil || cookie.Value == "" {
+			next.ServeHTTP(response, request)
+			return
+		}
+		basePath := ""
+		if container, ok := r.Router.ContainerFor(request).(*app.Container); ok && container != nil {
+			basePath = container.BasePath
+		}
+		cleared := flashCookie(request, basePath, "")
+		cleared.MaxAge = -1
+		http.SetCookie(response, cleared)
+
+		messages := decode(cookie.Value)
+		next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), messagesKey, messages)))
+	})
+}
+
+// Messages Get the messages left for a request, if any
+func Messages(ctx context.Context) []app.Flash {
+	messages, _ := ctx.Value(messagesKey).([]app.Flash)
+
+	return messages
+}
+
+// Left Get the messages a response leaves for the next page served, such as one redirecting once a form is saved
+func Left(response http.ResponseWriter) []app.Flash {
+	messages := []app.Flash{}
+	for _, cookie := range (&http.Response{Header: response.Header()}).Cookies() {
+		if cookie.Name == Cookie {
+			messages = decode(cookie.Value)
+		}
+	}
+
+	return messages
+}
+
+// decode Read the messages kept in a cookie, giving none when they cannot be read
+func decode(value string) []app.Flash {
+	messages := []app.Flash{}
+	if decoded, err := base64.RawURLEncoding.DecodeString(value); err == nil {
+		json.Unmarshal(decoded, &messages)
+	}
+
+	return messages
+}
+
+// flashCookie Build the cookie carrying messages, kept from scripts and other sites
+func flashCookie(request *http.Request, basePath string, value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     Cookie,
+		Value:    value,
+		Path:     basePath + "/",
+		HttpOnly: true,
+		Secure:   proxy.Secure(request),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
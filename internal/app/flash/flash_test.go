@@ -0,0 +1,86 @@
+package flash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+func TestReader_Middleware(t *testing.T) {
+	container := &app.Container{BasePath: "/journal"}
+	reader := NewReader(&pkgrouter.Router{Container: container})
+	var shown []app.Flash
+	handler := reader.Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		shown = Messages(request.Context())
+	}))
+
+	// Test messages left for the next page are given to it
+	left := httptest.NewRecorder()
+	Add(left, httptest.NewRequest("POST", "/admin/jobs", nil), container, app.Flash{Kind: app.FlashSuccess, Text: "Saved"}, app.Flash{Kind: app.FlashInfo, Text: "Queued"})
+	cookies := left.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != Cookie || cookies[0].Path != "/journal/" || !cookies[0].HttpOnly {
+		t.Fatalf("Expected messages to be left in a cookie, got %v", cookies)
+	}
+	request := httptest.NewRequest("GET", "/admin/jobs", nil)
+	request.AddCookie(cookies[0])
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if len(shown) != 2 || shown[0].Kind != app.FlashSuccess || shown[0].Text != "Saved" || shown[1].Kind != app.FlashInfo || shown[1].Text != "Queued" {
+		t.Errorf("Expected messages to be given to the page, got %v", shown)
+	}
+
+	// Test messages are removed once shown
+	if cleared := response.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge != -1 || cleared[0].Path != "/journal/" {
+		t.Errorf("Expected messages to be removed, got %v", cleared)
+	}
+
+	// Test pages without messages, or whose messages cannot be read, are given none
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if len(shown) != 0 {
+		t.Errorf("Expected no messages, got %v", shown)
+	}
+	request = httptest.NewRequest("GET", "/", nil)
+	request.AddCookie(&http.Cookie{Name: Cookie, Value: "not-json"})
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if len(shown) != 0 {
+		t.Errorf("Expected unreadable messages to be ignored, got %v", shown)
+	}
+}
+
+func TestSuccess(t *testing.T) {
+	container := &app.Container{}
+	for kind, add := range map[string]func(http.ResponseWriter, *http.Request, *app.Container, string, ...interface{}){app.FlashSuccess: Success, app.FlashError: Error, app.FlashInfo: Info} {
+		left := httptest.NewRecorder()
+		add(left, httptest.NewRequest("POST", "/", nil), container, "Message")
+		request := httptest.NewRequest("GET", "/", nil)
+		request.AddCookie(left.Result().Cookies()[0])
+		var shown []app.Flash
+		NewReader(&pkgrouter.Router{Container: container}).Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			shown = Messages(request.Context())
+		})).ServeHTTP(httptest.NewRecorder(), request)
+		if len(shown) != 1 || shown[0].Kind != kind || shown[0].Text != "Message" {
+			t.Errorf("Expected %s message to be left, got %v", kind, shown)
+		}
+	}
+}
+
+func TestLeft(t *testing.T) {
+	response := httptest.NewRecorder()
+	if left := Left(response); len(left) != 0 {
+		t.Errorf("Expected no messages, got %v", left)
+	}
+	Error(response, httptest.NewRequest("POST", "/", nil), &app.Container{}, "Failed")
+	if left := Left(response); len(left) != 1 || left[0].Kind != app.FlashError || left[0].Text != "Failed" {
+		t.Errorf("Expected message left to be given, got %v", left)
+	}
+
+	// Test messages are left in the language of the request, with any values filled in
+	response = httptest.NewRecorder()
+	Info(response, httptest.NewRequest("POST", "/", nil), &app.Container{Locale: "fr"}, "Job %d will be run again.", 3)
+	if left := Left(response); len(left) != 1 || left[0].Text != "La tâche 3 sera exécutée à nouveau." {
+		t.Errorf("Expected message in French, got %v", left)
+	}
+}
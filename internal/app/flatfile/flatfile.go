@@ -0,0 +1,634 @@
+package flatfile
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/frontmatter"
+)
+
+// Name Name of the backend, as reported by the store
+const Name = "files"
+
+// Errors refusing a file that does not hold an entry
+var (
+	ErrNoContent = errors.New("No content")
+	ErrNoSlug    = errors.New("No valid slug")
+	ErrNoTitle   = errors.New("No title")
+)
+
+// datedName A file named in the Jekyll style, with the date it was written before its slug
+var datedName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// syncBatch How many entries are read at a time when writing out those without a file
+const syncBatch = 100
+
+// Store Keeps each entry as a Markdown file with front matter beneath a directory, named like 2020/2020-01-02-slug.md.
+// The files are the source of truth for the entries: the database holds an index of them, built when the store is
+// opened and kept in step as the files change, so that listing, searching and feeds work as they do without them
+type Store struct {
+	Container *app.Container
+	Dir       string
+
+	mu      sync.Mutex
+	paths   map[int]string
+	entries map[string]int
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// Open Build the index from the files beneath a directory, creating it if needed
+func Open(container *app.Container, dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Store{Container: container, Dir: dir, paths: map[int]string{}, entries: map[string]int{}}
+
+	return s, s.Sync()
+}
+
+// Name Name of the backend
+func (s *Store) Name() string {
+	return Name
+}
+
+// CreateJournal Save a new entry, writing it out as a file
+func (s *Store) CreateJournal(j model.Journal) (model.Journal, error) {
+	return s.create(s.journals(), j)
+}
+
+// Delete Remove an entry for good, along with its file
+func (s *Store) Delete(j model.Journal) error {
+	return s.delete(s.journals(), j)
+}
+
+// GetBySlug Find an entry by its slug, leaving out any in the trash
+func (s *Store) GetBySlug(slug string) model.Journal {
+	js := s.journals()
+
+	return js.GetBySlug(slug)
+}
+
+// List Get a page of the published, listed entries, newest first
+func (s *Store) List(query database.PaginationQuery) ([]model.Journal, database.PaginationInformation) {
+	js := s.journals()
+
+	return js.List(query)
+}
+
+// Update Save the changes to an existing entry, writing its file again and renaming it when its date or slug changed
+func (s *Store) Update(j model.Journal) (model.Journal, error) {
+	return s.update(s.journals(), j)
+}
+
+// Bind The store as seen through the database of another container, such as one in a transaction, so that the index
+// is saved along with everything else and a file that cannot be written undoes it
+func (s *Store) Bind(c *app.Container) model.JournalStore {
+	return &bound{Store: s, container: c}
+}
+
+// create Save a new entry in the index given, then write it out as a file
+func (s *Store) create(js model.Journals, j model.Journal) (model.Journal, error) {
+	j, err := js.CreateJournal(j)
+	if err != nil {
+		return j, err
+	}
+
+	return j, s.Write(j)
+}
+
+// delete Remove an entry from the index given, along with its file
+func (s *Store) delete(js model.Journals, j model.Journal) error {
+	if err := js.Delete(j); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	name, ok := s.paths[j.ID]
+	delete(s.paths, j.ID)
+	delete(s.entries, name)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	return os.Remove(filepath.Join(s.Dir, name))
+}
+
+// update Save the changes to an entry in the index given, then write its file again
+func (s *Store) update(js model.Journals, j model.Journal) (model.Journal, error) {
+	j, err := js.Update(j)
+	if err != nil {
+		return j, err
+	}
+
+	return j, s.Write(j)
+}
+
+// Sync Index every file beneath the directory, then write out each entry outside the trash that has no file yet, such
+// as those kept before the directory was chosen or added by an import
+func (s *Store) Sync() error {
+	err := filepath.Walk(s.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isMarkdown(path) {
+			return nil
+		}
+		if _, err := s.Load(path); err != nil {
+			s.Container.Log().Warn("Could not load an entry file", "path", path, "err", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	js := s.journals()
+	query := database.PaginationQuery{Page: 1, ResultsPerPage: syncBatch}
+	for {
+		journals, pagination := js.FetchPaginatedAll(query)
+		for _, j := range journals {
+			s.mu.Lock()
+			_, ok := s.paths[j.ID]
+			s.mu.Unlock()
+			if ok {
+				continue
+			}
+			if err := s.Write(j); err != nil {
+				return err
+			}
+		}
+		if query.Page >= pagination.TotalPages {
+			break
+		}
+		query.Page++
+	}
+
+	return nil
+}
+
+// Load Read a file into the index, adding the entry it holds or updating the one it held before. The fields it has no
+// front matter for, such as a password, are kept from the entry as it was.
+func (s *Store) Load(path string) (model.Journal, error) {
+	name, err := s.name(path)
+	if err != nil {
+		return model.Journal{}, err
+	}
+	source, err := ioutil.ReadFile(filepath.Join(s.Dir, name))
+	if err != nil {
+		return model.Journal{}, err
+	}
+	doc, err := frontmatter.Parse(string(source))
+	if err != nil {
+		return model.Journal{}, err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	date := ""
+	if match := datedName.FindStringSubmatch(base); match != nil {
+		date, base = match[1], match[2]
+	}
+	if written := doc.String("date"); written != "" {
+		date = written
+	}
+	given := slug(doc.String("slug"))
+	if given == "" {
+		given = slug(base)
+	}
+	if given == "" {
+		return model.Journal{}, ErrNoSlug
+	}
+
+	js := s.journals()
+	s.mu.Lock()
+	id, known := s.entries[name]
+	s.mu.Unlock()
+	j := model.Journal{}
+	if known {
+		j = js.FindByID(id)
+	}
+	if j.ID == 0 {
+		if j = js.FindBySlug(given); j.ID == 0 {
+			j = js.FindDeletedBySlug(given)
+		}
+	}
+	ts := model.JournalTags{Container: s.Container}
+	if j.ID > 0 {
+		j = ts.Load(j)
+	}
+	previous := j
+
+	j.Title = strings.TrimSpace(doc.String("title"))
+	j.Content = strings.TrimSpace(doc.Body)
+	if j.Title == "" {
+		return previous, ErrNoTitle
+	}
+	if j.Content == "" {
+		return previous, ErrNoContent
+	}
+	if given != j.Slug && !js.SlugTaken(given, j.ID) {
+		j.Slug = given
+	}
+	// Keep the time of day the entry was given when its file only holds the day
+	if date = (model.Journal{Date: date}).GetEditableDate(); date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if date != j.GetEditableDate() {
+		j.Date = date
+	}
+	j.Excerpt = doc.String("excerpt")
+	j.Pinned = doc.String("pinned") == "true"
+	j.Comments = model.JournalCommentsOpen
+	if doc.String("comments") == model.JournalCommentsClosed {
+		j.Comments = model.JournalCommentsClosed
+	}
+	if j.Visibility = doc.String("visibility"); j.Visibility == "" {
+		j.Visibility = model.JournalVisibilityPublic
+	}
+	status(doc, &j, previous)
+	if err := s.category(doc, &j, previous); err != nil {
+		return previous, err
+	}
+
+	if j.ID == 0 {
+		j, err = js.CreateJournal(j)
+	} else if changed(previous, j) {
+		j, err = js.Update(j)
+	}
+	if err == nil && !sameTags(previous.Tags, j.Tags) {
+		err = ts.Save(j)
+	}
+	if err != nil {
+		return previous, err
+	}
+	s.mu.Lock()
+	s.index(j.ID, name)
+	s.mu.Unlock()
+
+	return j, nil
+}
+
+// Remove Drop a file that has gone from the index, moving the entry it held to the trash
+func (s *Store) Remove(path string) error {
+	name, err := s.name(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	id, ok := s.entries[name]
+	if ok {
+		delete(s.entries, name)
+		delete(s.paths, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	js := s.journals()
+	if j := js.FindByID(id); j.ID > 0 {
+		s.Container.Log().Info("Moved an entry to the trash as its file was removed", "slug", j.Slug)
+		return js.Trash(j)
+	}
+
+	return nil
+}
+
+// Write Write an entry out as a file, removing the one it was kept in before if its name has changed
+func (s *Store) Write(j model.Journal) error {
+	date := j.GetEditableDate()
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	name := filepath.Join(date[:4], date+"-"+j.Slug+".md")
+
+	// The index is updated first so the changes the watcher sees are known to be this store's own
+	s.mu.Lock()
+	old := s.paths[j.ID]
+	s.index(j.ID, name)
+	s.mu.Unlock()
+
+	path := filepath.Join(s.Dir, name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(path, []byte(s.format(j)), 0644)
+	}
+	if err != nil {
+		s.mu.Lock()
+		s.unindex(j.ID, name, old)
+		s.mu.Unlock()
+
+		return err
+	}
+	if old != "" && old != name {
+		return os.Remove(filepath.Join(s.Dir, old))
+	}
+
+	return nil
+}
+
+// Watch Keep the index in step with the files as they are added, changed and removed, until the store is closed
+func (s *Store) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := s.watchTree(watcher, s.Dir); err != nil {
+		watcher.Close()
+		return err
+	}
+	s.watcher = watcher
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				s.handle(watcher, event)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.Container.Log().Warn("Could not watch the entry files", "err", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close Stop watching the files
+func (s *Store) Close() {
+	if s.watcher == nil {
+		return
+	}
+	s.watcher.Close()
+	<-s.done
+	s.watcher = nil
+}
+
+// handle Load a file that was added or changed, or remove one that has gone, watching any new directory too
+func (s *Store) handle(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	info, err := os.Stat(event.Name)
+	switch {
+	case os.IsNotExist(err):
+		if err := s.Remove(event.Name); err != nil {
+			s.Container.Log().Warn("Could not remove an entry file", "path", event.Name, "err", err)
+		}
+	case err != nil:
+		s.Container.Log().Warn("Could not read an entry file", "path", event.Name, "err", err)
+	case info.IsDir():
+		if event.Op&fsnotify.Create == fsnotify.Create {
+			if err := s.watchTree(watcher, event.Name); err != nil {
+				s.Container.Log().Warn("Could not watch a directory", "path", event.Name, "err", err)
+			}
+		}
+	case isMarkdown(event.Name) && event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+		j, err := s.Load(event.Name)
+		if err != nil {
+			s.Container.Log().Warn("Could not load an entry file", "path", event.Name, "err", err)
+			return
+		}
+		// A file put back brings its entry out of the trash
+		if j.DeletedAt != "" && event.Op&fsnotify.Create == fsnotify.Create {
+			js := s.journals()
+			js.Restore(j)
+		}
+	}
+}
+
+// watchTree Watch a directory and every directory beneath it, loading any files already in those added after the
+// store was opened
+func (s *Store) watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		if dir != s.Dir && isMarkdown(path) {
+			if _, err := s.Load(path); err != nil {
+				s.Container.Log().Warn("Could not load an entry file", "path", path, "err", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// category File an entry under the category it names, created if needed, and give it the tags it lists. Files written
+// before entries were tagged named the category as their only tag, which is still read as the category when the entry
+// is already filed under it.
+func (s *Store) category(doc frontmatter.Document, j *model.Journal, previous model.Journal) error {
+	tags := doc.List("tags")
+	name := strings.TrimSpace(doc.String("category"))
+	cs := model.Categories{Container: s.Container}
+	if _, ok := doc.Fields["category"]; !ok && len(tags) == 1 && previous.CategoryID > 0 {
+		if c := cs.FindByID(previous.CategoryID); c.ID > 0 && model.Slugify(c.Name) == model.Slugify(tags[0]) {
+			j.CategoryID, j.Tags = c.ID, []string{}
+			return nil
+		}
+	}
+	j.Tags = model.ParseTags(strings.Join(tags, ","))
+	if name == "" {
+		j.CategoryID = 0
+		return nil
+	}
+	c := cs.FindBySlug(model.Slugify(name))
+	if c.ID == 0 {
+		var err error
+		if c, err = cs.Save(model.Category{Name: name}); err != nil {
+			return err
+		}
+	}
+	j.CategoryID = c.ID
+
+	return nil
+}
+
+// format Write an entry as front matter followed by the Markdown it was written in
+func (s *Store) format(j model.Journal) string {
+	fields := []frontmatter.Field{
+		{Key: "title", Value: j.Title},
+		{Key: "date", Value: j.GetEditableDate()},
+		{Key: "slug", Value: j.Slug},
+	}
+	if j.CategoryID > 0 {
+		cs := model.Categories{Container: s.Container}
+		if c := cs.FindByID(j.CategoryID); c.ID > 0 {
+			fields = append(fields, frontmatter.Field{Key: "category", Value: c.Name})
+		}
+	}
+	// Entries saved without their tags loaded keep those already stored
+	if j.Tags == nil && j.ID > 0 {
+		ts := model.JournalTags{Container: s.Container}
+		j = ts.Load(j)
+	}
+	if len(j.Tags) > 0 {
+		fields = append(fields, frontmatter.Field{Key: "tags", Value: j.Tags})
+	}
+	if j.Excerpt != "" {
+		fields = append(fields, frontmatter.Field{Key: "excerpt", Value: j.Excerpt})
+	}
+	if !j.IsPublished() {
+		fields = append(fields, frontmatter.Field{Key: "draft", Value: true})
+	}
+	if j.Status == model.JournalStatusScheduled {
+		fields = append(fields, frontmatter.Field{Key: "publish_at", Value: j.PublishAt})
+	}
+	if j.Pinned {
+		fields = append(fields, frontmatter.Field{Key: "pinned", Value: true})
+	}
+	if j.Visibility != "" && j.Visibility != model.JournalVisibilityPublic {
+		fields = append(fields, frontmatter.Field{Key: "visibility", Value: j.Visibility})
+	}
+	if !j.CommentsOpen() {
+		fields = append(fields, frontmatter.Field{Key: "comments", Value: model.JournalCommentsClosed})
+	}
+
+	return frontmatter.Format(fields, j.Content)
+}
+
+// index Remember which file an entry is kept in, which the caller must hold the lock for
+func (s *Store) index(id int, name string) {
+	if old, ok := s.paths[id]; ok {
+		delete(s.entries, old)
+	}
+	s.paths[id] = name
+	s.entries[name] = id
+}
+
+// unindex Forget the file an entry could not be written to, indexing it under the one it had before if any
+func (s *Store) unindex(id int, name string, old string) {
+	delete(s.entries, name)
+	delete(s.paths, id)
+	if old != "" {
+		s.index(id, old)
+	}
+}
+
+// journals Entries in the database, which holds the index of the files
+func (s *Store) journals() model.Journals {
+	return journals(s.Container)
+}
+
+// bound The store seen through the database of another container
+type bound struct {
+	*Store
+	container *app.Container
+}
+
+// CreateJournal Save a new entry, writing it out as a file
+func (b *bound) CreateJournal(j model.Journal) (model.Journal, error) {
+	return b.create(journals(b.container), j)
+}
+
+// Delete Remove an entry for good, along with its file
+func (b *bound) Delete(j model.Journal) error {
+	return b.delete(journals(b.container), j)
+}
+
+// GetBySlug Find an entry by its slug, leaving out any in the trash
+func (b *bound) GetBySlug(slug string) model.Journal {
+	js := journals(b.container)
+
+	return js.GetBySlug(slug)
+}
+
+// List Get a page of the published, listed entries, newest first
+func (b *bound) List(query database.PaginationQuery) ([]model.Journal, database.PaginationInformation) {
+	js := journals(b.container)
+
+	return js.List(query)
+}
+
+// Update Save the changes to an existing entry, writing its file again
+func (b *bound) Update(j model.Journal) (model.Journal, error) {
+	return b.update(journals(b.container), j)
+}
+
+// journals Entries in the database of a container
+func journals(c *app.Container) model.Journals {
+	return model.Journals{Container: c, Gs: model.GiphyAdapter(c)}
+}
+
+// name The name of a file relative to the directory, which it is indexed under
+func (s *Store) name(path string) (string, error) {
+	return filepath.Rel(s.Dir, path)
+}
+
+// changed Whether loading a file changed anything kept for the entry
+func changed(previous model.Journal, j model.Journal) bool {
+	return previous.Title != j.Title || previous.Content != j.Content || previous.Slug != j.Slug || previous.Date != j.Date ||
+		previous.Excerpt != j.Excerpt || previous.Status != j.Status || previous.PublishAt != j.PublishAt ||
+		previous.CategoryID != j.CategoryID || previous.Pinned != j.Pinned || previous.Visibility != j.Visibility ||
+		previous.Comments != j.Comments
+}
+
+// sameTags Whether an entry carries the same tags, named the same and in any order, as it did before
+func sameTags(previous []string, tags []string) bool {
+	if len(previous) != len(tags) {
+		return false
+	}
+	names := map[string]string{}
+	for _, name := range previous {
+		names[model.TagSlug(name)] = name
+	}
+	for _, name := range tags {
+		if names[model.TagSlug(name)] != name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// status Read whether an entry is a draft or scheduled. An entry published since its file was written as scheduled
+// stays published.
+func status(doc frontmatter.Document, j *model.Journal, previous model.Journal) {
+	publishAt := doc.String("publish_at")
+	switch {
+	case publishAt != "" && previous.ID > 0 && previous.IsPublished():
+		j.Status = model.JournalStatusPublished
+	case publishAt != "":
+		j.Status = model.JournalStatusScheduled
+		j.PublishAt = publishAt
+	case doc.String("draft") == "true":
+		j.Status = model.JournalStatusDraft
+	default:
+		j.Status = model.JournalStatusPublished
+	}
+}
+
+// isMarkdown Whether a file is named as Markdown
+func isMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	return ext == ".md" || ext == ".markdown"
+}
+
+// slug Turn a given slug or file name into one an entry can use, empty if there is nothing usable in it
+func slug(s string) string {
+	s = strings.Trim(model.Slugify(strings.TrimSpace(s)), "-")
+	if !model.IsValidSlug(s) {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,178 @@
+package flatfile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/frontmatter"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestStore_Name(t *testing.T) {
+	s := &Store{}
+	if s.Name() != Name {
+		t.Errorf("Expected store to be named %s, got %s", Name, s.Name())
+	}
+}
+
+func TestStore_format(t *testing.T) {
+	s := &Store{Container: &app.Container{Db: &database.MockSqlite{}}}
+	j := model.Journal{Title: "Trip to Rome", Date: "2019-05-04T00:00:00Z", Slug: "trip-to-rome", Content: "We went to *Rome*.", Status: model.JournalStatusDraft, Pinned: true, Visibility: model.JournalVisibilityPublic, Comments: model.JournalCommentsClosed}
+	formatted := s.format(j)
+	for _, expected := range []string{"title: Trip to Rome", "date: 2019-05-04", "slug: trip-to-rome", "draft: true", "pinned: true", "comments: closed", "We went to *Rome*."} {
+		if !strings.Contains(formatted, expected) {
+			t.Errorf("Expected %s to be written, got:\n%s", expected, formatted)
+		}
+	}
+	if strings.Contains(formatted, "visibility") {
+		t.Error("Expected public visibility to be left out")
+	}
+
+	doc, _ := frontmatter.Parse(formatted)
+	if doc.String("title") != j.Title || doc.String("slug") != j.Slug || strings.TrimSpace(doc.Body) != j.Content {
+		t.Errorf("Expected written entry to be read back, got %v", doc)
+	}
+
+	// Test the category and tags are written apart
+	s.Container.Db.(*database.MockSqlite).Rows = &database.MockCategory_SingleRow{}
+	j.CategoryID = 1
+	j.Tags = []string{"Road Trip", "Italy"}
+	doc, _ = frontmatter.Parse(s.format(j))
+	if doc.String("category") != "Travel" || strings.Join(doc.List("tags"), ",") != "Road Trip,Italy" {
+		t.Errorf("Expected category and tags to be written, got %v", doc.Fields)
+	}
+}
+
+func TestStore_category(t *testing.T) {
+	db := &database.MockSqlite{}
+	s := &Store{Container: &app.Container{Db: db}}
+	tests := []struct {
+		source   string
+		previous model.Journal
+		category int
+		tags     string
+	}{
+		{"---\ntitle: Test\ncategory: Travel\ntags: [Road Trip, road trip, Italy]\n---\nBody", model.Journal{}, 1, "Road Trip,Italy"},
+		{"---\ntitle: Test\ntags: [Travel]\n---\nBody", model.Journal{}, 0, "Travel"},
+		{"---\ntitle: Test\ntags: [Travel]\n---\nBody", model.Journal{ID: 1, CategoryID: 1}, 1, ""},
+		{"---\ntitle: Test\n---\nBody", model.Journal{ID: 1, CategoryID: 1}, 0, ""},
+	}
+	for _, test := range tests {
+		doc, _ := frontmatter.Parse(test.source)
+		db.Rows = &database.MockCategory_SingleRow{}
+		j := model.Journal{}
+		if err := s.category(doc, &j, test.previous); err != nil {
+			t.Fatal(err)
+		}
+		if j.CategoryID != test.category || j.Tags == nil || strings.Join(j.Tags, ",") != test.tags {
+			t.Errorf("Expected category %d and tags %s, got %d and %v", test.category, test.tags, j.CategoryID, j.Tags)
+		}
+	}
+}
+
+func TestStore_index(t *testing.T) {
+	s := &Store{paths: map[int]string{}, entries: map[string]int{}}
+	s.index(1, "2018/2018-01-01-test.md")
+	s.index(1, "2018/2018-01-02-test.md")
+	if s.paths[1] != "2018/2018-01-02-test.md" || s.entries["2018/2018-01-02-test.md"] != 1 {
+		t.Error("Expected entry to be indexed under its new file")
+	}
+	if _, ok := s.entries["2018/2018-01-01-test.md"]; ok {
+		t.Error("Expected old file to be forgotten")
+	}
+}
+
+func TestStore_Write(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	if err := ioutil.WriteFile(blocked, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Store{Container: &app.Container{Db: &database.MockSqlite{Rows: &database.MockRowsEmpty{}}}, Dir: blocked, paths: map[int]string{}, entries: map[string]int{}}
+	s.index(1, "2018/2018-01-01-test.md")
+	j := model.Journal{ID: 1, Title: "Test", Date: "2018-01-02T00:00:00Z", Slug: "test", Content: "Content"}
+	if err := s.Write(j); err == nil {
+		t.Error("Expected a file that could not be written to give an error")
+	}
+	if s.paths[1] != "2018/2018-01-01-test.md" || s.entries["2018/2018-01-01-test.md"] != 1 {
+		t.Error("Expected entry to stay indexed under its old file")
+	}
+	if _, ok := s.entries["2018/2018-01-02-test.md"]; ok {
+		t.Error("Expected file that could not be written to be forgotten")
+	}
+
+	s.Dir = dir
+	if err := s.Write(model.Journal{ID: 1, Title: "Test", Date: "2018-01-01T00:00:00Z", Slug: "test", Content: "Content"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(j); err != nil {
+		t.Errorf("Expected file to be written, got %s", err)
+	}
+	if s.paths[1] != "2018/2018-01-02-test.md" {
+		t.Error("Expected entry to be indexed under its new file")
+	}
+	if _, err := ioutil.ReadFile(filepath.Join(dir, "2018/2018-01-01-test.md")); err == nil {
+		t.Error("Expected old file to be removed")
+	}
+}
+
+func TestStore_Bind(t *testing.T) {
+	s := &Store{Container: &app.Container{Db: &database.MockSqlite{}}}
+	tx := &app.Container{Db: &database.MockSqlite{}, Store: s}
+	b, ok := model.Store(tx).(*bound)
+	if !ok || b.Store != s || b.container != tx {
+		t.Error("Expected store to be seen through the container it was taken from")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	j := model.Journal{ID: 1, Title: "Test", Content: "Content"}
+	if changed(j, j) {
+		t.Error("Expected identical entry not to have changed")
+	}
+	edited := j
+	edited.Content = "Edited"
+	if !changed(j, edited) {
+		t.Error("Expected edited entry to have changed")
+	}
+}
+
+func TestSameTags(t *testing.T) {
+	if !sameTags([]string{"Travel", "Road Trip"}, []string{"Road Trip", "Travel"}) || !sameTags(nil, []string{}) {
+		t.Error("Expected tags in another order to be the same")
+	}
+	if sameTags([]string{"Travel"}, []string{"travel"}) || sameTags([]string{"Travel"}, []string{"Travel", "Italy"}) {
+		t.Error("Expected renamed or added tags to differ")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		source   string
+		previous model.Journal
+		expected string
+	}{
+		{"---\ntitle: Test\n---\nBody", model.Journal{}, model.JournalStatusPublished},
+		{"---\ntitle: Test\ndraft: true\n---\nBody", model.Journal{}, model.JournalStatusDraft},
+		{"---\ntitle: Test\ndraft: true\npublish_at: 2030-01-01 10:00:00\n---\nBody", model.Journal{}, model.JournalStatusScheduled},
+		{"---\ntitle: Test\ndraft: true\npublish_at: 2018-01-01 10:00:00\n---\nBody", model.Journal{ID: 1, Status: model.JournalStatusPublished}, model.JournalStatusPublished},
+	}
+	for _, test := range tests {
+		doc, _ := frontmatter.Parse(test.source)
+		j := model.Journal{}
+		status(doc, &j, test.previous)
+		if j.Status != test.expected {
+			t.Errorf("Expected status %s, got %s", test.expected, j.Status)
+		}
+	}
+}
+
+func TestIsMarkdown(t *testing.T) {
+	if !isMarkdown("2018/2018-01-01-test.md") || !isMarkdown("post.MARKDOWN") || isMarkdown("image.png") {
+		t.Error("Expected only Markdown files to be recognised")
+	}
+}
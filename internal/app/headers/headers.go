@@ -0,0 +1,75 @@
+package headers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// DefaultContentSecurityPolicy Policy allowing the journal's own scripts, styles and requests, along with images and
+// media from anywhere and frames over HTTPS, as entries may embed them, while refusing plugins and being framed
+const DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src * data:; media-src *; frame-src https:; connect-src 'self'; object-src 'none'; base-uri 'self'; " +
+	"form-action 'self'; frame-ancestors 'none'"
+
+// PolicyOff Value of the configured policy that stops the header being sent, such as when a proxy sends its own
+const PolicyOff = "off"
+
+// Security Adds headers to every response asking browsers to only run what the journal serves, not to guess at types,
+// not to frame its pages, to keep its addresses to itself when following links elsewhere and, once served over HTTPS,
+// never to visit it over anything else
+type Security struct {
+	Router *pkgrouter.Router
+}
+
+// NewSecurity Create the headers for the journals served by the given router, each with the policy it is configured with
+func NewSecurity(router *pkgrouter.Router) *Security {
+	return &Security{Router: router}
+}
+
+// Middleware Add the headers before the response is written
+func (s *Security) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		config := app.DefaultConfiguration()
+		if container, ok := s.Router.ContainerFor(request).(*app.Container); ok && container != nil {
+			config = container.Configuration
+		}
+
+		header := response.Header()
+		if policy := ContentSecurityPolicy(config); policy != "" {
+			header.Set("Content-Security-Policy", policy)
+		}
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		if config.HSTSMaxAge > 0 && Secure(request, config) {
+			header.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(config.HSTSMaxAge)+"; includeSubDomains")
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// ContentSecurityPolicy Get the policy a journal is configured with, the default when none is, or nothing when it has
+// been turned off
+func ContentSecurityPolicy(config app.Configuration) string {
+	policy := strings.TrimSpace(config.ContentSecurityPolicy)
+	switch {
+	case policy == "":
+		return DefaultContentSecurityPolicy
+	case strings.EqualFold(policy, PolicyOff):
+		return ""
+	}
+
+	return policy
+}
+
+// Secure Whether a request reached the journal over HTTPS, either directly, through a trusted proxy saying so, or through
+// a proxy in front of a journal whose address is configured as HTTPS
+func Secure(request *http.Request, config app.Configuration) bool {
+	return proxy.Secure(request) || strings.HasPrefix(strings.ToLower(config.URL), "https://")
+}
@@ -0,0 +1,78 @@
+package headers
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestSecurity_Middleware(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	served := false
+	handler := NewSecurity(&pkgrouter.Router{Container: container}).Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served = true
+	}))
+	response := controller.NewMockResponse()
+
+	// Test headers are added to plain HTTP responses, without HSTS
+	request, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(response, request)
+	if !served || response.Headers.Get("Content-Security-Policy") != DefaultContentSecurityPolicy || response.Headers.Get("X-Frame-Options") != "DENY" ||
+		response.Headers.Get("X-Content-Type-Options") != "nosniff" || response.Headers.Get("Referrer-Policy") != "strict-origin-when-cross-origin" {
+		t.Error("Expected security headers to be added before serving the request")
+	}
+	if response.Headers.Get("Strict-Transport-Security") != "" {
+		t.Error("Expected no HSTS over plain HTTP")
+	}
+
+	// Test HSTS over TLS, unless it is turned off
+	response.Reset()
+	request.TLS = &tls.ConnectionState{}
+	handler.ServeHTTP(response, request)
+	if response.Headers.Get("Strict-Transport-Security") != "max-age=31536000; includeSubDomains" {
+		t.Errorf("Expected HSTS over TLS, got '%s'", response.Headers.Get("Strict-Transport-Security"))
+	}
+	response.Reset()
+	container.Configuration.HSTSMaxAge = 0
+	handler.ServeHTTP(response, request)
+	if response.Headers.Get("Strict-Transport-Security") != "" {
+		t.Error("Expected no HSTS once turned off")
+	}
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	tables := []struct {
+		configured string
+		policy     string
+	}{
+		{"", DefaultContentSecurityPolicy},
+		{"  ", DefaultContentSecurityPolicy},
+		{"default-src 'self'; font-src https://fonts.example.com", "default-src 'self'; font-src https://fonts.example.com"},
+		{"off", ""},
+		{"OFF", ""},
+	}
+
+	for _, table := range tables {
+		if actual := ContentSecurityPolicy(app.Configuration{ContentSecurityPolicy: table.configured}); actual != table.policy {
+			t.Errorf("Expected policy '%s' for '%s', got '%s'", table.policy, table.configured, actual)
+		}
+	}
+}
+
+func TestSecure(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/", nil)
+	if Secure(request, app.Configuration{}) || Secure(request, app.Configuration{URL: "http://journal.example.com"}) {
+		t.Error("Expected plain HTTP not to be secure")
+	}
+	if !Secure(request, app.Configuration{URL: "HTTPS://journal.example.com"}) {
+		t.Error("Expected journal configured with an HTTPS address to be secure behind a proxy")
+	}
+	request.TLS = &tls.ConnectionState{}
+	if !Secure(request, app.Configuration{}) {
+		t.Error("Expected TLS to be secure")
+	}
+}
@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/frontmatter"
+)
+
+// dateFormats Ways dates are commonly written in front matter, tried in order
+var dateFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// datedName A file named in the Jekyll style, with the date it was written before its slug
+var datedName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// Skipped A file or post that could not be imported, or part of one that could not be kept, along with why
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Report What an import created, or would create on a dry run, and what it left out because its slug was already
+// taken or it could not be read, along with the tags and categories of imported entries that could not be kept
+type Report struct {
+	Created    []string
+	Categories []string
+	Comments   int
+	Duplicates []string
+	Skipped    []Skipped
+	Dropped    []Skipped
+}
+
+// run The state of a single import, remembering what it has created so repeats within it are caught on a dry run
+type run struct {
+	js         model.Journals
+	cs         model.Categories
+	ts         model.JournalTags
+	dryRun     bool
+	report     Report
+	slugs      map[string]bool
+	categories map[string]model.Category
+}
+
+func newRun(container *app.Container, dryRun bool) *run {
+	return &run{
+		js:         model.Journals{Container: container, Gs: model.GiphyAdapter(container)},
+		cs:         model.Categories{Container: container},
+		ts:         model.JournalTags{Container: container},
+		dryRun:     dryRun,
+		slugs:      map[string]bool{},
+		categories: map[string]model.Category{},
+	}
+}
+
+func (r *run) skip(name string, reason string) {
+	r.report.Skipped = append(r.report.Skipped, Skipped{Name: name, Reason: reason})
+}
+
+func (r *run) drop(name string, reason string) {
+	r.report.Dropped = append(r.report.Dropped, Skipped{Name: name, Reason: reason})
+}
+
+// tags Keep the tags given to an entry, reporting any that cannot be kept as they are too long, have no letters or
+// numbers, or repeat another
+func (r *run) tags(name string, given []string) []string {
+	tags := []string{}
+	for _, tag := range given {
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		kept := model.ParseTags(strings.Join(append(tags, tag), ","))
+		switch {
+		case len(kept) > len(tags):
+			tags = kept
+		case model.TagSlug(tag) == "":
+			r.drop(name, "Tag \""+tag+"\" has no letters or numbers")
+		case len([]rune(tag)) > model.TagLength:
+			r.drop(name, "Tag \""+tag+"\" is longer than "+strconv.Itoa(model.TagLength)+" characters")
+		default:
+			r.drop(name, "Tag \""+tag+"\" repeats another")
+		}
+	}
+
+	return tags
+}
+
+// taken Check whether an entry already has the slug, reporting it as a duplicate if so
+func (r *run) taken(slug string) bool {
+	if r.slugs[slug] || r.js.SlugTaken(slug, 0) {
+		r.report.Duplicates = append(r.report.Duplicates, slug)
+		return true
+	}
+
+	return false
+}
+
+// save Save a new entry along with its tags, unless this is a dry run
+func (r *run) save(j model.Journal) (model.Journal, error) {
+	if r.dryRun {
+		r.slugs[j.Slug] = true
+		r.report.Created = append(r.report.Created, j.Slug)
+		return j, nil
+	}
+	tags := j.Tags
+	j, err := r.js.Save(j)
+	if err != nil {
+		return j, err
+	}
+	if err := r.ts.Add(j.ID, tags); err != nil {
+		return j, err
+	}
+	r.slugs[j.Slug] = true
+	r.report.Created = append(r.report.Created, j.Slug)
+
+	return j, nil
+}
+
+// category Find the category with a name, adding it when there is none unless this is a dry run
+func (r *run) category(name string, parentID int) (model.Category, error) {
+	key := model.Slugify(strings.TrimSpace(name))
+	if category, ok := r.categories[key]; ok {
+		return category, nil
+	}
+	category := r.cs.FindBySlug(key)
+	if category.ID == 0 {
+		r.report.Categories = append(r.report.Categories, name)
+		category = model.Category{Name: name, ParentID: parentID}
+		if !r.dryRun {
+			var err error
+			if category, err = r.cs.Save(category); err != nil {
+				return category, err
+			}
+		}
+	}
+	r.categories[key] = category
+
+	return category, nil
+}
+
+// Markdown Import every Markdown file beneath a directory as an entry, reading its title, date, slug, tags, category
+// and whether it is a draft from its front matter. The entry is filed under the category named by category or, failing
+// that, the last of categories nested beneath those before it, created if needed. Files named like 2020-01-02-slug.md
+// give their date and slug when the front matter does not. A dry run reports what would be created without saving
+// anything.
+func Markdown(container *app.Container, dir string, dryRun bool) (Report, error) {
+	r := newRun(container, dryRun)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if info.IsDir() || (ext != ".md" && ext != ".markdown") {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		skip := func(reason string) error {
+			r.skip(name, reason)
+			return nil
+		}
+
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return skip(err.Error())
+		}
+		doc, err := frontmatter.Parse(string(source))
+		if err != nil {
+			return skip(err.Error())
+		}
+
+		j := model.Journal{Title: strings.TrimSpace(doc.String("title")), Content: strings.TrimSpace(doc.Body)}
+		if j.Title == "" {
+			return skip("No title")
+		}
+		if j.Content == "" {
+			return skip("No content")
+		}
+
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		date := info.ModTime()
+		if match := datedName.FindStringSubmatch(base); match != nil {
+			base = match[2]
+			date, _ = time.Parse("2006-01-02", match[1])
+		}
+		if written := doc.String("date"); written != "" {
+			if date, err = parseDate(written); err != nil {
+				return skip("Invalid date " + written)
+			}
+		}
+		j.Date = date.Format("2006-01-02")
+
+		if j.Slug = slug(doc.String("slug")); j.Slug == "" {
+			j.Slug = slug(base)
+		}
+		if j.Slug == "" {
+			return skip("No valid slug")
+		}
+		if r.taken(j.Slug) {
+			return nil
+		}
+
+		if doc.String("draft") == "true" {
+			j.Status = model.JournalStatusDraft
+		}
+		j.Tags = r.tags(name, doc.List("tags"))
+		categories := doc.List("categories")
+		if category := strings.TrimSpace(doc.String("category")); category != "" {
+			categories = []string{category}
+		}
+		for _, categoryName := range categories {
+			category, err := r.category(categoryName, j.CategoryID)
+			if err != nil {
+				return err
+			}
+			j.CategoryID = category.ID
+		}
+		_, err = r.save(j)
+
+		return err
+	})
+
+	return r.report, err
+}
+
+// slug Turn a given slug or file name into one an entry can use, empty if there is nothing usable in it
+func slug(s string) string {
+	s = strings.Trim(model.Slugify(strings.TrimSpace(s)), "-")
+	if !model.IsValidSlug(s) {
+		return ""
+	}
+
+	return s
+}
+
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, format := range dateFormats {
+		var date time.Time
+		if date, err = time.Parse(format, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, err
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, _ := ioutil.TempDir("", "posts")
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMarkdown(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"2019-05-04-trip-to-rome.md":  "---\ntitle: Trip to Rome\ntags: [Italy, Food, food, \"!!\"]\ncategories: [Travel]\n---\nWe went to *Rome*.",
+		"notes/Reading List.markdown": "---\ntitle: Reading List\ndate: 2020-01-02 10:00:00\ndraft: true\n---\nBooks.",
+		"custom.md":                   "---\ntitle: Custom\nslug: my-custom-slug\ndate: 2021-03-04\ncategory: Work\ntags: " + strings.Repeat("a", 65) + "\n---\nText.",
+		"untitled.md":                 "Just some text.",
+		"baddate.md":                  "---\ntitle: Bad Date\ndate: yesterday\n---\nText.",
+		"open.md":                     "---\ntitle: Open\nText.",
+		"empty.md":                    "---\ntitle: Empty\n---\n",
+		"image.png":                   "Not Markdown",
+	})
+	defer os.RemoveAll(dir)
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	report, err := Markdown(container, dir, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(report.Created, []string{"trip-to-rome", "my-custom-slug", "reading-list"}) {
+		t.Errorf("Expected entries to be created from valid files, got %v", report.Created)
+	}
+	skipped := map[string]string{}
+	for _, s := range report.Skipped {
+		skipped[s.Name] = s.Reason
+	}
+	expected := map[string]string{
+		"untitled.md": "No title",
+		"baddate.md":  "Invalid date yesterday",
+		"open.md":     "Front matter is not closed by a line of ---",
+		"empty.md":    "No content",
+	}
+	if !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("Expected invalid files to be skipped with reasons, got %v", skipped)
+	}
+	dropped := []Skipped{
+		{"2019-05-04-trip-to-rome.md", `Tag "food" repeats another`},
+		{"2019-05-04-trip-to-rome.md", `Tag "!!" has no letters or numbers`},
+		{"custom.md", `Tag "` + strings.Repeat("a", 65) + `" is longer than 64 characters`},
+	}
+	if !reflect.DeepEqual(report.Dropped, dropped) {
+		t.Errorf("Expected tags that could not be kept to be reported, got %v", report.Dropped)
+	}
+
+	// Slugs already in use are reported rather than renamed
+	db.EnableMultiMode()
+	for i := 0; i < 3; i++ {
+		db.AppendResult(&database.MockJournal_SingleRow{})
+	}
+	report, _ = Markdown(container, dir, false)
+	if len(report.Created) != 0 || !reflect.DeepEqual(report.Duplicates, []string{"trip-to-rome", "my-custom-slug", "reading-list"}) {
+		t.Errorf("Expected duplicate slugs to be reported, got %v", report.Duplicates)
+	}
+
+	// Dry runs save nothing
+	db.Queries = 0
+	db.MultiMode = false
+	report, _ = Markdown(container, dir, true)
+	if len(report.Created) != 3 || !reflect.DeepEqual(report.Categories, []string{"Travel", "Work"}) || db.Queries != 5 {
+		t.Errorf("Expected entries to be reported without saving, got %v after %d queries", report, db.Queries)
+	}
+
+	if _, err := Markdown(container, filepath.Join(dir, "missing"), false); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"2020-01-02", "2020-01-02"},
+		{"2020-01-02 23:30", "2020-01-02"},
+		{"2020-01-02 23:30:00 +0100", "2020-01-02"},
+		{"2020-01-02T23:30:00Z", "2020-01-02"},
+	}
+
+	for _, table := range tables {
+		if date, err := parseDate(table.input); err != nil || date.Format("2006-01-02") != table.output {
+			t.Errorf("Expected parseDate(%q) to be %s, got %s", table.input, table.output, date)
+		}
+	}
+	if _, err := parseDate("02/01/2020"); err == nil {
+		t.Error("Expected error for an unknown date format")
+	}
+}
@@ -0,0 +1,283 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+// contentNamespace Namespace of the element holding a post's content, set apart from its excerpt only by namespace
+const contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+
+// wordPressTimeFormat How WordPress writes the dates of posts and comments
+const wordPressTimeFormat = "2006-01-02 15:04:05"
+
+// Elements of a WordPress eXtended RSS (WXR) export, matched by name alone as the namespace changes between versions
+type wxrCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Parent   string `xml:"category_parent"`
+	Name     string `xml:"cat_name"`
+}
+
+type wxrTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrText struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	AuthorURL   string `xml:"comment_author_url"`
+	AuthorIP    string `xml:"comment_author_IP"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+}
+
+type wxrItem struct {
+	Title         string       `xml:"title"`
+	PubDate       string       `xml:"pubDate"`
+	Encoded       []wxrText    `xml:"encoded"`
+	PostDate      string       `xml:"post_date"`
+	PostDateGMT   string       `xml:"post_date_gmt"`
+	CommentStatus string       `xml:"comment_status"`
+	Name          string       `xml:"post_name"`
+	Status        string       `xml:"status"`
+	Type          string       `xml:"post_type"`
+	Password      string       `xml:"post_password"`
+	Sticky        string       `xml:"is_sticky"`
+	Terms         []wxrTerm    `xml:"category"`
+	Comments      []wxrComment `xml:"comment"`
+}
+
+type wxr struct {
+	Categories []wxrCategory `xml:"channel>category"`
+	Items      []wxrItem     `xml:"channel>item"`
+}
+
+// content Get the content or, from the excerpt namespace, the excerpt of the post
+func (i wxrItem) content(excerpt bool) string {
+	for _, e := range i.Encoded {
+		if (e.XMLName.Space == contentNamespace) != excerpt {
+			return strings.TrimSpace(e.Value)
+		}
+	}
+
+	return ""
+}
+
+// categories Get the categories the post is filed under, in the order listed
+func (i wxrItem) categories() []wxrTerm {
+	return i.terms("category")
+}
+
+// tags Get the names of the tags given to the post
+func (i wxrItem) tags() []string {
+	tags := []string{}
+	for _, term := range i.terms("post_tag") {
+		tags = append(tags, term.Name)
+	}
+
+	return tags
+}
+
+func (i wxrItem) terms(domain string) []wxrTerm {
+	terms := []wxrTerm{}
+	for _, term := range i.Terms {
+		if term.Domain == domain {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// date Get when the post was written, drafts not being dated until they are published
+func (i wxrItem) date() (time.Time, bool) {
+	if date, err := time.Parse(wordPressTimeFormat, i.PostDate); err == nil && date.Year() > 1 {
+		return date, true
+	}
+	if date, err := time.Parse(time.RFC1123Z, i.PubDate); err == nil && date.Year() > 1 {
+		return date, true
+	}
+
+	return time.Time{}, false
+}
+
+// WordPress Import the posts of a WordPress export (WXR) as entries, keeping their dates, slugs, excerpts, first
+// category, tags and comments, and reporting any further categories as dropped. Drafts and pending posts become
+// drafts, future posts are scheduled and private posts are kept private, while pages, attachments and anything in the
+// trash are left out. Categories are created with the nesting they had. A dry run reports what would be created
+// without saving anything.
+func WordPress(container *app.Container, source io.Reader, dryRun bool) (Report, error) {
+	doc := wxr{}
+	decoder := xml.NewDecoder(source)
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return Report{}, err
+	}
+
+	r := newRun(container, dryRun)
+	categories, err := r.wordPressCategories(doc.Categories)
+	if err != nil {
+		return r.report, err
+	}
+	cs := model.Comments{Container: container}
+
+	for _, item := range doc.Items {
+		name := strings.TrimSpace(item.Title)
+		if name == "" {
+			name = item.Name
+		}
+		if item.Type != "post" {
+			r.skip(name, "Not a post ("+item.Type+")")
+			continue
+		}
+		if item.Status == "trash" || item.Status == "auto-draft" {
+			r.skip(name, "In the trash")
+			continue
+		}
+
+		j := model.Journal{Title: strings.TrimSpace(item.Title), Content: item.content(false), Excerpt: item.content(true)}
+		if j.Title == "" {
+			r.skip(name, "No title")
+			continue
+		}
+		if j.Content == "" {
+			r.skip(name, "No content")
+			continue
+		}
+		date, ok := item.date()
+		if !ok {
+			date = time.Now()
+		}
+		j.Date = date.Format("2006-01-02")
+
+		// Slugs with characters outside ASCII are exported encoded
+		unescaped, err := url.PathUnescape(item.Name)
+		if err != nil {
+			unescaped = item.Name
+		}
+		if j.Slug = slug(unescaped); j.Slug == "" {
+			j.Slug = slug(j.Title)
+		}
+		if j.Slug == "" {
+			r.skip(name, "No valid slug")
+			continue
+		}
+		if r.taken(j.Slug) {
+			continue
+		}
+
+		switch item.Status {
+		case "draft", "pending":
+			j.Status = model.JournalStatusDraft
+		case "future":
+			if publishAt, err := time.Parse(wordPressTimeFormat, item.PostDateGMT); err == nil {
+				j.Schedule(publishAt)
+			} else {
+				j.Status = model.JournalStatusDraft
+			}
+		case "private":
+			j.Visibility = model.JournalVisibilityPrivate
+		}
+		if item.Password != "" {
+			if err := j.SetPassword(item.Password); err != nil {
+				return r.report, err
+			}
+		}
+		if item.CommentStatus == "closed" {
+			j.Comments = model.JournalCommentsClosed
+		}
+		j.Pinned = item.Sticky == "1"
+		if terms := item.categories(); len(terms) > 0 {
+			category, found := categories[terms[0].Nicename]
+			if !found {
+				if category, err = r.category(terms[0].Name, 0); err != nil {
+					return r.report, err
+				}
+			}
+			j.CategoryID = category.ID
+			for _, term := range terms[1:] {
+				r.drop(name, "Category \""+term.Name+"\" as entries are filed under one category")
+			}
+		}
+		j.Tags = r.tags(name, item.tags())
+		if j, err = r.save(j); err != nil {
+			return r.report, err
+		}
+
+		for _, comment := range item.Comments {
+			c := model.Comment{JournalID: j.ID, Author: comment.Author, Email: comment.AuthorEmail, URL: comment.AuthorURL, Content: comment.Content, IP: comment.AuthorIP}
+			if comment.Type != "" && comment.Type != "comment" {
+				continue
+			}
+			switch comment.Approved {
+			case "1":
+				c.Status = model.CommentStatusApproved
+			case "0":
+				c.Status = model.CommentStatusPending
+			case "spam":
+				c.Status = model.CommentStatusSpam
+			default:
+				continue
+			}
+			if created, err := time.Parse(wordPressTimeFormat, comment.DateGMT); err == nil && created.Year() > 1 {
+				c.CreatedAt = created.Format(wordPressTimeFormat)
+			}
+			r.report.Comments++
+			if dryRun {
+				continue
+			}
+			if _, err := cs.Save(c); err != nil {
+				return r.report, err
+			}
+		}
+	}
+
+	return r.report, nil
+}
+
+// wordPressCategories Create the categories listed in the export, parents before the categories nested beneath them,
+// returning them by the slug WordPress gave them
+func (r *run) wordPressCategories(listed []wxrCategory) (map[string]model.Category, error) {
+	categories := map[string]model.Category{}
+	remaining := listed
+	for len(remaining) > 0 {
+		waiting := []wxrCategory{}
+		for _, c := range remaining {
+			parent, found := categories[c.Parent]
+			if c.Parent != "" && !found {
+				waiting = append(waiting, c)
+				continue
+			}
+			category, err := r.category(strings.TrimSpace(c.Name), parent.ID)
+			if err != nil {
+				return categories, err
+			}
+			categories[c.Nicename] = category
+		}
+
+		// Parents missing from the export leave their children at the top
+		if len(waiting) == len(remaining) {
+			for i := range waiting {
+				waiting[i].Parent = ""
+			}
+		}
+		remaining = waiting
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+const testExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>My Blog</title>
+	<wp:category><wp:term_id>2</wp:term_id><wp:category_nicename>europe</wp:category_nicename><wp:category_parent>travel</wp:category_parent><wp:cat_name><![CDATA[Europe]]></wp:cat_name></wp:category>
+	<wp:category><wp:term_id>1</wp:term_id><wp:category_nicename>travel</wp:category_nicename><wp:category_parent></wp:category_parent><wp:cat_name><![CDATA[Travel]]></wp:cat_name></wp:category>
+	<item>
+		<title>Trip to Rome</title>
+		<pubDate>Sat, 04 May 2019 10:00:00 +0000</pubDate>
+		<content:encoded><![CDATA[<p>We went to Rome.</p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[A short trip.]]></excerpt:encoded>
+		<wp:post_date>2019-05-04 11:00:00</wp:post_date>
+		<wp:post_date_gmt>2019-05-04 10:00:00</wp:post_date_gmt>
+		<wp:comment_status>closed</wp:comment_status>
+		<wp:post_name>trip-to-rome</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<wp:post_password></wp:post_password>
+		<wp:is_sticky>1</wp:is_sticky>
+		<category domain="post_tag" nicename="food"><![CDATA[Food]]></category>
+		<category domain="category" nicename="europe"><![CDATA[Europe]]></category>
+		<category domain="post_tag" nicename="italy"><![CDATA[Italy]]></category>
+		<category domain="category" nicename="travel"><![CDATA[Travel]]></category>
+		<wp:comment>
+			<wp:comment_author><![CDATA[Reader]]></wp:comment_author>
+			<wp:comment_author_email>reader@example.com</wp:comment_author_email>
+			<wp:comment_date_gmt>2019-05-05 09:30:00</wp:comment_date_gmt>
+			<wp:comment_content><![CDATA[Lovely!]]></wp:comment_content>
+			<wp:comment_approved>1</wp:comment_approved>
+			<wp:comment_type>comment</wp:comment_type>
+		</wp:comment>
+		<wp:comment>
+			<wp:comment_author><![CDATA[Spammer]]></wp:comment_author>
+			<wp:comment_content><![CDATA[Buy now]]></wp:comment_content>
+			<wp:comment_approved>spam</wp:comment_approved>
+		</wp:comment>
+		<wp:comment>
+			<wp:comment_author><![CDATA[Other Blog]]></wp:comment_author>
+			<wp:comment_content><![CDATA[Linked here]]></wp:comment_content>
+			<wp:comment_approved>1</wp:comment_approved>
+			<wp:comment_type>pingback</wp:comment_type>
+		</wp:comment>
+	</item>
+	<item>
+		<title>Caf&eacute; Notes</title>
+		<content:encoded><![CDATA[Some notes]]></content:encoded>
+		<wp:post_date>0000-00-00 00:00:00</wp:post_date>
+		<wp:post_name></wp:post_name>
+		<wp:status>draft</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+	</item>
+	<item>
+		<title>About</title>
+		<content:encoded><![CDATA[About me]]></content:encoded>
+		<wp:post_name>about</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>Deleted</title>
+		<content:encoded><![CDATA[Gone]]></content:encoded>
+		<wp:post_name>deleted</wp:post_name>
+		<wp:status>trash</wp:status>
+		<wp:post_type>post</wp:post_type>
+	</item>
+	<item>
+		<title>Trip to Rome</title>
+		<content:encoded><![CDATA[Again]]></content:encoded>
+		<wp:post_name>trip-to-rome</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func TestWordPress_DryRun(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	report, err := WordPress(container, strings.NewReader(testExport), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(report.Created, []string{"trip-to-rome", "caf--notes"}) {
+		t.Errorf("Expected posts to be reported as created, got %v", report.Created)
+	}
+	if !reflect.DeepEqual(report.Categories, []string{"Travel", "Europe", "Uncategorized"}) {
+		t.Errorf("Expected parent categories to be created first, got %v", report.Categories)
+	}
+	if report.Comments != 2 {
+		t.Errorf("Expected comments other than pingbacks to be counted, got %d", report.Comments)
+	}
+	if !reflect.DeepEqual(report.Duplicates, []string{"trip-to-rome"}) {
+		t.Errorf("Expected repeated slug within the export to be a duplicate, got %v", report.Duplicates)
+	}
+	if len(report.Skipped) != 2 || report.Skipped[0].Reason != "Not a post (page)" || report.Skipped[1].Reason != "In the trash" {
+		t.Errorf("Expected pages and trashed posts to be skipped, got %v", report.Skipped)
+	}
+	if !reflect.DeepEqual(report.Dropped, []Skipped{{"Trip to Rome", `Category "Travel" as entries are filed under one category`}}) {
+		t.Errorf("Expected categories after the first to be reported as dropped, got %v", report.Dropped)
+	}
+}
+
+func TestWxrItem_tags(t *testing.T) {
+	item := wxrItem{Terms: []wxrTerm{{Domain: "post_tag", Name: "Food"}, {Domain: "category", Name: "Europe"}, {Domain: "post_tag", Name: "Italy"}}}
+	if !reflect.DeepEqual(item.tags(), []string{"Food", "Italy"}) {
+		t.Errorf("Expected tags of the post, got %v", item.tags())
+	}
+}
+
+func TestWordPress(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// Nesting needs the parent to be found once saved, which is left to the end to end tests
+	export := strings.Replace(testExport, "<wp:category_parent>travel</wp:category_parent>", "", 1)
+	report, err := WordPress(container, strings.NewReader(export), false)
+	if err != nil || len(report.Created) != 2 || report.Comments != 2 {
+		t.Fatalf("Expected posts and comments to be imported, got %v, %v", report, err)
+	}
+
+	// Failures stop the import
+	db.ErrorMode = true
+	if _, err := WordPress(container, strings.NewReader(export), false); err == nil {
+		t.Error("Expected error to be returned")
+	}
+
+	if _, err := WordPress(container, strings.NewReader("<rss><channel>"), false); err == nil {
+		t.Error("Expected error for an unreadable export")
+	}
+}
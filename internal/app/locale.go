@@ -0,0 +1,50 @@
+package app
+
+import (
+	"github.com/jamiefdhurst/journal/pkg/i18n"
+	"github.com/jamiefdhurst/journal/web"
+)
+
+// Languages Catalogs of the messages of every page in each language the journal can be read in, built into the binary
+// from web/locales
+var Languages = loadLanguages()
+
+// loadLanguages Read the catalogs built in, which are checked by the tests so can always be read
+func loadLanguages() i18n.Catalogs {
+	catalogs, err := i18n.Load(web.Files, "locales")
+	if err != nil {
+		panic(err)
+	}
+
+	return catalogs
+}
+
+// NegotiateLocale Choose the language a request is served in, being the one configured or, when none is, the one the
+// reader's browser prefers of those the journal can be read in
+func (c *Container) NegotiateLocale(acceptLanguage string) string {
+	if c.Configuration.Language != "" {
+		return c.Configuration.Language
+	}
+
+	return i18n.Negotiate(acceptLanguage, Languages.Locales(), i18n.English)
+}
+
+// T Write a message in the language of the request being served, filling in any values as fmt does
+func (c *Container) T(message string, args ...interface{}) string {
+	return Languages.Translate(c.Lang(), message, args...)
+}
+
+// N Write a message counting something in the language of the request being served, in its form for one thing or any
+// other number of them
+func (c *Container) N(count int, message string, args ...interface{}) string {
+	return Languages.Plural(c.Lang(), count, message, args...)
+}
+
+// Lang The language of the request being served, as pages give it in their lang attribute, or English outside of one
+func (c *Container) Lang() string {
+	if c == nil || c.Locale == "" {
+		return i18n.English
+	}
+
+	return c.Locale
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/jamiefdhurst/journal/pkg/i18n"
+	"github.com/jamiefdhurst/journal/web"
+)
+
+// localeVerbs Values filled into messages, which translations must keep in the same order
+var localeVerbs = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-z]`)
+
+// namedMessages Messages written by name rather than as text in a template or call, such as those of months and days
+// written by dateFormat, or the roles of users
+var namedMessages = []string{
+	"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December",
+	"Jan", "Feb", "Mar", "Apr", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+	"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday",
+	"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun",
+	"admin", "editor", "reader",
+}
+
+// templateMessages Messages given as text to the functions of templates that translate them
+func templateMessages(t *testing.T) map[string]bool {
+	found := map[string]bool{}
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, child := range n.Nodes {
+					walk(child)
+				}
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n != nil {
+				for _, command := range n.Cmds {
+					walk(command)
+				}
+			}
+		case *parse.CommandNode:
+			translates := false
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok {
+				translates = ident.Ident == "t" || ident.Ident == "thtml" || ident.Ident == "tn" || ident.Ident == "dateFormat"
+			}
+			for _, arg := range n.Args {
+				// The message is the first text given, any after it being values filled into it
+				if text, ok := arg.(*parse.StringNode); ok && translates {
+					found[text.Text] = true
+					translates = false
+				}
+				walk(arg)
+			}
+		}
+	}
+
+	templates, _ := fs.Sub(web.Files, "templates")
+	names, _ := fs.Glob(templates, "*.tmpl")
+	more, _ := fs.Glob(templates, "*/*.tmpl")
+	for _, name := range append(names, more...) {
+		parsed, err := template.New(name).Funcs(template.FuncMap((&Container{}).TemplateFuncs())).ParseFS(templates, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, defined := range parsed.Templates() {
+			if defined.Tree != nil {
+				walk(defined.Tree.Root)
+			}
+		}
+	}
+
+	return found
+}
+
+// codeMessages Messages given as text, or as constants, to the functions of the journal that translate them and those
+// leaving them for the user to be shown
+func codeMessages(t *testing.T) map[string]bool {
+	calls := map[string]bool{"flash.Success": true, "flash.Error": true, "flash.Info": true, ".T": true, ".N": true, "signInFailed": true, "redirectFailed": true, "fail": true}
+	found := map[string]bool{}
+	err := filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		packages, err := parser.ParseDir(token.NewFileSet(), path, func(info os.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range packages {
+			constants := map[string]string{}
+			for _, file := range pkg.Files {
+				ast.Inspect(file, func(node ast.Node) bool {
+					if spec, ok := node.(*ast.ValueSpec); ok {
+						for i, name := range spec.Names {
+							if i < len(spec.Values) {
+								if text, ok := spec.Values[i].(*ast.BasicLit); ok && text.Kind == token.STRING {
+									constants[name.Name], _ = strconv.Unquote(text.Value)
+								}
+							}
+						}
+					}
+					return true
+				})
+			}
+			for _, file := range pkg.Files {
+				ast.Inspect(file, func(node ast.Node) bool {
+					call, ok := node.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					name := ""
+					switch fun := call.Fun.(type) {
+					case *ast.Ident:
+						name = fun.Name
+					case *ast.SelectorExpr:
+						name = "." + fun.Sel.Name
+						if pkg, ok := fun.X.(*ast.Ident); ok && pkg.Name == "flash" {
+							name = "flash" + name
+						}
+					}
+					if !calls[name] {
+						return true
+					}
+					for _, arg := range call.Args {
+						switch a := arg.(type) {
+						case *ast.BasicLit:
+							if a.Kind == token.STRING {
+								text, _ := strconv.Unquote(a.Value)
+								found[text] = true
+							}
+						case *ast.Ident:
+							if text, ok := constants[a.Name]; ok && a.Obj != nil && a.Obj.Kind == ast.Con {
+								found[text] = true
+							}
+						}
+					}
+					return true
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return found
+}
+
+func TestLanguages(t *testing.T) {
+	if locales := Languages.Locales(); len(locales) < 2 || locales[0] != i18n.English || locales[1] != "fr" {
+		t.Fatalf("Expected English and French to be built in, got %v", locales)
+	}
+	english := Languages[i18n.English]
+
+	used := map[string]bool{}
+	for message := range templateMessages(t) {
+		used[message] = true
+	}
+	for message := range codeMessages(t) {
+		used[message] = true
+	}
+	for _, message := range namedMessages {
+		used[message] = true
+	}
+	for message := range used {
+		if _, ok := english.Messages[message]; !ok {
+			t.Errorf("Expected '%s' to be in the English catalog", message)
+		}
+	}
+
+	// Test every catalog translates each message in English, filling in the same values
+	for _, locale := range Languages.Locales() {
+		catalog := Languages[locale]
+		if catalog.Name == "" {
+			t.Errorf("Expected %s to be named", locale)
+		}
+		for message := range english.Messages {
+			translated, ok := catalog.Messages[message]
+			if !ok {
+				t.Errorf("Expected '%s' to be translated in %s", message, locale)
+				continue
+			}
+			if strings.Join(localeVerbs.FindAllString(translated.Other, -1), "") != strings.Join(localeVerbs.FindAllString(message, -1), "") {
+				t.Errorf("Expected '%s' in %s to fill in the values of '%s'", translated.Other, locale, message)
+			}
+		}
+		for message := range catalog.Messages {
+			if _, ok := english.Messages[message]; !ok {
+				t.Errorf("Expected '%s' in %s to be in the English catalog", message, locale)
+			}
+		}
+	}
+}
+
+func TestContainer_NegotiateLocale(t *testing.T) {
+	container := &Container{Configuration: DefaultConfiguration()}
+	tables := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "en"},
+		{"fr-FR,fr;q=0.9,en;q=0.8", "fr"},
+		{"de-DE,de;q=0.9", "en"},
+		{"de, en;q=0.5, fr;q=0.8", "fr"},
+	}
+	for _, table := range tables {
+		if actual := container.NegotiateLocale(table.acceptLanguage); actual != table.expected {
+			t.Errorf("Expected %s for '%s', got %s", table.expected, table.acceptLanguage, actual)
+		}
+	}
+
+	container.Configuration.Language = "fr"
+	if actual := container.NegotiateLocale("en-GB"); actual != "fr" {
+		t.Errorf("Expected the language configured to be chosen, got %s", actual)
+	}
+}
+
+func TestContainer_T(t *testing.T) {
+	var none *Container
+	if none.Lang() != "en" || none.T("Search") != "Search" {
+		t.Error("Expected English without a container")
+	}
+	container := &Container{Locale: "fr"}
+	if container.Lang() != "fr" || container.T("Signed in as %s", "alice") != "Connecté en tant que alice" {
+		t.Errorf("Expected message in French, got %s", container.T("Signed in as %s", "alice"))
+	}
+	if container.N(1, "%d entries updated.") != "1 article mis à jour." || container.N(3, "Every %d days") != "Tous les 3 jours" || container.N(1, "Every %d days") != "Tous les jours" {
+		t.Errorf("Expected counts in French, got %s, %s", container.N(1, "%d entries updated."), container.N(1, "Every %d days"))
+	}
+}
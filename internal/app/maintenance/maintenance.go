@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// Guard Refuses anything that would change the journal while it is in maintenance, such as during a backup or migration,
+// still serving its pages and letting admins sign in to take it out of maintenance
+type Guard struct {
+	Router      *pkgrouter.Router
+	Unavailable controller.Controller
+}
+
+// NewGuard Create the guard for the journals served by the given router, answering what it refuses with the given page
+func NewGuard(router *pkgrouter.Router, unavailable controller.Controller) *Guard {
+	return &Guard{Router: router, Unavailable: unavailable}
+}
+
+// allowed Paths that may still be posted to in maintenance, being those needed to sign in and take the journal out of it
+var allowed = map[string]bool{"/admin/maintenance": true, "/login": true, "/logout": true}
+
+// Middleware Answer requests that would change the journal with a 503 while it is in maintenance
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served := g.Router.ContainerFor(request)
+		if container, ok := served.(*app.Container); ok && container != nil && container.Maintenance.On() && Refused(request) {
+			unavailable := controller.New(g.Unavailable)
+			unavailable.Init(served, []string{})
+			unavailable.Run(response, request)
+			return
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// Refused Whether a request would change the journal, and so is refused in maintenance
+func Refused(request *http.Request) bool {
+	if request.Method == http.MethodGet || request.Method == http.MethodHead || request.Method == http.MethodOptions {
+		return false
+	}
+
+	return !allowed[request.URL.Path]
+}
+
+// Switch Put the journal into maintenance or take it out, logging the change
+func Switch(container *app.Container, on bool) {
+	container.Maintenance.Switch(on)
+	report(container.Log(), on)
+}
+
+// Listen Toggle maintenance each time the journal is sent the signal asking for it, until the returned function is called
+func Listen(container *app.Container) func() {
+	if len(toggleSignals) == 0 {
+		return func() {}
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, toggleSignals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-received:
+				report(container.Log(), container.Maintenance.Toggle())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(received)
+		close(done)
+	}
+}
+
+// report Log which way maintenance was switched
+func report(logger *logging.Logger, on bool) {
+	if on {
+		logger.Info("In maintenance, refusing changes until it is switched off")
+	} else {
+		logger.Info("Out of maintenance, accepting changes again")
+	}
+}
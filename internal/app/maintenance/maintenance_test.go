@@ -0,0 +1,60 @@
+package maintenance
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestGuard_Middleware(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	unavailable := &controller.MockController{}
+	guard := NewGuard(&pkgrouter.Router{Container: container}, unavailable)
+	served := false
+	handler := guard.Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served = true
+	}))
+	send := func(method string, path string) bool {
+		served = false
+		unavailable.HasRun = false
+		request, _ := http.NewRequest(method, path, nil)
+		handler.ServeHTTP(controller.NewMockResponse(), request)
+		if served == unavailable.HasRun {
+			t.Fatalf("Expected %s %s to be either served or refused", method, path)
+		}
+		return served
+	}
+
+	// Test changes are served without a switch, or when it is off
+	if !send("POST", "/new") {
+		t.Error("Expected changes to be served without a maintenance switch")
+	}
+	container.Maintenance = app.NewMaintenance(false)
+	if !send("POST", "/new") {
+		t.Error("Expected changes to be served out of maintenance")
+	}
+
+	// Test changes are refused in maintenance, other than to sign in and switch it off
+	container.Maintenance.Switch(true)
+	if send("POST", "/new") || send("PUT", "/api/v1/post") || send("DELETE", "/api/admin/users/alice") {
+		t.Error("Expected changes to be refused in maintenance")
+	}
+	if !send("GET", "/") || !send("HEAD", "/feed.rss") || !send("POST", "/login") || !send("POST", "/logout") || !send("POST", "/admin/maintenance") {
+		t.Error("Expected pages, signing in and switching maintenance off to be served in maintenance")
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	container := &app.Container{Maintenance: app.NewMaintenance(false)}
+	Switch(container, true)
+	if !container.Maintenance.On() {
+		t.Error("Expected maintenance to be switched on")
+	}
+	Switch(container, false)
+	if container.Maintenance.On() {
+		t.Error("Expected maintenance to be switched off")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package maintenance
+
+import (
+	"os"
+	"syscall"
+)
+
+// toggleSignals SIGUSR1 switches maintenance on, or off again
+var toggleSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,28 @@
+//go:build !windows
+// +build !windows
+
+package maintenance
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+func TestListen(t *testing.T) {
+	container := &app.Container{Maintenance: app.NewMaintenance(false)}
+	stop := Listen(container)
+	defer stop()
+
+	process, _ := os.FindProcess(os.Getpid())
+	process.Signal(syscall.SIGUSR1)
+	for i := 0; i < 100 && !container.Maintenance.On(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !container.Maintenance.On() {
+		t.Error("Expected the signal to toggle maintenance on")
+	}
+}
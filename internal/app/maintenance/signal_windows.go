@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package maintenance
+
+import "os"
+
+// toggleSignals Windows has no signal to spare, so maintenance can only be switched from the admin pages
+var toggleSignals = []os.Signal{}
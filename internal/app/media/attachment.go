@@ -0,0 +1,86 @@
+package media
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+// AttachmentDir is the directory within the media path holding files attached to entries
+const AttachmentDir = "attachments"
+
+// ErrEmpty is returned when an attached file has no content
+var ErrEmpty = errors.New("File is empty")
+
+var validAttachment = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// AttachmentLimit The largest file, in bytes, that may be attached to an entry
+func AttachmentLimit(container *app.Container) int64 {
+	return int64(container.Configuration.AttachmentLimit) << 20
+}
+
+// StoreAttachment Save a file attached to an entry under a random name, returning that name, its size and content type
+func StoreAttachment(container *app.Container, original string, source io.Reader) (string, int64, string, error) {
+	limit := AttachmentLimit(container)
+	data, err := ioutil.ReadAll(io.LimitReader(source, limit+1))
+	if err != nil {
+		return "", 0, "", err
+	}
+	if int64(len(data)) > limit {
+		return "", 0, "", ErrTooLarge
+	}
+	if len(data) == 0 {
+		return "", 0, "", ErrEmpty
+	}
+
+	// The name given decides the type where it is known, as sniffing cannot tell most documents apart
+	contentType := mime.TypeByExtension(filepath.Ext(original))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", 0, "", err
+	}
+	name := hex.EncodeToString(random)
+
+	dir := filepath.Join(container.MediaPath(), AttachmentDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return "", 0, "", err
+	}
+
+	return name, int64(len(data)), contentType, nil
+}
+
+// FindAttachment Get the location on disk of an attached file, refusing any name that was not produced by StoreAttachment
+func FindAttachment(container *app.Container, name string) (string, bool) {
+	if !validAttachment.MatchString(name) {
+		return "", false
+	}
+	path := filepath.Join(container.MediaPath(), AttachmentDir, name)
+
+	return path, exists(path)
+}
+
+// RemoveAttachment Delete an attached file from disk, if it is still there
+func RemoveAttachment(container *app.Container, name string) error {
+	path, ok := FindAttachment(container, name)
+	if !ok {
+		return nil
+	}
+
+	return os.Remove(path)
+}
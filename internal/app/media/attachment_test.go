@@ -0,0 +1,81 @@
+package media
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestStoreAttachment(t *testing.T) {
+	container := testContainer(t)
+	container.Configuration.AttachmentLimit = 1
+
+	name, size, contentType, err := StoreAttachment(container, "../Report.pdf", strings.NewReader("%PDF-1.4 report"))
+	if err != nil {
+		t.Fatalf("Expected file to be stored, got %s", err)
+	}
+	if size != 15 || contentType != "application/pdf" {
+		t.Errorf("Expected size and type from the name, got %d and %s", size, contentType)
+	}
+	path, ok := FindAttachment(container, name)
+	if !ok {
+		t.Fatal("Expected stored file to be found")
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "%PDF-1.4 report" {
+		t.Errorf("Expected contents to be written, got %s", data)
+	}
+
+	// Names are random rather than taken from the upload
+	second, _, _, _ := StoreAttachment(container, "Report.pdf", strings.NewReader("%PDF-1.4 report"))
+	if second == name || strings.Contains(second, "report") {
+		t.Errorf("Expected a unique random name, got %s", second)
+	}
+
+	// Unknown extensions fall back to the content
+	_, _, contentType, _ = StoreAttachment(container, "notes", strings.NewReader("Plain notes"))
+	if !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Expected detected type, got %s", contentType)
+	}
+
+	// Attachments are not listed as images
+	if len(List(container)) != 0 {
+		t.Error("Expected attachments to be kept out of the media library")
+	}
+}
+
+func TestStoreAttachment_Errors(t *testing.T) {
+	container := testContainer(t)
+	container.Configuration.AttachmentLimit = 1
+
+	if _, _, _, err := StoreAttachment(container, "big.zip", bytes.NewReader(make([]byte, 1<<20+1))); err != ErrTooLarge {
+		t.Errorf("Expected too large error, got %v", err)
+	}
+	if _, _, _, err := StoreAttachment(container, "empty.txt", strings.NewReader("")); err != ErrEmpty {
+		t.Errorf("Expected empty error, got %v", err)
+	}
+	if _, _, _, err := StoreAttachment(container, "exact.bin", bytes.NewReader(make([]byte, 1<<20))); err != nil {
+		t.Errorf("Expected file at the limit to be stored, got %s", err)
+	}
+}
+
+func TestFindAttachment(t *testing.T) {
+	container := testContainer(t)
+	name, _, _, _ := StoreAttachment(container, "notes.txt", strings.NewReader("Notes"))
+
+	for _, invalid := range []string{"", "../journal.db", "0123456789abcdef0123456789abcdef/..", strings.ToUpper(name), "0123456789abcdef0123456789abcdef"} {
+		if _, ok := FindAttachment(container, invalid); ok {
+			t.Errorf("Expected %s not to be found", invalid)
+		}
+	}
+
+	if err := RemoveAttachment(container, name); err != nil {
+		t.Errorf("Expected file to be removed, got %s", err)
+	}
+	if _, ok := FindAttachment(container, name); ok {
+		t.Error("Expected removed file not to be found")
+	}
+	if err := RemoveAttachment(container, name); err != nil {
+		t.Error("Expected removing a missing file to be ignored")
+	}
+}
@@ -0,0 +1,127 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+// MaxSize is the largest file, in bytes, that may be uploaded
+const MaxSize = 10 << 20
+
+// Path is where uploaded files are served from
+const Path = "/media"
+
+// Types Image content types that may be uploaded, with the extension each is stored under
+var Types = map[string]string{
+	"image/gif":  ".gif",
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// Upload errors
+var (
+	ErrTooLarge    = errors.New("File is larger than the maximum upload size")
+	ErrUnsupported = errors.New("Only GIF, JPEG, PNG and WebP images can be uploaded")
+)
+
+var (
+	unsafeChars = regexp.MustCompile(`[^a-z0-9\-]+`)
+	validName   = regexp.MustCompile(`^[a-z0-9\-]+\.(gif|jpg|png|webp)$`)
+)
+
+// File An uploaded file within the media directory
+type File struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+	URL      string
+}
+
+// Markdown The snippet used to embed the file into an entry
+func (f File) Markdown() string {
+	return "![" + strings.TrimSuffix(f.Name, filepath.Ext(f.Name)) + "](" + f.URL + ")"
+}
+
+// Store Save an uploaded image into the media directory under a unique, safe name
+func Store(container *app.Container, original string, source io.Reader) (File, error) {
+	// Read one byte more than allowed to tell whether the limit was exceeded
+	data, err := ioutil.ReadAll(io.LimitReader(source, MaxSize+1))
+	if err != nil {
+		return File{}, err
+	}
+	if len(data) > MaxSize {
+		return File{}, ErrTooLarge
+	}
+	ext, ok := Types[http.DetectContentType(data)]
+	if !ok {
+		return File{}, ErrUnsupported
+	}
+
+	dir := container.MediaPath()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return File{}, err
+	}
+	base := strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))), "-"), "-")
+	if base == "" {
+		base = "image"
+	}
+	name := base + ext
+	for i := 1; exists(filepath.Join(dir, name)); i++ {
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return File{}, err
+	}
+
+	return describe(container, name, int64(len(data)), time.Now()), nil
+}
+
+// List Get every uploaded file, newest first
+func List(container *app.Container) []File {
+	files := []File{}
+	infos, err := ioutil.ReadDir(container.MediaPath())
+	if err != nil {
+		return files
+	}
+	for _, info := range infos {
+		if !info.IsDir() && validName.MatchString(info.Name()) {
+			files = append(files, describe(container, info.Name(), info.Size(), info.ModTime()))
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Modified.After(files[j].Modified)
+	})
+
+	return files
+}
+
+// Find Get the location on disk of an uploaded file, refusing any name that was not produced by Store
+func Find(container *app.Container, name string) (string, bool) {
+	if !validName.MatchString(name) {
+		return "", false
+	}
+	path := filepath.Join(container.MediaPath(), name)
+
+	return path, exists(path)
+}
+
+func describe(container *app.Container, name string, size int64, modified time.Time) File {
+	return File{Name: name, Size: size, Modified: modified, URL: container.BasePath + Path + "/" + name}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func testContainer(t *testing.T) *app.Container {
+	dir, err := ioutil.TempDir("", "media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	configuration := app.DefaultConfiguration()
+	configuration.MediaPath = dir
+
+	return &app.Container{Configuration: configuration, BasePath: "/base"}
+}
+
+func TestStore(t *testing.T) {
+	container := testContainer(t)
+
+	file, err := Store(container, "../My Holiday Photo.PNG", bytes.NewReader(png))
+	if err != nil {
+		t.Fatalf("Expected image to be stored, got %s", err)
+	}
+	if file.Name != "my-holiday-photo.png" || file.URL != "/base/media/my-holiday-photo.png" {
+		t.Errorf("Expected safe name and URL, got %s and %s", file.Name, file.URL)
+	}
+	if file.Markdown() != "![my-holiday-photo](/base/media/my-holiday-photo.png)" {
+		t.Errorf("Expected Markdown snippet, got %s", file.Markdown())
+	}
+
+	// Names are never reused
+	second, _ := Store(container, "my holiday photo.png", bytes.NewReader(png))
+	if second.Name != "my-holiday-photo-1.png" {
+		t.Errorf("Expected a unique name, got %s", second.Name)
+	}
+
+	// Content decides the type, not the extension
+	unnamed, _ := Store(container, "???.gif", bytes.NewReader(png))
+	if unnamed.Name != "image.png" {
+		t.Errorf("Expected fallback name with detected extension, got %s", unnamed.Name)
+	}
+
+	if _, err := Store(container, "script.png", strings.NewReader("<script>alert(1)</script>")); err != ErrUnsupported {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+	if _, err := Store(container, "big.png", bytes.NewReader(append(png, make([]byte, MaxSize)...))); err != ErrTooLarge {
+		t.Errorf("Expected too large error, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	container := testContainer(t)
+	if len(List(container)) != 0 {
+		t.Error("Expected empty list")
+	}
+
+	Store(container, "one.png", bytes.NewReader(png))
+	ioutil.WriteFile(container.MediaPath()+"/notes.txt", []byte("ignored"), 0644)
+	files := List(container)
+	if len(files) != 1 || files[0].Name != "one.png" || files[0].Size != int64(len(png)) {
+		t.Errorf("Expected only the stored image to be listed, got %v", files)
+	}
+}
+
+func TestFind(t *testing.T) {
+	container := testContainer(t)
+	Store(container, "one.png", bytes.NewReader(png))
+
+	if path, ok := Find(container, "one.png"); !ok || !strings.HasSuffix(path, "one.png") {
+		t.Error("Expected stored image to be found")
+	}
+	for _, name := range []string{"missing.png", "../journal.db", "one.png/..", "ONE.png"} {
+		if _, ok := Find(container, name); ok {
+			t.Errorf("Expected %s not to be found", name)
+		}
+	}
+}
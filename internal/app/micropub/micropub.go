@@ -0,0 +1,499 @@
+package micropub
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/adapter/indieauth"
+)
+
+// Scopes a token needs for each action
+const (
+	ScopeCreate = "create"
+	ScopeUpdate = "update"
+	ScopeDelete = "delete"
+)
+
+// Path Where the Micropub endpoint is served
+const Path = "/micropub"
+
+// WriteError Send a failed request back to the client, treating anything unexpected as a server error
+func WriteError(response http.ResponseWriter, err error) {
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Status: http.StatusInternalServerError, Code: "server_error", Description: err.Error()}
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(e.Status)
+	json.NewEncoder(response).Encode(e)
+}
+
+// Error A failed request, described as the Micropub specification asks
+type Error struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func invalid(description string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: "invalid_request", Description: description}
+}
+
+// Properties Values of an entry's microformats properties, each of which may be repeated
+type Properties map[string][]interface{}
+
+// Text Get the first value of a property as text, taking the HTML or plain value of an embedded object
+func (p Properties) Text(name string) string {
+	if len(p[name]) == 0 {
+		return ""
+	}
+	switch value := p[name][0].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[string]interface{}:
+		for _, key := range []string{"html", "value"} {
+			if text, ok := value[key].(string); ok {
+				return strings.TrimSpace(text)
+			}
+		}
+	}
+
+	return ""
+}
+
+// Strings Get every value of a property given as text
+func (p Properties) Strings(name string) []string {
+	values := []string{}
+	for _, v := range p[name] {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			values = append(values, strings.TrimSpace(s))
+		}
+	}
+
+	return values
+}
+
+// Request What a client has asked for, creating an entry or acting on the one at URL
+type Request struct {
+	Action     string
+	URL        string
+	Type       string
+	Properties Properties
+	Replace    Properties
+	Add        Properties
+	Delete     Properties
+	Remove     []string
+}
+
+type jsonRequest struct {
+	Type       []string        `json:"type"`
+	Action     string          `json:"action"`
+	URL        string          `json:"url"`
+	Properties Properties      `json:"properties"`
+	Replace    Properties      `json:"replace"`
+	Add        Properties      `json:"add"`
+	Delete     json.RawMessage `json:"delete"`
+}
+
+// Largest requests read, as JSON or as a form, which may carry a photo
+const (
+	MaxJSONSize = 1 << 20
+	MaxFormSize = 32 << 20
+)
+
+// MaxSize Largest request read for the type of body sent, for its reader to be limited to
+func MaxSize(request *http.Request) int64 {
+	if isJSON(request) {
+		return MaxJSONSize
+	}
+
+	return MaxFormSize + 1<<20
+}
+
+// ParseRequest Read a request sent either as a form or as JSON
+func ParseRequest(request *http.Request) (Request, error) {
+	if isJSON(request) {
+		return parseJSON(request)
+	}
+	if err := request.ParseMultipartForm(MaxFormSize); err != nil && err != http.ErrNotMultipart {
+		return Request{}, invalid("The form could not be read")
+	}
+
+	r := Request{Action: request.PostForm.Get("action"), URL: request.PostForm.Get("url"), Properties: Properties{}}
+	if h := request.PostForm.Get("h"); h != "" {
+		r.Type = "h-" + h
+	}
+	for key, values := range request.PostForm {
+		switch key {
+		case "access_token", "action", "h", "url":
+			continue
+		}
+		name := strings.TrimSuffix(key, "[]")
+		for _, v := range values {
+			r.Properties[name] = append(r.Properties[name], v)
+		}
+	}
+	if r.Action == "" {
+		r.Action = "create"
+	}
+
+	return r, nil
+}
+
+func isJSON(request *http.Request) bool {
+	return strings.HasPrefix(request.Header.Get("Content-Type"), "application/json")
+}
+
+func parseJSON(request *http.Request) (Request, error) {
+	body := jsonRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		return Request{}, invalid("The JSON could not be read")
+	}
+	r := Request{Action: body.Action, URL: body.URL, Properties: body.Properties, Replace: body.Replace, Add: body.Add}
+	if len(body.Type) > 0 {
+		r.Type = body.Type[0]
+	}
+	if r.Action == "" {
+		r.Action = "create"
+	}
+	if r.Properties == nil {
+		r.Properties = Properties{}
+	}
+
+	// Whole properties are deleted by name, single values by listing them against their property
+	if len(body.Delete) > 0 {
+		if json.Unmarshal(body.Delete, &r.Remove) != nil && json.Unmarshal(body.Delete, &r.Delete) != nil {
+			return r, invalid("The properties to delete could not be read")
+		}
+	}
+
+	return r, nil
+}
+
+// Authorize Check an access token grants a scope, or is valid at all when no scope is given, taking either a write
+// token issued by the journal to an editor or an IndieAuth token issued to the journal's own URL, and get who it acts
+// for, an admin without an ID for the admin token and IndieAuth
+func Authorize(container *app.Container, accessToken string, scope string) (model.User, error) {
+	owner := model.User{Username: "admin", Role: model.RoleAdmin}
+	if accessToken == "" {
+		return model.User{}, &Error{Status: http.StatusUnauthorized, Code: "unauthorized", Description: "No access token was provided"}
+	}
+	adminToken := container.Configuration.AdminToken
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(accessToken), []byte(adminToken)) == 1 {
+		return owner, nil
+	}
+	ts := model.Tokens{Container: container}
+	if token := ts.Authenticate(accessToken); token.ID > 0 {
+		if !token.HasScope(model.ScopeWrite) {
+			return model.User{}, &Error{Status: http.StatusForbidden, Code: "insufficient_scope", Description: "The token cannot write"}
+		}
+		us := model.Users{Container: container}
+		user := us.FindByID(token.UserID)
+		if !user.HasRole(model.RoleEditor) {
+			return model.User{}, &Error{Status: http.StatusForbidden, Code: "forbidden", Description: "The token belongs to someone who cannot write"}
+		}
+		return user, nil
+	}
+
+	config := container.Configuration
+	if config.IndieAuthTokenEndpoint == "" || config.URL == "" {
+		return model.User{}, &Error{Status: http.StatusForbidden, Code: "forbidden", Description: "The token was not recognised"}
+	}
+	client := indieauth.Client{Endpoint: config.IndieAuthTokenEndpoint}
+	token, err := client.Verify(accessToken)
+	if err != nil {
+		return model.User{}, &Error{Status: http.StatusForbidden, Code: "forbidden", Description: err.Error()}
+	}
+	if !SameSite(token.Me, container.URL("/")) {
+		return model.User{}, &Error{Status: http.StatusForbidden, Code: "forbidden", Description: "The token was issued to " + token.Me}
+	}
+
+	// Clients written before scopes were split up ask for post
+	if scope != "" && !token.HasScope(scope) && !(scope == ScopeCreate && token.HasScope("post")) {
+		return model.User{}, &Error{Status: http.StatusForbidden, Code: "insufficient_scope", Description: "The token does not grant " + scope}
+	}
+
+	return owner, nil
+}
+
+// SameSite Whether two URLs identify the same site, ignoring case in the host and a trailing slash
+func SameSite(a string, b string) bool {
+	first, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	second, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return first.Scheme == second.Scheme && strings.EqualFold(first.Host, second.Host) && strings.TrimSuffix(first.Path, "/") == strings.TrimSuffix(second.Path, "/")
+}
+
+// SlugFromURL Get the slug of the entry at a URL within the journal
+func SlugFromURL(container *app.Container, address string) string {
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+	slug := strings.Trim(strings.TrimPrefix(u.Path, container.BasePath), "/")
+	if !model.IsValidSlug(slug) {
+		return ""
+	}
+
+	return slug
+}
+
+// NewEntry Build the entry a create request describes, filed under the first category given, which is created if
+// needed
+func NewEntry(container *app.Container, r Request) (model.Journal, error) {
+	if r.Type != "" && r.Type != "h-entry" {
+		return model.Journal{}, invalid("Only entries can be created")
+	}
+	j := model.Journal{Date: time.Now().Format("2006-01-02")}
+	if err := apply(container, &j, r.Properties); err != nil {
+		return j, err
+	}
+	if j.Content == "" {
+		return j, invalid("An entry needs content")
+	}
+	if j.Title == "" {
+		j.Title = model.TitleFrom(j.Content)
+	}
+	if slug := model.Slugify(r.Properties.Text("mp-slug")); slug != "" && model.IsValidSlug(slug) {
+		j.Slug = slug
+	}
+
+	return j, nil
+}
+
+// Update Change an entry as an update request describes, replacing, adding to and deleting its properties in turn
+func Update(container *app.Container, j *model.Journal, r Request) error {
+	if err := apply(container, j, r.Replace); err != nil {
+		return err
+	}
+
+	for name := range r.Add {
+		switch name {
+		case "category":
+			if j.CategoryID == 0 {
+				if err := apply(container, j, Properties{"category": r.Add[name]}); err != nil {
+					return err
+				}
+			}
+		case "syndication":
+			existing := append([]string{}, j.Syndication...)
+			if err := apply(container, j, Properties{"syndication": r.Add[name]}); err != nil {
+				return err
+			}
+			j.Syndication = append(existing, j.Syndication...)
+		default:
+			if err := apply(container, j, Properties{name: r.Add[name]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range r.Remove {
+		removeProperty(j, name)
+	}
+	for name, values := range r.Delete {
+		switch name {
+		case "category":
+			cs := model.Categories{Container: container}
+			category := cs.FindByID(j.CategoryID)
+			for _, v := range values {
+				if s, ok := v.(string); ok && strings.EqualFold(s, category.Name) {
+					j.CategoryID = 0
+				}
+			}
+		case "syndication":
+			removed := Properties{name: values}.Strings(name)
+			kept := []string{}
+			for _, s := range j.Syndication {
+				if !contains(removed, s) {
+					kept = append(kept, s)
+				}
+			}
+			j.Syndication = kept
+		}
+	}
+	if j.Content == "" || j.Title == "" {
+		return invalid("An entry needs a name and content")
+	}
+
+	return nil
+}
+
+// Source Describe an entry's properties, limited to those asked for when any are given
+func Source(container *app.Container, j model.Journal, only []string) map[string]interface{} {
+	properties := Properties{
+		"name":        {j.Title},
+		"content":     {j.Content},
+		"published":   {j.GetEditableDate()},
+		"post-status": {"published"},
+		"visibility":  {j.Visibility},
+		"url":         {container.URL("/" + j.Slug)},
+	}
+	if j.IsDraft() || j.IsScheduled() {
+		properties["post-status"] = []interface{}{"draft"}
+	}
+	if j.Visibility == "" {
+		properties["visibility"] = []interface{}{model.JournalVisibilityPublic}
+	}
+	if j.Excerpt != "" {
+		properties["summary"] = []interface{}{j.Excerpt}
+	}
+	if j.CategoryID > 0 {
+		cs := model.Categories{Container: container}
+		if category := cs.FindByID(j.CategoryID); category.ID > 0 {
+			properties["category"] = []interface{}{category.Name}
+		}
+	}
+	if len(j.Syndication) > 0 {
+		for _, s := range j.Syndication {
+			properties["syndication"] = append(properties["syndication"], s)
+		}
+	}
+
+	if len(only) > 0 {
+		filtered := Properties{}
+		for _, name := range only {
+			if values, ok := properties[name]; ok {
+				filtered[name] = values
+			}
+		}
+		return map[string]interface{}{"properties": filtered}
+	}
+
+	return map[string]interface{}{"type": []string{"h-entry"}, "properties": properties}
+}
+
+// apply Copy the properties given onto an entry, leaving anything not mentioned untouched
+func apply(container *app.Container, j *model.Journal, p Properties) error {
+	if _, ok := p["name"]; ok {
+		j.Title = p.Text("name")
+	}
+	if _, ok := p["content"]; ok {
+		j.Content = p.Text("content")
+	}
+	if _, ok := p["summary"]; ok {
+		j.Excerpt = p.Text("summary")
+	}
+	if _, ok := p["published"]; ok {
+		published, err := parseDate(p.Text("published"))
+		if err != nil {
+			return invalid("The published date could not be read")
+		}
+		j.Date = published.Format("2006-01-02")
+	}
+	if _, ok := p["post-status"]; ok {
+		switch p.Text("post-status") {
+		case "draft":
+			j.Status = model.JournalStatusDraft
+		case "published":
+			j.Status = model.JournalStatusPublished
+		default:
+			return invalid("Unknown post status " + p.Text("post-status"))
+		}
+	}
+	if _, ok := p["visibility"]; ok {
+		switch visibility := p.Text("visibility"); visibility {
+		case model.JournalVisibilityPublic, model.JournalVisibilityUnlisted, model.JournalVisibilityPrivate:
+			j.Visibility = visibility
+		default:
+			return invalid("Unknown visibility " + visibility)
+		}
+	}
+	if _, ok := p["syndication"]; ok {
+		syndication := p.Strings("syndication")
+		for _, s := range syndication {
+			if !model.IsValidLinkURL(s) {
+				return invalid("Not a valid syndication URL: " + s)
+			}
+		}
+		j.Syndication = syndication
+	}
+	if names := p.Strings("category"); len(names) > 0 {
+		category, err := findCategory(container, names[0])
+		if err != nil {
+			return err
+		}
+		j.CategoryID = category.ID
+	}
+
+	return nil
+}
+
+// removeProperty Remove a whole property from an entry
+func removeProperty(j *model.Journal, name string) {
+	switch name {
+	case "summary":
+		j.Excerpt = ""
+	case "category":
+		j.CategoryID = 0
+	case "syndication":
+		j.Syndication = []string{}
+	}
+}
+
+// findCategory Find a category by name, creating it when there is none
+func findCategory(container *app.Container, name string) (model.Category, error) {
+	cs := model.Categories{Container: container}
+	for _, c := range cs.FetchAll() {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+
+	return cs.Save(model.Category{Name: name})
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, format := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func contains(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Config Describe what the endpoint supports to clients that ask
+func Config() map[string]interface{} {
+	return map[string]interface{}{
+		"syndicate-to": []string{},
+		"post-types":   []map[string]string{{"type": "note", "name": "Note"}, {"type": "article", "name": "Article"}},
+	}
+}
+
+// Categories Get the names of every category, which clients can offer when posting
+func Categories(container *app.Container) []string {
+	cs := model.Categories{Container: container}
+	names := []string{}
+	for _, c := range cs.FetchAll() {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
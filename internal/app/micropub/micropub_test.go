@@ -0,0 +1,234 @@
+package micropub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestParseRequest(t *testing.T) {
+	form := url.Values{"h": {"entry"}, "content": {"Hello"}, "category[]": {"Travel", "Food"}, "access_token": {"abc123"}}
+	request, _ := http.NewRequest("POST", "/micropub", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r, err := ParseRequest(request)
+	if err != nil || r.Action != "create" || r.Type != "h-entry" || r.Properties.Text("content") != "Hello" || len(r.Properties.Strings("category")) != 2 {
+		t.Errorf("Expected form to be read, got %v, %v", r, err)
+	}
+	if _, ok := r.Properties["access_token"]; ok {
+		t.Error("Expected access token not to be read as a property")
+	}
+
+	request, _ = http.NewRequest("POST", "/micropub", strings.NewReader(`{"type":["h-entry"],"properties":{"name":["Trip"],"content":[{"html":"<p>Away</p>"}]}}`))
+	request.Header.Set("Content-Type", "application/json")
+	r, err = ParseRequest(request)
+	if err != nil || r.Type != "h-entry" || r.Properties.Text("name") != "Trip" || r.Properties.Text("content") != "<p>Away</p>" {
+		t.Errorf("Expected JSON to be read, got %v, %v", r, err)
+	}
+
+	request, _ = http.NewRequest("POST", "/micropub", strings.NewReader(`{"action":"update","url":"https://example.com/trip","replace":{"name":["Trip"]},"delete":["summary"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	r, err = ParseRequest(request)
+	if err != nil || r.Action != "update" || r.Replace.Text("name") != "Trip" || len(r.Remove) != 1 || r.Remove[0] != "summary" {
+		t.Errorf("Expected update to be read, got %v, %v", r, err)
+	}
+
+	request, _ = http.NewRequest("POST", "/micropub", strings.NewReader(`{"action":"update","delete":{"category":["Travel"]}}`))
+	request.Header.Set("Content-Type", "application/json")
+	r, err = ParseRequest(request)
+	if err != nil || len(r.Delete.Strings("category")) != 1 {
+		t.Errorf("Expected values to delete to be read, got %v, %v", r, err)
+	}
+
+	request, _ = http.NewRequest("POST", "/micropub", strings.NewReader(`{`))
+	request.Header.Set("Content-Type", "application/json")
+	if _, err := ParseRequest(request); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	var me, scope string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer remote" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"me":"` + me + `","scope":"` + scope + `"}`))
+	}))
+	defer server.Close()
+
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.AdminToken = "admin"
+
+	if _, err := Authorize(container, "", ScopeCreate); err == nil || err.(*Error).Status != 401 {
+		t.Error("Expected 401 without a token")
+	}
+	if user, err := Authorize(container, "admin", ScopeDelete); err != nil || user.Role != model.RoleAdmin {
+		t.Errorf("Expected admin token to be accepted as an admin, got %s", err)
+	}
+	if _, err := Authorize(container, "remote", ScopeCreate); err == nil || err.(*Error).Status != 403 {
+		t.Error("Expected 403 for an unknown token without a token endpoint")
+	}
+
+	// Test tokens issued by the journal, acting for the user they belong to
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleEditor})
+	if user, err := Authorize(container, "local", ScopeCreate); err != nil || user.ID != 1 || user.Role != model.RoleEditor {
+		t.Errorf("Expected write token to be accepted for its editor, got %s", err)
+	}
+	db.AppendResult(&database.MockToken_SingleRow{Scopes: model.ScopeRead})
+	if _, err := Authorize(container, "local", ScopeCreate); err == nil || err.(*Error).Code != "insufficient_scope" {
+		t.Error("Expected read token to be refused")
+	}
+	db.AppendResult(&database.MockToken_SingleRow{})
+	db.AppendResult(&database.MockUser_SingleRow{Role: model.RoleReader})
+	if _, err := Authorize(container, "local", ScopeCreate); err == nil || err.(*Error).Status != 403 {
+		t.Error("Expected write token of a reader to be refused")
+	}
+
+	// Test tokens issued by an IndieAuth token endpoint
+	db.MultiMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	container.Configuration.URL = "https://Example.com"
+	container.Configuration.IndieAuthTokenEndpoint = server.URL
+	me, scope = "https://example.com/", "create update"
+	if user, err := Authorize(container, "remote", ScopeUpdate); err != nil || user.Role != model.RoleAdmin {
+		t.Errorf("Expected IndieAuth token to be accepted as the owner, got %s", err)
+	}
+	if _, err := Authorize(container, "remote", ScopeDelete); err == nil || err.(*Error).Code != "insufficient_scope" {
+		t.Error("Expected token without the delete scope to be refused")
+	}
+	scope = "post"
+	if _, err := Authorize(container, "remote", ScopeCreate); err != nil {
+		t.Errorf("Expected the older post scope to allow creating, got %s", err)
+	}
+	me = "https://someone.example.com/"
+	if _, err := Authorize(container, "remote", ScopeCreate); err == nil || err.(*Error).Code != "forbidden" {
+		t.Error("Expected token issued for another site to be refused")
+	}
+	if _, err := Authorize(container, "refused", ScopeCreate); err == nil || err.(*Error).Status != 403 {
+		t.Error("Expected token refused by the endpoint to be refused")
+	}
+}
+
+func TestSameSite(t *testing.T) {
+	tables := []struct {
+		a      string
+		b      string
+		output bool
+	}{
+		{"https://example.com", "https://example.com/", true},
+		{"https://EXAMPLE.com/journal/", "https://example.com/journal", true},
+		{"http://example.com/", "https://example.com/", false},
+		{"https://example.com/other", "https://example.com/", false},
+	}
+
+	for _, table := range tables {
+		if SameSite(table.a, table.b) != table.output {
+			t.Errorf("Expected SameSite(%s, %s) to be %t", table.a, table.b, table.output)
+		}
+	}
+}
+
+func TestSlugFromURL(t *testing.T) {
+	container := &app.Container{BasePath: "/journal"}
+	if slug := SlugFromURL(container, "https://example.com/journal/trip/"); slug != "trip" {
+		t.Errorf("Expected slug to be found, got %s", slug)
+	}
+	if slug := SlugFromURL(container, "https://example.com/journal/a/b"); slug != "" {
+		t.Errorf("Expected no slug for a nested path, got %s", slug)
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockCategory_MultipleRows{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	j, err := NewEntry(container, Request{Type: "h-entry", Properties: Properties{
+		"content":     {"Just got back from a long trip around the coast, with plenty of photos to share"},
+		"category":    {"travel"},
+		"published":   {"2018-02-01T10:00:00Z"},
+		"post-status": {"draft"},
+		"mp-slug":     {"Coast Trip"},
+		"syndication": {"https://social.example.com/1"},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if j.Title != "Just got back from a long trip around the coast, with..." || j.Date != "2018-02-01" || j.CategoryID != 1 || !j.IsDraft() || j.Slug != "coast-trip" || j.Syndication[0] != "https://social.example.com/1" {
+		t.Errorf("Expected entry to be built from the properties, got %v", j)
+	}
+
+	// Test new categories are created
+	db.Rows = &database.MockRowsEmpty{}
+	j, err = NewEntry(container, Request{Properties: Properties{"name": {"Trip"}, "content": {"Away"}, "category": {"Walking"}}})
+	if err != nil || j.Title != "Trip" || j.CategoryID != 1 {
+		t.Errorf("Expected category to be created, got %v, %v", j, err)
+	}
+
+	errorTables := []Request{
+		{Type: "h-event", Properties: Properties{"content": {"Away"}}},
+		{Properties: Properties{"name": {"Trip"}}},
+		{Properties: Properties{"content": {"Away"}, "published": {"yesterday"}}},
+		{Properties: Properties{"content": {"Away"}, "post-status": {"pending"}}},
+		{Properties: Properties{"content": {"Away"}, "visibility": {"friends"}}},
+		{Properties: Properties{"content": {"Away"}, "syndication": {"javascript:alert(1)"}}},
+	}
+	for _, r := range errorTables {
+		if _, err := NewEntry(container, r); err == nil || err.(*Error).Status != 400 {
+			t.Errorf("Expected 400 for %v", r.Properties)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockCategory_SingleRow{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	j := model.Journal{Title: "Trip", Content: "Away", Excerpt: "Short", CategoryID: 1, Syndication: []string{"https://social.example.com/1"}}
+
+	err := Update(container, &j, Request{
+		Replace: Properties{"content": {"Back home"}},
+		Add:     Properties{"syndication": {"https://social.example.com/2"}},
+		Delete:  Properties{"category": {"Travel"}, "syndication": {"https://social.example.com/1"}},
+		Remove:  []string{"summary"},
+	})
+	if err != nil || j.Content != "Back home" || j.Excerpt != "" || j.CategoryID != 0 || len(j.Syndication) != 1 || j.Syndication[0] != "https://social.example.com/2" {
+		t.Errorf("Expected entry to be changed, got %v, %v", j, err)
+	}
+
+	if err := Update(container, &j, Request{Replace: Properties{"content": {""}}}); err == nil {
+		t.Error("Expected error when content is removed")
+	}
+}
+
+func TestSource(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockCategory_SingleRow{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+	j := model.Journal{Slug: "trip", Title: "Trip", Content: "Away", Date: "2018-02-01", CategoryID: 1, Status: model.JournalStatusDraft}
+
+	source := Source(container, j, nil)
+	properties := source["properties"].(Properties)
+	if properties.Text("name") != "Trip" || properties.Text("url") != "https://example.com/trip" || properties.Text("category") != "Travel" || properties.Text("post-status") != "draft" || properties.Text("visibility") != "public" {
+		t.Errorf("Expected entry to be described, got %v", properties)
+	}
+
+	source = Source(container, j, []string{"content"})
+	properties = source["properties"].(Properties)
+	if len(properties) != 1 || properties.Text("content") != "Away" || source["type"] != nil {
+		t.Errorf("Expected only content to be given, got %v", source)
+	}
+}
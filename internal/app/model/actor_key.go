@@ -0,0 +1,64 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+const actorKeyTable = "actor_key"
+
+// ActorKeyBits Size of the key generated to sign requests sent to other servers
+const ActorKeyBits = 2048
+
+// ActorKeys Common database resource link for the key the journal signs its ActivityPub requests with
+type ActorKeys struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ks *ActorKeys) CreateTable() error {
+	_, err := ks.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + actorKeyTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`private_key` TEXT NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Fetch Get the journal's key, generating and storing one the first time it is needed
+func (ks *ActorKeys) Fetch() (*rsa.PrivateKey, error) {
+	rows, err := ks.Container.Db.Query("SELECT `private_key` FROM `" + actorKeyTable + "` ORDER BY `id` LIMIT 1")
+	if err != nil {
+		return nil, err
+	}
+	encoded := ""
+	if rows.Next() {
+		rows.Scan(&encoded)
+	}
+	rows.Close()
+	if encoded != "" {
+		block, _ := pem.Decode([]byte(encoded))
+		if block == nil {
+			return nil, errors.New("The stored actor key could not be read")
+		}
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, ActorKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	encoded = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	if _, err := ks.Container.Db.Exec("INSERT INTO `"+actorKeyTable+"` (`private_key`, `created_at`) VALUES(?,?)", encoded, time.Now().UTC().Format(jobTimeFormat)); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
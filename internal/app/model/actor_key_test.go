@@ -0,0 +1,56 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestActorKeys_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ks := ActorKeys{Container: container}
+	ks.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestActorKeys_Fetch(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ks := ActorKeys{Container: container}
+
+	// Test stored key is read
+	stored, _ := rsa.GenerateKey(rand.Reader, 1024)
+	encoded := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(stored)}))
+	db.Rows = &database.MockActorKey_SingleRow{PrivateKey: encoded}
+	key, err := ks.Fetch()
+	if err != nil || key.N.Cmp(stored.N) != 0 || db.Queries != 1 {
+		t.Errorf("Expected stored key to be returned, got %s", err)
+	}
+
+	// Test key is generated and stored the first time
+	db.Queries = 0
+	db.Rows = &database.MockRowsEmpty{}
+	key, err = ks.Fetch()
+	if err != nil || key.N.BitLen() != ActorKeyBits || db.Queries != 2 {
+		t.Errorf("Expected new key to be stored, got %s", err)
+	}
+
+	// Test unreadable key
+	db.Rows = &database.MockActorKey_SingleRow{PrivateKey: "invalid"}
+	if _, err := ks.Fetch(); err == nil {
+		t.Error("Expected error for an unreadable key")
+	}
+
+	db.ErrorMode = true
+	if _, err := ks.Fetch(); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
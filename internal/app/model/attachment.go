@@ -0,0 +1,129 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const attachmentTable = "attachments"
+
+const attachmentColumns = "`id`, `journal_id`, `name`, `file`, `content_type`, `size`, `created_at`"
+
+// Attachment model, a file attached to an entry and kept on disk under the media path
+type Attachment struct {
+	ID          int    `json:"id"`
+	JournalID   int    `json:"journal_id"`
+	Name        string `json:"name"`
+	File        string `json:"-"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// GetSize Get the size of the file for display
+func (a Attachment) GetSize() string {
+	switch {
+	case a.Size >= 1<<20:
+		return strconv.FormatFloat(float64(a.Size)/(1<<20), 'f', 1, 64) + " MB"
+	case a.Size >= 1<<10:
+		return strconv.FormatFloat(float64(a.Size)/(1<<10), 'f', 1, 64) + " KB"
+	}
+
+	return strconv.Itoa(a.Size) + " bytes"
+}
+
+// Attachments Common database resource link for Attachment actions
+type Attachments struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (as *Attachments) CreateTable() error {
+	_, err := as.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + attachmentTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`file` VARCHAR(32) NOT NULL, " +
+		"`content_type` VARCHAR(255) NOT NULL, " +
+		"`size` INTEGER NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Remove a single attachment along with its file
+func (as *Attachments) Delete(a Attachment) error {
+	if err := media.RemoveAttachment(as.Container, a.File); err != nil {
+		return err
+	}
+	_, err := as.Container.Db.Exec("DELETE FROM `"+attachmentTable+"` WHERE `id` = ?", strconv.Itoa(a.ID))
+
+	return err
+}
+
+// DeleteByJournal Remove every attachment on an entry along with their files
+func (as *Attachments) DeleteByJournal(journalID int) error {
+	for _, a := range as.FetchByJournal(journalID) {
+		if err := media.RemoveAttachment(as.Container, a.File); err != nil {
+			return err
+		}
+	}
+	_, err := as.Container.Db.Exec("DELETE FROM `"+attachmentTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+
+	return err
+}
+
+// FetchByJournal Get the files attached to an entry, in the order they were added
+func (as *Attachments) FetchByJournal(journalID int) []Attachment {
+	rows, err := as.Container.Db.Query("SELECT "+attachmentColumns+" FROM `"+attachmentTable+"` WHERE `journal_id` = ? ORDER BY `id`", strconv.Itoa(journalID))
+	if err != nil {
+		return []Attachment{}
+	}
+
+	return as.loadFromRows(rows)
+}
+
+// FindByID Find a single attachment, only if it belongs to the given entry
+func (as *Attachments) FindByID(journalID int, id int) Attachment {
+	rows, err := as.Container.Db.Query("SELECT "+attachmentColumns+" FROM `"+attachmentTable+"` WHERE `journal_id` = ? AND `id` = ? LIMIT 1", strconv.Itoa(journalID), strconv.Itoa(id))
+	if err != nil {
+		return Attachment{}
+	}
+	attachments := as.loadFromRows(rows)
+	if len(attachments) == 1 {
+		return attachments[0]
+	}
+
+	return Attachment{}
+}
+
+// Save Store the details of a newly attached file
+func (as *Attachments) Save(a Attachment) (Attachment, error) {
+	a.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := as.Container.Db.Exec("INSERT INTO `"+attachmentTable+"` (`journal_id`, `name`, `file`, `content_type`, `size`, `created_at`) VALUES(?,?,?,?,?,?)",
+		strconv.Itoa(a.JournalID), a.Name, a.File, a.ContentType, strconv.Itoa(a.Size), a.CreatedAt)
+	if err != nil {
+		return a, err
+	}
+	id, _ := res.LastInsertId()
+	a.ID = int(id)
+
+	return a, nil
+}
+
+func (as Attachments) loadFromRows(rows rows.Rows) []Attachment {
+	defer rows.Close()
+	attachments := []Attachment{}
+	for rows.Next() {
+		a := Attachment{}
+		rows.Scan(&a.ID, &a.JournalID, &a.Name, &a.File, &a.ContentType, &a.Size, &a.CreatedAt)
+		attachments = append(attachments, a)
+	}
+
+	return attachments
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestAttachment_GetSize(t *testing.T) {
+	tables := []struct {
+		input  int
+		output string
+	}{
+		{300, "300 bytes"},
+		{1536, "1.5 KB"},
+		{2 << 20, "2.0 MB"},
+	}
+
+	for _, table := range tables {
+		a := Attachment{Size: table.input}
+		if a.GetSize() != table.output {
+			t.Errorf("Expected GetSize(%d) to produce '%s', got '%s'", table.input, table.output, a.GetSize())
+		}
+	}
+}
+
+func TestAttachments_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	as := Attachments{Container: container}
+	as.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestAttachments_Delete(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "attachments")
+	defer os.RemoveAll(dir)
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db, Configuration: app.Configuration{MediaPath: dir, AttachmentLimit: 1}}
+	as := Attachments{Container: container}
+	file, _, _, _ := media.StoreAttachment(container, "notes.txt", strings.NewReader("Notes"))
+
+	db.ExpectedArgument = "4"
+	if err := as.Delete(Attachment{ID: 4, File: file}); err != nil || db.Queries != 1 {
+		t.Error("Expected attachment to be deleted")
+	}
+	if _, ok := media.FindAttachment(container, file); ok {
+		t.Error("Expected attached file to be removed from disk")
+	}
+
+	// Every attachment on an entry is removed with its file
+	file, _, _, _ = media.StoreAttachment(container, "notes.txt", strings.NewReader("Notes"))
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockAttachment_MultipleRows{File: file}
+	if err := as.DeleteByJournal(1); err != nil || db.Queries != 3 {
+		t.Error("Expected attachments to be deleted by journal ID")
+	}
+	if _, ok := media.FindAttachment(container, file); ok {
+		t.Error("Expected attached files to be removed from disk")
+	}
+
+	db.ErrorMode = true
+	if err := as.DeleteByJournal(1); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestAttachments_FetchByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	as := Attachments{Container: container}
+
+	db.ErrorMode = true
+	if len(as.FetchByJournal(1)) != 0 {
+		t.Error("Expected no attachments on error")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockAttachment_MultipleRows{}
+	attachments := as.FetchByJournal(1)
+	if len(attachments) != 2 || attachments[0].Name != "Report <1>.pdf" || attachments[0].ContentType != "application/pdf" || attachments[1].Size != 300 {
+		t.Errorf("Expected attachments to be loaded, got %v", attachments)
+	}
+}
+
+func TestAttachments_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	as := Attachments{Container: container}
+
+	db.ErrorMode = true
+	if as.FindByID(1, 2).ID != 0 {
+		t.Error("Expected empty attachment on error")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	if as.FindByID(1, 2).ID != 0 {
+		t.Error("Expected empty attachment when not found")
+	}
+
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockAttachment_SingleRow{}
+	if a := as.FindByID(1, 1); a.ID != 1 || a.Name != "Report <1>.pdf" {
+		t.Errorf("Expected attachment to be found, got %v", a)
+	}
+}
+
+func TestAttachments_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	as := Attachments{Container: container}
+
+	db.ExpectedArgument = "report.pdf"
+	a, err := as.Save(Attachment{JournalID: 1, Name: "report.pdf", File: "0123456789abcdef0123456789abcdef", ContentType: "application/pdf", Size: 15})
+	if err != nil || db.Queries != 1 || a.CreatedAt == "" {
+		t.Error("Expected attachment to be saved")
+	}
+
+	db.ErrorMode = true
+	if _, err := as.Save(Attachment{JournalID: 1}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const categoryTable = "category"
+
+const categoryColumns = "`id`, `slug`, `name`, `parent_id`"
+
+// Category model, a single place an entry is filed, optionally nested beneath another category
+type Category struct {
+	ID       int    `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id"`
+	Depth    int    `json:"-"`
+}
+
+// GetIndent Get the prefix that shows how deeply the category is nested when listed
+func (c Category) GetIndent() string {
+	return strings.Repeat("— ", c.Depth)
+}
+
+// Categories Common database resource link for Category actions
+type Categories struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (cs *Categories) CreateTable() error {
+	_, err := cs.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + categoryTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`slug` VARCHAR(255) NOT NULL, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`parent_id` INTEGER NOT NULL DEFAULT 0" +
+		")")
+
+	return err
+}
+
+// Ancestors Get the parents of a category, outermost first
+func (cs *Categories) Ancestors(c Category) []Category {
+	byID := map[int]Category{}
+	for _, other := range cs.FetchAll() {
+		byID[other.ID] = other
+	}
+
+	ancestors := []Category{}
+	seen := map[int]bool{c.ID: true}
+	for parent, ok := byID[c.ParentID]; ok && !seen[parent.ID]; parent, ok = byID[parent.ParentID] {
+		seen[parent.ID] = true
+		ancestors = append([]Category{parent}, ancestors...)
+	}
+
+	return ancestors
+}
+
+// Children Get the categories nested directly beneath a category
+func (cs *Categories) Children(c Category) []Category {
+	rows, err := cs.Container.Db.Query("SELECT "+categoryColumns+" FROM `"+categoryTable+"` WHERE `parent_id` = ? ORDER BY LOWER(`name`)", strconv.Itoa(c.ID))
+	if err != nil {
+		return []Category{}
+	}
+
+	return cs.loadFromRows(rows)
+}
+
+// Delete Remove a category, moving its entries and any nested categories up to its parent
+func (cs *Categories) Delete(c Category) error {
+	if _, err := cs.Container.Db.Exec("UPDATE `"+journalTable+"` SET `category_id` = ? WHERE `category_id` = ?", strconv.Itoa(c.ParentID), strconv.Itoa(c.ID)); err != nil {
+		return err
+	}
+	if _, err := cs.Container.Db.Exec("UPDATE `"+categoryTable+"` SET `parent_id` = ? WHERE `parent_id` = ?", strconv.Itoa(c.ParentID), strconv.Itoa(c.ID)); err != nil {
+		return err
+	}
+	_, err := cs.Container.Db.Exec("DELETE FROM `"+categoryTable+"` WHERE `id` = ?", strconv.Itoa(c.ID))
+
+	return err
+}
+
+// Descendants Get the IDs of a category and every category nested beneath it, at any depth
+func (cs *Categories) Descendants(c Category) []int {
+	children := map[int][]int{}
+	for _, other := range cs.FetchAll() {
+		children[other.ParentID] = append(children[other.ParentID], other.ID)
+	}
+
+	ids := []int{c.ID}
+	seen := map[int]bool{c.ID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, id := range children[ids[i]] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// FetchAll Get every category, ordered by name
+func (cs *Categories) FetchAll() []Category {
+	rows, err := cs.Container.Db.Query("SELECT " + categoryColumns + " FROM `" + categoryTable + "` ORDER BY LOWER(`name`)")
+	if err != nil {
+		return []Category{}
+	}
+
+	return cs.loadFromRows(rows)
+}
+
+// FetchTree Get every category with each listed after its parent and its depth set, for pickers and listings
+func (cs *Categories) FetchTree() []Category {
+	all := cs.FetchAll()
+	children := map[int][]Category{}
+	known := map[int]bool{}
+	for _, c := range all {
+		known[c.ID] = true
+	}
+	for _, c := range all {
+		parent := c.ParentID
+		if !known[parent] {
+			parent = 0
+		}
+		children[parent] = append(children[parent], c)
+	}
+
+	tree := []Category{}
+	var walk func(parent int, depth int)
+	walk = func(parent int, depth int) {
+		for _, c := range children[parent] {
+			c.Depth = depth
+			tree = append(tree, c)
+			walk(c.ID, depth+1)
+		}
+	}
+	walk(0, 0)
+
+	return tree
+}
+
+// FindByID Find a category by its ID
+func (cs *Categories) FindByID(id int) Category {
+	return cs.loadSingle(cs.Container.Db.Query("SELECT "+categoryColumns+" FROM `"+categoryTable+"` WHERE `id` = ? LIMIT 1", strconv.Itoa(id)))
+}
+
+// FindBySlug Find a category by its slug
+func (cs *Categories) FindBySlug(slug string) Category {
+	return cs.loadSingle(cs.Container.Db.Query("SELECT "+categoryColumns+" FROM `"+categoryTable+"` WHERE `slug` = ? LIMIT 1", slug))
+}
+
+// Save Save a category, refusing a parent that would nest it beneath itself
+func (cs *Categories) Save(c Category) (Category, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return c, errors.New("Category must have a name")
+	}
+	if c.ParentID != 0 {
+		if c.ID != 0 {
+			for _, id := range cs.Descendants(c) {
+				if id == c.ParentID {
+					return c, errors.New("Category cannot be nested beneath itself")
+				}
+			}
+		}
+		if cs.FindByID(c.ParentID).ID == 0 {
+			return c, errors.New("Parent category does not exist")
+		}
+	}
+
+	if c.ID == 0 {
+		c.Slug = cs.ensureUniqueSlug(Slugify(c.Name), 0)
+		res, err := cs.Container.Db.Exec("INSERT INTO `"+categoryTable+"` (`slug`, `name`, `parent_id`) VALUES(?,?,?)", c.Slug, c.Name, strconv.Itoa(c.ParentID))
+		if err != nil {
+			return c, err
+		}
+		id, _ := res.LastInsertId()
+		c.ID = int(id)
+
+		return c, nil
+	}
+
+	_, err := cs.Container.Db.Exec("UPDATE `"+categoryTable+"` SET `name` = ?, `parent_id` = ? WHERE `id` = ?", c.Name, strconv.Itoa(c.ParentID), strconv.Itoa(c.ID))
+
+	return c, err
+}
+
+// ensureUniqueSlug Make sure a new category's slug is not already in use
+func (cs *Categories) ensureUniqueSlug(slug string, addition int) string {
+	newSlug := slug
+	if addition > 0 {
+		newSlug = slug + "-" + strconv.Itoa(addition)
+	}
+	if cs.FindBySlug(newSlug).ID > 0 {
+		return cs.ensureUniqueSlug(slug, addition+1)
+	}
+
+	return newSlug
+}
+
+func (cs Categories) loadFromRows(rows rows.Rows) []Category {
+	defer rows.Close()
+	categories := []Category{}
+	for rows.Next() {
+		c := Category{}
+		rows.Scan(&c.ID, &c.Slug, &c.Name, &c.ParentID)
+		categories = append(categories, c)
+	}
+
+	return categories
+}
+
+func (cs *Categories) loadSingle(rows rows.Rows, err error) Category {
+	if err != nil {
+		return Category{}
+	}
+	categories := cs.loadFromRows(rows)
+
+	if len(categories) == 1 {
+		return categories[0]
+	}
+
+	return Category{}
+}
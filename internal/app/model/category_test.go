@@ -0,0 +1,166 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCategory_GetIndent(t *testing.T) {
+	if (Category{}).GetIndent() != "" || (Category{Depth: 2}).GetIndent() != "— — " {
+		t.Error("Expected indent to match the depth of the category")
+	}
+}
+
+func TestCategories_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	cs.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestCategories_Ancestors(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	db.Rows = &database.MockCategory_MultipleRows{}
+	ancestors := cs.Ancestors(Category{ID: 2, ParentID: 1})
+	if len(ancestors) != 1 || ancestors[0].Name != "Travel" {
+		t.Errorf("Expected Travel to be the only ancestor, got %v", ancestors)
+	}
+
+	db.Rows = &database.MockCategory_MultipleRows{}
+	if len(cs.Ancestors(Category{ID: 1})) != 0 {
+		t.Error("Expected a top level category to have no ancestors")
+	}
+}
+
+func TestCategories_Children(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	if len(cs.Children(Category{ID: 1})) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockCategory_SingleRow{ParentID: 1}
+	if children := cs.Children(Category{ID: 1}); len(children) != 1 {
+		t.Error("Expected children to be found by parent ID")
+	}
+}
+
+func TestCategories_Delete(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	db.ExpectedArgument = "2"
+	if err := cs.Delete(Category{ID: 2, ParentID: 1}); err != nil || db.Queries != 3 {
+		t.Error("Expected entries and nested categories to be moved before deleting")
+	}
+
+	db.ExpectedArgument = ""
+	db.ErrorAtQuery = 4
+	if err := cs.Delete(Category{ID: 2}); err == nil || db.Queries != 4 {
+		t.Error("Expected error to stop deletion")
+	}
+}
+
+func TestCategories_Descendants(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	db.Rows = &database.MockCategory_MultipleRows{}
+	ids := cs.Descendants(Category{ID: 1})
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("Expected Travel and Europe to be returned, got %v", ids)
+	}
+
+	db.Rows = &database.MockCategory_MultipleRows{}
+	if ids := cs.Descendants(Category{ID: 3}); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Expected only Cooking to be returned, got %v", ids)
+	}
+}
+
+func TestCategories_FetchTree(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	if len(cs.FetchTree()) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockCategory_MultipleRows{}
+	tree := cs.FetchTree()
+	if len(tree) != 3 || tree[0].Name != "Cooking" || tree[1].Name != "Travel" || tree[2].Name != "Europe" {
+		t.Errorf("Expected nested categories to follow their parent, got %v", tree)
+	}
+	if tree[0].Depth != 0 || tree[1].Depth != 0 || tree[2].Depth != 1 {
+		t.Errorf("Expected depth to be set, got %v", tree)
+	}
+}
+
+func TestCategories_FindBySlug(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if cs.FindBySlug("missing").ID != 0 {
+		t.Error("Expected no category to be found")
+	}
+
+	db.ExpectedArgument = "travel"
+	db.Rows = &database.MockCategory_SingleRow{}
+	if c := cs.FindBySlug("travel"); c.ID != 1 || c.Name != "Travel" {
+		t.Error("Expected category to be found by slug")
+	}
+
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockCategory_SingleRow{}
+	if c := cs.FindByID(1); c.Slug != "travel" {
+		t.Error("Expected category to be found by ID")
+	}
+}
+
+func TestCategories_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	cs := Categories{Container: container}
+
+	if _, err := cs.Save(Category{Name: "  "}); err == nil {
+		t.Error("Expected a category without a name to be refused")
+	}
+
+	db.Rows = &database.MockRowsEmpty{}
+	c, err := cs.Save(Category{Name: "Day Trips"})
+	if err != nil || c.ID != 1 || c.Slug != "day-trips" {
+		t.Errorf("Expected new category to be inserted with a slug, got %v", c)
+	}
+
+	db.Rows = &database.MockRowsEmpty{}
+	if _, err := cs.Save(Category{Name: "Day Trips", ParentID: 5}); err == nil {
+		t.Error("Expected a missing parent to be refused")
+	}
+
+	db.Rows = &database.MockCategory_MultipleRows{}
+	if _, err := cs.Save(Category{ID: 1, Name: "Travel", ParentID: 2}); err == nil {
+		t.Error("Expected a category nested beneath its own child to be refused")
+	}
+
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockCategory_MultipleRows{})
+	db.AppendResult(&database.MockCategory_SingleRow{})
+	queries := db.Queries
+	c, err = cs.Save(Category{ID: 3, Name: "Cooking", ParentID: 1})
+	if err != nil || c.ParentID != 1 || db.Queries != queries+3 {
+		t.Errorf("Expected category to be moved under its new parent, got %v", err)
+	}
+}
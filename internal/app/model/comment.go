@@ -0,0 +1,172 @@
+package model
+
+import (
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/adapter/akismet"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+	"github.com/jamiefdhurst/journal/pkg/sanitize"
+)
+
+const commentTable = "comment"
+
+// Comment statuses
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusSpam     = "spam"
+)
+
+// commentColumns Comment columns along with the slug and title of the entry each belongs to
+const commentColumns = "`" + commentTable + "`.`id`, `journal_id`, `author`, `email`, `url`, `" + commentTable + "`.`content`, `" + commentTable + "`.`status`, `ip`, `user_agent`, `created_at`, " +
+	"COALESCE(`" + journalTable + "`.`slug`, ''), COALESCE(`" + journalTable + "`.`title`, '')"
+
+const commentFrom = "`" + commentTable + "` LEFT JOIN `" + journalTable + "` ON `" + journalTable + "`.`id` = `" + commentTable + "`.`journal_id`"
+
+// Comment model, a response left by a reader beneath an entry
+type Comment struct {
+	ID           int    `json:"id"`
+	JournalID    int    `json:"journal_id"`
+	Author       string `json:"author"`
+	Email        string `json:"-"`
+	URL          string `json:"url"`
+	Content      string `json:"content"`
+	Status       string `json:"status"`
+	IP           string `json:"-"`
+	UserAgent    string `json:"-"`
+	CreatedAt    string `json:"created_at"`
+	JournalSlug  string `json:"-"`
+	JournalTitle string `json:"-"`
+}
+
+// GetHTML Get the content of the comment to show, keeping only simple formatting, such as that of comments imported or
+// sent as replies from elsewhere
+func (c Comment) GetHTML() template.HTML {
+	return template.HTML(sanitize.Comment.Sanitize(c.Content))
+}
+
+// SpamCheck Get the details of the comment passed to the spam checks
+func (c Comment) SpamCheck(permalink string) akismet.Comment {
+	return akismet.Comment{
+		Author:      c.Author,
+		AuthorEmail: c.Email,
+		AuthorURL:   c.URL,
+		Content:     c.Content,
+		Permalink:   permalink,
+		UserAgent:   c.UserAgent,
+		UserIP:      c.IP,
+	}
+}
+
+// Comments Common database resource link for Comment actions
+type Comments struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (cs *Comments) CreateTable() error {
+	_, err := cs.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + commentTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`author` VARCHAR(255) NOT NULL, " +
+		"`email` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`url` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`content` TEXT NOT NULL, " +
+		"`status` VARCHAR(20) NOT NULL, " +
+		"`ip` VARCHAR(45) NOT NULL DEFAULT '', " +
+		"`user_agent` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Permanently remove a comment
+func (cs *Comments) Delete(c Comment) error {
+	_, err := cs.Container.Db.Exec("DELETE FROM `"+commentTable+"` WHERE `id` = ?", strconv.Itoa(c.ID))
+
+	return err
+}
+
+// DeleteByJournal Remove every comment left on an entry
+func (cs *Comments) DeleteByJournal(journalID int) error {
+	_, err := cs.Container.Db.Exec("DELETE FROM `"+commentTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+
+	return err
+}
+
+// FetchApproved Get the comments shown beneath an entry, oldest first
+func (cs *Comments) FetchApproved(journalID int) []Comment {
+	rows, err := cs.Container.Db.Query("SELECT "+commentColumns+" FROM "+commentFrom+" WHERE `journal_id` = ? AND `"+commentTable+"`.`status` = ? ORDER BY `"+commentTable+"`.`id`", strconv.Itoa(journalID), CommentStatusApproved)
+	if err != nil {
+		return []Comment{}
+	}
+
+	return cs.loadFromRows(rows)
+}
+
+// FetchByStatus Get every comment with the given status across all entries, newest first
+func (cs *Comments) FetchByStatus(status string) []Comment {
+	rows, err := cs.Container.Db.Query("SELECT "+commentColumns+" FROM "+commentFrom+" WHERE `"+commentTable+"`.`status` = ? ORDER BY `"+commentTable+"`.`id` DESC", status)
+	if err != nil {
+		return []Comment{}
+	}
+
+	return cs.loadFromRows(rows)
+}
+
+// FindByID Find a single comment
+func (cs *Comments) FindByID(id int) Comment {
+	rows, err := cs.Container.Db.Query("SELECT "+commentColumns+" FROM "+commentFrom+" WHERE `"+commentTable+"`.`id` = ? LIMIT 1", strconv.Itoa(id))
+	if err != nil {
+		return Comment{}
+	}
+	comments := cs.loadFromRows(rows)
+	if len(comments) == 1 {
+		return comments[0]
+	}
+
+	return Comment{}
+}
+
+// Save Store a newly submitted comment, held for moderation unless a status has been given and dated now unless it
+// was written elsewhere before being imported
+func (cs *Comments) Save(c Comment) (Comment, error) {
+	if c.Status == "" {
+		c.Status = CommentStatusPending
+	}
+	if c.CreatedAt == "" {
+		c.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	}
+	res, err := cs.Container.Db.Exec("INSERT INTO `"+commentTable+"` (`journal_id`, `author`, `email`, `url`, `content`, `status`, `ip`, `user_agent`, `created_at`) VALUES(?,?,?,?,?,?,?,?,?)",
+		strconv.Itoa(c.JournalID), c.Author, c.Email, c.URL, c.Content, c.Status, c.IP, c.UserAgent, c.CreatedAt)
+	if err != nil {
+		return c, err
+	}
+	id, _ := res.LastInsertId()
+	c.ID = int(id)
+
+	return c, nil
+}
+
+// SetStatus Move a comment between the moderation queue, the entry and the spam folder
+func (cs *Comments) SetStatus(c Comment, status string) error {
+	_, err := cs.Container.Db.Exec("UPDATE `"+commentTable+"` SET `status` = ? WHERE `id` = ?", status, strconv.Itoa(c.ID))
+
+	return err
+}
+
+func (cs Comments) loadFromRows(rows rows.Rows) []Comment {
+	defer rows.Close()
+	comments := []Comment{}
+	for rows.Next() {
+		c := Comment{}
+		rows.Scan(&c.ID, &c.JournalID, &c.Author, &c.Email, &c.URL, &c.Content, &c.Status, &c.IP, &c.UserAgent, &c.CreatedAt, &c.JournalSlug, &c.JournalTitle)
+		comments = append(comments, c)
+	}
+
+	return comments
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestComment_GetHTML(t *testing.T) {
+	c := Comment{Content: `<p>Nice <a href="javascript:alert(1)" onclick="alert(1)">post</a></p><script>alert(1)</script>`}
+	if actual := string(c.GetHTML()); actual != `<p>Nice <a rel="nofollow ugc noopener">post</a></p>` {
+		t.Errorf("Expected comment to be sanitized, got '%s'", actual)
+	}
+}
+
+func TestComment_SpamCheck(t *testing.T) {
+	c := Comment{Author: "Reader", Email: "reader@example.com", URL: "https://reader.example.com", Content: "Hi", IP: "127.0.0.1", UserAgent: "Test"}
+	check := c.SpamCheck("https://example.com/slug")
+	if check.Author != "Reader" || check.AuthorEmail != "reader@example.com" || check.AuthorURL != "https://reader.example.com" || check.Content != "Hi" ||
+		check.Permalink != "https://example.com/slug" || check.UserIP != "127.0.0.1" || check.UserAgent != "Test" {
+		t.Errorf("Expected comment details to be passed to spam checks, got %v", check)
+	}
+}
+
+func TestComments_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	cs.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestComments_Delete(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	db.ExpectedArgument = "4"
+	if err := cs.Delete(Comment{ID: 4}); err != nil || db.Queries != 1 {
+		t.Error("Expected comment to be deleted")
+	}
+	db.ExpectedArgument = "3"
+	if err := cs.DeleteByJournal(3); err != nil || db.Queries != 2 {
+		t.Error("Expected comments to be deleted by journal ID")
+	}
+}
+
+func TestComments_FetchApproved(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	if len(cs.FetchApproved(1)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = CommentStatusApproved
+	db.Rows = &database.MockComment_MultipleRows{}
+	comments := cs.FetchApproved(1)
+	if len(comments) != 2 || comments[0].Author != "Reader" || comments[1].CreatedAt != "2018-02-03 10:00:00" || comments[0].JournalSlug != "slug" {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestComments_FetchByStatus(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	if len(cs.FetchByStatus(CommentStatusPending)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = CommentStatusSpam
+	db.Rows = &database.MockComment_MultipleRows{}
+	if len(cs.FetchByStatus(CommentStatusSpam)) != 2 {
+		t.Errorf("Expected 2 rows returned")
+	}
+}
+
+func TestComments_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	if cs.FindByID(4).ID != 0 {
+		t.Error("Expected empty comment returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "4"
+	db.Rows = &database.MockComment_SingleRow{}
+	comment := cs.FindByID(4)
+	if comment.ID != 4 || comment.Email != "reader@example.com" || comment.Status != CommentStatusPending || comment.JournalTitle != "Title" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+
+	db.Rows = &database.MockComment_MultipleRows{}
+	if cs.FindByID(4).ID != 0 {
+		t.Error("Expected no comment when query returns more than one result")
+	}
+}
+
+func TestComments_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+
+	db.ExpectedArgument = CommentStatusPending
+	comment, err := cs.Save(Comment{JournalID: 1, Author: "Reader", Content: "Hi"})
+	if err != nil || comment.Status != CommentStatusPending || comment.CreatedAt == "" {
+		t.Error("Expected comment to be held for moderation")
+	}
+
+	db.ExpectedArgument = CommentStatusSpam
+	comment, err = cs.Save(Comment{JournalID: 1, Author: "Reader", Content: "Hi", Status: CommentStatusSpam})
+	if err != nil || comment.Status != CommentStatusSpam {
+		t.Error("Expected given status to be kept")
+	}
+
+	db.ExpectedArgument = "2018-02-03 10:00:00"
+	comment, err = cs.Save(Comment{JournalID: 1, Author: "Reader", Content: "Hi", CreatedAt: "2018-02-03 10:00:00"})
+	if err != nil || comment.CreatedAt != "2018-02-03 10:00:00" {
+		t.Error("Expected given date to be kept")
+	}
+
+	db.ErrorMode = true
+	if _, err := cs.Save(Comment{JournalID: 1}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestComments_SetStatus(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	cs := Comments{Container: container}
+	db.ExpectedArgument = CommentStatusApproved
+	if err := cs.SetStatus(Comment{ID: 4}, CommentStatusApproved); err != nil || db.Queries != 1 {
+		t.Error("Expected comment status to be updated")
+	}
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/seal"
+)
+
+const encryptionTable = "encryption"
+
+// encryptionCheck Value sealed when encryption is turned on, which only the right passphrase opens again
+const encryptionCheck = "journal"
+
+// Errors refusing to unlock an encrypted database
+var (
+	ErrLocked          = errors.New("The database is encrypted, its passphrase must be given")
+	ErrWrongPassphrase = errors.New("Wrong passphrase for the encrypted database")
+)
+
+// sealedTables Tables holding the content of entries, sealed when the journal is encrypted, with the column each
+// row is found by and the columns sealed
+var sealedTables = []struct {
+	table, key string
+	columns    []string
+}{
+	{journalTable, "id", []string{"content", "excerpt"}},
+	{journalRevisionTable, "id", []string{"content"}},
+	{journalAutosaveTable, "id", []string{"content", "excerpt"}},
+}
+
+// Unlock Derive the key sealing the content of entries from a passphrase, turning encryption on with it when the
+// database is not yet encrypted. Nothing is returned when no passphrase is given for a database that is not
+// encrypted.
+func Unlock(c *app.Container, passphrase string) (*seal.Sealer, error) {
+	rows, err := c.Db.Query("SELECT `salt`, `verifier` FROM `" + encryptionTable + "` LIMIT 1")
+	if err != nil {
+		return nil, err
+	}
+	var encodedSalt, verifier string
+	encrypted := rows.Next()
+	if encrypted {
+		rows.Scan(&encodedSalt, &verifier)
+	}
+	rows.Close()
+
+	switch {
+	case !encrypted && passphrase == "":
+		return nil, nil
+	case passphrase == "":
+		return nil, ErrLocked
+	case !encrypted:
+		salt, err := seal.NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		sealer, err := seal.New(passphrase, salt)
+		if err != nil {
+			return nil, err
+		}
+		_, err = c.Db.Exec("INSERT INTO `"+encryptionTable+"` (`salt`, `verifier`) VALUES(?,?)", base64.StdEncoding.EncodeToString(salt), sealer.Seal(encryptionCheck))
+
+		return sealer, err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := seal.New(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if opened, err := sealer.Open(verifier); err != nil || opened != encryptionCheck {
+		return nil, ErrWrongPassphrase
+	}
+
+	return sealer, nil
+}
+
+// SealExisting Seal the content and excerpts of entries, revisions and autosaves written before encryption was
+// turned on, returning how many values were sealed
+func SealExisting(c *app.Container) (int, error) {
+	sealed := 0
+	if c.Sealer == nil {
+		return sealed, nil
+	}
+	for _, t := range sealedTables {
+		for _, column := range t.columns {
+			rows, err := c.Db.Query("SELECT `"+t.key+"`, `"+column+"` FROM `"+t.table+"` WHERE `"+column+"` != '' AND `"+column+"` NOT LIKE ?", seal.Prefix+"%")
+			if err != nil {
+				return sealed, err
+			}
+			values := map[int]string{}
+			for rows.Next() {
+				var id int
+				var value string
+				rows.Scan(&id, &value)
+				values[id] = value
+			}
+			rows.Close()
+
+			for id, value := range values {
+				if _, err := c.Db.Exec("UPDATE `"+t.table+"` SET `"+column+"` = ? WHERE `"+t.key+"` = ?", c.Sealer.Seal(value), strconv.Itoa(id)); err != nil {
+					return sealed, err
+				}
+				sealed++
+			}
+		}
+	}
+
+	return sealed, nil
+}
+
+// createEncryptionTable Create the table keeping the salt the key is derived with and a value to check it against
+func createEncryptionTable(c *app.Container) error {
+	_, err := c.Db.Exec("CREATE TABLE IF NOT EXISTS `" + encryptionTable + "` (" +
+		"`salt` VARCHAR(64) NOT NULL, " +
+		"`verifier` TEXT NOT NULL" +
+		")")
+
+	return err
+}
+
+// dropEncryptionTable Drop the table, unless encryption has been turned on and the key would be lost with it
+func dropEncryptionTable(c *app.Container) error {
+	rows, err := c.Db.Query("SELECT `salt` FROM `" + encryptionTable + "` LIMIT 1")
+	if err != nil {
+		return err
+	}
+	encrypted := rows.Next()
+	rows.Close()
+	if encrypted {
+		return ErrIrreversible
+	}
+	_, err = c.Db.Exec("DROP TABLE `" + encryptionTable + "`")
+
+	return err
+}
+
+// encrypted Whether the content of entries is sealed, so that it cannot be searched in the database
+func encrypted(c *app.Container) bool {
+	return c != nil && c.Sealer != nil
+}
+
+// sealed Seal a value before it is stored, when the journal is encrypted
+func sealed(c *app.Container, value string) string {
+	if c == nil || c.Sealer == nil {
+		return value
+	}
+
+	return c.Sealer.Seal(value)
+}
+
+// opened Open a value that was sealed when it was stored, leaving it as it is if it cannot be
+func opened(c *app.Container, value string) string {
+	if c == nil || c.Sealer == nil {
+		return value
+	}
+	if plain, err := c.Sealer.Open(value); err == nil {
+		return plain
+	}
+
+	return value
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/seal"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUnlock(t *testing.T) {
+	db := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	container := &app.Container{Db: db}
+	if sealer, err := Unlock(container, ""); sealer != nil || err != nil {
+		t.Error("Expected nothing to be unlocked without a passphrase")
+	}
+
+	// Giving a passphrase turns encryption on
+	db.Queries = 0
+	sealer, err := Unlock(container, "correct horse")
+	if sealer == nil || err != nil || db.Queries != 2 {
+		t.Errorf("Expected encryption to be turned on, got %v after %d queries", err, db.Queries)
+	}
+
+	salt, _ := seal.NewSalt()
+	s, _ := seal.New("correct horse", salt)
+	encrypted := func() *database.MockEncryption_SingleRow {
+		return &database.MockEncryption_SingleRow{Salt: base64.StdEncoding.EncodeToString(salt), Verifier: s.Seal(encryptionCheck)}
+	}
+	db.Rows = encrypted()
+	if sealer, err := Unlock(container, "correct horse"); sealer == nil || err != nil {
+		t.Errorf("Expected the right passphrase to unlock the database, got %v", err)
+	}
+	db.Rows = encrypted()
+	if _, err := Unlock(container, "wrong horse"); err != ErrWrongPassphrase {
+		t.Errorf("Expected the wrong passphrase to be refused, got %v", err)
+	}
+	db.Rows = encrypted()
+	if _, err := Unlock(container, ""); err != ErrLocked {
+		t.Errorf("Expected an encrypted database to stay locked without a passphrase, got %v", err)
+	}
+
+	db.ErrorMode = true
+	if _, err := Unlock(container, "correct horse"); err == nil {
+		t.Error("Expected error to have been returned")
+	}
+}
+
+func TestSealExisting(t *testing.T) {
+	db := &database.MockSqlite{Rows: &database.MockJournalContent_MultipleRows{}}
+	container := &app.Container{Db: db}
+	if count, err := SealExisting(container); count != 0 || err != nil || db.Queries != 0 {
+		t.Error("Expected nothing to be sealed without encryption")
+	}
+
+	salt, _ := seal.NewSalt()
+	container.Sealer, _ = seal.New("correct horse", salt)
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournalContent_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if count, err := SealExisting(container); count != 2 || err != nil || db.Queries != 7 {
+		t.Errorf("Expected existing content to be sealed, got %d after %d queries", count, db.Queries)
+	}
+
+	db.AppendResult(&database.MockJournalContent_MultipleRows{})
+	db.ErrorAtQuery = db.Queries + 2
+	if _, err := SealExisting(container); err == nil {
+		t.Error("Expected error to have been returned")
+	}
+}
+
+func TestSealed(t *testing.T) {
+	container := &app.Container{}
+	if sealed(container, "Dear diary") != "Dear diary" || opened(container, "Dear diary") != "Dear diary" {
+		t.Error("Expected values to be left alone without encryption")
+	}
+	salt, _ := seal.NewSalt()
+	container.Sealer, _ = seal.New("correct horse", salt)
+	value := sealed(container, "Dear diary")
+	if !seal.IsSealed(value) || opened(container, value) != "Dear diary" {
+		t.Errorf("Expected value to be sealed and opened again, got %s", value)
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+const federatedEntryTable = "federated_entry"
+
+// FederatedEntries Record of the entries already sent to followers, so that later changes are sent as updates
+type FederatedEntries struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (fe *FederatedEntries) CreateTable() error {
+	_, err := fe.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + federatedEntryTable + "` (" +
+		"`journal_id` INTEGER PRIMARY KEY, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Add Record that an entry has been sent, returning whether it had been already
+func (fe *FederatedEntries) Add(journalID int) (bool, error) {
+	rows, err := fe.Container.Db.Query("SELECT `journal_id` FROM `"+federatedEntryTable+"` WHERE `journal_id` = ? LIMIT 1", strconv.Itoa(journalID))
+	if err != nil {
+		return false, err
+	}
+	sent := rows.Next()
+	rows.Close()
+	if sent {
+		return true, nil
+	}
+	_, err = fe.Container.Db.Exec("INSERT INTO `"+federatedEntryTable+"` (`journal_id`, `created_at`) VALUES(?,?)", strconv.Itoa(journalID), time.Now().UTC().Format(jobTimeFormat))
+
+	return false, err
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestFederatedEntries_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	fe := FederatedEntries{Container: container}
+	fe.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestFederatedEntries_Add(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	fe := FederatedEntries{Container: container}
+
+	// Test first delivery is recorded
+	if sent, err := fe.Add(1); sent || err != nil || db.Queries != 2 {
+		t.Errorf("Expected entry to be recorded as sent, got %v", err)
+	}
+
+	// Test entry already sent
+	db.Queries = 0
+	db.Rows = &database.MockFederatedEntry_SingleRow{}
+	if sent, err := fe.Add(1); !sent || err != nil || db.Queries != 1 {
+		t.Error("Expected entry to have been sent already")
+	}
+
+	db.ErrorMode = true
+	if _, err := fe.Add(1); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
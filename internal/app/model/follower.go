@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const followerTable = "follower"
+
+// Follower model, an account elsewhere on the fediverse following the journal
+type Follower struct {
+	ID        int    `json:"id"`
+	Actor     string `json:"actor"`
+	Inbox     string `json:"inbox"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Followers Common database resource link for Follower actions
+type Followers struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (fs *Followers) CreateTable() error {
+	_, err := fs.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + followerTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`actor` VARCHAR(255) NOT NULL UNIQUE, " +
+		"`inbox` VARCHAR(255) NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Count Get the number of followers
+func (fs *Followers) Count() int {
+	rows, err := fs.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `" + followerTable + "`")
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+	total := 0
+	if rows.Next() {
+		rows.Scan(&total)
+	}
+
+	return total
+}
+
+// FetchAll Get every follower, oldest first
+func (fs *Followers) FetchAll() []Follower {
+	rows, err := fs.Container.Db.Query("SELECT `id`, `actor`, `inbox`, `created_at` FROM `" + followerTable + "` ORDER BY `id`")
+	if err != nil {
+		return []Follower{}
+	}
+
+	return fs.loadFromRows(rows)
+}
+
+// Inboxes Get the inboxes to deliver to, each once however many followers share it
+func (fs *Followers) Inboxes() []string {
+	inboxes := []string{}
+	seen := map[string]bool{}
+	for _, f := range fs.FetchAll() {
+		if !seen[f.Inbox] {
+			seen[f.Inbox] = true
+			inboxes = append(inboxes, f.Inbox)
+		}
+	}
+
+	return inboxes
+}
+
+// Remove Stop an actor following the journal
+func (fs *Followers) Remove(actor string) error {
+	_, err := fs.Container.Db.Exec("DELETE FROM `"+followerTable+"` WHERE `actor` = ?", actor)
+	return err
+}
+
+// Save Store a follower, replacing the inbox of one who follows again
+func (fs *Followers) Save(f Follower) (Follower, error) {
+	f.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := fs.Container.Db.Exec("INSERT INTO `"+followerTable+"` (`actor`, `inbox`, `created_at`) VALUES(?,?,?) "+
+		"ON CONFLICT (`actor`) DO UPDATE SET `inbox` = excluded.`inbox`, `created_at` = excluded.`created_at`", f.Actor, f.Inbox, f.CreatedAt)
+	if err != nil {
+		return Follower{}, err
+	}
+	id, _ := res.LastInsertId()
+	f.ID = int(id)
+
+	return f, nil
+}
+
+func (fs Followers) loadFromRows(rows rows.Rows) []Follower {
+	defer rows.Close()
+	followers := []Follower{}
+	for rows.Next() {
+		f := Follower{}
+		rows.Scan(&f.ID, &f.Actor, &f.Inbox, &f.CreatedAt)
+		followers = append(followers, f)
+	}
+
+	return followers
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestFollowers_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	fs := Followers{Container: container}
+	fs.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestFollowers_Count(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockPagination_Result{TotalResults: 3}
+	container := &app.Container{Db: db}
+	fs := Followers{Container: container}
+	if fs.Count() != 3 {
+		t.Error("Expected followers to be counted")
+	}
+
+	db.ErrorMode = true
+	if fs.Count() != 0 {
+		t.Error("Expected no followers on error")
+	}
+}
+
+func TestFollowers_Inboxes(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockFollower_MultipleRows{}
+	container := &app.Container{Db: db}
+	fs := Followers{Container: container}
+	expected := []string{"https://social.example.com/inbox", "https://other.example.com/users/reader/inbox"}
+	if inboxes := fs.Inboxes(); !reflect.DeepEqual(inboxes, expected) {
+		t.Errorf("Expected each inbox once, got %v", inboxes)
+	}
+
+	db.ErrorMode = true
+	if len(fs.Inboxes()) != 0 {
+		t.Error("Expected no inboxes on error")
+	}
+}
+
+func TestFollowers_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	fs := Followers{Container: container}
+	db.ExpectedArgument = "https://social.example.com/users/reader"
+	f, err := fs.Save(Follower{Actor: "https://social.example.com/users/reader", Inbox: "https://social.example.com/inbox"})
+	if err != nil || f.ID != 1 || f.CreatedAt == "" {
+		t.Errorf("Expected follower to be saved, got %v, %v", f, err)
+	}
+	if err := fs.Remove("https://social.example.com/users/reader"); err != nil {
+		t.Errorf("Expected follower to be removed, got %s", err)
+	}
+
+	db.ErrorMode = true
+	if _, err := fs.Save(Follower{}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
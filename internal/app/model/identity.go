@@ -0,0 +1,104 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const identityTable = "identity"
+
+// Identity model, an account with an external provider that a user signs in with instead of their password
+type Identity struct {
+	ID        int
+	UserID    int
+	Provider  string
+	Subject   string
+	CreatedAt string
+}
+
+// Identities Common database resource link for Identity actions
+type Identities struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table, where each provider's account can only be linked to one user
+func (is *Identities) CreateTable() error {
+	_, err := is.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + identityTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`user_id` INTEGER NOT NULL, " +
+		"`provider` VARCHAR(255) NOT NULL, " +
+		"`subject` VARCHAR(255) NOT NULL, " +
+		"`created_at` DATETIME NOT NULL, " +
+		"UNIQUE (`provider`, `subject`)" +
+		")")
+
+	return err
+}
+
+// DeleteByUser Unlink every account a user signs in with
+func (is *Identities) DeleteByUser(userID int) error {
+	_, err := is.Container.Db.Exec("DELETE FROM `"+identityTable+"` WHERE `user_id` = ?", strconv.Itoa(userID))
+
+	return err
+}
+
+// FindBySubject Find the link to a provider's account, given who the provider says signed in
+func (is *Identities) FindBySubject(provider string, subject string) Identity {
+	return is.loadSingle(is.Container.Db.Query("SELECT "+identityColumns+" FROM `"+identityTable+"` WHERE `provider` = ? AND `subject` = ? LIMIT 1", provider, subject))
+}
+
+// Link Link a provider's account to a user, who can then sign in with it
+func (is *Identities) Link(userID int, provider string, subject string) (Identity, error) {
+	i := Identity{UserID: userID, Provider: provider, Subject: subject, CreatedAt: time.Now().UTC().Format(jobTimeFormat)}
+	res, err := is.Container.Db.Exec("INSERT INTO `"+identityTable+"` (`user_id`, `provider`, `subject`, `created_at`) VALUES(?,?,?,?)", strconv.Itoa(i.UserID), i.Provider, i.Subject, i.CreatedAt)
+	if err != nil {
+		return Identity{}, err
+	}
+	id, _ := res.LastInsertId()
+	i.ID = int(id)
+
+	return i, nil
+}
+
+const identityColumns = "`id`, `user_id`, `provider`, `subject`, `created_at`"
+
+func (is Identities) loadFromRows(rows rows.Rows) []Identity {
+	defer rows.Close()
+	identities := []Identity{}
+	for rows.Next() {
+		i := Identity{}
+		rows.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.CreatedAt)
+		identities = append(identities, i)
+	}
+
+	return identities
+}
+
+func (is *Identities) loadSingle(rows rows.Rows, err error) Identity {
+	if err != nil {
+		return Identity{}
+	}
+	identities := is.loadFromRows(rows)
+
+	if len(identities) == 1 {
+		return identities[0]
+	}
+
+	return Identity{}
+}
+
+// createIdentityTable Create the table for databases created before users could sign in with an external provider
+func createIdentityTable(c *app.Container) error {
+	is := Identities{Container: c}
+
+	return is.CreateTable()
+}
+
+func dropIdentityTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + identityTable + "`")
+
+	return err
+}
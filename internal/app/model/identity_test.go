@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestIdentities_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	is := Identities{Container: container}
+	is.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestIdentities_DeleteByUser(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	is := Identities{Container: container}
+	if err := is.DeleteByUser(1); err != nil || db.Queries != 1 {
+		t.Error("Expected identities to have been deleted")
+	}
+}
+
+func TestIdentities_FindBySubject(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	is := Identities{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if is.FindBySubject("google", "unknown").ID > 0 {
+		t.Error("Expected unknown account to not be found")
+	}
+
+	db.Rows = &database.MockIdentity_SingleRow{}
+	db.ExpectedArgument = "248289761001"
+	if identity := is.FindBySubject("google", "248289761001"); identity.ID != 1 || identity.UserID != 1 || identity.Provider != "google" {
+		t.Error("Expected linked account to be found")
+	}
+}
+
+func TestIdentities_Link(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	is := Identities{Container: container}
+	identity, err := is.Link(1, "github", "583231")
+	if err != nil || identity.ID != 1 || identity.UserID != 1 || identity.Subject != "583231" || identity.CreatedAt == "" {
+		t.Error("Expected account to have been linked")
+	}
+
+	db.ErrorMode = true
+	if _, err = is.Link(1, "github", "583231"); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// Kinds of problem found by checking the database
+const (
+	ProblemIntegrity = "integrity"
+	ProblemSlug      = "slug"
+	ProblemDate      = "date"
+	ProblemOrphan    = "orphan"
+)
+
+// Problem Something found wrong in the database, and whether it was put right
+type Problem struct {
+	Kind   string
+	Detail string
+	Fixed  bool
+}
+
+// reValidDate A date as saved from the form or the API, optionally followed by a time
+var reValidDate = regexp.MustCompile("^\\d{4}-\\d{2}-\\d{2}([T ]\\d{2}:\\d{2}.*)?$")
+
+// orphan Rows pointing at another that no longer exists, and how they are put right when it can be done safely
+type orphan struct {
+	table       string
+	column      string
+	parent      string
+	description string
+	fix         string
+}
+
+// orphans Rows left behind by entries and categories removed without them. Orphaned rows only belonging to their entry
+// are removed, entries and categories are moved to the top level, while attachments are left as their files remain.
+var orphans = []orphan{
+	{commentTable, "journal_id", journalTable, "comment(s) on entries that no longer exist", "DELETE"},
+	{journalMetaTable, "journal_id", journalTable, "custom field(s) of entries that no longer exist", "DELETE"},
+	{journalTagTable, "journal_id", journalTable, "tag(s) of entries that no longer exist", "DELETE"},
+	{journalLinkTable, "journal_id", journalTable, "link(s) of entries that no longer exist", "DELETE"},
+	{journalRevisionTable, "journal_id", journalTable, "revision(s) of entries that no longer exist", "DELETE"},
+	{journalAutosaveTable, "journal_id", journalTable, "autosave(s) of entries that no longer exist", "DELETE"},
+	{attachmentTable, "journal_id", journalTable, "attachment(s) of entries that no longer exist", ""},
+	{journalTable, "category_id", categoryTable, "entry(s) filed under categories that no longer exist", "UPDATE"},
+	{categoryTable, "parent_id", categoryTable, "category(s) nested beneath categories that no longer exist", "UPDATE"},
+}
+
+// CheckIntegrity Look for corruption in an SQLite database, entries sharing a slug or with dates that cannot be read,
+// and rows left behind by entries and categories that no longer exist. When fix is given, entries sharing a slug are
+// renamed after the first and orphaned rows are put right, leaving what cannot be fixed safely to be reported.
+func CheckIntegrity(c *app.Container, fix bool) ([]Problem, error) {
+	problems := []Problem{}
+	for _, check := range []func(*app.Container, bool) ([]Problem, error){checkPragma, checkEntries, checkOrphans} {
+		found, err := check(c, fix)
+		problems = append(problems, found...)
+		if err != nil {
+			return problems, err
+		}
+	}
+
+	return problems, nil
+}
+
+// checkPragma Ask SQLite to check every page and index of the database, which it reports as ok when all is well
+func checkPragma(c *app.Container, fix bool) ([]Problem, error) {
+	problems := []Problem{}
+	if database.DialectOf(c.Db) != database.DialectSqlite {
+		return problems, nil
+	}
+	rows, err := c.Db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return problems, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var message string
+		rows.Scan(&message)
+		if message != "ok" {
+			problems = append(problems, Problem{Kind: ProblemIntegrity, Detail: message})
+		}
+	}
+
+	return problems, nil
+}
+
+// checkEntries Find entries whose slug is already taken by an earlier one, which hides both, and those whose date
+// cannot be read
+func checkEntries(c *app.Container, fix bool) ([]Problem, error) {
+	problems := []Problem{}
+	// The date is read as text, as the SQLite driver otherwise turns dates it cannot read into the zero time
+	rows, err := c.Db.Query("SELECT `id`, `slug`, SUBSTR(`date`, 1, 255) FROM `" + journalTable + "` ORDER BY `id`")
+	if err != nil {
+		return problems, err
+	}
+	type entry struct {
+		id         int
+		slug, date string
+	}
+	entries := []entry{}
+	for rows.Next() {
+		e := entry{}
+		rows.Scan(&e.id, &e.slug, &e.date)
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	js := Journals{Container: c}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if !IsValidDate(e.date) {
+			problems = append(problems, Problem{Kind: ProblemDate, Detail: "Entry " + e.slug + " has the date " + strconv.Quote(e.date) + ", expected YYYY-MM-DD"})
+		}
+		if !seen[e.slug] {
+			seen[e.slug] = true
+			continue
+		}
+		problem := Problem{Kind: ProblemSlug, Detail: "Entry " + strconv.Itoa(e.id) + " shares the slug " + e.slug + " with an earlier entry"}
+		if fix {
+			slug := js.EnsureUniqueSlug(e.slug, 0)
+			if _, err := c.Db.Exec("UPDATE `"+journalTable+"` SET `slug` = ? WHERE `id` = ?", slug, strconv.Itoa(e.id)); err != nil {
+				return append(problems, problem), err
+			}
+			problem.Detail += ", renamed to " + slug
+			problem.Fixed = true
+		}
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// checkOrphans Count the rows pointing at entries and categories that no longer exist
+func checkOrphans(c *app.Container, fix bool) ([]Problem, error) {
+	problems := []Problem{}
+	for _, o := range orphans {
+		// The parents are read through a derived table, as MySQL cannot otherwise change the table it reads from
+		condition := " WHERE `" + o.column + "` != 0 AND `" + o.column + "` NOT IN (SELECT `id` FROM (SELECT `id` FROM `" + o.parent + "`) AS `parents`)"
+		total := 0
+		rows, err := c.Db.Query("SELECT COUNT(*) FROM `" + o.table + "`" + condition)
+		if err != nil {
+			return problems, err
+		}
+		if rows.Next() {
+			rows.Scan(&total)
+		}
+		rows.Close()
+		if total == 0 {
+			continue
+		}
+
+		problem := Problem{Kind: ProblemOrphan, Detail: strconv.Itoa(total) + " " + o.description}
+		if fix && o.fix != "" {
+			query := "DELETE FROM `" + o.table + "`" + condition
+			if o.fix == "UPDATE" {
+				query = "UPDATE `" + o.table + "` SET `" + o.column + "` = 0" + condition
+			}
+			if _, err := c.Db.Exec(query); err != nil {
+				return append(problems, problem), err
+			}
+			problem.Fixed = true
+		}
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// IsValidDate Check the date of an entry can be read, as YYYY-MM-DD optionally followed by a time
+func IsValidDate(date string) bool {
+	if !reValidDate.MatchString(date) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date[:10])
+
+	return err == nil
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	appendResults := func(fix bool, orphaned int) {
+		db.AppendResult(&database.MockIntegrity_Messages{Messages: []string{"*** in database main ***", "Page 3 is never used"}})
+		db.AppendResult(&database.MockIntegrity_Entries{})
+		if fix {
+			// The shared slug is taken, the next is free
+			db.AppendResult(&database.MockJournal_SingleRow{})
+			db.AppendResult(&database.MockRowsEmpty{})
+		}
+		for i := range orphans {
+			if i == orphaned {
+				db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+			} else {
+				db.AppendResult(&database.MockRowsEmpty{})
+			}
+		}
+	}
+
+	// Problems are only reported
+	db.EnableMultiMode()
+	appendResults(false, 0)
+	problems, err := CheckIntegrity(container, false)
+	if err != nil || len(problems) != 5 || db.Queries != 2+len(orphans) {
+		t.Fatalf("Expected problems to be reported, got %v and %+v after %d queries", err, problems, db.Queries)
+	}
+	if problems[0].Kind != ProblemIntegrity || problems[1].Detail != "Page 3 is never used" {
+		t.Errorf("Expected messages from SQLite to be reported, got %+v", problems[:2])
+	}
+	if problems[2].Kind != ProblemDate || problems[3].Kind != ProblemSlug || problems[3].Fixed {
+		t.Errorf("Expected unreadable date and shared slug to be reported, got %+v", problems[2:4])
+	}
+	if problems[4].Kind != ProblemOrphan || problems[4].Detail != "2 comment(s) on entries that no longer exist" || problems[4].Fixed {
+		t.Errorf("Expected orphaned comments to be reported, got %+v", problems[4])
+	}
+
+	// What can be fixed safely is
+	db.Queries = 0
+	appendResults(true, 0)
+	problems, err = CheckIntegrity(container, true)
+	if err != nil || len(problems) != 5 || db.Queries != 2+3+len(orphans)+1 {
+		t.Fatalf("Expected problems to be fixed, got %v and %+v after %d queries", err, problems, db.Queries)
+	}
+	if problems[2].Fixed || !problems[3].Fixed || problems[3].Detail != "Entry 2 shares the slug slug with an earlier entry, renamed to slug-2" || !problems[4].Fixed {
+		t.Errorf("Expected slug and orphans to be fixed but not the date, got %+v", problems[2:])
+	}
+
+	// Orphaned attachments are left alone as their files remain
+	db.Queries = 0
+	appendResults(true, 6)
+	problems, err = CheckIntegrity(container, true)
+	if err != nil || problems[4].Detail != "2 attachment(s) of entries that no longer exist" || problems[4].Fixed || db.Queries != 2+3+len(orphans) {
+		t.Errorf("Expected orphaned attachments to be reported without being fixed, got %+v after %d queries", problems[4], db.Queries)
+	}
+
+	// Failures stop the check
+	db.Queries = 0
+	appendResults(false, 0)
+	db.ErrorAtQuery = 3
+	if _, err := CheckIntegrity(container, false); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestIsValidDate(t *testing.T) {
+	for date, expected := range map[string]bool{
+		"2018-02-01":           true,
+		"2018-02-01T00:00:00Z": true,
+		"2018-02-01 10:30:00":  true,
+		"2018-02-31":           false,
+		"01/02/2018":           false,
+		"":                     false,
+	} {
+		if IsValidDate(date) != expected {
+			t.Errorf("Expected %q to be valid: %t", date, expected)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const jobTable = "job"
+
+// JobMaxAttempts is the number of times a job is tried before it is marked as failed
+const JobMaxAttempts = 3
+
+// Job statuses
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+const jobTimeFormat = "2006-01-02 15:04:05"
+
+// Job model, a unit of background work
+type Job struct {
+	ID        int    `json:"id"`
+	Type      string `json:"type"`
+	Payload   string `json:"payload"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	RunAt     string `json:"run_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Decode unmarshals the JSON payload into the given destination
+func (j Job) Decode(destination interface{}) error {
+	return json.Unmarshal([]byte(j.Payload), destination)
+}
+
+// Jobs Common database resource link for Job actions
+type Jobs struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (js *Jobs) CreateTable() error {
+	_, err := js.db().Exec("CREATE TABLE IF NOT EXISTS `" + jobTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`type` VARCHAR(255) NOT NULL, " +
+		"`payload` TEXT NOT NULL, " +
+		"`status` VARCHAR(20) NOT NULL, " +
+		"`attempts` INTEGER NOT NULL DEFAULT 0, " +
+		"`last_error` TEXT NOT NULL DEFAULT '', " +
+		"`run_at` DATETIME NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Enqueue Store a new pending job, encoding the payload as JSON
+func (js *Jobs) Enqueue(jobType string, payload interface{}) (Job, error) {
+	return js.EnqueueAt(jobType, payload, time.Now())
+}
+
+// EnqueueAt Store a new pending job that is not due until the given time
+func (js *Jobs) EnqueueAt(jobType string, payload interface{}, runAt time.Time) (Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, err
+	}
+
+	j := Job{Type: jobType, Payload: string(encoded), Status: JobStatusPending, RunAt: runAt.UTC().Format(jobTimeFormat), CreatedAt: time.Now().UTC().Format(jobTimeFormat)}
+	res, err := js.db().Exec("INSERT INTO `"+jobTable+"` (`type`, `payload`, `status`, `attempts`, `last_error`, `run_at`, `created_at`) VALUES(?,?,?,0,'',?,?)", j.Type, j.Payload, j.Status, j.RunAt, j.CreatedAt)
+	if err != nil {
+		return Job{}, err
+	}
+	id, _ := res.LastInsertId()
+	j.ID = int(id)
+
+	return j, nil
+}
+
+// HasPending Check whether a job of the given type is already waiting to run
+func (js *Jobs) HasPending(jobType string) bool {
+	return js.loadSingle(js.db().Query("SELECT "+jobColumns+" FROM `"+jobTable+"` WHERE `type` = ? AND `status` = ? LIMIT 1", jobType, JobStatusPending)).ID > 0
+}
+
+// Claim Find the next pending job that is due and mark it as running
+func (js *Jobs) Claim() Job {
+	j := js.loadSingle(js.db().Query("SELECT "+jobColumns+" FROM `"+jobTable+"` WHERE `status` = ? AND `run_at` <= ? ORDER BY `id` LIMIT 1", JobStatusPending, time.Now().UTC().Format(jobTimeFormat)))
+	if j.ID == 0 {
+		return j
+	}
+
+	// Only one worker may move the job out of pending
+	res, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ?, `attempts` = `attempts` + 1 WHERE `id` = ? AND `status` = ?", JobStatusRunning, strconv.Itoa(j.ID), JobStatusPending)
+	if err != nil {
+		return Job{}
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return Job{}
+	}
+	j.Status = JobStatusRunning
+	j.Attempts++
+
+	return j
+}
+
+// Complete Mark a job as successfully done
+func (js *Jobs) Complete(j Job) error {
+	_, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ?, `last_error` = '' WHERE `id` = ?", JobStatusDone, strconv.Itoa(j.ID))
+	return err
+}
+
+// Fail Record a job failure, rescheduling it with a backoff until it runs out of attempts
+func (js *Jobs) Fail(j Job, reason error) error {
+	if j.Attempts >= JobMaxAttempts {
+		_, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ?, `last_error` = ? WHERE `id` = ?", JobStatusFailed, reason.Error(), strconv.Itoa(j.ID))
+		return err
+	}
+
+	runAt := time.Now().UTC().Add(time.Duration(j.Attempts*j.Attempts) * time.Minute).Format(jobTimeFormat)
+	_, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ?, `last_error` = ?, `run_at` = ? WHERE `id` = ?", JobStatusPending, reason.Error(), runAt, strconv.Itoa(j.ID))
+	return err
+}
+
+// Retry Put a failed job back into the queue with a fresh set of attempts
+func (js *Jobs) Retry(id int) error {
+	_, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ?, `attempts` = 0, `run_at` = ? WHERE `id` = ? AND `status` = ?", JobStatusPending, time.Now().UTC().Format(jobTimeFormat), strconv.Itoa(id), JobStatusFailed)
+	return err
+}
+
+// ResetRunning Return any jobs left running by a previous process to the queue
+func (js *Jobs) ResetRunning() error {
+	_, err := js.db().Exec("UPDATE `"+jobTable+"` SET `status` = ? WHERE `status` = ?", JobStatusPending, JobStatusRunning)
+	return err
+}
+
+// FetchPaginated returns a set of paginated jobs, newest first
+func (js *Jobs) FetchPaginated(query database.PaginationQuery) ([]Job, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+
+	countResult, err := js.db().Query("SELECT COUNT(*) AS `total` FROM `" + jobTable + "`")
+	if err != nil {
+		return []Job{}, pagination
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Job{}, pagination
+	}
+
+	rows, err := js.db().Query("SELECT "+jobColumns+" FROM `"+jobTable+"` ORDER BY `id` DESC LIMIT ? OFFSET ?", query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)
+	if err != nil {
+		return []Job{}, pagination
+	}
+	return js.loadFromRows(rows), pagination
+}
+
+// db Jobs live in the shared queue database when one is set, so hosted journals feed the same workers
+func (js *Jobs) db() app.Database {
+	if js.Container.Queue != nil {
+		return js.Container.Queue
+	}
+
+	return js.Container.Db
+}
+
+const jobColumns = "`id`, `type`, `payload`, `status`, `attempts`, `last_error`, `run_at`, `created_at`"
+
+func (js Jobs) loadFromRows(rows rows.Rows) []Job {
+	defer rows.Close()
+	jobs := []Job{}
+	for rows.Next() {
+		j := Job{}
+		rows.Scan(&j.ID, &j.Type, &j.Payload, &j.Status, &j.Attempts, &j.LastError, &j.RunAt, &j.CreatedAt)
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
+
+func (js *Jobs) loadSingle(rows rows.Rows, err error) Job {
+	if err != nil {
+		return Job{}
+	}
+	jobs := js.loadFromRows(rows)
+
+	if len(jobs) == 1 {
+		return jobs[0]
+	}
+
+	return Job{}
+}
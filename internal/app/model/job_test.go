@@ -0,0 +1,206 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgDb "github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJob_Decode(t *testing.T) {
+	j := Job{Payload: "{\"name\":\"example\"}"}
+	payload := struct{ Name string }{}
+	if err := j.Decode(&payload); err != nil || payload.Name != "example" {
+		t.Error("Expected payload to have been decoded")
+	}
+
+	j.Payload = "not json"
+	if err := j.Decode(&payload); err == nil {
+		t.Error("Expected error when payload is invalid")
+	}
+}
+
+func TestJobs_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+	js.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestJobs_Enqueue(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+
+	job, err := js.Enqueue("test", map[string]string{"name": "example"})
+	if err != nil || job.ID != 1 || job.Status != JobStatusPending || job.Payload != "{\"name\":\"example\"}" {
+		t.Errorf("Expected job to have been enqueued, got %+v", job)
+	}
+
+	// Test unencodable payload
+	_, err = js.Enqueue("test", make(chan int))
+	if err == nil {
+		t.Error("Expected error when payload cannot be encoded")
+	}
+
+	// Test database error
+	db.ErrorMode = true
+	_, err = js.Enqueue("test", "")
+	if err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
+
+func TestJobs_EnqueueAt(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+
+	runAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	db.ExpectedArgument = "2030-01-02 03:04:05"
+	job, err := js.EnqueueAt("test", nil, runAt)
+	if err != nil || job.RunAt != "2030-01-02 03:04:05" || job.CreatedAt == job.RunAt {
+		t.Errorf("Expected job to be enqueued for later, got %+v", job)
+	}
+}
+
+func TestJobs_HasPending(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+
+	db.Rows = &database.MockRowsEmpty{}
+	if js.HasPending("test") {
+		t.Error("Expected no pending job")
+	}
+
+	db.ExpectedArgument = "test"
+	db.Rows = &database.MockJob_SingleRow{}
+	if !js.HasPending("test") {
+		t.Error("Expected pending job to be found")
+	}
+
+	db.ErrorMode = true
+	if js.HasPending("test") {
+		t.Error("Expected no pending job on error")
+	}
+}
+
+func TestJobs_Claim(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+
+	// Test nothing due
+	db.Rows = &database.MockRowsEmpty{}
+	job := js.Claim()
+	if job.ID > 0 {
+		t.Error("Expected no job to have been claimed")
+	}
+
+	// Test successful claim
+	db.Rows = &database.MockJob_SingleRow{}
+	job = js.Claim()
+	if job.ID != 1 || job.Status != JobStatusRunning || job.Attempts != 1 {
+		t.Errorf("Expected job to have been claimed, got %+v", job)
+	}
+
+	// Test claimed by another worker
+	db.Rows = &database.MockJob_SingleRow{}
+	db.Result = &database.MockResult{Affected: 0}
+	job = js.Claim()
+	if job.ID > 0 {
+		t.Error("Expected no job when another worker claimed it first")
+	}
+
+	// Test error on update
+	db.Rows = &database.MockJob_SingleRow{}
+	db.ErrorAtQuery = db.Queries + 2
+	job = js.Claim()
+	if job.ID > 0 {
+		t.Error("Expected no job when update fails")
+	}
+}
+
+func TestJobs_Complete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+	if err := js.Complete(Job{ID: 1}); err != nil || db.Queries != 1 {
+		t.Error("Expected job to have been completed")
+	}
+}
+
+func TestJobs_Fail(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+
+	// Test rescheduled
+	db.ExpectedArgument = JobStatusPending
+	if err := js.Fail(Job{ID: 1, Attempts: 1}, errors.New("Failure")); err != nil {
+		t.Error("Expected job to have been rescheduled")
+	}
+
+	// Test out of attempts
+	db.ExpectedArgument = JobStatusFailed
+	if err := js.Fail(Job{ID: 1, Attempts: JobMaxAttempts}, errors.New("Failure")); err != nil {
+		t.Error("Expected job to have been marked as failed")
+	}
+}
+
+func TestJobs_Retry(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+	db.ExpectedArgument = "4"
+	if err := js.Retry(4); err != nil {
+		t.Error("Expected job to have been retried")
+	}
+}
+
+func TestJobs_ResetRunning(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+	db.ExpectedArgument = JobStatusRunning
+	if err := js.ResetRunning(); err != nil {
+		t.Error("Expected running jobs to have been reset")
+	}
+}
+
+func TestJobs_FetchPaginated(t *testing.T) {
+
+	// Test error
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Jobs{Container: container}
+	jobs, pagination := js.FetchPaginated(pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(jobs) > 0 || pagination.TotalPages > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test pages out of bounds
+	db.ErrorMode = false
+	db.Rows = &database.MockPagination_Result{TotalResults: 2}
+	jobs, pagination = js.FetchPaginated(pkgDb.PaginationQuery{Page: 4, ResultsPerPage: 2})
+	if len(jobs) > 0 || pagination.TotalPages != 1 {
+		t.Errorf("Expected empty result set with correct pages returned, instead received +%v", pagination)
+	}
+
+	// Test successful result
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJob_MultipleRows{})
+	jobs, pagination = js.FetchPaginated(pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(jobs) != 2 || jobs[1].Status != JobStatusFailed || pagination.TotalResults != 2 {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
@@ -2,7 +2,7 @@ package model
 
 import (
 	"database/sql"
-	"fmt"
+	"html"
 	"math"
 	"regexp"
 	"strconv"
@@ -12,17 +12,97 @@ import (
 	"github.com/jamiefdhurst/journal/internal/app"
 	"github.com/jamiefdhurst/journal/pkg/database"
 	"github.com/jamiefdhurst/journal/pkg/database/rows"
+	"github.com/jamiefdhurst/journal/pkg/emoji"
+	"github.com/jamiefdhurst/journal/pkg/markdown"
+	"github.com/jamiefdhurst/journal/pkg/sanitize"
 )
 
 const journalTable = "journal"
 
+// Journal statuses
+const (
+	JournalStatusDraft     = "draft"
+	JournalStatusPublished = "published"
+	JournalStatusScheduled = "scheduled"
+)
+
+// Who may see an entry, everyone, only those given its address, or only signed in users
+const (
+	JournalVisibilityPublic   = "public"
+	JournalVisibilityUnlisted = "unlisted"
+	JournalVisibilityPrivate  = "private"
+)
+
+// Whether readers may comment on an entry
+const (
+	JournalCommentsOpen   = "open"
+	JournalCommentsClosed = "closed"
+)
+
+const journalColumns = "`id`, `slug`, `title`, `date`, `content`, `status`, `deleted_at`, `comments`, `publish_at`, `category_id`, `excerpt`, `pinned`, `visibility`, `password_hash`, `word_count`, `reading_time`, `author_id`"
+
+// journalListed Condition excluding unlisted, private and password protected entries from indexes, feeds and searches
+const journalListed = "`visibility` = '" + JournalVisibilityPublic + "' AND `password_hash` = ''"
+
+// ReservedPaths First segments of the paths the router serves pages other than entries at, which neither an entry's
+// slug nor the name of a journal hosted beneath a path prefix may take, as it would be hidden behind them
+var ReservedPaths = []string{
+	"activitypub", "admin", "api", "author", "category", "drafts", "graphql", "healthz", "login", "logout", "media",
+	"micropub", "new", "oembed", "reading", "readyz", "register", "search", "settings", "setup", "static", "tag",
+	"trash", "upload",
+}
+
+// reservedSlugs Paths used by other pages, which an entry's slug would be hidden behind
+var reservedSlugs = reserved(ReservedPaths)
+
+var reValidSlug = regexp.MustCompile("^[a-z0-9_\\-]*[a-z0-9][a-z0-9_\\-]*$")
+
+// titleLength Longest title worked out from the content of an entry written without one
+const titleLength = 60
+
+// journalNotDeleted Condition excluding entries that have been moved to the trash
+const journalNotDeleted = "`deleted_at` = ''"
+
+// journalAddedColumns Columns added after the table was first released, which older databases are missing
+var journalAddedColumns = []string{
+	"`status` VARCHAR(20) NOT NULL DEFAULT '" + JournalStatusPublished + "'",
+	"`deleted_at` VARCHAR(20) NOT NULL DEFAULT ''",
+	"`comments` VARCHAR(10) NOT NULL DEFAULT '" + JournalCommentsOpen + "'",
+	"`publish_at` VARCHAR(20) NOT NULL DEFAULT ''",
+	"`category_id` INTEGER NOT NULL DEFAULT 0",
+	"`excerpt` TEXT NOT NULL DEFAULT ''",
+	"`pinned` INTEGER NOT NULL DEFAULT 0",
+	"`visibility` VARCHAR(10) NOT NULL DEFAULT '" + JournalVisibilityPublic + "'",
+	"`password_hash` VARCHAR(255) NOT NULL DEFAULT ''",
+	"`word_count` INTEGER NOT NULL DEFAULT 0",
+	"`reading_time` INTEGER NOT NULL DEFAULT 0",
+	"`author_id` INTEGER NOT NULL DEFAULT 0",
+}
+
 // Journal model
 type Journal struct {
-	ID      int    `json:"id"`
-	Slug    string `json:"slug"`
-	Title   string `json:"title"`
-	Date    string `json:"date"`
-	Content string `json:"content"`
+	ID           int               `json:"id"`
+	Slug         string            `json:"slug"`
+	Title        string            `json:"title"`
+	Date         string            `json:"date"`
+	Content      string            `json:"content"`
+	Excerpt      string            `json:"excerpt,omitempty"`
+	CanonicalURL string            `json:"canonical_url,omitempty"`
+	Syndication  []string          `json:"syndication,omitempty"`
+	Status       string            `json:"-"`
+	DeletedAt    string            `json:"-"`
+	Comments     string            `json:"-"`
+	PublishAt    string            `json:"-"`
+	CategoryID   int               `json:"category_id,omitempty"`
+	Pinned       bool              `json:"pinned,omitempty"`
+	Visibility   string            `json:"-"`
+	PasswordHash string            `json:"-"`
+	WordCount    int               `json:"word_count,omitempty"`
+	ReadingTime  int               `json:"reading_time,omitempty"`
+	Meta         map[string]string `json:"meta,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	AuthorID     int               `json:"author_id,omitempty"`
+	Author       string            `json:"author,omitempty"`
 }
 
 // GetDate Get the friendly date for the Journal
@@ -42,11 +122,101 @@ func (j Journal) GetEditableDate() string {
 	return re.FindString(j.Date)
 }
 
-// GetExcerpt returns a small extract of the entry
+// GetTime Get the date of the Journal as a time, for feeds and other machine readable output
+func (j Journal) GetTime() time.Time {
+	timeObj, _ := time.Parse("2006-01-02", j.GetEditableDate())
+	return timeObj
+}
+
+// CommentsOpen Check whether readers may comment on the entry
+func (j Journal) CommentsOpen() bool {
+	return j.Comments != JournalCommentsClosed
+}
+
+// IsListed Check whether the entry may appear in indexes, feeds and searches, rather than only at its own address
+func (j Journal) IsListed() bool {
+	return j.Visibility != JournalVisibilityUnlisted && j.Visibility != JournalVisibilityPrivate && !j.IsProtected()
+}
+
+// IsPrivate Check whether the entry may only be read by signed in users
+func (j Journal) IsPrivate() bool {
+	return j.Visibility == JournalVisibilityPrivate
+}
+
+// IsDeleted Check whether the entry has been moved to the trash
+func (j Journal) IsDeleted() bool {
+	return j.DeletedAt != ""
+}
+
+// IsDraft Check whether the entry is hidden from the public until it is published
+func (j Journal) IsDraft() bool {
+	return j.Status == JournalStatusDraft
+}
+
+// IsPublished Check whether the entry is shown to the public, neither a draft nor waiting for its scheduled time
+func (j Journal) IsPublished() bool {
+	return !j.IsDraft() && !j.IsScheduled()
+}
+
+// IsScheduled Check whether the entry will be published automatically once its publish time has passed
+func (j Journal) IsScheduled() bool {
+	return j.Status == JournalStatusScheduled
+}
+
+// Schedule Set the entry to be published automatically at the given time
+func (j *Journal) Schedule(at time.Time) {
+	j.Status = JournalStatusScheduled
+	j.PublishAt = at.UTC().Format(jobTimeFormat)
+}
+
+// GetPublishAt Get the friendly scheduled publish time in the server's time zone
+func (j Journal) GetPublishAt() string {
+	timeObj, err := time.ParseInLocation(jobTimeFormat, j.PublishAt, time.UTC)
+	if err != nil {
+		return ""
+	}
+	return timeObj.Local().Format("Monday January 2, 2006 at 15:04")
+}
+
+// GetPublishTime Get the scheduled publish time in the server's time zone, for templates to write in their language
+func (j Journal) GetPublishTime() time.Time {
+	timeObj, err := time.ParseInLocation(jobTimeFormat, j.PublishAt, time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	return timeObj.Local()
+}
+
+// GetEditablePublishAt Get the scheduled publish time for editing in the server's time zone
+func (j Journal) GetEditablePublishAt() string {
+	timeObj, err := time.ParseInLocation(jobTimeFormat, j.PublishAt, time.UTC)
+	if err != nil {
+		return ""
+	}
+	return timeObj.Local().Format("2006-01-02T15:04")
+}
+
+// GetHTML Render the Markdown content as HTML with built-in shortcodes as emoji, leaving entries written before
+// Markdown was supported as they are, and keep only the elements and attributes allowed in entries
+func (j Journal) GetHTML() string {
+	if strings.HasPrefix(strings.TrimSpace(j.Content), "<") {
+		return sanitize.Entry.Sanitize(j.Content)
+	}
+
+	return sanitize.Entry.Sanitize(emoji.Replace(markdown.Render(j.Content), nil))
+}
+
+// GetExcerpt returns a small extract of the entry, the excerpt written for it if there is one or else the start of its content
 func (j Journal) GetExcerpt() string {
+	source := StripWikiLinks(j.GetHTML())
+	if strings.TrimSpace(j.Excerpt) != "" {
+		source = emoji.Replace(markdown.Render(j.Excerpt), nil)
+	}
+
 	strip := regexp.MustCompile("\b+")
-	text := strings.ReplaceAll(j.Content, "<p>", "")
+	text := strings.ReplaceAll(source, "<p>", "")
 	text = strings.ReplaceAll(text, "</p>", " ")
+	text = regexp.MustCompile("<[^>]*>|\n").ReplaceAllString(text, "")
 	text = strip.ReplaceAllString(text, " ")
 	words := strings.Split(text, " ")
 
@@ -56,12 +226,41 @@ func (j Journal) GetExcerpt() string {
 	return strings.TrimSuffix(strings.Join(words, " "), " ")
 }
 
+// GetDescription returns the excerpt as plain text, for describing the entry outside of a page such as in link previews
+func (j Journal) GetDescription() string {
+	return strings.Join(strings.Fields(html.UnescapeString(j.GetExcerpt())), " ")
+}
+
+var reImageSource = regexp.MustCompile(`(?i)<img\s(?:[^>]*?\s)?src\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// GetImage returns the address of the first image shown in the entry, such as one embedded from the media library, or
+// an empty string when there is none
+func (j Journal) GetImage() string {
+	for _, match := range reImageSource.FindAllStringSubmatch(j.GetHTML(), -1) {
+		source := strings.TrimSpace(html.UnescapeString(match[1] + match[2]))
+		if source != "" && !strings.HasPrefix(strings.ToLower(source), "data:") {
+			return source
+		}
+	}
+
+	return ""
+}
+
 // Journals Common database resource link for Journal actions
 type Journals struct {
 	Container *app.Container
 	Gs        GiphysExtractor
 }
 
+// JournalOptions Narrow the published, listed entries FindAll returns to those filed in any of the given categories
+// or given the tag with a slug, and page through them from an offset up to a limit, where no limit returns every one
+type JournalOptions struct {
+	CategoryIDs []int
+	Tag         string
+	Limit       int
+	Offset      int
+}
+
 // CreateTable Create the actual table
 func (js *Journals) CreateTable() error {
 	_, err := js.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalTable + "` (" +
@@ -69,20 +268,82 @@ func (js *Journals) CreateTable() error {
 		"`slug` VARCHAR(255) NOT NULL, " +
 		"`title` VARCHAR(255) NOT NULL, " +
 		"`date` DATE NOT NULL, " +
-		"`content` TEXT NOT NULL" +
+		"`content` TEXT NOT NULL, " +
+		strings.Join(journalAddedColumns, ", ") +
 		")")
+	if err != nil {
+		return err
+	}
 
-	return err
+	for _, column := range journalAddedColumns {
+		_, err = js.Container.Db.Exec("ALTER TABLE `" + journalTable + "` ADD COLUMN " + column)
+		if err != nil && !strings.Contains(strings.ToLower(err.Error()), "duplicate column") {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count Get the total number of journals
+func (js *Journals) Count() int {
+	total := 0
+	rows, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `" + journalTable + "`")
+	if err != nil {
+		return total
+	}
+	defer rows.Close()
+	if rows.Next() {
+		rows.Scan(&total)
+	}
+
+	return total
 }
 
-// EnsureUniqueSlug Make sure the current slug is unique
+// Delete Permanently remove a journal entry along with its links, revisions, comments, attachments, custom fields and
+// tags, in one transaction so that nothing is left half removed
+func (js *Journals) Delete(j Journal) error {
+	return js.Container.Transaction(func(tx *app.Container) error {
+		if _, err := tx.Db.Exec("DELETE FROM `"+journalLinkTable+"` WHERE `journal_id` = ?", strconv.Itoa(j.ID)); err != nil {
+			return err
+		}
+		rs := JournalRevisions{Container: tx}
+		if err := rs.DeleteByJournal(j.ID); err != nil {
+			return err
+		}
+		cs := Comments{Container: tx}
+		if err := cs.DeleteByJournal(j.ID); err != nil {
+			return err
+		}
+		as := Attachments{Container: tx}
+		if err := as.DeleteByJournal(j.ID); err != nil {
+			return err
+		}
+		ms := JournalMetas{Container: tx}
+		if err := ms.DeleteByJournal(j.ID); err != nil {
+			return err
+		}
+		ts := JournalTags{Container: tx}
+		if err := ts.DeleteByJournal(j.ID); err != nil {
+			return err
+		}
+		_, err := tx.Db.Exec("DELETE FROM `"+journalTable+"` WHERE `id` = ?", strconv.Itoa(j.ID))
+
+		return err
+	})
+}
+
+// EnsureUniqueSlug Make sure the current slug is unique, including against entries in the trash and reserved paths,
+// numbering repeats from -2
 func (js *Journals) EnsureUniqueSlug(slug string, addition int) string {
 	newSlug := slug
 	if addition > 0 {
 		newSlug = strings.Join([]string{slug, "-", strconv.Itoa(addition)}, "")
 	}
-	exists := js.FindBySlug(newSlug)
-	if exists.ID > 0 {
+	if reservedSlugs[newSlug] || js.SlugTaken(newSlug, 0) {
+		if addition == 0 {
+			addition = 1
+		}
 		addition++
 		return js.EnsureUniqueSlug(slug, addition)
 	}
@@ -90,9 +351,14 @@ func (js *Journals) EnsureUniqueSlug(slug string, addition int) string {
 	return newSlug
 }
 
-// FetchAll Get all journals
+// FetchAll Get all published journals
 func (js *Journals) FetchAll() []Journal {
-	rows, err := js.Container.Db.Query("SELECT * FROM `" + journalTable + "` ORDER BY `date` DESC")
+	return js.FindAll(JournalOptions{})
+}
+
+// FetchDrafts Get all unpublished journals, drafts and scheduled alike, most recently written first
+func (js *Journals) FetchDrafts() []Journal {
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `status` IN (?, ?) AND "+journalNotDeleted+" ORDER BY `id` DESC", JournalStatusDraft, JournalStatusScheduled)
 	if err != nil {
 		return []Journal{}
 	}
@@ -100,14 +366,49 @@ func (js *Journals) FetchAll() []Journal {
 	return js.loadFromRows(rows)
 }
 
-// FetchPaginated returns a set of paginated journal entries
+// FetchLatest Get the most recent published journals, up to the given limit
+func (js *Journals) FetchLatest(limit int) []Journal {
+	if limit <= 0 {
+		return []Journal{}
+	}
+
+	return js.FindAll(JournalOptions{Limit: limit})
+}
+
+// FetchPaginated returns a set of paginated, published journal entries
 func (js *Journals) FetchPaginated(query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
 	pagination := database.PaginationInformation{
 		Page:           query.Page,
 		ResultsPerPage: query.ResultsPerPage,
 	}
 
-	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `" + journalTable + "`")
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `"+journalTable+"` WHERE `status` = ? AND "+journalNotDeleted+" AND "+journalListed, JournalStatusPublished)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Journal{}, pagination
+	}
+
+	// Pinned entries lead the index, ahead of everything else
+	rows, _ := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `status` = ? AND "+journalNotDeleted+" AND "+journalListed+" ORDER BY `pinned` DESC, `date` DESC LIMIT ? OFFSET ?", JournalStatusPublished, query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)
+	return js.loadFromRows(rows), pagination
+}
+
+// FetchPaginatedAll returns a set of paginated journal entries outside the trash, whatever their status or visibility,
+// most recently written first
+func (js *Journals) FetchPaginatedAll(query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `" + journalTable + "` WHERE " + journalNotDeleted)
 	if err != nil {
 		return []Journal{}, pagination
 	}
@@ -120,27 +421,258 @@ func (js *Journals) FetchPaginated(query database.PaginationQuery) ([]Journal, d
 		return []Journal{}, pagination
 	}
 
-	rows, _ := js.Container.Db.Query(fmt.Sprintf("SELECT * FROM `"+journalTable+"` ORDER BY `date` DESC LIMIT %d OFFSET %d", query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage))
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+journalNotDeleted+" ORDER BY `date` DESC, `id` DESC LIMIT ? OFFSET ?", query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)
+	if err != nil {
+		return []Journal{}, pagination
+	}
 	return js.loadFromRows(rows), pagination
 }
 
-// FindBySlug Find a journal by slug
+// FetchPaginatedByCategory returns a set of paginated, published journal entries filed in any of the given categories
+func (js *Journals) FetchPaginatedByCategory(categoryIDs []int, query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+	if len(categoryIDs) == 0 {
+		return []Journal{}, pagination
+	}
+
+	args := []interface{}{JournalStatusPublished}
+	for _, id := range categoryIDs {
+		args = append(args, strconv.Itoa(id))
+	}
+	where := "`status` = ? AND " + journalNotDeleted + " AND " + journalListed + " AND `category_id` IN (?" + strings.Repeat(", ?", len(categoryIDs)-1) + ")"
+
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `"+journalTable+"` WHERE "+where, args...)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Journal{}, pagination
+	}
+
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+where+" ORDER BY `date` DESC LIMIT ? OFFSET ?", append(args, query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)...)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	return js.loadFromRows(rows), pagination
+}
+
+// FetchPaginatedByTag returns a set of paginated, published journal entries given a tag, by its slug
+func (js *Journals) FetchPaginatedByTag(slug string, query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+
+	where := "`status` = ? AND " + journalNotDeleted + " AND " + journalListed + " AND " + journalTagged
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `"+journalTable+"` WHERE "+where, JournalStatusPublished, slug)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Journal{}, pagination
+	}
+
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+where+" ORDER BY `date` DESC LIMIT ? OFFSET ?", JournalStatusPublished, slug, query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	return js.loadFromRows(rows), pagination
+}
+
+// FetchRange returns published, listed journal entries from an offset along with how many there are in all, in the
+// order of the index or, when categories are given, of the entries filed in any of them, keeping only those given a tag
+// when its slug is not empty
+func (js *Journals) FetchRange(categoryIDs []int, tag string, offset int, limit int) ([]Journal, int) {
+	args := []interface{}{JournalStatusPublished}
+	where := "`status` = ? AND " + journalNotDeleted + " AND " + journalListed
+	order := "`pinned` DESC, `date` DESC, `id` DESC"
+	if len(categoryIDs) > 0 {
+		for _, id := range categoryIDs {
+			args = append(args, strconv.Itoa(id))
+		}
+		where += " AND `category_id` IN (?" + strings.Repeat(", ?", len(categoryIDs)-1) + ")"
+		order = "`date` DESC, `id` DESC"
+	}
+	if tag != "" {
+		args = append(args, tag)
+		where += " AND " + journalTagged
+		order = "`date` DESC, `id` DESC"
+	}
+
+	total := 0
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `"+journalTable+"` WHERE "+where, args...)
+	if err != nil {
+		return []Journal{}, total
+	}
+	countResult.Next()
+	countResult.Scan(&total)
+	countResult.Close()
+	if offset >= total || limit <= 0 {
+		return []Journal{}, total
+	}
+
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+where+" ORDER BY "+order+" LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return []Journal{}, total
+	}
+	return js.loadFromRows(rows), total
+}
+
+// FindAll Get the published, listed journals chosen by the options, most recent first
+func (js *Journals) FindAll(options JournalOptions) []Journal {
+	args := []interface{}{JournalStatusPublished}
+	where := "`status` = ? AND " + journalNotDeleted + " AND " + journalListed
+	if len(options.CategoryIDs) > 0 {
+		for _, id := range options.CategoryIDs {
+			args = append(args, strconv.Itoa(id))
+		}
+		where += " AND `category_id` IN (?" + strings.Repeat(", ?", len(options.CategoryIDs)-1) + ")"
+	}
+	if options.Tag != "" {
+		where += " AND " + journalTagged
+		args = append(args, options.Tag)
+	}
+	query := "SELECT " + journalColumns + " FROM `" + journalTable + "` WHERE " + where + " ORDER BY `date` DESC, `id` DESC"
+	if options.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, options.Limit, options.Offset)
+	}
+
+	rows, err := js.Container.Db.Query(query, args...)
+	if err != nil {
+		return []Journal{}
+	}
+
+	return js.loadFromRows(rows)
+}
+
+// FindByID Find a journal by ID, ignoring any in the trash
+func (js *Journals) FindByID(id int) Journal {
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `id` = ? AND "+journalNotDeleted+" LIMIT 1", strconv.Itoa(id)))
+}
+
+// FindBySlug Find a journal by slug, ignoring any in the trash
 func (js *Journals) FindBySlug(slug string) Journal {
-	return js.loadSingle(js.Container.Db.Query("SELECT * FROM `"+journalTable+"` WHERE `slug` = ? LIMIT 1", slug))
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `slug` = ? AND "+journalNotDeleted+" LIMIT 1", slug))
+}
+
+// FindByTag Find the published, listed journals given a tag, by its name or slug
+func (js *Journals) FindByTag(tag string) []Journal {
+	slug := TagSlug(tag)
+	if slug == "" {
+		return []Journal{}
+	}
+
+	return js.FindAll(JournalOptions{Tag: slug})
+}
+
+// FindDeletedBySlug Find a journal in the trash by slug
+func (js *Journals) FindDeletedBySlug(slug string) Journal {
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `slug` = ? AND `deleted_at` != '' LIMIT 1", slug))
 }
 
-// FindNext returns the next entry after an ID
+// FindNext returns the next published entry after an ID
 func (js *Journals) FindNext(id int) Journal {
-	return js.loadSingle(js.Container.Db.Query("SELECT * FROM `"+journalTable+"` WHERE `id` > ? ORDER BY `id` LIMIT 1", strconv.Itoa(id)))
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `id` > ? AND `status` = ? AND "+journalNotDeleted+" AND "+journalListed+" ORDER BY `id` LIMIT 1", strconv.Itoa(id), JournalStatusPublished))
 }
 
-// FindNext returns the previous entry before an ID
+// FindNext returns the previous published entry before an ID
 func (js *Journals) FindPrev(id int) Journal {
-	return js.loadSingle(js.Container.Db.Query("SELECT * FROM `"+journalTable+"` WHERE `id` < ? ORDER BY `id` DESC LIMIT 1", strconv.Itoa(id)))
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `id` < ? AND `status` = ? AND "+journalNotDeleted+" AND "+journalListed+" ORDER BY `id` DESC LIMIT 1", strconv.Itoa(id), JournalStatusPublished))
 }
 
-// Save Save a journal entry, either inserting it or updating it in the database
-func (js *Journals) Save(j Journal) Journal {
+// FetchDeleted Get all journals in the trash, most recently deleted first
+func (js *Journals) FetchDeleted() []Journal {
+	rows, err := js.Container.Db.Query("SELECT " + journalColumns + " FROM `" + journalTable + "` WHERE `deleted_at` != '' ORDER BY `deleted_at` DESC")
+	if err != nil {
+		return []Journal{}
+	}
+
+	return js.loadFromRows(rows)
+}
+
+// FetchExpired Get the journals left in the trash for longer than the given number of days, oldest first
+func (js *Journals) FetchExpired(days int) []Journal {
+	cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(jobTimeFormat)
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `deleted_at` != '' AND `deleted_at` <= ? ORDER BY `deleted_at`", cutoff)
+	if err != nil {
+		return []Journal{}
+	}
+
+	return js.loadFromRows(rows)
+}
+
+// PublishDue Publish every scheduled journal whose time has passed, returning the journals published
+func (js *Journals) PublishDue() []Journal {
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `status` = ? AND `publish_at` <= ? AND "+journalNotDeleted, JournalStatusScheduled, time.Now().UTC().Format(jobTimeFormat))
+	if err != nil {
+		return []Journal{}
+	}
+
+	published := []Journal{}
+	for _, j := range js.loadFromRows(rows) {
+		// Only one request may move each journal out of scheduled
+		res, err := js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `status` = ? WHERE `id` = ? AND `status` = ?", JournalStatusPublished, strconv.Itoa(j.ID), JournalStatusScheduled)
+		if err != nil {
+			continue
+		}
+		if affected, _ := res.RowsAffected(); affected != 1 {
+			continue
+		}
+		j.Status = JournalStatusPublished
+		published = append(published, j)
+	}
+
+	return published
+}
+
+// SlugTaken Check whether a slug is used by an entry other than the given one, including any in the trash, or by a
+// journal hosted beneath a path prefix, which would hide an entry of the journal itself
+func (js *Journals) SlugTaken(slug string, id int) bool {
+	exists := js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `slug` = ? AND `id` != ? LIMIT 1", slug, strconv.Itoa(id)))
+	if exists.ID > 0 {
+		return true
+	}
+	if js.Container.Tenant == "" && js.Container.Configuration.TenantMode == app.TenantModePath {
+		ts := Tenants{Container: js.Container}
+		return ts.FindByName(slug).ID > 0
+	}
+
+	return false
+}
+
+// QuotaReached Check whether a hosted tenant has used up its allowance of entries
+func (js *Journals) QuotaReached() bool {
+	max := js.Container.Configuration.TenantMaxEntries
+	if js.Container.Tenant == "" || max == 0 {
+		return false
+	}
+
+	return js.Count() >= max
+}
+
+// Restore Take a journal entry back out of the trash
+func (js *Journals) Restore(j Journal) error {
+	_, err := js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `deleted_at` = '' WHERE `id` = ?", strconv.Itoa(j.ID))
+
+	return err
+}
+
+// Save Save a journal entry, either inserting it or updating it in the database, returning any error doing so
+func (js *Journals) Save(j Journal) (Journal, error) {
 	var res sql.Result
 
 	// Convert content for saving
@@ -148,12 +680,40 @@ func (js *Journals) Save(j Journal) Journal {
 	if j.Slug == "" {
 		j.Slug = Slugify(j.Title)
 	}
+	if j.Status == JournalStatusScheduled && j.PublishAt == "" {
+		j.Status = JournalStatusDraft
+	}
+	if j.Status != JournalStatusDraft && j.Status != JournalStatusScheduled {
+		j.Status = JournalStatusPublished
+	}
+	if j.Status != JournalStatusScheduled {
+		j.PublishAt = ""
+	}
+	if j.Comments != JournalCommentsClosed {
+		j.Comments = JournalCommentsOpen
+	}
+	if j.IsListed() {
+		j.Visibility = JournalVisibilityPublic
+	}
+
+	j.WordCount = CountWords(j.Content)
+	j.ReadingTime = ReadingMinutes(j.WordCount)
+
+	pinned := "0"
+	if j.Pinned {
+		pinned = "1"
+	}
 
+	var err error
 	if j.ID == 0 {
 		j.Slug = js.EnsureUniqueSlug(j.Slug, 0)
-		res, _ = js.Container.Db.Exec("INSERT INTO `"+journalTable+"` (`slug`, `title`, `date`, `content`) VALUES(?,?,?,?)", j.Slug, j.Title, j.Date, j.Content)
+		res, err = js.Container.Db.Exec("INSERT INTO `"+journalTable+"` (`slug`, `title`, `date`, `content`, `status`, `comments`, `publish_at`, `category_id`, `excerpt`, `pinned`, `visibility`, `password_hash`, `word_count`, `reading_time`, `author_id`) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)", j.Slug, j.Title, j.Date, sealed(js.Container, j.Content), j.Status, j.Comments, j.PublishAt, strconv.Itoa(j.CategoryID), sealed(js.Container, j.Excerpt), pinned, j.Visibility, j.PasswordHash, strconv.Itoa(j.WordCount), strconv.Itoa(j.ReadingTime), strconv.Itoa(j.AuthorID))
 	} else {
-		res, _ = js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `slug` = ?, `title` = ?, `date` = ?, `content` = ? WHERE `id` = ?", j.Slug, j.Title, j.Date, j.Content, strconv.Itoa(j.ID))
+		res, err = js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `slug` = ?, `title` = ?, `date` = ?, `content` = ?, `status` = ?, `comments` = ?, `publish_at` = ?, `category_id` = ?, `excerpt` = ?, `pinned` = ?, `visibility` = ?, `password_hash` = ?, `word_count` = ?, `reading_time` = ? WHERE `id` = ?", j.Slug, j.Title, j.Date, sealed(js.Container, j.Content), j.Status, j.Comments, j.PublishAt, strconv.Itoa(j.CategoryID), sealed(js.Container, j.Excerpt), pinned, j.Visibility, j.PasswordHash, strconv.Itoa(j.WordCount), strconv.Itoa(j.ReadingTime), strconv.Itoa(j.ID))
+	}
+
+	if err != nil {
+		return j, err
 	}
 
 	// Store insert ID
@@ -162,7 +722,7 @@ func (js *Journals) Save(j Journal) Journal {
 		j.ID = int(id)
 	}
 
-	return j
+	return j, nil
 }
 
 func (js Journals) loadFromRows(rows rows.Rows) []Journal {
@@ -170,7 +730,9 @@ func (js Journals) loadFromRows(rows rows.Rows) []Journal {
 	journals := []Journal{}
 	for rows.Next() {
 		j := Journal{}
-		rows.Scan(&j.ID, &j.Slug, &j.Title, &j.Date, &j.Content)
+		rows.Scan(&j.ID, &j.Slug, &j.Title, &j.Date, &j.Content, &j.Status, &j.DeletedAt, &j.Comments, &j.PublishAt, &j.CategoryID, &j.Excerpt, &j.Pinned, &j.Visibility, &j.PasswordHash, &j.WordCount, &j.ReadingTime, &j.AuthorID)
+		j.Content = opened(js.Container, j.Content)
+		j.Excerpt = opened(js.Container, j.Excerpt)
 		journals = append(journals, j)
 	}
 
@@ -190,6 +752,42 @@ func (js *Journals) loadSingle(rows rows.Rows, err error) Journal {
 	return Journal{}
 }
 
+// Trash Move a journal entry to the trash, from where it can be restored or deleted permanently
+func (js *Journals) Trash(j Journal) error {
+	_, err := js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `deleted_at` = ? WHERE `id` = ?", time.Now().UTC().Format(jobTimeFormat), strconv.Itoa(j.ID))
+
+	return err
+}
+
+// reserved Index a list of paths, to check whether a name is among them
+func reserved(paths []string) map[string]bool {
+	index := map[string]bool{}
+	for _, path := range paths {
+		index[path] = true
+	}
+
+	return index
+}
+
+// IsValidSlug Check a slug chosen for an entry can be used in its address without clashing with another page
+func IsValidSlug(slug string) bool {
+	return len(slug) <= 255 && reValidSlug.MatchString(slug) && !reservedSlugs[slug]
+}
+
+// TitleFrom Work out a title for an entry written without one from the start of its text
+func TitleFrom(content string) string {
+	text := strings.Join(strings.Fields(regexp.MustCompile("<[^>]*>").ReplaceAllString(content, " ")), " ")
+	if len([]rune(text)) <= titleLength {
+		return text
+	}
+	runes := []rune(text)[:titleLength]
+	if space := strings.LastIndex(string(runes), " "); space > 0 {
+		return string(runes)[:space] + "..."
+	}
+
+	return string(runes) + "..."
+}
+
 // Slugify Utility to convert a string into a slug
 func Slugify(s string) string {
 	re := regexp.MustCompile("[\\W+]")
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// CanEdit Check whether a user may change or remove the entry, being its author or an admin
+func (j Journal) CanEdit(u User) bool {
+	return u.Role == RoleAdmin || (u.ID > 0 && u.ID == j.AuthorID)
+}
+
+// FetchPaginatedByAuthor returns a set of paginated, published journal entries written by a user
+func (js *Journals) FetchPaginatedByAuthor(authorID int, query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+	where := "`status` = ? AND " + journalNotDeleted + " AND " + journalListed + " AND `author_id` = ?"
+
+	countResult, err := js.Container.Db.Query("SELECT COUNT(*) AS `total` FROM `"+journalTable+"` WHERE "+where, JournalStatusPublished, strconv.Itoa(authorID))
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Journal{}, pagination
+	}
+
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+where+" ORDER BY `date` DESC, `id` DESC LIMIT ? OFFSET ?", JournalStatusPublished, strconv.Itoa(authorID), query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	return js.loadFromRows(rows), pagination
+}
+
+// LoadAuthors Attach the username of each entry's author, looking up every author only once
+func (us *Users) LoadAuthors(journals []Journal) []Journal {
+	names := map[int]string{}
+	for i, j := range journals {
+		if j.AuthorID == 0 {
+			continue
+		}
+		name, ok := names[j.AuthorID]
+		if !ok {
+			name = us.FindByID(j.AuthorID).Username
+			names[j.AuthorID] = name
+		}
+		journals[i].Author = name
+	}
+
+	return journals
+}
+
+// LoadAuthor Attach the username of an entry's author
+func (us *Users) LoadAuthor(j Journal) Journal {
+	return us.LoadAuthors([]Journal{j})[0]
+}
+
+// addJournalAuthors Add the author column for databases created before entries had authors, giving entries written
+// before then to the first admin, who is shown as having written them
+func addJournalAuthors(c *app.Container) error {
+	js := Journals{Container: c}
+	if err := js.CreateTable(); err != nil {
+		return err
+	}
+	_, err := c.Db.Exec("UPDATE `" + journalTable + "` SET `author_id` = (SELECT MIN(`id`) FROM `" + userTable + "` WHERE `role` = '" + RoleAdmin + "') " +
+		"WHERE `author_id` = 0 AND EXISTS (SELECT 1 FROM `" + userTable + "` WHERE `role` = '" + RoleAdmin + "')")
+
+	return err
+}
+
+// removeJournalAuthors Forget who wrote each entry, leaving the column in place as SQLite cannot drop it
+func removeJournalAuthors(c *app.Container) error {
+	_, err := c.Db.Exec("UPDATE `" + journalTable + "` SET `author_id` = 0")
+
+	return err
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgDb "github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournal_CanEdit(t *testing.T) {
+	j := Journal{AuthorID: 2}
+	tables := []struct {
+		user     User
+		expected bool
+	}{
+		{User{}, false},
+		{User{ID: 1, Role: RoleEditor}, false},
+		{User{ID: 2, Role: RoleEditor}, true},
+		{User{ID: 1, Role: RoleAdmin}, true},
+		{User{Role: RoleAdmin}, true},
+	}
+	for _, table := range tables {
+		if j.CanEdit(table.user) != table.expected {
+			t.Errorf("Expected CanEdit() for %v to be %v", table.user, table.expected)
+		}
+	}
+	if (Journal{}).CanEdit(User{Role: RoleEditor}) {
+		t.Error("Expected entries without an author to only be changed by admins")
+	}
+}
+
+func TestJournals_FetchPaginatedByAuthor(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	query := pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2}
+
+	db.ErrorMode = true
+	if journals, _ := js.FetchPaginatedByAuthor(1, query); len(journals) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.ExpectedArgument = "1"
+	journals, pagination := js.FetchPaginatedByAuthor(1, query)
+	if len(journals) != 2 || pagination.TotalPages != 2 {
+		t.Errorf("Expected 2 rows and 2 pages, got %d and %d", len(journals), pagination.TotalPages)
+	}
+
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	if journals, _ := js.FetchPaginatedByAuthor(1, query); len(journals) > 0 {
+		t.Error("Expected no rows for an author without entries")
+	}
+}
+
+func TestUsers_LoadAuthors(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	db.Rows = &database.MockUser_SingleRow{}
+	journals := us.LoadAuthors([]Journal{{ID: 1, AuthorID: 1}, {ID: 2}, {ID: 3, AuthorID: 1}})
+	if journals[0].Author != "jamie" || journals[1].Author != "" || journals[2].Author != "jamie" || db.Queries != 1 {
+		t.Error("Expected each author to be looked up once and given to their entries")
+	}
+	if us.LoadAuthor(Journal{}).Author != "" || db.Queries != 1 {
+		t.Error("Expected entries without an author to be left alone")
+	}
+}
+
+func TestAddJournalAuthors(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	if err := addJournalAuthors(container); err != nil || db.Queries < 2 {
+		t.Error("Expected column to be added and earlier entries given to the first admin")
+	}
+	db.Queries = 0
+	if err := removeJournalAuthors(container); err != nil || db.Queries != 1 {
+		t.Error("Expected every entry to lose its author")
+	}
+}
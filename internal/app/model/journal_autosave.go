@@ -0,0 +1,123 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const journalAutosaveTable = "journal_autosave"
+
+const journalAutosaveColumns = "`user_id`, `journal_id`, `title`, `date`, `content`, `excerpt`, `saved_at`"
+
+// JournalAutosave model, work in progress kept from the editor until the entry is saved, against journal ID 0 for a new entry.
+// Each user has their own, so that nobody reads or overwrites what another is writing.
+type JournalAutosave struct {
+	UserID    int    `json:"-"`
+	JournalID int    `json:"journal_id"`
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	Content   string `json:"content"`
+	Excerpt   string `json:"excerpt"`
+	SavedAt   string `json:"saved_at"`
+}
+
+// JournalAutosaves Common database resource link for JournalAutosave actions
+type JournalAutosaves struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (as *JournalAutosaves) CreateTable() error {
+	_, err := as.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalAutosaveTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`user_id` INTEGER NOT NULL DEFAULT 0, " +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`title` VARCHAR(255) NOT NULL, " +
+		"`date` VARCHAR(10) NOT NULL, " +
+		"`content` TEXT NOT NULL, " +
+		"`excerpt` TEXT NOT NULL, " +
+		"`saved_at` DATETIME NOT NULL, " +
+		"UNIQUE (`user_id`, `journal_id`)" +
+		")")
+
+	return err
+}
+
+// DeleteByJournal Forget the work in progress of a user on an entry, once it has been saved or discarded
+func (as *JournalAutosaves) DeleteByJournal(userID int, journalID int) error {
+	_, err := as.Container.Db.Exec("DELETE FROM `"+journalAutosaveTable+"` WHERE `user_id` = ? AND `journal_id` = ?", strconv.Itoa(userID), strconv.Itoa(journalID))
+
+	return err
+}
+
+// FindByJournal Find the work in progress of a user on an entry
+func (as *JournalAutosaves) FindByJournal(userID int, journalID int) JournalAutosave {
+	rows, err := as.Container.Db.Query("SELECT "+journalAutosaveColumns+" FROM `"+journalAutosaveTable+"` WHERE `user_id` = ? AND `journal_id` = ? LIMIT 1", strconv.Itoa(userID), strconv.Itoa(journalID))
+	if err != nil {
+		return JournalAutosave{}
+	}
+	autosaves := as.loadFromRows(rows)
+	if len(autosaves) == 1 {
+		return autosaves[0]
+	}
+
+	return JournalAutosave{}
+}
+
+// Save Keep the work in progress of a user on an entry, replacing anything they kept before
+func (as *JournalAutosaves) Save(a JournalAutosave) (JournalAutosave, error) {
+	a.SavedAt = time.Now().UTC().Format(jobTimeFormat)
+	_, err := as.Container.Db.Exec("INSERT INTO `"+journalAutosaveTable+"` ("+journalAutosaveColumns+") VALUES(?,?,?,?,?,?,?) "+
+		"ON CONFLICT (`user_id`, `journal_id`) DO UPDATE SET `title` = excluded.`title`, `date` = excluded.`date`, `content` = excluded.`content`, `excerpt` = excluded.`excerpt`, `saved_at` = excluded.`saved_at`", strconv.Itoa(a.UserID), strconv.Itoa(a.JournalID), a.Title, a.Date, sealed(as.Container, a.Content), sealed(as.Container, a.Excerpt), a.SavedAt)
+
+	return a, err
+}
+
+func (as JournalAutosaves) loadFromRows(rows rows.Rows) []JournalAutosave {
+	defer rows.Close()
+	autosaves := []JournalAutosave{}
+	for rows.Next() {
+		a := JournalAutosave{}
+		rows.Scan(&a.UserID, &a.JournalID, &a.Title, &a.Date, &a.Content, &a.Excerpt, &a.SavedAt)
+		a.Content = opened(as.Container, a.Content)
+		a.Excerpt = opened(as.Container, a.Excerpt)
+		autosaves = append(autosaves, a)
+	}
+
+	return autosaves
+}
+
+// keyAutosavesByUser Keep work in progress apart for each user, rebuilding the table when it was kept once per entry.
+// What was kept before is discarded, as whose it was is not known.
+func keyAutosavesByUser(c *app.Container) error {
+	if rows, err := c.Db.Query("SELECT `user_id` FROM `" + journalAutosaveTable + "` LIMIT 1"); err == nil {
+		rows.Close()
+		return nil
+	}
+	if _, err := c.Db.Exec("DROP TABLE `" + journalAutosaveTable + "`"); err != nil {
+		return err
+	}
+	as := JournalAutosaves{Container: c}
+
+	return as.CreateTable()
+}
+
+// unkeyAutosavesByUser Keep work in progress once per entry again, discarding what was kept
+func unkeyAutosavesByUser(c *app.Container) error {
+	if _, err := c.Db.Exec("DROP TABLE `" + journalAutosaveTable + "`"); err != nil {
+		return err
+	}
+	_, err := c.Db.Exec("CREATE TABLE `" + journalAutosaveTable + "` (" +
+		"`journal_id` INTEGER NOT NULL UNIQUE, " +
+		"`title` VARCHAR(255) NOT NULL, " +
+		"`date` VARCHAR(10) NOT NULL, " +
+		"`content` TEXT NOT NULL, " +
+		"`excerpt` TEXT NOT NULL, " +
+		"`saved_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalAutosaves_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	as := JournalAutosaves{Container: container}
+	as.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestJournalAutosaves_DeleteByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	as := JournalAutosaves{Container: container}
+	db.ExpectedArgument = "2"
+	if err := as.DeleteByJournal(2, 1); err != nil || db.Queries != 1 {
+		t.Error("Expected work in progress to be deleted")
+	}
+}
+
+func TestJournalAutosaves_FindByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	as := JournalAutosaves{Container: container}
+	if as.FindByJournal(2, 1).SavedAt != "" {
+		t.Error("Expected empty result returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	if as.FindByJournal(2, 1).SavedAt != "" {
+		t.Error("Expected empty result returned")
+	}
+
+	db.ExpectedArgument = "2"
+	db.Rows = &database.MockJournalAutosave_SingleRow{}
+	autosave := as.FindByJournal(2, 1)
+	if autosave.UserID != 2 || autosave.JournalID != 1 || autosave.Content != "Unsaved content" || autosave.SavedAt != "2018-02-02 10:00:00" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestJournalAutosaves_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	as := JournalAutosaves{Container: container}
+	db.ExpectedArgument = "2"
+	autosave, err := as.Save(JournalAutosave{UserID: 2, Title: "Unsaved", Content: "Unsaved content"})
+	if err != nil || db.Queries != 1 || autosave.SavedAt == "" {
+		t.Error("Expected work in progress to be saved with the time")
+	}
+
+	db.ErrorMode = true
+	if _, err := as.Save(JournalAutosave{}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestKeyAutosavesByUser(t *testing.T) {
+	db := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	container := &app.Container{Db: db}
+	if err := keyAutosavesByUser(container); err != nil || db.Queries != 1 {
+		t.Error("Expected a table already kept by user to be left alone")
+	}
+
+	db.Queries = 0
+	db.ErrorAtQuery = 1
+	if err := keyAutosavesByUser(container); err != nil || db.Queries != 3 {
+		t.Errorf("Expected a table kept once per entry to be rebuilt, got %v after %d queries", err, db.Queries)
+	}
+
+	db.Queries = 0
+	db.ErrorAtQuery = 0
+	if err := unkeyAutosavesByUser(container); err != nil || db.Queries != 2 {
+		t.Error("Expected the table to be rebuilt once per entry")
+	}
+}
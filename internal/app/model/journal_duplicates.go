@@ -0,0 +1,85 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DuplicateThreshold How similar two titles must be, from 0 to 1, for one entry to be taken as a duplicate of another
+const DuplicateThreshold = 0.85
+
+// normaliseTitle Lowercase a title and drop punctuation and repeated spaces, so only its words are compared
+func normaliseTitle(title string) []rune {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	return []rune(strings.Join(words, " "))
+}
+
+// TitleSimilarity Compare two titles ignoring case and punctuation, from 0 for nothing in common to 1 for the same
+func TitleSimilarity(a, b string) float64 {
+	ra, rb := normaliseTitle(a), normaliseTitle(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	// Edit distance, keeping only the previous row
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// FindDuplicate Find another entry, including drafts, with the same slug or a nearly identical title, preferring the
+// same slug and then the closest title
+func (js *Journals) FindDuplicate(j Journal) Journal {
+	// Titles too far apart in length cannot be similar enough, so leave them out of the query, allowing longer ones
+	// more room for the punctuation that is ignored when comparing
+	length := len(normaliseTitle(j.Title))
+	spread := int(float64(length)*(1-DuplicateThreshold)) + 1
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE "+journalNotDeleted+" AND `id` != ? AND (`slug` = ? OR LENGTH(`title`) BETWEEN CAST(? AS INTEGER) AND CAST(? AS INTEGER)) ORDER BY `id`",
+		strconv.Itoa(j.ID), j.Slug, strconv.Itoa(length-spread), strconv.Itoa(length+spread*2))
+	if err != nil {
+		return Journal{}
+	}
+
+	best := Journal{}
+	bestSimilarity := 0.0
+	for _, candidate := range js.loadFromRows(rows) {
+		if j.Slug != "" && candidate.Slug == j.Slug {
+			return candidate
+		}
+		if similarity := TitleSimilarity(j.Title, candidate.Title); similarity >= DuplicateThreshold && similarity > bestSimilarity {
+			best = candidate
+			bestSimilarity = similarity
+		}
+	}
+
+	return best
+}
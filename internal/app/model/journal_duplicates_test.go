@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	if TitleSimilarity("A Day at the Beach", "a day at the beach!") != 1 {
+		t.Error("Expected case and punctuation to be ignored")
+	}
+	if similarity := TitleSimilarity("A Day at the Beach", "A Day at the Beech"); similarity < DuplicateThreshold {
+		t.Errorf("Expected nearly identical titles to be similar, got %f", similarity)
+	}
+	if similarity := TitleSimilarity("A Day at the Beach", "Notes on Baking"); similarity >= DuplicateThreshold {
+		t.Errorf("Expected different titles not to be similar, got %f", similarity)
+	}
+	if TitleSimilarity("", "!!") != 0 {
+		t.Error("Expected empty titles to have nothing in common")
+	}
+}
+
+func TestJournals_FindDuplicate(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if js.FindDuplicate(Journal{Slug: "slug", Title: "Title"}).ID > 0 {
+		t.Error("Expected no duplicate when nothing is found")
+	}
+
+	// Test the same slug is preferred over a similar title
+	db.Rows = &database.MockJournal_MultipleRows{}
+	if duplicate := js.FindDuplicate(Journal{Slug: "slug-2", Title: "Title"}); duplicate.ID != 2 {
+		t.Errorf("Expected entry with the same slug to be found, got %d", duplicate.ID)
+	}
+
+	// Test the closest title is found
+	db.Rows = &database.MockJournal_MultipleRows{}
+	if duplicate := js.FindDuplicate(Journal{Slug: "title-3", Title: "Title 2!"}); duplicate.ID != 2 {
+		t.Errorf("Expected entry with the closest title to be found, got %d", duplicate.ID)
+	}
+
+	// Test titles that are not close enough are not duplicates
+	db.Rows = &database.MockJournal_MultipleRows{}
+	if duplicate := js.FindDuplicate(Journal{Slug: "other", Title: "Something Else"}); duplicate.ID > 0 {
+		t.Error("Expected no duplicate for a different title")
+	}
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const journalLinkTable = "journal_link"
+
+// Link relations stored against an entry
+const (
+	LinkCanonical   = "canonical"
+	LinkSyndication = "syndication"
+)
+
+// JournalLink model, an external URL related to an entry
+type JournalLink struct {
+	ID        int    `json:"id"`
+	JournalID int    `json:"journal_id"`
+	Rel       string `json:"rel"`
+	URL       string `json:"url"`
+}
+
+// JournalLinks Common database resource link for JournalLink actions
+type JournalLinks struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ls *JournalLinks) CreateTable() error {
+	_, err := ls.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalLinkTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`rel` VARCHAR(20) NOT NULL, " +
+		"`url` VARCHAR(2048) NOT NULL" +
+		")")
+
+	return err
+}
+
+// FetchByJournal Get every link stored for an entry
+func (ls *JournalLinks) FetchByJournal(journalID int) []JournalLink {
+	rows, err := ls.Container.Db.Query("SELECT `id`, `journal_id`, `rel`, `url` FROM `"+journalLinkTable+"` WHERE `journal_id` = ? ORDER BY `id`", strconv.Itoa(journalID))
+	if err != nil {
+		return []JournalLink{}
+	}
+
+	return ls.loadFromRows(rows)
+}
+
+// Load Attach the canonical and syndication URLs stored for an entry
+func (ls *JournalLinks) Load(j Journal) Journal {
+	j.CanonicalURL = ""
+	j.Syndication = nil
+	for _, l := range ls.FetchByJournal(j.ID) {
+		if l.Rel == LinkCanonical {
+			j.CanonicalURL = l.URL
+		} else if l.Rel == LinkSyndication {
+			j.Syndication = append(j.Syndication, l.URL)
+		}
+	}
+
+	return j
+}
+
+// Save Replace the canonical and syndication URLs stored for an entry
+func (ls *JournalLinks) Save(j Journal) error {
+	if j.ID == 0 {
+		return errors.New("Entry must be saved before its links")
+	}
+	if _, err := ls.Container.Db.Exec("DELETE FROM `"+journalLinkTable+"` WHERE `journal_id` = ?", strconv.Itoa(j.ID)); err != nil {
+		return err
+	}
+
+	links := []JournalLink{}
+	if j.CanonicalURL != "" {
+		links = append(links, JournalLink{Rel: LinkCanonical, URL: j.CanonicalURL})
+	}
+	for _, u := range j.Syndication {
+		links = append(links, JournalLink{Rel: LinkSyndication, URL: u})
+	}
+	for _, l := range links {
+		if _, err := ls.Container.Db.Exec("INSERT INTO `"+journalLinkTable+"` (`journal_id`, `rel`, `url`) VALUES(?,?,?)", strconv.Itoa(j.ID), l.Rel, l.URL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsValidLinkURL Check a link is an absolute web address
+func IsValidLinkURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ParseLinkURLs Split a block of text into URLs, one per line, rejecting any that are not web addresses
+func ParseLinkURLs(text string) ([]string, error) {
+	urls := []string{}
+	for _, line := range strings.Fields(text) {
+		if !IsValidLinkURL(line) {
+			return urls, errors.New("Not a valid URL: " + line)
+		}
+		urls = append(urls, line)
+	}
+
+	return urls, nil
+}
+
+func (ls JournalLinks) loadFromRows(rows rows.Rows) []JournalLink {
+	defer rows.Close()
+	links := []JournalLink{}
+	for rows.Next() {
+		l := JournalLink{}
+		rows.Scan(&l.ID, &l.JournalID, &l.Rel, &l.URL)
+		links = append(links, l)
+	}
+
+	return links
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalLinks_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ls := JournalLinks{Container: container}
+	ls.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestJournalLinks_FetchByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ls := JournalLinks{Container: container}
+	if len(ls.FetchByJournal(1)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockJournalLink_MultipleRows{}
+	links := ls.FetchByJournal(1)
+	if len(links) != 2 || links[0].Rel != LinkCanonical || links[1].Rel != LinkSyndication {
+		t.Error("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournalLinks_Load(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ls := JournalLinks{Container: container}
+	db.Rows = &database.MockJournalLink_MultipleRows{}
+	j := ls.Load(Journal{ID: 1, CanonicalURL: "https://old.example.com"})
+	if j.CanonicalURL != "https://example.com/original" || len(j.Syndication) != 1 || j.Syndication[0] != "https://mastodon.example/@jamie/1" {
+		t.Error("Expected canonical and syndication URLs to have been loaded")
+	}
+}
+
+func TestJournalLinks_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ls := JournalLinks{Container: container}
+
+	// Test unsaved entry
+	if err := ls.Save(Journal{}); err == nil || db.Queries != 0 {
+		t.Error("Expected error for an entry without an ID")
+	}
+
+	// Test links replaced
+	j := Journal{ID: 1, CanonicalURL: "https://example.com/original", Syndication: []string{"https://a.example", "https://b.example"}}
+	if err := ls.Save(j); err != nil || db.Queries != 4 {
+		t.Error("Expected existing links to be removed and 3 inserted")
+	}
+
+	// Test failure
+	db.ErrorAtQuery = db.Queries + 2
+	if err := ls.Save(j); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestParseLinkURLs(t *testing.T) {
+	urls, err := ParseLinkURLs(" https://a.example/1\r\nhttp://b.example/2\n\n")
+	if err != nil || len(urls) != 2 || urls[1] != "http://b.example/2" {
+		t.Error("Expected 2 URLs to be parsed")
+	}
+
+	urls, err = ParseLinkURLs("")
+	if err != nil || len(urls) != 0 {
+		t.Error("Expected no URLs to be parsed from empty text")
+	}
+
+	for _, invalid := range []string{"example.com", "ftp://example.com", "javascript:alert(1)", "https://"} {
+		if _, err := ParseLinkURLs(invalid); err == nil {
+			t.Errorf("Expected %s to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+const journalMetaTable = "journal_meta"
+
+// metaKeyLength Longest name a custom field may have
+const metaKeyLength = 64
+
+var reValidMetaKey = regexp.MustCompile("^[a-z0-9][a-z0-9_\\-]*$")
+
+// ErrInvalidMetaKey A custom field name that is not lower case letters, numbers, dashes and underscores
+var ErrInvalidMetaKey = errors.New("Custom field names may only use lower case letters, numbers, dashes and underscores")
+
+// JournalMetas Common database resource link for the custom fields of entries, such as location, mood or weather,
+// kept as names and values so any can be added without changing the schema
+type JournalMetas struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table, keeping one row for each custom field of an entry
+func (ms *JournalMetas) CreateTable() error {
+	_, err := ms.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalMetaTable + "` (" +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`key` VARCHAR(64) NOT NULL, " +
+		"`value` TEXT NOT NULL, " +
+		"PRIMARY KEY (`journal_id`, `key`)" +
+		")")
+
+	return err
+}
+
+// GetMeta Get the value of a custom field of an entry, or an empty string when it has none
+func (ms *JournalMetas) GetMeta(journalID int, key string) string {
+	value := ""
+	rows, err := ms.Container.Db.Query("SELECT `value` FROM `"+journalMetaTable+"` WHERE `journal_id` = ? AND `key` = ? LIMIT 1", strconv.Itoa(journalID), key)
+	if err != nil {
+		return value
+	}
+	defer rows.Close()
+	if rows.Next() {
+		rows.Scan(&value)
+	}
+
+	return value
+}
+
+// SetMeta Set a custom field of an entry, an empty value removing it
+func (ms *JournalMetas) SetMeta(journalID int, key string, value string) error {
+	if !IsValidMetaKey(key) {
+		return ErrInvalidMetaKey
+	}
+	if value == "" {
+		_, err := ms.Container.Db.Exec("DELETE FROM `"+journalMetaTable+"` WHERE `journal_id` = ? AND `key` = ?", strconv.Itoa(journalID), key)
+		return err
+	}
+	_, err := ms.Container.Db.Exec("INSERT INTO `"+journalMetaTable+"` (`journal_id`, `key`, `value`) VALUES(?,?,?) "+
+		"ON CONFLICT (`journal_id`, `key`) DO UPDATE SET `value` = excluded.`value`", strconv.Itoa(journalID), key, value)
+
+	return err
+}
+
+// FetchByJournal Get every custom field of an entry
+func (ms *JournalMetas) FetchByJournal(journalID int) map[string]string {
+	meta := map[string]string{}
+	rows, err := ms.Container.Db.Query("SELECT `key`, `value` FROM `"+journalMetaTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+	if err != nil {
+		return meta
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		rows.Scan(&key, &value)
+		meta[key] = value
+	}
+
+	return meta
+}
+
+// Load Attach the custom fields stored for an entry
+func (ms *JournalMetas) Load(j Journal) Journal {
+	j.Meta = ms.FetchByJournal(j.ID)
+
+	return j
+}
+
+// Save Replace the custom fields stored for an entry with those it carries
+func (ms *JournalMetas) Save(j Journal) error {
+	if j.ID == 0 {
+		return errors.New("Entry must be saved before its custom fields")
+	}
+	if err := ms.DeleteByJournal(j.ID); err != nil {
+		return err
+	}
+	for key, value := range j.Meta {
+		if err := ms.SetMeta(j.ID, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteByJournal Remove every custom field of an entry
+func (ms *JournalMetas) DeleteByJournal(journalID int) error {
+	_, err := ms.Container.Db.Exec("DELETE FROM `"+journalMetaTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+
+	return err
+}
+
+// GetMeta Get the value of a custom field loaded for the entry, for use in templates
+func (j Journal) GetMeta(key string) string {
+	return j.Meta[key]
+}
+
+// MetaKeys Get the names of the custom fields loaded for the entry, in order
+func (j Journal) MetaKeys() []string {
+	keys := []string{}
+	for key := range j.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// IsValidMetaKey Check a custom field name is lower case letters, numbers, dashes and underscores
+func IsValidMetaKey(key string) bool {
+	return len(key) <= metaKeyLength && reValidMetaKey.MatchString(key)
+}
+
+// ParseMeta Read custom fields written one to a line as name: value, ignoring blank lines
+func ParseMeta(text string) (map[string]string, error) {
+	meta := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || !IsValidMetaKey(key) {
+			return meta, ErrInvalidMetaKey
+		}
+		if value := strings.TrimSpace(parts[1]); value != "" {
+			meta[key] = value
+		}
+	}
+
+	return meta, nil
+}
+
+// createJournalMetaTable Create the table for databases created before custom fields were kept
+func createJournalMetaTable(c *app.Container) error {
+	ms := JournalMetas{Container: c}
+
+	return ms.CreateTable()
+}
+
+func dropJournalMetaTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + journalMetaTable + "`")
+
+	return err
+}
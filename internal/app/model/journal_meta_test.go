@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalMetas_GetMeta(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ms := JournalMetas{Container: container}
+	if ms.GetMeta(1, "mood") != "" {
+		t.Error("Expected empty value returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "mood"
+	db.Rows = &database.MockJournalMeta_MultipleRows{}
+	if value := ms.GetMeta(1, "mood"); value != "Happy" {
+		t.Errorf("Expected value of the field to be returned, got %s", value)
+	}
+}
+
+func TestJournalMetas_SetMeta(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ms := JournalMetas{Container: container}
+	if err := ms.SetMeta(1, "Not Valid", "value"); err != ErrInvalidMetaKey || db.Queries != 0 {
+		t.Error("Expected invalid name to be refused")
+	}
+
+	db.ExpectedArgument = "Sunny"
+	if err := ms.SetMeta(1, "weather", "Sunny"); err != nil || db.Queries != 1 {
+		t.Errorf("Expected field to be set, got %v", err)
+	}
+	db.ExpectedArgument = "weather"
+	if err := ms.SetMeta(1, "weather", ""); err != nil || db.Queries != 2 {
+		t.Errorf("Expected empty value to remove the field, got %v", err)
+	}
+}
+
+func TestJournalMetas_Load(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ms := JournalMetas{Container: container}
+	db.Rows = &database.MockJournalMeta_MultipleRows{}
+	j := ms.Load(Journal{ID: 1})
+	if j.GetMeta("mood") != "Happy" || j.GetMeta("weather") != "Sunny" || j.GetMeta("location") != "" {
+		t.Errorf("Expected custom fields to have been loaded, got %v", j.Meta)
+	}
+	if keys := j.MetaKeys(); len(keys) != 2 || keys[0] != "mood" {
+		t.Errorf("Expected names in order, got %v", keys)
+	}
+}
+
+func TestJournalMetas_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ms := JournalMetas{Container: container}
+	if err := ms.Save(Journal{}); err == nil || db.Queries != 0 {
+		t.Error("Expected error for an entry without an ID")
+	}
+
+	if err := ms.Save(Journal{ID: 1, Meta: map[string]string{"mood": "Happy", "weather": "Sunny"}}); err != nil || db.Queries != 3 {
+		t.Errorf("Expected fields to be replaced, got %d queries", db.Queries)
+	}
+
+	db.ErrorAtQuery = db.Queries + 2
+	if err := ms.Save(Journal{ID: 1, Meta: map[string]string{"mood": "Happy"}}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	meta, err := ParseMeta("Mood: Happy\n\nlocation: Paris: France\nweather:\n")
+	if err != nil || len(meta) != 2 || meta["mood"] != "Happy" || meta["location"] != "Paris: France" {
+		t.Errorf("Expected fields to be read one to a line, got %v and %v", meta, err)
+	}
+	for _, text := range []string{"no separator", "bad name: value", ": value"} {
+		if _, err := ParseMeta(text); err != ErrInvalidMetaKey {
+			t.Errorf("Expected %s to be refused", text)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// IsProtected Check whether the entry asks readers for a password before showing it
+func (j Journal) IsProtected() bool {
+	return j.PasswordHash != ""
+}
+
+// CheckPassword Compare a plain password against the one protecting the entry
+func (j Journal) CheckPassword(password string) bool {
+	if j.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(j.PasswordHash), []byte(password)) == nil
+}
+
+// SetPassword Hash and store a password protecting the entry
+func (j *Journal) SetPassword(password string) error {
+	if password == "" {
+		return errors.New("Password must not be empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	j.PasswordHash = string(hash)
+
+	return nil
+}
+
+// UnlockToken Get the token remembering a reader gave the entry's password, which
+// is signed with the stored hash so that changing the password forgets every reader
+func (j Journal) UnlockToken() string {
+	if j.PasswordHash == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(j.PasswordHash))
+	mac.Write([]byte("unlock:" + strconv.Itoa(j.ID)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckUnlockToken Check a token given back by a reader unlocks the entry
+func (j Journal) CheckUnlockToken(token string) bool {
+	expected := j.UnlockToken()
+	return expected != "" && hmac.Equal([]byte(token), []byte(expected))
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestJournal_Password(t *testing.T) {
+	j := Journal{ID: 1}
+	if j.IsProtected() || j.CheckPassword("") || j.UnlockToken() != "" || j.CheckUnlockToken("") {
+		t.Error("Expected Journal without a password to be unprotected")
+	}
+	if err := j.SetPassword(""); err == nil {
+		t.Error("Expected empty password to be refused")
+	}
+
+	if err := j.SetPassword("secret"); err != nil || !j.IsProtected() || j.IsListed() {
+		t.Error("Expected Journal to be protected and left off listings")
+	}
+	if !j.CheckPassword("secret") || j.CheckPassword("wrong") {
+		t.Error("Expected only the right password to be accepted")
+	}
+
+	token := j.UnlockToken()
+	if token == "" || !j.CheckUnlockToken(token) || j.CheckUnlockToken("wrong") {
+		t.Error("Expected only the unlock token to be accepted")
+	}
+	other := Journal{ID: 2, PasswordHash: j.PasswordHash}
+	if other.CheckUnlockToken(token) {
+		t.Error("Expected unlock token to be tied to its entry")
+	}
+	j.SetPassword("changed")
+	if j.CheckUnlockToken(token) {
+		t.Error("Expected changing the password to forget earlier unlock tokens")
+	}
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// relatedStopWords Common words that say nothing about what an entry is about
+var relatedStopWords = map[string]bool{
+	"and": true, "are": true, "but": true, "for": true, "from": true, "has": true, "have": true,
+	"her": true, "his": true, "how": true, "its": true, "not": true, "our": true, "that": true,
+	"the": true, "their": true, "this": true, "was": true, "what": true, "when": true, "with": true,
+	"you": true, "your": true,
+}
+
+var reRelatedWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// FetchRelated Get up to the given number of published entries sharing a category, the tags loaded for a journal or
+// title words with it, best matches first
+func (js *Journals) FetchRelated(j Journal, limit int) []Journal {
+	words := titleWords(j.Title)
+	conditions := []string{}
+	args := []interface{}{JournalStatusPublished, strconv.Itoa(j.ID)}
+	if j.CategoryID > 0 {
+		conditions = append(conditions, "`category_id` = ?")
+		args = append(args, strconv.Itoa(j.CategoryID))
+	}
+	slugs := tagSlugs(j.Tags)
+	if len(slugs) > 0 {
+		conditions = append(conditions, "`id` IN (SELECT `journal_id` FROM `"+journalTagTable+"` WHERE `slug` IN (?"+strings.Repeat(", ?", len(slugs)-1)+"))")
+		for slug := range slugs {
+			args = append(args, slug)
+		}
+	}
+	escape := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	for word := range words {
+		conditions = append(conditions, "`title` LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escape.Replace(word)+"%")
+	}
+	if len(conditions) == 0 {
+		return []Journal{}
+	}
+
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `status` = ? AND "+journalNotDeleted+" AND "+journalListed+" AND `id` != ? AND ("+strings.Join(conditions, " OR ")+")", args...)
+	if err != nil {
+		return []Journal{}
+	}
+
+	candidates := js.loadFromRows(rows)
+	if len(slugs) > 0 {
+		ts := JournalTags{Container: js.Container}
+		candidates = ts.LoadAll(candidates)
+	}
+
+	return rankRelated(j, candidates, limit)
+}
+
+// rankRelated Order candidates by how closely they relate to a journal, newest first on a tie, dropping any unrelated
+func rankRelated(j Journal, candidates []Journal, limit int) []Journal {
+	scores := map[int]float64{}
+	related := []Journal{}
+	for _, c := range candidates {
+		if score := relatedScore(j, c); score > 0 {
+			scores[c.ID] = score
+			related = append(related, c)
+		}
+	}
+	sort.SliceStable(related, func(a, b int) bool {
+		if scores[related[a].ID] != scores[related[b].ID] {
+			return scores[related[a].ID] > scores[related[b].ID]
+		}
+		return related[a].Date > related[b].Date
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related
+}
+
+// relatedScore Score how closely two entries relate, one point for a shared category plus the overlap of their tags
+// and of their title words
+func relatedScore(a Journal, b Journal) float64 {
+	score := 0.0
+	if a.CategoryID > 0 && a.CategoryID == b.CategoryID {
+		score++
+	}
+
+	return score + overlap(tagSlugs(a.Tags), tagSlugs(b.Tags)) + overlap(titleWords(a.Title), titleWords(b.Title))
+}
+
+// overlap How much two sets have in common, from none to all of them
+func overlap(a map[string]bool, b map[string]bool) float64 {
+	shared := 0
+	for item := range a {
+		if b[item] {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// tagSlugs The slugs of the tags named
+func tagSlugs(names []string) map[string]bool {
+	slugs := map[string]bool{}
+	for _, name := range names {
+		if slug := TagSlug(name); slug != "" {
+			slugs[slug] = true
+		}
+	}
+
+	return slugs
+}
+
+// titleWords Split a title into its lower case words, ignoring short and common ones
+func titleWords(title string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range reRelatedWord.FindAllString(strings.ToLower(title), -1) {
+		if len([]rune(word)) > 2 && !relatedStopWords[word] {
+			words[word] = true
+		}
+	}
+
+	return words
+}
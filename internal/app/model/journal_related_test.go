@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournals_FetchRelated(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test nothing to match on
+	if len(js.FetchRelated(Journal{ID: 1, Title: "On the go"}, 3)) > 0 || db.Queries != 0 {
+		t.Error("Expected no query to be run without a category, tags or title words")
+	}
+
+	// Test error
+	db.ErrorMode = true
+	if len(js.FetchRelated(Journal{ID: 1, Title: "Title"}, 3)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test candidates are ranked and the limit applied
+	db.ErrorMode = false
+	db.ExpectedArgument = "%title%"
+	db.Rows = &database.MockJournal_MultipleRows{}
+	related := js.FetchRelated(Journal{ID: 5, Title: "A Title"}, 1)
+	if len(related) != 1 || related[0].ID != 2 {
+		t.Errorf("Expected the newest equally related entry to be returned, got %v", related)
+	}
+
+	// Test entries sharing tags are looked for, and their tags loaded to rank them
+	db.Queries = 0
+	db.ExpectedArgument = "travel"
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	related = js.FetchRelated(Journal{ID: 5, Title: "On the go", Tags: []string{"Travel"}}, 3)
+	if db.Queries != 2 || len(related) > 0 {
+		t.Errorf("Expected entries without the tag to be left out after %d queries, got %v", db.Queries, related)
+	}
+}
+
+func TestRankRelated(t *testing.T) {
+	j := Journal{ID: 1, Title: "Walking the Pennine Way", CategoryID: 2}
+	candidates := []Journal{
+		{ID: 2, Title: "Cooking dinner", Date: "2018-01-01"},
+		{ID: 3, Title: "The Pennine Way, day two", Date: "2018-01-02"},
+		{ID: 4, Title: "Another day out", CategoryID: 2, Date: "2018-01-03"},
+		{ID: 5, Title: "Walking the Pennine Way again", CategoryID: 2, Date: "2018-01-04"},
+	}
+	related := rankRelated(j, candidates, 3)
+	if len(related) != 3 || related[0].ID != 5 || related[1].ID != 4 || related[2].ID != 3 {
+		t.Errorf("Expected entries ranked by category and title overlap, got %v", related)
+	}
+
+	j = Journal{ID: 1, Title: "Lunch", Tags: []string{"Travel", "Food"}}
+	candidates = []Journal{
+		{ID: 2, Title: "Dinner", Tags: []string{"Food"}, Date: "2018-01-01"},
+		{ID: 3, Title: "Breakfast", Tags: []string{"travel", "food"}, Date: "2018-01-02"},
+		{ID: 4, Title: "Supper", Date: "2018-01-03"},
+	}
+	related = rankRelated(j, candidates, 3)
+	if len(related) != 2 || related[0].ID != 3 || related[1].ID != 2 {
+		t.Errorf("Expected entries ranked by the tags they share, got %v", related)
+	}
+}
+
+func TestTitleWords(t *testing.T) {
+	words := titleWords("The Café, at 10 o'clock & Walking")
+	if len(words) != 3 || !words["café"] || !words["clock"] || !words["walking"] {
+		t.Errorf("Expected short and common words to be ignored, got %v", words)
+	}
+}
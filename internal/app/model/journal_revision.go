@@ -0,0 +1,145 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const journalRevisionTable = "journal_revisions"
+
+const journalRevisionColumns = "`id`, `journal_id`, `title`, `date`, `content`, `created_at`"
+
+// JournalRevision model, a previous version of an entry kept whenever it is changed
+type JournalRevision struct {
+	ID        int    `json:"id"`
+	JournalID int    `json:"journal_id"`
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Journal Get the entry as it was in this revision
+func (r JournalRevision) Journal() Journal {
+	return Journal{ID: r.JournalID, Title: r.Title, Date: r.Date, Content: r.Content}
+}
+
+// JournalRevisions Common database resource link for JournalRevision actions
+type JournalRevisions struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (rs *JournalRevisions) CreateTable() error {
+	_, err := rs.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalRevisionTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`title` VARCHAR(255) NOT NULL, " +
+		"`date` DATE NOT NULL, " +
+		"`content` TEXT NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// DeleteByJournal Remove every revision of an entry
+func (rs *JournalRevisions) DeleteByJournal(journalID int) error {
+	_, err := rs.Container.Db.Exec("DELETE FROM `"+journalRevisionTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+
+	return err
+}
+
+// FetchByJournal Get every revision of an entry, newest first
+func (rs *JournalRevisions) FetchByJournal(journalID int) []JournalRevision {
+	rows, err := rs.Container.Db.Query("SELECT "+journalRevisionColumns+" FROM `"+journalRevisionTable+"` WHERE `journal_id` = ? ORDER BY `id` DESC", strconv.Itoa(journalID))
+	if err != nil {
+		return []JournalRevision{}
+	}
+
+	return rs.loadFromRows(rows)
+}
+
+// FindByID Find a single revision belonging to an entry
+func (rs *JournalRevisions) FindByID(journalID int, id int) JournalRevision {
+	rows, err := rs.Container.Db.Query("SELECT "+journalRevisionColumns+" FROM `"+journalRevisionTable+"` WHERE `id` = ? AND `journal_id` = ? LIMIT 1", strconv.Itoa(id), strconv.Itoa(journalID))
+	if err != nil {
+		return JournalRevision{}
+	}
+	revisions := rs.loadFromRows(rows)
+	if len(revisions) == 1 {
+		return revisions[0]
+	}
+
+	return JournalRevision{}
+}
+
+// LastModified Get when any of the entries last changed, being when the latest of their revisions was kept or
+// otherwise the latest of their dates
+func (rs *JournalRevisions) LastModified(journals ...Journal) time.Time {
+	modified := time.Time{}
+	ids := []interface{}{}
+	for _, j := range journals {
+		if j.GetTime().After(modified) {
+			modified = j.GetTime()
+		}
+		ids = append(ids, strconv.Itoa(j.ID))
+	}
+	if len(ids) == 0 {
+		return modified
+	}
+
+	rows, err := rs.Container.Db.Query("SELECT COALESCE(MAX(`created_at`), '') FROM `"+journalRevisionTable+"` "+
+		"WHERE `journal_id` IN (?"+strings.Repeat(", ?", len(ids)-1)+")", ids...)
+	if err != nil {
+		return modified
+	}
+	defer rows.Close()
+	createdAt := ""
+	if rows.Next() {
+		rows.Scan(&createdAt)
+	}
+	if revised, ok := revisedAt(createdAt); ok && revised.After(modified) {
+		modified = revised
+	}
+
+	return modified
+}
+
+// Record Keep the previous version of an entry when saving has changed its title, date or content
+func (rs *JournalRevisions) Record(previous Journal, updated Journal) error {
+	if previous.ID == 0 || (previous.Title == updated.Title && previous.Date == updated.Date && previous.Content == updated.Content) {
+		return nil
+	}
+	_, err := rs.Container.Db.Exec("INSERT INTO `"+journalRevisionTable+"` (`journal_id`, `title`, `date`, `content`, `created_at`) VALUES(?,?,?,?,?)", strconv.Itoa(previous.ID), previous.Title, previous.Date, sealed(rs.Container, previous.Content), time.Now().UTC().Format(jobTimeFormat))
+
+	return err
+}
+
+func (rs JournalRevisions) loadFromRows(rows rows.Rows) []JournalRevision {
+	defer rows.Close()
+	revisions := []JournalRevision{}
+	for rows.Next() {
+		r := JournalRevision{}
+		rows.Scan(&r.ID, &r.JournalID, &r.Title, &r.Date, &r.Content, &r.CreatedAt)
+		r.Content = opened(rs.Container, r.Content)
+		revisions = append(revisions, r)
+	}
+
+	return revisions
+}
+
+// revisedAt Parse when a revision was kept, as read back from any of the databases
+func revisedAt(createdAt string) (time.Time, bool) {
+	for _, layout := range []string{jobTimeFormat, time.RFC3339} {
+		if revised, err := time.Parse(layout, createdAt); err == nil {
+			return revised, true
+		}
+	}
+
+	return time.Time{}, false
+}
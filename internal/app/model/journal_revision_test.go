@@ -0,0 +1,135 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalRevision_Journal(t *testing.T) {
+	r := JournalRevision{ID: 2, JournalID: 1, Title: "Old", Date: "2018-02-01", Content: "Old content"}
+	j := r.Journal()
+	if j.ID != 1 || j.Title != "Old" || j.Date != "2018-02-01" || j.Content != "Old content" {
+		t.Error("Expected revision to be converted into the entry it belongs to")
+	}
+}
+
+func TestJournalRevisions_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	rs.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestJournalRevisions_DeleteByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	db.ExpectedArgument = "1"
+	if err := rs.DeleteByJournal(1); err != nil || db.Queries != 1 {
+		t.Error("Expected revisions to be deleted")
+	}
+}
+
+func TestJournalRevisions_FetchByJournal(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	if len(rs.FetchByJournal(1)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockJournalRevision_MultipleRows{}
+	revisions := rs.FetchByJournal(1)
+	if len(revisions) != 2 || revisions[0].ID != 2 || revisions[1].Title != "First Title" {
+		t.Error("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournalRevisions_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	if rs.FindByID(1, 2).ID != 0 {
+		t.Error("Expected empty revision returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	if rs.FindByID(1, 2).ID != 0 {
+		t.Error("Expected empty revision returned when not found")
+	}
+
+	db.ExpectedArgument = "2"
+	db.Rows = &database.MockJournalRevision_SingleRow{}
+	if revision := rs.FindByID(1, 2); revision.ID != 2 || revision.Title != "Old Title" {
+		t.Error("Expected revision to be found")
+	}
+}
+
+func TestJournalRevisions_LastModified(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	if !rs.LastModified().IsZero() || db.Queries != 0 {
+		t.Error("Expected no time without any entries")
+	}
+
+	// Test the latest date is used without revisions
+	entries := []Journal{{ID: 1, Date: "2018-02-01"}, {ID: 2, Date: "2018-03-01"}}
+	db.Rows = &database.MockJournalRevision_LastModified{}
+	if modified := rs.LastModified(entries...); !modified.Equal(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected latest date of the entries, got %s", modified)
+	}
+
+	// Test a later revision is used
+	db.Rows = &database.MockJournalRevision_LastModified{CreatedAt: "2018-03-04 10:00:00"}
+	if modified := rs.LastModified(entries...); !modified.Equal(time.Date(2018, 3, 4, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected time of the latest revision, got %s", modified)
+	}
+
+	// Test error falls back on the dates
+	db.ErrorMode = true
+	if modified := rs.LastModified(entries[0]); !modified.Equal(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected date of the entry when revisions cannot be read, got %s", modified)
+	}
+}
+
+func TestJournalRevisions_Record(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	rs := JournalRevisions{Container: container}
+	previous := Journal{ID: 1, Title: "Title", Date: "2018-02-01", Content: "Content"}
+
+	// Test nothing recorded for new or unchanged entries
+	if err := rs.Record(Journal{}, previous); err != nil || db.Queries != 0 {
+		t.Error("Expected no revision for a new entry")
+	}
+	updated := previous
+	updated.CanonicalURL = "https://example.com"
+	if err := rs.Record(previous, updated); err != nil || db.Queries != 0 {
+		t.Error("Expected no revision when content is unchanged")
+	}
+
+	// Test previous version recorded
+	updated.Content = "Changed"
+	db.ExpectedArgument = "Content"
+	if err := rs.Record(previous, updated); err != nil || db.Queries != 1 {
+		t.Error("Expected previous version to be recorded")
+	}
+
+	// Test error
+	db.ErrorMode = true
+	if err := rs.Record(previous, updated); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"math"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+const journalSearchTable = "journal_search"
+
+// qualifiedJournalColumns Journal columns prefixed with the table, as the index shares the title and content names
+var qualifiedJournalColumns = "`" + journalTable + "`." + strings.ReplaceAll(journalColumns, ", ", ", `"+journalTable+"`.")
+
+// JournalSearch Full-text index over the title and content of each entry
+type JournalSearch struct {
+	Container *app.Container
+}
+
+// CreateTable Create the FTS5 index and the triggers that keep it in step with the journal table
+func (s *JournalSearch) CreateTable() error {
+	if !s.indexed() {
+		return nil
+	}
+	_, err := s.Container.Db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS `" + journalSearchTable + "` USING fts5(" +
+		"`title`, `content`, content='" + journalTable + "', content_rowid='id'" +
+		")")
+	if err != nil {
+		// SQLite built without FTS5, searches fall back to LIKE matching
+		if strings.Contains(err.Error(), "no such module") {
+			return nil
+		}
+		return err
+	}
+
+	triggers := []string{
+		"CREATE TRIGGER IF NOT EXISTS `" + journalSearchTable + "_insert` AFTER INSERT ON `" + journalTable + "` BEGIN " +
+			"INSERT INTO `" + journalSearchTable + "` (`rowid`, `title`, `content`) VALUES (new.`id`, new.`title`, new.`content`); END",
+		"CREATE TRIGGER IF NOT EXISTS `" + journalSearchTable + "_delete` AFTER DELETE ON `" + journalTable + "` BEGIN " +
+			"INSERT INTO `" + journalSearchTable + "` (`" + journalSearchTable + "`, `rowid`, `title`, `content`) VALUES ('delete', old.`id`, old.`title`, old.`content`); END",
+		"CREATE TRIGGER IF NOT EXISTS `" + journalSearchTable + "_update` AFTER UPDATE ON `" + journalTable + "` BEGIN " +
+			"INSERT INTO `" + journalSearchTable + "` (`" + journalSearchTable + "`, `rowid`, `title`, `content`) VALUES ('delete', old.`id`, old.`title`, old.`content`); " +
+			"INSERT INTO `" + journalSearchTable + "` (`rowid`, `title`, `content`) VALUES (new.`id`, new.`title`, new.`content`); END",
+	}
+	for _, trigger := range triggers {
+		if _, err := s.Container.Db.Exec(trigger); err != nil {
+			return err
+		}
+	}
+
+	// Rebuilding picks up entries written before the index existed
+	_, err = s.Container.Db.Exec("INSERT INTO `" + journalSearchTable + "` (`" + journalSearchTable + "`) VALUES ('rebuild')")
+
+	return err
+}
+
+// Search returns a page of entries matching every word in the text, best matches first
+func (s *JournalSearch) Search(text string, query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+	terms := strings.Fields(text)
+	if len(terms) == 0 {
+		return []Journal{}, pagination
+	}
+
+	from := "`" + journalSearchTable + "` JOIN `" + journalTable + "` ON `" + journalTable + "`.`id` = `" + journalSearchTable + "`.`rowid`"
+	where := "`" + journalSearchTable + "` MATCH ? AND `" + journalTable + "`.`status` = ? AND `" + journalTable + "`." + journalNotDeleted + " AND `" + journalTable + "`." + journalListed
+	order := "`rank`"
+	args := []interface{}{matchExpression(terms), JournalStatusPublished}
+
+	if !s.indexed() || encrypted(s.Container) {
+		from, where, order, args = s.likeClause(terms)
+	}
+	countResult, err := s.Container.Db.Query("SELECT COUNT(*) AS `total` FROM "+from+" WHERE "+where, args...)
+	if err != nil {
+		from, where, order, args = s.likeClause(terms)
+		countResult, err = s.Container.Db.Query("SELECT COUNT(*) AS `total` FROM "+from+" WHERE "+where, args...)
+		if err != nil {
+			return []Journal{}, pagination
+		}
+	}
+	countResult.Next()
+	countResult.Scan(&pagination.TotalResults)
+	countResult.Close()
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+
+	if query.Page > pagination.TotalPages {
+		return []Journal{}, pagination
+	}
+
+	rows, err := s.Container.Db.Query("SELECT "+qualifiedJournalColumns+" FROM "+from+" WHERE "+where+" ORDER BY "+order+" LIMIT ? OFFSET ?", append(args, query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)...)
+	if err != nil {
+		return []Journal{}, pagination
+	}
+	js := Journals{Container: s.Container}
+
+	return js.loadFromRows(rows), pagination
+}
+
+// indexed Whether the database can hold an FTS5 index, which only SQLite can. The index holds content as it is stored,
+// so it is left unused while the journal is encrypted.
+func (s *JournalSearch) indexed() bool {
+	return database.DialectOf(s.Container.Db) == database.DialectSqlite
+}
+
+// likeClause Match each term anywhere in the title or content when there is no FTS5 index, or only in the title when
+// the content is encrypted
+func (s *JournalSearch) likeClause(terms []string) (string, string, string, []interface{}) {
+	escape := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	conditions := []string{"`status` = ?", journalNotDeleted, journalListed}
+	args := []interface{}{JournalStatusPublished}
+	for _, term := range terms {
+		pattern := "%" + escape.Replace(term) + "%"
+		if encrypted(s.Container) {
+			conditions = append(conditions, "`title` LIKE ? ESCAPE '\\'")
+			args = append(args, pattern)
+			continue
+		}
+		conditions = append(conditions, "(`title` LIKE ? ESCAPE '\\' OR `content` LIKE ? ESCAPE '\\')")
+		args = append(args, pattern, pattern)
+	}
+
+	return "`" + journalTable + "`", strings.Join(conditions, " AND "), "`date` DESC", args
+}
+
+// matchExpression Quote each term as an FTS5 prefix query so that user input is never parsed as query syntax
+func matchExpression(terms []string) string {
+	quoted := []string{}
+	for _, term := range terms {
+		quoted = append(quoted, "\""+strings.ReplaceAll(term, "\"", "\"\"")+"\"*")
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgDb "github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/seal"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalSearch_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	s := JournalSearch{Container: container}
+	if err := s.CreateTable(); err != nil || db.Queries != 5 {
+		t.Errorf("Expected index, triggers and rebuild to have been run, got %d queries", db.Queries)
+	}
+
+	db.Queries = 0
+	db.ErrorMode = true
+	if err := s.CreateTable(); err == nil || db.Queries != 1 {
+		t.Error("Expected error to have been returned on failure")
+	}
+}
+
+func TestJournalSearch_Search(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	s := JournalSearch{Container: container}
+	query := pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2}
+
+	// Test nothing to search for
+	journals, pagination := s.Search("  ", query)
+	if len(journals) != 0 || pagination.TotalResults != 0 || db.Queries != 0 {
+		t.Error("Expected no search to have been run without any terms")
+	}
+
+	// Test full-text results
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.ExpectedArgument = `"title"* "conte"*`
+	journals, pagination = s.Search("title conte", query)
+	if len(journals) != 2 || pagination.TotalResults != 2 || pagination.TotalPages != 1 || db.Queries != 2 {
+		t.Error("Expected 2 results to have been returned")
+	}
+
+	// Test fallback when there is no full-text index
+	db.Queries = 0
+	db.ErrorAtQuery = 1
+	db.ExpectedArgument = `%100\%%`
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, _ = s.Search("100%", query)
+	if len(journals) != 2 || db.Queries != 3 {
+		t.Error("Expected LIKE search to have been used when full-text search failed")
+	}
+
+	// Test only titles are matched when the content is encrypted, as the index only holds it sealed
+	db.Queries = 0
+	db.ErrorAtQuery = 0
+	db.ExpectedArgument = "%title%"
+	salt, _ := seal.NewSalt()
+	container.Sealer, _ = seal.New("correct horse", salt)
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, _ = s.Search("title", query)
+	if len(journals) != 2 || db.Queries != 2 {
+		t.Error("Expected LIKE search of titles to have been used while encrypted")
+	}
+	container.Sealer = nil
+
+	// Test page out of range
+	db.Queries = 0
+	db.ErrorAtQuery = 0
+	db.ExpectedArgument = ""
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	journals, _ = s.Search("title", pkgDb.PaginationQuery{Page: 3, ResultsPerPage: 2})
+	if len(journals) != 0 || db.Queries != 1 {
+		t.Error("Expected no results beyond the last page")
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	if expr := matchExpression([]string{"one", `"two"`, "NOT"}); expr != `"one"* """two"""* "NOT"*` {
+		t.Errorf("Expected terms to have been quoted, got %s", expr)
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// SitemapEntry A published entry listed in the sitemap, with when it was last changed
+type SitemapEntry struct {
+	Slug      string
+	Date      string
+	UpdatedAt string
+}
+
+// LastModified Get when the entry was last changed, being the latest revision or otherwise its date
+func (e SitemapEntry) LastModified() time.Time {
+	if updated, ok := revisedAt(e.UpdatedAt); ok {
+		return updated
+	}
+
+	return Journal{Date: e.Date}.GetTime()
+}
+
+// FetchSitemap Get every published, listed entry along with the time its latest revision was kept, newest first
+func (js *Journals) FetchSitemap() []SitemapEntry {
+	rows, err := js.Container.Db.Query("SELECT j.`slug`, j.`date`, COALESCE(MAX(r.`created_at`), '') "+
+		"FROM `"+journalTable+"` AS j LEFT JOIN `"+journalRevisionTable+"` AS r ON r.`journal_id` = j.`id` "+
+		"WHERE j.`status` = ? AND j."+journalNotDeleted+" AND j.`visibility` = ? AND j.`password_hash` = '' "+
+		"GROUP BY j.`id` ORDER BY j.`date` DESC, j.`id` DESC", JournalStatusPublished, JournalVisibilityPublic)
+	if err != nil {
+		return []SitemapEntry{}
+	}
+	defer rows.Close()
+
+	entries := []SitemapEntry{}
+	for rows.Next() {
+		e := SitemapEntry{}
+		rows.Scan(&e.Slug, &e.Date, &e.UpdatedAt)
+		entries = append(entries, e)
+	}
+
+	return entries
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSitemapEntry_LastModified(t *testing.T) {
+	e := SitemapEntry{Slug: "slug", Date: "2018-02-01T00:00:00Z"}
+	if !e.LastModified().Equal(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected date of an unchanged entry, got %s", e.LastModified())
+	}
+	e.UpdatedAt = "2018-02-04 10:30:00"
+	if !e.LastModified().Equal(time.Date(2018, 2, 4, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected time of the latest revision, got %s", e.LastModified())
+	}
+}
+
+func TestJournals_FetchSitemap(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test error
+	db.ErrorMode = true
+	if len(js.FetchSitemap()) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test entries returned
+	db.ErrorMode = false
+	db.Rows = &database.MockSitemap_MultipleRows{}
+	entries := js.FetchSitemap()
+	if len(entries) != 2 || entries[0].Slug != "slug-2" || entries[0].UpdatedAt != "2018-03-04 10:00:00" || entries[1].UpdatedAt != "" {
+		t.Errorf("Expected entries to be returned with their latest revisions, got %v", entries)
+	}
+}
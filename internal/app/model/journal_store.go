@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+// JournalStore Storage of entries, which the database provides unless another backend has been given to the
+// container, so that backends can be added without changing the controllers
+type JournalStore interface {
+	app.Store
+	CreateJournal(j Journal) (Journal, error)
+	Delete(j Journal) error
+	GetBySlug(slug string) Journal
+	List(query database.PaginationQuery) ([]Journal, database.PaginationInformation)
+	Update(j Journal) (Journal, error)
+}
+
+// BoundStore A store that indexes its entries in the database, which can be seen through the database of another
+// container so that it takes part in the transactions made there
+type BoundStore interface {
+	JournalStore
+	Bind(c *app.Container) JournalStore
+}
+
+// ErrNotSaved An entry could not be saved, so nothing saved along with it has been kept
+var ErrNotSaved = errors.New("The entry could not be saved")
+
+// Store Get the store holding the entries of the journal being served
+func Store(c *app.Container) JournalStore {
+	if s, ok := c.Store.(BoundStore); ok {
+		return s.Bind(c)
+	}
+	if s, ok := c.Store.(JournalStore); ok {
+		return s
+	}
+
+	return &Journals{Container: c, Gs: GiphyAdapter(c)}
+}
+
+// Name Name of the backend, the database entries are kept in by default
+func (js *Journals) Name() string {
+	return database.DialectOf(js.Container.Db)
+}
+
+// CreateJournal Save a new entry, giving it a slug of its own
+func (js *Journals) CreateJournal(j Journal) (Journal, error) {
+	j.ID = 0
+
+	return js.Save(j)
+}
+
+// GetBySlug Find an entry by its slug, leaving out any in the trash
+func (js *Journals) GetBySlug(slug string) Journal {
+	return js.FindBySlug(slug)
+}
+
+// List Get a page of the published, listed entries, newest first
+func (js *Journals) List(query database.PaginationQuery) ([]Journal, database.PaginationInformation) {
+	return js.FetchPaginated(query)
+}
+
+// Update Save the changes to an existing entry
+func (js *Journals) Update(j Journal) (Journal, error) {
+	if j.ID == 0 {
+		return j, ErrNotSaved
+	}
+
+	return js.Save(j)
+}
+
+// SaveJournal Create an entry, or update it when given the entry as it was before, along with its links, its custom
+// fields and tags when it carries them and a revision of what it replaces, all in one transaction so that a failure
+// part way leaves nothing half written, including an entry file that could not be written.
+func SaveJournal(c *app.Container, previous Journal, j Journal) (Journal, error) {
+	err := c.Transaction(func(tx *app.Container) error {
+		store := Store(tx)
+		var err error
+		if previous.ID == 0 {
+			j, err = store.CreateJournal(j)
+		} else {
+			j, err = store.Update(j)
+		}
+		if err != nil {
+			return err
+		}
+		if j.ID == 0 {
+			return ErrNotSaved
+		}
+		ls := JournalLinks{Container: tx}
+		if err := ls.Save(j); err != nil {
+			return err
+		}
+		if j.Meta != nil {
+			ms := JournalMetas{Container: tx}
+			if err := ms.Save(j); err != nil {
+				return err
+			}
+		}
+		if j.Tags != nil {
+			ts := JournalTags{Container: tx}
+			if err := ts.Save(j); err != nil {
+				return err
+			}
+		}
+		rs := JournalRevisions{Container: tx}
+
+		return rs.Record(previous, j)
+	})
+
+	return j, err
+}
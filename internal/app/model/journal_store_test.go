@@ -0,0 +1,118 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+type fakeStore struct {
+	Journals
+}
+
+func (s *fakeStore) Name() string {
+	return "fake"
+}
+
+func TestStore(t *testing.T) {
+	container := &app.Container{Db: &database.MockSqlite{}}
+	if store := Store(container); store.Name() != "sqlite" {
+		t.Errorf("Expected the database to be the default store, got %s", store.Name())
+	}
+
+	container.Store = &fakeStore{}
+	if store := Store(container); store.Name() != "fake" {
+		t.Errorf("Expected the store given to the container to be used, got %s", store.Name())
+	}
+}
+
+func TestJournals_CreateJournal(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container, Gs: &database.MockGiphyExtractor{}}
+
+	journal, err := js.CreateJournal(Journal{ID: 5, Title: "Testing"})
+	if err != nil || journal.ID != 1 || journal.Slug != "testing" {
+		t.Errorf("Expected a new entry to have been created, got %v", journal)
+	}
+}
+
+func TestJournals_Update(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container, Gs: &database.MockGiphyExtractor{}}
+
+	journal, err := js.Update(Journal{Title: "Testing"})
+	if err != ErrNotSaved || journal.ID != 0 || db.Queries != 0 {
+		t.Error("Expected an entry that was never created to be left alone")
+	}
+
+	journal, err = js.Update(Journal{ID: 2, Title: "Testing"})
+	if err != nil || journal.ID != 2 || db.Queries == 0 {
+		t.Error("Expected the existing entry to have been saved")
+	}
+}
+
+func TestJournals_GetBySlug(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockJournal_SingleRow{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	if journal := js.GetBySlug("slug"); journal.ID != 1 {
+		t.Errorf("Expected the entry to be found, got %v", journal)
+	}
+}
+
+func TestSaveJournal(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}, Rows: &database.MockRowsEmpty{}}
+	container := &app.Container{Db: db}
+
+	// A new entry is saved with its links, and its custom fields when it carries them
+	journal, err := SaveJournal(container, Journal{}, Journal{Title: "Testing", Content: "Content", CanonicalURL: "https://example.com/original"})
+	if err != nil || journal.ID != 1 || db.Queries != 4 {
+		t.Errorf("Expected entry and links to be saved, got %v after %d queries", err, db.Queries)
+	}
+	db.Queries = 0
+	journal.Meta = map[string]string{"mood": "Happy"}
+	journal, err = SaveJournal(container, journal, journal)
+	if err != nil || db.Queries != 5 {
+		t.Errorf("Expected entry, links and custom fields to be saved without a revision, got %v after %d queries", err, db.Queries)
+	}
+
+	db.Queries = 0
+	journal.Tags = []string{"Travel", "Road Trip"}
+	journal, err = SaveJournal(container, journal, journal)
+	if err != nil || db.Queries != 8 {
+		t.Errorf("Expected entry, links, custom fields and tags to be saved, got %v after %d queries", err, db.Queries)
+	}
+	journal.Tags = nil
+
+	// Changes to the content record a revision
+	db.Queries = 0
+	changed := journal
+	changed.Content = "Changed"
+	if _, err := SaveJournal(container, journal, changed); err != nil || db.Queries != 6 {
+		t.Errorf("Expected a revision to be recorded, got %v after %d queries", err, db.Queries)
+	}
+
+	// Failures are returned for the transaction to be rolled back
+	db.Queries = 0
+	db.ErrorAtQuery = 2
+	if _, err := SaveJournal(container, Journal{}, Journal{Title: "Testing"}); err == nil {
+		t.Error("Expected entry that could not be inserted not to be saved")
+	}
+	db.Queries = 0
+	db.ErrorAtQuery = 1
+	if _, err := SaveJournal(container, journal, changed); err == nil || db.Queries != 1 {
+		t.Errorf("Expected entry that could not be updated not to be saved, got %v after %d queries", err, db.Queries)
+	}
+	db.Queries = 0
+	db.ErrorAtQuery = 4
+	if _, err := SaveJournal(container, journal, changed); err == nil {
+		t.Error("Expected error saving the custom fields to be returned")
+	}
+}
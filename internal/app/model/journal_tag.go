@@ -0,0 +1,208 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+const journalTagTable = "journal_tag"
+
+// TagLength Longest name a tag may have
+const TagLength = 64
+
+// Tag A label given to entries, of which an entry may carry any number, unlike the single category it is filed in
+type Tag struct {
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Count int    `json:"count,omitempty"`
+}
+
+// JournalTags Common database resource link for the tags given to entries
+type JournalTags struct {
+	Container *app.Container
+}
+
+// journalTagged Condition keeping the entries given a tag, by its slug
+const journalTagged = "`id` IN (SELECT `journal_id` FROM `" + journalTagTable + "` WHERE `slug` = ?)"
+
+// tagListed Condition keeping the tags of published, listed entries outside the trash
+const tagListed = "`journal_id` IN (SELECT `id` FROM `" + journalTable + "` WHERE `status` = ? AND " + journalNotDeleted + " AND " + journalListed + ")"
+
+// CreateTable Create the actual table, keeping one row for each tag of an entry
+func (ts *JournalTags) CreateTable() error {
+	_, err := ts.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + journalTagTable + "` (" +
+		"`journal_id` INTEGER NOT NULL, " +
+		"`name` VARCHAR(64) NOT NULL, " +
+		"`slug` VARCHAR(64) NOT NULL, " +
+		"PRIMARY KEY (`journal_id`, `slug`)" +
+		")")
+
+	return err
+}
+
+// Add Give an entry more tags, keeping those it already has
+func (ts *JournalTags) Add(journalID int, names []string) error {
+	for _, name := range ParseTags(strings.Join(names, ",")) {
+		_, err := ts.Container.Db.Exec("INSERT INTO `"+journalTagTable+"` (`journal_id`, `name`, `slug`) VALUES(?,?,?) ON CONFLICT DO NOTHING", strconv.Itoa(journalID), name, TagSlug(name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteByJournal Remove every tag of an entry
+func (ts *JournalTags) DeleteByJournal(journalID int) error {
+	_, err := ts.Container.Db.Exec("DELETE FROM `"+journalTagTable+"` WHERE `journal_id` = ?", strconv.Itoa(journalID))
+
+	return err
+}
+
+// FetchAll Get every tag given to published, listed entries, along with how many of them carry it, by name
+func (ts *JournalTags) FetchAll() []Tag {
+	rows, err := ts.Container.Db.Query("SELECT MIN(`name`), `slug`, COUNT(*) FROM `"+journalTagTable+"` WHERE "+tagListed+" GROUP BY `slug` ORDER BY `slug`", JournalStatusPublished)
+	if err != nil {
+		return []Tag{}
+	}
+	defer rows.Close()
+	tags := []Tag{}
+	for rows.Next() {
+		t := Tag{}
+		rows.Scan(&t.Name, &t.Slug, &t.Count)
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
+// FetchByJournal Get the tags of an entry, by name
+func (ts *JournalTags) FetchByJournal(journalID int) []Tag {
+	rows, err := ts.Container.Db.Query("SELECT `name`, `slug` FROM `"+journalTagTable+"` WHERE `journal_id` = ? ORDER BY `slug`", strconv.Itoa(journalID))
+	if err != nil {
+		return []Tag{}
+	}
+	defer rows.Close()
+	tags := []Tag{}
+	for rows.Next() {
+		t := Tag{}
+		rows.Scan(&t.Name, &t.Slug)
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
+// FindBySlug Find a tag given to published, listed entries by its slug, along with how many of them carry it
+func (ts *JournalTags) FindBySlug(slug string) Tag {
+	rows, err := ts.Container.Db.Query("SELECT MIN(`name`), `slug`, COUNT(*) FROM `"+journalTagTable+"` WHERE `slug` = ? AND "+tagListed+" GROUP BY `slug`", slug, JournalStatusPublished)
+	if err != nil {
+		return Tag{}
+	}
+	defer rows.Close()
+	t := Tag{}
+	if rows.Next() {
+		rows.Scan(&t.Name, &t.Slug, &t.Count)
+	}
+
+	return t
+}
+
+// Load Attach the names of the tags given to an entry
+func (ts *JournalTags) Load(j Journal) Journal {
+	j.Tags = []string{}
+	for _, t := range ts.FetchByJournal(j.ID) {
+		j.Tags = append(j.Tags, t.Name)
+	}
+
+	return j
+}
+
+// LoadAll Attach the names of their tags to many entries at once
+func (ts *JournalTags) LoadAll(journals []Journal) []Journal {
+	if len(journals) == 0 {
+		return journals
+	}
+	args := []interface{}{}
+	for _, j := range journals {
+		args = append(args, strconv.Itoa(j.ID))
+	}
+	rows, err := ts.Container.Db.Query("SELECT `journal_id`, `name` FROM `"+journalTagTable+"` WHERE `journal_id` IN (?"+strings.Repeat(", ?", len(journals)-1)+") ORDER BY `slug`", args...)
+	if err != nil {
+		return journals
+	}
+	defer rows.Close()
+	tags := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var name string
+		rows.Scan(&id, &name)
+		tags[id] = append(tags[id], name)
+	}
+	for i := range journals {
+		journals[i].Tags = tags[journals[i].ID]
+	}
+
+	return journals
+}
+
+// Save Replace the tags stored for an entry with those it carries
+func (ts *JournalTags) Save(j Journal) error {
+	if j.ID == 0 {
+		return errors.New("Entry must be saved before its tags")
+	}
+	if err := ts.DeleteByJournal(j.ID); err != nil {
+		return err
+	}
+
+	return ts.Add(j.ID, j.Tags)
+}
+
+// GetTags Get the tags given to the entry as loaded, with their slugs, for use in templates
+func (j Journal) GetTags() []Tag {
+	tags := []Tag{}
+	for _, name := range j.Tags {
+		tags = append(tags, Tag{Name: name, Slug: TagSlug(name)})
+	}
+
+	return tags
+}
+
+// TagSlug Turn the name of a tag into the slug it is found by, so that tags differing only in case or punctuation are
+// the same tag
+func TagSlug(name string) string {
+	return strings.Trim(Slugify(strings.TrimSpace(name)), "-")
+}
+
+// ParseTags Read tags written separated by commas, ignoring blank ones, those too long and repeats
+func ParseTags(text string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(text, ",") {
+		name = strings.Join(strings.Fields(name), " ")
+		slug := TagSlug(name)
+		if slug == "" || seen[slug] || len([]rune(name)) > TagLength {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, name)
+	}
+
+	return tags
+}
+
+// createJournalTagTable Create the table for databases created before entries were tagged
+func createJournalTagTable(c *app.Container) error {
+	ts := JournalTags{Container: c}
+
+	return ts.CreateTable()
+}
+
+func dropJournalTagTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + journalTagTable + "`")
+
+	return err
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestJournalTags_Add(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	db.ExpectedArgument = "road-trip"
+	if err := ts.Add(1, []string{"Road Trip", "road trip", " "}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected one tag to be added, got %v after %d queries", err, db.Queries)
+	}
+
+	db.ErrorMode = true
+	if err := ts.Add(1, []string{"Travel"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestJournalTags_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	db.ErrorMode = true
+	if len(ts.FetchAll()) > 0 {
+		t.Error("Expected no tags returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockJournalTag_MultipleRows{}
+	tags := ts.FetchAll()
+	if len(tags) != 2 || tags[0].Slug != "road-trip" || tags[0].Count != 3 {
+		t.Errorf("Expected tags and their counts, got %v", tags)
+	}
+}
+
+func TestJournalTags_FindBySlug(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if tag := ts.FindBySlug("missing"); tag.Slug != "" {
+		t.Errorf("Expected no tag found, got %v", tag)
+	}
+
+	db.ExpectedArgument = "road-trip"
+	db.Rows = &database.MockJournalTag_MultipleRows{}
+	if tag := ts.FindBySlug("road-trip"); tag.Name != "Road Trip" || tag.Count != 3 {
+		t.Errorf("Expected tag to be found, got %v", tag)
+	}
+}
+
+func TestJournalTags_Load(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	db.Rows = &database.MockJournalTag_MultipleRows{}
+	j := ts.Load(Journal{ID: 1})
+	if !reflect.DeepEqual(j.Tags, []string{"Road Trip", "Travel"}) {
+		t.Errorf("Expected tags to have been loaded, got %v", j.Tags)
+	}
+	if tags := j.GetTags(); tags[0].Slug != "road-trip" {
+		t.Errorf("Expected tags to carry their slugs, got %v", tags)
+	}
+
+	db.Rows = &database.MockRowsEmpty{}
+	if j := ts.Load(Journal{ID: 2}); j.Tags == nil || len(j.Tags) > 0 {
+		t.Error("Expected an entry without tags to carry none")
+	}
+}
+
+func TestJournalTags_LoadAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	if journals := ts.LoadAll([]Journal{}); len(journals) > 0 || db.Queries != 0 {
+		t.Error("Expected nothing to be loaded without entries")
+	}
+
+	db.Rows = &database.MockJournalTagged_MultipleRows{}
+	journals := ts.LoadAll([]Journal{{ID: 1}, {ID: 2}, {ID: 3}})
+	if !reflect.DeepEqual(journals[0].Tags, []string{"Road Trip", "Travel"}) || !reflect.DeepEqual(journals[1].Tags, []string{"Travel"}) || len(journals[2].Tags) > 0 || db.Queries != 1 {
+		t.Errorf("Expected tags of every entry to be loaded in one query, got %v", journals)
+	}
+}
+
+func TestJournalTags_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := JournalTags{Container: container}
+	if err := ts.Save(Journal{Tags: []string{"Travel"}}); err == nil || db.Queries != 0 {
+		t.Error("Expected unsaved entry to be refused")
+	}
+
+	if err := ts.Save(Journal{ID: 1, Tags: []string{"Travel", "Road Trip"}}); err != nil || db.Queries != 3 {
+		t.Errorf("Expected tags to be replaced, got %v after %d queries", err, db.Queries)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := map[string][]string{
+		"":                                {},
+		"Travel":                          {"Travel"},
+		" Road   Trip , travel, ,Travel,": {"Road Trip", "travel"},
+		"!!!, C++":                        {"C++"},
+	}
+	for input, expected := range tests {
+		if tags := ParseTags(input); !reflect.DeepEqual(tags, expected) {
+			t.Errorf("Expected %v from %q, got %v", expected, input, tags)
+		}
+	}
+}
+
+func TestTagSlug(t *testing.T) {
+	if slug := TagSlug(" Road Trip! "); slug != "road-trip" {
+		t.Errorf("Expected road-trip, got %s", slug)
+	}
+}
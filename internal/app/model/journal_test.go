@@ -1,7 +1,9 @@
 package model
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamiefdhurst/journal/internal/app"
 	pkgDb "github.com/jamiefdhurst/journal/pkg/database"
@@ -49,6 +51,81 @@ func TestJournal_GetEditableDate(t *testing.T) {
 	}
 }
 
+func TestJournal_GetTime(t *testing.T) {
+	j := Journal{Date: "2018-05-10T00:00:00Z"}
+	if !j.GetTime().Equal(time.Date(2018, 5, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected GetTime() to parse the date, got %s", j.GetTime())
+	}
+	j.Date = "not a date"
+	if !j.GetTime().IsZero() {
+		t.Error("Expected GetTime() to return the zero time for an invalid date")
+	}
+}
+
+func TestJournal_CommentsOpen(t *testing.T) {
+	if !(Journal{}).CommentsOpen() || !(Journal{Comments: JournalCommentsOpen}).CommentsOpen() {
+		t.Error("Expected comments to be open unless closed")
+	}
+	if (Journal{Comments: JournalCommentsClosed}).CommentsOpen() {
+		t.Error("Expected comments to be closed")
+	}
+}
+
+func TestJournal_Visibility(t *testing.T) {
+	if !(Journal{}).IsListed() || (Journal{}).IsPrivate() || !(Journal{Visibility: JournalVisibilityPublic}).IsListed() {
+		t.Error("Expected Journal to be listed and not private by default")
+	}
+	unlisted := Journal{Visibility: JournalVisibilityUnlisted}
+	if unlisted.IsListed() || unlisted.IsPrivate() {
+		t.Error("Expected unlisted Journal to be left off listings but not private")
+	}
+	private := Journal{Visibility: JournalVisibilityPrivate}
+	if private.IsListed() || !private.IsPrivate() {
+		t.Error("Expected private Journal to be left off listings and private")
+	}
+}
+
+func TestJournal_Schedule(t *testing.T) {
+	j := Journal{}
+	if !j.IsPublished() || j.IsScheduled() || j.GetPublishAt() != "" || j.GetEditablePublishAt() != "" || !j.GetPublishTime().IsZero() {
+		t.Error("Expected Journal to be published without a publish time")
+	}
+
+	at := time.Date(2030, 1, 2, 9, 30, 0, 0, time.UTC)
+	j.Schedule(at)
+	if !j.IsScheduled() || j.IsPublished() || j.PublishAt != "2030-01-02 09:30:00" {
+		t.Errorf("Expected Journal to be scheduled, got %s", j.PublishAt)
+	}
+	if j.GetEditablePublishAt() != at.Local().Format("2006-01-02T15:04") || j.GetPublishAt() != at.Local().Format("Monday January 2, 2006 at 15:04") ||
+		!j.GetPublishTime().Equal(at) || j.GetPublishTime().Location() != time.Local {
+		t.Error("Expected publish time to be shown in the server's time zone")
+	}
+	if (Journal{Status: JournalStatusDraft}).IsPublished() {
+		t.Error("Expected draft not to be published")
+	}
+}
+
+func TestJournal_GetHTML(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"<p>Existing <b>HTML</b> content</p>", "<p>Existing <b>HTML</b> content</p>"},
+		{"Some *Markdown*", "<p>Some <em>Markdown</em></p>"},
+		{"Unsafe <script>", "<p>Unsafe &lt;script&gt;</p>"},
+		{"<p onclick=\"alert(1)\">Old</p><script>alert(1)</script>", "<p>Old</p>"},
+		{"", ""},
+	}
+
+	for _, table := range tables {
+		j := Journal{Content: table.input}
+		actual := j.GetHTML()
+		if actual != table.output {
+			t.Errorf("Expected GetHTML() to produce result of '%s', got '%s'", table.output, actual)
+		}
+	}
+}
+
 func TestJournal_GetExcerpt(t *testing.T) {
 	tables := []struct {
 		input  string
@@ -59,6 +136,7 @@ func TestJournal_GetExcerpt(t *testing.T) {
 		{"", ""},
 		{"<p></p><p></p>", " "},
 		{"<p>a b c d e f g h i j k l m n o p q r s t u v w x y z a b c d e f g h i j k l m n o p q r s t u v w x y z</p>", "a b c d e f g h i j k l m n o p q r s t u v w x y z a b c d e f g h i j k l m n o p q r s t u v w x..."},
+		{"Some **Markdown** with a [link](/test)\n\nand more", "Some Markdown with a link and more"},
 	}
 
 	for _, table := range tables {
@@ -68,6 +146,43 @@ func TestJournal_GetExcerpt(t *testing.T) {
 			t.Errorf("Expected GetExcerpt() to produce result of '%s', got '%s'", table.output, actual)
 		}
 	}
+
+	// A written excerpt is used in place of the content, with any HTML removed
+	j := Journal{Content: "<p>The full entry</p>", Excerpt: "A **short** summary <script>alert(1)</script>"}
+	if actual := j.GetExcerpt(); actual != "A short summary &lt;script&gt;alert(1)&lt;/script&gt;" {
+		t.Errorf("Expected written excerpt to be used, got '%s'", actual)
+	}
+	j.Excerpt = "   "
+	if actual := j.GetExcerpt(); actual != "The full entry" {
+		t.Errorf("Expected content to be used for a blank excerpt, got '%s'", actual)
+	}
+}
+
+func TestJournal_GetDescription(t *testing.T) {
+	j := Journal{Content: "<p>Fish &amp; chips</p>\n<p>by the \"sea\"</p>"}
+	if actual := j.GetDescription(); actual != `Fish & chips by the "sea"` {
+		t.Errorf("Expected plain text description, got '%s'", actual)
+	}
+}
+
+func TestJournal_GetImage(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"<p>No images here</p>", ""},
+		{"Some text\n\n![Beach](/media/beach.jpg)\n\n![Sea](/media/sea.jpg)", "/media/beach.jpg"},
+		{`<p><IMG alt="x" SRC='https://example.com/a.png?w=1&amp;h=2'></p>`, "https://example.com/a.png?w=1&h=2"},
+		{`<img src="data:image/png;base64,AAAA"><img src="/media/second.png">`, "/media/second.png"},
+		{`<img data-src="/lazy.png" src="/media/real.png">`, "/media/real.png"},
+	}
+
+	for _, table := range tables {
+		j := Journal{Content: table.input}
+		if actual := j.GetImage(); actual != table.output {
+			t.Errorf("Expected GetImage() to produce result of '%s', got '%s'", table.output, actual)
+		}
+	}
 }
 
 func TestJournals_CreateTable(t *testing.T) {
@@ -75,8 +190,168 @@ func TestJournals_CreateTable(t *testing.T) {
 	container := &app.Container{Db: db}
 	js := Journals{Container: container}
 	js.CreateTable()
-	if db.Queries != 1 {
-		t.Errorf("Expected 1 query to have been run")
+	if db.Queries != 13 {
+		t.Errorf("Expected table creation and added column queries to have been run")
+	}
+
+	// Test error stops the columns being added
+	db.Queries = 0
+	db.ErrorAtQuery = 1
+	if err := js.CreateTable(); err == nil || db.Queries != 1 {
+		t.Error("Expected error to have been returned on failure")
+	}
+}
+
+func TestJournals_Delete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}, Rows: &database.MockRowsEmpty{}}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.ExpectedArgument = "3"
+	if err := js.Delete(Journal{ID: 3}); err != nil || db.Queries != 8 {
+		t.Error("Expected entry, its links, revisions, comments, attachments, custom fields and tags to be deleted")
+	}
+
+	db.ErrorAtQuery = db.Queries + 1
+	if err := js.Delete(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	db.ErrorAtQuery = db.Queries + 2
+	if err := js.Delete(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	db.ErrorAtQuery = db.Queries + 3
+	if err := js.Delete(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	db.ErrorAtQuery = db.Queries + 5
+	if err := js.Delete(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	db.ErrorAtQuery = db.Queries + 6
+	if err := js.Delete(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestJournals_Trash(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.ExpectedArgument = "3"
+	if err := js.Trash(Journal{ID: 3}); err != nil || db.Queries != 1 {
+		t.Error("Expected entry to be moved to the trash")
+	}
+
+	db.ErrorMode = true
+	if err := js.Trash(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestJournals_Restore(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.ExpectedArgument = "3"
+	if err := js.Restore(Journal{ID: 3}); err != nil || db.Queries != 1 {
+		t.Error("Expected entry to be restored")
+	}
+
+	db.ErrorMode = true
+	if err := js.Restore(Journal{ID: 3}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestJournals_FetchDeleted(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.FetchDeleted()) > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FetchDeleted()
+	if len(journals) != 2 || journals[1].Slug != "slug-2" {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FetchExpired(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.FetchExpired(30)) > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FetchExpired(30)
+	if len(journals) != 2 || journals[0].Slug != "slug" {
+		t.Errorf("Expected entries deleted before the retention period to be returned")
+	}
+}
+
+func TestJournals_FindDeletedBySlug(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.ExpectedArgument = "slug"
+	db.Rows = &database.MockJournal_SingleRow{DeletedAt: "2018-02-02 10:00:00"}
+	journal := js.FindDeletedBySlug("slug")
+	if journal.ID != 1 || !journal.IsDeleted() {
+		t.Error("Expected deleted journal to be returned")
+	}
+}
+
+func TestJournals_Count(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if js.Count() != 0 {
+		t.Error("Expected zero count when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockPagination_Result{TotalResults: 12}
+	if js.Count() != 12 {
+		t.Error("Expected count to have been returned")
+	}
+}
+
+func TestJournals_QuotaReached(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test not a tenant
+	container.Configuration.TenantMaxEntries = 2
+	if js.QuotaReached() || db.Queries > 0 {
+		t.Error("Expected no quota outside of a tenant")
+	}
+
+	// Test no quota configured
+	container.Tenant = "alice"
+	container.Configuration.TenantMaxEntries = 0
+	if js.QuotaReached() || db.Queries > 0 {
+		t.Error("Expected no quota when no maximum is configured")
+	}
+
+	// Test under and over quota
+	container.Configuration.TenantMaxEntries = 2
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	if js.QuotaReached() {
+		t.Error("Expected quota not to have been reached")
+	}
+	db.Rows = &database.MockPagination_Result{TotalResults: 2}
+	if !js.QuotaReached() {
+		t.Error("Expected quota to have been reached")
 	}
 }
 
@@ -97,8 +372,8 @@ func TestJournals_EnsureUniqueSlug(t *testing.T) {
 	db.Rows = &database.MockJournal_SingleRow{}
 	db.ExpectedArgument = "test"
 	actual = js.EnsureUniqueSlug("test", 0)
-	if actual != "test-1" {
-		t.Errorf("Expected EnsureUniqueSlug() to produce result of '%s', got '%s'", "test-1", actual)
+	if actual != "test-2" {
+		t.Errorf("Expected EnsureUniqueSlug() to produce result of '%s', got '%s'", "test-2", actual)
 	}
 
 	db.Rows = &database.MockJournal_SingleRow{}
@@ -107,6 +382,46 @@ func TestJournals_EnsureUniqueSlug(t *testing.T) {
 	if actual != "test-3" {
 		t.Errorf("Expected EnsureUniqueSlug() to produce result of '%s', got '%s'", "test-3", actual)
 	}
+
+	// Test reserved paths are avoided
+	db.ExpectedArgument = ""
+	db.Rows = &database.MockRowsEmpty{}
+	actual = js.EnsureUniqueSlug("search", 0)
+	if actual != "search-2" {
+		t.Errorf("Expected EnsureUniqueSlug() to produce result of '%s', got '%s'", "search-2", actual)
+	}
+}
+
+func TestJournals_SlugTaken(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if js.SlugTaken("test", 1) {
+		t.Error("Expected slug to be free")
+	}
+
+	db.ExpectedArgument = "3"
+	db.Rows = &database.MockJournal_SingleRow{}
+	if !js.SlugTaken("slug", 3) {
+		t.Error("Expected slug to be taken by another entry")
+	}
+
+	// Test slugs of the journal itself are taken by journals hosted beneath a path prefix
+	db.ExpectedArgument = ""
+	container.Configuration.TenantMode = app.TenantModePath
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	if !js.SlugTaken("alice", 0) {
+		t.Error("Expected slug to be taken by a hosted journal")
+	}
+	container.Tenant = "bob"
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	if js.SlugTaken("alice", 0) {
+		t.Error("Expected hosted journals not to take the slugs of another hosted journal")
+	}
 }
 
 func TestJournals_FetchAll(t *testing.T) {
@@ -137,6 +452,136 @@ func TestJournals_FetchAll(t *testing.T) {
 	}
 }
 
+func TestJournals_FetchDrafts(t *testing.T) {
+
+	// Test error
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.FetchDrafts()) > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	// Test successful result
+	db.ErrorMode = false
+	db.ExpectedArgument = JournalStatusDraft
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FetchDrafts()
+	if len(journals) != 2 || journals[0].ID != 1 {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FetchLatest(t *testing.T) {
+
+	// Test error
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.FetchLatest(10)) > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	// Test successful result
+	db.ErrorMode = false
+	db.ExpectedArgument = JournalStatusPublished
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FetchLatest(10)
+	if len(journals) != 2 || journals[1].Title != "Title 2" {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FindAll(t *testing.T) {
+
+	// Test error
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.FindAll(JournalOptions{})) > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	// Test successful result filtered by category
+	db.ErrorMode = false
+	db.ExpectedArgument = "2"
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FindAll(JournalOptions{CategoryIDs: []int{1, 2}, Limit: 10, Offset: 10})
+	if len(journals) != 2 || journals[1].Title != "Title 2" {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FindByTag(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test a tag with nothing in its name
+	if len(js.FindByTag(" - ")) > 0 || db.Queries != 0 {
+		t.Errorf("Expected nothing to be looked for without a tag")
+	}
+
+	// Test entries given the tag, found by its slug whatever the case of its name
+	db.ExpectedArgument = "road-trip"
+	db.Rows = &database.MockJournal_MultipleRows{}
+	journals := js.FindByTag("Road Trip")
+	if len(journals) != 2 || journals[0].Slug != "slug" || db.Queries != 1 {
+		t.Errorf("Expected entries given the tag to be returned")
+	}
+}
+
+func TestJournals_FetchPaginatedByTag(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	db.ErrorMode = true
+	if journals, _ := js.FetchPaginatedByTag("travel", pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2}); len(journals) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, pagination := js.FetchPaginatedByTag("travel", pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) != 2 || pagination.TotalPages != 2 {
+		t.Errorf("Expected a page of the entries given the tag, got %d and %d pages", len(journals), pagination.TotalPages)
+	}
+}
+
+func TestJournals_PublishDue(t *testing.T) {
+
+	// Test error
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if len(js.PublishDue()) > 0 {
+		t.Errorf("Expected nothing published when error received")
+	}
+
+	// Test due journals are published
+	db.ErrorMode = false
+	db.ExpectedArgument = JournalStatusScheduled
+	db.Rows = &database.MockJournal_MultipleRows{}
+	published := js.PublishDue()
+	if len(published) != 2 || published[1].Status != JournalStatusPublished || db.Queries != 4 {
+		t.Errorf("Expected 2 journals to be published")
+	}
+
+	// Test journals already published elsewhere are skipped
+	db.Result = &database.MockResult{}
+	db.Rows = &database.MockJournal_MultipleRows{}
+	if len(js.PublishDue()) != 0 {
+		t.Errorf("Expected journals published by another request to be skipped")
+	}
+}
+
 func TestJournals_FetchPaginated(t *testing.T) {
 
 	// Test error
@@ -174,6 +619,123 @@ func TestJournals_FetchPaginated(t *testing.T) {
 	}
 }
 
+func TestJournals_FetchPaginatedAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	journals, pagination := js.FetchPaginatedAll(pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) > 0 || pagination.TotalPages > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test pages out of bounds
+	db.ErrorMode = false
+	db.Rows = &database.MockPagination_Result{TotalResults: 2}
+	journals, pagination = js.FetchPaginatedAll(pkgDb.PaginationQuery{Page: 4, ResultsPerPage: 2})
+	if len(journals) > 0 || pagination.TotalPages != 1 {
+		t.Errorf("Expected empty result set with correct pages returned, instead received +%v", pagination)
+	}
+
+	// Test successful result, including drafts
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, pagination = js.FetchPaginatedAll(pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) != 2 || journals[1].Slug != "slug-2" || pagination.TotalPages != 2 || pagination.TotalResults != 3 {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FetchPaginatedByCategory(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test no categories
+	journals, pagination := js.FetchPaginatedByCategory([]int{}, pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) > 0 || db.Queries != 0 {
+		t.Error("Expected no query to be run without categories")
+	}
+
+	// Test error
+	db.ErrorMode = true
+	journals, pagination = js.FetchPaginatedByCategory([]int{1, 2}, pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) > 0 || pagination.TotalPages > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test successful result
+	db.ErrorMode = false
+	db.ExpectedArgument = "2"
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, pagination = js.FetchPaginatedByCategory([]int{1, 2}, pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 2})
+	if len(journals) != 2 || pagination.TotalPages != 2 || pagination.TotalResults != 4 {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestJournals_FetchRange(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+
+	// Test error
+	db.ErrorMode = true
+	journals, total := js.FetchRange([]int{}, "", 0, 2)
+	if len(journals) > 0 || total > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test successful result
+	db.ErrorMode = false
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, total = js.FetchRange([]int{}, "", 2, 2)
+	if len(journals) != 2 || total != 4 {
+		t.Errorf("Expected 2 rows returned and with correct data")
+	}
+
+	// Test filtering by category and reading past the end
+	db.Queries = 0
+	db.ExpectedArgument = "2"
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 4})
+	journals, total = js.FetchRange([]int{1, 2}, "", 4, 2)
+	if len(journals) > 0 || total != 4 || db.Queries != 1 {
+		t.Error("Expected only a count when reading past the end")
+	}
+
+	// Test filtering by tag
+	db.Queries = 0
+	db.ExpectedArgument = "road-trip"
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	journals, total = js.FetchRange([]int{}, "road-trip", 0, 2)
+	if len(journals) != 2 || total != 3 || db.Queries != 2 {
+		t.Error("Expected entries given the tag to be returned")
+	}
+}
+
+func TestJournals_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if js.FindByID(1).ID > 0 {
+		t.Errorf("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockJournal_SingleRow{}
+	if journal := js.FindByID(1); journal.ID != 1 || journal.Slug != "slug" {
+		t.Errorf("Expected journal to be found by ID")
+	}
+}
+
 func TestJournals_FindBySlug(t *testing.T) {
 	// Test error
 	db := &database.MockSqlite{}
@@ -287,20 +849,128 @@ func TestJournals_Save(t *testing.T) {
 	js := Journals{Container: container, Gs: gs}
 
 	// Test with new Journal
-	journal := js.Save(Journal{ID: 0, Title: "Testing"})
-	if journal.ID != 1 || journal.Title != "Testing" {
+	journal, err := js.Save(Journal{ID: 0, Title: "Testing"})
+	if err != nil || journal.ID != 1 || journal.Title != "Testing" {
 		t.Error("Expected same Journal to have been returned with new ID")
 	}
 
 	// Test with same Journal
-	journal = js.Save(Journal{ID: 2, Title: "Testing 2"})
-	if journal.ID != 2 || journal.Title != "Testing 2" {
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2"})
+	if err != nil || journal.ID != 2 || journal.Title != "Testing 2" {
 		t.Error("Expected same Journal to have been returned with new ID")
 	}
 
+	// Test status defaults to published unless saving a draft
+	db.ExpectedArgument = JournalStatusPublished
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Status: "unknown"})
+	if err != nil || journal.Status != JournalStatusPublished || journal.IsDraft() {
+		t.Error("Expected Journal to have been published")
+	}
+	db.ExpectedArgument = JournalStatusDraft
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Status: JournalStatusDraft})
+	if err != nil || journal.Status != JournalStatusDraft || !journal.IsDraft() {
+		t.Error("Expected Journal to have been saved as a draft")
+	}
+	scheduled := Journal{ID: 2, Title: "Testing 2"}
+	scheduled.Schedule(time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC))
+	db.ExpectedArgument = "2030-01-02 09:00:00"
+	journal, err = js.Save(scheduled)
+	if err != nil || !journal.IsScheduled() || journal.PublishAt != "2030-01-02 09:00:00" {
+		t.Error("Expected Journal to have been scheduled")
+	}
+	db.ExpectedArgument = JournalStatusDraft
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Status: JournalStatusScheduled})
+	if err != nil || !journal.IsDraft() {
+		t.Error("Expected Journal without a publish time to be saved as a draft")
+	}
+	db.ExpectedArgument = "Summary"
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Excerpt: "Summary"})
+	if err != nil || journal.Excerpt != "Summary" {
+		t.Error("Expected Journal to have been saved with its excerpt")
+	}
+	db.ExpectedArgument = "1"
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Pinned: true})
+	if err != nil || !journal.Pinned {
+		t.Error("Expected Journal to have been saved pinned")
+	}
+	db.ExpectedArgument = "7"
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", CategoryID: 7})
+	if err != nil || journal.CategoryID != 7 {
+		t.Error("Expected Journal to have been saved in its category")
+	}
+	db.ExpectedArgument = "3"
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Content: "Three *short* words"})
+	if err != nil || journal.WordCount != 3 || journal.ReadingTime != 1 {
+		t.Error("Expected Journal to have been saved with its word count and reading time")
+	}
+	db.ExpectedArgument = JournalVisibilityPrivate
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Visibility: JournalVisibilityPrivate})
+	if err != nil || !journal.IsPrivate() {
+		t.Error("Expected Journal to have been saved as private")
+	}
+	db.ExpectedArgument = JournalVisibilityPublic
+	journal, err = js.Save(Journal{ID: 2, Title: "Testing 2", Visibility: "unknown"})
+	if err != nil || journal.Visibility != JournalVisibilityPublic {
+		t.Error("Expected Journal with an unknown visibility to have been made public")
+	}
+
+	// Test failures are returned, for updates as well as new entries
+	db.ExpectedArgument = ""
+	db.ErrorMode = true
+	if journal, err = js.Save(Journal{ID: 0, Title: "Testing"}); err == nil || journal.ID != 0 {
+		t.Error("Expected failure inserting a Journal to be returned")
+	}
+	if _, err = js.Save(Journal{ID: 2, Title: "Testing 2"}); err == nil {
+		t.Error("Expected failure updating a Journal to be returned")
+	}
+
 	// Check Giphy calls
-	if gs.CalledTimes != 2 {
-		t.Error("Expected Giphy to have been called 2 times within test scope")
+	if gs.CalledTimes != 14 {
+		t.Error("Expected Giphy to have been called 14 times within test scope")
+	}
+}
+
+func TestIsValidSlug(t *testing.T) {
+	tables := []struct {
+		input  string
+		output bool
+	}{
+		{"my-entry", true},
+		{"entry_2", true},
+		{"---", false},
+		{"", false},
+		{"Upper", false},
+		{"with space", false},
+		{"../etc", false},
+		{"drafts", false},
+		{"setup", false},
+		{"graphql", false},
+		{"healthz", false},
+		{strings.Repeat("a", 256), false},
+	}
+
+	for _, table := range tables {
+		if actual := IsValidSlug(table.input); actual != table.output {
+			t.Errorf("Expected IsValidSlug(%q) to be %v", table.input, table.output)
+		}
+	}
+}
+
+func TestTitleFrom(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"Short note", "Short note"},
+		{"<p>Back   from\nthe <em>coast</em></p>", "Back from the coast"},
+		{"Just got back from a long trip around the coast, with plenty of photos to share", "Just got back from a long trip around the coast, with..."},
+		{strings.Repeat("a", 70), strings.Repeat("a", 60) + "..."},
+	}
+
+	for _, table := range tables {
+		if actual := TitleFrom(table.input); actual != table.output {
+			t.Errorf("Expected TitleFrom(%q) to be %q, got %q", table.input, table.output, actual)
+		}
 	}
 }
 
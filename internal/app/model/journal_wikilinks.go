@@ -0,0 +1,101 @@
+package model
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var reWikiLink = regexp.MustCompile(`\[\[([^\[\]<>\n]+?)\]\]`)
+
+// reWikiLinkCode Rendered code, where wiki links are left as they were written
+var reWikiLinkCode = regexp.MustCompile(`(?s)<pre.*?</pre>|<code.*?</code>`)
+
+// WikiLinkTargets Get the slugs or titles linked to with [[slug]] or [[Title]] in an entry's content
+func WikiLinkTargets(content string) []string {
+	targets := []string{}
+	for _, match := range reWikiLink.FindAllStringSubmatch(content, -1) {
+		targets = append(targets, strings.TrimSpace(html.UnescapeString(match[1])))
+	}
+
+	return targets
+}
+
+// StripWikiLinks Replace wiki links in rendered content with the text inside them, for feeds and excerpts
+func StripWikiLinks(rendered string) string {
+	return reWikiLink.ReplaceAllString(rendered, "$1")
+}
+
+// FindByTitle Find a published entry by its title, ignoring case
+func (js *Journals) FindByTitle(title string) Journal {
+	return js.loadSingle(js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE LOWER(`title`) = LOWER(?) AND `status` = ? AND "+journalNotDeleted+" ORDER BY `id` LIMIT 1", title, JournalStatusPublished))
+}
+
+// FindWikiLink Find the published entry a wiki link points to, by its slug or else its title
+func (js *Journals) FindWikiLink(target string) Journal {
+	if IsValidSlug(target) {
+		if j := js.FindBySlug(target); j.ID > 0 && j.IsPublished() {
+			return j
+		}
+	}
+
+	return js.FindByTitle(target)
+}
+
+// LinkWikiLinks Turn wiki links in rendered content into links to the entries they name, marking any left unresolved
+func (js *Journals) LinkWikiLinks(rendered string) string {
+	found := map[string]Journal{}
+	link := func(text string) string {
+		return reWikiLink.ReplaceAllStringFunc(text, func(match string) string {
+			inner := reWikiLink.FindStringSubmatch(match)[1]
+			target := strings.TrimSpace(html.UnescapeString(inner))
+			j, ok := found[strings.ToLower(target)]
+			if !ok {
+				j = js.FindWikiLink(target)
+				found[strings.ToLower(target)] = j
+			}
+			if j.ID == 0 {
+				return `<span class="wikilink-missing">` + inner + `</span>`
+			}
+
+			return `<a class="wikilink" href="` + js.Container.BasePath + "/" + j.Slug + `">` + html.EscapeString(j.Title) + `</a>`
+		})
+	}
+
+	result := ""
+	last := 0
+	for _, code := range reWikiLinkCode.FindAllStringIndex(rendered, -1) {
+		result += link(rendered[last:code[0]]) + rendered[code[0]:code[1]]
+		last = code[1]
+	}
+
+	return result + link(rendered[last:])
+}
+
+// FetchBacklinks Get the listed, published entries that link to an entry by its slug or title, newest first. None are
+// found while the journal is encrypted, as the content holding the links cannot be searched.
+func (js *Journals) FetchBacklinks(j Journal) []Journal {
+	if encrypted(js.Container) {
+		return []Journal{}
+	}
+	escape := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	rows, err := js.Container.Db.Query("SELECT "+journalColumns+" FROM `"+journalTable+"` WHERE `status` = ? AND "+journalNotDeleted+" AND "+journalListed+" AND `id` != ? AND (`content` LIKE ? ESCAPE '\\' OR `content` LIKE ? ESCAPE '\\') ORDER BY `date` DESC, `id` DESC",
+		JournalStatusPublished, strconv.Itoa(j.ID), "%[["+escape.Replace(j.Slug)+"]]%", "%[["+escape.Replace(j.Title)+"]]%")
+	if err != nil {
+		return []Journal{}
+	}
+
+	// Check each link found leads here, as another entry may share the title or use it as its slug
+	backlinks := []Journal{}
+	for _, candidate := range js.loadFromRows(rows) {
+		for _, target := range WikiLinkTargets(candidate.Content) {
+			if (target == j.Slug || strings.EqualFold(target, j.Title)) && js.FindWikiLink(target).ID == j.ID {
+				backlinks = append(backlinks, candidate)
+				break
+			}
+		}
+	}
+
+	return backlinks
+}
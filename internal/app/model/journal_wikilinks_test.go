@@ -0,0 +1,98 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/seal"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWikiLinkTargets(t *testing.T) {
+	targets := WikiLinkTargets("See [[slug]], [[ Fish &amp; Chips ]] and [not a link] or [[broken\nlink]]")
+	if len(targets) != 2 || targets[0] != "slug" || targets[1] != "Fish & Chips" {
+		t.Errorf("Expected slug and title targets, got %v", targets)
+	}
+}
+
+func TestStripWikiLinks(t *testing.T) {
+	if actual := StripWikiLinks("<p>See [[slug]] and [[Another Title]]</p>"); actual != "<p>See slug and Another Title</p>" {
+		t.Errorf("Expected wiki links to be replaced with their text, got %s", actual)
+	}
+}
+
+func TestJournals_FindByTitle(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if js.FindByTitle("Title").ID > 0 {
+		t.Error("Expected empty result set returned")
+	}
+
+	db.ExpectedArgument = "Title"
+	db.Rows = &database.MockJournal_SingleRow{}
+	if js.FindByTitle("Title").ID != 1 {
+		t.Error("Expected 1 row returned")
+	}
+}
+
+func TestJournals_LinkWikiLinks(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{BasePath: "/journal", Db: db}
+	js := Journals{Container: container}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	db.AppendResult(&database.MockRowsEmpty{})
+
+	actual := js.LinkWikiLinks("<p>See [[slug]], [[Missing &amp; Gone]] and [[slug]] again</p>\n<pre><code>[[slug]]\n</code></pre>")
+	expected := "<p>See <a class=\"wikilink\" href=\"/journal/slug\">Title</a>, <span class=\"wikilink-missing\">Missing &amp; Gone</span> and <a class=\"wikilink\" href=\"/journal/slug\">Title</a> again</p>\n<pre><code>[[slug]]\n</code></pre>"
+	if actual != expected {
+		t.Errorf("Expected wiki links to be resolved, got %s", actual)
+	}
+	if db.Queries != 2 {
+		t.Errorf("Expected each target to be looked up once, %d queries were run", db.Queries)
+	}
+
+	// Test drafts are not linked to
+	db.AppendResult(&database.MockJournal_SingleRow{Status: JournalStatusDraft})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if actual := js.LinkWikiLinks("<p>[[slug]]</p>"); actual != "<p><span class=\"wikilink-missing\">slug</span></p>" {
+		t.Errorf("Expected link to a draft to be left unresolved, got %s", actual)
+	}
+}
+
+func TestJournals_FetchBacklinks(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	target := Journal{ID: 1, Slug: "target", Title: "Target"}
+	if len(js.FetchBacklinks(target)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	// Test a link by title leading here
+	db.ErrorMode = false
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{Content: "Links to [[Target]]"})
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	if backlinks := js.FetchBacklinks(target); len(backlinks) != 1 || backlinks[0].Content != "Links to [[Target]]" {
+		t.Error("Expected entry linking here to be returned")
+	}
+
+	// Test a link by title leading to another entry of the same name
+	db.AppendResult(&database.MockJournal_SingleRow{Content: "Links to [[Target]]"})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if len(js.FetchBacklinks(target)) != 0 {
+		t.Error("Expected entry linking elsewhere to be ignored")
+	}
+
+	// Test nothing is searched for while the content is encrypted
+	db.Queries = 0
+	salt, _ := seal.NewSalt()
+	container.Sealer, _ = seal.New("correct horse", salt)
+	if len(js.FetchBacklinks(target)) != 0 || db.Queries != 0 {
+		t.Error("Expected no backlinks to be searched for while encrypted")
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+)
+
+// wordsPerMinute Average reading speed used to estimate how long an entry takes to read
+const wordsPerMinute = 200
+
+var reWord = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’\-][\p{L}\p{N}]+)*`)
+var reTag = regexp.MustCompile("<[^>]*>")
+
+// CountWords Count the words a reader sees in an entry's content, ignoring the markup
+func CountWords(content string) int {
+	text := html.UnescapeString(reTag.ReplaceAllString(Journal{Content: content}.GetHTML(), " "))
+
+	return len(reWord.FindAllString(text, -1))
+}
+
+// ReadingMinutes Estimate the whole minutes taken to read a number of words, at least one for any words at all
+func ReadingMinutes(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// GetWordCount Get the friendly word count for the Journal
+func (j Journal) GetWordCount() string {
+	if j.WordCount == 1 {
+		return "1 word"
+	}
+	return strconv.Itoa(j.WordCount) + " words"
+}
+
+// GetReadingTime Get the friendly estimated reading time for the Journal
+func (j Journal) GetReadingTime() string {
+	return strconv.Itoa(j.ReadingTime) + " min read"
+}
+
+// BackfillWordCounts Count the words in entries saved before word counts were stored
+func (js *Journals) BackfillWordCounts() error {
+	rows, err := js.Container.Db.Query("SELECT `id`, `content` FROM `" + journalTable + "` WHERE `word_count` = 0 AND TRIM(`content`) != ''")
+	if err != nil {
+		return err
+	}
+	counts := map[int]int{}
+	for rows.Next() {
+		var id int
+		var content string
+		rows.Scan(&id, &content)
+		counts[id] = CountWords(content)
+	}
+	rows.Close()
+
+	for id, words := range counts {
+		if words == 0 {
+			continue
+		}
+		if _, err := js.Container.Db.Exec("UPDATE `"+journalTable+"` SET `word_count` = ?, `reading_time` = ? WHERE `id` = ?", strconv.Itoa(words), strconv.Itoa(ReadingMinutes(words)), strconv.Itoa(id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
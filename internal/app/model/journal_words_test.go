@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCountWords(t *testing.T) {
+	tables := []struct {
+		input  string
+		output int
+	}{
+		{"", 0},
+		{"One", 1},
+		{"# A heading\n\nSome **bold** and _emphasised_ text.", 7},
+		{"A [link](https://example.com/a-long-path) here", 3},
+		{"It's a well-known fact &amp; more", 5},
+		{"---\n\n* one\n* two", 2},
+		{"<p>Older <em>HTML</em> content</p>", 3},
+	}
+
+	for _, table := range tables {
+		if actual := CountWords(table.input); actual != table.output {
+			t.Errorf("Expected CountWords(%q) to be %d, got %d", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestReadingMinutes(t *testing.T) {
+	tables := []struct {
+		input  int
+		output int
+	}{
+		{0, 0},
+		{1, 1},
+		{200, 1},
+		{201, 2},
+		{1000, 5},
+	}
+
+	for _, table := range tables {
+		if actual := ReadingMinutes(table.input); actual != table.output {
+			t.Errorf("Expected ReadingMinutes(%d) to be %d, got %d", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestJournal_GetWordCount(t *testing.T) {
+	if (Journal{WordCount: 1}).GetWordCount() != "1 word" || (Journal{WordCount: 250}).GetWordCount() != "250 words" {
+		t.Error("Expected friendly word counts")
+	}
+	if (Journal{ReadingTime: 2}).GetReadingTime() != "2 min read" {
+		t.Error("Expected friendly reading time")
+	}
+}
+
+func TestJournals_BackfillWordCounts(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	js := Journals{Container: container}
+	if err := js.BackfillWordCounts(); err == nil {
+		t.Error("Expected error to be returned")
+	}
+
+	// Test only entries with words are updated
+	db.ErrorMode = false
+	db.Rows = &database.MockJournalContent_MultipleRows{}
+	if err := js.BackfillWordCounts(); err != nil || db.Queries != 3 {
+		t.Errorf("Expected 1 entry to have been updated, %d queries were run", db.Queries)
+	}
+
+	// Test failure to update
+	db.Queries = 0
+	db.ErrorAtQuery = 2
+	db.Rows = &database.MockJournalContent_MultipleRows{}
+	if err := js.BackfillWordCounts(); err == nil || !strings.Contains(err.Error(), "Simulating") {
+		t.Error("Expected error to be returned when updating fails")
+	}
+}
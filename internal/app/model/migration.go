@@ -0,0 +1,148 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+)
+
+const migrationTable = "schema_migrations"
+
+// Errors refusing to roll back the schema
+var (
+	ErrIrreversible = errors.New("Migration cannot be rolled back")
+	ErrNoMigrations = errors.New("No migrations have been applied")
+)
+
+// Migration A versioned change to the schema, applied once in order of its version and recorded in the
+// schema_migrations table, with a way to undo it unless it cannot be
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(c *app.Container) error
+	Down    func(c *app.Container) error
+}
+
+// migrations Every change to the schema, in the order they are applied. Change the schema by appending a migration
+// with the next version, never by changing one that has been released, as databases that have applied it will not
+// apply it again.
+var migrations = []Migration{
+	{Version: 1, Name: "create tables", Up: CreateTables},
+	{Version: 2, Name: "index entries by slug", Up: indexJournalSlug, Down: dropJournalSlugIndex},
+	{Version: 3, Name: "create encryption table", Up: createEncryptionTable, Down: dropEncryptionTable},
+	{Version: 4, Name: "create journal meta table", Up: createJournalMetaTable, Down: dropJournalMetaTable},
+	{Version: 5, Name: "create session table", Up: createSessionTable, Down: dropSessionTable},
+	{Version: 6, Name: "add authors to entries", Up: addJournalAuthors, Down: removeJournalAuthors},
+	{Version: 7, Name: "create identity table", Up: createIdentityTable, Down: dropIdentityTable},
+	{Version: 8, Name: "create security event table", Up: createSecurityEventTable, Down: dropSecurityEventTable},
+	{Version: 9, Name: "create setting table", Up: createSettingTable, Down: dropSettingTable},
+	{Version: 10, Name: "create scheduled task table", Up: createScheduledTaskTable, Down: dropScheduledTaskTable},
+	{Version: 11, Name: "key autosaves by user", Up: keyAutosavesByUser, Down: unkeyAutosavesByUser},
+	{Version: 12, Name: "create journal tag table", Up: createJournalTagTable, Down: dropJournalTagTable},
+}
+
+// Migrate Apply every migration the database has not had yet, in order, returning those applied. Databases created
+// before migrations were recorded start from the first, which creates any table or column they are missing.
+func Migrate(c *app.Container) ([]Migration, error) {
+	applied := []Migration{}
+	versions, err := AppliedMigrations(c)
+	if err != nil {
+		return applied, err
+	}
+	done := map[int]bool{}
+	for _, version := range versions {
+		done[version] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		if err := m.Up(c); err != nil {
+			return applied, fmt.Errorf("Migration %d, %s: %s", m.Version, m.Name, err)
+		}
+		if _, err := c.Db.Exec("INSERT INTO `"+migrationTable+"` (`version`, `name`, `applied_at`) VALUES(?,?,?)", strconv.Itoa(m.Version), m.Name, time.Now().UTC().Format(jobTimeFormat)); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// Rollback Undo the latest migration the database has had, returning it
+func Rollback(c *app.Container) (Migration, error) {
+	versions, err := AppliedMigrations(c)
+	if err != nil {
+		return Migration{}, err
+	}
+	if len(versions) == 0 {
+		return Migration{}, ErrNoMigrations
+	}
+
+	latest := versions[len(versions)-1]
+	for _, m := range migrations {
+		if m.Version != latest {
+			continue
+		}
+		if m.Down == nil {
+			return m, ErrIrreversible
+		}
+		if err := m.Down(c); err != nil {
+			return m, fmt.Errorf("Migration %d, %s: %s", m.Version, m.Name, err)
+		}
+		_, err := c.Db.Exec("DELETE FROM `"+migrationTable+"` WHERE `version` = ?", strconv.Itoa(m.Version))
+
+		return m, err
+	}
+
+	return Migration{}, fmt.Errorf("Migration %d is not known to this version of the journal", latest)
+}
+
+// AppliedMigrations Get the versions of the migrations the database has had, in order, creating the table they are
+// recorded in if required
+func AppliedMigrations(c *app.Container) ([]int, error) {
+	versions := []int{}
+	_, err := c.Db.Exec("CREATE TABLE IF NOT EXISTS `" + migrationTable + "` (" +
+		"`version` INTEGER PRIMARY KEY, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`applied_at` DATETIME NOT NULL" +
+		")")
+	if err != nil {
+		return versions, err
+	}
+
+	rows, err := c.Db.Query("SELECT `version` FROM `" + migrationTable + "` ORDER BY `version`")
+	if err != nil {
+		return versions, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		rows.Scan(&version)
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// indexJournalSlug Index entries by slug, which every page showing an entry finds it by
+func indexJournalSlug(c *app.Container) error {
+	_, err := c.Db.Exec("CREATE INDEX `journal_slug` ON `" + journalTable + "` (`slug`)")
+
+	return err
+}
+
+func dropJournalSlugIndex(c *app.Container) error {
+	query := "DROP INDEX `journal_slug`"
+	if database.DialectOf(c.Db) == database.DialectMySQL {
+		query += " ON `" + journalTable + "`"
+	}
+	_, err := c.Db.Exec(query)
+
+	return err
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+// recorded Replace the migrations for a test, recording the versions applied and rolled back
+func recorded(t *testing.T, calls *[]int) {
+	previous := migrations
+	t.Cleanup(func() { migrations = previous })
+	step := func(version int) func(c *app.Container) error {
+		return func(c *app.Container) error {
+			*calls = append(*calls, version)
+			return nil
+		}
+	}
+	migrations = []Migration{
+		{Version: 1, Name: "first", Up: step(1)},
+		{Version: 2, Name: "second", Up: step(2), Down: step(-2)},
+		{Version: 3, Name: "third", Up: step(3), Down: step(-3)},
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	calls := []int{}
+	recorded(t, &calls)
+	db := &database.MockSqlite{Rows: &database.MockPagination_Result{TotalResults: 1}}
+	container := &app.Container{Db: db}
+	applied, err := Migrate(container)
+	if err != nil || len(applied) != 2 || applied[0].Version != 2 || applied[1].Version != 3 {
+		t.Errorf("Expected the migrations not yet applied to be applied, got %v and %v", applied, err)
+	}
+	if len(calls) != 2 || calls[0] != 2 || calls[1] != 3 {
+		t.Errorf("Expected migrations to be applied in order, got %v", calls)
+	}
+
+	// Test error stops migrating
+	calls = []int{}
+	db.Rows = &database.MockRowsEmpty{}
+	migrations[1].Up = func(c *app.Container) error {
+		return errors.New("Simulated error")
+	}
+	applied, err = Migrate(container)
+	if err == nil || len(applied) != 1 || len(calls) != 1 {
+		t.Errorf("Expected error to stop the migrations, got %v", applied)
+	}
+
+	db.ErrorMode = true
+	if _, err := Migrate(container); err == nil {
+		t.Error("Expected error to have been returned when the versions cannot be read")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	calls := []int{}
+	recorded(t, &calls)
+	db := &database.MockSqlite{Rows: &database.MockPagination_Result{TotalResults: 3}}
+	container := &app.Container{Db: db}
+	m, err := Rollback(container)
+	if err != nil || m.Version != 3 || len(calls) != 1 || calls[0] != -3 {
+		t.Errorf("Expected the latest migration to be rolled back, got %v and %v", m, err)
+	}
+
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	if _, err := Rollback(container); err != ErrIrreversible {
+		t.Errorf("Expected migration without a way back to be refused, got %v", err)
+	}
+
+	db.Rows = &database.MockPagination_Result{TotalResults: 4}
+	if _, err := Rollback(container); err == nil {
+		t.Error("Expected unknown migration to be refused")
+	}
+
+	db.Rows = &database.MockRowsEmpty{}
+	if _, err := Rollback(container); err != ErrNoMigrations {
+		t.Errorf("Expected error when nothing has been migrated, got %v", err)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 || m.Name == "" || m.Up == nil {
+			t.Errorf("Expected migration %d to follow on from the last, got %d", i+1, m.Version)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const scheduledTaskTable = "scheduled_task"
+
+// ScheduledTask When a task run in the background on an interval, such as backing up, last ran and is next due
+type ScheduledTask struct {
+	Name      string `json:"name"`
+	Interval  int    `json:"interval"`
+	LastRunAt string `json:"last_run_at"`
+	LastError string `json:"last_error"`
+	NextRunAt string `json:"next_run_at"`
+}
+
+// GetInterval Get how often the task runs, in minutes, hours or days
+func (t ScheduledTask) GetInterval() string {
+	interval, unit := t.GetIntervalCount(), t.GetIntervalUnit()
+	if interval == 1 {
+		return "Every " + unit
+	}
+
+	return fmt.Sprintf("Every %d %ss", interval, unit)
+}
+
+// GetIntervalUnit Get the unit how often the task runs is best given in, being minute, hour or day
+func (t ScheduledTask) GetIntervalUnit() string {
+	minutes := t.Interval / 60
+	switch {
+	case minutes > 0 && minutes%(24*60) == 0:
+		return "day"
+	case minutes > 0 && minutes%60 == 0:
+		return "hour"
+	}
+
+	return "minute"
+}
+
+// GetIntervalCount Get how many of its unit pass between each run of the task
+func (t ScheduledTask) GetIntervalCount() int {
+	switch t.GetIntervalUnit() {
+	case "day":
+		return t.Interval / (24 * 60 * 60)
+	case "hour":
+		return t.Interval / (60 * 60)
+	}
+
+	return t.Interval / 60
+}
+
+// IsDue Check whether the task is due to run at the given time
+func (t ScheduledTask) IsDue(now time.Time) bool {
+	next, err := time.Parse(jobTimeFormat, t.NextRunAt)
+
+	return err == nil && !now.UTC().Before(next)
+}
+
+// nextRun When the task is next due, being an interval after it last ran, or now when it never has or that has passed
+func (t ScheduledTask) nextRun(interval time.Duration, now time.Time) string {
+	next := now.UTC()
+	if last, err := time.Parse(jobTimeFormat, t.LastRunAt); err == nil && last.Add(interval).After(next) {
+		next = last.Add(interval)
+	}
+
+	return next.Format(jobTimeFormat)
+}
+
+// ScheduledTasks Common database resource link for ScheduledTask actions
+type ScheduledTasks struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ts *ScheduledTasks) CreateTable() error {
+	_, err := ts.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + scheduledTaskTable + "` (" +
+		"`name` VARCHAR(64) NOT NULL PRIMARY KEY, " +
+		"`interval` INTEGER NOT NULL, " +
+		"`last_run_at` VARCHAR(19) NOT NULL DEFAULT '', " +
+		"`last_error` TEXT NOT NULL DEFAULT '', " +
+		"`next_run_at` VARCHAR(19) NOT NULL" +
+		")")
+
+	return err
+}
+
+// FetchAll Get every task, the next due first
+func (ts *ScheduledTasks) FetchAll() []ScheduledTask {
+	return ts.loadFromRows(ts.Container.Db.Query("SELECT " + scheduledTaskColumns + " FROM `" + scheduledTaskTable + "` ORDER BY `next_run_at`, `name`"))
+}
+
+// FindByName Find a task by its name
+func (ts *ScheduledTasks) FindByName(name string) ScheduledTask {
+	tasks := ts.loadFromRows(ts.Container.Db.Query("SELECT "+scheduledTaskColumns+" FROM `"+scheduledTaskTable+"` WHERE `name` = ? LIMIT 1", name))
+	if len(tasks) == 0 {
+		return ScheduledTask{}
+	}
+
+	return tasks[0]
+}
+
+// Save Save a task, replacing any saved before under its name
+func (ts *ScheduledTasks) Save(t ScheduledTask) error {
+	_, err := ts.Container.Db.Exec("INSERT INTO `"+scheduledTaskTable+"` (`name`, `interval`, `last_run_at`, `last_error`, `next_run_at`) VALUES(?,?,?,?,?) "+
+		"ON CONFLICT (`name`) DO UPDATE SET `interval` = excluded.`interval`, `last_run_at` = excluded.`last_run_at`, "+
+		"`last_error` = excluded.`last_error`, `next_run_at` = excluded.`next_run_at`",
+		t.Name, strconv.Itoa(t.Interval), t.LastRunAt, t.LastError, t.NextRunAt)
+
+	return err
+}
+
+// Plan Save how often a task runs, due at once when it has never run, or otherwise an interval after it last did
+func (ts *ScheduledTasks) Plan(name string, interval time.Duration, now time.Time) error {
+	t := ts.FindByName(name)
+	t.Name = name
+	t.Interval = int(interval / time.Second)
+	t.NextRunAt = t.nextRun(interval, now)
+
+	return ts.Save(t)
+}
+
+// Delete Forget a task, such as once it has been disabled
+func (ts *ScheduledTasks) Delete(name string) error {
+	_, err := ts.Container.Db.Exec("DELETE FROM `"+scheduledTaskTable+"` WHERE `name` = ?", name)
+
+	return err
+}
+
+// Claim Move a due task on to its next run, giving whether it was claimed, so that only one process runs it when
+// several share the database
+func (ts *ScheduledTasks) Claim(t ScheduledTask, now time.Time) (ScheduledTask, bool) {
+	next := now.UTC().Add(time.Duration(t.Interval) * time.Second).Format(jobTimeFormat)
+	res, err := ts.Container.Db.Exec("UPDATE `"+scheduledTaskTable+"` SET `next_run_at` = ? WHERE `name` = ? AND `next_run_at` = ?", next, t.Name, t.NextRunAt)
+	if err != nil {
+		return t, false
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		return t, false
+	}
+	t.NextRunAt = next
+
+	return t, true
+}
+
+// Ran Record when a task last ran and what went wrong, if anything
+func (ts *ScheduledTasks) Ran(t ScheduledTask, at time.Time, reason error) error {
+	lastError := ""
+	if reason != nil {
+		lastError = reason.Error()
+	}
+	_, err := ts.Container.Db.Exec("UPDATE `"+scheduledTaskTable+"` SET `last_run_at` = ?, `last_error` = ? WHERE `name` = ?", at.UTC().Format(jobTimeFormat), lastError, t.Name)
+
+	return err
+}
+
+const scheduledTaskColumns = "`name`, `interval`, `last_run_at`, `last_error`, `next_run_at`"
+
+func (ts ScheduledTasks) loadFromRows(rows rows.Rows, err error) []ScheduledTask {
+	tasks := []ScheduledTask{}
+	if err != nil {
+		return tasks
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := ScheduledTask{}
+		rows.Scan(&t.Name, &t.Interval, &t.LastRunAt, &t.LastError, &t.NextRunAt)
+		tasks = append(tasks, t)
+	}
+
+	return tasks
+}
+
+func createScheduledTaskTable(c *app.Container) error {
+	ts := ScheduledTasks{Container: c}
+
+	return ts.CreateTable()
+}
+
+func dropScheduledTaskTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + scheduledTaskTable + "`")
+
+	return err
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestScheduledTask_GetInterval(t *testing.T) {
+	tables := []struct {
+		interval int
+		expected string
+		unit     string
+		count    int
+	}{
+		{60, "Every minute", "minute", 1},
+		{300, "Every 5 minutes", "minute", 5},
+		{3600, "Every hour", "hour", 1},
+		{6 * 3600, "Every 6 hours", "hour", 6},
+		{86400, "Every day", "day", 1},
+		{90 * 60, "Every 90 minutes", "minute", 90},
+	}
+	for _, table := range tables {
+		task := ScheduledTask{Interval: table.interval}
+		if actual := task.GetInterval(); actual != table.expected {
+			t.Errorf("Expected '%s' for %d seconds, got '%s'", table.expected, table.interval, actual)
+		}
+		if task.GetIntervalUnit() != table.unit || task.GetIntervalCount() != table.count {
+			t.Errorf("Expected %d %s for %d seconds, got %d %s", table.count, table.unit, table.interval, task.GetIntervalCount(), task.GetIntervalUnit())
+		}
+	}
+}
+
+func TestScheduledTask_IsDue(t *testing.T) {
+	task := ScheduledTask{NextRunAt: "2018-02-01 00:00:00"}
+	if task.IsDue(time.Date(2018, 1, 31, 23, 59, 59, 0, time.UTC)) || !task.IsDue(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected task to be due once its next run has come")
+	}
+	if (ScheduledTask{}).IsDue(time.Now()) {
+		t.Error("Expected task without a next run never to be due")
+	}
+}
+
+func TestScheduledTasks_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	ts.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestScheduledTasks_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	db.ErrorMode = true
+	if tasks := ts.FetchAll(); len(tasks) != 0 {
+		t.Error("Expected no tasks when database fails")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockScheduledTask_MultipleRows{}
+	tasks := ts.FetchAll()
+	if len(tasks) != 2 || tasks[0].Name != "publish" || tasks[1].LastError != "disk full" {
+		t.Errorf("Expected tasks to have been returned, got %v", tasks)
+	}
+}
+
+func TestScheduledTasks_FindByName(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if task := ts.FindByName("backup"); task.Name != "" {
+		t.Error("Expected no task to have been found")
+	}
+
+	db.Rows = &database.MockScheduledTask_SingleRow{}
+	if task := ts.FindByName("backup"); task.Name != "backup" || task.Interval != 3600 {
+		t.Error("Expected task to have been found")
+	}
+}
+
+func TestScheduledTasks_Plan(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	db.Rows = &database.MockScheduledTask_SingleRow{}
+	if err := ts.Plan("backup", time.Hour, time.Now()); err != nil || db.Queries != 2 {
+		t.Errorf("Expected task to have been planned, got %v", err)
+	}
+
+	// Test when the task is next due
+	now := time.Date(2018, 2, 1, 12, 0, 0, 0, time.UTC)
+	tables := []struct {
+		lastRunAt string
+		expected  string
+	}{
+		{"", "2018-02-01 12:00:00"},
+		{"2018-02-01 11:30:00", "2018-02-01 12:30:00"},
+		{"2018-01-31 11:30:00", "2018-02-01 12:00:00"},
+	}
+	for _, table := range tables {
+		if actual := (ScheduledTask{LastRunAt: table.lastRunAt}).nextRun(time.Hour, now); actual != table.expected {
+			t.Errorf("Expected task last run at '%s' to be due at %s, got %s", table.lastRunAt, table.expected, actual)
+		}
+	}
+}
+
+func TestScheduledTasks_Claim(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	task := ScheduledTask{Name: "backup", Interval: 3600, NextRunAt: "2018-02-01 00:00:00"}
+	now := time.Date(2018, 2, 1, 0, 0, 30, 0, time.UTC)
+
+	claimed, ok := ts.Claim(task, now)
+	if !ok || claimed.NextRunAt != "2018-02-01 01:00:30" {
+		t.Errorf("Expected task to have been claimed and moved on, got %v", claimed)
+	}
+
+	// Test a task claimed by another process first
+	db.Result = &database.MockResult{Affected: 0}
+	if _, ok := ts.Claim(task, now); ok {
+		t.Error("Expected task claimed elsewhere not to be claimed")
+	}
+	db.ErrorMode = true
+	if _, ok := ts.Claim(task, now); ok {
+		t.Error("Expected task not to be claimed when database fails")
+	}
+}
+
+func TestScheduledTasks_Ran(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	db.ExpectedArgument = "disk full"
+	if err := ts.Ran(ScheduledTask{Name: "backup"}, time.Now(), errors.New("disk full")); err != nil {
+		t.Errorf("Expected failure to have been recorded, got %v", err)
+	}
+	db.ExpectedArgument = ""
+	if err := ts.Ran(ScheduledTask{Name: "backup"}, time.Now(), nil); err != nil || db.Queries != 2 {
+		t.Error("Expected run to have been recorded")
+	}
+}
+
+func TestScheduledTasks_Delete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := ScheduledTasks{Container: container}
+	db.ExpectedArgument = "backup"
+	if err := ts.Delete("backup"); err != nil || db.Queries != 1 {
+		t.Error("Expected task to have been deleted")
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import "github.com/jamiefdhurst/journal/internal/app"
+
+// CreateTables Create every table used by the application, if required, and bring existing entries up to date
+func CreateTables(container *app.Container) error {
+	tables := []interface{ CreateTable() error }{
+		&Journals{Container: container},
+		&JournalLinks{Container: container},
+		&JournalMetas{Container: container},
+		&JournalTags{Container: container},
+		&JournalSearch{Container: container},
+		&JournalRevisions{Container: container},
+		&JournalAutosaves{Container: container},
+		&Attachments{Container: container},
+		&Categories{Container: container},
+		&Comments{Container: container},
+		&Shortcodes{Container: container},
+		&Jobs{Container: container},
+		&Tenants{Container: container},
+		&Users{Container: container},
+		&Tokens{Container: container},
+		&Sessions{Container: container},
+		&Identities{Container: container},
+		&SecurityEvents{Container: container},
+		&Settings{Container: container},
+		&ScheduledTasks{Container: container},
+		&ActorKeys{Container: container},
+		&Followers{Container: container},
+		&FederatedEntries{Container: container},
+		&Webhooks{Container: container},
+		&WebhookDeliveries{Container: container},
+		&Subscriptions{Container: container},
+		&SubscriptionItems{Container: container},
+	}
+	for _, t := range tables {
+		if err := t.CreateTable(); err != nil {
+			return err
+		}
+	}
+
+	// Fill in what is now worked out on save for entries written before it was stored
+	js := Journals{Container: container}
+
+	return js.BackfillWordCounts()
+}
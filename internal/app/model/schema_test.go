@@ -0,0 +1,24 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestCreateTables(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	if err := CreateTables(container); err != nil || db.Queries < 2 {
+		t.Errorf("Expected all tables to have been created, %d queries were run", db.Queries)
+	}
+
+	// Test error stops creation
+	db.Queries = 0
+	db.ErrorAtQuery = 2
+	if err := CreateTables(container); err == nil || db.Queries != 2 {
+		t.Error("Expected error to have been returned on failure")
+	}
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const securityEventTable = "security_event"
+
+// Security event kinds
+const (
+	SecurityLogin           = "login"
+	SecurityLoginFailed     = "login_failed"
+	SecurityLockout         = "lockout"
+	SecurityPasswordChanged = "password_changed"
+)
+
+// Lockout scopes, kept as the detail of a lockout event
+const (
+	LockoutAccount = "account"
+	LockoutAddress = "address"
+)
+
+// SecurityEvent model, something done to or by an account worth auditing, such as signing in or changing a password
+type SecurityEvent struct {
+	ID        int
+	Kind      string
+	UserID    int
+	Username  string
+	IP        string
+	Detail    string
+	CreatedAt string
+}
+
+// SecurityEvents Common database resource link for SecurityEvent actions
+type SecurityEvents struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (es *SecurityEvents) CreateTable() error {
+	_, err := es.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + securityEventTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`kind` VARCHAR(20) NOT NULL, " +
+		"`user_id` INTEGER NOT NULL DEFAULT 0, " +
+		"`username` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`ip` VARCHAR(64) NOT NULL DEFAULT '', " +
+		"`detail` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Failures Count the failed sign in attempts within a window for an account, since it last signed in or was locked, and
+// for an address, since it was last locked
+func (es *SecurityEvents) Failures(username string, ip string, window time.Duration) (int, int) {
+	since := time.Now().UTC().Add(-window).Format(jobTimeFormat)
+	account := es.count("SELECT COUNT(*) FROM `"+securityEventTable+"` WHERE `kind` = ? AND `username` = ? AND `created_at` > ? AND `id` > "+
+		"COALESCE((SELECT MAX(`id`) FROM `"+securityEventTable+"` WHERE `username` = ? AND (`kind` = ? OR (`kind` = ? AND `detail` = ?))), 0)",
+		SecurityLoginFailed, username, since, username, SecurityLogin, SecurityLockout, LockoutAccount)
+	address := es.count("SELECT COUNT(*) FROM `"+securityEventTable+"` WHERE `kind` = ? AND `ip` = ? AND `created_at` > ? AND `id` > "+
+		"COALESCE((SELECT MAX(`id`) FROM `"+securityEventTable+"` WHERE `ip` = ? AND `kind` = ? AND `detail` = ?), 0)",
+		SecurityLoginFailed, ip, since, ip, SecurityLockout, LockoutAddress)
+
+	return account, address
+}
+
+// FetchPaginated Get a page of events, the most recent first, optionally only those of one kind
+func (es *SecurityEvents) FetchPaginated(kind string, query database.PaginationQuery) ([]SecurityEvent, database.PaginationInformation) {
+	pagination := database.PaginationInformation{
+		Page:           query.Page,
+		ResultsPerPage: query.ResultsPerPage,
+	}
+	where, args := "", []interface{}{}
+	if kind != "" {
+		where, args = " WHERE `kind` = ?", append(args, kind)
+	}
+
+	pagination.TotalResults = es.count("SELECT COUNT(*) FROM `"+securityEventTable+"`"+where, args...)
+	pagination.TotalPages = int(math.Ceil(float64(pagination.TotalResults) / float64(query.ResultsPerPage)))
+	if query.Page > pagination.TotalPages {
+		return []SecurityEvent{}, pagination
+	}
+
+	rows, err := es.Container.Db.Query("SELECT "+securityEventColumns+" FROM `"+securityEventTable+"`"+where+" ORDER BY `id` DESC LIMIT ? OFFSET ?", append(args, query.ResultsPerPage, (query.Page-1)*query.ResultsPerPage)...)
+	if err != nil {
+		return []SecurityEvent{}, pagination
+	}
+
+	return es.loadFromRows(rows), pagination
+}
+
+// LockedOut Whether signing in is locked for an account or an address, having been locked within the window
+func (es *SecurityEvents) LockedOut(username string, ip string, window time.Duration) bool {
+	since := time.Now().UTC().Add(-window).Format(jobTimeFormat)
+
+	return es.count("SELECT COUNT(*) FROM `"+securityEventTable+"` WHERE `kind` = ? AND `created_at` > ? AND ((`detail` = ? AND `username` = ?) OR (`detail` = ? AND `ip` = ?))",
+		SecurityLockout, since, LockoutAccount, username, LockoutAddress, ip) > 0
+}
+
+// Record Save an event as happening now
+func (es *SecurityEvents) Record(e SecurityEvent) (SecurityEvent, error) {
+	e.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := es.Container.Db.Exec("INSERT INTO `"+securityEventTable+"` (`kind`, `user_id`, `username`, `ip`, `detail`, `created_at`) VALUES(?,?,?,?,?,?)", e.Kind, strconv.Itoa(e.UserID), e.Username, e.IP, e.Detail, e.CreatedAt)
+	if err != nil {
+		return e, err
+	}
+	id, _ := res.LastInsertId()
+	e.ID = int(id)
+
+	return e, nil
+}
+
+const securityEventColumns = "`id`, `kind`, `user_id`, `username`, `ip`, `detail`, `created_at`"
+
+func (es *SecurityEvents) count(query string, args ...interface{}) int {
+	total := 0
+	rows, err := es.Container.Db.Query(query, args...)
+	if err != nil {
+		return total
+	}
+	defer rows.Close()
+	if rows.Next() {
+		rows.Scan(&total)
+	}
+
+	return total
+}
+
+func (es SecurityEvents) loadFromRows(rows rows.Rows) []SecurityEvent {
+	defer rows.Close()
+	events := []SecurityEvent{}
+	for rows.Next() {
+		e := SecurityEvent{}
+		rows.Scan(&e.ID, &e.Kind, &e.UserID, &e.Username, &e.IP, &e.Detail, &e.CreatedAt)
+		events = append(events, e)
+	}
+
+	return events
+}
+
+// createSecurityEventTable Create the table for databases created before security events were recorded, indexed for
+// counting the recent failures of an account or address on each attempt to sign in
+func createSecurityEventTable(c *app.Container) error {
+	es := SecurityEvents{Container: c}
+	if err := es.CreateTable(); err != nil {
+		return err
+	}
+	_, err := c.Db.Exec("CREATE INDEX `security_event_kind_created_at` ON `" + securityEventTable + "` (`kind`, `created_at`)")
+
+	return err
+}
+
+func dropSecurityEventTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + securityEventTable + "`")
+
+	return err
+}
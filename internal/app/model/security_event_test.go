@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	pkgDb "github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSecurityEvents_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	es := SecurityEvents{Container: container}
+	es.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestSecurityEvents_Failures(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	es := SecurityEvents{Container: container}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 3})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 7})
+	if account, address := es.Failures("jamie", "192.0.2.1", 15*time.Minute); account != 3 || address != 7 || db.Queries != 2 {
+		t.Errorf("Expected failures to be counted for the account and address, got %d and %d", account, address)
+	}
+
+	db.ErrorMode = true
+	if account, address := es.Failures("jamie", "192.0.2.1", 15*time.Minute); account != 0 || address != 0 {
+		t.Error("Expected no failures when database fails")
+	}
+}
+
+func TestSecurityEvents_FetchPaginated(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	es := SecurityEvents{Container: container}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 2})
+	db.AppendResult(&database.MockSecurityEvent_MultipleRows{})
+	events, pagination := es.FetchPaginated("", pkgDb.PaginationQuery{Page: 1, ResultsPerPage: 20})
+	if len(events) != 2 || pagination.TotalPages != 1 || events[0].Kind != SecurityLockout || events[1].Username != "<nobody>" {
+		t.Error("Expected events to have been returned")
+	}
+
+	// Test filtering by kind and asking beyond the last page
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	db.ExpectedArgument = SecurityLockout
+	events, pagination = es.FetchPaginated(SecurityLockout, pkgDb.PaginationQuery{Page: 2, ResultsPerPage: 20})
+	if len(events) != 0 || pagination.TotalResults != 1 {
+		t.Error("Expected no events beyond the last page")
+	}
+}
+
+func TestSecurityEvents_LockedOut(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	es := SecurityEvents{Container: container}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	if es.LockedOut("jamie", "192.0.2.1", 15*time.Minute) {
+		t.Error("Expected account without a recent lockout to be open")
+	}
+	if !es.LockedOut("jamie", "192.0.2.1", 15*time.Minute) {
+		t.Error("Expected account with a recent lockout to be locked")
+	}
+}
+
+func TestSecurityEvents_Record(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	es := SecurityEvents{Container: container}
+	db.ExpectedArgument = "192.0.2.1"
+	event, err := es.Record(SecurityEvent{Kind: SecurityLogin, UserID: 1, Username: "jamie", IP: "192.0.2.1"})
+	if err != nil || event.ID != 1 || event.CreatedAt == "" {
+		t.Error("Expected event to have been recorded")
+	}
+
+	db.ErrorMode = true
+	if _, err := es.Record(SecurityEvent{Kind: SecurityLogin}); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const sessionTable = "session"
+
+// SessionLifetime How long a user stays signed in after signing in through the login form
+const SessionLifetime = 30 * 24 * time.Hour
+
+// Session model, a user signed in through the login form and remembered by a cookie
+type Session struct {
+	ID        int
+	UserID    int
+	Hash      string
+	ExpiresAt string
+	CreatedAt string
+}
+
+// Sessions Common database resource link for Session actions
+type Sessions struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table, keeping only a digest of each session's cookie as for API tokens
+func (ss *Sessions) CreateTable() error {
+	_, err := ss.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + sessionTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`user_id` INTEGER NOT NULL, " +
+		"`hash` CHAR(64) NOT NULL UNIQUE, " +
+		"`expires_at` VARCHAR(20) NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Authenticate Find the session matching the plain value of a cookie, unless it has expired
+func (ss *Sessions) Authenticate(plain string) Session {
+	if plain == "" {
+		return Session{}
+	}
+
+	return ss.loadSingle(ss.Container.Db.Query("SELECT "+sessionColumns+" FROM `"+sessionTable+"` WHERE `hash` = ? AND `expires_at` > ? LIMIT 1", HashToken(plain), time.Now().UTC().Format(jobTimeFormat)))
+}
+
+// DeleteByUser Sign a user out everywhere, removing each of their sessions
+func (ss *Sessions) DeleteByUser(userID int) error {
+	_, err := ss.Container.Db.Exec("DELETE FROM `"+sessionTable+"` WHERE `user_id` = ?", strconv.Itoa(userID))
+
+	return err
+}
+
+// End Remove the session matching the plain value of a cookie, along with any others that have expired
+func (ss *Sessions) End(plain string) error {
+	_, err := ss.Container.Db.Exec("DELETE FROM `"+sessionTable+"` WHERE `hash` = ? OR `expires_at` <= ?", HashToken(plain), time.Now().UTC().Format(jobTimeFormat))
+
+	return err
+}
+
+// Start Sign a user in, returning the plain value for their cookie which is never stored
+func (ss *Sessions) Start(userID int) (Session, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return Session{}, "", err
+	}
+	plain := hex.EncodeToString(random)
+
+	now := time.Now().UTC()
+	s := Session{UserID: userID, Hash: HashToken(plain), ExpiresAt: now.Add(SessionLifetime).Format(jobTimeFormat), CreatedAt: now.Format(jobTimeFormat)}
+	res, err := ss.Container.Db.Exec("INSERT INTO `"+sessionTable+"` (`user_id`, `hash`, `expires_at`, `created_at`) VALUES(?,?,?,?)", strconv.Itoa(s.UserID), s.Hash, s.ExpiresAt, s.CreatedAt)
+	if err != nil {
+		return Session{}, "", err
+	}
+	id, _ := res.LastInsertId()
+	s.ID = int(id)
+
+	return s, plain, nil
+}
+
+const sessionColumns = "`id`, `user_id`, `hash`, `expires_at`, `created_at`"
+
+func (ss Sessions) loadFromRows(rows rows.Rows) []Session {
+	defer rows.Close()
+	sessions := []Session{}
+	for rows.Next() {
+		s := Session{}
+		rows.Scan(&s.ID, &s.UserID, &s.Hash, &s.ExpiresAt, &s.CreatedAt)
+		sessions = append(sessions, s)
+	}
+
+	return sessions
+}
+
+func (ss *Sessions) loadSingle(rows rows.Rows, err error) Session {
+	if err != nil {
+		return Session{}
+	}
+	sessions := ss.loadFromRows(rows)
+
+	if len(sessions) == 1 {
+		return sessions[0]
+	}
+
+	return Session{}
+}
+
+// createSessionTable Create the table for databases created before users could sign in through the login form
+func createSessionTable(c *app.Container) error {
+	ss := Sessions{Container: c}
+
+	return ss.CreateTable()
+}
+
+func dropSessionTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + sessionTable + "`")
+
+	return err
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSessions_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Sessions{Container: container}
+	ss.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestSessions_Authenticate(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ss := Sessions{Container: container}
+
+	// Test empty cookie never queries
+	if ss.Authenticate("").ID > 0 || db.Queries > 0 {
+		t.Error("Expected empty cookie to have been rejected")
+	}
+
+	// Test unknown or expired session
+	db.Rows = &database.MockRowsEmpty{}
+	if ss.Authenticate("unknown").ID > 0 {
+		t.Error("Expected unknown session to have been rejected")
+	}
+
+	// Test known session is looked up by hash
+	db.Rows = &database.MockSession_SingleRow{}
+	db.ExpectedArgument = HashToken("known")
+	if session := ss.Authenticate("known"); session.ID != 1 || session.UserID != 1 {
+		t.Error("Expected session to have been found")
+	}
+}
+
+func TestSessions_Start(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ss := Sessions{Container: container}
+	session, plain, err := ss.Start(1)
+	if err != nil || session.ID != 1 || session.UserID != 1 || session.ExpiresAt <= session.CreatedAt {
+		t.Error("Expected session to have been started")
+	}
+	if len(plain) != 64 || session.Hash != HashToken(plain) {
+		t.Error("Expected plain cookie value to be returned and only its hash stored")
+	}
+
+	db.ErrorMode = true
+	if _, _, err = ss.Start(1); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
+
+func TestSessions_End(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ss := Sessions{Container: container}
+	db.ExpectedArgument = HashToken("known")
+	if err := ss.End("known"); err != nil || db.Queries != 1 {
+		t.Error("Expected session to have been ended")
+	}
+	db.ExpectedArgument = "2"
+	if err := ss.DeleteByUser(2); err != nil || db.Queries != 2 {
+		t.Error("Expected user's sessions to have been removed")
+	}
+}
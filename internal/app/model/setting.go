@@ -0,0 +1,73 @@
+package model
+
+import (
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+const settingTable = "setting"
+
+// Settings Common database resource link for the settings saved in the journal's database
+type Settings struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ss *Settings) CreateTable() error {
+	_, err := ss.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + settingTable + "` (" +
+		"`name` VARCHAR(64) NOT NULL PRIMARY KEY, " +
+		"`value` TEXT NOT NULL" +
+		")")
+
+	return err
+}
+
+// FetchAll Get every setting saved, by its name
+func (ss *Settings) FetchAll() map[string]string {
+	settings := map[string]string{}
+	rows, err := ss.Container.Db.Query("SELECT `name`, `value` FROM `" + settingTable + "`")
+	if err != nil {
+		return settings
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		rows.Scan(&name, &value)
+		settings[name] = value
+	}
+
+	return settings
+}
+
+// Load Read every setting saved, for the container to share with each request it serves
+func (ss *Settings) Load() *app.Settings {
+	return app.NewSettings(ss.FetchAll())
+}
+
+// Save Save a setting, replacing any saved before, and keep it in the container so that requests use it from then on.
+// An empty value removes the setting, leaving the one configured.
+func (ss *Settings) Save(name string, value string) error {
+	var err error
+	if value == "" {
+		_, err = ss.Container.Db.Exec("DELETE FROM `"+settingTable+"` WHERE `name` = ?", name)
+	} else {
+		_, err = ss.Container.Db.Exec("INSERT INTO `"+settingTable+"` (`name`, `value`) VALUES(?,?) "+
+			"ON CONFLICT (`name`) DO UPDATE SET `value` = excluded.`value`", name, value)
+	}
+	if err == nil && ss.Container.Settings != nil {
+		ss.Container.Settings.Set(name, value)
+	}
+
+	return err
+}
+
+func createSettingTable(c *app.Container) error {
+	ss := Settings{Container: c}
+
+	return ss.CreateTable()
+}
+
+func dropSettingTable(c *app.Container) error {
+	_, err := c.Db.Exec("DROP TABLE `" + settingTable + "`")
+
+	return err
+}
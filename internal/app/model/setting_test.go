@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSettings_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Settings{Container: container}
+	ss.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestSettings_Load(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Settings{Container: container}
+	db.ErrorMode = true
+	if settings := ss.FetchAll(); len(settings) != 0 {
+		t.Error("Expected no settings when database fails")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockSetting_MultipleRows{}
+	if settings := ss.Load(); settings.Get(app.SettingTitle) != "Alice's Journal" {
+		t.Error("Expected the settings saved to have been loaded")
+	}
+}
+
+func TestSettings_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db, Settings: app.NewSettings(nil)}
+	ss := Settings{Container: container}
+	db.ExpectedArgument = "Alice's Journal"
+	if err := ss.Save(app.SettingTitle, "Alice's Journal"); err != nil || db.Queries != 1 || container.Settings.Get(app.SettingTitle) != "Alice's Journal" {
+		t.Errorf("Expected the setting to have been saved and kept, got %v", err)
+	}
+
+	db.ErrorMode = true
+	if err := ss.Save(app.SettingTitle, "Bob's Journal"); err == nil || container.Settings.Get(app.SettingTitle) != "Alice's Journal" {
+		t.Error("Expected the setting to be left as it was when database fails")
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+)
+
+// NeedsSetup Check whether the journal is still to be set up, having no admin to manage it and no bootstrap admin token
+// to add one with. Journals hosted for tenants are never set up this way.
+func NeedsSetup(c *app.Container) bool {
+	if c.Configuration.AdminToken != "" || c.Tenant != "" {
+		return false
+	}
+	us := Users{Container: c}
+
+	return us.CountAdmins() == 0
+}
+
+// NewSetupCode Create the code asked for while setting the journal up, logged as it starts so that only whoever runs it
+// can take it over
+func NewSetupCode() (string, error) {
+	random := make([]byte, 6)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(random), nil
+}
+
+// Setup Set the journal up, saving its title, when one is given, and adding its first admin with a password
+func Setup(c *app.Container, title string, admin User, password string) (User, error) {
+	if !NeedsSetup(c) {
+		return admin, errors.New("Journal has already been set up")
+	}
+	admin.Role = RoleAdmin
+	if err := admin.SetPassword(password); err != nil {
+		return admin, err
+	}
+
+	// The title is saved first, as saving it again replaces it should adding the admin fail
+	if title != "" {
+		ss := Settings{Container: c}
+		if err := ss.Save(app.SettingTitle, title); err != nil {
+			return admin, err
+		}
+	}
+	us := Users{Container: c}
+
+	return us.Save(admin)
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestNeedsSetup(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	db.Rows = &database.MockPagination_Result{TotalResults: 0}
+	if !NeedsSetup(container) {
+		t.Error("Expected a journal without an admin to need setting up")
+	}
+	db.Rows = &database.MockPagination_Result{TotalResults: 1}
+	if NeedsSetup(container) {
+		t.Error("Expected a journal with an admin to be set up")
+	}
+
+	db.Queries = 0
+	container.Configuration.AdminToken = "bootstrap"
+	if NeedsSetup(container) || db.Queries != 0 {
+		t.Error("Expected a journal with an admin token to be set up with it instead")
+	}
+	container.Configuration.AdminToken = ""
+	container.Tenant = "alice"
+	if NeedsSetup(container) || db.Queries != 0 {
+		t.Error("Expected hosted journals never to be set up")
+	}
+}
+
+func TestNewSetupCode(t *testing.T) {
+	code, err := NewSetupCode()
+	if err != nil || len(code) != 12 {
+		t.Errorf("Expected a random code, got %s", code)
+	}
+	if other, _ := NewSetupCode(); other == code {
+		t.Error("Expected each code to differ")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db, Settings: app.NewSettings(nil)}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	if _, err := Setup(container, "Alice's Journal", User{Username: "alice"}, "short"); err == nil || db.Queries != 1 {
+		t.Error("Expected a short password to be refused before anything is saved")
+	}
+
+	db.Queries = 0
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 0})
+	db.AppendResult(&database.MockRowsEmpty{})
+	admin, err := Setup(container, "Alice's Journal", User{Username: "alice", Email: "alice@example.com"}, "password1")
+	if err != nil || admin.ID != 1 || admin.Role != RoleAdmin || !admin.CheckPassword("password1") {
+		t.Errorf("Expected the first admin to have been added, got %v", err)
+	}
+	if container.Settings.Get(app.SettingTitle) != "Alice's Journal" || db.Queries != 4 {
+		t.Errorf("Expected the title to have been saved, %d queries were run", db.Queries)
+	}
+
+	db.AppendResult(&database.MockPagination_Result{TotalResults: 1})
+	if _, err := Setup(container, "", User{Username: "mallory"}, "password1"); err == nil {
+		t.Error("Expected a journal already set up to be refused")
+	}
+}
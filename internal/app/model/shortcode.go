@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+	"github.com/jamiefdhurst/journal/pkg/emoji"
+)
+
+const shortcodeTable = "shortcode"
+
+const shortcodeColumns = "`id`, `name`, `value`"
+
+// Shortcode model, a custom :name: written in entries and shown as the emoji or text it stands for
+type Shortcode struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Shortcodes Common database resource link for Shortcode actions
+type Shortcodes struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ss *Shortcodes) CreateTable() error {
+	_, err := ss.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + shortcodeTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`name` VARCHAR(50) NOT NULL UNIQUE, " +
+		"`value` VARCHAR(255) NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Remove a custom shortcode, leaving it written out wherever it was used
+func (ss *Shortcodes) Delete(s Shortcode) error {
+	_, err := ss.Container.Db.Exec("DELETE FROM `"+shortcodeTable+"` WHERE `id` = ?", strconv.Itoa(s.ID))
+
+	return err
+}
+
+// FetchAll Get every custom shortcode, ordered by name
+func (ss *Shortcodes) FetchAll() []Shortcode {
+	rows, err := ss.Container.Db.Query("SELECT " + shortcodeColumns + " FROM `" + shortcodeTable + "` ORDER BY `name`")
+	if err != nil {
+		return []Shortcode{}
+	}
+
+	return ss.loadFromRows(rows)
+}
+
+// FindByID Find a custom shortcode by its ID
+func (ss *Shortcodes) FindByID(id int) Shortcode {
+	return ss.loadSingle(ss.Container.Db.Query("SELECT "+shortcodeColumns+" FROM `"+shortcodeTable+"` WHERE `id` = ? LIMIT 1", strconv.Itoa(id)))
+}
+
+// FindByName Find a custom shortcode by its name
+func (ss *Shortcodes) FindByName(name string) Shortcode {
+	return ss.loadSingle(ss.Container.Db.Query("SELECT "+shortcodeColumns+" FROM `"+shortcodeTable+"` WHERE `name` = ? LIMIT 1", name))
+}
+
+// Replace Turn shortcodes in rendered content into emoji, looking up custom ones only when the content uses any
+func (ss *Shortcodes) Replace(rendered string) string {
+	custom := map[string]string{}
+	if len(emoji.Unknown(rendered)) == 0 {
+		return emoji.Replace(rendered, custom)
+	}
+	for _, s := range ss.FetchAll() {
+		custom[s.Name] = s.Value
+	}
+
+	return emoji.Replace(rendered, custom)
+}
+
+// Save Save a custom shortcode, refusing names that cannot be written between colons, are built in or already used
+func (ss *Shortcodes) Save(s Shortcode) (Shortcode, error) {
+	s.Name = strings.Trim(strings.TrimSpace(s.Name), ":")
+	s.Value = strings.TrimSpace(s.Value)
+	if !emoji.IsValidName(s.Name) {
+		return s, errors.New("Shortcode name must use lower case letters, numbers, dashes and underscores and not be built in")
+	}
+	if s.Value == "" {
+		return s, errors.New("Shortcode must have something to show")
+	}
+	if existing := ss.FindByName(s.Name); existing.ID > 0 && existing.ID != s.ID {
+		return s, errors.New("Shortcode name is already in use")
+	}
+
+	if s.ID == 0 {
+		res, err := ss.Container.Db.Exec("INSERT INTO `"+shortcodeTable+"` (`name`, `value`) VALUES(?,?)", s.Name, s.Value)
+		if err != nil {
+			return s, err
+		}
+		id, _ := res.LastInsertId()
+		s.ID = int(id)
+
+		return s, nil
+	}
+
+	_, err := ss.Container.Db.Exec("UPDATE `"+shortcodeTable+"` SET `name` = ?, `value` = ? WHERE `id` = ?", s.Name, s.Value, strconv.Itoa(s.ID))
+
+	return s, err
+}
+
+func (ss Shortcodes) loadFromRows(rows rows.Rows) []Shortcode {
+	defer rows.Close()
+	shortcodes := []Shortcode{}
+	for rows.Next() {
+		s := Shortcode{}
+		rows.Scan(&s.ID, &s.Name, &s.Value)
+		shortcodes = append(shortcodes, s)
+	}
+
+	return shortcodes
+}
+
+func (ss *Shortcodes) loadSingle(rows rows.Rows, err error) Shortcode {
+	if err != nil {
+		return Shortcode{}
+	}
+	shortcodes := ss.loadFromRows(rows)
+
+	if len(shortcodes) == 1 {
+		return shortcodes[0]
+	}
+
+	return Shortcode{}
+}
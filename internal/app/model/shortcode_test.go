@@ -0,0 +1,112 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestShortcodes_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+	ss.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestShortcodes_Delete(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Result = &database.MockResult{}
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+	db.ExpectedArgument = "1"
+	if err := ss.Delete(Shortcode{ID: 1}); err != nil || db.Queries != 1 {
+		t.Error("Expected shortcode to be deleted")
+	}
+}
+
+func TestShortcodes_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+	if len(ss.FetchAll()) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockShortcode_MultipleRows{}
+	shortcodes := ss.FetchAll()
+	if len(shortcodes) != 2 || shortcodes[0].Name != "company" || shortcodes[1].Value != "🐿️" {
+		t.Errorf("Expected 2 shortcodes returned, got %v", shortcodes)
+	}
+}
+
+func TestShortcodes_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if ss.FindByID(1).ID > 0 {
+		t.Error("Expected empty result set returned")
+	}
+
+	db.ExpectedArgument = "1"
+	db.Rows = &database.MockShortcode_SingleRow{}
+	if ss.FindByID(1).Name != "shipit" {
+		t.Error("Expected shortcode to be found")
+	}
+}
+
+func TestShortcodes_Replace(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+
+	// Test custom shortcodes are only looked up when used
+	if actual := ss.Replace("<p>Just :tada:</p>"); actual != "<p>Just 🎉</p>" || db.Queries != 0 {
+		t.Errorf("Expected built-in shortcode to be replaced without a query, got %s", actual)
+	}
+	db.Rows = &database.MockShortcode_MultipleRows{}
+	if actual := ss.Replace("<p>:shipit: for :company: :missing:</p>"); actual != "<p>🐿️ for Acme &amp; Sons :missing:</p>" || db.Queries != 1 {
+		t.Errorf("Expected custom shortcodes to be replaced, got %s", actual)
+	}
+}
+
+func TestShortcodes_Save(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.Result = &database.MockResult{}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	ss := Shortcodes{Container: container}
+
+	tables := []Shortcode{
+		{Name: "Not Valid", Value: "x"},
+		{Name: "smile", Value: "x"},
+		{Name: "shipit", Value: " "},
+	}
+	for _, table := range tables {
+		if _, err := ss.Save(table); err == nil {
+			t.Errorf("Expected %v to be refused", table)
+		}
+	}
+
+	db.Queries = 0
+	s, err := ss.Save(Shortcode{Name: ":shipit:", Value: " 🐿️ "})
+	if err != nil || s.Name != "shipit" || s.Value != "🐿️" || db.Queries != 2 {
+		t.Error("Expected new shortcode to be inserted with colons and spaces trimmed")
+	}
+
+	// Test names already used by another shortcode are refused, but a shortcode keeps its own
+	db.Rows = &database.MockShortcode_SingleRow{}
+	if _, err := ss.Save(Shortcode{Name: "shipit", Value: "Ship"}); err == nil {
+		t.Error("Expected name in use to be refused")
+	}
+	db.Rows = &database.MockShortcode_SingleRow{}
+	if _, err := ss.Save(Shortcode{ID: 1, Name: "shipit", Value: "Ship"}); err != nil {
+		t.Error("Expected existing shortcode to be updated")
+	}
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+// wordCountSQL Takes the words counted in an entry when it was saved, which still holds when its content is sealed, or
+// approximates them by counting the spaces between them for entries that have not been counted
+const wordCountSQL = "(CASE WHEN `word_count` > 0 THEN `word_count` ELSE LENGTH(TRIM(`content`)) - LENGTH(REPLACE(TRIM(`content`), ' ', '')) + 1 END)"
+
+// statsTags How many of the busiest tags are reported
+const statsTags = 10
+
+// Stats Summary of the writing held within the journal
+type Stats struct {
+	TotalEntries  int          `json:"total_entries"`
+	TotalWords    int          `json:"total_words"`
+	AverageWords  int          `json:"average_words"`
+	LongestStreak int          `json:"longest_streak"`
+	Years         []StatsCount `json:"years"`
+	Months        []StatsCount `json:"months"`
+	Weekdays      []StatsCount `json:"weekdays"`
+	Hours         []StatsCount `json:"hours"`
+	Tags          []StatsCount `json:"tags"`
+}
+
+// StatsCount Number of entries and words within one bucket of a report
+type StatsCount struct {
+	Label   string `json:"label"`
+	Entries int    `json:"entries"`
+	Words   int    `json:"words"`
+	Slug    string `json:"slug,omitempty"`
+	Percent int    `json:"-"`
+}
+
+// Statistics Common database resource link for reporting on journals
+type Statistics struct {
+	Container *app.Container
+}
+
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// Fetch Build the full set of statistics
+func (ss *Statistics) Fetch() Stats {
+	s := Stats{
+		Years:    []StatsCount{},
+		Months:   []StatsCount{},
+		Weekdays: []StatsCount{},
+		Hours:    []StatsCount{},
+		Tags:     []StatsCount{},
+	}
+
+	rows, err := ss.Container.Db.Query("SELECT COUNT(*), COALESCE(SUM(" + wordCountSQL + "), 0) FROM `" + journalTable + "` WHERE " + journalNotDeleted)
+	if err != nil {
+		return s
+	}
+	if rows.Next() {
+		rows.Scan(&s.TotalEntries, &s.TotalWords)
+	}
+	rows.Close()
+	if s.TotalEntries > 0 {
+		s.AverageWords = s.TotalWords / s.TotalEntries
+	}
+
+	s.Years = ss.group("SUBSTR(`date`, 1, 4)", "")
+	s.Months = ss.group("SUBSTR(`date`, 1, 7)", "")
+	s.Weekdays = ss.group(ss.weekday("SUBSTR(`date`, 1, 10)"), "")
+	for i, w := range s.Weekdays {
+		if day, err := strconv.Atoi(w.Label); err == nil && day < len(weekdays) {
+			s.Weekdays[i].Label = weekdays[day]
+		}
+	}
+	s.Hours = ss.group("SUBSTR(`date`, 12, 2)", " AND LENGTH(`date`) > 10")
+	s.LongestStreak = ss.longestStreak()
+	s.Tags = ss.busiestTags()
+
+	return s
+}
+
+// weekday Number the day of the week a date falls on from 0 for Sunday, in the dialect of the database
+func (ss *Statistics) weekday(date string) string {
+	switch database.DialectOf(ss.Container.Db) {
+	case database.DialectMySQL:
+		return "DAYOFWEEK(" + date + ") - 1"
+	case database.DialectPostgres:
+		return "CAST(EXTRACT(DOW FROM CAST(" + date + " AS DATE)) AS INTEGER)"
+	}
+
+	return "STRFTIME('%w', " + date + ")"
+}
+
+func (ss *Statistics) group(bucket string, condition string) []StatsCount {
+	rows, err := ss.Container.Db.Query("SELECT " + bucket + " AS `bucket`, COUNT(*), SUM(" + wordCountSQL + ") FROM `" + journalTable + "` WHERE " + journalNotDeleted + condition + " GROUP BY `bucket` ORDER BY `bucket`")
+	if err != nil {
+		return []StatsCount{}
+	}
+
+	return ss.loadCounts(rows)
+}
+
+// busiestTags Count the entries and words under each of the tags given most often, busiest first
+func (ss *Statistics) busiestTags() []StatsCount {
+	rows, err := ss.Container.Db.Query("SELECT MIN(`t`.`name`), COUNT(*), SUM("+wordCountSQL+"), `t`.`slug` FROM `"+journalTagTable+"` AS `t` "+
+		"INNER JOIN `"+journalTable+"` ON `"+journalTable+"`.`id` = `t`.`journal_id` WHERE "+journalNotDeleted+
+		" GROUP BY `t`.`slug` ORDER BY COUNT(*) DESC, `t`.`slug` LIMIT ?", statsTags)
+	if err != nil {
+		return []StatsCount{}
+	}
+	defer rows.Close()
+	counts := []StatsCount{}
+	for rows.Next() {
+		c := StatsCount{}
+		rows.Scan(&c.Label, &c.Entries, &c.Words, &c.Slug)
+		counts = append(counts, c)
+	}
+
+	return scaleCounts(counts)
+}
+
+// longestStreak Count the most consecutive days with at least one entry
+func (ss *Statistics) longestStreak() int {
+	rows, err := ss.Container.Db.Query("SELECT DISTINCT SUBSTR(`date`, 1, 10) AS `day` FROM `" + journalTable + "` WHERE " + journalNotDeleted + " ORDER BY `day`")
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+
+	longest, current := 0, 0
+	var previous time.Time
+	for rows.Next() {
+		var day string
+		rows.Scan(&day)
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		if !previous.IsZero() && date.Sub(previous) == 24*time.Hour {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+		previous = date
+	}
+
+	return longest
+}
+
+func (ss Statistics) loadCounts(rows rows.Rows) []StatsCount {
+	defer rows.Close()
+	counts := []StatsCount{}
+	for rows.Next() {
+		c := StatsCount{}
+		rows.Scan(&c.Label, &c.Entries, &c.Words)
+		counts = append(counts, c)
+	}
+
+	return scaleCounts(counts)
+}
+
+// scaleCounts Scale each bucket against the busiest so they can be drawn as bars
+func scaleCounts(counts []StatsCount) []StatsCount {
+	max := 0
+	for _, c := range counts {
+		if c.Entries > max {
+			max = c.Entries
+		}
+	}
+	for i := range counts {
+		counts[i].Percent = counts[i].Entries * 100 / max
+	}
+
+	return counts
+}
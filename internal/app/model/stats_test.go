@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestStatistics_Fetch(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ss := Statistics{Container: container}
+	s := ss.Fetch()
+	if s.TotalEntries != 0 || len(s.Months) != 0 {
+		t.Error("Expected empty statistics when error received")
+	}
+
+	db.ErrorMode = false
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockStatsTotals_Row{})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018", "2019"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"2018-01", "2018-02", "2019-05"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"0", "3"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"09"}})
+	db.AppendResult(&database.MockStatsDays_Rows{Days: []string{"2018-01-30", "2018-01-31", "2018-02-01", "2018-02-03", "2018-02-04", "bad"}})
+	db.AppendResult(&database.MockStatsCount_Rows{Labels: []string{"Road Trip", "Travel"}})
+	s = ss.Fetch()
+
+	if s.TotalEntries != 4 || s.TotalWords != 1000 || s.AverageWords != 250 {
+		t.Error("Expected totals and average to be calculated")
+	}
+	if len(s.Years) != 2 || s.Years[1].Label != "2019" || s.Years[1].Entries != 2 || s.Years[0].Percent != 50 || s.Years[1].Percent != 100 {
+		t.Error("Expected entries per year to be scaled against the busiest")
+	}
+	if len(s.Months) != 3 || s.Months[2].Words != 300 {
+		t.Error("Expected entries per month to be returned")
+	}
+	if len(s.Weekdays) != 2 || s.Weekdays[0].Label != "Sunday" || s.Weekdays[1].Label != "Wednesday" {
+		t.Error("Expected weekdays to be named")
+	}
+	if len(s.Hours) != 1 || s.Hours[0].Label != "09" {
+		t.Error("Expected hours to be returned")
+	}
+	if s.LongestStreak != 3 {
+		t.Errorf("Expected longest streak of 3 days, got %d", s.LongestStreak)
+	}
+	if len(s.Tags) != 2 || s.Tags[0].Label != "Road Trip" || s.Tags[0].Slug != "road-trip" || s.Tags[1].Entries != 2 || s.Tags[0].Percent != 50 {
+		t.Error("Expected busiest tags to be returned")
+	}
+}
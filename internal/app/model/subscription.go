@@ -0,0 +1,216 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const subscriptionTable = "subscription"
+
+const subscriptionColumns = "`id`, `url`, `title`, `site_url`, `last_fetched_at`, `last_error`, `created_at`"
+
+// Subscription model, a feed from elsewhere followed on the reading page and listed in the blogroll
+type Subscription struct {
+	ID            int    `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	SiteURL       string `json:"site_url"`
+	LastFetchedAt string `json:"last_fetched_at"`
+	LastError     string `json:"last_error"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// Name The name to show for the subscription, its title or else the address of its feed
+func (s Subscription) Name() string {
+	if s.Title != "" {
+		return s.Title
+	}
+
+	return s.URL
+}
+
+// Link The address to link to for the subscription, its site or else its feed
+func (s Subscription) Link() string {
+	if s.SiteURL != "" {
+		return s.SiteURL
+	}
+
+	return s.URL
+}
+
+// Subscriptions Common database resource link for Subscription actions
+type Subscriptions struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ss *Subscriptions) CreateTable() error {
+	_, err := ss.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + subscriptionTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`url` VARCHAR(255) NOT NULL UNIQUE, " +
+		"`title` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`site_url` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`last_fetched_at` DATETIME NOT NULL DEFAULT '', " +
+		"`last_error` TEXT NOT NULL DEFAULT '', " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Stop following a feed, removing the items fetched from it
+func (ss *Subscriptions) Delete(s Subscription) error {
+	if _, err := ss.Container.Db.Exec("DELETE FROM `"+subscriptionItemTable+"` WHERE `subscription_id` = ?", strconv.Itoa(s.ID)); err != nil {
+		return err
+	}
+	_, err := ss.Container.Db.Exec("DELETE FROM `"+subscriptionTable+"` WHERE `id` = ?", strconv.Itoa(s.ID))
+
+	return err
+}
+
+// FetchAll Get every subscription, ordered by title
+func (ss *Subscriptions) FetchAll() []Subscription {
+	rows, err := ss.Container.Db.Query("SELECT " + subscriptionColumns + " FROM `" + subscriptionTable + "` ORDER BY LOWER(`title`), `id`")
+	if err != nil {
+		return []Subscription{}
+	}
+
+	return ss.loadFromRows(rows)
+}
+
+// FindByID Find a subscription by its ID
+func (ss *Subscriptions) FindByID(id int) Subscription {
+	return ss.loadSingle(ss.Container.Db.Query("SELECT "+subscriptionColumns+" FROM `"+subscriptionTable+"` WHERE `id` = ? LIMIT 1", strconv.Itoa(id)))
+}
+
+// FindByURL Find a subscription by the address of its feed
+func (ss *Subscriptions) FindByURL(url string) Subscription {
+	return ss.loadSingle(ss.Container.Db.Query("SELECT "+subscriptionColumns+" FROM `"+subscriptionTable+"` WHERE `url` = ? LIMIT 1", url))
+}
+
+// Save Store a new subscription, or the details last fetched for an existing one
+func (ss *Subscriptions) Save(s Subscription) (Subscription, error) {
+	if s.ID > 0 {
+		_, err := ss.Container.Db.Exec("UPDATE `"+subscriptionTable+"` SET `title` = ?, `site_url` = ?, `last_fetched_at` = ?, `last_error` = ? WHERE `id` = ?", s.Title, s.SiteURL, s.LastFetchedAt, s.LastError, strconv.Itoa(s.ID))
+		return s, err
+	}
+
+	s.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := ss.Container.Db.Exec("INSERT INTO `"+subscriptionTable+"` (`url`, `title`, `site_url`, `last_fetched_at`, `last_error`, `created_at`) VALUES(?,?,?,?,?,?)", s.URL, s.Title, s.SiteURL, s.LastFetchedAt, s.LastError, s.CreatedAt)
+	if err != nil {
+		return s, err
+	}
+	id, _ := res.LastInsertId()
+	s.ID = int(id)
+
+	return s, nil
+}
+
+func (ss Subscriptions) loadSingle(rows rows.Rows, err error) Subscription {
+	if err != nil {
+		return Subscription{}
+	}
+	subscriptions := ss.loadFromRows(rows)
+	if len(subscriptions) == 1 {
+		return subscriptions[0]
+	}
+
+	return Subscription{}
+}
+
+func (ss Subscriptions) loadFromRows(rows rows.Rows) []Subscription {
+	defer rows.Close()
+	subscriptions := []Subscription{}
+	for rows.Next() {
+		s := Subscription{}
+		rows.Scan(&s.ID, &s.URL, &s.Title, &s.SiteURL, &s.LastFetchedAt, &s.LastError, &s.CreatedAt)
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions
+}
+
+const subscriptionItemTable = "subscription_item"
+
+// SubscriptionItem model, a post fetched from a followed feed, along with the name and address of where it came from
+type SubscriptionItem struct {
+	ID             int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	Title          string `json:"title"`
+	URL            string `json:"url"`
+	Summary        string `json:"summary"`
+	PublishedAt    string `json:"published_at"`
+	Source         string `json:"source"`
+	SourceURL      string `json:"source_url"`
+}
+
+// GetTime Get the time the item was published
+func (i SubscriptionItem) GetTime() time.Time {
+	t, _ := time.Parse(jobTimeFormat, i.PublishedAt)
+	return t
+}
+
+// SubscriptionItems Common database resource link for SubscriptionItem actions
+type SubscriptionItems struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (is *SubscriptionItems) CreateTable() error {
+	_, err := is.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + subscriptionItemTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`subscription_id` INTEGER NOT NULL, " +
+		"`title` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`url` VARCHAR(255) NOT NULL, " +
+		"`summary` TEXT NOT NULL DEFAULT '', " +
+		"`published_at` DATETIME NOT NULL, " +
+		"UNIQUE (`subscription_id`, `url`)" +
+		")")
+
+	return err
+}
+
+// Add Store an item fetched from a feed, returning whether it was new
+func (is *SubscriptionItems) Add(i SubscriptionItem) (bool, error) {
+	res, err := is.Container.Db.Exec("INSERT INTO `"+subscriptionItemTable+"` (`subscription_id`, `title`, `url`, `summary`, `published_at`) VALUES(?,?,?,?,?) ON CONFLICT DO NOTHING", strconv.Itoa(i.SubscriptionID), i.Title, i.URL, i.Summary, i.PublishedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := res.RowsAffected()
+
+	return affected > 0, nil
+}
+
+// FetchLatest Get the most recently published items from every followed feed
+func (is *SubscriptionItems) FetchLatest(limit int) []SubscriptionItem {
+	rows, err := is.Container.Db.Query("SELECT i.`id`, i.`subscription_id`, i.`title`, i.`url`, i.`summary`, i.`published_at`, s.`title`, s.`site_url`, s.`url` "+
+		"FROM `"+subscriptionItemTable+"` AS i INNER JOIN `"+subscriptionTable+"` AS s ON s.`id` = i.`subscription_id` "+
+		"ORDER BY i.`published_at` DESC, i.`id` DESC LIMIT ?", limit)
+	if err != nil {
+		return []SubscriptionItem{}
+	}
+	defer rows.Close()
+	items := []SubscriptionItem{}
+	for rows.Next() {
+		i := SubscriptionItem{}
+		s := Subscription{}
+		rows.Scan(&i.ID, &i.SubscriptionID, &i.Title, &i.URL, &i.Summary, &i.PublishedAt, &s.Title, &s.SiteURL, &s.URL)
+		i.Source = s.Name()
+		i.SourceURL = s.Link()
+		items = append(items, i)
+	}
+
+	return items
+}
+
+// Prune Remove all but the newest items from a feed, so that following it does not grow the database forever
+func (is *SubscriptionItems) Prune(subscriptionID int, keep int) error {
+	_, err := is.Container.Db.Exec("DELETE FROM `"+subscriptionItemTable+"` WHERE `subscription_id` = ? AND `id` NOT IN ("+
+		"SELECT `id` FROM `"+subscriptionItemTable+"` WHERE `subscription_id` = ? ORDER BY `published_at` DESC, `id` DESC LIMIT ?)",
+		strconv.Itoa(subscriptionID), strconv.Itoa(subscriptionID), keep)
+
+	return err
+}
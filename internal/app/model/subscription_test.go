@@ -0,0 +1,142 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestSubscription_NameAndLink(t *testing.T) {
+	s := Subscription{URL: "https://alice.example.com/feed"}
+	if s.Name() != s.URL || s.Link() != s.URL {
+		t.Error("Expected feed address to be used before the feed is fetched")
+	}
+	s.Title = "Alice's Blog"
+	s.SiteURL = "https://alice.example.com/"
+	if s.Name() != "Alice's Blog" || s.Link() != "https://alice.example.com/" {
+		t.Error("Expected title and site to be used once known")
+	}
+}
+
+func TestSubscriptions_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Subscriptions{Container: container}
+	ss.CreateTable()
+	is := SubscriptionItems{Container: container}
+	is.CreateTable()
+	if db.Queries != 2 {
+		t.Errorf("Expected 2 queries to have been run")
+	}
+}
+
+func TestSubscriptions_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Subscriptions{Container: container}
+
+	db.Rows = &database.MockSubscription_MultipleRows{}
+	subscriptions := ss.FetchAll()
+	if len(subscriptions) != 2 || subscriptions[0].Name() != "Alice's Blog" || subscriptions[1].Name() != "https://bob.example.com/atom.xml" {
+		t.Errorf("Expected subscriptions to be returned, got %v", subscriptions)
+	}
+
+	db.ErrorMode = true
+	if len(ss.FetchAll()) != 0 {
+		t.Error("Expected no subscriptions on error")
+	}
+}
+
+func TestSubscriptions_Find(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ss := Subscriptions{Container: container}
+
+	db.Rows = &database.MockSubscription_SingleRow{}
+	if s := ss.FindByID(1); s.ID != 1 || s.Title != "Alice's Blog" {
+		t.Errorf("Expected subscription to be found, got %v", s)
+	}
+	db.Rows = &database.MockSubscription_SingleRow{URL: "https://bob.example.com/atom.xml"}
+	if s := ss.FindByURL("https://bob.example.com/atom.xml"); s.URL != "https://bob.example.com/atom.xml" {
+		t.Errorf("Expected subscription to be found by its feed, got %v", s)
+	}
+	db.Rows = &database.MockRowsEmpty{}
+	if s := ss.FindByURL("https://carol.example.com/feed"); s.ID != 0 {
+		t.Error("Expected no subscription to be found")
+	}
+	db.ErrorMode = true
+	if s := ss.FindByID(1); s.ID != 0 {
+		t.Error("Expected no subscription on error")
+	}
+}
+
+func TestSubscriptions_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ss := Subscriptions{Container: container}
+
+	s, err := ss.Save(Subscription{URL: "https://alice.example.com/feed"})
+	if err != nil || s.ID != 1 || s.CreatedAt == "" {
+		t.Errorf("Expected subscription to be added, got %v, %v", s, err)
+	}
+	db.ExpectedArgument = "Alice's Blog"
+	s.Title = "Alice's Blog"
+	if _, err := ss.Save(s); err != nil || db.Queries != 2 {
+		t.Errorf("Expected subscription to be updated, got %v", err)
+	}
+
+	db.ErrorMode = true
+	if _, err := ss.Save(Subscription{URL: "https://bob.example.com/atom.xml"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestSubscriptions_Delete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ss := Subscriptions{Container: container}
+	if err := ss.Delete(Subscription{ID: 1}); err != nil || db.Queries != 2 {
+		t.Error("Expected subscription and its items to be deleted")
+	}
+
+	db.ErrorMode = true
+	if err := ss.Delete(Subscription{ID: 1}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestSubscriptionItems(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Db: db}
+	is := SubscriptionItems{Container: container}
+
+	if added, err := is.Add(SubscriptionItem{SubscriptionID: 1, URL: "https://alice.example.com/post", PublishedAt: "2018-02-15 12:00:00"}); !added || err != nil {
+		t.Errorf("Expected item to be added, got %v", err)
+	}
+	db.Result = &database.MockResult{}
+	if added, _ := is.Add(SubscriptionItem{SubscriptionID: 1, URL: "https://alice.example.com/post"}); added {
+		t.Error("Expected item already fetched not to be added again")
+	}
+
+	db.Rows = &database.MockSubscriptionItem_MultipleRows{}
+	items := is.FetchLatest(10)
+	if len(items) != 2 || items[0].Source != "Alice's Blog" || items[0].SourceURL != "https://alice.example.com/" || items[1].Source != "https://bob.example.com/atom.xml" {
+		t.Errorf("Expected items to be returned with where they came from, got %v", items)
+	}
+	if items[0].GetTime().Day() != 15 {
+		t.Error("Expected time the item was published")
+	}
+
+	if err := is.Prune(1, 50); err != nil {
+		t.Errorf("Expected older items to be pruned, got %v", err)
+	}
+
+	db.ErrorMode = true
+	if _, err := is.Add(SubscriptionItem{SubscriptionID: 1}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+	if len(is.FetchLatest(10)) != 0 {
+		t.Error("Expected no items on error")
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const tenantTable = "tenant"
+
+// reservedTenantNames Names no hosted journal may take, being served at by the journal itself or commonly expected
+var reservedTenantNames = reserved(append([]string{"css", "js", "www"}, ReservedPaths...))
+
+// Tenant model, an isolated journal hosted by this instance
+type Tenant struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Tenants Common database resource link for Tenant actions
+type Tenants struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ts *Tenants) CreateTable() error {
+	_, err := ts.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + tenantTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`name` VARCHAR(63) NOT NULL UNIQUE, " +
+		"`title` VARCHAR(255) NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// FetchAll Get all tenants
+func (ts *Tenants) FetchAll() []Tenant {
+	rows, err := ts.Container.Db.Query("SELECT `id`, `name`, `title`, `created_at` FROM `" + tenantTable + "` ORDER BY `name`")
+	if err != nil {
+		return []Tenant{}
+	}
+
+	return ts.loadFromRows(rows)
+}
+
+// FindByName Find a tenant by its name
+func (ts *Tenants) FindByName(name string) Tenant {
+	rows, err := ts.Container.Db.Query("SELECT `id`, `name`, `title`, `created_at` FROM `"+tenantTable+"` WHERE `name` = ? LIMIT 1", name)
+	if err != nil {
+		return Tenant{}
+	}
+	tenants := ts.loadFromRows(rows)
+	if len(tenants) == 1 {
+		return tenants[0]
+	}
+
+	return Tenant{}
+}
+
+// IsAvailable Check the name is valid for a subdomain or path and not already taken, by another journal or an entry
+func (ts *Tenants) IsAvailable(name string) bool {
+	if !regexp.MustCompile("^[a-z0-9][a-z0-9\\-]{1,62}$").MatchString(name) {
+		return false
+	}
+	if reservedTenantNames[name] {
+		return false
+	}
+
+	// Beneath a path prefix the name takes the address of any entry of the journal itself with the same slug
+	if ts.Container.Configuration.TenantMode == app.TenantModePath {
+		js := Journals{Container: ts.Container}
+		return !js.SlugTaken(name, 0)
+	}
+
+	return ts.FindByName(name).ID == 0
+}
+
+// Delete Remove a tenant, such as one whose journal could not be given its admin
+func (ts *Tenants) Delete(t Tenant) error {
+	_, err := ts.Container.Db.Exec("DELETE FROM `"+tenantTable+"` WHERE `id` = ?", strconv.Itoa(t.ID))
+
+	return err
+}
+
+// Save Store a new tenant
+func (ts *Tenants) Save(t Tenant) (Tenant, error) {
+	t.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := ts.Container.Db.Exec("INSERT INTO `"+tenantTable+"` (`name`, `title`, `created_at`) VALUES(?,?,?)", t.Name, t.Title, t.CreatedAt)
+	if err != nil {
+		return Tenant{}, err
+	}
+	id, _ := res.LastInsertId()
+	t.ID = int(id)
+
+	return t, nil
+}
+
+func (ts Tenants) loadFromRows(rows rows.Rows) []Tenant {
+	defer rows.Close()
+	tenants := []Tenant{}
+	for rows.Next() {
+		t := Tenant{}
+		rows.Scan(&t.ID, &t.Name, &t.Title, &t.CreatedAt)
+		tenants = append(tenants, t)
+	}
+
+	return tenants
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestTenants_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := Tenants{Container: container}
+	ts.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestTenants_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ts := Tenants{Container: container}
+	if len(ts.FetchAll()) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockTenant_SingleRow{}
+	tenants := ts.FetchAll()
+	if len(tenants) != 1 || tenants[0].Name != "alice" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestTenants_FindByName(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ts := Tenants{Container: container}
+	if ts.FindByName("alice").ID > 0 {
+		t.Error("Expected empty result returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	if ts.FindByName("alice").ID > 0 {
+		t.Error("Expected empty result returned")
+	}
+
+	db.Rows = &database.MockTenant_SingleRow{}
+	db.ExpectedArgument = "alice"
+	tenant := ts.FindByName("alice")
+	if tenant.ID != 1 || tenant.Title != "Alice's Journal" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestTenants_IsAvailable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := Tenants{Container: container}
+	tables := []struct {
+		input  string
+		rows   bool
+		output bool
+	}{
+		{"alice", false, true},
+		{"alice", true, false},
+		{"Alice", false, false},
+		{"a", false, false},
+		{"-alice", false, false},
+		{"with spaces", false, false},
+		{"admin", false, false},
+		{"api", false, false},
+		{"login", false, false},
+		{"setup", false, false},
+		{"graphql", false, false},
+		{"www", false, false},
+	}
+
+	for _, table := range tables {
+		db.Rows = &database.MockRowsEmpty{}
+		if table.rows {
+			db.Rows = &database.MockTenant_SingleRow{}
+		}
+		actual := ts.IsAvailable(table.input)
+		if actual != table.output {
+			t.Errorf("Expected IsAvailable(%s) to produce result of '%t', got '%t'", table.input, table.output, actual)
+		}
+	}
+
+	// Test names beneath a path prefix are not available when an entry of the journal itself has the slug
+	container.Configuration.TenantMode = app.TenantModePath
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_SingleRow{})
+	if ts.IsAvailable("slug") {
+		t.Error("Expected name of an entry's slug not to be available beneath a path prefix")
+	}
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	if !ts.IsAvailable("alice") {
+		t.Error("Expected name no entry or journal has to be available beneath a path prefix")
+	}
+}
+
+func TestTenants_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := Tenants{Container: container}
+	tenant, err := ts.Save(Tenant{Name: "alice", Title: "Alice's Journal"})
+	if err != nil || tenant.ID != 1 || tenant.CreatedAt == "" {
+		t.Error("Expected tenant to have been saved with new ID")
+	}
+
+	db.ErrorMode = true
+	if _, err = ts.Save(Tenant{Name: "bob"}); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
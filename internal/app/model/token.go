@@ -0,0 +1,144 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const tokenTable = "api_token"
+
+// Token scopes
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// Token model, a personal access token for the API
+type Token struct {
+	ID         int    `json:"id"`
+	UserID     int    `json:"user_id"`
+	Name       string `json:"name"`
+	Hash       string `json:"-"`
+	Scopes     string `json:"scopes"`
+	LastUsedAt string `json:"last_used_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// HasScope Check whether the token was issued with a given scope
+func (t Token) HasScope(scope string) bool {
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HashToken Get the stored form of a plain token
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// Tokens Common database resource link for Token actions
+type Tokens struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ts *Tokens) CreateTable() error {
+	_, err := ts.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + tokenTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`user_id` INTEGER NOT NULL, " +
+		"`name` VARCHAR(255) NOT NULL, " +
+		"`hash` CHAR(64) NOT NULL UNIQUE, " +
+		"`scopes` VARCHAR(255) NOT NULL, " +
+		"`last_used_at` VARCHAR(20) NOT NULL DEFAULT '', " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Authenticate Find the token matching a plain value and record its use
+func (ts *Tokens) Authenticate(plain string) Token {
+	if plain == "" {
+		return Token{}
+	}
+	t := ts.loadSingle(ts.Container.Db.Query("SELECT "+tokenColumns+" FROM `"+tokenTable+"` WHERE `hash` = ? LIMIT 1", HashToken(plain)))
+	if t.ID > 0 {
+		t.LastUsedAt = time.Now().UTC().Format(jobTimeFormat)
+		ts.Container.Db.Exec("UPDATE `"+tokenTable+"` SET `last_used_at` = ? WHERE `id` = ?", t.LastUsedAt, strconv.Itoa(t.ID))
+	}
+
+	return t
+}
+
+// FetchByUser Get all tokens issued to a user
+func (ts *Tokens) FetchByUser(userID int) []Token {
+	rows, err := ts.Container.Db.Query("SELECT "+tokenColumns+" FROM `"+tokenTable+"` WHERE `user_id` = ? ORDER BY `id`", strconv.Itoa(userID))
+	if err != nil {
+		return []Token{}
+	}
+
+	return ts.loadFromRows(rows)
+}
+
+// Issue Create a new token for a user, returning the plain value which is never stored
+func (ts *Tokens) Issue(userID int, name string, scopes []string) (Token, string, error) {
+	random := make([]byte, 20)
+	if _, err := rand.Read(random); err != nil {
+		return Token{}, "", err
+	}
+	plain := "jt_" + hex.EncodeToString(random)
+
+	t := Token{UserID: userID, Name: name, Hash: HashToken(plain), Scopes: strings.Join(scopes, ","), CreatedAt: time.Now().UTC().Format(jobTimeFormat)}
+	res, err := ts.Container.Db.Exec("INSERT INTO `"+tokenTable+"` (`user_id`, `name`, `hash`, `scopes`, `last_used_at`, `created_at`) VALUES(?,?,?,?,'',?)", strconv.Itoa(t.UserID), t.Name, t.Hash, t.Scopes, t.CreatedAt)
+	if err != nil {
+		return Token{}, "", err
+	}
+	id, _ := res.LastInsertId()
+	t.ID = int(id)
+
+	return t, plain, nil
+}
+
+// Revoke Remove a token belonging to a user
+func (ts *Tokens) Revoke(userID int, id int) error {
+	_, err := ts.Container.Db.Exec("DELETE FROM `"+tokenTable+"` WHERE `id` = ? AND `user_id` = ?", strconv.Itoa(id), strconv.Itoa(userID))
+	return err
+}
+
+const tokenColumns = "`id`, `user_id`, `name`, `hash`, `scopes`, `last_used_at`, `created_at`"
+
+func (ts Tokens) loadFromRows(rows rows.Rows) []Token {
+	defer rows.Close()
+	tokens := []Token{}
+	for rows.Next() {
+		t := Token{}
+		rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Hash, &t.Scopes, &t.LastUsedAt, &t.CreatedAt)
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
+
+func (ts *Tokens) loadSingle(rows rows.Rows, err error) Token {
+	if err != nil {
+		return Token{}
+	}
+	tokens := ts.loadFromRows(rows)
+
+	if len(tokens) == 1 {
+		return tokens[0]
+	}
+
+	return Token{}
+}
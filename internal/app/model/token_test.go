@@ -0,0 +1,108 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestToken_HasScope(t *testing.T) {
+	token := Token{Scopes: "read,write"}
+	if !token.HasScope(ScopeRead) || !token.HasScope(ScopeWrite) {
+		t.Error("Expected both scopes to be present")
+	}
+	token.Scopes = "read"
+	if token.HasScope(ScopeWrite) || token.HasScope("rea") {
+		t.Error("Expected only the read scope to be present")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("example")
+	if len(hash) != 64 || hash == "example" || hash != HashToken("example") || hash == HashToken("other") {
+		t.Error("Expected a stable SHA-256 hex digest")
+	}
+}
+
+func TestTokens_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ts := Tokens{Container: container}
+	ts.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestTokens_Authenticate(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := Tokens{Container: container}
+
+	// Test empty token never queries
+	if ts.Authenticate("").ID > 0 || db.Queries > 0 {
+		t.Error("Expected empty token to have been rejected")
+	}
+
+	// Test unknown token
+	db.Rows = &database.MockRowsEmpty{}
+	if ts.Authenticate("jt_unknown").ID > 0 {
+		t.Error("Expected unknown token to have been rejected")
+	}
+
+	// Test known token is looked up by hash and marked as used
+	db.Rows = &database.MockToken_SingleRow{}
+	db.ExpectedArgument = HashToken("jt_known")
+	db.Queries = 0
+	token := ts.Authenticate("jt_known")
+	if token.ID != 1 || token.LastUsedAt == "" || db.Queries != 2 {
+		t.Error("Expected token to have been found and its use recorded")
+	}
+}
+
+func TestTokens_FetchByUser(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	ts := Tokens{Container: container}
+	if len(ts.FetchByUser(1)) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockToken_SingleRow{}
+	tokens := ts.FetchByUser(1)
+	if len(tokens) != 1 || tokens[0].Name != "Script" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestTokens_Issue(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := Tokens{Container: container}
+	token, plain, err := ts.Issue(1, "Script", []string{ScopeRead, ScopeWrite})
+	if err != nil || token.ID != 1 || token.Scopes != "read,write" {
+		t.Error("Expected token to have been issued")
+	}
+	if !strings.HasPrefix(plain, "jt_") || token.Hash != HashToken(plain) {
+		t.Error("Expected plain token to be returned and only its hash stored")
+	}
+
+	db.ErrorMode = true
+	if _, _, err = ts.Issue(1, "Script", []string{ScopeRead}); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
+
+func TestTokens_Revoke(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ts := Tokens{Container: container}
+	db.ExpectedArgument = "3"
+	if err := ts.Revoke(1, 3); err != nil {
+		t.Error("Expected token to have been revoked")
+	}
+}
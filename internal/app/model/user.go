@@ -0,0 +1,221 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const userTable = "user"
+
+// User roles
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleReader = "reader"
+)
+
+// Roles lists every role a user may hold, from the one allowed most to the one allowed least
+var Roles = []string{RoleAdmin, RoleEditor, RoleReader}
+
+// User model
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// CheckPassword Compare a plain password against the stored hash
+func (u User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// SetPassword Hash and store a new password
+func (u *User) SetPassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("Password must be at least 8 characters")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+
+	return nil
+}
+
+// HasRole Check whether the user holds a role or one allowed more than it, as admins may do anything editors may, who
+// may do anything readers may
+func (u User) HasRole(role string) bool {
+	for _, r := range Roles {
+		if r == u.Role {
+			return true
+		}
+		if r == role {
+			return false
+		}
+	}
+	return false
+}
+
+// IsValidRole Check a role is one that is known
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Users Common database resource link for User actions
+type Users struct {
+	Container *app.Container
+}
+
+// CountAdmins Count the users who are admins, of whom there must always be one left to manage the others
+func (us *Users) CountAdmins() int {
+	total := 0
+	rows, err := us.Container.Db.Query("SELECT COUNT(*) FROM `"+userTable+"` WHERE `role` = ?", RoleAdmin)
+	if err != nil {
+		return total
+	}
+	defer rows.Close()
+	if rows.Next() {
+		rows.Scan(&total)
+	}
+
+	return total
+}
+
+// CreateTable Create the actual table
+func (us *Users) CreateTable() error {
+	_, err := us.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + userTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`username` VARCHAR(255) NOT NULL UNIQUE, " +
+		"`email` VARCHAR(255) NOT NULL, " +
+		"`password_hash` VARCHAR(255) NOT NULL, " +
+		"`role` VARCHAR(20) NOT NULL, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Remove a user along with their API tokens, sessions and the accounts they sign in with
+func (us *Users) Delete(u User) error {
+	if _, err := us.Container.Db.Exec("DELETE FROM `"+tokenTable+"` WHERE `user_id` = ?", strconv.Itoa(u.ID)); err != nil {
+		return err
+	}
+	ss := Sessions{Container: us.Container}
+	if err := ss.DeleteByUser(u.ID); err != nil {
+		return err
+	}
+	is := Identities{Container: us.Container}
+	if err := is.DeleteByUser(u.ID); err != nil {
+		return err
+	}
+	_, err := us.Container.Db.Exec("DELETE FROM `"+userTable+"` WHERE `id` = ?", strconv.Itoa(u.ID))
+
+	return err
+}
+
+// FetchAll Get all users
+func (us *Users) FetchAll() []User {
+	rows, err := us.Container.Db.Query("SELECT " + userColumns + " FROM `" + userTable + "` ORDER BY `username`")
+	if err != nil {
+		return []User{}
+	}
+
+	return us.loadFromRows(rows)
+}
+
+// FindByEmail Find the only user with an email address, ignoring case, and nobody when it is shared
+func (us *Users) FindByEmail(email string) User {
+	if email == "" {
+		return User{}
+	}
+
+	return us.loadSingle(us.Container.Db.Query("SELECT "+userColumns+" FROM `"+userTable+"` WHERE LOWER(`email`) = LOWER(?) LIMIT 2", email))
+}
+
+// FindByID Find a user by ID
+func (us *Users) FindByID(id int) User {
+	return us.loadSingle(us.Container.Db.Query("SELECT "+userColumns+" FROM `"+userTable+"` WHERE `id` = ? LIMIT 1", strconv.Itoa(id)))
+}
+
+// FindByUsername Find a user by username
+func (us *Users) FindByUsername(username string) User {
+	return us.loadSingle(us.Container.Db.Query("SELECT "+userColumns+" FROM `"+userTable+"` WHERE `username` = ? LIMIT 1", username))
+}
+
+// Save Save a user, either inserting or updating them in the database
+func (us *Users) Save(u User) (User, error) {
+	if !regexp.MustCompile("^[\\w\\-]{2,64}$").MatchString(u.Username) {
+		return u, errors.New("Username must be 2-64 letters, numbers, hyphens or underscores")
+	}
+	if !IsValidRole(u.Role) {
+		return u, errors.New("Role is not valid")
+	}
+	if u.PasswordHash == "" {
+		return u, errors.New("Password must be set")
+	}
+
+	if u.ID == 0 {
+		if us.FindByUsername(u.Username).ID > 0 {
+			return u, errors.New("Username is already taken")
+		}
+		u.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+		res, err := us.Container.Db.Exec("INSERT INTO `"+userTable+"` (`username`, `email`, `password_hash`, `role`, `created_at`) VALUES(?,?,?,?,?)", u.Username, u.Email, u.PasswordHash, u.Role, u.CreatedAt)
+		if err != nil {
+			return u, err
+		}
+		id, _ := res.LastInsertId()
+		u.ID = int(id)
+	} else {
+		_, err := us.Container.Db.Exec("UPDATE `"+userTable+"` SET `email` = ?, `password_hash` = ?, `role` = ? WHERE `id` = ?", u.Email, u.PasswordHash, u.Role, strconv.Itoa(u.ID))
+		if err != nil {
+			return u, err
+		}
+	}
+
+	return u, nil
+}
+
+const userColumns = "`id`, `username`, `email`, `password_hash`, `role`, `created_at`"
+
+func (us Users) loadFromRows(rows rows.Rows) []User {
+	defer rows.Close()
+	users := []User{}
+	for rows.Next() {
+		u := User{}
+		rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt)
+		users = append(users, u)
+	}
+
+	return users
+}
+
+func (us *Users) loadSingle(rows rows.Rows, err error) User {
+	if err != nil {
+		return User{}
+	}
+	users := us.loadFromRows(rows)
+
+	if len(users) == 1 {
+		return users[0]
+	}
+
+	return User{}
+}
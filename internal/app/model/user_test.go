@@ -0,0 +1,224 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestUser_SetPassword(t *testing.T) {
+	u := User{}
+	if err := u.SetPassword("short"); err == nil || u.PasswordHash != "" {
+		t.Error("Expected short password to have been rejected")
+	}
+	if err := u.SetPassword("password123"); err != nil || u.PasswordHash == "" || u.PasswordHash == "password123" {
+		t.Error("Expected password to have been hashed")
+	}
+}
+
+func TestUser_CheckPassword(t *testing.T) {
+	u := User{}
+	if u.CheckPassword("") {
+		t.Error("Expected user without a password to never match")
+	}
+	u.SetPassword("password123")
+	if !u.CheckPassword("password123") || u.CheckPassword("password124") {
+		t.Error("Expected only the correct password to match")
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	if !IsValidRole(RoleAdmin) || !IsValidRole(RoleEditor) || !IsValidRole(RoleReader) || IsValidRole("owner") {
+		t.Error("Expected only known roles to be valid")
+	}
+}
+
+func TestUsers_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	us.CreateTable()
+	if db.Queries != 1 {
+		t.Errorf("Expected 1 query to have been run")
+	}
+}
+
+func TestUsers_Delete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	if err := us.Delete(User{ID: 1}); err != nil || db.Queries != 4 {
+		t.Error("Expected user, tokens, sessions and identities to have been deleted")
+	}
+
+	db.ErrorMode = true
+	if err := us.Delete(User{ID: 1}); err == nil {
+		t.Error("Expected error when database fails")
+	}
+}
+
+func TestUsers_FetchAll(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	if len(us.FetchAll()) > 0 {
+		t.Error("Expected empty result set returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockUser_MultipleRows{}
+	users := us.FetchAll()
+	if len(users) != 2 || users[1].Role != RoleEditor {
+		t.Error("Expected 2 rows returned and with correct data")
+	}
+}
+
+func TestUsers_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	db.ErrorMode = true
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	if us.FindByID(1).ID > 0 {
+		t.Error("Expected empty result returned when error received")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ExpectedArgument = "1"
+	if user := us.FindByID(1); user.ID != 1 || user.Username != "jamie" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestUsers_FindByEmail(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	if us.FindByEmail("").ID > 0 || db.Queries > 0 {
+		t.Error("Expected empty email to never be looked up")
+	}
+
+	db.Rows = &database.MockUser_MultipleRows{}
+	if us.FindByEmail("shared@example.com").ID > 0 {
+		t.Error("Expected nobody when more than one user has the email")
+	}
+
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ExpectedArgument = "Jamie@Example.com"
+	if user := us.FindByEmail("Jamie@Example.com"); user.ID != 1 {
+		t.Error("Expected user to be found by email")
+	}
+}
+
+func TestUsers_FindByUsername(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	db.Rows = &database.MockRowsEmpty{}
+	if us.FindByUsername("jamie").ID > 0 {
+		t.Error("Expected empty result returned")
+	}
+
+	db.Rows = &database.MockUser_MultipleRows{}
+	if us.FindByUsername("jamie").ID > 0 {
+		t.Error("Expected no rows when query returns more than one result")
+	}
+
+	db.Rows = &database.MockUser_SingleRow{}
+	db.ExpectedArgument = "jamie"
+	if user := us.FindByUsername("jamie"); user.ID != 1 || user.Email != "jamie@example.com" {
+		t.Error("Expected 1 row returned and with correct data")
+	}
+}
+
+func TestUsers_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+
+	// Test validation
+	tables := []User{
+		{Username: "with spaces", Role: RoleAdmin, PasswordHash: "hash"},
+		{Username: "jamie", Role: "owner", PasswordHash: "hash"},
+		{Username: "jamie", Role: RoleAdmin},
+	}
+	for _, table := range tables {
+		if _, err := us.Save(table); err == nil {
+			t.Errorf("Expected validation error saving %+v", table)
+		}
+	}
+
+	// Test new user
+	user, err := us.Save(User{Username: "jamie", Role: RoleAdmin, PasswordHash: "hash"})
+	if err != nil || user.ID != 1 || user.CreatedAt == "" {
+		t.Error("Expected new user to have been saved with new ID")
+	}
+
+	// Test taken username
+	db.Rows = &database.MockUser_SingleRow{}
+	if _, err = us.Save(User{Username: "jamie", Role: RoleAdmin, PasswordHash: "hash"}); err == nil {
+		t.Error("Expected error when username is taken")
+	}
+
+	// Test existing user
+	user, err = us.Save(User{ID: 2, Username: "jamie", Role: RoleEditor, PasswordHash: "hash"})
+	if err != nil || user.ID != 2 {
+		t.Error("Expected existing user to have been updated")
+	}
+
+	// Test database errors
+	db.ErrorMode = true
+	if _, err = us.Save(User{ID: 2, Username: "jamie", Role: RoleEditor, PasswordHash: "hash"}); err == nil {
+		t.Error("Expected error when update fails")
+	}
+	db.ErrorMode = false
+	db.Rows = &database.MockRowsEmpty{}
+	db.ErrorAtQuery = db.Queries + 2
+	if _, err = us.Save(User{Username: "new", Role: RoleEditor, PasswordHash: "hash"}); err == nil {
+		t.Error("Expected error when insert fails")
+	}
+}
+
+func TestUser_HasRole(t *testing.T) {
+	tables := []struct {
+		role     string
+		required string
+		expected bool
+	}{
+		{RoleAdmin, RoleAdmin, true},
+		{RoleAdmin, RoleEditor, true},
+		{RoleAdmin, RoleReader, true},
+		{RoleEditor, RoleAdmin, false},
+		{RoleEditor, RoleEditor, true},
+		{RoleEditor, RoleReader, true},
+		{RoleReader, RoleEditor, false},
+		{RoleReader, RoleReader, true},
+		{"", RoleReader, false},
+		{"owner", RoleReader, false},
+	}
+	for _, table := range tables {
+		if actual := (User{Role: table.role}).HasRole(table.required); actual != table.expected {
+			t.Errorf("Expected HasRole(%s) for %s to be %v", table.required, table.role, table.expected)
+		}
+	}
+}
+
+func TestUsers_CountAdmins(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	us := Users{Container: container}
+	db.ErrorMode = true
+	if us.CountAdmins() != 0 {
+		t.Error("Expected no admins to be counted when database fails")
+	}
+
+	db.ErrorMode = false
+	db.Rows = &database.MockPagination_Result{TotalResults: 2}
+	db.ExpectedArgument = RoleAdmin
+	if us.CountAdmins() != 2 {
+		t.Error("Expected admins to be counted")
+	}
+}
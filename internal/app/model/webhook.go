@@ -0,0 +1,207 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+const webhookTable = "webhook"
+
+const webhookColumns = "`id`, `url`, `secret`, `events`, `created_at`"
+
+// Webhook model, an address told about changes to entries with a signed request
+type Webhook struct {
+	ID        int    `json:"id"`
+	URL       string `json:"url"`
+	Secret    string `json:"-"`
+	Events    string `json:"events"`
+	CreatedAt string `json:"created_at"`
+}
+
+// HasEvent Check whether the webhook wants to hear about an event, which it does for all of them when none are listed
+func (w Webhook) HasEvent(event string) bool {
+	if w.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Webhooks Common database resource link for Webhook actions
+type Webhooks struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ws *Webhooks) CreateTable() error {
+	_, err := ws.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + webhookTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`url` VARCHAR(255) NOT NULL, " +
+		"`secret` VARCHAR(64) NOT NULL, " +
+		"`events` VARCHAR(255) NOT NULL DEFAULT '', " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// Delete Remove a webhook along with the record of what was sent to it
+func (ws *Webhooks) Delete(w Webhook) error {
+	if _, err := ws.Container.Db.Exec("DELETE FROM `"+webhookTable+"` WHERE `id` = ?", strconv.Itoa(w.ID)); err != nil {
+		return err
+	}
+	_, err := ws.Container.Db.Exec("DELETE FROM `"+webhookDeliveryTable+"` WHERE `webhook_id` = ?", strconv.Itoa(w.ID))
+
+	return err
+}
+
+// FetchAll Get every webhook, oldest first
+func (ws *Webhooks) FetchAll() []Webhook {
+	rows, err := ws.Container.Db.Query("SELECT " + webhookColumns + " FROM `" + webhookTable + "` ORDER BY `id`")
+	if err != nil {
+		return []Webhook{}
+	}
+
+	return ws.loadFromRows(rows)
+}
+
+// FetchForEvent Get the webhooks that want to hear about an event
+func (ws *Webhooks) FetchForEvent(event string) []Webhook {
+	webhooks := []Webhook{}
+	for _, w := range ws.FetchAll() {
+		if w.HasEvent(event) {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks
+}
+
+// FindByID Find a webhook by its ID
+func (ws *Webhooks) FindByID(id int) Webhook {
+	rows, err := ws.Container.Db.Query("SELECT "+webhookColumns+" FROM `"+webhookTable+"` WHERE `id` = ? LIMIT 1", strconv.Itoa(id))
+	if err != nil {
+		return Webhook{}
+	}
+	webhooks := ws.loadFromRows(rows)
+	if len(webhooks) == 1 {
+		return webhooks[0]
+	}
+
+	return Webhook{}
+}
+
+// Save Store a new webhook, generating a secret to sign its requests with when none is given
+func (ws *Webhooks) Save(w Webhook) (Webhook, error) {
+	if w.Secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return w, err
+		}
+		w.Secret = hex.EncodeToString(random)
+	}
+	w.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := ws.Container.Db.Exec("INSERT INTO `"+webhookTable+"` (`url`, `secret`, `events`, `created_at`) VALUES(?,?,?,?)", w.URL, w.Secret, w.Events, w.CreatedAt)
+	if err != nil {
+		return w, err
+	}
+	id, _ := res.LastInsertId()
+	w.ID = int(id)
+
+	return w, nil
+}
+
+func (ws Webhooks) loadFromRows(rows rows.Rows) []Webhook {
+	defer rows.Close()
+	webhooks := []Webhook{}
+	for rows.Next() {
+		w := Webhook{}
+		rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.CreatedAt)
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks
+}
+
+const webhookDeliveryTable = "webhook_delivery"
+
+const webhookDeliveryColumns = "`id`, `webhook_id`, `event`, `payload`, `status_code`, `error`, `attempt`, `created_at`"
+
+// WebhookDelivery model, one attempt to send an event to a webhook
+type WebhookDelivery struct {
+	ID         int    `json:"id"`
+	WebhookID  int    `json:"webhook_id"`
+	Event      string `json:"event"`
+	Payload    string `json:"payload"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error"`
+	Attempt    int    `json:"attempt"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// Succeeded Whether the webhook accepted the delivery
+func (d WebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+// WebhookDeliveries Common database resource link for WebhookDelivery actions
+type WebhookDeliveries struct {
+	Container *app.Container
+}
+
+// CreateTable Create the actual table
+func (ds *WebhookDeliveries) CreateTable() error {
+	_, err := ds.Container.Db.Exec("CREATE TABLE IF NOT EXISTS `" + webhookDeliveryTable + "` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`webhook_id` INTEGER NOT NULL, " +
+		"`event` VARCHAR(50) NOT NULL, " +
+		"`payload` TEXT NOT NULL, " +
+		"`status_code` INTEGER NOT NULL DEFAULT 0, " +
+		"`error` TEXT NOT NULL DEFAULT '', " +
+		"`attempt` INTEGER NOT NULL DEFAULT 1, " +
+		"`created_at` DATETIME NOT NULL" +
+		")")
+
+	return err
+}
+
+// FetchByWebhook Get the latest deliveries to a webhook, newest first
+func (ds *WebhookDeliveries) FetchByWebhook(webhookID int, limit int) []WebhookDelivery {
+	rows, err := ds.Container.Db.Query("SELECT "+webhookDeliveryColumns+" FROM `"+webhookDeliveryTable+"` WHERE `webhook_id` = ? ORDER BY `id` DESC LIMIT ?", strconv.Itoa(webhookID), limit)
+	if err != nil {
+		return []WebhookDelivery{}
+	}
+	defer rows.Close()
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		d := WebhookDelivery{}
+		rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.StatusCode, &d.Error, &d.Attempt, &d.CreatedAt)
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries
+}
+
+// Record Store the outcome of an attempt to send an event to a webhook
+func (ds *WebhookDeliveries) Record(d WebhookDelivery) (WebhookDelivery, error) {
+	d.CreatedAt = time.Now().UTC().Format(jobTimeFormat)
+	res, err := ds.Container.Db.Exec("INSERT INTO `"+webhookDeliveryTable+"` (`webhook_id`, `event`, `payload`, `status_code`, `error`, `attempt`, `created_at`) VALUES(?,?,?,?,?,?,?)", strconv.Itoa(d.WebhookID), d.Event, d.Payload, strconv.Itoa(d.StatusCode), d.Error, strconv.Itoa(d.Attempt), d.CreatedAt)
+	if err != nil {
+		return d, err
+	}
+	id, _ := res.LastInsertId()
+	d.ID = int(id)
+
+	return d, nil
+}
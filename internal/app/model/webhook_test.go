@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestWebhook_HasEvent(t *testing.T) {
+	if !(Webhook{}).HasEvent("entry.created") {
+		t.Error("Expected webhook without events to hear about all of them")
+	}
+	w := Webhook{Events: "entry.created,entry.deleted"}
+	if !w.HasEvent("entry.deleted") || w.HasEvent("entry.updated") {
+		t.Error("Expected webhook to hear only about the events listed")
+	}
+}
+
+func TestWebhooks_CreateTable(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ws := Webhooks{Container: container}
+	ws.CreateTable()
+	ds := WebhookDeliveries{Container: container}
+	ds.CreateTable()
+	if db.Queries != 2 {
+		t.Errorf("Expected 2 queries to have been run")
+	}
+}
+
+func TestWebhooks_FetchForEvent(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ws := Webhooks{Container: container}
+
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	if webhooks := ws.FetchForEvent("entry.created"); len(webhooks) != 1 || webhooks[0].URL != "https://hooks.example.com/1" {
+		t.Errorf("Expected only the webhook hearing about every event, got %v", webhooks)
+	}
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	if webhooks := ws.FetchForEvent("entry.published"); len(webhooks) != 2 {
+		t.Errorf("Expected both webhooks, got %v", webhooks)
+	}
+
+	db.ErrorMode = true
+	if len(ws.FetchAll()) != 0 {
+		t.Error("Expected no webhooks on error")
+	}
+}
+
+func TestWebhooks_FindByID(t *testing.T) {
+	db := &database.MockSqlite{}
+	container := &app.Container{Db: db}
+	ws := Webhooks{Container: container}
+
+	db.Rows = &database.MockWebhook_SingleRow{}
+	if w := ws.FindByID(1); w.ID != 1 || w.Secret != "secret" {
+		t.Errorf("Expected webhook to be found, got %v", w)
+	}
+	db.Rows = &database.MockRowsEmpty{}
+	if w := ws.FindByID(2); w.ID != 0 {
+		t.Error("Expected no webhook to be found")
+	}
+}
+
+func TestWebhooks_Save(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ws := Webhooks{Container: container}
+
+	w, err := ws.Save(Webhook{URL: "https://hooks.example.com/1"})
+	if err != nil || w.ID != 1 || len(w.Secret) != 64 || w.CreatedAt == "" {
+		t.Errorf("Expected webhook to be saved with a generated secret, got %v, %v", w, err)
+	}
+	w, _ = ws.Save(Webhook{URL: "https://hooks.example.com/2", Secret: "chosen"})
+	if w.Secret != "chosen" {
+		t.Error("Expected secret given to be kept")
+	}
+
+	db.ErrorMode = true
+	if _, err := ws.Save(Webhook{URL: "https://hooks.example.com/3"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestWebhooks_Delete(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ws := Webhooks{Container: container}
+	if err := ws.Delete(Webhook{ID: 1}); err != nil || db.Queries != 2 {
+		t.Error("Expected webhook and its deliveries to be deleted")
+	}
+
+	db.ErrorAtQuery = 3
+	if err := ws.Delete(Webhook{ID: 1}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Db: db}
+	ds := WebhookDeliveries{Container: container}
+
+	d, err := ds.Record(WebhookDelivery{WebhookID: 1, Event: "entry.created", Payload: "{}", StatusCode: 204, Attempt: 1})
+	if err != nil || d.ID != 1 || !d.Succeeded() {
+		t.Errorf("Expected delivery to be recorded, got %v, %v", d, err)
+	}
+	if (WebhookDelivery{StatusCode: 500}).Succeeded() || (WebhookDelivery{Error: "Timeout"}).Succeeded() {
+		t.Error("Expected failed deliveries not to have succeeded")
+	}
+
+	db.Rows = &database.MockWebhookDelivery_MultipleRows{}
+	deliveries := ds.FetchByWebhook(1, 20)
+	if len(deliveries) != 2 || deliveries[0].ID != 2 || deliveries[0].Succeeded() || !deliveries[1].Succeeded() {
+		t.Errorf("Expected latest deliveries first, got %v", deliveries)
+	}
+
+	db.ErrorMode = true
+	if len(ds.FetchByWebhook(1, 20)) != 0 {
+		t.Error("Expected no deliveries on error")
+	}
+}
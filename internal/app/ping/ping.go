@@ -0,0 +1,71 @@
+package ping
+
+import (
+	"errors"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/adapter/ping"
+)
+
+// JobType Name of the background job that sends the notifications
+const JobType = "ping"
+
+// FeedPath Path of the feed announced to the WebSub hub
+const FeedPath = "/feed.atom"
+
+// Payload URLs to announce, stored with the queued job
+type Payload struct {
+	URLs []string `json:"urls"`
+	Feed string   `json:"feed"`
+}
+
+// Enabled Whether a site URL and at least one notification endpoint have been configured
+func Enabled(container *app.Container) bool {
+	config := container.Configuration
+	return config.URL != "" && (config.IndexNowKey != "" || config.WebSubHub != "")
+}
+
+// Notify Queue notifications for a listed entry that has been published or updated
+func Notify(container *app.Container, journal model.Journal) error {
+	if !Enabled(container) || journal.Slug == "" || !journal.IsPublished() || !journal.IsListed() {
+		return nil
+	}
+
+	js := model.Jobs{Container: container}
+	_, err := js.Enqueue(JobType, Payload{
+		URLs: []string{container.URL("/" + journal.Slug)},
+		Feed: container.URL(FeedPath),
+	})
+
+	return err
+}
+
+// Handle Send a queued set of notifications to each configured endpoint
+func Handle(container *app.Container, job model.Job) error {
+	payload := Payload{}
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	// Try every endpoint before reporting, so one being down does not hold up the rest
+	var failed error
+	config := container.Configuration
+	if config.IndexNowKey != "" {
+		client := ping.IndexNow{Endpoint: config.IndexNowEndpoint, Key: config.IndexNowKey}
+		if err := client.Submit(payload.URLs); err != nil {
+			failed = err
+		}
+	}
+	if config.WebSubHub != "" && payload.Feed != "" {
+		client := ping.WebSub{Hub: config.WebSubHub}
+		if err := client.Publish(payload.Feed); err != nil {
+			if failed != nil {
+				return errors.New(failed.Error() + "; " + err.Error())
+			}
+			failed = err
+		}
+	}
+
+	return failed
+}
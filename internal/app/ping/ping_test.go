@@ -0,0 +1,96 @@
+package ping
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if Enabled(container) {
+		t.Error("Expected notifications to be disabled by default")
+	}
+	container.Configuration.IndexNowKey = "abc123"
+	if Enabled(container) {
+		t.Error("Expected notifications to be disabled without a site URL")
+	}
+	container.Configuration.URL = "https://example.com"
+	if !Enabled(container) {
+		t.Error("Expected notifications to be enabled")
+	}
+}
+
+func TestNotify(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// Test nothing queued when disabled
+	Notify(container, model.Journal{Slug: "test"})
+	if db.Queries != 0 {
+		t.Error("Expected nothing to be queued when disabled")
+	}
+
+	// Test queued with absolute URLs
+	container.Configuration.URL = "https://example.com/"
+	container.Configuration.WebSubHub = "https://hub.example.com"
+	db.ExpectedArgument = "{\"urls\":[\"https://example.com/test\"],\"feed\":\"https://example.com/feed.atom\"}"
+	if err := Notify(container, model.Journal{Slug: "test"}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected notification to be queued, got %s", err)
+	}
+
+	// Test drafts are not announced
+	db.Queries = 0
+	if err := Notify(container, model.Journal{Slug: "test", Status: model.JournalStatusDraft}); err != nil || db.Queries != 0 {
+		t.Error("Expected nothing to be queued for a draft")
+	}
+
+	// Test unlisted and private entries are not announced
+	for _, visibility := range []string{model.JournalVisibilityUnlisted, model.JournalVisibilityPrivate} {
+		if err := Notify(container, model.Journal{Slug: "test", Visibility: visibility}); err != nil || db.Queries != 0 {
+			t.Errorf("Expected nothing to be queued for a %s entry", visibility)
+		}
+	}
+
+	// Test error
+	db.ErrorMode = true
+	if err := Notify(container, model.Journal{Slug: "test"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	hits := map[string]int{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	container.Configuration.IndexNowEndpoint = server.URL + "/indexnow"
+	container.Configuration.IndexNowKey = "abc123"
+	container.Configuration.WebSubHub = server.URL + "/hub"
+	job := model.Job{Payload: "{\"urls\":[\"https://example.com/test\"],\"feed\":\"https://example.com/feed.atom\"}"}
+
+	// Test invalid payload
+	if err := Handle(container, model.Job{Payload: "{"}); err == nil {
+		t.Error("Expected error for invalid payload")
+	}
+
+	// Test both endpoints notified
+	if err := Handle(container, job); err != nil || hits["/indexnow"] != 1 || hits["/hub"] != 1 {
+		t.Error("Expected both endpoints to have been notified")
+	}
+
+	// Test failures are reported after trying each endpoint
+	status = http.StatusInternalServerError
+	if err := Handle(container, job); err == nil || hits["/indexnow"] != 2 || hits["/hub"] != 2 {
+		t.Error("Expected failure to be reported after both endpoints were tried")
+	}
+}
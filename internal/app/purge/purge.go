@@ -0,0 +1,81 @@
+package purge
+
+import (
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/cron"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+)
+
+// JobType Name of the background job that empties old entries from the trash
+const JobType = "purge"
+
+// Interval How long to wait between each purge of the trash
+var Interval = 24 * time.Hour
+
+// Enabled Whether a retention period has been configured, without which entries stay in the trash until deleted by hand
+func Enabled(container *app.Container) bool {
+	return container.Configuration.TrashRetention > 0
+}
+
+// Purge Permanently delete every entry left in the trash for longer than the retention period, logging each one
+func Purge(container *app.Container) ([]model.Journal, error) {
+	purged := []model.Journal{}
+	if !Enabled(container) {
+		return purged, nil
+	}
+
+	js := model.Journals{Container: container}
+	store := model.Store(container)
+	for _, j := range js.FetchExpired(container.Configuration.TrashRetention) {
+		if err := store.Delete(j); err != nil {
+			return purged, err
+		}
+		container.Log().Info("Purged entry from the trash", "slug", j.Slug, "deleted_at", j.DeletedAt)
+		purged = append(purged, j)
+	}
+
+	return purged, nil
+}
+
+// Task Build the task purging the journal and, when open is given, each hosted journal it opens, once a day
+func Task(open func(model.Tenant) (*app.Container, error)) cron.Task {
+	return cron.Task{
+		Name:     JobType,
+		Enabled:  Enabled,
+		Interval: func(*app.Container) time.Duration { return Interval },
+		Run:      func(container *app.Container) error { return purgeAll(container, open) },
+	}
+}
+
+// Handler Build the job handler purging the journal and, when open is given, each hosted journal it opens, for a purge
+// queued to run straight away
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		return purgeAll(container, open)
+	}
+}
+
+// purgeAll Purge the journal and each hosted journal opened
+func purgeAll(container *app.Container, open func(model.Tenant) (*app.Container, error)) error {
+	if _, err := Purge(container); err != nil {
+		return err
+	}
+	if open == nil {
+		return nil
+	}
+	ts := model.Tenants{Container: container}
+	for _, t := range ts.FetchAll() {
+		hosted, err := open(t)
+		if err != nil {
+			return err
+		}
+		if _, err := Purge(hosted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
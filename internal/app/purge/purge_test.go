@@ -0,0 +1,104 @@
+package purge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if Enabled(container) {
+		t.Error("Expected purging to be disabled by default")
+	}
+	container.Configuration.TrashRetention = 30
+	if !Enabled(container) {
+		t.Error("Expected purging to be enabled with a retention period")
+	}
+}
+
+func TestTask(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	task := Task(nil)
+
+	if task.Name != JobType || task.Enabled(container) {
+		t.Error("Expected purges not to be run when disabled")
+	}
+
+	container.Configuration.TrashRetention = 30
+	if !task.Enabled(container) || task.Interval(container) != Interval {
+		t.Error("Expected purges to be run each day")
+	}
+	if err := task.Run(container); err != nil || db.Queries != 1 {
+		t.Errorf("Expected trash to be purged, got %d queries", db.Queries)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	if purged, err := Purge(container); err != nil || len(purged) != 0 || db.Queries != 0 {
+		t.Error("Expected nothing to be purged when disabled")
+	}
+
+	// Each expired entry is deleted along with everything attached to it
+	container.Configuration.TrashRetention = 30
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockRowsEmpty{})
+	purged, err := Purge(container)
+	if err != nil || len(purged) != 2 || purged[1].Slug != "slug-2" || db.Queries != 17 {
+		t.Errorf("Expected expired entries to be purged, got %d queries", db.Queries)
+	}
+
+	// Failures stop the purge
+	db.AppendResult(&database.MockJournal_MultipleRows{})
+	db.ErrorAtQuery = db.Queries + 2
+	if purged, err := Purge(container); err == nil || len(purged) != 0 {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.TrashRetention = 30
+
+	// The journal is purged
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Handler(nil)(container, model.Job{}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected journal to be purged, got %d queries", db.Queries)
+	}
+
+	// Hosted journals are purged from their own databases
+	hostedDb := &database.MockSqlite{Result: &database.MockResult{}}
+	hostedDb.Rows = &database.MockRowsEmpty{}
+	opened := []string{}
+	open := func(tenant model.Tenant) (*app.Container, error) {
+		opened = append(opened, tenant.Name)
+		return &app.Container{Configuration: container.Configuration, Db: hostedDb, Tenant: tenant.Name}, nil
+	}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	if err := Handler(open)(container, model.Job{}); err != nil || len(opened) != 1 || opened[0] != "alice" || hostedDb.Queries != 1 {
+		t.Error("Expected hosted journal to be purged")
+	}
+
+	// Journals that cannot be opened are reported
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	failing := func(tenant model.Tenant) (*app.Container, error) {
+		return nil, errors.New("Unavailable")
+	}
+	if err := Handler(failing)(container, model.Job{}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
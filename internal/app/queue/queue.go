@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+)
+
+// Handler Performs the work for a single job
+type Handler func(container *app.Container, job model.Job) error
+
+// Dispatcher Polls the persistent job table and hands jobs to registered handlers
+type Dispatcher struct {
+	Container    *app.Container
+	PollInterval time.Duration
+	handlers     map[string]Handler
+	mutex        sync.Mutex
+	stop         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewDispatcher Create a dispatcher for the given container
+func NewDispatcher(container *app.Container) *Dispatcher {
+	return &Dispatcher{
+		Container:    container,
+		PollInterval: 2 * time.Second,
+		handlers:     map[string]Handler{},
+	}
+}
+
+// Handle Register the handler for a job type
+func (d *Dispatcher) Handle(jobType string, handler Handler) {
+	d.handlers[jobType] = handler
+}
+
+// RunNext Claim and run a single job, returning false when nothing was due
+func (d *Dispatcher) RunNext() bool {
+	js := model.Jobs{Container: d.Container}
+
+	// Claims are serialised so that workers in this process never race
+	d.mutex.Lock()
+	job := js.Claim()
+	d.mutex.Unlock()
+	if job.ID == 0 {
+		return false
+	}
+
+	handler, ok := d.handlers[job.Type]
+	if !ok {
+		job.Attempts = model.JobMaxAttempts
+		js.Fail(job, errors.New("No handler registered for job type "+job.Type))
+		return true
+	}
+
+	// Everything the job logs carries its ID
+	container := *d.Container
+	container.Logger = d.Container.Log().With("job", job.ID, "job_type", job.Type)
+	if err := handler(&container, job); err != nil {
+		container.Log().Warn("Job failed", "attempts", job.Attempts, "err", err)
+		js.Fail(job, err)
+	} else {
+		js.Complete(job)
+	}
+
+	return true
+}
+
+// Start Recover jobs interrupted by a restart and launch the workers
+func (d *Dispatcher) Start(workers int) {
+	js := model.Jobs{Container: d.Container}
+	js.ResetRunning()
+	d.Resume(workers)
+}
+
+// Resume Launch the workers without recovering running jobs, for when another process is still finishing them while
+// handing over to this one
+func (d *Dispatcher) Resume(workers int) {
+	d.stop = make(chan struct{})
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop Signal the workers to finish and wait for any running jobs
+func (d *Dispatcher) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	d.wg.Wait()
+	d.stop = nil
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		// Drain everything that is due before sleeping
+		for d.RunNext() {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-time.After(d.PollInterval):
+		}
+	}
+}
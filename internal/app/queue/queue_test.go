@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestDispatcher_RunNext(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Db: db}
+	dispatcher := NewDispatcher(container)
+
+	// Test nothing to run
+	db.Rows = &database.MockRowsEmpty{}
+	if dispatcher.RunNext() {
+		t.Error("Expected no job to have been run")
+	}
+
+	// Test no handler registered
+	db.Rows = &database.MockJob_SingleRow{Type: "unknown"}
+	db.ExpectedArgument = ""
+	if !dispatcher.RunNext() {
+		t.Error("Expected job to have been consumed")
+	}
+
+	// Test successful handler
+	ran := 0
+	dispatcher.Handle("test", func(c *app.Container, j model.Job) error {
+		ran++
+		if c.Logger == nil || c.Db != container.Db {
+			t.Error("Expected the handler to be given the container with a logger for the job")
+		}
+		return nil
+	})
+	db.Rows = &database.MockJob_SingleRow{}
+	if !dispatcher.RunNext() || ran != 1 {
+		t.Error("Expected handler to have been run")
+	}
+
+	// Test failing handler
+	dispatcher.Handle("test", func(c *app.Container, j model.Job) error {
+		ran++
+		return errors.New("Simulated failure")
+	})
+	db.Rows = &database.MockJob_SingleRow{}
+	if !dispatcher.RunNext() || ran != 2 {
+		t.Error("Expected failing handler to have been run")
+	}
+}
+
+func TestDispatcher_StartStop(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	dispatcher := NewDispatcher(container)
+	dispatcher.PollInterval = time.Millisecond
+
+	// Stopping before starting is harmless
+	dispatcher.Stop()
+
+	dispatcher.Start(2)
+	time.Sleep(5 * time.Millisecond)
+	dispatcher.Stop()
+	if db.Queries < 2 {
+		t.Error("Expected running jobs to have been reset and the queue polled")
+	}
+}
+
+func TestDispatcher_Resume(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Db: db}
+	dispatcher := NewDispatcher(container)
+	dispatcher.PollInterval = time.Hour
+
+	dispatcher.Resume(1)
+	time.Sleep(5 * time.Millisecond)
+	dispatcher.Stop()
+	if db.Queries != 1 {
+		t.Errorf("Expected the queue to be polled without resetting running jobs, got %d queries", db.Queries)
+	}
+}
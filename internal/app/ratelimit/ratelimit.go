@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+)
+
+// bucket Tokens left for an address, refilled steadily up to the limit and taken one for each request
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter Limits how often each address may change anything or try to sign in, giving it a bucket of tokens for each
+// that refills over a minute
+type Limiter struct {
+	Limit          int
+	LoginLimit     int
+	TrustedProxies []*net.IPNet
+	Now            func() time.Time
+	buckets        map[string]*bucket
+	pruned         time.Time
+	mutex          sync.Mutex
+}
+
+// Enabled Whether either limit has been configured
+func Enabled(container *app.Container) bool {
+	return container.Configuration.RateLimit > 0 || container.Configuration.RateLimitLogin > 0
+}
+
+// NewLimiter Create a limiter with the configured limits, trusting the configured proxies to say who they forward for
+func NewLimiter(config app.Configuration) *Limiter {
+	return &Limiter{
+		Limit:          config.RateLimit,
+		LoginLimit:     config.RateLimitLogin,
+		TrustedProxies: proxy.ParseProxies(config.TrustedProxies),
+		Now:            time.Now,
+		buckets:        map[string]*bucket{},
+	}
+}
+
+// Middleware Answer requests changing anything or signing in with a 429 once their address has used up its limit
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodGet || request.Method == http.MethodHead || request.Method == http.MethodOptions {
+			next.ServeHTTP(response, request)
+			return
+		}
+
+		kind, limit := "write", l.Limit
+		if strings.HasSuffix(request.URL.Path, "/login") {
+			kind, limit = "login", l.LoginLimit
+		}
+		if wait := l.Take(kind+" "+l.ClientIP(request), limit); wait > 0 {
+			response.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(response, "Too many requests, please try again shortly", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// Take Take a token from the key's bucket, holding a minute's worth, or say how long until one is refilled when it is
+// empty. A limit of 0 never runs out.
+func (l *Limiter) Take(key string, limit int) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.Now()
+	rate := float64(limit) / time.Minute.Seconds()
+	l.prune(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+
+	return 0
+}
+
+// prune Forget buckets left alone long enough to have refilled, once a minute, so addresses seen once are not kept
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < time.Minute {
+		return
+	}
+	l.pruned = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ClientIP Find the address of the client making a request, as the limiter sees it
+func (l *Limiter) ClientIP(request *http.Request) string {
+	return proxy.ClientIP(request, l.TrustedProxies)
+}
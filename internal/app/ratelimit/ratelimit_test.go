@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+)
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if !Enabled(container) {
+		t.Error("Expected limits to be enabled by default")
+	}
+	container.Configuration.RateLimit = 0
+	container.Configuration.RateLimitLogin = 0
+	if Enabled(container) {
+		t.Error("Expected limits of 0 to disable limiting")
+	}
+}
+
+func TestLimiter_Take(t *testing.T) {
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(app.Configuration{})
+	limiter.Now = func() time.Time { return now }
+
+	// Test a minute's worth may be taken at once
+	for i := 0; i < 3; i++ {
+		if wait := limiter.Take("1.2.3.4", 3); wait != 0 {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	if wait := limiter.Take("1.2.3.4", 3); wait != 20*time.Second {
+		t.Errorf("Expected to wait 20 seconds for a token, got %s", wait)
+	}
+	if wait := limiter.Take("5.6.7.8", 3); wait != 0 {
+		t.Error("Expected other addresses to have their own bucket")
+	}
+
+	// Test tokens are refilled steadily
+	now = now.Add(20 * time.Second)
+	if wait := limiter.Take("1.2.3.4", 3); wait != 0 {
+		t.Error("Expected a token to be refilled")
+	}
+	if wait := limiter.Take("1.2.3.4", 3); wait == 0 {
+		t.Error("Expected only one token to be refilled")
+	}
+
+	// Test no limit, and idle buckets being forgotten
+	if wait := limiter.Take("1.2.3.4", 0); wait != 0 {
+		t.Error("Expected a limit of 0 to never run out")
+	}
+	now = now.Add(2 * time.Minute)
+	limiter.Take("9.9.9.9", 3)
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected idle buckets to be forgotten, got %d", len(limiter.buckets))
+	}
+}
+
+func TestLimiter_ClientIP(t *testing.T) {
+	limiter := NewLimiter(app.Configuration{TrustedProxies: "10.0.0.0/8"})
+	request, _ := http.NewRequest("POST", "/new", nil)
+
+	// Test address connecting is used
+	request.RemoteAddr = "1.2.3.4:5000"
+	request.Header.Set("X-Forwarded-For", "6.6.6.6")
+	if ip := limiter.ClientIP(request); ip != "1.2.3.4" {
+		t.Errorf("Expected untrusted forwarding to be ignored, got %s", ip)
+	}
+
+	// Test trusted proxies give the client, but not anything the client claimed before reaching them
+	request.RemoteAddr = "10.0.0.1:5000"
+	request.Header.Set("X-Forwarded-For", "6.6.6.6, 1.2.3.4, 10.0.0.2")
+	if ip := limiter.ClientIP(request); ip != "1.2.3.4" {
+		t.Errorf("Expected client forwarded by trusted proxies, got %s", ip)
+	}
+	request.Header.Set("X-Forwarded-For", "garbage")
+	if ip := limiter.ClientIP(request); ip != "10.0.0.1" {
+		t.Errorf("Expected proxy when it forwards nothing valid, got %s", ip)
+	}
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	limiter := NewLimiter(app.Configuration{RateLimit: 2, RateLimitLogin: 1})
+	served := 0
+	handler := limiter.Middleware(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		served++
+	}))
+	send := func(method string, path string) *controller.MockResponse {
+		response := controller.NewMockResponse()
+		request, _ := http.NewRequest(method, path, nil)
+		request.RemoteAddr = "1.2.3.4:5000"
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	// Test reading is never limited
+	for i := 0; i < 5; i++ {
+		send("GET", "/")
+	}
+	if served != 5 {
+		t.Error("Expected reading to be allowed")
+	}
+
+	// Test sign in attempts and other changes are limited apart
+	if send("POST", "/login").StatusCode == 429 {
+		t.Error("Expected first sign in attempt to be allowed")
+	}
+	response := send("POST", "/login")
+	if response.StatusCode != 429 || response.Headers.Get("Retry-After") != "60" {
+		t.Errorf("Expected second sign in attempt to be refused, got %d", response.StatusCode)
+	}
+	send("POST", "/new")
+	send("DELETE", "/api/journals/test")
+	if send("PUT", "/api/v1/post").StatusCode != 429 || served != 8 {
+		t.Errorf("Expected changes beyond the limit to be refused, served %d", served)
+	}
+}
@@ -1,9 +1,19 @@
 package router
 
 import (
+	"io/fs"
+	"net/http"
+
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/controller/admin"
+	"github.com/jamiefdhurst/journal/internal/app/controller/apiadmin"
 	"github.com/jamiefdhurst/journal/internal/app/controller/apiv1"
 	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
 	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
 )
 
@@ -12,17 +22,159 @@ func NewRouter(app *app.Container) *pkgrouter.Router {
 	rtr := pkgrouter.Router{}
 	rtr.Container = app
 	rtr.ErrorController = &web.BadRequest{}
+	rtr.ServerErrorController = &web.ServerError{}
+	rtr.Prepare = withRequestContext
+	if app != nil {
+		rtr.TrustedProxies = proxy.ParseProxies(app.Configuration.TrustedProxies)
+		rtr.Files, _ = fs.Sub(app.Configuration.Files(), "static")
+		rtr.Use(pkgrouter.Mount(app.BasePath))
+	}
 
-	rtr.Get("/new", &web.New{})
-	rtr.Post("/new", &web.New{})
+	rtr.Get("/new", asEditor(&web.New{}))
+	rtr.Post("/new", asEditor(&web.New{}))
+	rtr.Get("/admin/jobs", asAdmin(&admin.Jobs{}))
+	rtr.Post("/admin/jobs", asAdmin(&admin.Jobs{}))
+	rtr.Get("/admin/entries", asAdmin(&admin.Entries{}))
+	rtr.Post("/admin/entries", asAdmin(&admin.Entries{}))
+	rtr.Get("/admin/comments", asAdmin(&admin.Comments{}))
+	rtr.Post("/admin/comments", asAdmin(&admin.Comments{}))
+	rtr.Get("/admin/categories", asAdmin(&admin.Categories{}))
+	rtr.Post("/admin/categories", asAdmin(&admin.Categories{}))
+	rtr.Get("/admin/shortcodes", asAdmin(&admin.Shortcodes{}))
+	rtr.Post("/admin/shortcodes", asAdmin(&admin.Shortcodes{}))
+	rtr.Get("/admin/stats", asAdmin(&admin.Stats{}))
+	rtr.Get("/admin/blogroll", asAdmin(&admin.Blogroll{}))
+	rtr.Post("/admin/blogroll", asAdmin(&admin.Blogroll{}))
+	rtr.Get("/admin/maintenance", asAdmin(&admin.Maintenance{}))
+	rtr.Post("/admin/maintenance", asAdmin(&admin.Maintenance{}))
+	rtr.Get("/admin/security", asAdmin(&admin.Security{}))
+	rtr.Get("/admin/settings", asAdmin(&admin.Settings{}))
+	rtr.Post("/admin/settings", asAdmin(&admin.Settings{}))
+	rtr.Get("/admin/users", asAdmin(&admin.Users{}))
+	rtr.Post("/admin/users", asAdmin(&admin.Users{}))
+	rtr.Get("/.well-known/webfinger", &web.WebFinger{})
+	rtr.Get("/activitypub/actor", &web.ActivityPubActor{})
+	rtr.Get("/activitypub/entries/{slug:lower}", &web.ActivityPubArticle{})
+	rtr.Get("/activitypub/followers", &web.ActivityPubFollowers{})
+	rtr.Post("/activitypub/inbox", &web.ActivityPubInbox{})
+	rtr.Get("/activitypub/outbox", &web.ActivityPubOutbox{})
+	rtr.Get("/author/{username}", &web.Author{})
+	rtr.Get("/blogroll.opml", &web.BlogrollOPML{})
+	rtr.Get("/category/{slug:lower}", &web.Category{})
+	rtr.Get("/drafts", asEditor(&web.Drafts{}))
+	rtr.Get("/feed.atom", &web.Atom{})
+	rtr.Get("/feed.json", &web.JSONFeed{})
+	rtr.Get("/feed.rss", &web.RSS{})
+	rtr.Get(web.HealthPath, &web.Health{})
+	rtr.Get("/login", &web.Login{})
+	rtr.Post("/login", &web.Login{})
+	rtr.Get("/login/oidc", &web.OIDCLogin{})
+	rtr.Get("/login/oidc/callback", &web.OIDCCallback{})
+	rtr.Post("/logout", &web.Logout{})
+	rtr.Get("/media", asEditor(&web.Media{}))
+	rtr.Get("/media/{path...}", &web.MediaFile{})
+	rtr.Get("/oembed", &web.OEmbed{})
+	rtr.Get("/reading", &web.Reading{})
+	rtr.Get(web.ReadyPath, &web.Ready{})
+	rtr.Get("/robots.txt", &web.Robots{})
+	rtr.Get("/search", &web.Search{})
+	rtr.Get("/setup", &web.Setup{})
+	rtr.Post("/setup", &web.Setup{})
+	rtr.Get("/settings/tokens", asReader(&web.Tokens{}))
+	rtr.Post("/settings/tokens", asReader(&web.Tokens{}))
+	rtr.Get("/sitemap.xml", &web.Sitemap{})
+	rtr.Get("/static/{path...}", &web.Static{})
+	rtr.Get("/tag/{slug:lower}", &web.Tag{})
+	rtr.Get("/trash", asAdmin(&web.Trash{}))
+	rtr.Post("/trash", asAdmin(&web.Trash{}))
+	rtr.Post("/upload", asEditor(&web.Upload{}))
+	rtr.Get("/register", &web.Register{})
+	rtr.Post("/register", &web.Register{})
+	rtr.Get("/api/admin/users", &apiadmin.UserList{})
+	rtr.Put("/api/admin/users", &apiadmin.UserCreate{})
+	rtr.Get("/api/admin/users/{username}", &apiadmin.UserSingle{})
+	rtr.Post("/api/admin/users/{username}", &apiadmin.UserUpdate{})
+	rtr.Delete("/api/admin/users/{username}", &apiadmin.UserDelete{})
+	rtr.Post("/api/admin/users/{username}/password", &apiadmin.PasswordReset{})
+	rtr.Get("/api/admin/users/{username}/tokens", &apiadmin.TokenList{})
+	rtr.Put("/api/admin/users/{username}/tokens", &apiadmin.TokenCreate{})
+	rtr.Delete("/api/admin/users/{username}/tokens/{id:int}", &apiadmin.TokenRevoke{})
+	rtr.Get("/api/admin/webhooks", &apiadmin.WebhookList{})
+	rtr.Put("/api/admin/webhooks", &apiadmin.WebhookCreate{})
+	rtr.Get("/api/admin/webhooks/{id:int}", &apiadmin.WebhookSingle{})
+	rtr.Delete("/api/admin/webhooks/{id:int}", &apiadmin.WebhookDelete{})
+	rtr.Get("/graphql", &apiv1.GraphQL{})
+	rtr.Post("/graphql", &apiv1.GraphQL{})
+	rtr.Get("/micropub", &apiv1.Micropub{})
+	rtr.Post("/micropub", &apiv1.Micropub{})
+	rtr.Get("/api/stats", &apiv1.Stats{})
+	rtr.Get("/api/journals", &apiv1.List{})
+	rtr.Post("/api/journals", asAPIEditor(&apiv1.Create{}))
+	rtr.Get("/api/journals/{slug:lower}/autosave", asAPIEditor(&apiv1.Autosave{}))
+	rtr.Post("/api/journals/{slug:lower}/autosave", asAPIEditor(&apiv1.Autosave{}))
+	rtr.Delete("/api/journals/{slug:lower}/autosave", asAPIEditor(&apiv1.Autosave{}))
+	rtr.Get("/api/journals/{slug:lower}", &apiv1.Single{})
+	rtr.Put("/api/journals/{slug:lower}", asAPIEditor(&apiv1.Update{}))
+	rtr.Delete("/api/journals/{slug:lower}", asAPIEditor(&apiv1.Delete{}))
 	rtr.Get("/api/v1/post", &apiv1.List{})
-	rtr.Put("/api/v1/post", &apiv1.Create{})
-	rtr.Get("/api/v1/post/[%s]", &apiv1.Single{})
-	rtr.Post("/api/v1/post/[%s]", &apiv1.Update{})
-	rtr.Get("/[%s]/edit", &web.Edit{})
-	rtr.Post("/[%s]/edit", &web.Edit{})
-	rtr.Get("/[%s]", &web.View{})
+	rtr.Put("/api/v1/post", asAPIEditor(&apiv1.Create{}))
+	rtr.Get("/api/v1/post/{slug:lower}", &apiv1.Single{})
+	rtr.Post("/api/v1/post/{slug:lower}", asAPIEditor(&apiv1.Update{}))
+	rtr.Get("/{key}.txt", &web.IndexNowKey{})
+	rtr.Get("/{slug:lower}/attachments", asEditor(&web.Attachments{}))
+	rtr.Post("/{slug:lower}/attachments", asEditor(&web.Attachments{}))
+	rtr.Get("/{slug:lower}/attachments/{id:int}", &web.AttachmentFile{})
+	rtr.Post("/{slug:lower}/attachments/{id:int}/delete", asEditor(&web.AttachmentDelete{}))
+	rtr.Post("/{slug:lower}/comments", &web.Comment{})
+	rtr.Post("/{slug:lower}/delete", asEditor(&web.Delete{}))
+	rtr.Get("/{slug:lower}/history", asEditor(&web.History{}))
+	rtr.Get("/{slug:lower}/history/{id:int}", asEditor(&web.Revision{}))
+	rtr.Post("/{slug:lower}/history/{id:int}", asEditor(&web.Revision{}))
+	rtr.Post("/{slug:lower}/unlock", &web.Unlock{})
+	rtr.Get("/{slug:lower}/edit", asEditor(&web.Edit{}))
+	rtr.Post("/{slug:lower}/edit", asEditor(&web.Edit{}))
+	rtr.Get("/{slug:lower}", &web.View{})
 	rtr.Get("/", &web.Index{})
 
 	return &rtr
 }
+
+// asAdmin Only let signed in admins reach a controller, such as those managing the journal rather than their entries
+func asAdmin(c controller.Controller) controller.Controller {
+	return auth.Required(model.RoleAdmin, c, &web.Forbidden{})
+}
+
+// asAPIEditor Only answer API requests carrying the credentials of an editor or admin, such as those changing entries
+func asAPIEditor(c controller.Controller) controller.Controller {
+	return auth.APIRequired(model.RoleEditor, c)
+}
+
+// asEditor Only let signed in editors and admins reach a controller, such as those writing entries
+func asEditor(c controller.Controller) controller.Controller {
+	return auth.Required(model.RoleEditor, c, &web.Forbidden{})
+}
+
+// asReader Only let signed in users reach a controller, whichever role they hold, such as their own settings
+func asReader(c controller.Controller) controller.Controller {
+	return auth.Required(model.RoleReader, c, &web.Forbidden{})
+}
+
+// withRequestContext Serve each request from a copy of the journal's container whose statements stop with the request,
+// reading the rows of pages that only read from the replica when one is configured, and carrying the token its forms post
+// and the language it is read in along with what every page shows around its content
+func withRequestContext(container interface{}, request *http.Request) interface{} {
+	if c, ok := container.(*app.Container); ok && c != nil {
+		bound := c.WithContext(request.Context())
+		bound.CSRFToken = auth.CSRFToken(request)
+		bound.Locale = bound.NegotiateLocale(request.Header.Get("Accept-Language"))
+		user := auth.SessionUser(request, bound)
+		bound.Site = bound.NewSiteData(app.SiteUser{Username: user.Username, Role: user.Role}, flash.Messages(request.Context()))
+		if request.Method == http.MethodGet || request.Method == http.MethodHead {
+			return bound.ReadOnly()
+		}
+
+		return bound
+	}
+
+	return container
+}
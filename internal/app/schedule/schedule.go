@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/cron"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// JobType Name of the background job that publishes scheduled entries
+const JobType = "publish"
+
+// Publisher Publishes scheduled entries once they are due, checking as requests arrive so each hosted journal is covered
+type Publisher struct {
+	Interval time.Duration
+	Router   *pkgrouter.Router
+	checked  map[string]time.Time
+	mutex    sync.Mutex
+}
+
+// NewPublisher Create a publisher checking the journals served by the given router
+func NewPublisher(router *pkgrouter.Router) *Publisher {
+	return &Publisher{
+		Interval: time.Minute,
+		Router:   router,
+		checked:  map[string]time.Time{},
+	}
+}
+
+// Middleware Publish anything due for the journal being requested before it is served
+func (p *Publisher) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if container, ok := p.Router.ContainerFor(request).(*app.Container); ok && container != nil {
+			p.Check(container)
+		}
+		next.ServeHTTP(response, request)
+	})
+}
+
+// Check Publish anything due for the journal, unless it was already checked within the interval or is in maintenance
+func (p *Publisher) Check(container *app.Container) {
+	if container.Maintenance.On() {
+		return
+	}
+	p.mutex.Lock()
+	last, ok := p.checked[container.Tenant]
+	if ok && time.Since(last) < p.Interval {
+		p.mutex.Unlock()
+		return
+	}
+	p.checked[container.Tenant] = time.Now()
+	p.mutex.Unlock()
+
+	Publish(container)
+}
+
+// Publish Publish every scheduled entry whose time has passed, notifying as for any other published entry
+func Publish(container *app.Container) []model.Journal {
+	js := model.Journals{Container: container}
+	published := js.PublishDue()
+	for _, j := range published {
+		container.Log().Info("Published scheduled entry", "slug", j.Slug)
+		ping.Notify(container, j)
+		federation.Notify(container, j)
+		webhook.Published(container, j)
+	}
+
+	return published
+}
+
+// Task Build the task publishing scheduled entries of the journal and, when open is given, each hosted journal it opens,
+// each minute, so that they are published on time even while no requests arrive
+func Task(open func(model.Tenant) (*app.Container, error)) cron.Task {
+	return cron.Task{
+		Name:     JobType,
+		Enabled:  func(*app.Container) bool { return true },
+		Interval: func(*app.Container) time.Duration { return time.Minute },
+		Run:      func(container *app.Container) error { return publishAll(container, open) },
+	}
+}
+
+// Handler Build the job handler publishing scheduled entries of the journal and, when open is given, each hosted
+// journal it opens
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		return publishAll(container, open)
+	}
+}
+
+// publishAll Publish what is due in the journal and each hosted journal opened, unless in maintenance
+func publishAll(container *app.Container, open func(model.Tenant) (*app.Container, error)) error {
+	if container.Maintenance.On() {
+		return nil
+	}
+	Publish(container)
+	if open == nil {
+		return nil
+	}
+	ts := model.Tenants{Container: container}
+	for _, t := range ts.FetchAll() {
+		hosted, err := open(t)
+		if err != nil {
+			return err
+		}
+		Publish(hosted)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package schedule
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestPublish(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	db.Rows = &database.MockJournal_MultipleRows{}
+	published := Publish(container)
+	if len(published) != 2 || published[0].Slug != "slug" {
+		t.Error("Expected due entries to be published")
+	}
+
+	// Notifications are queued for each once enabled
+	db.Queries = 0
+	container.Configuration.URL = "https://example.com"
+	container.Configuration.WebSubHub = "https://hub.example.com"
+	db.Rows = &database.MockJournal_MultipleRows{}
+	Publish(container)
+	if db.Queries != 7 {
+		t.Errorf("Expected entries to be published and notified, got %d queries", db.Queries)
+	}
+}
+
+func TestPublisher_Check(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	publisher := NewPublisher(&pkgrouter.Router{})
+
+	publisher.Check(container)
+	publisher.Check(container)
+	if db.Queries != 1 {
+		t.Error("Expected journal to be checked once within the interval")
+	}
+
+	// Each hosted journal is checked separately
+	hosted := &app.Container{Configuration: app.DefaultConfiguration(), Db: db, Tenant: "hosted"}
+	publisher.Check(hosted)
+	if db.Queries != 2 {
+		t.Error("Expected hosted journal to be checked")
+	}
+
+	publisher.Interval = 0
+	time.Sleep(time.Millisecond)
+	publisher.Check(container)
+	if db.Queries != 3 {
+		t.Error("Expected journal to be checked again once the interval has passed")
+	}
+
+	// Nothing is published in maintenance
+	container.Maintenance = app.NewMaintenance(true)
+	time.Sleep(time.Millisecond)
+	publisher.Check(container)
+	if db.Queries != 3 {
+		t.Error("Expected journal not to be checked in maintenance")
+	}
+}
+
+func TestPublisher_Middleware(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{Affected: 1}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	router := &pkgrouter.Router{Container: container}
+	publisher := NewPublisher(router)
+	response := controller.NewMockResponse()
+	request := &http.Request{URL: &url.URL{Path: "/"}, Method: "GET"}
+
+	served := false
+	publisher.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})).ServeHTTP(response, request)
+	if !served || db.Queries != 1 {
+		t.Error("Expected journal to be checked before the request is served")
+	}
+
+	// Without a container the request is still served
+	served = false
+	router.Container = nil
+	publisher.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})).ServeHTTP(response, request)
+	if !served || db.Queries != 1 {
+		t.Error("Expected request to be served without checking")
+	}
+}
+
+func TestTask(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	task := Task(nil)
+
+	if task.Name != JobType || !task.Enabled(container) || task.Interval(container) != time.Minute {
+		t.Error("Expected scheduled entries to be published each minute")
+	}
+	if err := task.Run(container); err != nil || db.Queries != 1 {
+		t.Errorf("Expected due entries to be published, got %d queries", db.Queries)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+
+	// The journal is published
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Handler(nil)(container, model.Job{}); err != nil || db.Queries != 1 {
+		t.Errorf("Expected journal to be published, got %d queries", db.Queries)
+	}
+
+	// Hosted journals are published from their own databases
+	hostedDb := &database.MockSqlite{Result: &database.MockResult{}}
+	hostedDb.Rows = &database.MockRowsEmpty{}
+	opened := []string{}
+	open := func(tenant model.Tenant) (*app.Container, error) {
+		opened = append(opened, tenant.Name)
+		return &app.Container{Configuration: container.Configuration, Db: hostedDb, Tenant: tenant.Name}, nil
+	}
+	db.EnableMultiMode()
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	if err := Handler(open)(container, model.Job{}); err != nil || len(opened) != 1 || opened[0] != "alice" || hostedDb.Queries != 1 {
+		t.Error("Expected hosted journal to be published")
+	}
+
+	// Journals that cannot be opened are reported
+	db.AppendResult(&database.MockRowsEmpty{})
+	db.AppendResult(&database.MockTenant_SingleRow{})
+	failing := func(tenant model.Tenant) (*app.Container, error) {
+		return nil, errors.New("Unavailable")
+	}
+	if err := Handler(failing)(container, model.Job{}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+
+	// Nothing is published in maintenance
+	db.Queries = 0
+	container.Maintenance = app.NewMaintenance(true)
+	if err := Handler(open)(container, model.Job{}); err != nil || db.Queries != 0 {
+		t.Error("Expected nothing to be published in maintenance")
+	}
+}
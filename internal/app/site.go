@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/jamiefdhurst/journal/pkg/markdown"
+	"github.com/jamiefdhurst/journal/pkg/sanitize"
+)
+
+// SiteData What every page shows around its content: the journal's title and description, the links in its header,
+// who is signed in and any messages left for them by the page before, such as that a form was saved
+type SiteData struct {
+	Title       string
+	Description string
+	Nav         []NavLink
+	User        SiteUser
+	Flashes     []Flash
+}
+
+// Kinds of message left for the next page served, each shown in its own colour
+const (
+	FlashSuccess = "success"
+	FlashError   = "error"
+	FlashInfo    = "info"
+)
+
+// Flash A message left for the next page served, such as that a form was saved or why it could not be
+type Flash struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// NavLink A link in the header of every page, the primary one standing out from the rest
+type NavLink struct {
+	Title   string
+	Path    string
+	Primary bool
+}
+
+// SiteUser Who is signed in to the journal being served, if anyone
+type SiteUser struct {
+	Username string
+	Role     string
+}
+
+// SignedIn Check whether anyone is signed in
+func (u SiteUser) SignedIn() bool {
+	return u.Role != ""
+}
+
+// NewSiteData Gather what every page shows for the journal being served to a user, with any messages left for them
+func (c *Container) NewSiteData(user SiteUser, flashes []Flash) SiteData {
+	site := SiteData{
+		Title:       c.Configuration.Title,
+		Description: c.Configuration.Description,
+		Nav:         []NavLink{},
+		User:        user,
+		Flashes:     flashes,
+	}
+	if c.Configuration.EnableCreate {
+		site.Nav = append(site.Nav, NavLink{Title: c.T("Media"), Path: "/media"}, NavLink{Title: c.T("Drafts"), Path: "/drafts"})
+		if c.Configuration.EnableEdit {
+			site.Nav = append(site.Nav, NavLink{Title: c.T("Trash"), Path: "/trash"})
+		}
+		site.Nav = append(site.Nav, NavLink{Title: c.T("Create New Post"), Path: "/new", Primary: true})
+	}
+
+	return site
+}
+
+// templateTimeFormats Times read by dateFormat when given as text, as they are kept in the database
+var templateTimeFormats = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05Z", "2006-01-02"}
+
+// TemplateFuncs Functions templates can call, each page being given its own to find the files of the journal served and
+// write in the language it is served in:
+//   - dateFormat: write a time, or one kept as text such as 2006-01-02, in a layout, e.g. {{.Date | dateFormat "2 Jan 2006"}}
+//   - truncate: shorten text to a number of characters, ending it with an ellipsis, e.g. {{.Title | truncate 40}}
+//   - markdown: render Markdown as HTML, keeping only the elements allowed in entries
+//   - asset: the URL of a static file, e.g. {{asset "css/print.css"}}
+//   - t: write a message in the language served, filling in any values, e.g. {{t "Posted by %s" .Author}}
+//   - thtml: write a message holding markup, such as a link, escaping the values filled in
+//   - tn: write a message counting something, e.g. {{tn .Count "%d comments"}}
+func (c *Container) TemplateFuncs() template.FuncMap {
+	basePath := ""
+	if c != nil {
+		basePath = c.BasePath
+	}
+	locale := c.Lang()
+
+	return template.FuncMap{
+		"dateFormat": func(layout string, value interface{}) string {
+			return dateFormat(locale, layout, value)
+		},
+		"truncate": truncate,
+		"markdown": func(source string) template.HTML {
+			return template.HTML(sanitize.Entry.Sanitize(markdown.Render(source)))
+		},
+		"asset": func(name string) string {
+			return basePath + "/static/" + strings.TrimPrefix(name, "/")
+		},
+		"t": func(message string, args ...interface{}) string {
+			return Languages.Translate(locale, message, args...)
+		},
+		"thtml": func(message string, args ...interface{}) template.HTML {
+			escaped := make([]interface{}, len(args))
+			for i, arg := range args {
+				escaped[i] = template.HTMLEscapeString(fmt.Sprint(arg))
+			}
+			return template.HTML(Languages.Translate(locale, message, escaped...))
+		},
+		"tn": func(count int, message string, args ...interface{}) string {
+			return Languages.Plural(locale, count, message, args...)
+		},
+	}
+}
+
+// dateFormat Write a time, or one kept as text, in a layout as it is written in a language, leaving text that is not a
+// time as it is
+func dateFormat(locale string, layout string, value interface{}) string {
+	switch typed := value.(type) {
+	case time.Time:
+		if typed.IsZero() {
+			return ""
+		}
+		return Languages.FormatTime(locale, typed, layout)
+	case string:
+		for _, format := range templateTimeFormats {
+			if parsed, err := time.Parse(format, typed); err == nil {
+				return Languages.FormatTime(locale, parsed, layout)
+			}
+		}
+		return typed
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// truncate Shorten text to a number of characters, ending it with an ellipsis when anything was cut
+func truncate(length int, text string) string {
+	runes := []rune(text)
+	if length < 1 || len(runes) <= length {
+		return text
+	}
+
+	return strings.TrimSpace(string(runes[:length])) + "…"
+}
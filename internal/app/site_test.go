@@ -0,0 +1,75 @@
+package app
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContainer_NewSiteData(t *testing.T) {
+	container := &Container{Configuration: DefaultConfiguration()}
+	container.Configuration.Title = "Alice's Journal"
+	site := container.NewSiteData(SiteUser{Username: "alice", Role: "admin"}, []Flash{{Kind: FlashSuccess, Text: "Saved"}})
+	if site.Title != "Alice's Journal" || !site.User.SignedIn() || len(site.Flashes) != 1 {
+		t.Errorf("Expected the journal, user and messages to be given, got %+v", site)
+	}
+	if len(site.Nav) != 4 || site.Nav[2].Path != "/trash" || !site.Nav[3].Primary {
+		t.Errorf("Expected links for writing entries, got %+v", site.Nav)
+	}
+
+	container.Locale = "fr"
+	if site := container.NewSiteData(SiteUser{}, nil); site.Nav[0].Title != "Médias" || site.Nav[3].Title != "Créer un article" {
+		t.Errorf("Expected links in the language served, got %+v", site.Nav)
+	}
+
+	container.Configuration.EnableEdit = false
+	if site := container.NewSiteData(SiteUser{}, nil); len(site.Nav) != 3 || site.User.SignedIn() {
+		t.Errorf("Expected trash to be left out without editing, got %+v", site)
+	}
+	container.Configuration.EnableCreate = false
+	if site := container.NewSiteData(SiteUser{}, nil); len(site.Nav) != 0 {
+		t.Errorf("Expected no links without writing, got %+v", site.Nav)
+	}
+}
+
+func TestContainer_TemplateFuncs(t *testing.T) {
+	container := &Container{BasePath: "/journal"}
+	page, err := template.New("page").Funcs(container.TemplateFuncs()).Parse(
+		`{{.Date | dateFormat "2 Jan 2006"}}|{{.When | dateFormat "15:04"}}|{{.Title | truncate 5}}|{{.Short | truncate 5}}|{{markdown .Body}}|{{asset "css/print.css"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := strings.Builder{}
+	page.Execute(&output, map[string]interface{}{
+		"Date":  "2026-10-17",
+		"When":  time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+		"Title": "A Long Title",
+		"Short": "Short",
+		"Body":  "Some **bold** text<script>alert(1)</script>",
+	})
+	expected := `17 Oct 2026|09:30|A Lon…|Short|<p>Some <strong>bold</strong> text&lt;script&gt;alert(1)&lt;/script&gt;</p>|/journal/static/css/print.css`
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+
+	// Test text that is not a time is left as it is, and static files are found without a container
+	if dateFormat("en", "2006", "someday") != "someday" || dateFormat("en", "2006", nil) != "" {
+		t.Error("Expected text that is not a time to be left as it is")
+	}
+	var none *Container
+	if asset := none.TemplateFuncs()["asset"].(func(string) string)("/js/default.min.js"); asset != "/static/js/default.min.js" {
+		t.Errorf("Expected static file from the root, got %s", asset)
+	}
+
+	// Test messages and dates are written in the language served, escaping values filled into markup
+	french := &Container{Locale: "fr"}
+	page, _ = template.New("page").Funcs(french.TemplateFuncs()).Parse(
+		`{{.When | dateFormat "Monday January 2, 2006"}}|{{t "Search"}}|{{thtml "Another entry already uses the address <strong>%s</strong>." .Slug}}|{{tn 1 "%d comments"}}|{{tn 2 "%d comments"}}|{{t "Not in a catalog"}}`)
+	output.Reset()
+	page.Execute(&output, map[string]interface{}{"When": time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC), "Slug": "<b>"})
+	expected = `samedi 17 octobre 2026|Rechercher|Un autre article utilise déjà l'adresse <strong>&lt;b&gt;</strong>.|1 commentaire|2 commentaires|Not in a catalog`
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
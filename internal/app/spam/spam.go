@@ -0,0 +1,98 @@
+package spam
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/adapter/akismet"
+)
+
+// MaxLinks is the most links a comment may contain before the local heuristics treat it as spam
+const MaxLinks = 2
+
+var blockedPhrases = []string{
+	"buy now", "casino", "cheap pills", "cialis", "click here", "crypto giveaway",
+	"earn money fast", "free money", "payday loan", "viagra", "work from home",
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\[url[=\]])`)
+
+// Enabled Whether an Akismet-compatible API has been configured
+func Enabled(container *app.Container) bool {
+	return container.Configuration.SpamAPIKey != ""
+}
+
+// Check Decide whether a comment is spam, asking the API when one is configured and
+// falling back to the local heuristics when it is not or it cannot be reached
+func Check(container *app.Container, comment akismet.Comment) bool {
+	if Enabled(container) {
+		spam, err := client(container).Check(comment)
+		if err == nil {
+			return spam
+		}
+		container.Log().Warn("Spam API unavailable, using local checks", "err", err)
+	}
+
+	return LooksLikeSpam(comment)
+}
+
+// Report Tell the API about a comment a moderator has marked differently
+func Report(container *app.Container, comment akismet.Comment, spam bool) error {
+	if !Enabled(container) {
+		return errors.New("No spam API has been configured")
+	}
+	if spam {
+		return client(container).SubmitSpam(comment)
+	}
+
+	return client(container).SubmitHam(comment)
+}
+
+// LooksLikeSpam Apply simple local checks for common spam traits
+func LooksLikeSpam(comment akismet.Comment) bool {
+	content := strings.TrimSpace(comment.Content)
+	if content == "" {
+		return true
+	}
+	if linkPattern.MatchString(comment.Author) {
+		return true
+	}
+	if len(linkPattern.FindAllString(content, -1)) > MaxLinks {
+		return true
+	}
+
+	lower := strings.ToLower(content + " " + comment.Author)
+	for _, phrase := range blockedPhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+
+	return shouting(content)
+}
+
+// shouting Whether a reasonably long comment is written mostly in capitals
+func shouting(content string) bool {
+	letters, upper := 0, 0
+	for _, r := range content {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	return letters >= 20 && upper*10 > letters*7
+}
+
+func client(container *app.Container) akismet.Client {
+	return akismet.Client{
+		Endpoint: container.Configuration.SpamAPIEndpoint,
+		Key:      container.Configuration.SpamAPIKey,
+		Site:     container.URL("/"),
+	}
+}
@@ -0,0 +1,85 @@
+package spam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/pkg/adapter/akismet"
+)
+
+func TestLooksLikeSpam(t *testing.T) {
+	tables := []struct {
+		comment akismet.Comment
+		output  bool
+	}{
+		{akismet.Comment{Author: "Jamie", Content: "Lovely post, thanks for sharing."}, false},
+		{akismet.Comment{Author: "Jamie", Content: "See https://example.com for more."}, false},
+		{akismet.Comment{Author: "Jamie", Content: "   "}, true},
+		{akismet.Comment{Author: "www.cheap.example", Content: "Nice"}, true},
+		{akismet.Comment{Author: "Jamie", Content: "http://a.example http://b.example http://c.example"}, true},
+		{akismet.Comment{Author: "Jamie", Content: "[url=http://a.example]a[/url] [url=http://b.example]b[/url] www.c.example"}, true},
+		{akismet.Comment{Author: "Jamie", Content: "Great read. Visit our CASINO today!"}, true},
+		{akismet.Comment{Author: "Jamie", Content: "THIS IS THE BEST POST I HAVE EVER READ"}, true},
+		{akismet.Comment{Author: "Jamie", Content: "OK"}, false},
+	}
+
+	for _, table := range tables {
+		actual := LooksLikeSpam(table.comment)
+		if actual != table.output {
+			t.Errorf("Expected LooksLikeSpam() for '%s' to produce %t", table.comment.Content, table.output)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	reply := "true"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	comment := akismet.Comment{Author: "Jamie", Content: "Lovely post"}
+
+	// Test local heuristics without a key
+	if Check(container, comment) {
+		t.Error("Expected local heuristics to pass the comment")
+	}
+
+	// Test API decision
+	container.Configuration.SpamAPIEndpoint = server.URL
+	container.Configuration.SpamAPIKey = "abc123"
+	if !Check(container, comment) {
+		t.Error("Expected API to mark the comment as spam")
+	}
+
+	// Test fallback when API fails
+	reply = "invalid"
+	if Check(container, comment) {
+		t.Error("Expected local heuristics to be used when the API fails")
+	}
+}
+
+func TestReport(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if err := Report(container, akismet.Comment{}, true); err == nil {
+		t.Error("Expected error when no API is configured")
+	}
+
+	container.Configuration.SpamAPIEndpoint = server.URL
+	container.Configuration.SpamAPIKey = "abc123"
+	if err := Report(container, akismet.Comment{}, true); err != nil || path != "/submit-spam" {
+		t.Error("Expected spam to be reported")
+	}
+	if err := Report(container, akismet.Comment{}, false); err != nil || path != "/submit-ham" {
+		t.Error("Expected ham to be reported")
+	}
+}
@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/adapter/telegram"
+)
+
+// PollTimeout How long, in seconds, each request for new messages waits for one to arrive
+const PollTimeout = 30
+
+// RetryDelay How long to wait before asking again after the Bot API could not be reached
+var RetryDelay = 10 * time.Second
+
+// Errors refusing a message, sent back to the chat it came from
+var (
+	ErrEmpty = errors.New("Send some text or a photo to post an entry")
+	ErrQuota = errors.New("The journal has no room for more entries")
+)
+
+// ErrMaintenance Returned instead of waiting for messages while the journal is in maintenance, leaving them with Telegram
+// until it is over
+var ErrMaintenance = errors.New("The journal is in maintenance")
+
+// Enabled Whether a bot token and the chat allowed to post have both been configured
+func Enabled(container *app.Container) bool {
+	return container.Configuration.TelegramToken != "" && ChatID(container) != 0
+}
+
+// ChatID The chat allowed to post, or 0 when none is configured
+func ChatID(container *app.Container) int64 {
+	id, _ := strconv.ParseInt(container.Configuration.TelegramChatID, 10, 64)
+
+	return id
+}
+
+// Client Build the client talking to the Bot API as the configured bot
+func Client(container *app.Container) telegram.Client {
+	return telegram.Client{Endpoint: container.Configuration.TelegramEndpoint, Token: container.Configuration.TelegramToken}
+}
+
+// Post Publish a message as an entry, taking the first line as the title when there is more than one, and embedding
+// a photo from the media library when one was sent
+func Post(ctx context.Context, container *app.Container, client telegram.Client, message telegram.Message) (model.Journal, error) {
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	if js.QuotaReached() {
+		return model.Journal{}, ErrQuota
+	}
+
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		text = strings.TrimSpace(message.Caption)
+	}
+	j := model.Journal{Date: time.Now().Format("2006-01-02"), Content: text}
+	if lines := strings.SplitN(text, "\n", 2); len(lines) == 2 && strings.TrimSpace(lines[0]) != "" {
+		j.Title = strings.TrimSpace(lines[0])
+		j.Content = strings.TrimSpace(lines[1])
+	} else {
+		j.Title = model.TitleFrom(text)
+	}
+
+	if fileID, name := photo(message); fileID != "" {
+		image, err := store(ctx, container, client, fileID, name)
+		if err != nil {
+			return model.Journal{}, err
+		}
+		j.Content = strings.TrimSpace(j.Content + "\n\n" + image.Markdown())
+		if j.Title == "" {
+			j.Title = "Photo from " + time.Now().Format("2 January 2006")
+		}
+	}
+	if j.Content == "" {
+		return model.Journal{}, ErrEmpty
+	}
+
+	j, err := model.Store(container).CreateJournal(j)
+	if err != nil {
+		return j, err
+	}
+	ping.Notify(container, j)
+	federation.Notify(container, j)
+	webhook.Created(container, j)
+
+	return j, nil
+}
+
+// photo Find the file holding the largest size of a photo, or an image sent as a document
+func photo(message telegram.Message) (string, string) {
+	if len(message.Photo) > 0 {
+		largest := message.Photo[0]
+		for _, p := range message.Photo[1:] {
+			if p.Width*p.Height > largest.Width*largest.Height {
+				largest = p
+			}
+		}
+		return largest.FileID, ""
+	}
+	if message.Document != nil && strings.HasPrefix(message.Document.MimeType, "image/") {
+		return message.Document.FileID, message.Document.FileName
+	}
+
+	return "", ""
+}
+
+// store Download a file from Telegram into the media library
+func store(ctx context.Context, container *app.Container, client telegram.Client, fileID string, name string) (media.File, error) {
+	file, err := client.GetFile(ctx, fileID)
+	if err != nil {
+		return media.File{}, err
+	}
+	data, err := client.Download(ctx, file, media.MaxSize)
+	if err == telegram.ErrTooLarge {
+		return media.File{}, media.ErrTooLarge
+	}
+	if err != nil {
+		return media.File{}, err
+	}
+	if name == "" {
+		name = path.Base(file.FilePath)
+	}
+
+	return media.Store(container, name, bytes.NewReader(data))
+}
+
+// Listener Asks the Bot API for new messages, posting those from the allowed chat and ignoring any others
+type Listener struct {
+	Container *app.Container
+	Client    telegram.Client
+	offset    int64
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mu        sync.Mutex
+}
+
+// NewListener Create a listener for the configured bot
+func NewListener(container *app.Container) *Listener {
+	return &Listener{Container: container, Client: Client(container)}
+}
+
+// Poll Wait for the next messages to arrive and handle each of them. Messages are not acknowledged while the journal is
+// in maintenance, so they are asked for again once it is over.
+func (l *Listener) Poll(ctx context.Context, timeout int) error {
+	if l.Container.Maintenance.On() {
+		return ErrMaintenance
+	}
+	updates, err := l.Client.GetUpdates(ctx, l.offset, timeout)
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		if l.Container.Maintenance.On() {
+			return ErrMaintenance
+		}
+		if update.UpdateID >= l.offset {
+			l.offset = update.UpdateID + 1
+		}
+		if update.Message != nil {
+			l.handle(ctx, *update.Message)
+		}
+	}
+
+	return nil
+}
+
+// handle Post a message from the allowed chat, replying with where the entry can be found or why it could not be
+func (l *Listener) handle(ctx context.Context, message telegram.Message) {
+	if message.Chat.ID != ChatID(l.Container) {
+		l.Container.Log().Warn("Ignored a Telegram message from a chat not allowed to post", "chat", message.Chat.ID)
+		return
+	}
+
+	j, err := Post(ctx, l.Container, l.Client, message)
+	reply := ""
+	if err != nil {
+		l.Container.Log().Warn("Could not post a Telegram message", "err", err)
+		reply = "Could not post: " + err.Error()
+	} else {
+		l.Container.Log().Info("Posted from Telegram", "slug", j.Slug)
+		reply = "Posted " + j.Title
+		if address := l.Container.URL("/" + j.Slug); address != "" {
+			reply += "\n" + address
+		}
+	}
+	if err := l.Client.SendMessage(ctx, message.Chat.ID, reply); err != nil {
+		l.Container.Log().Warn("Could not reply on Telegram", "err", err)
+	}
+}
+
+// Start Keep asking for messages in the background until stopped, waiting a while after each failure and while in
+// maintenance
+func (l *Listener) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	l.mu.Unlock()
+
+	go func() {
+		defer close(l.done)
+		for ctx.Err() == nil {
+			if err := l.Poll(ctx, PollTimeout); err != nil && ctx.Err() == nil {
+				if err != ErrMaintenance {
+					l.Container.Log().Warn("Could not receive Telegram messages", "err", err)
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(RetryDelay):
+				}
+			}
+		}
+	}()
+}
+
+// Stop Stop asking for messages, acknowledging those already handled so they are not posted again
+func (l *Listener) Stop() {
+	l.mu.Lock()
+	cancel, done := l.cancel, l.done
+	l.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	if l.offset > 0 {
+		ctx, cancelAck := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelAck()
+		l.Client.GetUpdates(ctx, l.offset, 0)
+	}
+}
@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/media"
+	"github.com/jamiefdhurst/journal/pkg/adapter/telegram"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+// botAPI A fake Bot API handing out the updates given once, and recording the offsets asked for and replies sent
+type botAPI struct {
+	mu      sync.Mutex
+	updates string
+	offsets []float64
+	replies []string
+}
+
+func (b *botAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	params := map[string]interface{}{}
+	json.NewDecoder(r.Body).Decode(&params)
+	switch r.URL.Path {
+	case "/bottoken/getUpdates":
+		b.offsets = append(b.offsets, params["offset"].(float64))
+		updates := b.updates
+		b.updates = "[]"
+		w.Write([]byte(`{"ok":true,"result":` + updates + `}`))
+	case "/bottoken/getFile":
+		w.Write([]byte(`{"ok":true,"result":{"file_id":"` + params["file_id"].(string) + `","file_path":"photos/file_1.png"}}`))
+	case "/bottoken/sendMessage":
+		b.replies = append(b.replies, params["text"].(string))
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	case "/file/bottoken/photos/file_1.png":
+		w.Write(png)
+	}
+}
+
+func testContainer(t *testing.T, endpoint string) (*app.Container, *database.MockSqlite) {
+	dir, err := ioutil.TempDir("", "telegram")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	db.Rows = &database.MockRowsEmpty{}
+	configuration := app.DefaultConfiguration()
+	configuration.MediaPath = dir
+	configuration.TelegramEndpoint = endpoint
+	configuration.TelegramToken = "token"
+	configuration.TelegramChatID = "42"
+
+	return &app.Container{Configuration: configuration, Db: db}, db
+}
+
+func TestEnabled(t *testing.T) {
+	container := &app.Container{Configuration: app.DefaultConfiguration()}
+	if Enabled(container) {
+		t.Error("Expected Telegram to be disabled by default")
+	}
+	container.Configuration.TelegramToken = "token"
+	container.Configuration.TelegramChatID = "not a number"
+	if Enabled(container) {
+		t.Error("Expected Telegram to be disabled without a valid chat")
+	}
+	container.Configuration.TelegramChatID = "-1001"
+	if !Enabled(container) || ChatID(container) != -1001 {
+		t.Error("Expected Telegram to be enabled for the chat")
+	}
+}
+
+func TestPost(t *testing.T) {
+	api := &botAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	container, _ := testContainer(t, server.URL)
+	client := Client(container)
+	ctx := context.Background()
+
+	j, err := Post(ctx, container, client, telegram.Message{Text: "Quick thought while out walking"})
+	if err != nil || j.Title != "Quick thought while out walking" || j.Content != "Quick thought while out walking" || !j.IsPublished() {
+		t.Errorf("Expected single line to be posted, got %v, %v", j, err)
+	}
+
+	j, err = Post(ctx, container, client, telegram.Message{Text: "Walking\n\nThe long way *round*."})
+	if err != nil || j.Title != "Walking" || j.Content != "The long way *round*." {
+		t.Errorf("Expected first line to be the title, got %v, %v", j, err)
+	}
+
+	j, err = Post(ctx, container, client, telegram.Message{Caption: "Sunset", Photo: []telegram.PhotoSize{{FileID: "large", Width: 800, Height: 600}, {FileID: "small", Width: 90, Height: 60}}})
+	if err != nil || j.Title != "Sunset" || j.Content != "Sunset\n\n![file-1](/media/file-1.png)" {
+		t.Errorf("Expected photo to be embedded, got %v, %v", j, err)
+	}
+	if _, ok := media.Find(container, "file-1.png"); !ok {
+		t.Error("Expected photo to be saved to the media library")
+	}
+
+	j, err = Post(ctx, container, client, telegram.Message{Document: &telegram.Document{FileID: "doc", FileName: "Beach.png", MimeType: "image/png"}})
+	if err != nil || !strings.HasPrefix(j.Title, "Photo from ") || j.Content != "![beach](/media/beach.png)" {
+		t.Errorf("Expected image sent as a file to be embedded, got %v, %v", j, err)
+	}
+
+	if _, err := Post(ctx, container, client, telegram.Message{}); err != ErrEmpty {
+		t.Errorf("Expected empty message to be refused, got %v", err)
+	}
+}
+
+func TestListener_Poll(t *testing.T) {
+	api := &botAPI{updates: `[
+		{"update_id":10,"message":{"chat":{"id":7},"text":"Let me in"}},
+		{"update_id":11,"message":{"chat":{"id":42},"text":"Posted from my phone"}},
+		{"update_id":12,"message":{"chat":{"id":42}}}
+	]`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	container, db := testContainer(t, server.URL)
+	container.Configuration.URL = "https://example.com"
+	listener := NewListener(container)
+
+	if err := listener.Poll(context.Background(), 0); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if db.Queries != 4 {
+		t.Errorf("Expected only the message from the allowed chat to be posted, got %d queries", db.Queries)
+	}
+	if len(api.replies) != 2 || api.replies[0] != "Posted Posted from my phone\nhttps://example.com/posted-from-my-phone" || api.replies[1] != "Could not post: "+ErrEmpty.Error() {
+		t.Errorf("Expected replies to the allowed chat, got %v", api.replies)
+	}
+
+	// Test handled messages are acknowledged
+	listener.Poll(context.Background(), 0)
+	if api.offsets[1] != 13 {
+		t.Errorf("Expected next request to start after the last update, got %v", api.offsets)
+	}
+
+	// Test messages are left with Telegram while in maintenance
+	api.updates = `[{"update_id":13,"message":{"chat":{"id":42},"text":"Later"}}]`
+	container.Maintenance = app.NewMaintenance(true)
+	db.Queries = 0
+	if err := listener.Poll(context.Background(), 0); err != ErrMaintenance || db.Queries != 0 || len(api.offsets) != 2 {
+		t.Errorf("Expected messages not to be asked for while in maintenance, got %v", err)
+	}
+	container.Maintenance.Switch(false)
+	listener.Poll(context.Background(), 0)
+	if db.Queries == 0 || len(api.replies) != 3 || api.replies[2] != "Posted Later\nhttps://example.com/later" {
+		t.Errorf("Expected message to be posted once out of maintenance, got %v", api.replies)
+	}
+
+	// Test errors returned
+	listener.Client.Token = ""
+	if err := listener.Poll(context.Background(), 0); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestListener_Start(t *testing.T) {
+	api := &botAPI{updates: `[{"update_id":3,"message":{"chat":{"id":42},"text":"Hello"}}]`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	container, _ := testContainer(t, server.URL)
+	listener := NewListener(container)
+
+	listener.Start()
+	for i := 0; i < 100; i++ {
+		api.mu.Lock()
+		replied := len(api.replies) > 0
+		api.mu.Unlock()
+		if replied {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	listener.Stop()
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.replies) != 1 || api.offsets[len(api.offsets)-1] != 4 {
+		t.Errorf("Expected message to be posted and acknowledged on stopping, got %v and %v", api.replies, api.offsets)
+	}
+}
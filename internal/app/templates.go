@@ -0,0 +1,118 @@
+package app
+
+import (
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamiefdhurst/journal/web"
+)
+
+// TemplateCache Templates of pages parsed once and kept for every request after, rather than read and parsed for each.
+// Pages are kept apart by the web files, theme, base path and language they were parsed for, so that hosted journals,
+// themes chosen at /admin/settings and readers of each language get their own. When Reload is set, as it is by -dev, a page is parsed again once any of
+// its files changes, so that a theme can be worked on without restarting the journal.
+type TemplateCache struct {
+	Reload bool
+	pages  map[string]*cachedPage
+	mu     sync.Mutex
+}
+
+// cachedPage A page parsed, with when each of its files was last changed when it was
+type cachedPage struct {
+	template *template.Template
+	stamps   []time.Time
+}
+
+// NewTemplateCache Create an empty cache, parsing pages again as they change when reloading
+func NewTemplateCache(reload bool) *TemplateCache {
+	return &TemplateCache{Reload: reload, pages: map[string]*cachedPage{}}
+}
+
+// Get Get a page parsed from the templates given, for the files and base path of a container, parsing it when it is not
+// kept or has changed since. Pages that cannot be parsed are not kept, so that they are tried again once put right.
+func (t *TemplateCache) Get(c *Container, names ...string) (*template.Template, error) {
+	key := strings.Join([]string{c.Configuration.WebPath, c.Configuration.Theme, c.BasePath, c.Lang(), strings.Join(names, ",")}, "\x00")
+	files := c.templateFiles()
+	stamps := []time.Time{}
+	if t.Reload {
+		stamps = templateStamps(files, names)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if page, ok := t.pages[key]; ok && sameStamps(page.stamps, stamps) {
+		return page.template, nil
+	}
+	parsed, err := parseTemplates(files, c.TemplateFuncs(), names)
+	if err != nil {
+		delete(t.pages, key)
+		return nil, err
+	}
+	t.pages[key] = &cachedPage{template: parsed, stamps: stamps}
+
+	return parsed, nil
+}
+
+// Preload Parse every page of web.Pages for a container in each language, as the journal starts, giving the errors of
+// any that cannot be
+func (t *TemplateCache) Preload(c *Container) []error {
+	errs := []error{}
+	for _, names := range web.Pages {
+		for _, locale := range Languages.Locales() {
+			localized := *c
+			localized.Locale = locale
+			if _, err := t.Get(&localized, names...); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// templateFiles Templates beneath templates/ in the files of the container, or those built in without one
+func (c *Container) templateFiles() fs.FS {
+	files := fs.FS(web.Files)
+	if c != nil {
+		files = c.Configuration.Files()
+	}
+	templates, _ := fs.Sub(files, "templates")
+
+	return templates
+}
+
+// parseTemplates Parse templates into a page named after the first, able to call the functions given
+func parseTemplates(files fs.FS, funcs template.FuncMap, names []string) (*template.Template, error) {
+	return template.New(path.Base(names[0])).Funcs(funcs).ParseFS(files, names...)
+}
+
+// templateStamps When each template was last changed, a template that cannot be found having no time
+func templateStamps(files fs.FS, names []string) []time.Time {
+	stamps := make([]time.Time, len(names))
+	for i, name := range names {
+		if info, err := fs.Stat(files, name); err == nil {
+			stamps[i] = info.ModTime()
+		}
+	}
+
+	return stamps
+}
+
+// sameStamps Check whether templates were last changed when they were before
+func sameStamps(a []time.Time, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
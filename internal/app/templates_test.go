@@ -0,0 +1,108 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTemplate(t *testing.T, dir string, name string, content string, changed time.Time) {
+	path := filepath.Join(dir, "templates", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, changed, changed); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateCache_Get(t *testing.T) {
+	cache := NewTemplateCache(false)
+	container := &Container{Configuration: DefaultConfiguration(), TemplateCache: cache}
+
+	first, err := container.Templates("_layout/default.tmpl", "index.tmpl")
+	if err != nil {
+		t.Fatalf("Expected page to be parsed, got %s", err)
+	}
+	second, _ := container.Templates("_layout/default.tmpl", "index.tmpl")
+	if first != second {
+		t.Error("Expected page to be parsed once and kept")
+	}
+
+	// Each base path is given its own, as the functions of its templates find files beneath it
+	tenant := *container
+	tenant.BasePath = "/tenant"
+	other, _ := tenant.Templates("_layout/default.tmpl", "index.tmpl")
+	if other == first {
+		t.Error("Expected another base path to be given its own page")
+	}
+
+	if _, err := container.Templates("_layout/default.tmpl", "missing.tmpl"); err == nil {
+		t.Error("Expected a missing template to be reported")
+	}
+}
+
+func TestTemplateCache_GetReload(t *testing.T) {
+	dir := t.TempDir()
+	changed := time.Now().Add(-time.Hour)
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}First{{end}}`, changed)
+	configuration := DefaultConfiguration()
+	configuration.WebPath = dir
+
+	kept := &Container{Configuration: configuration, TemplateCache: NewTemplateCache(false)}
+	reloaded := &Container{Configuration: configuration, TemplateCache: NewTemplateCache(true)}
+	for _, container := range []*Container{kept, reloaded} {
+		if _, err := container.Templates("_layout/default.tmpl", "index.tmpl"); err != nil {
+			t.Fatalf("Expected page to be parsed, got %s", err)
+		}
+	}
+
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}Second{{end}}`, changed.Add(time.Minute))
+	page := strings.Builder{}
+	template, _ := kept.Templates("_layout/default.tmpl", "index.tmpl")
+	template.ExecuteTemplate(&page, "content", nil)
+	if page.String() != "First" {
+		t.Errorf("Expected page to be kept without reloading, got %s", page.String())
+	}
+	page.Reset()
+	template, _ = reloaded.Templates("_layout/default.tmpl", "index.tmpl")
+	template.ExecuteTemplate(&page, "content", nil)
+	if page.String() != "Second" {
+		t.Errorf("Expected page to be parsed again once changed, got %s", page.String())
+	}
+
+	// A page that cannot be parsed is reported each time until it is put right
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}{{.Broken`, changed.Add(2*time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := reloaded.Templates("_layout/default.tmpl", "index.tmpl"); err == nil {
+			t.Error("Expected broken template to be reported")
+		}
+	}
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}Third{{end}}`, changed.Add(3*time.Minute))
+	if _, err := reloaded.Templates("_layout/default.tmpl", "index.tmpl"); err != nil {
+		t.Errorf("Expected page to be parsed once put right, got %s", err)
+	}
+}
+
+func TestTemplateCache_Preload(t *testing.T) {
+	cache := NewTemplateCache(false)
+	if errs := cache.Preload(&Container{Configuration: DefaultConfiguration()}); len(errs) > 0 {
+		t.Errorf("Expected every page built in to be parsed, got %v", errs)
+	}
+	if len(cache.pages) == 0 {
+		t.Error("Expected pages to be kept")
+	}
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}{{end`, time.Now())
+	configuration := DefaultConfiguration()
+	configuration.WebPath = dir
+	if errs := NewTemplateCache(false).Preload(&Container{Configuration: configuration}); len(errs) != 1 {
+		t.Errorf("Expected the broken page to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,149 @@
+package tenant
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+)
+
+// Resolver Finds the tenant for each request and serves it from its own isolated database
+type Resolver struct {
+	Container *app.Container
+	Connect   func(path string) (app.Database, error)
+	dbs       map[string]app.Database
+	mutex     sync.Mutex
+	settings  map[string]*app.Settings
+}
+
+// NewResolver Create a resolver backed by one SQLite file per tenant
+func NewResolver(container *app.Container) *Resolver {
+	return &Resolver{
+		Container: container,
+		Connect: func(path string) (app.Database, error) {
+			db := &database.Sqlite{Pool: container.Configuration.DatabasePool(), Pragmas: container.Configuration.SqlitePragmas()}
+			err := db.Connect(path)
+			return db, err
+		},
+		dbs:      map[string]app.Database{},
+		settings: map[string]*app.Settings{},
+	}
+}
+
+// Middleware Swap the container for the tenant's own when a request belongs to one
+func (r *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		name, path := r.Resolve(request)
+		if name == "" {
+			next.ServeHTTP(response, request)
+			return
+		}
+
+		ts := model.Tenants{Container: r.Container}
+		tenant := ts.FindByName(name)
+		if tenant.ID == 0 {
+			if r.Container.Configuration.TenantMode == app.TenantModePath {
+				next.ServeHTTP(response, request)
+			} else {
+				web.RunBadRequest(response, request, r.Container)
+			}
+			return
+		}
+
+		container, err := r.Open(tenant)
+		if err != nil {
+			http.Error(response, "Journal unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if path != request.URL.Path {
+			request.URL.Path = path
+		}
+		ctx := logging.NewContext(request.Context(), logging.FromContext(request.Context()).With("tenant", tenant.Name))
+		next.ServeHTTP(response, pkgrouter.WithContainer(request.WithContext(ctx), container))
+	})
+}
+
+// Resolve Work out the tenant name and the path within its journal
+func (r *Resolver) Resolve(request *http.Request) (string, string) {
+	path := request.URL.Path
+	switch r.Container.Configuration.TenantMode {
+	case app.TenantModeSubdomain:
+		host := strings.ToLower(strings.Split(request.Host, ":")[0])
+		suffix := "." + r.Container.Configuration.TenantDomain
+		if r.Container.Configuration.TenantDomain == "" || !strings.HasSuffix(host, suffix) {
+			return "", path
+		}
+		return strings.TrimSuffix(host, suffix), path
+	case app.TenantModePath:
+		parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+		if parts[0] == "" {
+			return "", path
+		}
+		if len(parts) == 1 {
+			return parts[0], "/"
+		}
+		return parts[0], "/" + parts[1]
+	}
+
+	return "", path
+}
+
+// Open Build the container for a tenant, connecting and preparing its database and reading its settings on first use
+func (r *Resolver) Open(tenant model.Tenant) (*app.Container, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	db, ok := r.dbs[tenant.Name]
+	if !ok {
+		if err := os.MkdirAll(r.Container.Configuration.TenantPath, 0755); err != nil {
+			return nil, err
+		}
+		var err error
+		db, err = r.Connect(r.Container.Configuration.TenantPath + "/" + tenant.Name + ".db")
+		if err != nil {
+			return nil, err
+		}
+		if _, err = model.Migrate(&app.Container{Db: db}); err != nil {
+			return nil, err
+		}
+		ss := model.Settings{Container: &app.Container{Db: db}}
+		r.dbs[tenant.Name] = db
+		r.settings[tenant.Name] = ss.Load()
+	}
+
+	container := *r.Container
+	container.Db = db
+	container.Replica = nil
+	container.Settings = r.settings[tenant.Name]
+	container.Tenant = tenant.Name
+	container.Logger = r.Container.Log().With("tenant", tenant.Name)
+	container.Configuration.Title = tenant.Title
+	if container.Configuration.TenantMode == app.TenantModePath {
+		container.BasePath = r.Container.BasePath + "/" + tenant.Name
+	}
+
+	return &container, nil
+}
+
+// OpenTenant Build the container for a tenant by its name and title, such as one just registered
+func (r *Resolver) OpenTenant(name string, title string) (*app.Container, error) {
+	return r.Open(model.Tenant{Name: name, Title: title})
+}
+
+// Close Close every tenant database that has been opened
+func (r *Resolver) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for name, db := range r.dbs {
+		db.Close()
+		delete(r.dbs, name)
+		delete(r.settings, name)
+	}
+}
@@ -0,0 +1,178 @@
+package tenant
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/test/mocks/controller"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+type capture struct {
+	path   string
+	served bool
+}
+
+func (c *capture) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	c.served = true
+	c.path = request.URL.Path
+}
+
+func newResolver(mode string) (*Resolver, *database.MockSqlite, *database.MockSqlite) {
+	main := &database.MockSqlite{}
+	tenantDb := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	configuration := app.DefaultConfiguration()
+	configuration.TenantMode = mode
+	configuration.TenantDomain = "example.com"
+	configuration.TenantPath = os.TempDir() + "/journal-tenants"
+	resolver := NewResolver(&app.Container{Configuration: configuration, Db: main})
+	resolver.Connect = func(path string) (app.Database, error) {
+		if strings.HasSuffix(path, "broken.db") {
+			return nil, errors.New("Simulated error")
+		}
+		return tenantDb, nil
+	}
+
+	return resolver, main, tenantDb
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	tables := []struct {
+		mode string
+		host string
+		path string
+		name string
+		rest string
+	}{
+		{app.TenantModeSubdomain, "alice.example.com", "/slug", "alice", "/slug"},
+		{app.TenantModeSubdomain, "alice.example.com:3000", "/", "alice", "/"},
+		{app.TenantModeSubdomain, "example.com", "/slug", "", "/slug"},
+		{app.TenantModeSubdomain, "alice.other.com", "/slug", "", "/slug"},
+		{app.TenantModePath, "example.com", "/alice/slug/edit", "alice", "/slug/edit"},
+		{app.TenantModePath, "example.com", "/alice", "alice", "/"},
+		{app.TenantModePath, "example.com", "/", "", "/"},
+		{"", "alice.example.com", "/alice/slug", "", "/alice/slug"},
+	}
+
+	for _, table := range tables {
+		resolver, _, _ := newResolver(table.mode)
+		request, _ := http.NewRequest("GET", table.path, nil)
+		request.Host = table.host
+		name, rest := resolver.Resolve(request)
+		if name != table.name || rest != table.rest {
+			t.Errorf("Expected Resolve() of %s%s to produce '%s' and '%s', got '%s' and '%s'", table.host, table.path, table.name, table.rest, name, rest)
+		}
+	}
+}
+
+func TestResolver_Middleware(t *testing.T) {
+	// Test request with no tenant passes straight through
+	resolver, main, _ := newResolver(app.TenantModePath)
+	next := &capture{}
+	response := controller.NewMockResponse()
+	request, _ := http.NewRequest("GET", "/", nil)
+	resolver.Middleware(next).ServeHTTP(response, request)
+	if !next.served || next.path != "/" {
+		t.Error("Expected request without a tenant to have been served")
+	}
+
+	// Test unknown tenant in path mode falls through to the main journal
+	next = &capture{}
+	main.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("GET", "/some-slug", nil)
+	resolver.Middleware(next).ServeHTTP(response, request)
+	if !next.served || next.path != "/some-slug" {
+		t.Error("Expected unknown tenant to fall through in path mode")
+	}
+
+	// Test known tenant has its path rewritten and its own container
+	ctrl := &controller.MockController{}
+	rtr := &pkgrouter.Router{Container: resolver.Container, ErrorController: &controller.MockController{}}
+	rtr.Get("/slug", ctrl)
+	main.Rows = &database.MockTenant_SingleRow{}
+	request, _ = http.NewRequest("GET", "/alice/slug", nil)
+	resolver.Middleware(rtr).ServeHTTP(response, request)
+	if !ctrl.HasRun {
+		t.Error("Expected tenant request to have been rewritten")
+	}
+	if container, ok := ctrl.Container.(*app.Container); !ok || container.Tenant != "alice" {
+		t.Error("Expected tenant container to have been passed to the controller")
+	}
+
+	// Test unknown tenant in subdomain mode is not found
+	resolver, main, _ = newResolver(app.TenantModeSubdomain)
+	next = &capture{}
+	response.Reset()
+	main.Rows = &database.MockRowsEmpty{}
+	request, _ = http.NewRequest("GET", "/", nil)
+	request.Host = "nobody.example.com"
+	resolver.Middleware(next).ServeHTTP(response, request)
+	if next.served || response.StatusCode != 404 {
+		t.Error("Expected unknown tenant to be not found")
+	}
+
+	// Test database that cannot be opened
+	next = &capture{}
+	response.Reset()
+	main.Rows = &database.MockTenant_SingleRow{}
+	resolver.Connect = func(path string) (app.Database, error) {
+		return nil, errors.New("Simulated error")
+	}
+	request, _ = http.NewRequest("GET", "/", nil)
+	request.Host = "alice.example.com"
+	resolver.Middleware(next).ServeHTTP(response, request)
+	if next.served || response.StatusCode != 503 {
+		t.Error("Expected unavailable error when tenant database cannot be opened")
+	}
+}
+
+func TestResolver_Open(t *testing.T) {
+	schema := &database.MockSqlite{Rows: &database.MockRowsEmpty{}}
+	model.Migrate(&app.Container{Db: schema})
+	resolver, _, tenantDb := newResolver(app.TenantModePath)
+	resolver.Container.BasePath = "/journal"
+	resolver.Container.Replica = &database.MockSqlite{}
+	resolver.Container.Settings = app.NewSettings(map[string]string{app.SettingTitle: "Jamie's Journal"})
+
+	container, err := resolver.Open(model.Tenant{Name: "alice", Title: "Alice's Journal"})
+	if err != nil {
+		t.Fatal("Expected tenant to have been opened")
+	}
+	if container.Db != tenantDb || container.Replica != nil || container.Settings == nil || container.Settings == resolver.Container.Settings || container.Tenant != "alice" || container.Configuration.Title != "Alice's Journal" || container.BasePath != "/journal/alice" {
+		t.Errorf("Expected tenant container to have been built, got %+v", container)
+	}
+	if resolver.Container.Tenant != "" || resolver.Container.Configuration.Title == "Alice's Journal" {
+		t.Error("Expected main container to have been left untouched")
+	}
+	if tenantDb.Queries != schema.Queries+1 {
+		t.Error("Expected tenant tables to have been created and its settings read")
+	}
+
+	// Test database and settings are reused
+	reused, _ := resolver.Open(model.Tenant{Name: "alice", Title: "Alice's Journal"})
+	if tenantDb.Queries != schema.Queries+1 || reused.Settings != container.Settings {
+		t.Error("Expected tenant database and settings to have been reused")
+	}
+
+	// Test connection failure
+	if _, err = resolver.Open(model.Tenant{Name: "broken"}); err == nil {
+		t.Error("Expected error when connection fails")
+	}
+
+	// Test schema failure
+	tenantDb.ErrorMode = true
+	if _, err = resolver.Open(model.Tenant{Name: "bob"}); err == nil {
+		t.Error("Expected error when tables cannot be created")
+	}
+
+	resolver.Close()
+	if !tenantDb.Closed {
+		t.Error("Expected tenant databases to have been closed")
+	}
+}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/pkg/adapter/webhook"
+)
+
+// JobType Name of the background job that sends an event to a webhook
+const JobType = "webhook"
+
+// Events a webhook can be told about
+const (
+	EventCreated   = "entry.created"
+	EventUpdated   = "entry.updated"
+	EventDeleted   = "entry.deleted"
+	EventPublished = "entry.published"
+)
+
+// Events Every event a webhook can be told about
+var Events = []string{EventCreated, EventUpdated, EventDeleted, EventPublished}
+
+// Payload An event to send to one webhook, stored with the queued job
+type Payload struct {
+	Tenant    string          `json:"tenant,omitempty"`
+	WebhookID int             `json:"webhook_id"`
+	Event     string          `json:"event"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// Entry The entry an event is about, with the fields kept out of the public API
+type Entry struct {
+	model.Journal
+	Status     string `json:"status"`
+	Visibility string `json:"visibility"`
+	DeletedAt  string `json:"deleted_at,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// Body The JSON posted to a webhook
+type Body struct {
+	Event      string `json:"event"`
+	OccurredAt string `json:"occurred_at"`
+	Entry      Entry  `json:"entry"`
+}
+
+// IsEvent Check whether an event is one a webhook can be told about
+func IsEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Notify Queue an event about an entry for every webhook that wants to hear about it
+func Notify(container *app.Container, event string, journal model.Journal) error {
+	if journal.ID == 0 {
+		return nil
+	}
+	ws := model.Webhooks{Container: container}
+	webhooks := ws.FetchForEvent(event)
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	status := journal.Status
+	if status == "" {
+		status = model.JournalStatusPublished
+	}
+	visibility := journal.Visibility
+	if visibility == "" {
+		visibility = model.JournalVisibilityPublic
+	}
+	entry := Entry{Journal: journal, Status: status, Visibility: visibility, DeletedAt: journal.DeletedAt}
+	if journal.Slug != "" {
+		entry.URL = container.URL("/" + journal.Slug)
+	}
+	body, err := json.Marshal(Body{Event: event, OccurredAt: time.Now().UTC().Format(time.RFC3339), Entry: entry})
+	if err != nil {
+		return err
+	}
+
+	js := model.Jobs{Container: container}
+	for _, w := range webhooks {
+		if _, err := js.Enqueue(JobType, Payload{Tenant: container.Tenant, WebhookID: w.ID, Event: event, Body: body}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Created Queue the events for a new entry, which is also published when it was not saved as a draft or for later
+func Created(container *app.Container, journal model.Journal) error {
+	if err := Notify(container, EventCreated, journal); err != nil {
+		return err
+	}
+	if journal.IsPublished() {
+		return Notify(container, EventPublished, journal)
+	}
+
+	return nil
+}
+
+// Updated Queue the events for a changed entry, which is also published when it was not before
+func Updated(container *app.Container, previous model.Journal, journal model.Journal) error {
+	if err := Notify(container, EventUpdated, journal); err != nil {
+		return err
+	}
+	if !previous.IsPublished() && journal.IsPublished() {
+		return Notify(container, EventPublished, journal)
+	}
+
+	return nil
+}
+
+// Deleted Queue the event for an entry that has been moved to the trash
+func Deleted(container *app.Container, journal model.Journal) error {
+	return Notify(container, EventDeleted, journal)
+}
+
+// Published Queue the event for an entry published once the time it was scheduled for had passed
+func Published(container *app.Container, journal model.Journal) error {
+	return Notify(container, EventPublished, journal)
+}
+
+// Handler Build the job handler sending a queued event to its webhook and recording how it went, which when open is
+// given may belong to one of the hosted journals
+func Handler(open func(model.Tenant) (*app.Container, error)) queue.Handler {
+	return func(container *app.Container, job model.Job) error {
+		payload := Payload{}
+		if err := job.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Tenant != "" && open != nil {
+			ts := model.Tenants{Container: container}
+			t := ts.FindByName(payload.Tenant)
+			if t.ID == 0 {
+				return nil
+			}
+			hosted, err := open(t)
+			if err != nil {
+				return err
+			}
+			container = hosted
+		}
+
+		// The webhook may have been removed since the event was queued
+		ws := model.Webhooks{Container: container}
+		w := ws.FindByID(payload.WebhookID)
+		if w.ID == 0 {
+			return nil
+		}
+
+		client := webhook.Client{}
+		status, err := client.Deliver(w.URL, w.Secret, payload.Event, strconv.Itoa(job.ID), payload.Body)
+		delivery := model.WebhookDelivery{WebhookID: w.ID, Event: payload.Event, Payload: string(payload.Body), StatusCode: status, Attempt: job.Attempts}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		ds := model.WebhookDeliveries{Container: container}
+		ds.Record(delivery)
+
+		return err
+	}
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/pkg/adapter/webhook"
+	"github.com/jamiefdhurst/journal/test/mocks/database"
+)
+
+func TestIsEvent(t *testing.T) {
+	for _, event := range Events {
+		if !IsEvent(event) {
+			t.Errorf("Expected %s to be an event", event)
+		}
+	}
+	if IsEvent("entry.read") {
+		t.Error("Expected unknown event to be refused")
+	}
+}
+
+func TestNotify(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	container.Configuration.URL = "https://example.com"
+
+	// Test nothing queued for an entry that was never saved
+	if err := Notify(container, EventCreated, model.Journal{}); err != nil || db.Queries != 0 {
+		t.Error("Expected nothing to be queued for an unsaved entry")
+	}
+
+	// Test nothing queued without webhooks
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Notify(container, EventCreated, model.Journal{ID: 1, Slug: "test"}); err != nil || db.Queries != 1 {
+		t.Error("Expected nothing to be queued without webhooks")
+	}
+
+	// Test queued once for each webhook wanting the event
+	db.Queries = 0
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	if err := Notify(container, EventPublished, model.Journal{ID: 1, Slug: "test", Title: "Test"}); err != nil || db.Queries != 3 {
+		t.Errorf("Expected an event to be queued for both webhooks, got %d queries", db.Queries)
+	}
+	db.Queries = 0
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	if err := Notify(container, EventDeleted, model.Journal{ID: 1, Slug: "test"}); err != nil || db.Queries != 2 {
+		t.Errorf("Expected an event to be queued for the webhook told about everything, got %d queries", db.Queries)
+	}
+
+	// Test error
+	db.Rows = &database.MockWebhook_MultipleRows{}
+	db.ErrorAtQuery = db.Queries + 2
+	if err := Notify(container, EventDeleted, model.Journal{ID: 1, Slug: "test"}); err == nil {
+		t.Error("Expected error to be returned")
+	}
+}
+
+func TestCreatedAndUpdated(t *testing.T) {
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	db.Rows = &database.MockRowsEmpty{}
+
+	tables := []struct {
+		name    string
+		notify  func() error
+		queries int
+	}{
+		{"new draft", func() error { return Created(container, model.Journal{ID: 1, Status: model.JournalStatusDraft}) }, 1},
+		{"new entry", func() error { return Created(container, model.Journal{ID: 1}) }, 2},
+		{"edited entry", func() error { return Updated(container, model.Journal{ID: 1}, model.Journal{ID: 1}) }, 1},
+		{"published draft", func() error {
+			return Updated(container, model.Journal{ID: 1, Status: model.JournalStatusDraft}, model.Journal{ID: 1})
+		}, 2},
+		{"deleted entry", func() error { return Deleted(container, model.Journal{ID: 1}) }, 1},
+		{"scheduled entry", func() error { return Published(container, model.Journal{ID: 1}) }, 1},
+	}
+
+	for _, table := range tables {
+		db.Queries = 0
+		if err := table.notify(); err != nil || db.Queries != table.queries {
+			t.Errorf("Expected %d event(s) to be looked up for a %s, got %d", table.queries, table.name, db.Queries)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	db := &database.MockSqlite{Result: &database.MockResult{}}
+	container := &app.Container{Configuration: app.DefaultConfiguration(), Db: db}
+	payload, _ := json.Marshal(Payload{WebhookID: 1, Event: EventCreated, Body: json.RawMessage(`{"event":"entry.created"}`)})
+	job := model.Job{ID: 7, Payload: string(payload), Attempts: 1}
+
+	// Test invalid payload
+	if err := Handler(nil)(container, model.Job{Payload: "{"}); err == nil {
+		t.Error("Expected error for invalid payload")
+	}
+
+	// Test webhooks that have since gone are skipped
+	db.Rows = &database.MockRowsEmpty{}
+	if err := Handler(nil)(container, job); err != nil || received != nil {
+		t.Errorf("Expected job for a missing webhook to be dropped, got %v", err)
+	}
+
+	// Test event delivered, signed, and recorded
+	db.Queries = 0
+	db.Rows = &database.MockWebhook_SingleRow{URL: server.URL}
+	if err := Handler(nil)(container, job); err != nil || db.Queries != 2 {
+		t.Errorf("Expected event to be delivered and recorded, got %v", err)
+	}
+	if string(body) != `{"event":"entry.created"}` || received.Header.Get("X-Journal-Delivery") != "7" || !webhook.Verify("secret", body, received.Header.Get("X-Journal-Signature")) {
+		t.Error("Expected body to be signed with the secret of the webhook")
+	}
+
+	// Test hosted journals look up their own webhooks
+	hostedDb := &database.MockSqlite{Result: &database.MockResult{}}
+	hostedDb.Rows = &database.MockWebhook_SingleRow{URL: server.URL + "/hosted"}
+	open := func(tenant model.Tenant) (*app.Container, error) {
+		return &app.Container{Configuration: container.Configuration, Db: hostedDb, Tenant: tenant.Name}, nil
+	}
+	db.Rows = &database.MockTenant_SingleRow{}
+	hostedPayload, _ := json.Marshal(Payload{Tenant: "alice", WebhookID: 1, Event: EventCreated, Body: json.RawMessage(`{}`)})
+	if err := Handler(open)(container, model.Job{Payload: string(hostedPayload)}); err != nil || received.URL.Path != "/hosted" || hostedDb.Queries != 2 {
+		t.Errorf("Expected event to be delivered for the hosted journal, got %v", err)
+	}
+
+	// Test refused delivery is recorded and retried
+	status = http.StatusInternalServerError
+	db.Queries = 0
+	db.Rows = &database.MockWebhook_SingleRow{URL: server.URL}
+	if err := Handler(nil)(container, job); err == nil || db.Queries != 2 {
+		t.Error("Expected refused delivery to be recorded and an error returned")
+	}
+}
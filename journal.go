@@ -1,76 +1,687 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/jamiefdhurst/journal/pkg/adapter/giphy"
 	"github.com/jamiefdhurst/journal/pkg/adapter/json"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/backup"
+	"github.com/jamiefdhurst/journal/internal/app/blogroll"
+	"github.com/jamiefdhurst/journal/internal/app/canonical"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/cron"
+	"github.com/jamiefdhurst/journal/internal/app/email"
+	"github.com/jamiefdhurst/journal/internal/app/export"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/flatfile"
+	"github.com/jamiefdhurst/journal/internal/app/headers"
+	"github.com/jamiefdhurst/journal/internal/app/importer"
+	"github.com/jamiefdhurst/journal/internal/app/maintenance"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/ping"
+	"github.com/jamiefdhurst/journal/internal/app/purge"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/internal/app/ratelimit"
 	"github.com/jamiefdhurst/journal/internal/app/router"
+	"github.com/jamiefdhurst/journal/internal/app/schedule"
+	"github.com/jamiefdhurst/journal/internal/app/telegram"
+	"github.com/jamiefdhurst/journal/internal/app/tenant"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/certificate"
 	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/graceful"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
+	"github.com/jamiefdhurst/journal/pkg/smtpd"
 )
 
+// settingFlags Flags overriding settings, by the env variable of the setting each overrides
+var settingFlags = map[string]string{
+	"base-path":      "J_BASE_PATH",
+	"create":         "J_CREATE",
+	"dev":            "J_DEV",
+	"domain":         "J_TLS_DOMAIN",
+	"edit":           "J_EDIT",
+	"maintenance":    "J_MAINTENANCE",
+	"media-path":     "J_MEDIA_PATH",
+	"oidc-client-id": "J_OIDC_CLIENT_ID",
+	"oidc-provider":  "J_OIDC_PROVIDER",
+	"port":           "J_PORT",
+	"socket":         "J_SOCKET",
+	"theme":          "J_THEME",
+	"title":          "J_TITLE",
+	"tls":            "J_TLS",
+	"url":            "J_URL",
+}
+
 func main() {
 	const version = "0.3.0.1"
 
-	// Set CWD
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
-	fmt.Printf("Journal v%s\n-------------------\n\n", version)
+	mode := flag.String("mode", "serve", "What to run: serve, migrate to bring the database up to date and stop, rollback to undo its latest migration, backup to copy the database into a directory, restore to replace it with a backup, check to look for problems in the database, config-check to look for problems in the settings, export to write the journal out as a static site, or import to add entries from Markdown files or a WordPress export")
+	driver := flag.String("db", database.DialectSqlite, "Database to keep the journal in: sqlite, postgres or mysql")
+	dsn := flag.String("dsn", "", "Connection string for the database, defaulting to the J_DB_PATH file for SQLite")
+	readDSN := flag.String("read-dsn", "", "Connection string for a read-only replica of the database to read pages from while serving, such as one kept by Litestream")
+	dir := flag.String("dir", "", "Directory to export into, import Markdown files from or back up into, defaulting to J_BACKUP_PATH for backups")
+	format := flag.String("format", "html", "What to export: html for a static site, or markdown for Jekyll and Hugo")
+	file := flag.String("file", "", "WordPress export (WXR) file to import, or backup to restore")
+	dryRun := flag.Bool("dry-run", false, "Report what an import would create without saving anything")
+	fix := flag.Bool("fix", false, "Put right what a check finds wrong when it can be done safely")
+	configFile := flag.String("config", os.Getenv("J_CONFIG"), "TOML or YAML file to read settings from, each named like its env variable without J_, such as port or db_path, defaulting to J_CONFIG - env variables and flags override it")
+	flag.String("base-path", "", "Path to serve the journal beneath, such as /journal, with every link, redirect and asset kept within it, overriding J_BASE_PATH")
+	flag.Bool("create", true, "Allow entries to be created, overriding J_CREATE")
+	flag.Bool("dev", false, "Develop templates and themes, reading them from ./web unless J_WEB_PATH is set, parsing them again as they change and showing why a page could not be rendered, overriding J_DEV")
+	flag.String("domain", "", "Comma separated host names to obtain certificates for from Let's Encrypt when serving over TLS, overriding J_TLS_DOMAIN")
+	flag.Bool("edit", true, "Allow entries to be edited, overriding J_EDIT")
+	flag.Bool("maintenance", false, "Start in maintenance, refusing changes until it is switched off at /admin/maintenance or with SIGUSR1, overriding J_MAINTENANCE")
+	flag.String("media-path", "", "Directory to keep uploaded media in, overriding J_MEDIA_PATH")
+	flag.String("oidc-provider", "", "Provider to sign in with instead of a password: google, github or the URL of an OpenID Connect issuer, overriding J_OIDC_PROVIDER")
+	flag.String("oidc-client-id", "", "Client ID registered with the provider to sign in with, overriding J_OIDC_CLIENT_ID")
+	flag.String("port", "", "Port to listen on, overriding J_PORT")
+	flag.String("socket", "", "Unix domain socket to listen on instead of the port, such as /run/journal/journal.sock for a proxy on the same host, overriding J_SOCKET")
+	flag.String("theme", "", "Theme in web/themes, or stylesheet in web/static/css, to render pages with, overriding J_THEME")
+	flag.String("title", "", "Title of the journal, overriding J_TITLE")
+	flag.Bool("tls", false, "Serve HTTPS directly, with certificates from J_TLS_CERT and J_TLS_KEY or obtained for -domain, overriding J_TLS")
+	flag.String("url", "", "Public URL of the journal, used when building absolute links, overriding J_URL")
+	flag.Parse()
+	if *mode == "restore" && *file == "" {
+		logging.Fatal("A backup must be given with -file to restore")
+	}
+	if *mode != "serve" && *mode != "migrate" && *mode != "rollback" && *mode != "backup" && *mode != "restore" && *mode != "check" && *mode != "config-check" && *dir == "" && (*mode != "import" || *file == "") {
+		logging.Fatal("A directory must be given with -dir", "mode", *mode)
+	}
+	if *format != "html" && *format != "markdown" {
+		logging.Fatal("Unknown format, expected html or markdown", "format", *format)
+	}
 
-	// Define default configuration
+	// Define default configuration, overridden by any file, then the env, then flags
 	configuration := app.DefaultConfiguration()
+	if *configFile != "" {
+		logging.Info("Reading settings", "path", *configFile)
+		if err := app.ApplyFileConfiguration(&configuration, *configFile); err != nil {
+			logging.Fatal("Could not read the settings", "err", err)
+		}
+	}
 	app.ApplyEnvConfiguration(&configuration)
+	flags := map[string]interface{}{}
+	flag.Visit(func(f *flag.Flag) {
+		if name, ok := settingFlags[f.Name]; ok {
+			flags[name] = f.Value.(flag.Getter).Get()
+		}
+	})
+	app.ApplySettings(&configuration, flags)
+	if configuration.Dev && configuration.WebPath == "" {
+		if info, err := os.Stat("web"); err == nil && info.IsDir() {
+			configuration.WebPath = "web"
+		}
+	}
+
+	// Log as configured from here on, including anything still written through the log package, such as by net/http
+	level, _ := logging.ParseLevel(configuration.LogLevel)
+	var logOutput io.Writer = os.Stderr
+	if configuration.LogFile != "" {
+		logFile, err := configuration.OpenLog(configuration.LogFile)
+		if err != nil {
+			logging.Fatal("Could not open the log file", "path", configuration.LogFile, "err", err)
+		}
+		defer logFile.Close()
+		logOutput = logFile
+	}
+	logging.SetDefault(logging.New(logOutput, configuration.LogFormat, level))
+	log.SetFlags(0)
+	log.SetOutput(logging.Default().Writer(logging.LevelInfo))
+	logging.Info("Starting the journal", "version", version, "mode", *mode)
+
+	problems := configuration.Validate()
+	for _, problem := range problems {
+		logging.Error("Invalid settings", "err", problem)
+	}
+	if *mode == "config-check" {
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		logging.Info("The settings are valid")
+		return
+	}
+	if len(problems) > 0 {
+		logging.Fatal("Could not start with these settings")
+	}
+	if missing := configuration.MissingTemplates(); len(missing) > 0 {
+		logging.Info("Rendering pages the theme leaves out with the templates built in", "theme", configuration.Theme, "templates", missing)
+	}
 
 	// Create/define container
 	container := &app.Container{
+		BasePath:      configuration.BasePath,
 		Configuration: configuration,
 		Version:       version,
 	}
 
 	// Open database
-	db := &database.Sqlite{}
-	log.Printf("Loading DB from %s...\n", configuration.DatabasePath)
-	if err := db.Connect(configuration.DatabasePath); err != nil {
-		log.Printf("Database error - please verify that the %s path is available and writable.\n", configuration.DatabasePath)
+	db, err := database.New(*driver, configuration.DatabasePool())
+	if err != nil {
+		logging.Fatal("Could not use the database", "err", err)
+	}
+	if sqlite, ok := db.(*database.Sqlite); ok {
+		sqlite.Pragmas = configuration.SqlitePragmas()
+	}
+	dialect := database.DialectOf(db)
+	if dialect == database.DialectSqlite {
+		if *dsn == "" {
+			*dsn = configuration.DatabasePath
+		}
+		if *dsn == database.Memory {
+			logging.Info("Keeping the journal in memory, where it is lost once stopped")
+		} else {
+			logging.Info("Loading DB", "path", *dsn)
+		}
+	} else {
+		if *dsn == "" {
+			logging.Fatal("A connection string must be given with -dsn", "db", dialect)
+		}
+		logging.Info("Connecting to the database", "db", dialect)
+	}
+	if *mode == "restore" {
+		if dialect != database.DialectSqlite {
+			logging.Fatal(backup.ErrNotSqlite.Error())
+		}
+		if *dsn == database.Memory {
+			logging.Fatal("A database kept in memory starts empty each time, so cannot be restored into")
+		}
+		if err := backup.Restore(*file, *dsn); err != nil {
+			logging.Fatal("Could not restore the backup", "err", err)
+		}
+		logging.Info("Restored the backup, keeping the database it replaced", "backup", *file, "replaced", *dsn+".before-restore")
+		return
+	}
+	if dialect == database.DialectSqlite && *dsn != database.Memory && !strings.HasPrefix(*dsn, "file:") {
+		// Create the directory of a database being created, so that a journal run for the first time can open it
+		if err := os.MkdirAll(filepath.Dir(*dsn), 0755); err != nil {
+			logging.Warn("Could not create the directory of the database", "path", *dsn, "err", err)
+		}
+	}
+	if err := db.Connect(*dsn); err != nil {
+		if dialect == database.DialectSqlite {
+			logging.Error("Database error - please verify that the path is available and writable", "path", *dsn)
+		} else {
+			logging.Error("Database error - please verify that the database can be reached", "db", dialect, "err", err)
+		}
 		os.Exit(1)
 	}
 
 	// Create Giphy adapter
-	giphyAPIKey := os.Getenv("J_GIPHY_API_KEY")
-	if giphyAPIKey != "" {
-		log.Println("Enabling GIPHY client...")
-		container.Giphy = &giphy.Client{APIKey: giphyAPIKey, Client: &json.Client{}}
+	if configuration.GiphyAPIKey != "" {
+		logging.Info("Enabling GIPHY client")
+		container.Giphy = &giphy.Client{APIKey: configuration.GiphyAPIKey, Client: &json.Client{}}
 	}
 
-	// Create table if required
 	container.Db = db
-	var err error
-	js := model.Journals{Container: container}
-	if err = js.CreateTable(); err != nil {
-		log.Panicln(err)
+	container.Queue = db
+	if *mode == "rollback" {
+		migration, err := model.Rollback(container)
+		db.Close()
+		if err != nil {
+			logging.Fatal("Could not roll back", "err", err)
+		}
+		logging.Info("Rolled back migration", "version", migration.Version, "name", migration.Name)
+		return
 	}
 
+	// Bring the database up to date
+	applied, err := model.Migrate(container)
+	for _, migration := range applied {
+		logging.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	if err != nil {
+		db.Close()
+		logging.Fatal("Could not bring the database up to date", "err", err)
+	}
+
+	// Unlock the content of entries when the database is encrypted, or encrypt it when a passphrase is first given
+	sealer, err := model.Unlock(container, configuration.Passphrase)
+	if err != nil {
+		db.Close()
+		logging.Fatal("Could not unlock the database", "err", err)
+	}
+	if sealer != nil {
+		container.Sealer = sealer
+		sealed, err := model.SealExisting(container)
+		if err != nil {
+			db.Close()
+			logging.Fatal("Could not encrypt the database", "err", err)
+		}
+		if sealed > 0 {
+			logging.Info("Encrypted entries, revisions and autosaves", "count", sealed)
+		}
+	}
+
+	// Use the settings saved in the database, such as the title chosen while setting the journal up
+	settings := model.Settings{Container: container}
+	container.Settings = settings.Load()
+	container.Configuration = container.Settings.Apply(container.Configuration)
+
+	switch *mode {
+	case "serve":
+	case "migrate":
+		db.Close()
+		logging.Info("The database is up to date")
+		return
+	case "backup":
+		if *dir == "" {
+			*dir = configuration.BackupPath
+		}
+		if *dir == "" {
+			db.Close()
+			logging.Fatal("A directory must be given with -dir or J_BACKUP_PATH to back up into")
+		}
+		_, err = backup.Backup(container, *dir)
+		db.Close()
+		if err != nil {
+			logging.Fatal("Could not back up the database", "err", err)
+		}
+		return
+	case "check":
+		logging.Info("Checking the database")
+		problems, err := model.CheckIntegrity(container, *fix)
+		db.Close()
+		unfixed := logProblems(problems, *fix)
+		if err != nil {
+			logging.Fatal("Error reported", "err", err)
+		}
+		if unfixed > 0 {
+			os.Exit(1)
+		}
+		return
+	case "export":
+		var written int
+		if *format == "markdown" {
+			logging.Info("Exporting Markdown files", "dir", *dir)
+			written, err = export.Markdown(container, *dir)
+		} else {
+			logging.Info("Exporting static site", "dir", *dir)
+			written, err = export.Static(container, router.NewRouter(container), *dir)
+		}
+		db.Close()
+		if err != nil {
+			logging.Fatal("Error reported", "err", err)
+		}
+		logging.Info("Exported files", "count", written)
+		return
+	case "import":
+		var report importer.Report
+		if *file != "" {
+			logging.Info("Importing WordPress export", "path", *file)
+			var source *os.File
+			if source, err = os.Open(*file); err == nil {
+				report, err = importer.WordPress(container, source, *dryRun)
+				source.Close()
+			}
+		} else {
+			logging.Info("Importing Markdown files", "dir", *dir)
+			report, err = importer.Markdown(container, *dir, *dryRun)
+		}
+		db.Close()
+		logReport(report, *dryRun)
+		if err != nil {
+			logging.Fatal("Error reported", "err", err)
+		}
+		return
+	default:
+		db.Close()
+		logging.Fatal("Unknown mode, expected serve, migrate, rollback, backup, restore, check, config-check, export or import", "mode", *mode)
+	}
+
+	// Read pages from a replica, making every change to the database
+	if *readDSN != "" {
+		replica, err := database.New(*driver, configuration.DatabasePool())
+		if err != nil {
+			db.Close()
+			logging.Fatal("Could not use the replica", "err", err)
+		}
+		if sqlite, ok := replica.(*database.Sqlite); ok {
+			sqlite.Pragmas = configuration.SqliteReplicaPragmas()
+		}
+		logging.Info("Reading pages from a replica", "db", dialect)
+		if err := replica.Connect(*readDSN); err != nil {
+			db.Close()
+			logging.Fatal("Database error - please verify that the replica can be reached", "err", err)
+		}
+		container.Replica = replica
+	}
+
+	// Keep entries as Markdown files indexed in the database
+	var files *flatfile.Store
+	if configuration.EntriesPath != "" {
+		logging.Info("Indexing entry files", "dir", configuration.EntriesPath)
+		if files, err = flatfile.Open(container, configuration.EntriesPath); err != nil {
+			db.Close()
+			logging.Fatal("Could not index the entry files", "err", err)
+		}
+		if err = files.Watch(); err != nil {
+			logging.Warn("Could not watch the entry files for changes", "err", err)
+		}
+		container.Store = files
+	}
+
+	// Serve each hosted journal from its own database
+	var resolver *tenant.Resolver
+	var openTenant func(model.Tenant) (*app.Container, error)
+	if configuration.TenantMode != "" {
+		resolver = tenant.NewResolver(container)
+		openTenant = resolver.Open
+		container.TenantOpener = resolver
+	}
+
+	// Start background workers
+	dispatcher := queue.NewDispatcher(container)
+	dispatcher.Handle(ping.JobType, ping.Handle)
+	dispatcher.Handle(backup.JobType, backup.Handler(openTenant))
+	dispatcher.Handle(blogroll.JobType, blogroll.Handler(openTenant))
+	dispatcher.Handle(federation.JobType, federation.Handler(openTenant))
+	dispatcher.Handle(purge.JobType, purge.Handler(openTenant))
+	dispatcher.Handle(schedule.JobType, schedule.Handler(openTenant))
+	dispatcher.Handle(webhook.JobType, webhook.Handler(openTenant))
+	if configuration.Workers > 0 && graceful.Inherited() {
+		// The process being replaced is still finishing its jobs, so they are left running
+		logging.Info("Resuming background workers", "workers", configuration.Workers)
+		dispatcher.Resume(configuration.Workers)
+	} else if configuration.Workers > 0 {
+		logging.Info("Starting background workers", "workers", configuration.Workers)
+		dispatcher.Start(configuration.Workers)
+	}
+	if purge.Enabled(container) {
+		logging.Info("Purging entries left in the trash", "days", configuration.TrashRetention)
+	}
+	if backup.Enabled(container) {
+		logging.Info("Backing up the database", "dir", configuration.BackupPath, "hours", configuration.BackupInterval)
+	} else if configuration.BackupPath != "" {
+		logging.Info("Not backing up a database with tools of its own for backups", "db", dialect)
+	}
+	if blogroll.Enabled(container) {
+		logging.Info("Fetching followed feeds", "minutes", configuration.BlogrollInterval)
+	}
+
+	// Run the tasks repeated in the background as they fall due, alongside the workers
+	scheduler := cron.NewScheduler(container)
+	scheduler.Add(schedule.Task(openTenant))
+	scheduler.Add(purge.Task(openTenant))
+	scheduler.Add(backup.Task(openTenant))
+	scheduler.Add(blogroll.Task(openTenant))
+	if configuration.Workers > 0 {
+		if err = scheduler.Start(); err != nil {
+			logging.Warn("Could not schedule background tasks", "err", err)
+		}
+	}
+
+	if container.BasePath != "" {
+		logging.Info("Serving the journal beneath a base path", "path", container.BasePath)
+	}
+	container.Maintenance = app.NewMaintenance(configuration.Maintenance)
+	if configuration.Maintenance {
+		logging.Info("Starting in maintenance, refusing changes until it is switched off")
+	}
+	// Parse the templates of every page once, parsing them again as they change while developing
+	container.TemplateCache = app.NewTemplateCache(configuration.Dev)
+	for _, err := range container.TemplateCache.Preload(container) {
+		logging.Error("Could not parse the templates of a page", "err", err)
+	}
+	if configuration.Dev {
+		logging.Info("Developing templates, parsing them again as they change", "path", configuration.WebPath)
+	}
+	// Ask for a code only whoever runs the journal knows while it has no admin, so that nobody else can set it up
+	if model.NeedsSetup(container) {
+		code, err := model.NewSetupCode()
+		if err != nil {
+			logging.Fatal("Could not create a setup code", "err", err)
+		}
+		container.SetupCode = code
+		logging.Info("Set the journal up at /setup, adding its admin with the setup code", "path", container.BasePath+"/setup", "code", code)
+	}
 	router := router.NewRouter(container)
-	server := &http.Server{Addr: ":" + configuration.Port, Handler: router}
+	if configuration.AccessLog != "" {
+		accessLog, err := configuration.OpenLog(configuration.AccessLog)
+		if err != nil {
+			logging.Fatal("Could not open the access log", "path", configuration.AccessLog, "err", err)
+		}
+		defer accessLog.Close()
+		format := configuration.AccessLogFormat
+		if format == "" {
+			format = configuration.LogFormat
+		}
+		logging.Info("Writing requests served to the access log", "path", configuration.AccessLog, "format", format)
+		router.AccessLog = logging.NewAccessLog(accessLog, format)
+	}
+
+	if ratelimit.Enabled(container) {
+		logging.Info("Limiting changes and sign in attempts a minute from each address", "changes", configuration.RateLimit, "sign_ins", configuration.RateLimitLogin)
+		router.Use(ratelimit.NewLimiter(configuration).Middleware)
+	}
+
+	if resolver != nil {
+		logging.Info("Enabling multi-tenant hosting", "mode", configuration.TenantMode)
+		router.Use(resolver.Middleware)
+	}
+
+	// Ask browsers to only run what the journal serves, with the policy of the journal being requested
+	router.Use(headers.NewSecurity(router).Middleware)
+
+	// Send pages asked for at another address to the journal's own, when configured to
+	router.Use(canonical.NewHost(router).Middleware)
 
+	// Refuse forms posted from other sites, giving tokens for the journal being requested
+	router.Use(auth.NewCSRF(router, &web.Forbidden{}).Middleware)
+
+	// Check passwords sent over HTTP Basic once for each request, counting those that are wrong towards a lockout
+	router.Use(auth.BasicAttempts)
+
+	// Show messages left by the page before, such as that a form was saved, once each
+	router.Use(flash.NewReader(router).Middleware)
+
+	// Refuse changes while in maintenance, as switched at /admin/maintenance or by a signal
+	router.Use(maintenance.NewGuard(router, &web.Unavailable{}).Middleware)
+	stopMaintenance := maintenance.Listen(container)
+
+	// Publish scheduled entries as their time passes, once the journal being requested is known
+	router.Use(schedule.NewPublisher(router).Middleware)
+
+	server := &http.Server{Handler: router, ErrorLog: log.New(logging.Default().Writer(logging.LevelWarn), "", 0)}
+
+	// Listen on sockets handed over by the process being replaced on a restart, so no connection is refused, or passed
+	// by systemd, trusting connections over unix domain sockets as from a proxy on the same host
+	listeners := &graceful.Listeners{Unnamed: "http"}
+	server.ConnContext = proxy.ConnContext
+	failed := make(chan error, 1)
+
+	// Serve HTTPS directly, answering plain HTTP with a redirect to it and with challenges for new certificates
+	var redirectServer *http.Server
+	if configuration.TLS {
+		redirect := certificate.Redirect(configuration.Port)
+		if configuration.TLSCert != "" {
+			logging.Info("Serving HTTPS with a certificate from files", "path", configuration.TLSCert)
+			certs, err := certificate.NewFiles(configuration.TLSCert, configuration.TLSKey)
+			if err != nil {
+				logging.Error("Could not read the certificate", "err", err)
+			} else {
+				server.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+			}
+		} else {
+			logging.Info("Serving HTTPS with certificates from Let's Encrypt", "domains", configuration.TLSDomains())
+			manager := certificate.NewManager(configuration.TLSDomains(), configuration.TLSCache, configuration.TLSEmail)
+			server.TLSConfig = manager.TLSConfig()
+			redirect = manager.HTTPHandler(redirect)
+		}
+		if server.TLSConfig != nil {
+			server.TLSConfig.MinVersion = tls.VersionTLS12
+		}
+		if server.TLSConfig != nil && configuration.TLSHTTPPort != "0" {
+			if redirectListener, err := listeners.Listen("redirect", ":"+configuration.TLSHTTPPort); err != nil {
+				logging.Warn("Could not answer plain HTTP", "err", err)
+			} else {
+				logging.Info("Redirecting plain HTTP to HTTPS", "port", configuration.TLSHTTPPort)
+				redirectServer = &http.Server{Handler: redirect, ErrorLog: server.ErrorLog}
+				go func() {
+					if err := redirectServer.Serve(redirectListener); err != http.ErrServerClosed {
+						logging.Warn("Could not answer plain HTTP", "err", err)
+					}
+				}()
+			}
+		}
+	}
+
+	if ping.Enabled(container) {
+		logging.Info("Enabling search engine and feed hub notifications")
+	}
+	if federation.Enabled(container) {
+		logging.Info("Enabling ActivityPub federation", "account", federation.Account(container))
+	}
+	var mailServer *smtpd.Server
+	if email.Enabled(container) {
+		logging.Info("Receiving entries by email", "port", configuration.MailPort)
+		mailServer = &smtpd.Server{Handler: email.Handler(container)}
+		if mailListener, err := listeners.Listen("smtp", ":"+configuration.MailPort); err != nil {
+			logging.Warn("Could not receive email", "err", err)
+		} else {
+			go func() {
+				if err := mailServer.Serve(mailListener); err != nil && err != smtpd.ErrServerClosed {
+					logging.Warn("Could not receive email", "err", err)
+				}
+			}()
+		}
+	}
+	var telegramListener *telegram.Listener
+	if telegram.Enabled(container) {
+		logging.Info("Receiving entries from Telegram", "chat", telegram.ChatID(container))
+		telegramListener = telegram.NewListener(container)
+		telegramListener.Start()
+	}
 	if !configuration.EnableCreate {
-		log.Println("Article creating is disabled...")
+		logging.Info("Article creating is disabled")
 	}
 	if !configuration.EnableEdit {
-		log.Println("Article editing is disabled...")
+		logging.Info("Article editing is disabled")
+	}
+
+	var listener net.Listener
+	if configuration.Socket != "" {
+		listener, err = listeners.ListenUnix("http", configuration.Socket, configuration.SocketFileMode())
+	} else {
+		listener, err = listeners.Listen("http", ":"+configuration.Port)
+	}
+	if configuration.TLS && server.TLSConfig == nil {
+		err = errors.New("HTTPS cannot be served without a certificate")
 	}
+	if err == nil {
+		go func() {
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ServeTLS(listener, "", "")
+			} else {
+				err = server.Serve(listener)
+			}
+			if err != http.ErrServerClosed {
+				failed <- err
+			}
+		}()
+		logging.Info("Ready and listening", "addr", listener.Addr().String())
+		graceful.Ready()
 
-	log.Printf("Ready and listening on port %s...\n", configuration.Port)
-	err = router.StartAndServe(server)
+		// Stop on SIGINT or SIGTERM, or hand over to a new process on SIGUSR2, finishing requests already being served
+		timeout := time.Duration(configuration.ShutdownTimeout) * time.Second
+		err = listeners.Wait(graceful.Signals(), failed, timeout)
+		logging.Info("Stopping, giving requests time to finish", "seconds", configuration.ShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
+			logging.Warn("Closing requests still being served", "err", shutdownErr)
+			server.Close()
+		}
+		if redirectServer != nil {
+			redirectServer.Shutdown(ctx)
+		}
+		cancel()
+	}
 
 	// Close cleanly
+	stopMaintenance()
+	if mailServer != nil {
+		mailServer.Close()
+	}
+	if telegramListener != nil {
+		telegramListener.Stop()
+	}
+	scheduler.Stop()
+	dispatcher.Stop()
+	if files != nil {
+		files.Close()
+	}
+	if resolver != nil {
+		resolver.Close()
+	}
+	if container.Replica != nil {
+		container.Replica.Close()
+	}
 	db.Close()
 	if err != nil {
-		log.Fatal("Error reported: ", err)
+		logging.Fatal("Error reported", "err", err)
+	}
+	logging.Info("Stopped")
+}
+
+// logProblems Log each problem found by a check, followed by how many were found and fixed, returning how many remain
+func logProblems(problems []model.Problem, fix bool) int {
+	fixed := 0
+	for _, problem := range problems {
+		if problem.Fixed {
+			fixed++
+			logging.Info("Fixed problem", "kind", problem.Kind, "detail", problem.Detail)
+		} else {
+			logging.Warn("Found problem", "kind", problem.Kind, "detail", problem.Detail)
+		}
+	}
+	if len(problems) == 0 {
+		logging.Info("No problems found")
+	} else if fix {
+		logging.Info("Found problems", "count", len(problems), "fixed", fixed)
+	} else {
+		logging.Warn("Found problems, run again with -fix to put right what can be fixed safely", "count", len(problems))
+	}
+
+	return len(problems) - fixed
+}
+
+// logReport Log what an import created, or would have on a dry run, and everything it left out
+func logReport(report importer.Report, dryRun bool) {
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+		for _, slug := range report.Created {
+			logging.Info("Would create entry", "slug", slug)
+		}
+		for _, name := range report.Categories {
+			logging.Info("Would create category", "name", name)
+		}
+	}
+	for _, slug := range report.Duplicates {
+		logging.Info("Skipped entry, the slug is already taken", "slug", slug)
+	}
+	for _, skipped := range report.Skipped {
+		logging.Info("Skipped", "name", skipped.Name, "reason", skipped.Reason)
+	}
+	for _, dropped := range report.Dropped {
+		logging.Info("Dropped", "name", dropped.Name, "reason", dropped.Reason)
 	}
+	logging.Info(verb+" entries, categories and comments", "entries", len(report.Created), "categories", len(report.Categories), "comments", report.Comments, "duplicates", len(report.Duplicates), "skipped", len(report.Skipped), "dropped", len(report.Dropped))
 }
@@ -1,30 +1,77 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	gojson "encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jamiefdhurst/journal/pkg/adapter/giphy"
 	"github.com/jamiefdhurst/journal/pkg/adapter/json"
 
 	"github.com/jamiefdhurst/journal/internal/app"
+	"github.com/jamiefdhurst/journal/internal/app/auth"
+	"github.com/jamiefdhurst/journal/internal/app/backup"
+	"github.com/jamiefdhurst/journal/internal/app/blogroll"
+	"github.com/jamiefdhurst/journal/internal/app/canonical"
+	"github.com/jamiefdhurst/journal/internal/app/controller/web"
+	"github.com/jamiefdhurst/journal/internal/app/email"
+	"github.com/jamiefdhurst/journal/internal/app/export"
+	"github.com/jamiefdhurst/journal/internal/app/federation"
+	"github.com/jamiefdhurst/journal/internal/app/flash"
+	"github.com/jamiefdhurst/journal/internal/app/flatfile"
+	"github.com/jamiefdhurst/journal/internal/app/headers"
+	"github.com/jamiefdhurst/journal/internal/app/importer"
+	"github.com/jamiefdhurst/journal/internal/app/maintenance"
 	"github.com/jamiefdhurst/journal/internal/app/model"
+	"github.com/jamiefdhurst/journal/internal/app/purge"
+	"github.com/jamiefdhurst/journal/internal/app/queue"
+	"github.com/jamiefdhurst/journal/internal/app/ratelimit"
 	"github.com/jamiefdhurst/journal/internal/app/router"
+	"github.com/jamiefdhurst/journal/internal/app/schedule"
+	"github.com/jamiefdhurst/journal/internal/app/telegram"
+	"github.com/jamiefdhurst/journal/internal/app/webhook"
+	"github.com/jamiefdhurst/journal/pkg/activitypub"
+	webhookadapter "github.com/jamiefdhurst/journal/pkg/adapter/webhook"
 	"github.com/jamiefdhurst/journal/pkg/database"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
 	pkgrouter "github.com/jamiefdhurst/journal/pkg/router"
+	"github.com/jamiefdhurst/journal/pkg/smtpd"
 )
 
 var (
 	rtr    *pkgrouter.Router
 	server *httptest.Server
+	admin  *http.Client
 )
 
 func init() {
 	rtr = router.NewRouter(nil)
+	rtr.Use(headers.NewSecurity(rtr).Middleware)
+	rtr.Use(canonical.NewHost(rtr).Middleware)
+	rtr.Use(auth.NewCSRF(rtr, &web.Forbidden{}).Middleware)
+	rtr.Use(auth.BasicAttempts)
+	rtr.Use(flash.NewReader(rtr).Middleware)
+	rtr.Use(maintenance.NewGuard(rtr, &web.Unavailable{}).Middleware)
+	publisher := schedule.NewPublisher(rtr)
+	publisher.Interval = 0
+	rtr.Use(publisher.Middleware)
 	server = httptest.NewServer(rtr)
 
 	log.Println("Serving on " + server.URL)
@@ -34,23 +81,64 @@ func fixtures(t *testing.T) {
 	container := &app.Container{Configuration: app.DefaultConfiguration()}
 	adapter := giphy.Client{Client: &json.Client{}}
 	db := &database.Sqlite{}
-	if err := db.Connect("test/data/test.db"); err != nil {
+	if err := db.Connect(database.Memory); err != nil {
 		t.Error("Could not open test database for writing...")
 	}
 
-	// Setup container
+	// Setup container, starting from an empty database each time
+	if previous, ok := rtr.Container.(*app.Container); ok && previous != nil && previous.Db != nil {
+		previous.Db.Close()
+	}
 	container.Db = db
 	container.Giphy = adapter
 	rtr.Container = container
-
-	js := model.Journals{Container: container}
-	db.Exec("DROP TABLE journal")
-	js.CreateTable()
+	model.CreateTables(container)
 
 	// Set up data
 	db.Exec("INSERT INTO journal (slug, title, content, date) VALUES (?, ?, ?, ?)", "test", "Test", "<p>Test!</p>", "2018-01-01")
 	db.Exec("INSERT INTO journal (slug, title, content, date) VALUES (?, ?, ?, ?)", "test-2", "Another Test", "<p>Test again!</p>", "2018-02-01")
 	db.Exec("INSERT INTO journal (slug, title, content, date) VALUES (?, ?, ?, ?)", "test-3", "A Final Test", "<p>Test finally!</p>", "2018-03-01")
+
+	// Sign in an admin to write entries
+	us := model.Users{Container: container}
+	user := model.User{Username: "admin", Email: "admin@example.com", Role: model.RoleAdmin}
+	user.SetPassword("password123")
+	us.Save(user)
+	admin = signIn("admin", "password123")
+}
+
+// signIn Get a client signed in as a user, keeping their session cookie
+func signIn(username string, password string) *http.Client {
+	client := browser()
+	client.PostForm(server.URL+"/login", map[string][]string{"username": {username}, "password": {password}})
+
+	return client
+}
+
+// browser Get a client keeping cookies and posting the CSRF token it was given, as the journal's own forms do
+func browser() *http.Client {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar, Transport: csrfTransport{jar: jar}}
+	res, _ := client.Get(server.URL + "/login")
+	res.Body.Close()
+
+	return client
+}
+
+// csrfTransport Send each request with the CSRF token held in the cookie jar
+type csrfTransport struct {
+	jar http.CookieJar
+}
+
+func (t csrfTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	for _, cookie := range t.jar.Cookies(request.URL) {
+		if cookie.Name == auth.CSRFCookie {
+			request.Header.Set(auth.CSRFHeader, cookie.Value)
+		}
+	}
+
+	return http.DefaultTransport.RoundTrip(request)
 }
 
 func TestApiv1List(t *testing.T) {
@@ -125,7 +213,7 @@ func TestApiV1Create(t *testing.T) {
 
 	request, err := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 4","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>"}`))
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -137,7 +225,7 @@ func TestApiV1Create(t *testing.T) {
 
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
-	expected := `{"id":4,"slug":"test-4","title":"Test 4","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>"}`
+	expected := `{"id":4,"slug":"test-4","title":"Test 4","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>","word_count":2,"reading_time":1,"author_id":1}`
 
 	// Use contains to get rid of any extra whitespace that we can discount
 	if !strings.Contains(string(body[:]), expected) {
@@ -150,7 +238,7 @@ func TestApiV1Create_InvalidRequest(t *testing.T) {
 
 	request, err := http.NewRequest("PUT", server.URL+"/api/v1/post", nil)
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -166,7 +254,7 @@ func TestApiV1Create_MissingData(t *testing.T) {
 
 	request, err := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 4"}`))
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -181,7 +269,7 @@ func TestApiV1Create_RepeatTitles(t *testing.T) {
 	fixtures(t)
 
 	request, err := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Repeated","date":"2018-02-01T00:00:00Z","content":"<p>Repeated content test!</p>"}`))
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
@@ -190,7 +278,7 @@ func TestApiV1Create_RepeatTitles(t *testing.T) {
 	}
 
 	request, err = http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Repeated","date":"2019-02-01T00:00:00Z","content":"<p>Repeated content test again!</p>"}`))
-	res, err = http.DefaultClient.Do(request)
+	res, err = admin.Do(request)
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
 	}
@@ -198,7 +286,7 @@ func TestApiV1Create_RepeatTitles(t *testing.T) {
 		t.Error("Expected 201 status code")
 	}
 
-	request, err = http.NewRequest("GET", server.URL+"/api/v1/post/repeated-1", nil)
+	request, err = http.NewRequest("GET", server.URL+"/api/v1/post/repeated-2", nil)
 	res, err = http.DefaultClient.Do(request)
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -208,7 +296,7 @@ func TestApiV1Create_RepeatTitles(t *testing.T) {
 	}
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
-	expected := `{"id":5,"slug":"repeated-1","title":"Repeated","date":"2019-02-01T00:00:00Z","content":"<p>Repeated content test again!</p>"}`
+	expected := `{"id":5,"slug":"repeated-2","title":"Repeated","date":"2019-02-01T00:00:00Z","content":"<p>Repeated content test again!</p>","word_count":4,"reading_time":1,"author_id":1}`
 
 	// Use contains to get rid of any extra whitespace that we can discount
 	if !strings.Contains(string(body[:]), expected) {
@@ -221,7 +309,7 @@ func TestApiV1Update(t *testing.T) {
 
 	request, err := http.NewRequest("POST", server.URL+"/api/v1/post/test", strings.NewReader(`{"title":"A different title"}`))
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -233,7 +321,7 @@ func TestApiV1Update(t *testing.T) {
 
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
-	expected := `{"id":1,"slug":"test","title":"A different title","date":"2018-01-01T00:00:00Z","content":"<p>Test!</p>"}`
+	expected := `{"id":1,"slug":"test","title":"A different title","date":"2018-01-01T00:00:00Z","content":"<p>Test!</p>","word_count":1,"reading_time":1}`
 
 	// Use contains to get rid of any extra whitespace that we can discount
 	if !strings.Contains(string(body[:]), expected) {
@@ -246,7 +334,7 @@ func TestApiV1Update_NotFound(t *testing.T) {
 
 	request, err := http.NewRequest("POST", server.URL+"/api/v1/post/random", strings.NewReader(`{"title":"A different title"}`))
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -262,7 +350,7 @@ func TestApiV1Update_InvalidRequest(t *testing.T) {
 
 	request, err := http.NewRequest("POST", server.URL+"/api/v1/post/test", nil)
 
-	res, err := http.DefaultClient.Do(request)
+	res, err := admin.Do(request)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %s", err)
@@ -272,3 +360,2831 @@ func TestApiV1Update_InvalidRequest(t *testing.T) {
 		t.Error("Expected 400 status code")
 	}
 }
+
+func TestApiJournals(t *testing.T) {
+	fixtures(t)
+
+	// Create
+	request, _ := http.NewRequest("POST", server.URL+"/api/journals", strings.NewReader(`{"title":"Test 4","date":"2018-06-01","content":"<p>Test 4!</p>","syndication":["https://mastodon.example/@jamie/4"]}`))
+	res, err := admin.Do(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if res.StatusCode != 201 {
+		t.Error("Expected 201 status code")
+	}
+	res.Body.Close()
+
+	// Update
+	request, _ = http.NewRequest("PUT", server.URL+"/api/journals/test-4", strings.NewReader(`{"title":"Test Four","excerpt":"Four in short"}`))
+	res, _ = admin.Do(request)
+	if res.StatusCode != 200 {
+		t.Error("Expected 200 status code")
+	}
+	res.Body.Close()
+
+	// Retrieve
+	res, _ = http.Get(server.URL + "/api/journals/test-4")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	expected := `{"id":4,"slug":"test-4","title":"Test Four","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>","excerpt":"Four in short","syndication":["https://mastodon.example/@jamie/4"],"word_count":2,"reading_time":1,"author_id":1}`
+	if !strings.Contains(string(body[:]), expected) {
+		t.Errorf("Expected:\n\t%s\nGot:\n\t%s", expected, string(body[:]))
+	}
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "<p>Four in short</p>") || strings.Contains(string(body[:]), "Test 4!") {
+		t.Error("Expected excerpt to be shown on the index in place of the content")
+	}
+
+	// Delete
+	request, _ = http.NewRequest("DELETE", server.URL+"/api/journals/test-4", nil)
+	res, _ = admin.Do(request)
+	if res.StatusCode != 204 {
+		t.Error("Expected 204 status code")
+	}
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/api/journals/test-4")
+	if res.StatusCode != 404 {
+		t.Error("Expected 404 status code after deletion")
+	}
+	res.Body.Close()
+}
+
+func TestApiStats(t *testing.T) {
+	fixtures(t)
+	db := rtr.Container.(*app.Container).Db
+	db.Exec("INSERT INTO journal_tag (journal_id, name, slug) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?)", 1, "Travel", "travel", 2, "travel", "travel", 2, "Road Trip", "road-trip")
+
+	request, _ := http.NewRequest("GET", server.URL+"/api/stats", nil)
+	res, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if res.StatusCode != 200 {
+		t.Error("Expected 200 status code")
+	}
+
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	expected := `{"total_entries":3,"total_words":5,"average_words":1,"longest_streak":1,"years":[{"label":"2018","entries":3,"words":5}],"months":[{"label":"2018-01","entries":1,"words":1},{"label":"2018-02","entries":1,"words":2},{"label":"2018-03","entries":1,"words":2}],"weekdays":[{"label":"Monday","entries":1,"words":1},{"label":"Thursday","entries":2,"words":4}],"hours":[],"tags":[{"label":"Travel","entries":2,"words":3,"slug":"travel"},{"label":"Road Trip","entries":1,"words":2,"slug":"road-trip"}]}`
+	if !strings.Contains(string(body[:]), expected) {
+		t.Errorf("Expected:\n\t%s\nGot:\n\t%s", expected, string(body[:]))
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	fixtures(t)
+
+	query := `{"query": "query Page($after: String) { journals(first: 2, after: $after) { totalCount nodes { slug } pageInfo { hasNextPage endCursor } } }", "variables": {"after": %s}}`
+	res, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader(fmt.Sprintf(query, "null")))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	expected := `{"data":{"journals":{"totalCount":3,"nodes":[{"slug":"test-3"},{"slug":"test-2"}],"pageInfo":{"hasNextPage":true,"endCursor":"Y3Vyc29yOjE="}}}}`
+	if res.StatusCode != 200 || strings.TrimSpace(string(body[:])) != expected {
+		t.Errorf("Expected:\n\t%s\nGot:\n\t%s", expected, string(body[:]))
+	}
+
+	res, _ = http.Post(server.URL+"/graphql", "application/json", strings.NewReader(fmt.Sprintf(query, `"Y3Vyc29yOjE="`)))
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	expected = `{"data":{"journals":{"totalCount":3,"nodes":[{"slug":"test"}],"pageInfo":{"hasNextPage":false,"endCursor":"Y3Vyc29yOjI="}}}}`
+	if strings.TrimSpace(string(body[:])) != expected {
+		t.Errorf("Expected:\n\t%s\nGot:\n\t%s", expected, string(body[:]))
+	}
+
+	res, _ = admin.Post(server.URL+"/graphql", "application/json", strings.NewReader(`{"query": "mutation { createJournal(input: {title: \"From GraphQL\", date: \"2018-06-01\", content: \"Sent *once*\"}) { slug html } }"}`))
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	expected = `{"data":{"createJournal":{"slug":"from-graphql","html":"<p>Sent <em>once</em></p>"}}}`
+	if strings.TrimSpace(string(body[:])) != expected {
+		t.Errorf("Expected:\n\t%s\nGot:\n\t%s", expected, string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/graphql?query=" + url.QueryEscape(`{ journal(slug: "from-graphql") { title } }`))
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.TrimSpace(string(body[:])) != `{"data":{"journal":{"title":"From GraphQL"}}}` {
+		t.Errorf("Expected created entry to be found, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	fixtures(t)
+
+	request, _ := http.NewRequest("GET", server.URL+"/search?q=final+test", nil)
+	res, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if res.StatusCode != 200 {
+		t.Error("Expected 200 status code")
+	}
+
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if !strings.Contains(string(body[:]), "A Final Test") || strings.Contains(string(body[:]), "Another Test") {
+		t.Errorf("Expected only the matching entry to be found, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestDrafts(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/new", map[string][]string{
+		"title":   {"Unfinished Thoughts"},
+		"date":    {"2018-04-01"},
+		"content": {"Not ready yet"},
+		"status":  {"draft"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.Request.URL.Path != "/drafts" || !strings.Contains(string(body[:]), "Unfinished Thoughts") {
+		t.Errorf("Expected draft to be listed in drafts, got:\n\t%s", string(body[:]))
+	}
+
+	// Drafts are kept out of the public listings
+	for _, path := range []string{"/", "/api/journals", "/search?q=unfinished"} {
+		res, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		body, _ = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != 200 || strings.Contains(string(body[:]), "Unfinished Thoughts") {
+			t.Errorf("Expected draft to be hidden from %s", path)
+		}
+	}
+
+	// Only those who may edit a draft can read it
+	res, _ = http.Get(server.URL + "/unfinished-thoughts")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected draft not to be found by readers, got %d", res.StatusCode)
+	}
+	res, _ = admin.Get(server.URL + "/unfinished-thoughts")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "Not ready yet") {
+		t.Errorf("Expected draft to be shown to its author, got %d", res.StatusCode)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/test/delete", nil)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/" || !strings.Contains(string(body[:]), `class="flash flash-success"`) {
+		t.Error("Expected redirect back to index with deleted message")
+	}
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected entry in the trash not to be found")
+	}
+
+	res, _ = admin.Get(server.URL + "/trash")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `value="test"`) {
+		t.Errorf("Expected entry to be listed in the trash, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.PostForm(server.URL+"/trash", map[string][]string{"slug": {"test"}, "action": {"restore"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Error("Expected restored entry to be found")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/test/delete", nil)
+	res.Body.Close()
+	res, _ = admin.PostForm(server.URL+"/trash", map[string][]string{"slug": {"test"}, "action": {"delete"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "The trash is empty") {
+		t.Error("Expected entry to have been deleted permanently")
+	}
+
+	// Entries left in the trash past the retention period are purged
+	container := rtr.Container.(*app.Container)
+	container.Configuration.TrashRetention = 30
+	defer func() { container.Configuration.TrashRetention = 0 }()
+	container.Db.Exec("UPDATE journal SET deleted_at = ? WHERE slug = ?", "2018-01-01 00:00:00", "test-2")
+	container.Db.Exec("UPDATE journal SET deleted_at = ? WHERE slug = ?", time.Now().UTC().Format("2006-01-02 15:04:05"), "test-3")
+	res, _ = admin.Get(server.URL + "/trash")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "in the trash for 30 days") {
+		t.Error("Expected retention period to be shown in the trash")
+	}
+	purged, err := purge.Purge(container)
+	if err != nil || len(purged) != 1 || purged[0].Slug != "test-2" {
+		t.Errorf("Expected only the expired entry to be purged, got %v", purged)
+	}
+	res, _ = admin.Get(server.URL + "/trash")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), `value="test-2"`) || !strings.Contains(string(body[:]), `value="test-3"`) {
+		t.Error("Expected recently deleted entry to be kept in the trash")
+	}
+}
+
+func TestBulkActions(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.Get(server.URL + "/admin/entries")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `name="id" value="1"`) || !strings.Contains(string(body[:]), `name="id" value="3"`) {
+		t.Errorf("Expected entries to be listed for bulk actions, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/entries", map[string][]string{"action": {"draft"}, "id": {"1", "2"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "2 entries updated") {
+		t.Error("Expected both entries to be updated")
+	}
+	res, _ = admin.Get(server.URL + "/drafts")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Another Test") || strings.Contains(string(body[:]), "A Final Test") {
+		t.Error("Expected selected entries to be returned to drafts")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/entries", map[string][]string{"action": {"trash"}, "id": {"1", "2", "3"}})
+	res.Body.Close()
+	res, _ = admin.Get(server.URL + "/trash")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `value="test"`) || !strings.Contains(string(body[:]), `value="test-3"`) {
+		t.Error("Expected selected entries to be moved to the trash")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/test/edit", map[string][]string{
+		"title":   {"Test"},
+		"date":    {"2018-01-01"},
+		"content": {"Rewritten"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+
+	// Earlier versions are only shown to those who may edit the entry
+	for _, path := range []string{"/test/history", "/test/history/1"} {
+		res, _ = http.Get(server.URL + path)
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if res.Request.URL.Path != "/login" || strings.Contains(string(body[:]), "Test!") {
+			t.Errorf("Expected %s to need signing in", path)
+		}
+	}
+
+	res, _ = admin.Get(server.URL + "/test/history/1")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `<span class="diff-removed">&lt;p&gt;Test!&lt;/p&gt;</span>`) || !strings.Contains(string(body[:]), `<span class="diff-added">Rewritten</span>`) {
+		t.Errorf("Expected changes to be shown, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.PostForm(server.URL+"/test/history/1", nil)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Earlier version restored") || !strings.Contains(string(body[:]), "/test/history/2") {
+		t.Error("Expected restore to have kept the replaced version")
+	}
+
+	res, _ = http.Get(server.URL + "/api/journals/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `"content":"<p>Test!</p>"`) {
+		t.Errorf("Expected original content to be restored, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestMedia(t *testing.T) {
+	fixtures(t)
+	dir, _ := ioutil.TempDir("", "media")
+	defer os.RemoveAll(dir)
+	rtr.Container.(*app.Container).Configuration.MediaPath = dir
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "Holiday.png")
+	part.Write([]byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"))
+	writer.Close()
+	request, _ := http.NewRequest("POST", server.URL+"/upload", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Accept", "application/json")
+	res, err := admin.Do(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	uploaded, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 201 || !strings.Contains(string(uploaded[:]), `"url":"/media/holiday.png"`) {
+		t.Errorf("Expected image to be uploaded, got:\n\t%s", string(uploaded[:]))
+	}
+
+	res, _ = admin.Get(server.URL + "/media")
+	page, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(page[:]), "![holiday](/media/holiday.png)") {
+		t.Error("Expected image to be listed in the media library")
+	}
+
+	res, _ = http.Get(server.URL + "/media/holiday.png")
+	res.Body.Close()
+	if res.StatusCode != 200 || res.Header.Get("Content-Type") != "image/png" {
+		t.Error("Expected uploaded image to be served")
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	fixtures(t)
+	dir, _ := ioutil.TempDir("", "media")
+	defer os.RemoveAll(dir)
+	rtr.Container.(*app.Container).Configuration.MediaPath = dir
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "Trip Plan.pdf")
+	part.Write([]byte("%PDF-1.4 plan"))
+	writer.Close()
+	request, _ := http.NewRequest("POST", server.URL+"/test/attachments", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	res, err := admin.Do(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	page, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(page[:]), "Trip Plan.pdf attached") || !strings.Contains(string(page[:]), `<a href="/test/attachments/1">Trip Plan.pdf</a>`) {
+		t.Errorf("Expected file to be attached, got:\n\t%s", string(page[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/test")
+	page, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(page[:]), `href="/test/attachments/1" download>Trip Plan.pdf</a> <span>13 bytes</span>`) {
+		t.Error("Expected attachment to be listed on the entry")
+	}
+
+	res, _ = http.Get(server.URL + "/test/attachments/1")
+	download, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(download[:]) != "%PDF-1.4 plan" || res.Header.Get("Content-Type") != "application/pdf" || res.Header.Get("Content-Disposition") != `attachment; filename="Trip Plan.pdf"` {
+		t.Error("Expected attachment to be downloaded")
+	}
+	res, _ = http.Get(server.URL + "/test-2/attachments/1")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected attachment to only be found on its own entry")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/test/attachments/1/delete", nil)
+	page, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(page[:]), "Attachment removed") || !strings.Contains(string(page[:]), "Nothing has been attached") {
+		t.Error("Expected attachment to be removed")
+	}
+	res, _ = http.Get(server.URL + "/test/attachments/1")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected removed attachment not to be found")
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	fixtures(t)
+	db := rtr.Container.(*app.Container).Db
+	db.Exec("INSERT INTO journal (slug, title, content, date, status) VALUES (?, ?, ?, ?, ?)", "draft", "Unfinished", "Draft", "2018-04-01", model.JournalStatusDraft)
+
+	res, err := http.Get(server.URL + "/feed.rss")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Header.Get("Content-Type") != "application/rss+xml; charset=utf-8" || !strings.Contains(string(body[:]), "<title>A Final Test</title>") {
+		t.Errorf("Expected RSS feed of entries, got:\n\t%s", string(body[:]))
+	}
+	if strings.Contains(string(body[:]), "Unfinished") {
+		t.Error("Expected drafts to be left out of the feed")
+	}
+
+	res, _ = http.Get(server.URL + "/feed.atom")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Header.Get("Content-Type") != "application/atom+xml; charset=utf-8" || !strings.Contains(string(body[:]), "<id>"+server.URL+"/test-3</id>") {
+		t.Errorf("Expected Atom feed of entries, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/feed.json")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Header.Get("Content-Type") != "application/feed+json; charset=utf-8" || !strings.Contains(string(body[:]), `"id": "`+server.URL+`/test-3"`) || strings.Contains(string(body[:]), "Unfinished") {
+		t.Errorf("Expected JSON Feed of entries, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestComments(t *testing.T) {
+	fixtures(t)
+
+	reader := browser()
+	res, err := reader.PostForm(server.URL+"/test/comments", map[string][]string{"author": {"Reader"}, "content": {"Lovely <em>post</em><script>alert(1)</script>"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "will appear once it has been approved") {
+		t.Error("Expected comment to be held for moderation")
+	}
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "Lovely") {
+		t.Error("Expected comment not to be shown before it is approved")
+	}
+
+	res, _ = admin.Get(server.URL + "/admin/comments")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Lovely <em>post</em>") || strings.Contains(string(body[:]), "alert(1)") {
+		t.Errorf("Expected sanitized comment in the moderation queue, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/comments", map[string][]string{"id": {"1"}, "action": {"approve"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Lovely <em>post</em></div>") || strings.Contains(string(body[:]), "alert(1)") {
+		t.Error("Expected approved comment to be shown sanitized beneath the entry")
+	}
+
+	db := rtr.Container.(*app.Container).Db
+	db.Exec("UPDATE journal SET comments = ? WHERE slug = ?", model.JournalCommentsClosed, "test")
+	res, _ = reader.PostForm(server.URL+"/test/comments", map[string][]string{"author": {"Reader"}, "content": {"Again"}})
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected comments to be refused once closed")
+	}
+}
+
+func TestScheduled(t *testing.T) {
+	fixtures(t)
+
+	publishAt := time.Now().Add(time.Hour).Format("2006-01-02T15:04")
+	res, err := admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Coming Soon"}, "date": {"2018-04-01"}, "content": {"Later"}, "status": {"scheduled"}, "publish_at": {publishAt}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/drafts" || !strings.Contains(string(body[:]), "Scheduled for") {
+		t.Errorf("Expected scheduled entry to be listed with drafts, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "Coming Soon") {
+		t.Error("Expected scheduled entry to be hidden before its time")
+	}
+
+	db := rtr.Container.(*app.Container).Db
+	db.Exec("UPDATE journal SET publish_at = ? WHERE slug = ?", time.Now().UTC().Add(-time.Minute).Format("2006-01-02 15:04:05"), "coming-soon")
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Coming Soon") {
+		t.Error("Expected scheduled entry to be published once its time has passed")
+	}
+}
+
+func TestCategories(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/admin/categories", map[string][]string{"name": {"Travel"}, "parent_id": {"0"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+	res, _ = admin.PostForm(server.URL+"/admin/categories", map[string][]string{"name": {"Europe"}, "parent_id": {"1"}})
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `class="flash flash-success"`) || !strings.Contains(string(body[:]), `— <input type="text" name="name" value="Europe"`) {
+		t.Errorf("Expected nested category to be added, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.PostForm(server.URL+"/test-2/edit", map[string][]string{"title": {"Another Test"}, "date": {"2018-02-01"}, "content": {"Test again!"}, "category_id": {"2"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/test-2")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `<a href="/category/travel">Travel</a> &rsaquo; <a class="p-category" href="/category/europe">Europe</a>`) {
+		t.Errorf("Expected entry to link to its category, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/category/travel")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Another Test") || strings.Contains(string(body[:]), "A Final Test") {
+		t.Error("Expected parent category to list entries from nested categories only")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/categories", map[string][]string{"id": {"1"}, "name": {"Travel"}, "parent_id": {"2"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `class="flash flash-error"`) {
+		t.Error("Expected category nested beneath its own child to be refused")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/categories", map[string][]string{"id": {"2"}, "action": {"delete"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/test-2")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `<a class="p-category" href="/category/travel">Travel</a>`) {
+		t.Error("Expected entry to move up to the parent when its category is deleted")
+	}
+	res, _ = http.Get(server.URL + "/category/europe")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected deleted category to be gone")
+	}
+}
+
+func TestRelated(t *testing.T) {
+	fixtures(t)
+
+	res, err := http.Get(server.URL + "/test-2")
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	related := string(body[:])
+	if i := strings.Index(related, "Related entries"); i >= 0 {
+		related = related[i:]
+	} else {
+		related = ""
+	}
+	if !strings.Contains(related, `<a href="/test-3">A Final Test</a>`) || !strings.Contains(related, `<a href="/test">Test</a>`) || strings.Contains(related, "/test-2\"") {
+		t.Errorf("Expected entries sharing title words to be suggested, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestSlugs(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Search"}, "date": {"2018-04-01"}, "content": {"Reserved"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/search-2")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Error("Expected entry titled after a reserved path to be numbered")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Custom"}, "date": {"2018-04-02"}, "content": {"Mine"}, "slug": {"my-address"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/my-address")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "Mine") {
+		t.Error("Expected entry to be found at its custom slug")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/test-2/edit", map[string][]string{"title": {"Another Test"}, "date": {"2018-02-01"}, "content": {"Again"}, "slug": {"my-address"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/test-2/edit" || !strings.Contains(string(body[:]), "must not already be in use") {
+		t.Error("Expected a slug used by another entry to be refused")
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Another test!"}, "date": {"2018-04-01"}, "content": {"Repeated"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/new" || !strings.Contains(string(body[:]), "Possible Duplicate") || !strings.Contains(string(body[:]), "/test-2/edit") {
+		t.Error("Expected nearly identical entry to be confirmed before saving")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Another test!"}, "date": {"2018-04-01"}, "content": {"Repeated"}, "confirm_duplicate": {"1"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/" || !strings.Contains(string(body[:]), "Journal saved.") {
+		t.Error("Expected confirmed entry to be saved")
+	}
+}
+
+func TestPinned(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "pinned": {"1"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+
+	res, _ = http.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	pinned := strings.Index(string(body[:]), `<a href="/test">Test</a>`)
+	latest := strings.Index(string(body[:]), `<a href="/test-3">A Final Test</a>`)
+	if pinned < 0 || latest < 0 || pinned > latest || !strings.Contains(string(body[:]), "pinned-marker") {
+		t.Errorf("Expected pinned entry to lead the index, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	fixtures(t)
+
+	res, err := admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "meta": {"Mood: <b>Happy</b>\nweather: Sunny"}})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+
+	res, _ = http.Get(server.URL + "/test")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "<dt>mood</dt><dd>&lt;b&gt;Happy&lt;/b&gt;</dd><dt>weather</dt><dd>Sunny</dd>") {
+		t.Errorf("Expected custom fields to be shown escaped, got:\n\t%s", string(body[:]))
+	}
+
+	request, _ := http.NewRequest("POST", server.URL+"/api/v1/post/test", strings.NewReader(`{"meta":{"weather":"","location":"Paris"}}`))
+	res, err = admin.Do(request)
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("Expected custom fields to be updated, got %v", err)
+	}
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/api/v1/post/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `"meta":{"location":"Paris","mood":"<b>Happy</b>"}`) {
+		t.Errorf("Expected fields given to be set or removed and others kept, got:\n\t%s", string(body[:]))
+	}
+
+	request, _ = http.NewRequest("POST", server.URL+"/api/v1/post/test", strings.NewReader(`{"meta":{"Not Valid":"x"}}`))
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	if res.StatusCode != 400 {
+		t.Errorf("Expected invalid name to be refused, got %d", res.StatusCode)
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	fixtures(t)
+	rtr.Container.(*app.Container).Configuration.AdminToken = "secret"
+
+	admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "visibility": {"unlisted"}})
+	admin.PostForm(server.URL+"/test-2/edit", map[string][]string{"title": {"Another Test"}, "date": {"2018-02-01"}, "content": {"Test again!"}, "visibility": {"private"}})
+
+	res, _ := http.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), `<a href="/test">Test</a>`) || strings.Contains(string(body[:]), `<a href="/test-2">Another Test</a>`) || !strings.Contains(string(body[:]), `<a href="/test-3">A Final Test</a>`) {
+		t.Errorf("Expected only public entries on the index, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "This entry is unlisted") {
+		t.Errorf("Expected unlisted entry to be reachable by its address, got %d", res.StatusCode)
+	}
+
+	res, _ = http.Get(server.URL + "/test-2")
+	res.Body.Close()
+	if res.StatusCode != 401 {
+		t.Errorf("Expected private entry to require authentication, got %d", res.StatusCode)
+	}
+	res, _ = http.Get(server.URL + "/api/journals/test-2")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected private entry to be hidden from the API, got %d", res.StatusCode)
+	}
+
+	request, _ := http.NewRequest("GET", server.URL+"/test-2", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	res, _ = http.DefaultClient.Do(request)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "This entry is private") {
+		t.Errorf("Expected private entry to be shown when authenticated, got %d", res.StatusCode)
+	}
+}
+
+func TestProtected(t *testing.T) {
+	fixtures(t)
+
+	admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "password": {"letmein"}})
+
+	res, _ := http.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), `<a href="/test">Test</a>`) {
+		t.Errorf("Expected protected entry to be left off the index, got:\n\t%s", string(body[:]))
+	}
+
+	client := browser()
+	res, _ = client.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 403 || strings.Contains(string(body[:]), "Test!") || !strings.Contains(string(body[:]), `action="/test/unlock"`) {
+		t.Errorf("Expected protected entry to ask for its password, got %d", res.StatusCode)
+	}
+
+	res, _ = client.PostForm(server.URL+"/test/unlock", map[string][]string{"password": {"wrong"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 403 || !strings.Contains(string(body[:]), "That password is not right") {
+		t.Errorf("Expected wrong password to be refused, got %d", res.StatusCode)
+	}
+
+	res, _ = client.PostForm(server.URL+"/test/unlock", map[string][]string{"password": {"letmein"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "Test!") {
+		t.Errorf("Expected entry to be shown once unlocked, got %d", res.StatusCode)
+	}
+
+	admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "password": {"changed"}})
+	res, _ = client.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != 403 {
+		t.Errorf("Expected changing the password to lock the entry again, got %d", res.StatusCode)
+	}
+
+	admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Test!"}, "remove_password": {"1"}})
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("Expected removing the password to unlock the entry, got %d", res.StatusCode)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	fixtures(t)
+
+	// Writing or removing entries needs a user to be signed in
+	client := browser()
+	res, _ := client.PostForm(server.URL+"/new", map[string][]string{"title": {"Anonymous"}, "date": {"2018-04-01"}, "content": {"Nobody"}})
+	res.Body.Close()
+	if res.Request.URL.Path != "/login" {
+		t.Errorf("Expected anonymous post to be sent to the login form, got %s", res.Request.URL.Path)
+	}
+	res, _ = client.PostForm(server.URL+"/test/delete", nil)
+	res.Body.Close()
+	res, _ = client.Get(server.URL + "/test/edit")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.RawQuery != "next=%2Ftest%2Fedit" || !strings.Contains(string(body[:]), `name="next" value="/test/edit"`) {
+		t.Errorf("Expected login form to return to the page asked for, got %s", res.Request.URL)
+	}
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("Expected anonymous delete to have been refused, got %d", res.StatusCode)
+	}
+
+	res, _ = client.PostForm(server.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"wrong"}, "next": {"/test/edit"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "That username and password do not match") {
+		t.Error("Expected wrong password to be refused")
+	}
+
+	res, _ = client.PostForm(server.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"password123"}, "next": {"/test/edit"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/test/edit" || !strings.Contains(string(body[:]), `action="/test/delete"`) {
+		t.Errorf("Expected signed in user to be returned to the edit form, got %s", res.Request.URL.Path)
+	}
+	res, _ = client.Get(server.URL + "/login")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "signed in as admin") {
+		t.Error("Expected login page to show who is signed in")
+	}
+
+	client.PostForm(server.URL+"/logout", nil)
+	res, _ = client.Get(server.URL + "/new")
+	res.Body.Close()
+	if res.Request.URL.Path != "/login" {
+		t.Errorf("Expected signing out to end the session, got %s", res.Request.URL.Path)
+	}
+}
+
+func TestLockout(t *testing.T) {
+	fixtures(t)
+
+	// Five wrong passwords lock the account, even with the right password after
+	client := browser()
+	for i := 0; i < 4; i++ {
+		res, _ := client.PostForm(server.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"wrong"}})
+		res.Body.Close()
+	}
+	res, _ := client.PostForm(server.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"wrong"}})
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "locked after too many failed attempts") {
+		t.Errorf("Expected account to be locked after five failures, got:\n\t%s", string(body[:]))
+	}
+	res, _ = client.PostForm(server.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"password123"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "locked after too many failed attempts") || strings.Contains(string(body[:]), "signed in as admin") {
+		t.Error("Expected locked account to be refused even with the right password")
+	}
+
+	// Admins see each sign in, failure and lockout
+	res, _ = admin.Get(server.URL + "/admin/security")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Count(string(body[:]), `class="security-login_failed"`) != 5 || strings.Count(string(body[:]), `class="security-lockout"`) != 2 || strings.Count(string(body[:]), `class="security-login"`) != 1 {
+		t.Errorf("Expected security events to be listed, got:\n\t%s", string(body[:]))
+	}
+
+	// Password changes are recorded too
+	rtr.Container.(*app.Container).Configuration.AdminToken = "secret"
+	req, _ := http.NewRequest("POST", server.URL+"/api/admin/users/admin/password", strings.NewReader(`{"password":"another-password"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	res, _ = http.DefaultClient.Do(req)
+	res.Body.Close()
+	res, _ = admin.Get(server.URL + "/admin/security?kind=password_changed")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Reset through the admin API") || strings.Contains(string(body[:]), "security-lockout") {
+		t.Errorf("Expected password change to be listed on its own, got:\n\t%s", string(body[:]))
+	}
+
+	// Passwords sent over HTTP Basic are counted once for each request, and refused in the same way once locked
+	fixtures(t)
+	us := model.Users{Container: rtr.Container.(*app.Container)}
+	user := model.User{Username: "script", Email: "script@example.com", Role: model.RoleEditor}
+	user.SetPassword("password123")
+	us.Save(user)
+	basic := func(password string) *http.Response {
+		req, _ := http.NewRequest("GET", server.URL+"/new", nil)
+		req.SetBasicAuth("script", password)
+		res, _ := browser().Do(req)
+		res.Body.Close()
+		return res
+	}
+	if res = basic("password123"); res.Request.URL.Path != "/new" {
+		t.Error("Expected the right password to be accepted over HTTP Basic")
+	}
+	for i := 0; i < 5; i++ {
+		basic("wrong")
+	}
+	if res = basic("password123"); res.Request.URL.Path != "/login" {
+		t.Error("Expected the right password to be refused over HTTP Basic once locked")
+	}
+	res, _ = admin.Get(server.URL + "/admin/security")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Count(string(body[:]), `class="security-login_failed"`) != 5 {
+		t.Errorf("Expected each wrong password sent over HTTP Basic to be recorded once, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestAuthors(t *testing.T) {
+	fixtures(t)
+
+	us := model.Users{Container: rtr.Container.(*app.Container)}
+	for _, username := range []string{"alice", "bob"} {
+		user := model.User{Username: username, Email: username + "@example.com", Role: model.RoleEditor}
+		user.SetPassword("password123")
+		us.Save(user)
+	}
+	alice := signIn("alice", "password123")
+	bob := signIn("bob", "password123")
+
+	res, _ := alice.PostForm(server.URL+"/new", map[string][]string{"title": {"By Alice"}, "date": {"2018-04-01"}, "content": {"Written by Alice"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/by-alice")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `Posted by <a class="p-author h-card" href="/author/alice">alice</a> on`) {
+		t.Errorf("Expected entry to name its author, got:\n\t%s", string(body[:]))
+	}
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `href="/author/alice">alice</a>`) {
+		t.Error("Expected index to name the author of each entry")
+	}
+
+	// Only the author or an admin may change an entry
+	res, _ = bob.Get(server.URL + "/by-alice/edit")
+	res.Body.Close()
+	if res.StatusCode != 403 {
+		t.Errorf("Expected another author to be refused the edit form, got %d", res.StatusCode)
+	}
+	res, _ = bob.PostForm(server.URL+"/by-alice/delete", nil)
+	res.Body.Close()
+	if res.StatusCode != 403 {
+		t.Errorf("Expected another author to be refused deleting, got %d", res.StatusCode)
+	}
+	res, _ = alice.PostForm(server.URL+"/by-alice/edit", map[string][]string{"title": {"By Alice"}, "date": {"2018-04-01"}, "content": {"Changed by Alice"}})
+	res.Body.Close()
+	res, _ = admin.PostForm(server.URL+"/by-alice/edit", map[string][]string{"title": {"By Alice"}, "date": {"2018-04-01"}, "content": {"Changed by an admin"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/by-alice")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Changed by an admin") || !strings.Contains(string(body[:]), `href="/author/alice"`) {
+		t.Error("Expected author and admins to change the entry, which keeps its author")
+	}
+
+	// Archives list the entries of each author
+	res, _ = http.Get(server.URL + "/author/alice")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Entries by alice") || !strings.Contains(string(body[:]), `href="/by-alice"`) || strings.Contains(string(body[:]), `href="/test"`) {
+		t.Errorf("Expected archive to list only the author's entries, got:\n\t%s", string(body[:]))
+	}
+	res, _ = http.Get(server.URL + "/author/bob")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "bob has not published any entries yet") {
+		t.Error("Expected archive to say when an author has no entries")
+	}
+	res, _ = http.Get(server.URL + "/author/nobody")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected 404 for an unknown author, got %d", res.StatusCode)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	fixtures(t)
+
+	us := model.Users{Container: rtr.Container.(*app.Container)}
+	user := model.User{Username: "friend", Email: "friend@example.com", Role: model.RoleReader}
+	user.SetPassword("password123")
+	us.Save(user)
+	friend := signIn("friend", "password123")
+
+	// Readers may sign in but not write
+	res, _ := friend.Get(server.URL + "/new")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 403 || !strings.Contains(string(body[:]), "Not Allowed") {
+		t.Errorf("Expected reader to be refused the new form, got %d", res.StatusCode)
+	}
+
+	// Admins assign roles
+	friendID := strconv.Itoa(us.FindByUsername("friend").ID)
+	res, _ = admin.PostForm(server.URL+"/admin/users", map[string][]string{"id": {friendID}, "role": {"editor"}})
+	res.Body.Close()
+	res, _ = admin.Get(server.URL + "/admin/users")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "<td>friend</td>") || us.FindByUsername("friend").Role != model.RoleEditor {
+		t.Errorf("Expected friend to be made an editor, got:\n\t%s", string(body[:]))
+	}
+
+	// Editors may write drafts but not manage the journal
+	res, _ = friend.PostForm(server.URL+"/new", map[string][]string{"title": {"Friendly Draft"}, "date": {"2018-04-01"}, "content": {"Not ready yet"}, "status": {"draft"}})
+	res.Body.Close()
+	res, _ = friend.Get(server.URL + "/drafts")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body[:]), "Friendly Draft") {
+		t.Errorf("Expected editor to write a draft, got:\n\t%s", string(body[:]))
+	}
+	for _, path := range []string{"/trash", "/admin/users", "/admin/entries"} {
+		res, _ = friend.Get(server.URL + path)
+		res.Body.Close()
+		if res.StatusCode != 403 {
+			t.Errorf("Expected editor to be refused %s, got %d", path, res.StatusCode)
+		}
+	}
+	res, _ = friend.PostForm(server.URL+"/admin/users", map[string][]string{"id": {friendID}, "role": {"admin"}})
+	res.Body.Close()
+	if res.StatusCode != 403 || us.FindByUsername("friend").Role != model.RoleEditor {
+		t.Error("Expected editor to be refused making themselves an admin")
+	}
+
+	// The last admin cannot give up their role
+	adminID := strconv.Itoa(us.FindByUsername("admin").ID)
+	res, _ = admin.PostForm(server.URL+"/admin/users", map[string][]string{"id": {adminID}, "role": {"reader"}})
+	res.Body.Close()
+	if us.FindByUsername("admin").Role != model.RoleAdmin {
+		t.Error("Expected the last admin to keep their role")
+	}
+}
+
+func TestOIDC(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	us := model.Users{Container: container}
+	user := model.User{Username: "friend", Email: "Friend@Example.com", Role: model.RoleEditor}
+	user.SetPassword("password123")
+	us.Save(user)
+
+	// An OpenID Connect provider, signing in whoever is set as its current account
+	account := `{"sub":"248289761001","email":"friend@example.com","email_verified":true}`
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			w.Write([]byte(`{"authorization_endpoint":"` + issuer.URL + `/authorize","token_endpoint":"` + issuer.URL + `/token","userinfo_endpoint":"` + issuer.URL + `/userinfo"}`))
+		case "/authorize":
+			http.Redirect(w, r, r.FormValue("redirect_uri")+"?code=code123&state="+r.FormValue("state"), 302)
+		case "/token":
+			w.Write([]byte(`{"access_token":"token123"}`))
+		case "/userinfo":
+			w.Write([]byte(account))
+		}
+	}))
+	defer issuer.Close()
+	container.Configuration.OIDCProvider = issuer.URL
+	container.Configuration.OIDCClientID = "journal"
+	container.Configuration.OIDCClientSecret = "secret"
+	signInWithProvider := func() (*http.Client, string) {
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{Jar: jar}
+		res, _ := client.Get(server.URL + "/login/oidc?next=/new")
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return client, string(body[:])
+	}
+
+	res, _ := http.Get(server.URL + "/login")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Sign in with Single Sign-On") {
+		t.Error("Expected signing in with the provider to be offered")
+	}
+
+	// The account is linked to the user with its email
+	_, page := signInWithProvider()
+	if !strings.Contains(page, "New Post") {
+		t.Errorf("Expected user to be signed in and returned to the new form, got:\n\t%s", page)
+	}
+
+	// Once linked, the account signs in even after its email changes
+	account = `{"sub":"248289761001","email":"changed@example.com","email_verified":false}`
+	if _, page = signInWithProvider(); !strings.Contains(page, "New Post") {
+		t.Error("Expected linked account to sign in")
+	}
+
+	// Accounts without a user are refused
+	account = `{"sub":"99","email":"stranger@example.com","email_verified":true}`
+	client, page := signInWithProvider()
+	if !strings.Contains(page, "could not be signed in with Single Sign-On") {
+		t.Errorf("Expected unknown account to be refused, got:\n\t%s", page)
+	}
+	res, _ = client.Get(server.URL + "/new")
+	res.Body.Close()
+	if res.Request.URL.Path != "/login" {
+		t.Error("Expected unknown account to stay signed out")
+	}
+}
+
+func TestAutosave(t *testing.T) {
+	fixtures(t)
+
+	res, _ := admin.Get(server.URL + "/new")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `data-autosave="/api/journals/new/autosave"`) {
+		t.Errorf("Expected new form to autosave, got:\n\t%s", string(body[:]))
+	}
+
+	res, err := admin.Post(server.URL+"/api/journals/new/autosave", "application/json", strings.NewReader(`{"title":"Unsaved","date":"2018-06-01","content":"Half written"}`))
+	if err != nil || res.StatusCode != 200 {
+		t.Errorf("Expected work in progress to be kept, got %d", res.StatusCode)
+	}
+	res.Body.Close()
+
+	res, _ = admin.Get(server.URL + "/api/journals/new/autosave")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `"title":"Unsaved"`) || !strings.Contains(string(body[:]), `"content":"Half written"`) {
+		t.Errorf("Expected work in progress to be returned, got:\n\t%s", string(body[:]))
+	}
+	res, _ = admin.Get(server.URL + "/api/journals/test/autosave")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected work in progress to be kept apart for each entry, got %d", res.StatusCode)
+	}
+
+	// Other editors neither see what someone else is writing nor keep work on entries they may not edit
+	us := model.Users{Container: rtr.Container.(*app.Container)}
+	user := model.User{Username: "friend", Email: "friend@example.com", Role: model.RoleEditor}
+	user.SetPassword("password123")
+	us.Save(user)
+	friend := signIn("friend", "password123")
+	res, _ = friend.Get(server.URL + "/api/journals/new/autosave")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected work in progress to be kept apart for each user, got %d", res.StatusCode)
+	}
+	res, _ = friend.Get(server.URL + "/api/journals/test/autosave")
+	res.Body.Close()
+	if res.StatusCode != 403 {
+		t.Errorf("Expected work in progress on an entry of someone else to be refused, got %d", res.StatusCode)
+	}
+
+	admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Unsaved"}, "date": {"2018-06-01"}, "content": {"Fully written"}})
+	res, _ = admin.Get(server.URL + "/api/journals/new/autosave")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected work in progress to be forgotten once saved, got %d", res.StatusCode)
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	fixtures(t)
+
+	admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Counted"}, "date": {"2018-06-01"}, "content": {"Just *four* short words"}})
+	res, _ := http.Get(server.URL + "/counted")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "4 words &middot; 1 min read") {
+		t.Errorf("Expected word count and reading time on the entry, got:\n\t%s", string(body[:]))
+	}
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `<span class="reading-time">&middot; 1 min read</span>`) {
+		t.Errorf("Expected reading time on the index, got:\n\t%s", string(body[:]))
+	}
+
+	// Entries written before word counts were stored are counted on start
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "min read") {
+		t.Error("Expected fixture entry not to have been counted yet")
+	}
+	model.CreateTables(rtr.Container.(*app.Container))
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "1 word &middot; 1 min read") {
+		t.Errorf("Expected fixture entry to have been counted, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestWikiLinks(t *testing.T) {
+	fixtures(t)
+
+	admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Linking"}, "date": {"2018-06-01"}, "content": {"See [[another test]], [[test-3]] and [[Nowhere]]"}})
+
+	res, _ := http.Get(server.URL + "/linking")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `<a class="wikilink" href="/test-2">Another Test</a>`) || !strings.Contains(string(body[:]), `<a class="wikilink" href="/test-3">A Final Test</a>`) || !strings.Contains(string(body[:]), `<span class="wikilink-missing">Nowhere</span>`) {
+		t.Errorf("Expected wiki links to be resolved, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/test-2")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Linked from") || !strings.Contains(string(body[:]), `<a href="/linking">Linking</a>`) {
+		t.Errorf("Expected backlink to be listed, got:\n\t%s", string(body[:]))
+	}
+	res, _ = http.Get(server.URL + "/test")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "Linked from") {
+		t.Error("Expected no backlinks for an entry nothing links to")
+	}
+
+	res, _ = http.Get(server.URL + "/feed.atom")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "[[") || !strings.Contains(string(body[:]), "See another test, test-3 and Nowhere") {
+		t.Errorf("Expected wiki links to be plain text in the feed, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestShortcodes(t *testing.T) {
+	fixtures(t)
+
+	res, _ := admin.PostForm(server.URL+"/admin/shortcodes", map[string][]string{"name": {"shipit"}, "value": {"🐿️"}, "action": {"save"}})
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `class="flash flash-success"`) {
+		t.Error("Expected custom shortcode to be added")
+	}
+
+	admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Celebrating"}, "date": {"2018-06-01"}, "content": {"Done :tada: so :shipit: but not `:smile:` or :nothing:"}})
+	res, _ = http.Get(server.URL + "/celebrating")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Done 🎉 so 🐿️ but not <code>:smile:</code> or :nothing:") {
+		t.Errorf("Expected shortcodes to be shown as emoji, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = http.Get(server.URL + "/feed.atom")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Done 🎉 so 🐿️") {
+		t.Error("Expected shortcodes to be shown as emoji in feeds")
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	fixtures(t)
+
+	res, _ := http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.Header.Get("Content-Security-Policy") != headers.DefaultContentSecurityPolicy || res.Header.Get("X-Frame-Options") != "DENY" ||
+		res.Header.Get("X-Content-Type-Options") != "nosniff" || res.Header.Get("Referrer-Policy") != "strict-origin-when-cross-origin" {
+		t.Errorf("Expected security headers on every page, got %v", res.Header)
+	}
+	if res.Header.Get("Strict-Transport-Security") != "" {
+		t.Error("Expected no HSTS over plain HTTP")
+	}
+
+	// Pages keep no inline scripts or handlers the policy would block
+	res, _ = admin.Get(server.URL + "/media")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "onclick=") || strings.Contains(string(body[:]), "<script>") {
+		t.Error("Expected no inline scripts to be served")
+	}
+
+	// Journals served over HTTPS ask browsers to keep to it, and may change the policy for their own theme
+	rtr.Container.(*app.Container).Configuration.URL = "https://journal.example.com"
+	rtr.Container.(*app.Container).Configuration.ContentSecurityPolicy = "default-src 'self' https://fonts.example.com"
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.Header.Get("Strict-Transport-Security") != "max-age=31536000; includeSubDomains" || res.Header.Get("Content-Security-Policy") != "default-src 'self' https://fonts.example.com" {
+		t.Errorf("Expected HSTS and the configured policy, got %v", res.Header)
+	}
+	rtr.Container.(*app.Container).Configuration.ContentSecurityPolicy = "off"
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	if res.Header.Get("Content-Security-Policy") != "" || res.Header.Get("X-Frame-Options") != "DENY" {
+		t.Error("Expected policy to be turned off, leaving the other headers")
+	}
+}
+
+func TestSanitized(t *testing.T) {
+	fixtures(t)
+
+	content := "Safe <em>words</em><script>alert(1)</script> <img src=x onerror=alert(2)> <a href=\"javascript:alert(3)\">link</a></textarea><b>"
+	admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Risky <i>title</i>"}, "date": {"2018-06-01"}, "content": {"<p>" + content}, "slug": {"risky-title"}})
+	res, _ := http.Get(server.URL + "/risky-title")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "Safe <em>words</em>") || !strings.Contains(string(body[:]), `<img src="x" />`) || !strings.Contains(string(body[:]), "<a>link</a>") {
+		t.Errorf("Expected allowed formatting to be kept in the entry, got:\n\t%s", string(body[:]))
+	}
+	if strings.Contains(string(body[:]), "alert(") || strings.Contains(string(body[:]), "<i>title</i>") {
+		t.Errorf("Expected scripts and markup in the title to be removed or escaped, got:\n\t%s", string(body[:]))
+	}
+
+	res, _ = admin.Get(server.URL + "/risky-title/edit")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body[:]), "</textarea><b>") || !strings.Contains(string(body[:]), "&lt;/textarea&gt;&lt;b&gt;") {
+		t.Errorf("Expected content to be escaped within the editor, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestRouting(t *testing.T) {
+	fixtures(t)
+
+	res, _ := http.Head(server.URL + "/test")
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected HEAD to be answered by the page, got %d", res.StatusCode)
+	}
+
+	res, _ = http.Get(server.URL + "/test/delete")
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed || res.Header.Get("Allow") != "POST" {
+		t.Errorf("Expected the methods a page allows when using another, got %d and '%s'", res.StatusCode, res.Header.Get("Allow"))
+	}
+
+	res, _ = http.Get(server.URL + "/test/history/latest")
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a revision that is not an ID not to be found, got %d", res.StatusCode)
+	}
+
+	// Posts may stand in for other methods
+	res, _ = http.Post(server.URL+"/api/journals/test", "application/json", strings.NewReader("{}"))
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed || res.Header.Get("Allow") != "GET, HEAD, PUT, DELETE" {
+		t.Errorf("Expected post to an entry to be refused, got %d and '%s'", res.StatusCode, res.Header.Get("Allow"))
+	}
+	request, _ := http.NewRequest("POST", server.URL+"/api/journals/test", strings.NewReader("{}"))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-HTTP-Method-Override", "DELETE")
+	res, _ = http.DefaultClient.Do(request)
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected overridden post to reach the guarded DELETE route, got %d", res.StatusCode)
+	}
+
+	// Pages are known by one address
+	noFollow := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }}
+	res, _ = noFollow.Get(server.URL + "/Test/?page=1")
+	res.Body.Close()
+	if res.StatusCode != http.StatusMovedPermanently || res.Header.Get("Location") != "/test?page=1" {
+		t.Errorf("Expected entry asked for with a trailing slash and capitals to be redirected, got %d and '%s'", res.StatusCode, res.Header.Get("Location"))
+	}
+	rtr.Container.(*app.Container).Configuration.URL = "https://journal.example.com"
+	rtr.Container.(*app.Container).Configuration.CanonicalRedirect = true
+	res, _ = noFollow.Get(server.URL + "/test")
+	res.Body.Close()
+	rtr.Container.(*app.Container).Configuration.URL = ""
+	rtr.Container.(*app.Container).Configuration.CanonicalRedirect = false
+	if res.StatusCode != http.StatusMovedPermanently || res.Header.Get("Location") != "https://journal.example.com/test" {
+		t.Errorf("Expected page to be redirected to the journal's own address, got %d and '%s'", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	// Requests passed on by trusted proxies are seen as the client made them
+	rtr.TrustedProxies = proxy.ParseProxies("127.0.0.1")
+	request, _ = http.NewRequest("GET", server.URL+"/feed.atom", nil)
+	request.Header.Set("X-Forwarded-Host", "journal.example.com")
+	request.Header.Set("X-Forwarded-Proto", "https")
+	res, _ = http.DefaultClient.Do(request)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	rtr.TrustedProxies = nil
+	if !strings.Contains(string(body[:]), "https://journal.example.com/test") {
+		t.Errorf("Expected links built from the forwarded host and scheme, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestReservedPaths(t *testing.T) {
+	reserved := map[string]bool{}
+	for _, path := range model.ReservedPaths {
+		reserved[path] = true
+	}
+
+	// Names with a dot or a parameter cannot be taken by an entry or a hosted journal
+	for _, route := range rtr.Routes {
+		segment := strings.SplitN(strings.TrimPrefix(route.Pattern(), "/"), "/", 2)[0]
+		if segment == "" || strings.ContainsAny(segment, ".{") {
+			continue
+		}
+		if !reserved[segment] {
+			t.Errorf("Expected '%s', served by %s, to be reserved", segment, route.Pattern())
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	fixtures(t)
+
+	res, _ := http.Get(server.URL + "/static/css/default.min.css")
+	res.Body.Close()
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" || res.Header.Get("Cache-Control") != "public, max-age=86400" ||
+		res.Header.Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("Expected stylesheet with caching headers, got %d and %v", res.StatusCode, res.Header)
+	}
+
+	request, _ := http.NewRequest("GET", server.URL+"/static/css/default.min.css", nil)
+	request.Header.Set("If-None-Match", etag)
+	res, _ = http.DefaultClient.Do(request)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected unchanged stylesheet not to be sent again, got %d", res.StatusCode)
+	}
+
+	res, _ = http.Get(server.URL + "/static/css")
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected directory not to be listed, got %d", res.StatusCode)
+	}
+
+	res, _ = http.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `href="/static/css/default.min.css"`) || !strings.Contains(string(body[:]), `src="/static/js/default.min.js"`) {
+		t.Error("Expected pages to load their stylesheet and script from /static/")
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.BasePath = "/journal"
+	defer func() { container.BasePath = "" }()
+	mounted := router.NewRouter(container)
+	mounted.Use(auth.NewCSRF(mounted, &web.Forbidden{}).Middleware)
+	site := httptest.NewServer(mounted)
+	defer site.Close()
+	noFollow := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }}
+
+	res, _ := http.Get(site.URL + "/journal/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body[:]), `href="/journal/static/css/default.min.css"`) ||
+		!strings.Contains(string(body[:]), `href="/journal/test"`) {
+		t.Errorf("Expected index beneath the base path linking within it, got %d", res.StatusCode)
+	}
+
+	res, _ = http.Get(site.URL + "/journal/static/css/default.min.css")
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected stylesheet beneath the base path, got %d", res.StatusCode)
+	}
+
+	tables := []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/", http.StatusFound, "/journal/"},
+		{"/journal/Test/", http.StatusMovedPermanently, "/journal/test"},
+		{"/journal/new", http.StatusFound, "/journal/login?next=%2Fnew"},
+		{"/test", http.StatusNotFound, ""},
+	}
+	for _, table := range tables {
+		res, _ = noFollow.Get(site.URL + table.path)
+		res.Body.Close()
+		if res.StatusCode != table.status || res.Header.Get("Location") != table.location {
+			t.Errorf("Expected %s to give %d and '%s', got %d and '%s'", table.path, table.status, table.location, res.StatusCode, res.Header.Get("Location"))
+		}
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	fixtures(t)
+	reader := browser()
+
+	for _, path := range []string{"/test", "/feed.atom"} {
+		res, _ := reader.Get(server.URL + path)
+		res.Body.Close()
+		etag := res.Header.Get("ETag")
+		modified := res.Header.Get("Last-Modified")
+		if res.StatusCode != http.StatusOK || etag == "" || modified == "" {
+			t.Errorf("Expected %s to be tagged and dated, got %d and %v", path, res.StatusCode, res.Header)
+		}
+
+		for header, value := range map[string]string{"If-None-Match": etag, "If-Modified-Since": modified} {
+			request, _ := http.NewRequest("GET", server.URL+path, nil)
+			request.Header.Set(header, value)
+			res, _ = reader.Do(request)
+			body, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if res.StatusCode != http.StatusNotModified || len(body) != 0 {
+				t.Errorf("Expected %s not to be sent again with %s, got %d", path, header, res.StatusCode)
+			}
+		}
+	}
+
+	// Editing the entry changes its page and feed
+	res, _ := reader.Get(server.URL + "/test")
+	res.Body.Close()
+	etag := res.Header.Get("ETag")
+	admin.PostForm(server.URL+"/test/edit", map[string][]string{"title": {"Test"}, "date": {"2018-01-01"}, "content": {"Changed!"}})
+	request, _ := http.NewRequest("GET", server.URL+"/test", nil)
+	request.Header.Set("If-None-Match", etag)
+	res, _ = reader.Do(request)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body[:]), "Changed!") || res.Header.Get("Last-Modified") == "Mon, 01 Jan 2018 00:00:00 GMT" {
+		t.Errorf("Expected edited entry to be sent again, dated when it was edited, got %d and %v", res.StatusCode, res.Header)
+	}
+}
+
+func TestExport(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Configuration.ArticlesPerPage = 2
+	container.Configuration.MediaPath, _ = ioutil.TempDir("", "media")
+	defer os.RemoveAll(container.Configuration.MediaPath)
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+	db := container.Db
+	db.Exec("INSERT INTO category (slug, name, parent_id) VALUES (?, ?, ?)", "travel", "Travel", "0")
+	db.Exec("UPDATE journal SET category_id = 1 WHERE slug = ?", "test-2")
+	db.Exec("INSERT INTO journal (slug, title, content, date, status) VALUES (?, ?, ?, ?, ?)", "draft", "Unfinished", "Draft", "2018-04-01", model.JournalStatusDraft)
+
+	written, err := export.Static(container, rtr, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if written < 12 {
+		t.Errorf("Expected every page to be exported, got %d file(s)", written)
+	}
+
+	read := func(file string) string {
+		body, err := ioutil.ReadFile(dir + "/" + file)
+		if err != nil {
+			t.Errorf("Expected %s to be exported", file)
+		}
+		return string(body)
+	}
+	index := read("index.html")
+	if !strings.Contains(index, `<a href="/test-3">A Final Test</a>`) || !strings.Contains(index, `<a href="/page/2/">2</a>`) || strings.Contains(index, "Create New Post") {
+		t.Errorf("Expected index to be exported with static pagination, got:\n\t%s", index)
+	}
+	if !strings.Contains(read("page/2/index.html"), `<a href="/test">Test</a>`) {
+		t.Error("Expected second page of the index to be exported")
+	}
+	if read("static/css/default.min.css") == "" || !strings.Contains(index, `href="/static/css/default.min.css"`) {
+		t.Error("Expected stylesheet to be exported where pages link to it")
+	}
+	if !strings.Contains(read("test-2/index.html"), "Test again!") || !strings.Contains(read("category/travel/index.html"), "Another Test") {
+		t.Error("Expected entries and their categories to be exported")
+	}
+	if !strings.Contains(read("feed.atom"), "<title>A Final Test</title>") || !strings.Contains(read("feed.json"), "A Final Test") {
+		t.Error("Expected feeds to be exported")
+	}
+	if _, err := os.Stat(dir + "/draft/index.html"); err == nil {
+		t.Error("Expected drafts to be left out of the export")
+	}
+
+	// Entries written as Markdown can be imported again
+	markdown, _ := ioutil.TempDir("", "posts")
+	defer os.RemoveAll(markdown)
+	db.Exec("INSERT INTO journal_tag (journal_id, name, slug) VALUES (?, ?, ?)", 2, "Road Trip", "road-trip")
+	if written, err := export.Markdown(container, markdown); err != nil || written != 4 {
+		t.Errorf("Expected every entry to be exported as Markdown, got %d file(s), %v", written, err)
+	}
+	entry, _ := ioutil.ReadFile(markdown + "/2018-02-01-test-2.md")
+	draft, _ := ioutil.ReadFile(markdown + "/2018-04-01-draft.md")
+	if !strings.Contains(string(entry), "tags: [Road Trip]") || !strings.Contains(string(entry), "categories: [Travel]") || !strings.Contains(string(draft), "draft: true") {
+		t.Error("Expected entries to be written with their tags and categories and drafts marked")
+	}
+	db.Exec("DELETE FROM journal")
+	db.Exec("DELETE FROM journal_tag")
+	report, err := importer.Markdown(container, markdown, false)
+	if err != nil || len(report.Created) != 4 {
+		t.Errorf("Expected exported entries to be imported again, got %v", report)
+	}
+	j := (&model.Journals{Container: container}).FindBySlug("test-2")
+	if j = (&model.JournalTags{Container: container}).Load(j); j.GetEditableDate() != "2018-02-01" || j.Content != "<p>Test again!</p>" || j.CategoryID != 1 || strings.Join(j.Tags, ",") != "Road Trip" {
+		t.Errorf("Expected entry to survive the round trip, got %v", j)
+	}
+}
+
+func TestImport(t *testing.T) {
+	fixtures(t)
+	dir, _ := ioutil.TempDir("", "posts")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(dir+"/2019-05-04-trip-to-rome.md", []byte("---\ntitle: Trip to Rome\ntags: [Italy, Road Trip]\ncategories: [Travel, Europe]\n---\nWe went to *Rome*."), 0644)
+	ioutil.WriteFile(dir+"/test.md", []byte("---\ntitle: Test Again\n---\nAlready here."), 0644)
+
+	report, err := importer.Markdown(rtr.Container.(*app.Container), dir, false)
+	if err != nil || len(report.Created) != 1 || len(report.Duplicates) != 1 || report.Duplicates[0] != "test" {
+		t.Errorf("Expected one entry to be imported and one duplicate, got %v", report)
+	}
+
+	res, _ := http.Get(server.URL + "/trip-to-rome")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "<em>Rome</em>") || !strings.Contains(string(body[:]), "Saturday May 4, 2019") || !strings.Contains(string(body[:]), `href="/category/europe"`) {
+		t.Errorf("Expected imported entry to be shown, got:\n\t%s", string(body[:]))
+	}
+	if !strings.Contains(string(body[:]), `href="/tag/italy"`) || !strings.Contains(string(body[:]), `href="/tag/road-trip"`) {
+		t.Errorf("Expected imported entry to keep every tag, got:\n\t%s", string(body[:]))
+	}
+	cs := model.Categories{Container: rtr.Container.(*app.Container)}
+	if europe := cs.FindBySlug("europe"); europe.ParentID != cs.FindBySlug("travel").ID {
+		t.Error("Expected categories to be nested as listed")
+	}
+}
+
+func TestWordPressImport(t *testing.T) {
+	fixtures(t)
+	export := `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<wp:category><wp:category_nicename>europe</wp:category_nicename><wp:category_parent>travel</wp:category_parent><wp:cat_name>Europe</wp:cat_name></wp:category>
+	<wp:category><wp:category_nicename>travel</wp:category_nicename><wp:category_parent></wp:category_parent><wp:cat_name>Travel</wp:cat_name></wp:category>
+	<item>
+		<title>Trip to Rome</title>
+		<content:encoded><![CDATA[<p>We went to Rome.</p>]]></content:encoded>
+		<wp:post_date>2019-05-04 11:00:00</wp:post_date>
+		<wp:post_name>trip-to-rome</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="europe"><![CDATA[Europe]]></category>
+		<category domain="post_tag" nicename="food"><![CDATA[Food]]></category>
+		<wp:comment>
+			<wp:comment_author>Reader</wp:comment_author>
+			<wp:comment_date_gmt>2019-05-05 09:30:00</wp:comment_date_gmt>
+			<wp:comment_content>Lovely!</wp:comment_content>
+			<wp:comment_approved>1</wp:comment_approved>
+		</wp:comment>
+	</item>
+	<item>
+		<title>Test</title>
+		<content:encoded><![CDATA[Again]]></content:encoded>
+		<wp:post_name>test</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+	</item>
+</channel>
+</rss>`
+	container := rtr.Container.(*app.Container)
+
+	report, err := importer.WordPress(container, strings.NewReader(export), true)
+	if err != nil || len(report.Created) != 1 || len(report.Duplicates) != 1 || report.Comments != 1 {
+		t.Errorf("Expected dry run to report one entry, got %v", report)
+	}
+	res, _ := http.Get(server.URL + "/trip-to-rome")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected nothing to be saved on a dry run")
+	}
+
+	if _, err := importer.WordPress(container, strings.NewReader(export), false); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	res, _ = http.Get(server.URL + "/trip-to-rome")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "<p>We went to Rome.</p>") || !strings.Contains(string(body[:]), `<a href="/category/travel">Travel</a> &rsaquo; <a class="p-category" href="/category/europe">Europe</a>`) || !strings.Contains(string(body[:]), "Lovely!") {
+		t.Errorf("Expected imported post with its category and comment, got:\n\t%s", string(body[:]))
+	}
+	if !strings.Contains(string(body[:]), `href="/tag/food"`) {
+		t.Errorf("Expected imported post with its tags, got:\n\t%s", string(body[:]))
+	}
+}
+
+func TestActivityPub(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Configuration.URL = server.URL
+	container.Configuration.ActivityPubUser = "journal"
+	container.Db.Exec("DELETE FROM job")
+
+	// A remote account, checking what the journal sends it was signed by the journal's key
+	remoteKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	remotePem, _ := activitypub.EncodePublicKey(&remoteKey.PublicKey)
+	received := []activitypub.Activity{}
+	var remote *httptest.Server
+	remote = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			body, _ := ioutil.ReadAll(r.Body)
+			signature, err := activitypub.ParseSignature(r)
+			actor, _ := (activitypub.Client{}).FetchActor(signature.KeyID)
+			key, _ := activitypub.ParsePublicKey(actor.PublicKey.PublicKeyPem)
+			if err != nil || key == nil || signature.Verify(r, body, key) != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			activity := activitypub.Activity{}
+			gojson.Unmarshal(body, &activity)
+			received = append(received, activity)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		gojson.NewEncoder(w).Encode(activitypub.Actor{
+			ID:        remote.URL + "/actor",
+			Type:      "Person",
+			Inbox:     remote.URL + "/inbox",
+			PublicKey: activitypub.PublicKey{ID: remote.URL + "/actor#main-key", Owner: remote.URL + "/actor", PublicKeyPem: remotePem},
+		})
+	}))
+	defer remote.Close()
+	dispatcher := queue.NewDispatcher(container)
+	dispatcher.Handle(federation.JobType, federation.Handler(nil))
+
+	// Discover the actor from the account
+	account := "journal@" + strings.TrimPrefix(server.URL, "http://")
+	res, _ := http.Get(server.URL + "/.well-known/webfinger?resource=acct:" + account)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body), `"href":"`+server.URL+`/activitypub/actor"`) {
+		t.Fatalf("Expected account to point to the actor, got:\n\t%s", string(body))
+	}
+	res, _ = http.Get(server.URL + "/activitypub/outbox")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `"totalItems":3`) || !strings.Contains(string(body), `"name":"A Final Test"`) {
+		t.Errorf("Expected published entries in the outbox, got:\n\t%s", string(body))
+	}
+
+	// Follow the journal
+	follow := `{"id":"` + remote.URL + `/follows/1","type":"Follow","actor":"` + remote.URL + `/actor","object":"` + server.URL + `/activitypub/actor"}`
+	inbox := func(activity string) int {
+		request, _ := http.NewRequest("POST", server.URL+"/activitypub/inbox", strings.NewReader(activity))
+		activitypub.Sign(request, []byte(activity), remote.URL+"/actor#main-key", remoteKey)
+		res, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	if status := inbox(follow); status != 202 {
+		t.Fatalf("Expected follow to be accepted, got %d", status)
+	}
+	for dispatcher.RunNext() {
+	}
+	if len(received) != 1 || received[0].Type != "Accept" || received[0].ObjectID() != remote.URL+"/follows/1" {
+		t.Fatalf("Expected signed reply accepting the follow, got %v", received)
+	}
+	res, _ = http.Get(server.URL + "/activitypub/followers")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `"totalItems":1`) {
+		t.Errorf("Expected one follower, got:\n\t%s", string(body))
+	}
+
+	// New entries are sent to followers, then sent again as updates
+	request, _ := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 4","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>"}`))
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	request, _ = http.NewRequest("POST", server.URL+"/api/v1/post/test-4", strings.NewReader(`{"title":"Test 4","content":"<p>Test 4 again!</p>"}`))
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	for dispatcher.RunNext() {
+	}
+	if len(received) != 3 || received[1].Type != "Create" || received[2].Type != "Update" || received[1].ObjectID() != server.URL+"/activitypub/entries/test-4" {
+		t.Fatalf("Expected entry to be created then updated, got %v", received)
+	}
+	res, _ = http.Get(received[1].ObjectID())
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Test 4 again!") {
+		t.Errorf("Expected article to be served at its ID, got:\n\t%s", string(body))
+	}
+
+	// Unfollow
+	if status := inbox(`{"type":"Undo","actor":"` + remote.URL + `/actor","object":` + follow + `}`); status != 202 {
+		t.Errorf("Expected unfollow to be accepted, got %d", status)
+	}
+	fs := model.Followers{Container: container}
+	if fs.Count() != 0 {
+		t.Error("Expected follower to have been removed")
+	}
+}
+
+func TestMicropub(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Configuration.URL = server.URL
+
+	// An IndieAuth token endpoint, vouching for a token issued to the journal's own site
+	tokenEndpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer indieauth" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"me":"` + server.URL + `/","client_id":"https://client.example.com/","scope":"create update delete"}`))
+	}))
+	defer tokenEndpoint.Close()
+	container.Configuration.IndieAuthTokenEndpoint = tokenEndpoint.URL
+	micropub := func(body string, contentType string, token string) *http.Response {
+		request, _ := http.NewRequest("POST", server.URL+"/micropub", strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Authorization", "Bearer "+token)
+		res, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		res.Body.Close()
+		return res
+	}
+
+	// The endpoint is advertised on every page
+	res, _ := http.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `<link rel="micropub" href="/micropub" />`) || !strings.Contains(string(body), `<link rel="token_endpoint" href="`+tokenEndpoint.URL+`" />`) {
+		t.Errorf("Expected Micropub endpoint to be advertised, got:\n\t%s", string(body))
+	}
+
+	// Tokens the endpoint does not vouch for are refused
+	res = micropub("h=entry&content=Hello", "application/x-www-form-urlencoded", "stolen")
+	if res.StatusCode != 403 {
+		t.Errorf("Expected unknown token to be refused, got %d", res.StatusCode)
+	}
+
+	// Post a note
+	res = micropub("h=entry&content=Posted+from+my+phone&category[]=Mobile", "application/x-www-form-urlencoded", "indieauth")
+	if res.StatusCode != 201 || res.Header.Get("Location") != server.URL+"/posted-from-my-phone" {
+		t.Fatalf("Expected note to be created, got %d at %s", res.StatusCode, res.Header.Get("Location"))
+	}
+	res, _ = http.Get(server.URL + "/category/mobile")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Posted from my phone") {
+		t.Errorf("Expected note to be filed under a new category, got:\n\t%s", string(body))
+	}
+
+	// Update it, then read back its source
+	res = micropub(`{"action":"update","url":"`+server.URL+`/posted-from-my-phone","replace":{"content":["Posted from my laptop"]}}`, "application/json", "indieauth")
+	if res.StatusCode != 204 {
+		t.Errorf("Expected note to be updated, got %d", res.StatusCode)
+	}
+	request, _ := http.NewRequest("GET", server.URL+"/micropub?q=source&url="+url.QueryEscape(server.URL+"/posted-from-my-phone"), nil)
+	request.Header.Set("Authorization", "Bearer indieauth")
+	res, _ = http.DefaultClient.Do(request)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `"content":["Posted from my laptop"]`) || !strings.Contains(string(body), `"category":["Mobile"]`) {
+		t.Errorf("Expected source of the updated note, got:\n\t%s", string(body))
+	}
+
+	// Tokens issued by the journal act for their user, readers being refused and editors only changing their own entries
+	us := model.Users{Container: container}
+	ts := model.Tokens{Container: container}
+	for username, role := range map[string]string{"watcher": model.RoleReader, "friend": model.RoleEditor} {
+		user := model.User{Username: username, Email: username + "@example.com", Role: role}
+		user.SetPassword("password123")
+		us.Save(user)
+	}
+	_, reader, _ := ts.Issue(us.FindByUsername("watcher").ID, "Phone", []string{model.ScopeRead, model.ScopeWrite})
+	_, editor, _ := ts.Issue(us.FindByUsername("friend").ID, "Phone", []string{model.ScopeRead, model.ScopeWrite})
+	if res = micropub("h=entry&content=Sneaked+in", "application/x-www-form-urlencoded", reader); res.StatusCode != 403 {
+		t.Errorf("Expected token of a reader to be refused, got %d", res.StatusCode)
+	}
+	if res = micropub(`{"action":"delete","url":"`+server.URL+`/posted-from-my-phone"}`, "application/json", editor); res.StatusCode != 403 {
+		t.Errorf("Expected editor to be refused deleting an entry of someone else, got %d", res.StatusCode)
+	}
+	if res = micropub("h=entry&content=From+a+friend", "application/x-www-form-urlencoded", editor); res.StatusCode != 201 {
+		t.Fatalf("Expected editor to post a note, got %d", res.StatusCode)
+	}
+	if res = micropub(`{"action":"delete","url":"`+server.URL+`/from-a-friend"}`, "application/json", editor); res.StatusCode != 204 {
+		t.Errorf("Expected editor to delete their own note, got %d", res.StatusCode)
+	}
+
+	// Delete it
+	res = micropub(`{"action":"delete","url":"`+server.URL+`/posted-from-my-phone"}`, "application/json", "indieauth")
+	if res.StatusCode != 204 {
+		t.Errorf("Expected note to be deleted, got %d", res.StatusCode)
+	}
+	res, _ = http.Get(server.URL + "/posted-from-my-phone")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Error("Expected deleted note to be gone")
+	}
+}
+
+func TestEmail(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Configuration.MailFrom = "me@example.com"
+	dir, _ := ioutil.TempDir("", "journal-email")
+	defer os.RemoveAll(dir)
+	container.Configuration.MediaPath = dir
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	mailServer := &smtpd.Server{Handler: email.Handler(container)}
+	go mailServer.Serve(listener)
+	defer mailServer.Close()
+
+	// Mail from anyone else is refused
+	message := "From: someone@example.com\r\nSubject: Let me in\r\n\r\nHello\r\n"
+	if err := smtp.SendMail(listener.Addr().String(), nil, "someone@example.com", []string{"journal@example.com"}, []byte(message)); err == nil {
+		t.Error("Expected mail from another sender to be refused")
+	}
+
+	// Mail from the allowed address becomes a draft, with its photo in the media library
+	message = strings.Join([]string{
+		"From: Me <me@example.com>",
+		"Subject: Written on the train",
+		`Content-Type: multipart/mixed; boundary="part"`,
+		"",
+		"--part",
+		"Content-Type: text/plain",
+		"",
+		"Somewhere near *Reading*.",
+		"--part",
+		`Content-Type: image/png; name="window.png"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		"iVBORw0KGgoAAAANSUhEUg==",
+		"--part--",
+		"",
+	}, "\r\n")
+	if err := smtp.SendMail(listener.Addr().String(), nil, "me@example.com", []string{"journal@example.com"}, []byte(message)); err != nil {
+		t.Fatalf("Expected mail to be accepted, got %s", err)
+	}
+	res, _ := admin.Get(server.URL + "/drafts")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Written on the train") {
+		t.Errorf("Expected email to be listed in drafts, got:\n\t%s", string(body))
+	}
+	res, _ = admin.Get(server.URL + "/written-on-the-train")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "<em>Reading</em>") || !strings.Contains(string(body), `src="/media/window.png"`) {
+		t.Errorf("Expected draft with the photo embedded, got:\n\t%s", string(body))
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	dir, _ := ioutil.TempDir("", "journal-telegram")
+	defer os.RemoveAll(dir)
+	container.Configuration.MediaPath = dir
+	container.Configuration.URL = server.URL
+	container.Configuration.TelegramChatID = "42"
+	container.Configuration.TelegramToken = "token"
+
+	// A Bot API with a photo waiting to be collected, keeping the replies sent back
+	replies := []string{}
+	bot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bottoken/getUpdates":
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":1,"message":{"chat":{"id":42},"caption":"At the harbour\nBoats everywhere.","photo":[{"file_id":"harbour","width":800,"height":600}]}}]}`))
+		case "/bottoken/getFile":
+			w.Write([]byte(`{"ok":true,"result":{"file_id":"harbour","file_path":"photos/harbour.png"}}`))
+		case "/file/bottoken/photos/harbour.png":
+			w.Write([]byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"))
+		case "/bottoken/sendMessage":
+			params := map[string]interface{}{}
+			gojson.NewDecoder(r.Body).Decode(&params)
+			replies = append(replies, params["text"].(string))
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		}
+	}))
+	defer bot.Close()
+	container.Configuration.TelegramEndpoint = bot.URL
+
+	listener := telegram.NewListener(container)
+	if err := listener.Poll(context.Background(), 0); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(replies) != 1 || replies[0] != "Posted At the harbour\n"+server.URL+"/at-the-harbour" {
+		t.Errorf("Expected reply with the new entry, got %v", replies)
+	}
+	res, _ := http.Get(server.URL + "/at-the-harbour")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Boats everywhere.") || !strings.Contains(string(body), `src="/media/harbour.png"`) {
+		t.Errorf("Expected entry with the photo embedded, got:\n\t%s", string(body))
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Configuration.AdminToken = "secret"
+	container.Configuration.URL = server.URL
+	container.Db.Exec("DELETE FROM job")
+	dispatcher := queue.NewDispatcher(container)
+	dispatcher.Handle(webhook.JobType, webhook.Handler(nil))
+
+	// A receiver checking each event was signed with the secret it was given
+	var secret string
+	events := []string{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !webhookadapter.Verify(secret, body, r.Header.Get("X-Journal-Signature")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received := webhook.Body{}
+		gojson.Unmarshal(body, &received)
+		events = append(events, received.Event+" "+received.Entry.Slug+" "+received.Entry.URL)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	adminAPI := func(method string, path string, body string) (int, string) {
+		request, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		request.Header.Add("Authorization", "Bearer secret")
+		res, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		content, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return res.StatusCode, string(content)
+	}
+	status, body := adminAPI("PUT", "/api/admin/webhooks", `{"url":"`+receiver.URL+`","events":["entry.created","entry.published","entry.deleted"]}`)
+	created := webhookWithSecret{}
+	gojson.Unmarshal([]byte(body), &created)
+	if status != 201 || created.ID != 1 || created.Secret == "" {
+		t.Fatalf("Expected webhook to be created with a secret, got:\n\t%s", body)
+	}
+	secret = created.Secret
+
+	// Create, update and delete an entry; updates were not asked for
+	request, _ := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 4","date":"2018-06-01T00:00:00Z","content":"<p>Test 4!</p>"}`))
+	res, _ := admin.Do(request)
+	res.Body.Close()
+	request, _ = http.NewRequest("POST", server.URL+"/api/v1/post/test-4", strings.NewReader(`{"title":"Test 4","content":"<p>Test 4 again!</p>"}`))
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	request, _ = http.NewRequest("DELETE", server.URL+"/api/journals/test-4", nil)
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	for dispatcher.RunNext() {
+	}
+	expected := []string{"entry.created test-4 " + server.URL + "/test-4", "entry.published test-4 " + server.URL + "/test-4", "entry.deleted test-4 " + server.URL + "/test-4"}
+	if strings.Join(events, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected signed events in order, got %v", events)
+	}
+
+	// Each delivery is logged against the webhook
+	status, body = adminAPI("GET", "/api/admin/webhooks/1", "")
+	if status != 200 || strings.Count(body, `"status_code":204`) != 3 || strings.Contains(body, secret) {
+		t.Errorf("Expected deliveries to be logged, got:\n\t%s", body)
+	}
+
+	// Removed webhooks are no longer told about changes
+	if status, _ := adminAPI("DELETE", "/api/admin/webhooks/1", ""); status != 204 {
+		t.Errorf("Expected webhook to be deleted, got %d", status)
+	}
+	request, _ = http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 5","date":"2018-06-01T00:00:00Z","content":"<p>Test 5!</p>"}`))
+	res, _ = admin.Do(request)
+	res.Body.Close()
+	for dispatcher.RunNext() {
+	}
+	if len(events) != 3 {
+		t.Errorf("Expected no more events, got %v", events)
+	}
+}
+
+func TestBlogroll(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Db.Exec("DELETE FROM job")
+	dispatcher := queue.NewDispatcher(container)
+	dispatcher.Handle(blogroll.JobType, blogroll.Handler(nil))
+
+	// Two sites publishing feeds in different formats
+	feeds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss.xml":
+			w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>Alice's Blog</title><link>https://alice.example.com/</link>` +
+				`<item><title>Alice's &lt;Post&gt;</title><link>https://alice.example.com/post</link><description>Hello there</description><pubDate>Thu, 15 Feb 2018 12:00:00 GMT</pubDate></item>` +
+				`</channel></rss>`))
+		case "/atom.xml":
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Bob's Notes</title><link href="https://bob.example.com/"/>` +
+				`<entry><title>Bob's Post</title><link href="https://bob.example.com/post"/><updated>2018-02-20T09:00:00Z</updated></entry>` +
+				`</feed>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer feeds.Close()
+
+	// Following a feed fetches it straight away
+	res, err := admin.PostForm(server.URL+"/admin/blogroll", url.Values{"action": {"add"}, "url": {feeds.URL + "/rss.xml"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Blogroll updated") || !strings.Contains(string(body), "Alice&#39;s Blog") {
+		t.Errorf("Expected feed to be followed and named, got:\n\t%s", string(body))
+	}
+
+	// Importing a list of feeds fetches the new ones in the background
+	document := `<opml version="2.0"><body><outline text="Friends">` +
+		`<outline text="Alice" xmlUrl="` + feeds.URL + `/rss.xml"/><outline text="Bob" xmlUrl="` + feeds.URL + `/atom.xml"/>` +
+		`</outline></body></opml>`
+	upload := &bytes.Buffer{}
+	writer := multipart.NewWriter(upload)
+	writer.WriteField("action", "import")
+	part, _ := writer.CreateFormFile("opml", "feeds.opml")
+	part.Write([]byte(document))
+	writer.Close()
+	res, _ = admin.Post(server.URL+"/admin/blogroll", writer.FormDataContentType(), upload)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Imported 1 feed,") {
+		t.Errorf("Expected only the new feed to be imported, got:\n\t%s", string(body))
+	}
+	for dispatcher.RunNext() {
+	}
+
+	// Items from both feeds are read alongside the journal, newest first
+	res, _ = http.Get(server.URL + "/reading")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	content := string(body)
+	bob := strings.Index(content, "Bob&#39;s Post")
+	alice := strings.Index(content, "Alice&#39;s &lt;Post&gt;")
+	own := strings.Index(content, "A Final Test")
+	if own < 0 || bob < 0 || alice < 0 || !(own < bob && bob < alice) {
+		t.Errorf("Expected items and entries to be read newest first, got:\n\t%s", content)
+	}
+	if !strings.Contains(content, `<a href="https://bob.example.com/" rel="noopener">Bob&#39;s Notes</a></li>`) {
+		t.Error("Expected blogroll to be listed")
+	}
+
+	// The blogroll can be taken to a feed reader
+	res, _ = http.Get(server.URL + "/blogroll.opml")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Header.Get("Content-Type") != "text/x-opml; charset=utf-8" || strings.Count(string(body), "xmlUrl=") != 2 || !strings.Contains(string(body), `text="Bob&#39;s Notes"`) {
+		t.Errorf("Expected blogroll to be exported, got:\n\t%s", string(body))
+	}
+}
+
+func TestSitemap(t *testing.T) {
+	fixtures(t)
+
+	// Editing an entry keeps a revision, which dates its last change
+	request, _ := http.NewRequest("POST", server.URL+"/api/v1/post/test-2", strings.NewReader(`{"title":"Another Test","content":"<p>Changed!</p>"}`))
+	res, err := admin.Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	res.Body.Close()
+
+	res, _ = http.Get(server.URL + "/sitemap.xml")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	content := string(body)
+	if res.StatusCode != 200 || res.Header.Get("Content-Type") != "application/xml; charset=utf-8" || strings.Count(content, "<url>") != 4 {
+		t.Fatalf("Expected the home page and each entry to be listed, got:\n\t%s", content)
+	}
+	if !strings.Contains(content, "<loc>"+server.URL+"/test-3</loc>\n    <lastmod>2018-03-01T00:00:00Z</lastmod>") {
+		t.Errorf("Expected an unchanged entry to be dated, got:\n\t%s", content)
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	if !strings.Contains(content, "<loc>"+server.URL+"/test-2</loc>\n    <lastmod>"+today) {
+		t.Errorf("Expected an edited entry to be dated by its change, got:\n\t%s", content)
+	}
+
+	res, _ = http.Get(server.URL + "/robots.txt")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body), "Disallow: /admin\n") || !strings.Contains(string(body), "Sitemap: "+server.URL+"/sitemap.xml") {
+		t.Errorf("Expected robots.txt pointing to the sitemap, got:\n\t%s", string(body))
+	}
+}
+
+func TestOEmbed(t *testing.T) {
+	fixtures(t)
+
+	// Entries advertise where to find their preview
+	res, err := http.Get(server.URL + "/test-2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	discovery := server.URL + "/oembed?url=" + url.QueryEscape(server.URL+"/test-2")
+	if !strings.Contains(string(body), `<link rel="alternate" type="application/json+oembed" href="`+discovery+`"`) {
+		t.Fatalf("Expected oEmbed discovery link, got:\n\t%s", string(body))
+	}
+
+	res, _ = http.Get(discovery)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	embed := map[string]interface{}{}
+	gojson.Unmarshal(body, &embed)
+	if res.StatusCode != 200 || embed["type"] != "rich" || embed["title"] != "Another Test" || !strings.Contains(fmt.Sprint(embed["html"]), "Test again!") {
+		t.Errorf("Expected entry to be embedded, got:\n\t%s", string(body))
+	}
+
+	res, _ = http.Get(server.URL + "/oembed?url=" + url.QueryEscape(server.URL+"/missing"))
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected missing entry not to be found, got %d", res.StatusCode)
+	}
+}
+
+func TestEntryFiles(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	dir, _ := ioutil.TempDir("", "entries")
+	defer os.RemoveAll(dir)
+	files, err := flatfile.Open(container, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	container.Store = files
+	defer func() {
+		files.Close()
+		container.Store = nil
+	}()
+	status := func(path string) int {
+		res, err := http.Get(server.URL + path)
+		if err != nil {
+			return 0
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	eventually := func(check func() bool) bool {
+		for i := 0; i < 50; i++ {
+			if check() {
+				return true
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+
+	// Entries already in the database are written out
+	source, err := ioutil.ReadFile(dir + "/2018/2018-02-01-test-2.md")
+	if err != nil || !strings.Contains(string(source), "title: Another Test") || !strings.Contains(string(source), "<p>Test again!</p>") {
+		t.Fatalf("Expected existing entry to be written out, got:\n\t%s", string(source))
+	}
+
+	// So are new ones
+	request, _ := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"Test 4","date":"2018-06-01","content":"<p>Test 4!</p>","tags":["Road Trip"]}`))
+	res, _ := admin.Do(request)
+	res.Body.Close()
+	source, err = ioutil.ReadFile(dir + "/2018/2018-06-01-test-4.md")
+	if res.StatusCode != 201 || err != nil || !strings.Contains(string(source), "Road Trip") {
+		t.Errorf("Expected new entry to be written out with its tags, got %d and %v", res.StatusCode, err)
+	}
+
+	// Changing, adding and removing files changes the entries
+	if err := files.Watch(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	ioutil.WriteFile(dir+"/2018/2018-01-01-test.md", []byte("---\ntitle: Changed on Disk\ndate: 2018-01-01\nslug: test\n---\nEdited elsewhere."), 0644)
+	if !eventually(func() bool {
+		res, _ := http.Get(server.URL + "/test")
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return strings.Contains(string(body), "Changed on Disk")
+	}) {
+		t.Error("Expected entry to change along with its file")
+	}
+	os.MkdirAll(dir+"/2019", 0755)
+	ioutil.WriteFile(dir+"/2019/2019-05-04-trip-to-rome.md", []byte("---\ntitle: Trip to Rome\ntags: [Italy, Road Trip]\n---\nWe went to *Rome*."), 0644)
+	if !eventually(func() bool { return status("/trip-to-rome") == 200 }) {
+		t.Error("Expected new file to add an entry")
+	}
+	if status("/tag/italy") != 200 {
+		t.Error("Expected new file to tag its entry")
+	}
+	os.Remove(dir + "/2018/2018-03-01-test-3.md")
+	if !eventually(func() bool { return status("/test-3") == 404 }) {
+		t.Error("Expected removed file to move its entry to the trash")
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "migrations")
+	defer os.RemoveAll(dir)
+	db := &database.Sqlite{}
+	if err := db.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer db.Close()
+	container := &app.Container{Db: db}
+	indexed := func() bool {
+		rows, _ := db.Query("SELECT `name` FROM `sqlite_master` WHERE `type` = 'index' AND `name` = 'journal_slug'")
+		defer rows.Close()
+		return rows.Next()
+	}
+
+	applied, err := model.Migrate(container)
+	if err != nil || len(applied) < 2 || !indexed() {
+		t.Fatalf("Expected every migration to be applied, got %d and %v", len(applied), err)
+	}
+	if applied, err = model.Migrate(container); err != nil || len(applied) != 0 {
+		t.Errorf("Expected migrations to be applied only once, got %d and %v", len(applied), err)
+	}
+
+	// Roll back until reaching the migration creating the tables
+	for {
+		migration, err := model.Rollback(container)
+		if err == model.ErrIrreversible {
+			if migration.Version != 1 {
+				t.Errorf("Expected only the first migration to be irreversible, got %d", migration.Version)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if indexed() {
+		t.Error("Expected index to have been dropped")
+	}
+	versions, _ := model.AppliedMigrations(container)
+	if len(versions) != 1 {
+		t.Errorf("Expected only the first migration to be left, got %v", versions)
+	}
+	if applied, _ = model.Migrate(container); len(applied) == 0 || !indexed() {
+		t.Error("Expected rolled back migrations to be applied again")
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "encryption")
+	defer os.RemoveAll(dir)
+	db := &database.Sqlite{}
+	if err := db.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer db.Close()
+	container := &app.Container{Db: db}
+	model.Migrate(container)
+	db.Exec("INSERT INTO journal (slug, title, content, excerpt, date) VALUES (?, ?, ?, ?, ?)", "before", "Before", "<p>Written before.</p>", "Written summary", "2018-01-01")
+	stored := func(slug string) string {
+		rows, _ := db.Query("SELECT `content`, `excerpt` FROM `journal` WHERE `slug` = ?", slug)
+		defer rows.Close()
+		content, excerpt := "", ""
+		if rows.Next() {
+			rows.Scan(&content, &excerpt)
+		}
+		return content + " " + excerpt
+	}
+
+	// Giving a passphrase seals what was written before and everything written after
+	sealer, err := model.Unlock(container, "correct horse")
+	if err != nil || sealer == nil {
+		t.Fatalf("Expected encryption to be turned on, got %v", err)
+	}
+	container.Sealer = sealer
+	if sealed, err := model.SealExisting(container); sealed != 2 || err != nil {
+		t.Errorf("Expected existing content and excerpt to be sealed, got %d and %v", sealed, err)
+	}
+	js := model.Journals{Container: container, Gs: model.GiphyAdapter(container)}
+	js.CreateJournal(model.Journal{Title: "Secret", Date: "2018-02-01", Content: "<p>Dear diary.</p>", Excerpt: "Diary summary"})
+	for _, slug := range []string{"before", "secret"} {
+		if content := stored(slug); strings.Count(content, "sealed:") != 2 || strings.Contains(content, "iary") || strings.Contains(content, "Written") {
+			t.Errorf("Expected %s to be stored sealed, got %s", slug, content)
+		}
+	}
+	if j := js.FindBySlug("secret"); j.Content != "<p>Dear diary.</p>" || j.Excerpt != "Diary summary" {
+		t.Errorf("Expected entry to be read back, got %s and %s", j.Content, j.Excerpt)
+	}
+
+	// Searches only match titles, and links within the content are not looked for
+	s := model.JournalSearch{Container: container}
+	if _, pagination := s.Search("diary", database.PaginationQuery{Page: 1, ResultsPerPage: 10}); pagination.TotalResults != 0 {
+		t.Errorf("Expected sealed content not to be searched, got %d results", pagination.TotalResults)
+	}
+	if _, pagination := s.Search("secret", database.PaginationQuery{Page: 1, ResultsPerPage: 10}); pagination.TotalResults != 1 {
+		t.Errorf("Expected titles to be searched, got %d results", pagination.TotalResults)
+	}
+
+	// Only the same passphrase unlocks the database again
+	if _, err := model.Unlock(container, "wrong horse"); err != model.ErrWrongPassphrase {
+		t.Errorf("Expected the wrong passphrase to be refused, got %v", err)
+	}
+	if _, err := model.Unlock(container, ""); err != model.ErrLocked {
+		t.Errorf("Expected the database to stay locked, got %v", err)
+	}
+	if sealer, err = model.Unlock(container, "correct horse"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	container.Sealer = sealer
+	if j := js.FindBySlug("before"); j.Content != "<p>Written before.</p>" {
+		t.Errorf("Expected entry to be unlocked again, got %s", j.Content)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	dir, _ := ioutil.TempDir("", "backup")
+	defer os.RemoveAll(dir)
+
+	path, err := backup.Backup(container, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// The backup can be restored over another database, which is kept alongside
+	dbFile := dir + "/restored.db"
+	ioutil.WriteFile(dbFile, []byte("SQLite format 3\x00"), 0644)
+	if err := backup.Restore(path, dbFile); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	db := &database.Sqlite{}
+	if err := db.Connect(dbFile); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer db.Close()
+	js := model.Journals{Container: &app.Container{Db: db}}
+	if j := js.FindBySlug("test-2"); j.Title != "Another Test" {
+		t.Errorf("Expected entries to have been restored, got %v", j)
+	}
+	if _, err := os.Stat(dbFile + ".before-restore"); err != nil {
+		t.Error("Expected replaced database to have been kept")
+	}
+}
+
+// webhookWithSecret A webhook as returned when it is created
+type webhookWithSecret struct {
+	ID     int    `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func TestTokens(t *testing.T) {
+	fixtures(t)
+
+	us := model.Users{Container: rtr.Container.(*app.Container)}
+	user := model.User{Username: "friend", Email: "friend@example.com", Role: model.RoleEditor}
+	user.SetPassword("password123")
+	us.Save(user)
+	friend := signIn("friend", "password123")
+	issue := func(name string, scopes []string) string {
+		res, _ := friend.PostForm(server.URL+"/settings/tokens", map[string][]string{"name": {name}, "scope": scopes})
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		page := string(body[:])
+		start := strings.Index(page, "<code>jt_")
+		if start == -1 {
+			t.Fatalf("Expected token to be shown once issued, got:\n\t%s", page)
+		}
+		page = page[start+len("<code>"):]
+
+		return page[:strings.Index(page, "</code>")]
+	}
+	create := func(token string) int {
+		request, _ := http.NewRequest("PUT", server.URL+"/api/v1/post", strings.NewReader(`{"title":"By Token","date":"2018-06-01","content":"Written by a script"}`))
+		if token != "" {
+			request.Header.Add("Authorization", "Bearer "+token)
+		}
+		res, _ := http.DefaultClient.Do(request)
+		res.Body.Close()
+
+		return res.StatusCode
+	}
+	read := issue("Reader", []string{"read"})
+	write := issue("Writer", []string{"read", "write"})
+	issue("Unused", []string{"read"})
+
+	// Reading needs no token, writing needs one with the write scope
+	res, _ := http.Get(server.URL + "/api/v1/post")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("Expected posts to be read without a token, got %d", res.StatusCode)
+	}
+	if status := create(""); status != 401 {
+		t.Errorf("Expected 401 without a token, got %d", status)
+	}
+	if status := create(read); status != 403 {
+		t.Errorf("Expected 403 with a read token, got %d", status)
+	}
+	if status := create(write); status != 201 {
+		t.Errorf("Expected 201 with a write token, got %d", status)
+	}
+	res, _ = http.Get(server.URL + "/author/friend")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), "By Token") {
+		t.Error("Expected entry written with a token to be credited to its user")
+	}
+
+	// Tokens show when they were last used, and can be revoked
+	res, _ = friend.Get(server.URL + "/settings/tokens")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Count(string(body[:]), "Never") != 1 || strings.Contains(string(body[:]), write) {
+		t.Errorf("Expected only the unused token to have never been used, and no token shown again, got:\n\t%s", string(body[:]))
+	}
+	ts := model.Tokens{Container: rtr.Container.(*app.Container)}
+	for _, token := range ts.FetchByUser(us.FindByUsername("friend").ID) {
+		if token.Name == "Writer" {
+			res, _ = friend.PostForm(server.URL+"/settings/tokens", map[string][]string{"action": {"revoke"}, "id": {strconv.Itoa(token.ID)}})
+			res.Body.Close()
+		}
+	}
+	if status := create(write); status != 401 {
+		t.Errorf("Expected 401 with a revoked token, got %d", status)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	fixtures(t)
+
+	// Pages give the token within their forms, and in the action of those uploading files
+	token := ""
+	site, _ := url.Parse(server.URL)
+	for _, cookie := range admin.Jar.Cookies(site) {
+		if cookie.Name == auth.CSRFCookie {
+			token = cookie.Value
+		}
+	}
+	res, _ := admin.Get(server.URL + "/new")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if token == "" || !strings.Contains(string(body[:]), `name="csrf_token" value="`+token+`"`) {
+		t.Errorf("Expected new form to carry the token, got:\n\t%s", string(body[:]))
+	}
+	res, _ = admin.Get(server.URL + "/media")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body[:]), `action="/upload?csrf_token=`+token+`"`) {
+		t.Errorf("Expected upload form to carry the token, got:\n\t%s", string(body[:]))
+	}
+
+	// Forms posted by another site as the signed in user are refused
+	forged := &http.Client{Jar: admin.Jar}
+	res, _ = forged.PostForm(server.URL+"/new", map[string][]string{"title": {"Forged"}, "date": {"2018-04-01"}, "content": {"Forged"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 403 || !strings.Contains(string(body[:]), "Not Allowed") {
+		t.Errorf("Expected forged form to be refused, got %d", res.StatusCode)
+	}
+	res, _ = forged.PostForm(server.URL+"/test/delete", map[string][]string{"csrf_token": {"wrong"}})
+	res.Body.Close()
+	res, _ = forged.Post(server.URL+"/api/journals", "text/plain", strings.NewReader(`{"title":"Forged","date":"2018-04-01","content":"Forged"}`))
+	res.Body.Close()
+	if res.StatusCode != 403 {
+		t.Errorf("Expected forged post to the API to be refused, got %d", res.StatusCode)
+	}
+	res, _ = http.Get(server.URL + "/test")
+	res.Body.Close()
+	js := model.Journals{Container: rtr.Container.(*app.Container)}
+	if res.StatusCode != 200 || js.FindBySlug("forged").ID > 0 {
+		t.Error("Expected nothing to have been changed by forged posts")
+	}
+
+	// Forms posted from the journal's own pages are served
+	res, _ = forged.PostForm(server.URL+"/new", map[string][]string{"csrf_token": {token}, "title": {"Real"}, "date": {"2018-04-01"}, "content": {"Real"}})
+	res.Body.Close()
+	if js.FindBySlug("real").ID == 0 {
+		t.Error("Expected form carrying the token to be served")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	fixtures(t)
+
+	limited := httptest.NewServer(ratelimit.NewLimiter(app.Configuration{RateLimit: 60, RateLimitLogin: 2}).Middleware(rtr))
+	defer limited.Close()
+	login := func() int {
+		res, _ := http.PostForm(limited.URL+"/login", map[string][]string{"username": {"admin"}, "password": {"wrong"}})
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// Sign in attempts beyond the limit are refused before the password is checked
+	login()
+	login()
+	if status := login(); status != 429 {
+		t.Errorf("Expected third sign in attempt to be refused, got %d", status)
+	}
+	res, _ := http.Get(limited.URL + "/login")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("Expected pages to still be shown, got %d", res.StatusCode)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	fixtures(t)
+
+	for path, expected := range map[string]string{
+		"/healthz": `{"status":"ok"}`,
+		"/readyz":  `{"status":"ok","checks":{"database":"ok","templates":"ok"}}`,
+	} {
+		res, _ := http.Get(server.URL + path)
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != 200 || res.Header.Get("Content-Type") != "application/json; charset=utf-8" || strings.TrimSpace(string(body)) != expected {
+			t.Errorf("Expected %s to answer %s, got %d and %s", path, expected, res.StatusCode, body)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Db.Exec("DELETE FROM user")
+	container.Settings = app.NewSettings(nil)
+	container.SetupCode = "abc123"
+
+	// Pages needing an admin are sent to setting the journal up while it has none
+	client := browser()
+	res, _ := client.Get(server.URL + "/new")
+	res.Body.Close()
+	if res.Request.URL.Path != "/setup" {
+		t.Errorf("Expected a journal without an admin to be set up first, got %s", res.Request.URL.Path)
+	}
+
+	res, _ = client.PostForm(server.URL+"/setup", map[string][]string{"code": {"wrong"}, "title": {"Alice's Journal"}, "username": {"alice"}, "password": {"password123"}, "confirm": {"password123"}})
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "setup code does not match") {
+		t.Error("Expected a wrong setup code to be refused")
+	}
+
+	res, _ = client.PostForm(server.URL+"/setup", map[string][]string{"code": {"abc123"}, "title": {"Alice's Journal"}, "username": {"alice"}, "email": {"alice@example.com"}, "password": {"password123"}, "confirm": {"password123"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Request.URL.Path != "/" || !strings.Contains(string(body), "Alice&#39;s Journal") {
+		t.Errorf("Expected the journal to be set up with its title, got %s", res.Request.URL.Path)
+	}
+	res, _ = client.Get(server.URL + "/new")
+	res.Body.Close()
+	if res.Request.URL.Path != "/new" || res.StatusCode != 200 {
+		t.Error("Expected the new admin to have been signed in")
+	}
+	res, _ = http.Get(server.URL + "/setup")
+	res.Body.Close()
+	if res.StatusCode != 404 {
+		t.Errorf("Expected setting up to be refused once done, got %d", res.StatusCode)
+	}
+}
+
+func TestAdminSettings(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Settings = app.NewSettings(nil)
+
+	res, _ := admin.PostForm(server.URL+"/admin/settings", map[string][]string{"title": {"Alice's Journal"}, "description": {"Notes from Alice"}, "articles_per_page": {"2"}})
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Settings saved.") {
+		t.Errorf("Expected settings to be saved, got %s", res.Request.URL)
+	}
+
+	// Settings are used by the next request, without restarting
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "<title>Alice&#39;s Journal</title>") || !strings.Contains(string(body), `<meta name="description" content="Notes from Alice" />`) ||
+		!strings.Contains(string(body), `href="/?page=2"`) {
+		t.Error("Expected saved settings to be used at once")
+	}
+
+	res, _ = admin.PostForm(server.URL+"/admin/settings", map[string][]string{"articles_per_page": {"0"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `class="flash flash-error"`) || container.Settings.Get(app.SettingTitle) != "Alice's Journal" {
+		t.Error("Expected invalid settings to be refused, keeping those saved")
+	}
+
+	// Emptied settings go back to those configured
+	res, _ = admin.PostForm(server.URL+"/admin/settings", map[string][]string{})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "<title>Jamie&#39;s Journal</title>") {
+		t.Error("Expected emptied settings to be removed")
+	}
+
+	res, _ = http.Get(server.URL + "/admin/settings")
+	res.Body.Close()
+	if res.Request.URL.Path != "/login" {
+		t.Error("Expected settings to need an admin")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Maintenance = app.NewMaintenance(false)
+	defer func() { container.Maintenance = nil }()
+
+	res, _ := admin.PostForm(server.URL+"/admin/maintenance", map[string][]string{"on": {"1"}})
+	res.Body.Close()
+	if !container.Maintenance.On() {
+		t.Fatal("Expected maintenance to be switched on")
+	}
+
+	// Pages are read with a notice while changes are refused
+	res, _ = http.Get(server.URL + "/test")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != 200 || !strings.Contains(string(body), `<div class="maintenance">`) {
+		t.Error("Expected pages to be read with a maintenance notice")
+	}
+	res, _ = admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Refused"}, "date": {"2018-06-01"}, "content": {"<p>Refused</p>"}})
+	res.Body.Close()
+	res2, _ := http.Get(server.URL + "/refused")
+	res2.Body.Close()
+	if res.StatusCode != 503 || res.Header.Get("Retry-After") == "" || res2.StatusCode != 404 {
+		t.Errorf("Expected changes to be refused in maintenance, got %d", res.StatusCode)
+	}
+
+	// Admins can still sign in to switch it off
+	client := signIn("admin", "password123")
+	res, _ = client.PostForm(server.URL+"/admin/maintenance", map[string][]string{"on": {"0"}})
+	res.Body.Close()
+	if container.Maintenance.On() {
+		t.Error("Expected maintenance to be switched off by an admin signing in")
+	}
+	res, _ = admin.PostForm(server.URL+"/new", map[string][]string{"title": {"Accepted"}, "date": {"2018-06-01"}, "content": {"<p>Accepted</p>"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/accepted")
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Error("Expected changes to be accepted again")
+	}
+}
+
+func TestSiteData(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	ts := model.ScheduledTasks{Container: container}
+	ts.Plan("backup", time.Hour, time.Now())
+
+	// Pages show who is signed in along with the links in the header
+	res, _ := admin.Get(server.URL + "/")
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Signed in as admin") || !strings.Contains(string(body), `<a class="button" href="/new">Create New Post</a>`) {
+		t.Error("Expected the signed in user and header links to be shown")
+	}
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body), "Signed in as") {
+		t.Error("Expected nobody to be shown as signed in")
+	}
+
+	// Messages left once a form is posted are shown on the next page only
+	res, _ = admin.PostForm(server.URL+"/admin/jobs", map[string][]string{"run": {"backup"}})
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `<div class="flash flash-info" role="status">The backup task will be run now.</div>`) {
+		t.Error("Expected message to be shown once redirected")
+	}
+	res, _ = admin.Get(server.URL + "/admin/jobs")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if strings.Contains(string(body), `class="flash`) {
+		t.Error("Expected message to be shown only once")
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	fixtures(t)
+	container := rtr.Container.(*app.Container)
+	container.Settings = app.NewSettings(nil)
+
+	// Pages are written in the language the browser prefers, of those the journal can be read in
+	request, _ := http.NewRequest("GET", server.URL+"/", nil)
+	request.Header.Set("Accept-Language", "fr-FR,fr;q=0.9,en;q=0.8")
+	res, _ := http.DefaultClient.Do(request)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `<html lang="fr">`) || !strings.Contains(string(body), "Publié le lundi 1 janvier 2018") || !strings.Contains(string(body), "Lire la suite") {
+		t.Errorf("Expected page in French, got:\n\t%s", string(body))
+	}
+	request.Header.Set("Accept-Language", "de")
+	res, _ = http.DefaultClient.Do(request)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `<html lang="en">`) || !strings.Contains(string(body), "Posted on Monday January 1, 2018") {
+		t.Error("Expected page in English when no language preferred can be read")
+	}
+
+	// Messages left are written in the same language
+	request, _ = http.NewRequest("POST", server.URL+"/admin/settings", strings.NewReader("title=Journal"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept-Language", "fr")
+	res, _ = admin.Do(request)
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "Paramètres enregistrés.") {
+		t.Error("Expected message in French")
+	}
+
+	// A language chosen in the settings is used for every reader
+	res, _ = admin.PostForm(server.URL+"/admin/settings", map[string][]string{"language": {"fr"}})
+	res.Body.Close()
+	res, _ = http.Get(server.URL + "/")
+	body, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), `<html lang="fr">`) {
+		t.Error("Expected the language saved to be used")
+	}
+	res, _ = admin.PostForm(server.URL+"/admin/settings", map[string][]string{"language": {"de"}})
+	res.Body.Close()
+	if container.Settings.Get(app.SettingLanguage) != "fr" {
+		t.Error("Expected a language that cannot be read to be refused")
+	}
+}
@@ -0,0 +1,246 @@
+package activitypub
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// ContentType Media type of ActivityStreams documents exchanged between servers
+const ContentType = "application/activity+json"
+
+// Context JSON-LD contexts every document is written in
+var Context = []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"}
+
+// Public Address of the public collection, used to make an object visible to everyone
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// maxDocumentSize Largest document read back from a remote server
+const maxDocumentSize = 1 << 20
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// PublicKey Key that signs the requests sent on behalf of an actor
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Endpoints Further addresses an actor can be reached at
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// Actor Someone, or something such as a journal, that can be followed
+type Actor struct {
+	Context           interface{} `json:"@context,omitempty"`
+	ID                string      `json:"id"`
+	Type              string      `json:"type"`
+	PreferredUsername string      `json:"preferredUsername,omitempty"`
+	Name              string      `json:"name,omitempty"`
+	Summary           string      `json:"summary,omitempty"`
+	URL               string      `json:"url,omitempty"`
+	Inbox             string      `json:"inbox"`
+	Outbox            string      `json:"outbox,omitempty"`
+	Followers         string      `json:"followers,omitempty"`
+	Endpoints         *Endpoints  `json:"endpoints,omitempty"`
+	PublicKey         PublicKey   `json:"publicKey"`
+}
+
+// DeliveryInbox Get the inbox to deliver to, preferring one shared by everyone on the actor's server
+func (a Actor) DeliveryInbox() string {
+	if a.Endpoints != nil && a.Endpoints.SharedInbox != "" {
+		return a.Endpoints.SharedInbox
+	}
+
+	return a.Inbox
+}
+
+// Object Something published by an actor, such as an article
+type Object struct {
+	Context      interface{} `json:"@context,omitempty"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	AttributedTo string      `json:"attributedTo,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	Summary      string      `json:"summary,omitempty"`
+	Content      string      `json:"content,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	Published    string      `json:"published,omitempty"`
+	Updated      string      `json:"updated,omitempty"`
+	To           []string    `json:"to,omitempty"`
+	Cc           []string    `json:"cc,omitempty"`
+}
+
+// Activity Something an actor has done, whose object is either a link or an embedded document
+type Activity struct {
+	Context interface{} `json:"@context,omitempty"`
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Actor   string      `json:"actor"`
+	Object  interface{} `json:"object"`
+	To      []string    `json:"to,omitempty"`
+	Cc      []string    `json:"cc,omitempty"`
+}
+
+// ObjectID Get the ID of the activity's object, whether linked or embedded
+func (a Activity) ObjectID() string {
+	switch object := a.Object.(type) {
+	case string:
+		return object
+	case Object:
+		return object.ID
+	case map[string]interface{}:
+		id, _ := object["id"].(string)
+		return id
+	}
+
+	return ""
+}
+
+// ObjectType Get the type of an embedded object, or an empty string when the object is only linked
+func (a Activity) ObjectType() string {
+	switch object := a.Object.(type) {
+	case Object:
+		return object.Type
+	case map[string]interface{}:
+		objectType, _ := object["type"].(string)
+		return objectType
+	}
+
+	return ""
+}
+
+// OrderedCollection A list of items, newest first, such as an outbox
+type OrderedCollection struct {
+	Context      interface{}   `json:"@context,omitempty"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	TotalItems   int           `json:"totalItems"`
+	OrderedItems []interface{} `json:"orderedItems,omitempty"`
+}
+
+// Link A link within a WebFinger response
+type Link struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// WebFinger Response describing where the actor behind an account such as acct:journal@example.com is found
+type WebFinger struct {
+	Subject string   `json:"subject"`
+	Aliases []string `json:"aliases,omitempty"`
+	Links   []Link   `json:"links"`
+}
+
+// EncodePublicKey Write the public half of a key in the PEM form published with an actor
+func EncodePublicKey(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePublicKey Read the PEM form of an actor's public key
+func ParsePublicKey(encoded string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("No public key was found")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("Only RSA public keys are supported")
+	}
+
+	return rsaKey, nil
+}
+
+// Client Sends signed requests to other servers on behalf of an actor
+type Client struct {
+	Client Adapter
+	KeyID  string
+	Key    *rsa.PrivateKey
+}
+
+// Deliver Post an activity to an inbox
+func (c Client) Deliver(inbox string, activity interface{}) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", ContentType)
+	_, err = c.send(request, body)
+
+	return err
+}
+
+// FetchActor Get the actor at an address, which may be the ID of one of its keys
+func (c Client) FetchActor(address string) (Actor, error) {
+	actor := Actor{}
+	request, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return actor, err
+	}
+	request.Header.Set("Accept", ContentType)
+	body, err := c.send(request, nil)
+	if err != nil {
+		return actor, err
+	}
+	if err := json.Unmarshal(body, &actor); err != nil {
+		return actor, err
+	}
+	if actor.ID == "" || actor.Inbox == "" {
+		return actor, errors.New("No actor was found at " + address)
+	}
+
+	return actor, nil
+}
+
+func (c Client) send(request *http.Request, body []byte) ([]byte, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request.Header.Set("User-Agent", "Journal")
+	if c.Key != nil {
+		if err := Sign(request, body, c.KeyID, c.Key); err != nil {
+			return nil, err
+		}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, errors.New("Unexpected response status " + strconv.Itoa(response.StatusCode) + " from " + request.URL.Host)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(response.Body, maxDocumentSize))
+}
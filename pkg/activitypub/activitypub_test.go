@@ -0,0 +1,110 @@
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestActivity_Object(t *testing.T) {
+	linked := Activity{Object: "https://example.com/actor"}
+	if linked.ObjectID() != "https://example.com/actor" || linked.ObjectType() != "" {
+		t.Error("Expected linked object to give only its ID")
+	}
+
+	embedded := Activity{}
+	json.Unmarshal([]byte(`{"type":"Undo","object":{"id":"https://example.com/follow/1","type":"Follow"}}`), &embedded)
+	if embedded.ObjectID() != "https://example.com/follow/1" || embedded.ObjectType() != "Follow" {
+		t.Errorf("Expected embedded object to give its ID and type, got %v", embedded.Object)
+	}
+	article := Activity{Object: Object{ID: "https://example.com/entries/1", Type: "Article"}}
+	if article.ObjectID() != "https://example.com/entries/1" || article.ObjectType() != "Article" {
+		t.Error("Expected object being sent to give its ID and type")
+	}
+	if (Activity{}).ObjectID() != "" {
+		t.Error("Expected missing object to have no ID")
+	}
+}
+
+func TestActor_DeliveryInbox(t *testing.T) {
+	actor := Actor{Inbox: "https://example.com/inbox"}
+	if actor.DeliveryInbox() != "https://example.com/inbox" {
+		t.Error("Expected own inbox without a shared one")
+	}
+	actor.Endpoints = &Endpoints{SharedInbox: "https://example.com/shared"}
+	if actor.DeliveryInbox() != "https://example.com/shared" {
+		t.Error("Expected shared inbox to be preferred")
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	encoded, err := EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	decoded, err := ParsePublicKey(encoded)
+	if err != nil || decoded.N.Cmp(key.PublicKey.N) != 0 {
+		t.Errorf("Expected key to be read back, got %s", err)
+	}
+	if _, err := ParsePublicKey("not a key"); err == nil {
+		t.Error("Expected error for an invalid key")
+	}
+}
+
+func TestClient(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	received := []byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature, err := ParseSignature(r)
+		if err != nil || signature.KeyID != "https://example.com/actor#main-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			if signature.Verify(r, nil, &key.PublicKey) != nil || r.Header.Get("Accept") != ContentType {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path == "/missing" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.Write([]byte(`{"id":"https://remote.example.com/actor","type":"Person","inbox":"https://remote.example.com/inbox"}`))
+		case "POST":
+			received, _ = ioutil.ReadAll(r.Body)
+			if signature.Verify(r, received, &key.PublicKey) != nil || r.Header.Get("Content-Type") != ContentType {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+	client := Client{KeyID: "https://example.com/actor#main-key", Key: key}
+
+	actor, err := client.FetchActor(server.URL + "/actor#main-key")
+	if err != nil || actor.ID != "https://remote.example.com/actor" || actor.Inbox != "https://remote.example.com/inbox" {
+		t.Errorf("Expected actor to be fetched with a signed request, got %v, %v", actor, err)
+	}
+	if _, err := client.FetchActor(server.URL + "/missing"); err == nil {
+		t.Error("Expected error when no actor is found")
+	}
+
+	if err := client.Deliver(server.URL+"/inbox", Activity{ID: "https://example.com/1", Type: "Create", Actor: "https://example.com/actor"}); err != nil {
+		t.Errorf("Expected activity to be delivered, got %s", err)
+	}
+	if string(received) != `{"id":"https://example.com/1","type":"Create","actor":"https://example.com/actor","object":null}` {
+		t.Errorf("Expected activity to be posted, got %s", received)
+	}
+
+	// Test unsigned requests are refused by the server
+	if err := (Client{}).Deliver(server.URL+"/inbox", Activity{}); err == nil {
+		t.Error("Expected error for a refused delivery")
+	}
+}
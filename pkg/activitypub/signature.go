@@ -0,0 +1,162 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew How far the date of a signed request may be from now before it is refused, limiting replays
+const MaxClockSkew = 12 * time.Hour
+
+// signedHeaders Headers covered by the signature on requests with and without a body
+const (
+	signedHeaders       = "(request-target) host date digest"
+	signedHeadersNoBody = "(request-target) host date"
+)
+
+// Signature Parameters of a request's Signature header
+type Signature struct {
+	KeyID     string
+	Algorithm string
+	Headers   []string
+	Signature []byte
+}
+
+// Digest Get the Digest header value for a request body
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Sign Add Date, Digest and Signature headers to a request, following the draft HTTP Signatures scheme other servers expect
+func Sign(request *http.Request, body []byte, keyID string, key *rsa.PrivateKey) error {
+	headers := signedHeadersNoBody
+	if request.Header.Get("Date") == "" {
+		request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	if body != nil {
+		request.Header.Set("Digest", Digest(body))
+		headers = signedHeaders
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(request, strings.Split(headers, " "))))
+	signed, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Signature", `keyId="`+keyID+`",algorithm="rsa-sha256",headers="`+headers+`",signature="`+base64.StdEncoding.EncodeToString(signed)+`"`)
+
+	return nil
+}
+
+// ParseSignature Read the Signature header of a request
+func ParseSignature(request *http.Request) (Signature, error) {
+	s := Signature{Headers: []string{"date"}}
+	header := request.Header.Get("Signature")
+	if header == "" {
+		return s, errors.New("The request is not signed")
+	}
+	for _, part := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		value := strings.Trim(pair[1], `"`)
+		switch pair[0] {
+		case "keyId":
+			s.KeyID = value
+		case "algorithm":
+			s.Algorithm = value
+		case "headers":
+			s.Headers = strings.Fields(strings.ToLower(value))
+		case "signature":
+			signature, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return s, errors.New("The signature could not be decoded")
+			}
+			s.Signature = signature
+		}
+	}
+	if s.KeyID == "" || len(s.Signature) == 0 {
+		return s, errors.New("The signature is incomplete")
+	}
+
+	return s, nil
+}
+
+// Verify Check a signature against the public key of the actor who made the request, along with the date and, when
+// one was sent, the body
+func (s Signature) Verify(request *http.Request, body []byte, key *rsa.PublicKey) error {
+	if s.Algorithm != "" && s.Algorithm != "rsa-sha256" && s.Algorithm != "hs2019" {
+		return errors.New("Unsupported signature algorithm " + s.Algorithm)
+	}
+	covered := map[string]bool{}
+	for _, h := range s.Headers {
+		covered[h] = true
+	}
+	if !covered["(request-target)"] || !covered["date"] {
+		return errors.New("The signature does not cover the request target and date")
+	}
+	date, err := http.ParseTime(request.Header.Get("Date"))
+	if err != nil {
+		return errors.New("The request has no valid date")
+	}
+	if skew := time.Since(date); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return errors.New("The request date is too far from now")
+	}
+	if body != nil {
+		if !covered["digest"] {
+			return errors.New("The signature does not cover the digest")
+		}
+		if !digestMatches(request.Header.Get("Digest"), body) {
+			return errors.New("The digest does not match the body")
+		}
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(request, s.Headers)))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], s.Signature); err != nil {
+		return errors.New("The signature does not match")
+	}
+
+	return nil
+}
+
+// digestMatches Whether one of the digests listed in a header is the SHA-256 digest of the body
+func digestMatches(header string, body []byte) bool {
+	expected := strings.SplitN(Digest(body), "=", 2)
+	for _, digest := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+		if len(pair) == 2 && strings.EqualFold(pair[0], expected[0]) && pair[1] == expected[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func signingString(request *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		switch h {
+		case "(request-target)":
+			lines[i] = h + ": " + strings.ToLower(request.Method) + " " + request.URL.RequestURI()
+		case "host":
+			host := request.Host
+			if host == "" {
+				host = request.URL.Host
+			}
+			lines[i] = h + ": " + host
+		default:
+			lines[i] = h + ": " + strings.Join(request.Header.Values(h), ", ")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,99 @@
+package activitypub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedRequest(t *testing.T, key *rsa.PrivateKey, body []byte) *http.Request {
+	request, _ := http.NewRequest("POST", "https://example.com/inbox?page=1", strings.NewReader(string(body)))
+	if err := Sign(request, body, "https://remote.example.com/actor#main-key", key); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return request
+}
+
+func TestSign(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	body := []byte(`{"type":"Follow"}`)
+	request := signedRequest(t, key, body)
+
+	if request.Header.Get("Digest") != Digest(body) || request.Header.Get("Date") == "" {
+		t.Error("Expected digest and date headers to be set")
+	}
+	signature, err := ParseSignature(request)
+	if err != nil || signature.KeyID != "https://remote.example.com/actor#main-key" || signature.Algorithm != "rsa-sha256" || strings.Join(signature.Headers, " ") != "(request-target) host date digest" {
+		t.Fatalf("Expected signature to be read, got %v, %v", signature, err)
+	}
+	if err := signature.Verify(request, body, &key.PublicKey); err != nil {
+		t.Errorf("Expected signature to be verified, got %s", err)
+	}
+
+	// Test requests without a body are signed without a digest
+	get, _ := http.NewRequest("GET", "https://example.com/actor", nil)
+	Sign(get, nil, "https://remote.example.com/actor#main-key", key)
+	signature, _ = ParseSignature(get)
+	if get.Header.Get("Digest") != "" || signature.Verify(get, nil, &key.PublicKey) != nil {
+		t.Error("Expected request without a body to be signed and verified")
+	}
+}
+
+func TestSignature_Verify(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	other, _ := rsa.GenerateKey(rand.Reader, 1024)
+	body := []byte(`{"type":"Follow"}`)
+
+	// Test another key
+	request := signedRequest(t, key, body)
+	signature, _ := ParseSignature(request)
+	if signature.Verify(request, body, &other.PublicKey) == nil {
+		t.Error("Expected signature from another key to be refused")
+	}
+
+	// Test altered body
+	if signature.Verify(request, []byte(`{"type":"Undo"}`), &key.PublicKey) == nil {
+		t.Error("Expected altered body to be refused")
+	}
+
+	// Test digests listed alongside others are matched without regard to case
+	if !digestMatches("md5=abc, sha-256="+strings.TrimPrefix(Digest(body), "SHA-256="), body) || digestMatches("md5=abc", body) {
+		t.Error("Expected only the SHA-256 digest to be matched")
+	}
+
+	// Test old requests are refused
+	request, _ = http.NewRequest("POST", "https://example.com/inbox", strings.NewReader(string(body)))
+	request.Header.Set("Date", time.Now().Add(-2*MaxClockSkew).UTC().Format(http.TimeFormat))
+	Sign(request, body, "https://remote.example.com/actor#main-key", key)
+	signature, _ = ParseSignature(request)
+	if signature.Verify(request, body, &key.PublicKey) == nil {
+		t.Error("Expected old request to be refused")
+	}
+
+	// Test signatures not covering the request target
+	request = signedRequest(t, key, body)
+	request.Header.Set("Signature", strings.Replace(request.Header.Get("Signature"), "(request-target) ", "", 1))
+	signature, _ = ParseSignature(request)
+	if signature.Verify(request, body, &key.PublicKey) == nil {
+		t.Error("Expected signature without the request target to be refused")
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	request, _ := http.NewRequest("POST", "https://example.com/inbox", nil)
+	if _, err := ParseSignature(request); err == nil {
+		t.Error("Expected unsigned request to be refused")
+	}
+	request.Header.Set("Signature", `keyId="https://example.com/key",signature="!!"`)
+	if _, err := ParseSignature(request); err == nil {
+		t.Error("Expected undecodable signature to be refused")
+	}
+	request.Header.Set("Signature", `algorithm="rsa-sha256"`)
+	if _, err := ParseSignature(request); err == nil {
+		t.Error("Expected incomplete signature to be refused")
+	}
+}
@@ -0,0 +1,107 @@
+package akismet
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Comment Details of a submitted comment passed to the API
+type Comment struct {
+	Author      string
+	AuthorEmail string
+	AuthorURL   string
+	Content     string
+	Permalink   string
+	Referrer    string
+	UserAgent   string
+	UserIP      string
+}
+
+// Client Actual client for an Akismet-compatible API
+type Client struct {
+	Client   Adapter
+	Endpoint string
+	Key      string
+	Site     string
+}
+
+// Check Ask the API whether a comment is spam
+func (c Client) Check(comment Comment) (bool, error) {
+	body, err := c.post("comment-check", comment)
+	if err != nil {
+		return false, err
+	}
+	switch body {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	return false, errors.New("Unexpected response from spam API: " + body)
+}
+
+// SubmitHam Report a comment that was wrongly marked as spam
+func (c Client) SubmitHam(comment Comment) error {
+	_, err := c.post("submit-ham", comment)
+	return err
+}
+
+// SubmitSpam Report a comment that was missed as spam
+func (c Client) SubmitSpam(comment Comment) error {
+	_, err := c.post("submit-spam", comment)
+	return err
+}
+
+func (c Client) post(method string, comment Comment) (string, error) {
+	if c.Key == "" {
+		return "", errors.New("No API key was found for the spam API")
+	}
+
+	form := url.Values{}
+	form.Add("api_key", c.Key)
+	form.Add("blog", c.Site)
+	form.Add("comment_type", "comment")
+	form.Add("comment_author", comment.Author)
+	form.Add("comment_author_email", comment.AuthorEmail)
+	form.Add("comment_author_url", comment.AuthorURL)
+	form.Add("comment_content", comment.Content)
+	form.Add("permalink", comment.Permalink)
+	form.Add("referrer", comment.Referrer)
+	form.Add("user_agent", comment.UserAgent)
+	form.Add("user_ip", comment.UserIP)
+
+	request, err := http.NewRequest("POST", strings.TrimSuffix(c.Endpoint, "/")+"/"+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Add("User-Agent", "Journal")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", errors.New("Unexpected response status from spam API: " + response.Status)
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,69 @@
+package akismet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Check(t *testing.T) {
+	var path, key, content string
+	reply := "false"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		key = r.FormValue("api_key")
+		content = r.FormValue("comment_content")
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	// Test no API key
+	client := Client{Endpoint: server.URL + "/1.1"}
+	if _, err := client.Check(Comment{Content: "Hello"}); err == nil {
+		t.Error("Expected API key error was not achieved")
+	}
+
+	// Test not spam
+	client.Key = "abc123"
+	spam, err := client.Check(Comment{Content: "Hello"})
+	if err != nil || spam || path != "/1.1/comment-check" || key != "abc123" || content != "Hello" {
+		t.Error("Expected comment to be checked and found not to be spam")
+	}
+
+	// Test spam
+	reply = "true"
+	spam, err = client.Check(Comment{Content: "Buy now"})
+	if err != nil || !spam {
+		t.Error("Expected comment to be found to be spam")
+	}
+
+	// Test invalid key
+	reply = "invalid"
+	if _, err = client.Check(Comment{Content: "Hello"}); err == nil {
+		t.Error("Expected error for an unexpected response")
+	}
+}
+
+func TestClient_Submit(t *testing.T) {
+	var path string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(status)
+		w.Write([]byte("Thanks for making the web a better place."))
+	}))
+	defer server.Close()
+
+	client := Client{Endpoint: server.URL + "/1.1/", Key: "abc123"}
+	if err := client.SubmitSpam(Comment{}); err != nil || path != "/1.1/submit-spam" {
+		t.Error("Expected spam to be submitted")
+	}
+	if err := client.SubmitHam(Comment{}); err != nil || path != "/1.1/submit-ham" {
+		t.Error("Expected ham to be submitted")
+	}
+
+	status = http.StatusInternalServerError
+	if err := client.SubmitHam(Comment{}); err == nil {
+		t.Error("Expected error when API fails")
+	}
+}
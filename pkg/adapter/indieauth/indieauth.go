@@ -0,0 +1,87 @@
+package indieauth
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Token What a token endpoint says about an access token
+type Token struct {
+	Me       string `json:"me"`
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+}
+
+// HasScope Check whether the token was granted a given scope
+func (t Token) HasScope(scope string) bool {
+	for _, s := range strings.Fields(t.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Client Verifies access tokens with an IndieAuth token endpoint
+type Client struct {
+	Client   Adapter
+	Endpoint string
+}
+
+// Verify Ask the token endpoint who an access token was issued to and for what
+func (c Client) Verify(accessToken string) (Token, error) {
+	token := Token{}
+	if c.Endpoint == "" {
+		return token, errors.New("No token endpoint was found for IndieAuth")
+	}
+	request, err := http.NewRequest("GET", c.Endpoint, nil)
+	if err != nil {
+		return token, err
+	}
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("Authorization", "Bearer "+accessToken)
+	request.Header.Add("User-Agent", "Journal")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return token, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return token, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return token, errors.New("The token was not accepted by the token endpoint: " + response.Status)
+	}
+
+	// Older endpoints answer with a form, whatever was asked for
+	if strings.HasPrefix(response.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return token, err
+		}
+		token = Token{Me: values.Get("me"), ClientID: values.Get("client_id"), Scope: values.Get("scope")}
+	} else if err := json.Unmarshal(body, &token); err != nil {
+		return token, err
+	}
+	if token.Me == "" {
+		return token, errors.New("The token endpoint did not say who the token was issued to")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,61 @@
+package indieauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestToken_HasScope(t *testing.T) {
+	token := Token{Scope: "create update"}
+	if !token.HasScope("create") || !token.HasScope("update") || token.HasScope("delete") {
+		t.Error("Expected only the scopes granted to be found")
+	}
+}
+
+func TestClient_Verify(t *testing.T) {
+	var authorization string
+	status := http.StatusOK
+	contentType := "application/json"
+	reply := `{"me":"https://example.com/","client_id":"https://client.example.com/","scope":"create"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	// Test no endpoint
+	if _, err := (Client{}).Verify("abc123"); err == nil {
+		t.Error("Expected error without a token endpoint")
+	}
+
+	// Test JSON response
+	client := Client{Endpoint: server.URL}
+	token, err := client.Verify("abc123")
+	if err != nil || authorization != "Bearer abc123" || token.Me != "https://example.com/" || token.ClientID != "https://client.example.com/" || !token.HasScope("create") {
+		t.Errorf("Expected token to be verified, got %v, %v", token, err)
+	}
+
+	// Test form response
+	contentType = "application/x-www-form-urlencoded"
+	reply = "me=https%3A%2F%2Fexample.com%2F&scope=create+update"
+	token, err = client.Verify("abc123")
+	if err != nil || token.Me != "https://example.com/" || !token.HasScope("update") {
+		t.Errorf("Expected form response to be read, got %v, %v", token, err)
+	}
+
+	// Test response without me
+	contentType = "application/json"
+	reply = `{"scope":"create"}`
+	if _, err := client.Verify("abc123"); err == nil {
+		t.Error("Expected error when the token endpoint does not give me")
+	}
+
+	// Test token refused
+	status = http.StatusUnauthorized
+	if _, err := client.Verify("abc123"); err == nil {
+		t.Error("Expected error when the token is refused")
+	}
+}
@@ -0,0 +1,222 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Provider Where to send users to sign in, and where to ask who they are once they have
+type Provider struct {
+	Name                  string `json:"-"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	EmailsEndpoint        string `json:"-"`
+	Scopes                string `json:"-"`
+}
+
+// GitHub signs users in with OAuth2 rather than OpenID Connect, so is described here rather than discovered
+var GitHub = Provider{
+	Name:                  "github",
+	AuthorizationEndpoint: "https://github.com/login/oauth/authorize",
+	TokenEndpoint:         "https://github.com/login/oauth/access_token",
+	UserInfoEndpoint:      "https://api.github.com/user",
+	EmailsEndpoint:        "https://api.github.com/user/emails",
+	Scopes:                "read:user user:email",
+}
+
+// GoogleIssuer Issuer whose configuration is discovered when signing in with Google
+const GoogleIssuer = "https://accounts.google.com"
+
+// Identity Who a provider says has signed in
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Discover Find the provider to sign in with, being github, google or the URL of any other OpenID Connect issuer
+func Discover(client Adapter, name string) (Provider, error) {
+	if name == GitHub.Name {
+		return GitHub, nil
+	}
+	issuer := name
+	if name == "google" {
+		issuer = GoogleIssuer
+	}
+	if !strings.HasPrefix(issuer, "https://") && !strings.HasPrefix(issuer, "http://") {
+		return Provider{}, errors.New("The provider must be github, google or the URL of an OpenID Connect issuer")
+	}
+
+	provider := Provider{}
+	if err := getJSON(client, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", "", &provider); err != nil {
+		return provider, err
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.UserInfoEndpoint == "" {
+		return provider, errors.New("The issuer did not give its authorization, token and userinfo endpoints")
+	}
+	provider.Name = name
+	provider.Scopes = "openid email profile"
+
+	return provider, nil
+}
+
+// Client Signs users in with a provider using the authorization code flow
+type Client struct {
+	Client       Adapter
+	ClientID     string
+	ClientSecret string
+	Provider     Provider
+}
+
+// AuthorizationURL Where to send a user to sign in, who is sent back to the redirect URI with a code and the state
+func (c Client) AuthorizationURL(state string, redirectURI string) string {
+	query := url.Values{
+		"client_id":     {c.ClientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {c.Provider.Scopes},
+		"state":         {state},
+	}
+	separator := "?"
+	if strings.Contains(c.Provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return c.Provider.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange Trade the code a user was sent back with for an access token
+func (c Client) Exchange(code string, redirectURI string) (string, error) {
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+	}
+	request, err := http.NewRequest("POST", c.Provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}{}
+	if err := do(c.Client, request, &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("The provider did not give an access token: " + token.Error)
+	}
+
+	return token.AccessToken, nil
+}
+
+// Identify Ask the provider who an access token belongs to
+func (c Client) Identify(accessToken string) (Identity, error) {
+	if c.Provider.EmailsEndpoint != "" {
+		return c.identifyGitHub(accessToken)
+	}
+
+	info := struct {
+		Subject       string      `json:"sub"`
+		Email         string      `json:"email"`
+		EmailVerified interface{} `json:"email_verified"`
+		Name          string      `json:"name"`
+	}{}
+	if err := getJSON(c.Client, c.Provider.UserInfoEndpoint, accessToken, &info); err != nil {
+		return Identity{}, err
+	}
+	if info.Subject == "" {
+		return Identity{}, errors.New("The provider did not say who signed in")
+	}
+
+	// Some providers send whether the email was verified as a string
+	verified := info.EmailVerified == true || info.EmailVerified == "true"
+
+	return Identity{Subject: info.Subject, Email: info.Email, EmailVerified: verified, Name: info.Name}, nil
+}
+
+// identifyGitHub Ask GitHub who signed in, and for their primary email along with whether it was verified
+func (c Client) identifyGitHub(accessToken string) (Identity, error) {
+	user := struct {
+		ID    int    `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}{}
+	if err := getJSON(c.Client, c.Provider.UserInfoEndpoint, accessToken, &user); err != nil {
+		return Identity{}, err
+	}
+	if user.ID == 0 {
+		return Identity{}, errors.New("The provider did not say who signed in")
+	}
+	identity := Identity{Subject: strconv.Itoa(user.ID), Name: user.Name}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	emails := []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}{}
+	if err := getJSON(c.Client, c.Provider.EmailsEndpoint, accessToken, &emails); err != nil {
+		return Identity{}, err
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func getJSON(client Adapter, endpoint string, accessToken string, v interface{}) error {
+	request, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		request.Header.Add("Authorization", "Bearer "+accessToken)
+	}
+
+	return do(client, request, v)
+}
+
+func do(client Adapter, request *http.Request, v interface{}) error {
+	request.Header.Add("Accept", "application/json")
+	request.Header.Add("User-Agent", "Journal")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.New("The provider refused the request: " + response.Status)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,121 @@
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	reply := `{"authorization_endpoint":"https://id.example.com/auth","token_endpoint":"https://id.example.com/token","userinfo_endpoint":"https://id.example.com/userinfo"}`
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	// Test GitHub is known without discovery
+	if provider, err := Discover(nil, "github"); err != nil || provider.TokenEndpoint != GitHub.TokenEndpoint {
+		t.Error("Expected GitHub to be known")
+	}
+
+	// Test unknown names
+	if _, err := Discover(nil, "example"); err == nil {
+		t.Error("Expected error for a provider that is not a URL")
+	}
+
+	// Test issuer discovered
+	provider, err := Discover(nil, server.URL+"/")
+	if err != nil || path != "/.well-known/openid-configuration" || provider.UserInfoEndpoint != "https://id.example.com/userinfo" || provider.Scopes != "openid email profile" {
+		t.Errorf("Expected issuer to be discovered, got %v, %v", provider, err)
+	}
+
+	// Test incomplete configuration
+	reply = `{"authorization_endpoint":"https://id.example.com/auth"}`
+	if _, err := Discover(nil, server.URL); err == nil {
+		t.Error("Expected error when the issuer does not give every endpoint")
+	}
+}
+
+func TestClient_AuthorizationURL(t *testing.T) {
+	client := Client{ClientID: "journal", Provider: Provider{AuthorizationEndpoint: "https://id.example.com/auth?prompt=login", Scopes: "openid email"}}
+	location, _ := url.Parse(client.AuthorizationURL("abc", "https://journal.example.com/login/oidc/callback"))
+	query := location.Query()
+	if query.Get("prompt") != "login" || query.Get("client_id") != "journal" || query.Get("state") != "abc" || query.Get("scope") != "openid email" || query.Get("response_type") != "code" || query.Get("redirect_uri") != "https://journal.example.com/login/oidc/callback" {
+		t.Errorf("Expected authorization URL to carry the request, got %s", location)
+	}
+}
+
+func TestClient_Exchange(t *testing.T) {
+	var form url.Values
+	reply := `{"access_token":"token123","token_type":"Bearer"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	client := Client{ClientID: "journal", ClientSecret: "secret", Provider: Provider{TokenEndpoint: server.URL}}
+	token, err := client.Exchange("code123", "https://journal.example.com/login/oidc/callback")
+	if err != nil || token != "token123" || form.Get("code") != "code123" || form.Get("client_secret") != "secret" || form.Get("grant_type") != "authorization_code" {
+		t.Errorf("Expected code to be exchanged, got %s, %v", token, err)
+	}
+
+	// Test error given instead of a token, as GitHub does
+	reply = `{"error":"bad_verification_code"}`
+	if _, err := client.Exchange("code123", ""); err == nil || !strings.Contains(err.Error(), "bad_verification_code") {
+		t.Error("Expected error when no access token is given")
+	}
+}
+
+func TestClient_Identify(t *testing.T) {
+	var authorization string
+	status := http.StatusOK
+	replies := map[string]string{
+		"/userinfo":    `{"sub":"248289761001","email":"jamie@example.com","email_verified":true,"name":"Jamie"}`,
+		"/user":        `{"id":583231,"login":"jamie","name":""}`,
+		"/user/emails": `[{"email":"old@example.com","primary":false,"verified":true},{"email":"jamie@example.com","primary":true,"verified":true}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(status)
+		w.Write([]byte(replies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	// Test OpenID Connect userinfo
+	client := Client{Provider: Provider{UserInfoEndpoint: server.URL + "/userinfo"}}
+	identity, err := client.Identify("token123")
+	if err != nil || authorization != "Bearer token123" || identity.Subject != "248289761001" || identity.Email != "jamie@example.com" || !identity.EmailVerified || identity.Name != "Jamie" {
+		t.Errorf("Expected identity to be found, got %v, %v", identity, err)
+	}
+	replies["/userinfo"] = `{"sub":"248289761001","email":"jamie@example.com","email_verified":"true"}`
+	if identity, _ = client.Identify("token123"); !identity.EmailVerified {
+		t.Error("Expected email verified as a string to be read")
+	}
+	replies["/userinfo"] = `{"sub":"248289761001","email":"jamie@example.com"}`
+	if identity, _ = client.Identify("token123"); identity.EmailVerified {
+		t.Error("Expected email not said to be verified to be unverified")
+	}
+	replies["/userinfo"] = `{"email":"jamie@example.com"}`
+	if _, err = client.Identify("token123"); err == nil {
+		t.Error("Expected error when the provider does not give a subject")
+	}
+
+	// Test GitHub user and primary email
+	client = Client{Provider: Provider{UserInfoEndpoint: server.URL + "/user", EmailsEndpoint: server.URL + "/user/emails"}}
+	identity, err = client.Identify("token123")
+	if err != nil || identity.Subject != "583231" || identity.Email != "jamie@example.com" || !identity.EmailVerified || identity.Name != "jamie" {
+		t.Errorf("Expected GitHub identity to be found, got %v, %v", identity, err)
+	}
+
+	// Test token refused
+	status = http.StatusUnauthorized
+	if _, err = client.Identify("token123"); err == nil {
+		t.Error("Expected error when the token is refused")
+	}
+}
@@ -0,0 +1,94 @@
+package ping
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// IndexNow Client notifying search engines of changed URLs through the IndexNow protocol
+type IndexNow struct {
+	Client   Adapter
+	Endpoint string
+	Key      string
+}
+
+type indexNowRequest struct {
+	Host    string   `json:"host"`
+	Key     string   `json:"key"`
+	URLList []string `json:"urlList"`
+}
+
+// Submit Notify the endpoint that the given URLs, all on the same host, have changed
+func (i IndexNow) Submit(urls []string) error {
+	if i.Key == "" {
+		return errors.New("No key was found for IndexNow")
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	first, err := url.Parse(urls[0])
+	if err != nil {
+		return err
+	}
+
+	body, _ := json.Marshal(indexNowRequest{Host: first.Host, Key: i.Key, URLList: urls})
+	request, err := http.NewRequest("POST", i.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	return send(i.Client, request)
+}
+
+// WebSub Client pinging a WebSub hub when a topic, such as a feed, has new content
+type WebSub struct {
+	Client Adapter
+	Hub    string
+}
+
+// Publish Tell the hub that the topic has been updated
+func (w WebSub) Publish(topic string) error {
+	if w.Hub == "" {
+		return errors.New("No hub was found for WebSub")
+	}
+
+	form := url.Values{}
+	form.Add("hub.mode", "publish")
+	form.Add("hub.url", topic)
+	request, err := http.NewRequest("POST", w.Hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	return send(w.Client, request)
+}
+
+func send(client Adapter, request *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request.Header.Add("User-Agent", "Journal")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.New("Unexpected response status " + strconv.Itoa(response.StatusCode) + " from " + request.URL.Host)
+	}
+
+	return nil
+}
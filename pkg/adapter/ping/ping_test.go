@@ -0,0 +1,76 @@
+package ping
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIndexNow_Submit(t *testing.T) {
+	var received indexNowRequest
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// Test no key
+	client := IndexNow{Endpoint: server.URL}
+	if err := client.Submit([]string{"https://example.com/test"}); err == nil {
+		t.Error("Expected key error was not achieved")
+	}
+
+	// Test nothing to submit
+	client.Key = "abc123"
+	if err := client.Submit([]string{}); err != nil {
+		t.Error("Expected no error when there are no URLs")
+	}
+
+	// Test valid submission
+	if err := client.Submit([]string{"https://example.com/test", "https://example.com/other"}); err != nil {
+		t.Errorf("Expected no error, got %s", err)
+	}
+	if received.Host != "example.com" || received.Key != "abc123" || len(received.URLList) != 2 {
+		t.Error("Expected host, key and URLs to have been submitted")
+	}
+
+	// Test rejected submission
+	status = http.StatusUnprocessableEntity
+	if err := client.Submit([]string{"https://example.com/test"}); err == nil {
+		t.Error("Expected error when submission is rejected")
+	}
+}
+
+func TestWebSub_Publish(t *testing.T) {
+	var mode, topic string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode = r.FormValue("hub.mode")
+		topic = r.FormValue("hub.url")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// Test no hub
+	client := WebSub{}
+	if err := client.Publish("https://example.com/atom.xml"); err == nil {
+		t.Error("Expected hub error was not achieved")
+	}
+
+	// Test valid ping
+	client.Hub = server.URL
+	if err := client.Publish("https://example.com/atom.xml"); err != nil {
+		t.Errorf("Expected no error, got %s", err)
+	}
+	if mode != "publish" || topic != "https://example.com/atom.xml" {
+		t.Error("Expected publish ping to have been sent for the feed")
+	}
+
+	// Test failing hub
+	status = http.StatusInternalServerError
+	if err := client.Publish("https://example.com/atom.xml"); err == nil {
+		t.Error("Expected error when hub fails")
+	}
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DefaultEndpoint is the Bot API used when no other is configured
+const DefaultEndpoint = "https://api.telegram.org"
+
+// ErrTooLarge is returned when a file is larger than the limit it is downloaded with
+var ErrTooLarge = errors.New("File is larger than allowed")
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Chat A conversation a message was sent in
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// User Someone who sent a message
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// PhotoSize One of the sizes a photo is available in
+type PhotoSize struct {
+	FileID   string `json:"file_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int    `json:"file_size"`
+}
+
+// Document A file sent without being compressed as a photo
+type Document struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+}
+
+// Message A message sent to the bot, which may carry text, a photo with a caption or a document
+type Message struct {
+	MessageID int64       `json:"message_id"`
+	From      *User       `json:"from"`
+	Chat      Chat        `json:"chat"`
+	Date      int64       `json:"date"`
+	Text      string      `json:"text"`
+	Caption   string      `json:"caption"`
+	Photo     []PhotoSize `json:"photo"`
+	Document  *Document   `json:"document"`
+}
+
+// Update Something that happened since the bot last asked, numbered so each is only received once
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// File A file ready to be downloaded
+type File struct {
+	FileID   string `json:"file_id"`
+	FilePath string `json:"file_path"`
+}
+
+type response struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// Client Talks to the Telegram Bot API as the bot a token belongs to
+type Client struct {
+	Client   Adapter
+	Endpoint string
+	Token    string
+}
+
+// GetUpdates Wait up to timeout seconds for updates from offset onwards, acknowledging every update before it
+func (c Client) GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error) {
+	updates := []Update{}
+	err := c.call(ctx, "getUpdates", map[string]interface{}{"offset": offset, "timeout": timeout, "allowed_updates": []string{"message"}}, &updates)
+
+	return updates, err
+}
+
+// GetFile Get the path a file can be downloaded from
+func (c Client) GetFile(ctx context.Context, fileID string) (File, error) {
+	file := File{}
+	err := c.call(ctx, "getFile", map[string]interface{}{"file_id": fileID}, &file)
+
+	return file, err
+}
+
+// SendMessage Send a text message to a chat
+func (c Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]interface{}{"chat_id": chatID, "text": text}, nil)
+}
+
+// Download Fetch the contents of a file, refusing any larger than limit bytes
+func (c Client) Download(ctx context.Context, file File, limit int64) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", c.endpoint()+"/file/bot"+c.Token+"/"+strings.TrimPrefix(file.FilePath, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New("The file could not be downloaded: " + res.Status)
+	}
+
+	// Read one byte more than allowed to tell whether the limit was exceeded
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+
+	return data, nil
+}
+
+// call Send a method to the Bot API, reading its result into the value given
+func (c Client) call(ctx context.Context, method string, params map[string]interface{}, result interface{}) error {
+	if c.Token == "" {
+		return errors.New("No token was found for the Telegram bot")
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", c.endpoint()+"/bot"+c.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Add("Content-Type", "application/json")
+
+	res, err := c.do(request)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	r := response{}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&r); err != nil {
+		return err
+	}
+	if !r.OK {
+		return errors.New("Telegram refused " + method + ": " + r.Description)
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(r.Result, result)
+}
+
+func (c Client) do(request *http.Request) (*http.Response, error) {
+	request.Header.Add("User-Agent", "Journal")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(request)
+}
+
+func (c Client) endpoint() string {
+	if c.Endpoint == "" {
+		return DefaultEndpoint
+	}
+
+	return strings.TrimSuffix(c.Endpoint, "/")
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	params := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&params)
+		switch r.URL.Path {
+		case "/botabc123/getUpdates":
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"message_id":1,"chat":{"id":42},"text":"Hello","photo":[{"file_id":"small","width":90,"height":90}]}}]}`))
+		case "/botabc123/getFile":
+			w.Write([]byte(`{"ok":true,"result":{"file_id":"small","file_path":"photos/file_1.jpg"}}`))
+		case "/botabc123/sendMessage":
+			w.Write([]byte(`{"ok":true,"result":{"message_id":2}}`))
+		case "/file/botabc123/photos/file_1.jpg":
+			w.Write([]byte("image data"))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Test no token
+	if _, err := (Client{Endpoint: server.URL}).GetUpdates(ctx, 0, 0); err == nil {
+		t.Error("Expected error without a token")
+	}
+
+	client := Client{Endpoint: server.URL + "/", Token: "abc123"}
+	updates, err := client.GetUpdates(ctx, 5, 30)
+	if err != nil || len(updates) != 1 || updates[0].UpdateID != 7 || updates[0].Message.Chat.ID != 42 || updates[0].Message.Photo[0].FileID != "small" {
+		t.Errorf("Expected updates to be read, got %v, %v", updates, err)
+	}
+	if params["offset"] != float64(5) || params["timeout"] != float64(30) {
+		t.Errorf("Expected offset and timeout to be sent, got %v", params)
+	}
+
+	file, err := client.GetFile(ctx, "small")
+	if err != nil || file.FilePath != "photos/file_1.jpg" {
+		t.Errorf("Expected file to be found, got %v, %v", file, err)
+	}
+	data, err := client.Download(ctx, file, 100)
+	if err != nil || string(data) != "image data" {
+		t.Errorf("Expected file to be downloaded, got %s, %v", data, err)
+	}
+	if _, err := client.Download(ctx, file, 5); err != ErrTooLarge {
+		t.Errorf("Expected large file to be refused, got %v", err)
+	}
+	if _, err := client.Download(ctx, File{FilePath: "missing"}, 100); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+
+	if err := client.SendMessage(ctx, 42, "Posted"); err != nil || params["chat_id"] != float64(42) || params["text"] != "Posted" {
+		t.Errorf("Expected message to be sent, got %v", err)
+	}
+
+	// Test refused token
+	client.Token = "wrong"
+	if err := client.SendMessage(ctx, 42, "Posted"); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Expected error describing the refusal, got %v", err)
+	}
+}
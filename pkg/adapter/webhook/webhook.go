@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// SignaturePrefix Names the hash used in the signature header, in the same form as other services sending webhooks
+const SignaturePrefix = "sha256="
+
+// Adapter Common interface for sending an HTTP request
+type Adapter interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Client Sends events as JSON to the address of a webhook, signed with its secret
+type Client struct {
+	Client Adapter
+}
+
+// Sign Calculate the signature of a body, an HMAC-SHA256 keyed with the secret of the webhook
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify Check that a signature was made from a body with the secret given
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Deliver Post an event to a webhook, returning the status it replied with and an error when it was not accepted
+func (c Client) Deliver(address string, secret string, event string, deliveryID string, body []byte) (int, error) {
+	request, err := http.NewRequest("POST", address, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("User-Agent", "Journal")
+	request.Header.Add("X-Journal-Event", event)
+	request.Header.Add("X-Journal-Delivery", deliveryID)
+	request.Header.Add("X-Journal-Signature", Sign(secret, body))
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// Read a little of the reply so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, errors.New("Unexpected response status " + strconv.Itoa(response.StatusCode) + " from " + request.URL.Host)
+	}
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type failingAdapter struct{}
+
+func (f failingAdapter) Do(request *http.Request) (*http.Response, error) {
+	return nil, errors.New("Connection refused")
+}
+
+func TestSign(t *testing.T) {
+	// Known value for HMAC-SHA256 of "hello" keyed with "secret"
+	expected := "sha256=88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b"
+	if signature := Sign("secret", []byte("hello")); signature != expected {
+		t.Errorf("Expected signature %s, got %s", expected, signature)
+	}
+	if !Verify("secret", []byte("hello"), expected) {
+		t.Error("Expected signature to be verified")
+	}
+	if Verify("other", []byte("hello"), expected) || Verify("secret", []byte("hello!"), expected) {
+		t.Error("Expected signature not to be verified with a different secret or body")
+	}
+}
+
+func TestClient_Deliver(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := Client{}
+	code, err := client.Deliver(server.URL, "secret", "entry.created", "1", []byte("{\"event\":\"entry.created\"}"))
+	if err != nil || code != http.StatusNoContent {
+		t.Errorf("Expected delivery to be accepted, got %d, %s", code, err)
+	}
+	if received.Method != "POST" || received.Header.Get("Content-Type") != "application/json" || received.Header.Get("User-Agent") != "Journal" {
+		t.Error("Expected JSON to be posted")
+	}
+	if received.Header.Get("X-Journal-Event") != "entry.created" || received.Header.Get("X-Journal-Delivery") != "1" {
+		t.Error("Expected event and delivery headers to be sent")
+	}
+	if !Verify("secret", body, received.Header.Get("X-Journal-Signature")) {
+		t.Error("Expected body to be signed with the secret")
+	}
+
+	// Test refused delivery
+	status = http.StatusGone
+	if code, err := client.Deliver(server.URL, "secret", "entry.created", "2", []byte("{}")); err == nil || code != http.StatusGone {
+		t.Errorf("Expected refused delivery to return its status and an error, got %d", code)
+	}
+
+	// Test unreachable webhook
+	client.Client = failingAdapter{}
+	if code, err := client.Deliver(server.URL, "secret", "entry.created", "3", []byte("{}")); err == nil || code != 0 {
+		t.Error("Expected error when the webhook cannot be reached")
+	}
+
+	// Test invalid address
+	if _, err := client.Deliver("://", "secret", "entry.created", "4", []byte("{}")); err == nil {
+		t.Error("Expected error for an invalid address")
+	}
+}
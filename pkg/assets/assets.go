@@ -0,0 +1,136 @@
+package assets
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiefdhurst/journal/pkg/conditional"
+)
+
+// Assets Files served from a file system, such as stylesheets and scripts, with headers letting browsers keep them for
+// MaxAge seconds and then check whether they changed rather than fetching them again
+type Assets struct {
+	Files  fs.FS
+	MaxAge int
+}
+
+// Serve Serve a file by its name within the file system, with its type worked out from its extension or contents, and
+// say whether there was one. Directories, hidden files and names reaching outside it are never served.
+func (a Assets) Serve(response http.ResponseWriter, request *http.Request, name string) bool {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	if name == "" || a.Files == nil {
+		return false
+	}
+
+	file, err := a.Files.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	// Files built into the binary carry no time they were changed, so are tagged by a hash of their contents instead
+	etag := ETag(info)
+	content, ok := file.(io.ReadSeeker)
+	if !ok || info.ModTime().IsZero() {
+		body, err := io.ReadAll(file)
+		if err != nil {
+			return false
+		}
+		etag = conditional.ETag(body)
+		content = bytes.NewReader(body)
+	}
+
+	response.Header().Set("ETag", etag)
+	response.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(a.MaxAge))
+	http.ServeContent(response, request, info.Name(), info.ModTime(), content)
+
+	return true
+}
+
+// ETag Tag identifying a version of a file by its size and when it was last changed, cheap enough to give on every
+// request without reading it
+func ETag(info os.FileInfo) string {
+	return "\"" + strconv.FormatInt(info.Size(), 36) + "-" + strconv.FormatInt(info.ModTime().UnixNano(), 36) + "\""
+}
+
+// Overlay Files in a directory on disk, or of Top when it is given, laid over those of another file system, such as one
+// built into the binary, each file on top taking the place of the one at the same path beneath it and directories
+// listing the files of both
+type Overlay struct {
+	Dir  string
+	Top  fs.FS
+	Base fs.FS
+}
+
+// Open Open the file on top at a path, or the one beneath when there is none
+func (o Overlay) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	top, err := o.top().Open(name)
+	if err != nil {
+		return o.Base.Open(name)
+	}
+	if info, err := top.Stat(); err == nil && !info.IsDir() {
+		return top, nil
+	}
+
+	// A directory on top is only opened in place of one beneath when there is none there, both being listed together
+	if file, err := o.Base.Open(name); err == nil {
+		top.Close()
+		return file, nil
+	}
+
+	return top, nil
+}
+
+// ReadDir List a directory on top and beneath together, in order of name, with the files on top taking the place of
+// any of the same name beneath
+func (o Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, err := fs.ReadDir(o.top(), name)
+	base, baseErr := fs.ReadDir(o.Base, name)
+	if err != nil && baseErr != nil {
+		return nil, baseErr
+	}
+
+	entries := map[string]fs.DirEntry{}
+	for _, entry := range append(base, top...) {
+		entries[entry.Name()] = entry
+	}
+	names := []string{}
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	merged := []fs.DirEntry{}
+	for _, name := range names {
+		merged = append(merged, entries[name])
+	}
+
+	return merged, nil
+}
+
+// top Files laid over those beneath
+func (o Overlay) top() fs.FS {
+	if o.Top != nil {
+		return o.Top
+	}
+
+	return os.DirFS(o.Dir)
+}
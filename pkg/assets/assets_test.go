@@ -0,0 +1,118 @@
+package assets
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAssets_Serve(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "assets")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "css"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "css", "default.min.css"), []byte("body{color:red}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, ".secret"), []byte("secret"), 0644)
+	assets := Assets{Files: os.DirFS(dir), MaxAge: 3600}
+
+	// Test file is served with its type and caching headers
+	response := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/static/css/default.min.css", nil)
+	if !assets.Serve(response, request, "css/default.min.css") {
+		t.Fatal("Expected file to be served")
+	}
+	etag := response.Header().Get("ETag")
+	if response.Code != http.StatusOK || response.Body.String() != "body{color:red}" || etag == "" ||
+		response.Header().Get("Content-Type") != "text/css; charset=utf-8" || response.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Errorf("Expected file with caching headers, got %d and %v", response.Code, response.Header())
+	}
+
+	// Test unchanged files are not sent again
+	response = httptest.NewRecorder()
+	request.Header.Set("If-None-Match", etag)
+	assets.Serve(response, request, "css/default.min.css")
+	if response.Code != http.StatusNotModified || response.Body.Len() != 0 {
+		t.Errorf("Expected file not to be sent again, got %d", response.Code)
+	}
+
+	// Test missing files, directories, hidden files and names reaching outside are refused
+	for _, name := range []string{"css/missing.css", "css", "", ".secret", "../assets.go", "css/../../assets.go"} {
+		response = httptest.NewRecorder()
+		if assets.Serve(response, request, name) || response.Body.Len() != 0 {
+			t.Errorf("Expected %s not to be served", name)
+		}
+	}
+}
+
+func TestAssets_Serve_Embedded(t *testing.T) {
+	files := fstest.MapFS{"js/default.min.js": &fstest.MapFile{Data: []byte("alert(1)")}}
+	assets := Assets{Files: files, MaxAge: 60}
+
+	// Test files without a time they changed are tagged by their contents
+	response := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/static/js/default.min.js", nil)
+	if !assets.Serve(response, request, "js/default.min.js") || response.Body.String() != "alert(1)" {
+		t.Fatal("Expected file to be served")
+	}
+	etag := response.Header().Get("ETag")
+	files["js/default.min.js"] = &fstest.MapFile{Data: []byte("alert(2)")}
+	response = httptest.NewRecorder()
+	assets.Serve(response, request, "js/default.min.js")
+	if etag == "" || response.Header().Get("ETag") == etag {
+		t.Errorf("Expected changed contents to be tagged again, got %s", etag)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "overlay")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "css"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "css", "default.min.css"), []byte("body{color:blue}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "css", "dark.min.css"), []byte("body{color:white}"), 0644)
+	overlay := Overlay{Dir: dir, Base: fstest.MapFS{
+		"css/default.min.css": &fstest.MapFile{Data: []byte("body{color:red}")},
+		"js/default.min.js":   &fstest.MapFile{Data: []byte("alert(1)")},
+	}}
+
+	// Test files on disk take the place of those beneath, which are still found otherwise
+	for name, expected := range map[string]string{"css/default.min.css": "body{color:blue}", "css/dark.min.css": "body{color:white}", "js/default.min.js": "alert(1)"} {
+		if content, err := fs.ReadFile(overlay, name); err != nil || string(content) != expected {
+			t.Errorf("Expected %s to read %s, got %s", name, expected, content)
+		}
+	}
+	if _, err := fs.ReadFile(overlay, "css/missing.css"); err == nil {
+		t.Error("Expected missing file not to be found")
+	}
+
+	// Test directories list the files of both
+	names := []string{}
+	fs.WalkDir(overlay, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			names = append(names, path)
+		}
+		return err
+	})
+	if !reflect.DeepEqual(names, []string{"css/dark.min.css", "css/default.min.css", "js/default.min.js"}) {
+		t.Errorf("Expected files of both to be listed, got %v", names)
+	}
+}
+
+func TestOverlay_Top(t *testing.T) {
+	base := fstest.MapFS{
+		"templates/index.tmpl": &fstest.MapFile{Data: []byte("index")},
+		"templates/view.tmpl":  &fstest.MapFile{Data: []byte("view")},
+	}
+	overlay := Overlay{Top: fstest.MapFS{"templates/index.tmpl": &fstest.MapFile{Data: []byte("themed index")}}, Base: base}
+
+	// Test files of another file system can be laid over those beneath
+	for name, expected := range map[string]string{"templates/index.tmpl": "themed index", "templates/view.tmpl": "view"} {
+		if content, err := fs.ReadFile(overlay, name); err != nil || string(content) != expected {
+			t.Errorf("Expected %s to read %s, got %s", name, expected, content)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package certificate
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Files A certificate and key read from PEM files, read again once either changes so that certificates renewed by
+// another tool are served without a restart
+type Files struct {
+	Cert          string
+	Key           string
+	CheckInterval time.Duration
+	mu            sync.Mutex
+	certificate   *tls.Certificate
+	checked       time.Time
+	modified      time.Time
+}
+
+// NewFiles Read a certificate and key from PEM files, checking them for changes at most once a minute
+func NewFiles(cert string, key string) (*Files, error) {
+	f := &Files{Cert: cert, Key: key, CheckInterval: time.Minute}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// GetCertificate Give the certificate for a TLS handshake, reading the files again when they have changed and keeping
+// the certificate already read when they cannot be
+func (f *Files) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.checked) >= f.CheckInterval {
+		f.checked = time.Now()
+		if f.lastModified().After(f.modified) {
+			f.load()
+		}
+	}
+
+	return f.certificate, nil
+}
+
+func (f *Files) load() error {
+	modified := f.lastModified()
+	certificate, err := tls.LoadX509KeyPair(f.Cert, f.Key)
+	if err != nil {
+		return err
+	}
+	f.certificate = &certificate
+	f.modified = modified
+
+	return nil
+}
+
+// lastModified Get when the certificate or key last changed
+func (f *Files) lastModified() time.Time {
+	latest := time.Time{}
+	for _, name := range []string{f.Cert, f.Key} {
+		if info, err := os.Stat(name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
+}
+
+// NewManager Get a manager obtaining certificates from Let's Encrypt for the given hosts, and renewing them before they
+// expire, keeping them in a directory so that they survive restarts. The email is given to Let's Encrypt to warn of
+// problems with them, and may be left empty.
+func NewManager(hosts []string, cache string, email string) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cache),
+		Email:      email,
+	}
+}
+
+// Redirect Send requests made over plain HTTP to the same address over HTTPS, served on the given port
+func Redirect(port string) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		host := request.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if port != "" && port != "443" {
+			host = host + ":" + port
+		}
+
+		status := http.StatusMovedPermanently
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(response, request, "https://"+host+request.URL.RequestURI(), status)
+	})
+}
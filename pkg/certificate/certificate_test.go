@@ -0,0 +1,136 @@
+package certificate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writePair Write a self-signed certificate and its key, with the given serial number, as PEM files
+func writePair(t *testing.T, cert string, key string, serial int64) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "journal.example.com"},
+		DNSNames:     []string{"journal.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &private.PublicKey, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(private)
+	ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+}
+
+func serialOf(t *testing.T, certificate *tls.Certificate) int64 {
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed.SerialNumber.Int64()
+}
+
+func TestFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "certificate")
+	defer os.RemoveAll(dir)
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+
+	// Test files that cannot be read are refused
+	if _, err := NewFiles(cert, key); err == nil {
+		t.Error("Expected missing files to be refused")
+	}
+
+	writePair(t, cert, key, 1)
+	files, err := NewFiles(cert, key)
+	if err != nil {
+		t.Fatalf("Expected the certificate to be read, got %s", err)
+	}
+	certificate, err := files.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil || serialOf(t, certificate) != 1 {
+		t.Errorf("Expected the certificate read to be given, got %v", err)
+	}
+
+	// Test renewed files are only read again once checked
+	writePair(t, cert, key, 2)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(cert, later, later)
+	certificate, _ = files.GetCertificate(&tls.ClientHelloInfo{})
+	if serialOf(t, certificate) != 1 {
+		t.Error("Expected the files not to be checked again so soon")
+	}
+	files.CheckInterval = 0
+	certificate, _ = files.GetCertificate(&tls.ClientHelloInfo{})
+	if serialOf(t, certificate) != 2 {
+		t.Error("Expected the renewed certificate to be given")
+	}
+
+	// Test a broken renewal keeps the certificate already read
+	ioutil.WriteFile(key, []byte("broken"), 0600)
+	later = later.Add(time.Minute)
+	os.Chtimes(key, later, later)
+	certificate, err = files.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil || serialOf(t, certificate) != 2 {
+		t.Errorf("Expected the certificate already read to be kept, got %v", err)
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	manager := NewManager([]string{"journal.example.com"}, "/tmp/certs", "jamie@example.com")
+	if manager.Email != "jamie@example.com" || manager.Cache == nil {
+		t.Error("Expected the email and cache to be kept")
+	}
+	if err := manager.HostPolicy(context.Background(), "journal.example.com"); err != nil {
+		t.Errorf("Expected the host to be allowed, got %s", err)
+	}
+	if err := manager.HostPolicy(context.Background(), "other.example.com"); err == nil {
+		t.Error("Expected other hosts to be refused")
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	tables := []struct {
+		port     string
+		method   string
+		host     string
+		target   string
+		status   int
+		location string
+	}{
+		{"443", "GET", "journal.example.com", "/entry?page=2", http.StatusMovedPermanently, "https://journal.example.com/entry?page=2"},
+		{"443", "HEAD", "journal.example.com:80", "/", http.StatusMovedPermanently, "https://journal.example.com/"},
+		{"8443", "GET", "journal.example.com:8080", "/", http.StatusMovedPermanently, "https://journal.example.com:8443/"},
+		{"443", "POST", "journal.example.com", "/new", http.StatusPermanentRedirect, "https://journal.example.com/new"},
+		{"443", "GET", "[::1]:80", "/", http.StatusMovedPermanently, "https://[::1]/"},
+	}
+
+	for _, table := range tables {
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(table.method, table.target, nil)
+		request.Host = table.host
+		Redirect(table.port).ServeHTTP(response, request)
+		if response.Code != table.status || response.Header().Get("Location") != table.location {
+			t.Errorf("Expected %s %s%s to be sent to %s with %d, got %s with %d", table.method, table.host, table.target, table.location, table.status, response.Header().Get("Location"), response.Code)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package conditional
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Serve Send a page along with a tag made from a hash of it and the time it last changed, answering requests that
+// already hold the same page, going by either, with 304 Not Modified and no body. Clients are asked to check back
+// each time rather than keep the page for as long as they guess it will stay the same.
+func Serve(response http.ResponseWriter, request *http.Request, content []byte, modified time.Time) {
+	response.Header().Set("ETag", ETag(content))
+	if response.Header().Get("Cache-Control") == "" {
+		response.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(response, request, "", modified, bytes.NewReader(content))
+}
+
+// ETag Tag identifying a page by a hash of it, changing whenever any of it does
+func ETag(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "\"" + hex.EncodeToString(sum[:16]) + "\""
+}
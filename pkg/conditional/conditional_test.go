@@ -0,0 +1,60 @@
+package conditional
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	content := []byte("<p>Test</p>")
+	modified := time.Date(2018, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	// Test page is sent with its tag and time
+	response := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/test", nil)
+	Serve(response, request, content, modified)
+	etag := response.Header().Get("ETag")
+	if response.Code != http.StatusOK || response.Body.String() != "<p>Test</p>" || etag != ETag(content) ||
+		response.Header().Get("Last-Modified") != "Sun, 04 Mar 2018 10:00:00 GMT" || response.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("Expected page with its tag and time, got %d and %v", response.Code, response.Header())
+	}
+
+	// Test the same page is not sent again, by either
+	for header, value := range map[string]string{"If-None-Match": etag, "If-Modified-Since": "Sun, 04 Mar 2018 10:00:00 GMT"} {
+		response = httptest.NewRecorder()
+		request, _ = http.NewRequest("GET", "/test", nil)
+		request.Header.Set(header, value)
+		Serve(response, request, content, modified)
+		if response.Code != http.StatusNotModified || response.Body.Len() != 0 {
+			t.Errorf("Expected page not to be sent again with %s, got %d", header, response.Code)
+		}
+	}
+
+	// Test a changed page is sent, even when its time is the same
+	response = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/test", nil)
+	request.Header.Set("If-None-Match", etag)
+	request.Header.Set("If-Modified-Since", "Sun, 04 Mar 2018 10:00:00 GMT")
+	Serve(response, request, []byte("<p>Changed</p>"), modified)
+	if response.Code != http.StatusOK || response.Body.String() != "<p>Changed</p>" {
+		t.Errorf("Expected changed page to be sent, got %d", response.Code)
+	}
+
+	// Test an earlier time sends the page, and caching headers already given are kept
+	response = httptest.NewRecorder()
+	response.Header().Set("Cache-Control", "private, no-cache")
+	request, _ = http.NewRequest("GET", "/test", nil)
+	request.Header.Set("If-Modified-Since", "Sat, 03 Mar 2018 10:00:00 GMT")
+	Serve(response, request, content, modified)
+	if response.Code != http.StatusOK || response.Header().Get("Cache-Control") != "private, no-cache" {
+		t.Errorf("Expected page changed since to be sent, got %d and %v", response.Code, response.Header())
+	}
+}
+
+func TestETag(t *testing.T) {
+	if ETag([]byte("a")) == ETag([]byte("b")) || ETag([]byte("a")) != ETag([]byte("a")) || len(ETag([]byte("a"))) != 34 {
+		t.Error("Expected a quoted tag that changes only with the content")
+	}
+}
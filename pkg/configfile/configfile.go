@@ -0,0 +1,291 @@
+package configfile
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrFormat The file is neither TOML nor YAML, going by its extension
+var ErrFormat = errors.New("Settings can only be read from .toml, .yaml or .yml files")
+
+// Settings Values read from a file by the name of their setting, each holding a string, int, bool or, for lists, a
+// slice of strings
+type Settings map[string]interface{}
+
+// validKey Names of settings and the tables or mappings holding them
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Parse Read the settings of a TOML or YAML file, told apart by its extension. Only the flat subset needed for settings
+// is understood: strings, whole numbers, booleans and lists of strings, either at the top of the file or one level
+// within a [table] or mapping. Settings within one are named after it, e.g. path within db becomes db_path, as are
+// dotted keys such as db.path, and dashes become underscores.
+func Parse(name string, source string) (Settings, error) {
+	source = strings.TrimPrefix(strings.ReplaceAll(source, "\r\n", "\n"), "\ufeff")
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml":
+		return parseTOML(source)
+	case ".yaml", ".yml":
+		return parseYAML(source)
+	}
+
+	return nil, ErrFormat
+}
+
+func parseTOML(source string) (Settings, error) {
+	settings := Settings{}
+	table := ""
+	for n, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(uncomment(line, false))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "[["):
+			return nil, lineError(n, "arrays of tables are not supported")
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if !validKey.MatchString(table) {
+				return nil, lineError(n, "%s is not a valid table name", line)
+			}
+			continue
+		}
+
+		equals := strings.Index(line, "=")
+		if equals < 0 {
+			return nil, lineError(n, "expected a setting written as name = value")
+		}
+		key := strings.TrimSpace(line[:equals])
+		value, err := tomlValue(strings.TrimSpace(line[equals+1:]))
+		if err != nil {
+			return nil, lineError(n, "%s of %s", err, key)
+		}
+		if err := settings.set(table, key, value); err != nil {
+			return nil, lineError(n, "%s", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// tomlValue Read a single TOML value as a string, int, bool or list of strings
+func tomlValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	case strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]"):
+		list := []string{}
+		for _, item := range splitList(raw[1 : len(raw)-1]) {
+			value, err := tomlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := value.([]string); ok {
+				return nil, errors.New("nested list")
+			}
+			list = append(list, fmt.Sprint(value))
+		}
+		return list, nil
+	case len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"':
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, errors.New("badly quoted value")
+		}
+		return value, nil
+	case len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'':
+		return raw[1 : len(raw)-1], nil
+	}
+	if number, err := strconv.Atoi(strings.ReplaceAll(raw, "_", "")); err == nil {
+		return number, nil
+	}
+
+	return nil, errors.New("expected a quoted string, whole number, boolean or list as the value")
+}
+
+func parseYAML(source string) (Settings, error) {
+	settings := Settings{}
+	parent := ""
+	indent := 0
+	for n, line := range strings.Split(source, "\n") {
+		line = strings.TrimRight(uncomment(line, true), " \t")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || line == "---" || line == "..." {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, lineError(n, "tabs cannot be used to indent YAML")
+		}
+
+		// Lines indented beneath a setting left without a value list its items or hold the settings within it
+		depth := len(line) - len(trimmed)
+		if depth > 0 {
+			if parent == "" {
+				return nil, lineError(n, "indented beneath nothing")
+			}
+			if indent == 0 {
+				indent = depth
+			}
+			if depth != indent {
+				return nil, lineError(n, "settings can only be nested one level deep")
+			}
+			if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+				list, ok := settings[name("", parent)].([]string)
+				if !ok && settings[name("", parent)] != nil {
+					return nil, lineError(n, "%s mixes a list with settings", parent)
+				}
+				settings[name("", parent)] = append(list, fmt.Sprint(yamlValue(strings.TrimPrefix(trimmed, "-"))))
+				continue
+			}
+			if _, ok := settings[name("", parent)].([]string); ok {
+				return nil, lineError(n, "%s mixes a list with settings", parent)
+			}
+		} else {
+			parent = ""
+			indent = 0
+		}
+
+		colon := strings.Index(trimmed, ":")
+		if colon < 0 {
+			return nil, lineError(n, "expected a setting written as name: value")
+		}
+		key := strings.TrimSpace(trimmed[:colon])
+		raw := strings.TrimSpace(trimmed[colon+1:])
+		if raw == "" {
+			if depth > 0 {
+				return nil, lineError(n, "settings can only be nested one level deep")
+			}
+			if !validKey.MatchString(key) {
+				return nil, lineError(n, "%s is not a valid name", key)
+			}
+			parent = key
+			continue
+		}
+		table := ""
+		if depth > 0 {
+			table = parent
+		}
+		if err := settings.set(table, key, yamlValue(raw)); err != nil {
+			return nil, lineError(n, "%s", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// yamlValue Read a single YAML value as a string, int, bool or list of strings
+func yamlValue(raw string) interface{} {
+	raw = strings.TrimSpace(raw)
+	switch strings.ToLower(raw) {
+	case "true", "yes", "on":
+		return true
+	case "false", "no", "off":
+		return false
+	}
+	switch {
+	case strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]"):
+		list := []string{}
+		for _, item := range splitList(raw[1 : len(raw)-1]) {
+			list = append(list, fmt.Sprint(yamlValue(item)))
+		}
+		return list
+	case len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"':
+		if value, err := strconv.Unquote(raw); err == nil {
+			return value
+		}
+		return raw[1 : len(raw)-1]
+	case len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'':
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'")
+	}
+	if number, err := strconv.Atoi(raw); err == nil {
+		return number
+	}
+
+	return raw
+}
+
+// set Keep a setting by its full name, refusing names that are not valid or are given twice
+func (s Settings) set(table string, key string, value interface{}) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("%s is not a valid name", key)
+	}
+	full := name(table, key)
+	if _, ok := s[full]; ok {
+		return fmt.Errorf("%s is set more than once", full)
+	}
+	s[full] = value
+
+	return nil
+}
+
+// name Get the full name of a setting, within any table, in lowercase with underscores between its words
+func name(table string, key string) string {
+	if table != "" {
+		key = table + "_" + key
+	}
+
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToLower(key))
+}
+
+// uncomment Remove a comment from the end of a line, leaving any # inside quotes alone. YAML only starts comments at
+// the beginning of a line or after a space, and quotes at the beginning of a value.
+func uncomment(line string, spaced bool) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\'') && (!spaced || startsValue(line[:i])):
+			quote = r
+		case quote == 0 && r == '#' && (!spaced || i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// startsValue Check whether what comes next in a line of YAML starts a value, rather than continuing one
+func startsValue(before string) bool {
+	before = strings.TrimRight(before, " \t")
+
+	return before == "" || strings.ContainsAny(before[len(before)-1:], ":-[,")
+}
+
+// splitList Split the items of a list written inline, leaving commas inside quotes alone
+func splitList(list string) []string {
+	items := []string{}
+	item := strings.Builder{}
+	var quote rune
+	for _, r := range list {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == ',':
+			if trimmed := strings.TrimSpace(item.String()); trimmed != "" {
+				items = append(items, trimmed)
+			}
+			item.Reset()
+			continue
+		}
+		item.WriteRune(r)
+	}
+	if trimmed := strings.TrimSpace(item.String()); trimmed != "" {
+		items = append(items, trimmed)
+	}
+
+	return items
+}
+
+// lineError Describe a problem found on a line, counting from one
+func lineError(n int, format string, args ...interface{}) error {
+	return fmt.Errorf("Line %d: "+format, append([]interface{}{n + 1}, args...)...)
+}
@@ -0,0 +1,107 @@
+package configfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_TOML(t *testing.T) {
+	source := `# Journal settings
+title = "Jamie's \"Journal\"" # shown in the header
+port = 3_000
+url = 'https://example.com/#top'
+create = false
+trusted-proxies = ["10.0.0.0/8", "192.168.1.1",]
+
+[db]
+path = "/data/journal.db"
+max_open = 5
+
+[media]
+path = "/data/media"
+db.timeout = 10
+`
+	settings, err := Parse("journal.toml", source)
+	if err != nil {
+		t.Fatalf("Expected settings to be read, got %s", err)
+	}
+	expected := Settings{
+		"title":            `Jamie's "Journal"`,
+		"port":             3000,
+		"url":              "https://example.com/#top",
+		"create":           false,
+		"trusted_proxies":  []string{"10.0.0.0/8", "192.168.1.1"},
+		"db_path":          "/data/journal.db",
+		"db_max_open":      5,
+		"media_path":       "/data/media",
+		"media_db_timeout": 10,
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Expected %v, got %v", expected, settings)
+	}
+}
+
+func TestParse_YAML(t *testing.T) {
+	source := `---
+# Journal settings
+title: Jamie's Journal # shown in the header
+port: 3000
+url: "https://example.com/#top"
+create: no
+trusted-proxies:
+  - 10.0.0.0/8
+  - '192.168.1.1'
+db:
+  path: /data/journal.db
+  max_open: 5
+media_path: [/data/media]
+`
+	settings, err := Parse("journal.yml", source)
+	if err != nil {
+		t.Fatalf("Expected settings to be read, got %s", err)
+	}
+	expected := Settings{
+		"title":           "Jamie's Journal",
+		"port":            3000,
+		"url":             "https://example.com/#top",
+		"create":          false,
+		"trusted_proxies": []string{"10.0.0.0/8", "192.168.1.1"},
+		"db_path":         "/data/journal.db",
+		"db_max_open":     5,
+		"media_path":      []string{"/data/media"},
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Expected %v, got %v", expected, settings)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tables := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{"journal.json", `{}`, ErrFormat.Error()},
+		{"journal.toml", "title", "Line 1: expected a setting"},
+		{"journal.toml", "\ntitle = Journal", "Line 2: expected a quoted string"},
+		{"journal.toml", `title = "Journal`, "Line 1: expected a quoted string"},
+		{"journal.toml", "port = 1\nport = 2", "Line 2: port is set more than once"},
+		{"journal.toml", "[[db]]", "Line 1: arrays of tables"},
+		{"journal.toml", "[db path]", "Line 1: [db path] is not a valid table name"},
+		{"journal.toml", "my title = 1", "Line 1: my title is not a valid name"},
+		{"journal.yaml", "  port: 1", "Line 1: indented beneath nothing"},
+		{"journal.yaml", "db:\n  sqlite:\n    path: x", "Line 2: settings can only be nested one level deep"},
+		{"journal.yaml", "db:\n  path: x\n    more: y", "Line 3: settings can only be nested one level deep"},
+		{"journal.yaml", "db:\n  - x\n  path: y", "Line 3: db mixes a list with settings"},
+		{"journal.yaml", "title", "Line 1: expected a setting"},
+		{"journal.yaml", "db:\n\t- x", "Line 2: tabs cannot be used"},
+	}
+
+	for _, table := range tables {
+		_, err := Parse(table.name, table.source)
+		if err == nil || !strings.HasPrefix(err.Error(), table.err) {
+			t.Errorf("Expected %s to fail with '%s', got %v", table.source, table.err, err)
+		}
+	}
+}
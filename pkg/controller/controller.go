@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"reflect"
 )
 
 // Controller Main interface for controllers
@@ -10,6 +11,12 @@ type Controller interface {
 	Run(response http.ResponseWriter, request *http.Request)
 }
 
+// Cloner A controller that makes its own copies, such as one wrapping other controllers that must be copied along
+// with it
+type Cloner interface {
+	Clone() Controller
+}
+
 // Super Super-struct for all controllers.
 type Super struct {
 	Controller
@@ -22,3 +29,20 @@ func (c *Super) Init(app interface{}, params []string) {
 	c.Container = app
 	c.Params = params
 }
+
+// New Create a controller for a single request from the one registered, so that requests served at the same time
+// never share one. The registered controller is copied as it was registered, never having been initialised itself.
+func New(registered Controller) Controller {
+	if cloner, ok := registered.(Cloner); ok {
+		return cloner.Clone()
+	}
+
+	value := reflect.ValueOf(registered)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return registered
+	}
+	fresh := reflect.New(value.Elem().Type())
+	fresh.Elem().Set(value.Elem())
+
+	return fresh.Interface().(Controller)
+}
@@ -17,3 +17,25 @@ func TestInit(t *testing.T) {
 		t.Error("Expected values were not passed into struct")
 	}
 }
+
+type sharedController struct {
+	Super
+}
+
+func (c *sharedController) Clone() Controller {
+	return c
+}
+
+func TestNew(t *testing.T) {
+	registered := &Super{}
+	fresh := New(registered).(*Super)
+	fresh.Init(BlankInterface{}, []string{"param1"})
+	if fresh == registered || registered.Container != nil || registered.Params != nil {
+		t.Error("Expected a fresh controller, leaving the registered one untouched")
+	}
+
+	shared := &sharedController{}
+	if New(shared) != shared {
+		t.Error("Expected controller making its own copies to be asked for one")
+	}
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+// ContextDatabase A database able to stop a statement once the context it runs under is cancelled
+type ContextDatabase interface {
+	Database
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (rows.Rows, error)
+}
+
+// Bound A database running every statement under a context, such as that of the request it serves, giving each no
+// longer than the timeout when one is set
+type Bound struct {
+	Database
+	Context context.Context
+	Timeout time.Duration
+}
+
+// WithContext Bind a database to a context, replacing any context it was already bound to
+func WithContext(db Database, ctx context.Context, timeout time.Duration) Database {
+	if bound, ok := db.(*Bound); ok {
+		db = bound.Database
+	}
+
+	return &Bound{Database: db, Context: ctx, Timeout: timeout}
+}
+
+// Dialect Name the SQL dialect spoken by the database bound
+func (b *Bound) Dialect() string {
+	return DialectOf(b.Database)
+}
+
+// Exec Execute a query on the database, stopping it once the context is cancelled or the timeout passes
+func (b *Bound) Exec(query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := b.start()
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if db, ok := b.Database.(ContextDatabase); ok {
+		return db.ExecContext(ctx, query, args...)
+	}
+
+	return b.Database.Exec(query, args...)
+}
+
+// Query Query the database, stopping once the context is cancelled or the timeout passes, which keeps running until
+// the rows are closed
+func (b *Bound) Query(query string, args ...interface{}) (rows.Rows, error) {
+	ctx, cancel := b.start()
+	if err := ctx.Err(); err != nil {
+		cancel()
+		return nil, err
+	}
+	db, ok := b.Database.(ContextDatabase)
+	if !ok {
+		cancel()
+		return b.Database.Query(query, args...)
+	}
+	result, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return result, err
+	}
+
+	return &boundRows{Rows: result, cancel: cancel}, nil
+}
+
+// start Begin the time allowed for a statement
+func (b *Bound) start() (context.Context, context.CancelFunc) {
+	if b.Timeout > 0 {
+		return context.WithTimeout(b.Context, b.Timeout)
+	}
+
+	return context.WithCancel(b.Context)
+}
+
+// boundRows Rows read under a context that is released once they are closed
+type boundRows struct {
+	rows.Rows
+	cancel context.CancelFunc
+}
+
+// Close Close the rows and release their context
+func (r *boundRows) Close() error {
+	defer r.cancel()
+
+	return r.Rows.Close()
+}
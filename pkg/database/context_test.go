@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithContext(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "context")
+	defer os.RemoveAll(dir)
+	sqlite := &Sqlite{}
+	if err := sqlite.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer sqlite.Close()
+
+	db := WithContext(sqlite, context.Background(), time.Second)
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var value int
+	if !rows.Next() || rows.Scan(&value) != nil || value != 1 {
+		t.Error("Expected rows to be read until they are closed")
+	}
+	rows.Close()
+
+	// A query running longer than allowed is stopped
+	db = WithContext(db, context.Background(), 50*time.Millisecond)
+	if bound := db.(*Bound); bound.Database != sqlite {
+		t.Error("Expected binding again to replace the context")
+	}
+	started := time.Now()
+	_, err = db.Exec("WITH RECURSIVE `c`(`x`) AS (SELECT 1 UNION ALL SELECT `x` + 1 FROM `c` WHERE `x` < 1000000000) SELECT COUNT(*) FROM `c`")
+	if err == nil || time.Since(started) > 5*time.Second {
+		t.Errorf("Expected slow query to be stopped, got %v after %s", err, time.Since(started))
+	}
+
+	// Nothing runs once the context has been cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	db = WithContext(sqlite, ctx, 0)
+	if _, err := db.Exec("SELECT 1"); err != context.Canceled {
+		t.Errorf("Expected cancelled context to stop the statement, got %v", err)
+	}
+	if _, err := db.Query("SELECT 1"); err != context.Canceled {
+		t.Errorf("Expected cancelled context to stop the query, got %v", err)
+	}
+}
+
+func TestBound_Dialect(t *testing.T) {
+	if DialectOf(WithContext(&Postgres{}, context.Background(), 0)) != DialectPostgres {
+		t.Error("Expected bound database to speak the dialect of the database it binds")
+	}
+}
@@ -1,7 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/jamiefdhurst/journal/pkg/database/rows"
 	_ "github.com/mattn/go-sqlite3" // SQLite 3 driver
@@ -15,29 +20,152 @@ type Database interface {
 	Query(sql string, args ...interface{}) (rows.Rows, error)
 }
 
+// Dialects of SQL spoken by the supported databases
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSqlite   = "sqlite"
+)
+
+// New Create a connection for the named driver, ready to be connected with the given pool
+func New(driver string, pool Pool) (Database, error) {
+	switch driver {
+	case "", DialectSqlite, "sqlite3":
+		return &Sqlite{Pool: pool}, nil
+	case DialectMySQL, "mariadb":
+		return &MySQL{Pool: pool}, nil
+	case DialectPostgres, "postgresql":
+		return &Postgres{Pool: pool}, nil
+	}
+
+	return nil, errors.New("Unknown database " + driver + ", expected sqlite, postgres or mysql")
+}
+
+// DialectOf Name the SQL dialect a database speaks, which is SQLite's unless it says otherwise
+func DialectOf(db Database) string {
+	if d, ok := db.(interface{ Dialect() string }); ok {
+		return d.Dialect()
+	}
+
+	return DialectSqlite
+}
+
+// Memory File name opening an SQLite database kept in memory, which starts empty and is lost once closed
+const Memory = ":memory:"
+
+// memoryDatabases Number of databases kept in memory so far, naming each so that they are kept apart
+var memoryDatabases uint64
+
 // Sqlite Handle an Sqlite connection
 type Sqlite struct {
 	Database
-	db *sql.DB
+	Pool       Pool
+	Pragmas    Pragmas
+	db         *sql.DB
+	keep       *sql.Conn
+	statements statements
 }
 
 // Close Close open database
 func (s *Sqlite) Close() {
+	s.statements.close()
+	if s.keep != nil {
+		s.keep.Close()
+	}
 	s.db.Close()
 }
 
-// Connect Connect/open the database
+// Connect Connect/open the database, keeping it in memory when the file is :memory:
 func (s *Sqlite) Connect(dbFile string) error {
-	s.db, _ = sql.Open("sqlite3", dbFile)
-	return s.db.Ping()
+	memory := dbFile == Memory
+	if memory {
+		dbFile = "file:memory-" + strconv.FormatUint(atomic.AddUint64(&memoryDatabases, 1), 10) + "?mode=memory&cache=shared"
+	}
+	s.db, _ = sql.Open("sqlite3", sqliteDSN(dbFile, s.Pool, s.Pragmas))
+	s.Pool.apply(s.db)
+	if !memory {
+		return s.db.Ping()
+	}
+
+	// Every connection shares the database in memory, which is lost once the last is closed, so one is kept open
+	var err error
+	s.keep, err = s.db.Conn(context.Background())
+
+	return err
 }
 
 // Exec Execute a query on the database, returning a simple result
-func (s *Sqlite) Exec(sql string, args ...interface{}) (sql.Result, error) {
-	return s.db.Exec(sql, args...)
+func (s *Sqlite) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), query, args...)
+}
+
+// BeginContext Begin a transaction, stopped and rolled back once the context is cancelled
+func (s *Sqlite) BeginContext(ctx context.Context) (*Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{dialect: DialectSqlite, tx: tx, exec: s.exec, query: s.query}, nil
+}
+
+// ExecContext Execute a query on the database, stopping it once the context is cancelled
+func (s *Sqlite) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.exec(ctx, nil, query, args...)
 }
 
 // Query Query the database
-func (s *Sqlite) Query(sql string, args ...interface{}) (rows.Rows, error) {
-	return s.db.Query(sql, args...)
+func (s *Sqlite) Query(query string, args ...interface{}) (rows.Rows, error) {
+	return s.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext Query the database, stopping once the context is cancelled
+func (s *Sqlite) QueryContext(ctx context.Context, query string, args ...interface{}) (rows.Rows, error) {
+	return s.query(ctx, nil, query, args...)
+}
+
+// exec Execute a query, within the transaction when one is given, retrying it while the database is locked unless it
+// is in a transaction, which holds locks a retry would have to wait on
+func (s *Sqlite) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (result sql.Result, err error) {
+	if tx != nil {
+		return s.statements.exec(ctx, s.db, tx, query, args...)
+	}
+	err = s.Pool.retry(func() error {
+		result, err = s.statements.exec(ctx, s.db, nil, query, args...)
+		return err
+	})
+
+	return result, err
+}
+
+// query Query the database, within the transaction when one is given, retrying while the database is locked unless
+// it is in a transaction
+func (s *Sqlite) query(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (result rows.Rows, err error) {
+	if tx != nil {
+		return s.statements.query(ctx, s.db, tx, query, args...)
+	}
+	err = s.Pool.retry(func() error {
+		result, err = s.statements.query(ctx, s.db, nil, query, args...)
+		return err
+	})
+
+	return result, err
+}
+
+// sqliteDSN Give the file to open how long to wait for a lock and the pragmas to set, unless it already says
+func sqliteDSN(dbFile string, pool Pool, pragmas Pragmas) string {
+	params := []string{}
+	if pool.BusyTimeout > 0 && !strings.Contains(dbFile, "_busy_timeout=") && !strings.Contains(dbFile, "_timeout=") {
+		params = append(params, "_busy_timeout="+strconv.FormatInt(pool.BusyTimeout.Milliseconds(), 10))
+	}
+	params = pragmas.params(params, dbFile)
+	if len(params) == 0 {
+		return dbFile
+	}
+	separator := "?"
+	if strings.Contains(dbFile, "?") {
+		separator = "&"
+	}
+
+	return dbFile + separator + strings.Join(params, "&")
 }
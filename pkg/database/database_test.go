@@ -1,30 +1,29 @@
 package database
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestSqliteClose(t *testing.T) {
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 	sqlite := &Sqlite{}
-	_ = sqlite.Connect("test/data/test.db")
+	_ = sqlite.Connect("../../test/data/test.db")
 	sqlite.Close()
 }
 
 func TestSqliteConnect(t *testing.T) {
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 	sqlite := &Sqlite{}
-	err := sqlite.Connect("test/data/test.db")
+	err := sqlite.Connect("../../test/data/test.db")
 	if err != nil {
 		t.Errorf("Expected database to have been connected and no error to have been returned")
 	}
 }
 
 func TestSqliteExec(t *testing.T) {
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 	sqlite := &Sqlite{}
-	_ = sqlite.Connect("test/data/test.db")
+	_ = sqlite.Connect("../../test/data/test.db")
 	result, err := sqlite.Exec("SELECT 1")
 	rows, _ := result.RowsAffected()
 	if err != nil || rows > 0 {
@@ -33,9 +32,8 @@ func TestSqliteExec(t *testing.T) {
 }
 
 func TestSqliteQuery(t *testing.T) {
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
 	sqlite := &Sqlite{}
-	_ = sqlite.Connect("test/data/test.db")
+	_ = sqlite.Connect("../../test/data/test.db")
 	rows, err := sqlite.Query("SELECT 1 AS example")
 	if err != nil {
 		t.Errorf("Expected query to have been executed")
@@ -53,3 +51,123 @@ func TestSqliteQuery(t *testing.T) {
 	}
 	rows.Close()
 }
+
+func TestSqliteConnect_Memory(t *testing.T) {
+	sqlite := &Sqlite{Pragmas: Pragmas{ForeignKeys: true, JournalMode: "WAL", Synchronous: "NORMAL"}}
+	if err := sqlite.Connect(Memory); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := sqlite.Exec("CREATE TABLE `journal` (`title` TEXT)"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	sqlite.Exec("INSERT INTO `journal` VALUES('In memory')")
+
+	// Every connection shares the same database, while others in memory are kept apart
+	err := Transaction(sqlite, func(tx Database) error {
+		rows, err := sqlite.Query("SELECT `title` FROM `journal`")
+		if err != nil || !rows.Next() {
+			t.Error("Expected another connection to read the same database")
+		}
+		rows.Close()
+		return err
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	other := &Sqlite{}
+	other.Connect(Memory)
+	if _, err := other.Query("SELECT `title` FROM `journal`"); err == nil {
+		t.Error("Expected each database in memory to be kept apart")
+	}
+	other.Close()
+	sqlite.Close()
+}
+
+func TestNew(t *testing.T) {
+	db, err := New("", Pool{Retries: 2})
+	if sqlite, ok := db.(*Sqlite); !ok || err != nil || sqlite.Pool.Retries != 2 {
+		t.Error("Expected SQLite to be used by default with the pool given")
+	}
+	db, err = New("mysql", Pool{})
+	if _, ok := db.(*MySQL); !ok || err != nil {
+		t.Error("Expected MySQL to be used when asked for")
+	}
+	db, err = New("postgres", Pool{})
+	if _, ok := db.(*Postgres); !ok || err != nil {
+		t.Error("Expected PostgreSQL to be used when asked for")
+	}
+	if _, err = New("oracle", Pool{}); err == nil {
+		t.Error("Expected an unknown database to be refused")
+	}
+}
+
+func TestSqliteDSN(t *testing.T) {
+	pool := Pool{BusyTimeout: 2 * time.Second}
+	tests := []struct {
+		file     string
+		pool     Pool
+		expected string
+	}{
+		{"journal.db", pool, "journal.db?_busy_timeout=2000"},
+		{"journal.db?cache=shared", pool, "journal.db?cache=shared&_busy_timeout=2000"},
+		{"journal.db?_timeout=100", pool, "journal.db?_timeout=100"},
+		{"journal.db", Pool{}, "journal.db"},
+	}
+	for _, test := range tests {
+		if actual := sqliteDSN(test.file, test.pool, Pragmas{}); actual != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, actual)
+		}
+	}
+}
+
+func TestSqliteDSN_Pragmas(t *testing.T) {
+	pragmas := Pragmas{ForeignKeys: true, JournalMode: "WAL", Synchronous: "NORMAL"}
+	if actual := sqliteDSN("journal.db", Pool{}, pragmas); actual != "journal.db?_foreign_keys=1&_journal_mode=WAL&_synchronous=NORMAL" {
+		t.Errorf("Expected pragmas to be given to the driver, got %s", actual)
+	}
+	if actual := sqliteDSN("journal.db?_journal_mode=DELETE", Pool{BusyTimeout: time.Second}, pragmas); actual != "journal.db?_journal_mode=DELETE&_busy_timeout=1000&_foreign_keys=1&_synchronous=NORMAL" {
+		t.Errorf("Expected pragmas already given to be kept, got %s", actual)
+	}
+	if actual := sqliteDSN("replica.db", Pool{}, Pragmas{QueryOnly: true}); actual != "replica.db?_query_only=1" {
+		t.Errorf("Expected replica to refuse writes, got %s", actual)
+	}
+}
+
+func TestSqliteConnect_WAL(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "wal")
+	defer os.RemoveAll(dir)
+	sqlite := &Sqlite{Pragmas: Pragmas{ForeignKeys: true, JournalMode: "WAL", Synchronous: "NORMAL"}}
+	if err := sqlite.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer sqlite.Close()
+	for pragma, expected := range map[string]string{"journal_mode": "wal", "foreign_keys": "1", "synchronous": "1"} {
+		rows, _ := sqlite.Query("PRAGMA " + pragma)
+		var value string
+		if rows.Next() {
+			rows.Scan(&value)
+		}
+		rows.Close()
+		if value != expected {
+			t.Errorf("Expected %s to be %s, got %s", pragma, expected, value)
+		}
+	}
+}
+
+func TestIsMode(t *testing.T) {
+	if !IsMode("wal", JournalModes) || !IsMode("NORMAL", SynchronousModes) || IsMode("FAST", SynchronousModes) {
+		t.Error("Expected only known modes to be recognised, whatever their case")
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	if DialectOf(&Sqlite{}) != DialectSqlite {
+		t.Error("Expected SQLite to speak its own dialect")
+	}
+	if DialectOf(&MySQL{}) != DialectMySQL {
+		t.Error("Expected MySQL to speak its own dialect")
+	}
+	if DialectOf(&Postgres{}) != DialectPostgres {
+		t.Error("Expected PostgreSQL to speak its own dialect")
+	}
+}
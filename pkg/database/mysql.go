@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+var (
+	reMySQLAutoincrement = regexp.MustCompile(`(?i)INTEGER PRIMARY KEY AUTOINCREMENT`)
+	reMySQLDateTypes     = regexp.MustCompile(`\b(DATETIME|DATE)\b`)
+	reMySQLExcluded      = regexp.MustCompile("(?i)excluded\\.(`[^`]+`)")
+	reMySQLIgnore        = regexp.MustCompile(`(?i)\s*ON CONFLICT DO NOTHING\s*$`)
+	reMySQLTextDefault   = regexp.MustCompile(`TEXT NOT NULL DEFAULT ('[^']*')`)
+	reMySQLUpsert        = regexp.MustCompile(`(?is)\s*ON CONFLICT\s*\([^)]*\)\s*DO UPDATE SET\s+`)
+)
+
+// MySQL Handle a MySQL or MariaDB connection, rewriting the SQLite flavoured SQL the models are written in as it goes
+type MySQL struct {
+	Database
+	Pool       Pool
+	db         *sql.DB
+	statements statements
+}
+
+// Close Close open database
+func (m *MySQL) Close() {
+	m.statements.close()
+	m.db.Close()
+}
+
+// Connect Connect to the database described by a data source name, such as user:pass@tcp(localhost:3306)/journal
+func (m *MySQL) Connect(dsn string) error {
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return err
+	}
+	// Count the rows an update matched rather than those it changed, as SQLite does
+	config.ClientFoundRows = true
+	if config.Collation == "" {
+		config.Collation = "utf8mb4_unicode_ci"
+	}
+	if m.db, err = sql.Open("mysql", config.FormatDSN()); err != nil {
+		return err
+	}
+	m.Pool.apply(m.db)
+
+	return m.db.Ping()
+}
+
+// Dialect Name the SQL dialect spoken
+func (m *MySQL) Dialect() string {
+	return DialectMySQL
+}
+
+// Exec Execute a query on the database, returning a simple result
+func (m *MySQL) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return m.ExecContext(context.Background(), query, args...)
+}
+
+// BeginContext Begin a transaction, stopped and rolled back once the context is cancelled
+func (m *MySQL) BeginContext(ctx context.Context) (*Tx, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{dialect: DialectMySQL, tx: tx, exec: m.exec, query: m.query}, nil
+}
+
+// ExecContext Execute a query on the database, stopping it once the context is cancelled
+func (m *MySQL) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return m.exec(ctx, nil, query, args...)
+}
+
+// Query Query the database
+func (m *MySQL) Query(query string, args ...interface{}) (rows.Rows, error) {
+	return m.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext Query the database, stopping once the context is cancelled
+func (m *MySQL) QueryContext(ctx context.Context, query string, args ...interface{}) (rows.Rows, error) {
+	return m.query(ctx, nil, query, args...)
+}
+
+// exec Execute a query, within the transaction when one is given
+func (m *MySQL) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return m.statements.exec(ctx, m.db, tx, mysqlQuery(query), args...)
+}
+
+// query Query the database, within the transaction when one is given
+func (m *MySQL) query(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (rows.Rows, error) {
+	return m.statements.query(ctx, m.db, tx, mysqlQuery(query), args...)
+}
+
+// mysqlQuery Rewrite a query written for SQLite: escaping backslashes in strings, creating tables with the column types
+// and options MySQL needs, and turning upserts into its ON DUPLICATE KEY and INSERT IGNORE forms
+func mysqlQuery(query string) string {
+	var b strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted && r == '\\':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	query = b.String()
+
+	trimmed := strings.ToUpper(strings.TrimSpace(query))
+	if strings.HasPrefix(trimmed, "CREATE TABLE") || strings.HasPrefix(trimmed, "ALTER TABLE") {
+		// Dates are kept as text, as in SQLite, so that entries keep the times given to them
+		query = reMySQLAutoincrement.ReplaceAllString(query, "INTEGER PRIMARY KEY AUTO_INCREMENT")
+		query = reMySQLDateTypes.ReplaceAllString(query, "VARCHAR(32)")
+		query = reMySQLTextDefault.ReplaceAllString(query, "TEXT NOT NULL DEFAULT ($1)")
+		if strings.HasPrefix(trimmed, "CREATE TABLE") {
+			query += " DEFAULT CHARSET=utf8mb4"
+		}
+	}
+
+	if loc := reMySQLUpsert.FindStringIndex(query); loc != nil {
+		query = query[:loc[0]] + " ON DUPLICATE KEY UPDATE " + reMySQLExcluded.ReplaceAllString(query[loc[1]:], "VALUES($1)")
+	}
+	if reMySQLIgnore.MatchString(query) {
+		query = reMySQLIgnore.ReplaceAllString(query, "")
+		query = strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	}
+
+	return query
+}
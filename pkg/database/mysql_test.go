@@ -0,0 +1,34 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestMySQLConnect(t *testing.T) {
+	mysql := &MySQL{}
+	if err := mysql.Connect("not a data source name"); err == nil {
+		t.Error("Expected an error when the data source name cannot be read")
+	}
+	if err := mysql.Connect("journal@tcp(127.0.0.1:1)/journal?timeout=1s"); err == nil {
+		t.Error("Expected an error when the database cannot be reached")
+	}
+}
+
+func TestMySQLQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT `id` FROM `journal` WHERE `slug` = ?", "SELECT `id` FROM `journal` WHERE `slug` = ?"},
+		{"SELECT `id` FROM `journal` WHERE `title` LIKE ? ESCAPE '\\'", "SELECT `id` FROM `journal` WHERE `title` LIKE ? ESCAPE '\\\\'"},
+		{"CREATE TABLE IF NOT EXISTS `job` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `last_error` TEXT NOT NULL DEFAULT '', `run_at` DATETIME NOT NULL)", "CREATE TABLE IF NOT EXISTS `job` (`id` INTEGER PRIMARY KEY AUTO_INCREMENT, `last_error` TEXT NOT NULL DEFAULT (''), `run_at` VARCHAR(32) NOT NULL) DEFAULT CHARSET=utf8mb4"},
+		{"ALTER TABLE `journal` ADD COLUMN `excerpt` TEXT NOT NULL DEFAULT ''", "ALTER TABLE `journal` ADD COLUMN `excerpt` TEXT NOT NULL DEFAULT ('')"},
+		{"INSERT INTO `follower` (`actor`, `inbox`) VALUES(?,?) ON CONFLICT (`actor`) DO UPDATE SET `inbox` = excluded.`inbox`", "INSERT INTO `follower` (`actor`, `inbox`) VALUES(?,?) ON DUPLICATE KEY UPDATE `inbox` = VALUES(`inbox`)"},
+		{"INSERT INTO `subscription_item` (`url`) VALUES(?) ON CONFLICT DO NOTHING", "INSERT IGNORE INTO `subscription_item` (`url`) VALUES(?)"},
+	}
+	for _, test := range tests {
+		if actual := mysqlQuery(test.query); actual != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, actual)
+		}
+	}
+}
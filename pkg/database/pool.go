@@ -0,0 +1,50 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// retryDelay Wait before the first retry of a statement refused as the database was locked, doubled for each after it
+const retryDelay = 50 * time.Millisecond
+
+// Pool How many connections are kept open to a database and for how long, and how patiently SQLite waits for another
+// connection to finish writing. Settings left at zero keep the driver's own behaviour.
+type Pool struct {
+	BusyTimeout     time.Duration
+	ConnMaxLifetime time.Duration
+	MaxIdleConns    int
+	MaxOpenConns    int
+	Retries         int
+}
+
+// apply Size the pool of connections to a database
+func (p Pool) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+}
+
+// retry Run a statement, trying it again after a growing delay while SQLite reports the database as locked, until the
+// retries run out
+func (p Pool) retry(run func() error) error {
+	err := run()
+	for attempt := 0; attempt < p.Retries && locked(err); attempt++ {
+		time.Sleep(retryDelay << uint(attempt))
+		err = run()
+	}
+
+	return err
+}
+
+// locked Whether SQLite refused a statement as another connection held the lock it needed
+func locked(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "database is locked") || strings.Contains(err.Error(), "database table is locked"))
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPoolRetry(t *testing.T) {
+	pool := Pool{Retries: 2}
+	calls := 0
+	err := pool.retry(func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("database is locked")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Expected locked statement to be tried again, got %d calls and %v", calls, err)
+	}
+
+	calls = 0
+	err = pool.retry(func() error {
+		calls++
+		return errors.New("database is locked")
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("Expected retries to run out, got %d calls", calls)
+	}
+
+	calls = 0
+	pool.retry(func() error {
+		calls++
+		return errors.New("no such table: journal")
+	})
+	if calls != 1 {
+		t.Error("Expected other errors not to be tried again")
+	}
+}
+
+func TestLocked(t *testing.T) {
+	if !locked(errors.New("database is locked")) || !locked(errors.New("database table is locked: journal")) {
+		t.Error("Expected lock errors to be recognised")
+	}
+	if locked(nil) || locked(errors.New("UNIQUE constraint failed")) {
+		t.Error("Expected other errors not to be recognised as locks")
+	}
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestPostgresConnect(t *testing.T) {
+	postgres := &Postgres{}
+	if err := postgres.Connect("postgres://journal@127.0.0.1:1/journal?sslmode=disable&connect_timeout=1"); err == nil {
+		t.Error("Expected an error when the database cannot be reached")
+	}
+}
+
+func TestPostgresQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT `id` FROM `journal` WHERE `slug` = ? AND `status` = ?", `SELECT "id" FROM "journal" WHERE "slug" = $1 AND "status" = $2`},
+		{"SELECT `id` FROM `journal` WHERE `title` = 'What?' AND `slug` = ?", `SELECT "id" FROM "journal" WHERE "title" = 'What?' AND "slug" = $1`},
+		{"SELECT `id` FROM `journal` WHERE `title` LIKE ? ESCAPE '\\'", `SELECT "id" FROM "journal" WHERE "title" ILIKE $1 ESCAPE '\'`},
+		{"CREATE TABLE IF NOT EXISTS `job` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `date` DATE NOT NULL, `run_at` DATETIME NOT NULL)", `CREATE TABLE IF NOT EXISTS "job" ("id" SERIAL PRIMARY KEY, "date" TEXT NOT NULL, "run_at" TEXT NOT NULL)`},
+		{"ALTER TABLE `journal` ADD COLUMN `published_at` DATETIME", `ALTER TABLE "journal" ADD COLUMN IF NOT EXISTS "published_at" TEXT`},
+		{"SELECT `date` FROM `journal`", `SELECT "date" FROM "journal"`},
+	}
+	for _, test := range tests {
+		if actual := postgresQuery(test.query); actual != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, actual)
+		}
+	}
+}
+
+func TestPostgresRewrite(t *testing.T) {
+	postgres := &Postgres{}
+	postgres.rewrite("CREATE TABLE IF NOT EXISTS `job` (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `type` VARCHAR(50) NOT NULL)")
+	postgres.rewrite("CREATE TABLE IF NOT EXISTS `journal_autosave` (`journal_id` INTEGER NOT NULL UNIQUE)")
+	if !postgres.numbered("job") {
+		t.Error("Expected a table numbering its rows to be remembered")
+	}
+	if postgres.numbered("journal_autosave") {
+		t.Error("Expected a table without an ID not to be numbered")
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import "strings"
+
+// JournalModes Ways SQLite can journal its writes, of which WAL lets readers carry on while a write is made
+var JournalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+
+// SynchronousModes How often SQLite waits for writes to reach the disk, of which NORMAL is safe with WAL
+var SynchronousModes = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+
+// Pragmas How SQLite journals and syncs its writes, whether it enforces foreign keys and whether it refuses writes
+// altogether, set on every connection opened. Settings left empty keep SQLite's own.
+type Pragmas struct {
+	ForeignKeys bool
+	JournalMode string
+	QueryOnly   bool
+	Synchronous string
+}
+
+// params Add the pragmas as the parameters go-sqlite3 reads from a file name, leaving any it already has
+func (p Pragmas) params(params []string, dbFile string) []string {
+	add := func(name string, value string) {
+		if value != "" && !strings.Contains(dbFile, name+"=") {
+			params = append(params, name+"="+value)
+		}
+	}
+	if p.ForeignKeys {
+		add("_foreign_keys", "1")
+	}
+	add("_journal_mode", p.JournalMode)
+	if p.QueryOnly {
+		add("_query_only", "1")
+	}
+	add("_synchronous", p.Synchronous)
+
+	return params
+}
+
+// IsMode Whether a value is one of the modes given, ignoring case
+func IsMode(value string, modes []string) bool {
+	for _, mode := range modes {
+		if strings.EqualFold(value, mode) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+// Replicated A database reading rows from a read-only replica, such as one kept by Litestream or a PostgreSQL or MySQL
+// replica, while every change is made to the database it replicates
+type Replicated struct {
+	Database
+	Replica Database
+}
+
+// WithReplica Read rows from a replica of a database when one is given, leaving the database as it is otherwise
+func WithReplica(db Database, replica Database) Database {
+	if replica == nil {
+		return db
+	}
+
+	return &Replicated{Database: db, Replica: replica}
+}
+
+// Dialect Name the SQL dialect spoken by the database replicated
+func (r *Replicated) Dialect() string {
+	return DialectOf(r.Database)
+}
+
+// Query Read rows from the replica, sending any other query to the database replicated
+func (r *Replicated) Query(query string, args ...interface{}) (rows.Rows, error) {
+	if !readsOnly(query) {
+		return r.Database.Query(query, args...)
+	}
+
+	return r.Replica.Query(query, args...)
+}
+
+// readsOnly Whether a query only reads rows, which a replica can answer
+func readsOnly(query string) bool {
+	verb := strings.ToUpper(strings.SplitN(strings.TrimSpace(query), " ", 2)[0])
+
+	return verb == "SELECT"
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWithReplica(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "replica")
+	defer os.RemoveAll(dir)
+	primary := &Sqlite{}
+	if err := primary.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer primary.Close()
+	primary.Exec("CREATE TABLE `journal` (`title` TEXT)")
+	primary.Exec("INSERT INTO `journal` VALUES('Primary')")
+	setup := &Sqlite{}
+	setup.Connect(dir + "/replica.db")
+	setup.Exec("CREATE TABLE `journal` (`title` TEXT)")
+	setup.Exec("INSERT INTO `journal` VALUES('Replica')")
+	setup.Close()
+	replica := &Sqlite{Pragmas: Pragmas{QueryOnly: true}}
+	if err := replica.Connect(dir + "/replica.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer replica.Close()
+
+	if WithReplica(primary, nil) != primary {
+		t.Error("Expected database to be left as it is without a replica")
+	}
+	db := WithReplica(primary, replica)
+	title := func() string {
+		rows, err := db.Query("SELECT `title` FROM `journal`")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer rows.Close()
+		var title string
+		if rows.Next() {
+			rows.Scan(&title)
+		}
+		return title
+	}
+	if title() != "Replica" {
+		t.Error("Expected rows to be read from the replica")
+	}
+	if _, err := db.Exec("UPDATE `journal` SET `title` = 'Changed'"); err != nil {
+		t.Fatalf("Expected changes to be made to the primary, got %s", err)
+	}
+	if title() != "Replica" {
+		t.Error("Expected replica to be left unchanged")
+	}
+	primary.Exec("CREATE TABLE `primary_only` (`id` INTEGER)")
+	rows, err := db.Query("PRAGMA table_info(`primary_only`)")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !rows.Next() {
+		t.Error("Expected queries that do not only read to be sent to the primary")
+	}
+	rows.Close()
+
+	if _, err := replica.Exec("DELETE FROM `journal`"); err == nil {
+		t.Error("Expected query only replica to refuse changes")
+	}
+	if DialectOf(WithReplica(&Postgres{}, &Postgres{})) != DialectPostgres {
+		t.Error("Expected replicated database to speak the dialect of the database it replicates")
+	}
+}
+
+func TestReadsOnly(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"SELECT * FROM `journal`":                  true,
+		"  select `id` FROM `journal`":             true,
+		"INSERT INTO `journal` (`slug`) VALUES(?)": false,
+		"WITH `c` AS (SELECT 1) DELETE FROM `a`":   false,
+		"PRAGMA integrity_check":                   false,
+	} {
+		if readsOnly(query) != expected {
+			t.Errorf("Expected %s to only read: %t", query, expected)
+		}
+	}
+}
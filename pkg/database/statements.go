@@ -0,0 +1,169 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+// statementLimit Most statements kept prepared for a database, beyond which the least recently used is closed
+const statementLimit = 256
+
+// statements Queries prepared the first time they are run and reused every time after, so the database parses each
+// only once. Only single statements reading or changing rows are kept, as changes to the schema run once and scripts
+// of several statements cannot be prepared as one. Values are bound rather than written into queries, so that each
+// keeps the same text, and once the limit is reached the statement used least recently makes way for the next.
+type statements struct {
+	mu       sync.Mutex
+	prepared map[string]*statement
+	recent   *list.List
+}
+
+// statement A statement kept prepared, with how many queries are running through it, so that one making way for
+// another is only closed once they are done
+type statement struct {
+	stmt    *sql.Stmt
+	query   string
+	users   int
+	evicted bool
+	element *list.Element
+}
+
+// get Get the prepared statement for a query, preparing it on first use, or nothing when it is not to be kept. Each
+// statement given must be released once it has been run.
+func (s *statements) get(ctx context.Context, db *sql.DB, query string) (*statement, error) {
+	if !reusable(query) {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.prepared[query]; ok {
+		s.recent.MoveToFront(st.element)
+		st.users++
+		return st, nil
+	}
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.prepared == nil {
+		s.prepared = map[string]*statement{}
+		s.recent = list.New()
+	}
+	if len(s.prepared) >= statementLimit {
+		s.evict(s.recent.Back().Value.(*statement))
+	}
+	st := &statement{stmt: stmt, query: query, users: 1}
+	st.element = s.recent.PushFront(st)
+	s.prepared[query] = st
+
+	return st, nil
+}
+
+// release Let go of a statement once it has been run, closing it if it has made way for another in the meantime
+func (s *statements) release(st *statement) {
+	if st == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.users--
+	if st.evicted && st.users == 0 {
+		st.stmt.Close()
+	}
+}
+
+// evict Stop keeping a statement, closing it unless a query is still running through it
+func (s *statements) evict(st *statement) {
+	s.recent.Remove(st.element)
+	delete(s.prepared, st.query)
+	st.evicted = true
+	if st.users == 0 {
+		st.stmt.Close()
+	}
+}
+
+// exec Execute a query through its prepared statement when it is kept, within the transaction when one is given
+func (s *statements) exec(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	st, err := s.get(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil && tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	if st == nil {
+		return db.ExecContext(ctx, query, args...)
+	}
+	defer s.release(st)
+
+	return within(ctx, tx, st.stmt).ExecContext(ctx, args...)
+}
+
+// query Run a query through its prepared statement when it is kept, within the transaction when one is given
+func (s *statements) query(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	st, err := s.get(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil && tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	if st == nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	defer s.release(st)
+
+	return within(ctx, tx, st.stmt).QueryContext(ctx, args...)
+}
+
+// queryRow Run a query returning a single row through its prepared statement when it is kept, within the transaction
+// when one is given
+func (s *statements) queryRow(ctx context.Context, db *sql.DB, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	if st, err := s.get(ctx, db, query); err == nil && st != nil {
+		defer s.release(st)
+		return within(ctx, tx, st.stmt).QueryRowContext(ctx, args...)
+	}
+	if tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+
+	return db.QueryRowContext(ctx, query, args...)
+}
+
+// close Close every statement kept, before the database they were prepared for is closed
+func (s *statements) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.prepared {
+		st.stmt.Close()
+	}
+	s.prepared = nil
+	s.recent = nil
+}
+
+// within Use a prepared statement within a transaction, which closes it when it ends, or as it is without one
+func within(ctx context.Context, tx *sql.Tx, stmt *sql.Stmt) *sql.Stmt {
+	if tx == nil {
+		return stmt
+	}
+
+	return tx.StmtContext(ctx, stmt)
+}
+
+// reusable Whether a query is a single statement reading or changing rows
+func reusable(query string) bool {
+	trimmed := strings.TrimSpace(query)
+	if strings.Contains(strings.TrimSuffix(trimmed, ";"), ";") {
+		return false
+	}
+	verb := strings.ToUpper(strings.SplitN(trimmed, " ", 2)[0])
+	switch verb {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "REPLACE", "WITH":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestReusable(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"SELECT * FROM `journal` WHERE `id` = ?":     true,
+		"  insert INTO `journal` (`slug`) VALUES(?)": true,
+		"UPDATE `journal` SET `title` = ?;":          true,
+		"CREATE TABLE `journal` (`id` INTEGER)":      false,
+		"VACUUM INTO 'backup.db'":                    false,
+		"DELETE FROM `a`; DELETE FROM `b`":           false,
+	} {
+		if reusable(query) != expected {
+			t.Errorf("Expected %s to be reusable: %t", query, expected)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "statements")
+	defer os.RemoveAll(dir)
+	sqlite := &Sqlite{}
+	if err := sqlite.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer sqlite.Close()
+
+	sqlite.Exec("CREATE TABLE `journal` (`id` INTEGER PRIMARY KEY, `title` TEXT)")
+	if len(sqlite.statements.prepared) != 0 {
+		t.Error("Expected changes to the schema not to be kept")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := sqlite.Exec("INSERT INTO `journal` (`title`) VALUES(?)", "Title "+strconv.Itoa(i)); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	rows, err := sqlite.Query("SELECT COUNT(*) FROM `journal` WHERE `id` > ?", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var total int
+	rows.Next()
+	rows.Scan(&total)
+	rows.Close()
+	if total != 2 || len(sqlite.statements.prepared) != 2 {
+		t.Errorf("Expected each query to be prepared once and reused, got %d for %d statements", total, len(sqlite.statements.prepared))
+	}
+
+	if _, err := sqlite.Query("SELECT `missing` FROM `journal`"); err == nil || len(sqlite.statements.prepared) != 2 {
+		t.Error("Expected query that cannot be prepared to return its error without being kept")
+	}
+
+	for i := 0; i < statementLimit; i++ {
+		sqlite.Exec("UPDATE `journal` SET `title` = ? WHERE `id` = " + strconv.Itoa(i))
+	}
+	if len(sqlite.statements.prepared) != statementLimit {
+		t.Errorf("Expected no more than %d statements to be kept, got %d", statementLimit, len(sqlite.statements.prepared))
+	}
+
+	// Test statements beyond the limit are kept in place of those used least recently
+	sqlite.Query("SELECT COUNT(*) FROM `journal` WHERE `id` > ?", 1)
+	if _, err := sqlite.Exec("UPDATE `journal` SET `title` = ? WHERE `id` = ?", "Beyond", 1); err != nil {
+		t.Errorf("Expected queries beyond the limit to still run, got %s", err)
+	}
+	if _, ok := sqlite.statements.prepared["UPDATE `journal` SET `title` = ? WHERE `id` = ?"]; !ok || len(sqlite.statements.prepared) != statementLimit {
+		t.Error("Expected query beyond the limit to be kept")
+	}
+	if _, ok := sqlite.statements.prepared["SELECT COUNT(*) FROM `journal` WHERE `id` > ?"]; !ok {
+		t.Error("Expected recently used query to be kept")
+	}
+	if _, ok := sqlite.statements.prepared["UPDATE `journal` SET `title` = ? WHERE `id` = 0"]; ok {
+		t.Error("Expected query used least recently to make way")
+	}
+
+	// Test statements making way while in use are only closed once released
+	st, _ := sqlite.statements.get(context.Background(), sqlite.db, "SELECT `title` FROM `journal` WHERE `id` = ?")
+	for i := 0; i < statementLimit; i++ {
+		sqlite.Exec("UPDATE `journal` SET `title` = ? WHERE `id` > " + strconv.Itoa(i))
+	}
+	if !st.evicted {
+		t.Fatal("Expected statement to make way")
+	}
+	var title string
+	if err := st.stmt.QueryRow(1).Scan(&title); err != nil {
+		t.Errorf("Expected statement in use not to be closed, got %s", err)
+	}
+	sqlite.statements.release(st)
+	if err := st.stmt.QueryRow(1).Scan(&title); err == nil {
+		t.Error("Expected statement to be closed once released")
+	}
+
+	sqlite.statements.close()
+	if len(sqlite.statements.prepared) != 0 {
+		t.Error("Expected statements to be closed")
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jamiefdhurst/journal/pkg/database/rows"
+)
+
+// ErrTransactionConnect A transaction runs on the connection of the database beginning it, and cannot connect itself
+var ErrTransactionConnect = errors.New("A transaction cannot be connected")
+
+// Transactor A database able to make several statements together in a transaction
+type Transactor interface {
+	BeginContext(ctx context.Context) (*Tx, error)
+}
+
+// Tx A transaction, whose statements are rewritten and prepared as those of the database beginning it, and are only
+// kept once it is committed
+type Tx struct {
+	dialect string
+	tx      *sql.Tx
+	exec    func(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error)
+	query   func(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (rows.Rows, error)
+}
+
+// Close Leave the database open, as it is closed by whatever opened it
+func (t *Tx) Close() {}
+
+// Connect Refuse to connect, as the transaction uses the connection of the database beginning it
+func (t *Tx) Connect(dbFile string) error {
+	return ErrTransactionConnect
+}
+
+// Dialect Name the SQL dialect spoken by the database beginning the transaction
+func (t *Tx) Dialect() string {
+	return t.dialect
+}
+
+// Exec Execute a query within the transaction
+func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext Execute a query within the transaction, stopping it once the context is cancelled
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.exec(ctx, t.tx, query, args...)
+}
+
+// Query Query the database within the transaction
+func (t *Tx) Query(query string, args ...interface{}) (rows.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext Query the database within the transaction, stopping once the context is cancelled
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (rows.Rows, error) {
+	return t.query(ctx, t.tx, query, args...)
+}
+
+// Commit Keep every change made in the transaction
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback Undo every change made in the transaction
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Transaction Run fn with a database making its statements in a transaction, committed when fn returns nothing and
+// rolled back when it returns an error or panics. Statements of a database bound to a context stay bound to it, and
+// those of a replicated database are all made to the database replicated. Databases without transactions, or already
+// in one, are handed to fn as they are.
+func Transaction(db Database, fn func(Database) error) (err error) {
+	ctx := context.Background()
+	bind := func(tx Database) Database {
+		return tx
+	}
+	target := db
+	if replicated, ok := target.(*Replicated); ok {
+		target = replicated.Database
+	}
+	if bound, ok := target.(*Bound); ok {
+		ctx, target = bound.Context, bound.Database
+		bind = func(tx Database) Database {
+			return WithContext(tx, bound.Context, bound.Timeout)
+		}
+	}
+	transactor, ok := target.(Transactor)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := transactor.BeginContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+			panic(recovered)
+		}
+	}()
+	if err = fn(bind(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTransaction(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "transaction")
+	defer os.RemoveAll(dir)
+	sqlite := &Sqlite{}
+	if err := sqlite.Connect(dir + "/journal.db"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer sqlite.Close()
+	sqlite.Exec("CREATE TABLE `journal` (`id` INTEGER PRIMARY KEY, `title` TEXT)")
+	count := func() int {
+		rows, _ := sqlite.Query("SELECT COUNT(*) FROM `journal`")
+		defer rows.Close()
+		total := 0
+		if rows.Next() {
+			rows.Scan(&total)
+		}
+		return total
+	}
+
+	// Changes are kept once committed, and seen within the transaction before
+	err := Transaction(sqlite, func(tx Database) error {
+		if DialectOf(tx) != DialectSqlite || tx.Connect("other.db") != ErrTransactionConnect {
+			t.Error("Expected transaction to speak the dialect of its database without connecting")
+		}
+		result, err := tx.Exec("INSERT INTO `journal` (`title`) VALUES(?)", "Kept")
+		if id, _ := result.LastInsertId(); err != nil || id != 1 {
+			t.Errorf("Expected row to be inserted, got %v", err)
+		}
+		rows, err := tx.Query("SELECT `title` FROM `journal` WHERE `id` = ?", 1)
+		if err != nil || !rows.Next() {
+			t.Error("Expected row to be seen within the transaction")
+		}
+		rows.Close()
+
+		// Running another transaction within it keeps to the same one
+		return Transaction(tx, func(nested Database) error {
+			if nested != tx {
+				t.Error("Expected transaction to be used as it is")
+			}
+			_, err := nested.Exec("INSERT INTO `journal` (`title`) VALUES(?)", "Also kept")
+			return err
+		})
+	})
+	if err != nil || count() != 2 {
+		t.Errorf("Expected changes to be committed, got %v and %d rows", err, count())
+	}
+
+	// Failures roll every change back
+	failure := errors.New("Simulated failure")
+	err = Transaction(sqlite, func(tx Database) error {
+		tx.Exec("INSERT INTO `journal` (`title`) VALUES(?)", "Lost")
+		tx.Exec("DELETE FROM `journal` WHERE `id` = ?", 1)
+		return failure
+	})
+	if err != failure || count() != 2 {
+		t.Errorf("Expected changes to be rolled back, got %v and %d rows", err, count())
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic to carry on once rolled back")
+			}
+		}()
+		Transaction(sqlite, func(tx Database) error {
+			tx.Exec("DELETE FROM `journal`")
+			panic("Simulated panic")
+		})
+	}()
+	if count() != 2 {
+		t.Errorf("Expected changes to be rolled back after a panic, got %d rows", count())
+	}
+
+	// Statements of a bound database stay bound within the transaction, and those of a replica go to the database
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := WithReplica(WithContext(sqlite, ctx, time.Second), &Sqlite{})
+	err = Transaction(bound, func(tx Database) error {
+		if _, err := tx.Exec("DELETE FROM `journal` WHERE `id` = ?", 2); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		cancel()
+		_, err := tx.Exec("DELETE FROM `journal`")
+		return err
+	})
+	if err != context.Canceled || count() != 2 {
+		t.Errorf("Expected cancelled transaction to be rolled back, got %v and %d rows", err, count())
+	}
+	if err := Transaction(WithContext(sqlite, ctx, 0), func(tx Database) error { return nil }); err == nil {
+		t.Error("Expected transaction not to begin once cancelled")
+	}
+}
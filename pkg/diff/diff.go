@@ -0,0 +1,84 @@
+package diff
+
+import "strings"
+
+// Kinds of line in a diff
+const (
+	Added     = "added"
+	Removed   = "removed"
+	Unchanged = "unchanged"
+)
+
+// Line A single line of a diff, marked as added, removed or unchanged
+type Line struct {
+	Kind string
+	Text string
+}
+
+// Lines Compare two texts line by line, returning the lines of both in order with what changed between them
+func Lines(before string, after string) []Line {
+	a := split(before)
+	b := split(after)
+
+	// Length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	lines := []Line{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Unchanged, a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, Line{Removed, a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Added, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Removed, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Added, b[j]})
+	}
+
+	return lines
+}
+
+// Changed Check whether any line in a diff was added or removed
+func Changed(lines []Line) bool {
+	for _, l := range lines {
+		if l.Kind != Unchanged {
+			return true
+		}
+	}
+
+	return false
+}
+
+func split(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return []string{}
+	}
+
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tables := []struct {
+		before   string
+		after    string
+		expected []Line
+	}{
+		{"", "", []Line{}},
+		{"one\ntwo", "one\ntwo", []Line{{Unchanged, "one"}, {Unchanged, "two"}}},
+		{"one\ntwo\nthree", "one\nthree", []Line{{Unchanged, "one"}, {Removed, "two"}, {Unchanged, "three"}}},
+		{"one\nthree", "one\ntwo\nthree", []Line{{Unchanged, "one"}, {Added, "two"}, {Unchanged, "three"}}},
+		{"one\r\ntwo", "one\nchanged", []Line{{Unchanged, "one"}, {Removed, "two"}, {Added, "changed"}}},
+		{"", "new", []Line{{Added, "new"}}},
+		{"old", "", []Line{{Removed, "old"}}},
+	}
+
+	for _, table := range tables {
+		actual := Lines(table.before, table.after)
+		if !reflect.DeepEqual(actual, table.expected) {
+			t.Errorf("Expected diff of %q and %q to be %v, got %v", table.before, table.after, table.expected, actual)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	if Changed(Lines("same", "same")) {
+		t.Error("Expected identical texts to be unchanged")
+	}
+	if !Changed(Lines("same", "different")) {
+		t.Error("Expected different texts to be changed")
+	}
+}
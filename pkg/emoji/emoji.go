@@ -0,0 +1,82 @@
+package emoji
+
+import (
+	"html"
+	"regexp"
+)
+
+var reShortcode = regexp.MustCompile(`:([a-z0-9_+\-]+):`)
+
+// reSkip Tags, along with preformatted text and code, where shortcodes are left as they were written
+var reSkip = regexp.MustCompile(`(?s)<pre.*?</pre>|<code.*?</code>|<[^>]*>`)
+
+// reName The names a shortcode may be given
+var reName = regexp.MustCompile(`^[a-z0-9_+\-]+$`)
+
+// Shortcodes The emoji each built-in shortcode stands for, named as on most chat and code hosting sites
+var Shortcodes = map[string]string{
+	"+1": "👍", "-1": "👎", "100": "💯", "airplane": "✈️", "alarm_clock": "⏰", "angry": "😠", "apple": "🍎",
+	"art": "🎨", "baby": "👶", "balloon": "🎈", "bear": "🐻", "bee": "🐝", "beer": "🍺", "bell": "🔔",
+	"bike": "🚲", "birthday": "🎂", "blush": "😊", "book": "📖", "books": "📚", "boom": "💥", "broken_heart": "💔",
+	"bug": "🐛", "bulb": "💡", "cake": "🍰", "calendar": "📅", "camera": "📷", "car": "🚗", "cat": "🐱",
+	"check": "✔️", "clap": "👏", "cloud": "☁️", "coffee": "☕", "computer": "💻", "confused": "😕", "cool": "🆒",
+	"crown": "👑", "cry": "😢", "dog": "🐶", "eyes": "👀", "fire": "🔥", "fish": "🐟", "flower": "🌸",
+	"gift": "🎁", "grin": "😁", "grinning": "😀", "hammer": "🔨", "heart": "❤️", "heart_eyes": "😍", "house": "🏠",
+	"hugs": "🤗", "hourglass": "⌛", "joy": "😂", "key": "🔑", "kiss": "😘", "laughing": "😆", "leaves": "🍃",
+	"lock": "🔒", "mag": "🔍", "memo": "📝", "moon": "🌙", "mountain": "⛰️", "muscle": "💪", "music": "🎵",
+	"neutral_face": "😐", "ok_hand": "👌", "open_mouth": "😮", "palm_tree": "🌴", "party": "🥳", "pencil": "✏️", "pizza": "🍕",
+	"point_right": "👉", "pray": "🙏", "question": "❓", "rage": "😡", "rainbow": "🌈", "raised_hands": "🙌", "rocket": "🚀",
+	"rose": "🌹", "runner": "🏃", "sad": "😞", "scream": "😱", "see_no_evil": "🙈", "seedling": "🌱", "shrug": "🤷",
+	"sleeping": "😴", "smile": "😄", "smiley": "😃", "smirk": "😏", "snowflake": "❄️", "snowman": "⛄", "sob": "😭",
+	"sparkles": "✨", "star": "⭐", "sun": "☀️", "sunglasses": "😎", "sweat_smile": "😅", "tada": "🎉", "thinking": "🤔",
+	"thumbsdown": "👎", "thumbsup": "👍", "tree": "🌳", "trophy": "🏆", "umbrella": "☂️", "warning": "⚠️", "wave": "👋",
+	"wink": "😉", "wine_glass": "🍷", "x": "❌", "yum": "😋", "zap": "⚡", "zzz": "💤",
+}
+
+// IsValidName Check a custom shortcode's name can be written between colons and does not replace a built-in one
+func IsValidName(name string) bool {
+	_, builtIn := Shortcodes[name]
+
+	return len(name) <= 50 && reName.MatchString(name) && !builtIn
+}
+
+// Unknown Get the names of shortcodes in rendered HTML that are not built in, which may have been added as custom ones
+func Unknown(rendered string) []string {
+	names := []string{}
+	for _, text := range reSkip.Split(rendered, -1) {
+		for _, match := range reShortcode.FindAllStringSubmatch(text, -1) {
+			if _, ok := Shortcodes[match[1]]; !ok {
+				names = append(names, match[1])
+			}
+		}
+	}
+
+	return names
+}
+
+// Replace Turn :shortcodes: in rendered HTML into the emoji they stand for, or the text given for any custom ones,
+// leaving tags, code and unknown shortcodes untouched
+func Replace(rendered string, custom map[string]string) string {
+	replace := func(text string) string {
+		return reShortcode.ReplaceAllStringFunc(text, func(match string) string {
+			name := match[1 : len(match)-1]
+			if value, ok := Shortcodes[name]; ok {
+				return value
+			}
+			if value, ok := custom[name]; ok {
+				return html.EscapeString(value)
+			}
+
+			return match
+		})
+	}
+
+	result := ""
+	last := 0
+	for _, skip := range reSkip.FindAllStringIndex(rendered, -1) {
+		result += replace(rendered[last:skip[0]]) + rendered[skip[0]:skip[1]]
+		last = skip[1]
+	}
+
+	return result + replace(rendered[last:])
+}
@@ -0,0 +1,57 @@
+package emoji
+
+import "testing"
+
+func TestReplace(t *testing.T) {
+	custom := map[string]string{"shipit": "🐿️", "co": "<Company>"}
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"", ""},
+		{"<p>Hello :smile: and :+1:</p>", "<p>Hello 😄 and 👍</p>"},
+		{"<p>:sweat_smile::tada:</p>", "<p>😅🎉</p>"},
+		{"<p>Ship it :shipit: for :co:</p>", "<p>Ship it 🐿️ for &lt;Company&gt;</p>"},
+		{"<p>At 12:30:45 or :unknown:</p>", "<p>At 12:30:45 or :unknown:</p>"},
+		{"<p>Use <code>:smile:</code></p>\n<pre><code>:tada:\n</code></pre>", "<p>Use <code>:smile:</code></p>\n<pre><code>:tada:\n</code></pre>"},
+		{"<a href=\"/a:smile:b\">:smile:</a>", "<a href=\"/a:smile:b\">😄</a>"},
+		{"<p>:SMILE:</p>", "<p>:SMILE:</p>"},
+	}
+
+	for _, table := range tables {
+		if actual := Replace(table.input, custom); actual != table.output {
+			t.Errorf("Expected Replace(%q) to be %q, got %q", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	names := Unknown("<p>:smile: :shipit: and <code>:other:</code> at 12:30:45</p>")
+	if len(names) != 2 || names[0] != "shipit" || names[1] != "30" {
+		t.Errorf("Expected shortcodes that are not built in, got %v", names)
+	}
+	if len(Unknown("<p>Just :tada:</p>")) != 0 {
+		t.Error("Expected built-in shortcodes to be known")
+	}
+}
+
+func TestIsValidName(t *testing.T) {
+	tables := []struct {
+		input  string
+		output bool
+	}{
+		{"shipit", true},
+		{"party_parrot", true},
+		{"+2", true},
+		{"", false},
+		{"Has Space", false},
+		{"colon:", false},
+		{"smile", false},
+	}
+
+	for _, table := range tables {
+		if actual := IsValidName(table.input); actual != table.output {
+			t.Errorf("Expected IsValidName(%q) to be %t, got %t", table.input, table.output, actual)
+		}
+	}
+}
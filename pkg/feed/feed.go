@@ -0,0 +1,245 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Format A way of rendering a feed, along with the content type it is served as
+type Format struct {
+	ContentType string
+	Render      func(Feed) ([]byte, error)
+}
+
+// Formats the feed can be rendered as
+var (
+	RSSFormat  = Format{ContentType: "application/rss+xml; charset=utf-8", Render: Feed.RSS}
+	AtomFormat = Format{ContentType: "application/atom+xml; charset=utf-8", Render: Feed.Atom}
+	JSONFormat = Format{ContentType: "application/feed+json; charset=utf-8", Render: Feed.JSON}
+)
+
+// Feed A list of entries to syndicate, rendered as RSS, Atom or JSON Feed
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	Self        string
+	Hub         string
+	Updated     time.Time
+	Entries     []Entry
+}
+
+// Entry A single item within a feed, where the link doubles as its permanent ID
+type Entry struct {
+	Title     string
+	Link      string
+	Published time.Time
+	Summary   string
+	Content   string
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	GUID        rssGUID    `xml:"guid"`
+	PubDate     string     `xml:"pubDate"`
+	Description string     `xml:"description"`
+	Content     rssContent `xml:"content:encoded"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Links         []rssLink `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+}
+
+type atom struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type jsonHub struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type jsonItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	Summary       string `json:"summary,omitempty"`
+	DatePublished string `json:"date_published"`
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Hubs        []jsonHub  `json:"hubs,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+// RSS Render the feed as an RSS 2.0 document, with the full content of each entry in content:encoded and described by
+// its title when it has no description
+func (f Feed) RSS() ([]byte, error) {
+	description := f.Description
+	if description == "" {
+		description = f.Title
+	}
+	doc := rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   description,
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+			Links:         []rssLink{{Href: f.Self, Rel: "self", Type: "application/rss+xml"}},
+		},
+	}
+	if f.Hub != "" {
+		doc.Channel.Links = append(doc.Channel.Links, rssLink{Href: f.Hub, Rel: "hub"})
+	}
+	for _, e := range f.Entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: e.Link},
+			PubDate:     e.Published.Format(time.RFC1123Z),
+			Description: e.Summary,
+			Content:     rssContent{Value: e.Content},
+		})
+	}
+
+	return render(doc)
+}
+
+// Atom Render the feed as an Atom 1.0 document, with the full content of each entry as HTML
+func (f Feed) Atom() ([]byte, error) {
+	doc := atom{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Links:    []atomLink{{Href: f.Link}, {Href: f.Self, Rel: "self", Type: "application/atom+xml"}},
+		ID:       f.Self,
+		Updated:  f.Updated.Format(time.RFC3339),
+	}
+	if f.Hub != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.Hub, Rel: "hub"})
+	}
+	for _, e := range f.Entries {
+		doc.Entries = append(doc.Entries, atomEntry{
+			Title:     e.Title,
+			Links:     []atomLink{{Href: e.Link, Rel: "alternate", Type: "text/html"}},
+			ID:        e.Link,
+			Published: e.Published.Format(time.RFC3339),
+			Updated:   e.Published.Format(time.RFC3339),
+			Summary:   atomText{Type: "text", Value: e.Summary},
+			Content:   atomText{Type: "html", Value: e.Content},
+		})
+	}
+
+	return render(doc)
+}
+
+// JSON Render the feed as a JSON Feed 1.1 document, with the full content of each entry as HTML
+func (f Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		Description: f.Description,
+		HomePageURL: f.Link,
+		FeedURL:     f.Self,
+		Items:       []jsonItem{},
+	}
+	if f.Hub != "" {
+		doc.Hubs = []jsonHub{{Type: "WebSub", URL: f.Hub}}
+	}
+	for _, e := range f.Entries {
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            e.Link,
+			URL:           e.Link,
+			Title:         e.Title,
+			ContentHTML:   e.Content,
+			Summary:       e.Summary,
+			DatePublished: e.Published.Format(time.RFC3339),
+		})
+	}
+
+	// Content is already HTML, so leave it readable rather than escaping every tag
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func render(doc interface{}) ([]byte, error) {
+	output, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
@@ -0,0 +1,148 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)
+	return Feed{
+		Title:   "Journal & Notes",
+		Link:    "https://example.com/",
+		Self:    "https://example.com/feed.atom",
+		Updated: published,
+		Entries: []Entry{
+			{Title: "First <entry>", Link: "https://example.com/first", Published: published, Summary: "Hello", Content: "<p>Hello ]]> world</p>"},
+		},
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	f := testFeed()
+	output, err := f.RSS()
+	if err != nil {
+		t.Fatalf("Expected RSS to render, got %s", err)
+	}
+	rendered := string(output)
+	expected := []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<rss version="2.0"`,
+		`<title>Journal &amp; Notes</title>`,
+		`<description>Journal &amp; Notes</description>`,
+		`<atom:link href="https://example.com/feed.atom" rel="self" type="application/rss+xml"></atom:link>`,
+		`<title>First &lt;entry&gt;</title>`,
+		`<guid isPermaLink="true">https://example.com/first</guid>`,
+		`<pubDate>Thu, 01 Feb 2018 00:00:00 +0000</pubDate>`,
+		`<content:encoded><![CDATA[<p>Hello ]]]]><![CDATA[> world</p>]]></content:encoded>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(rendered, e) {
+			t.Errorf("Expected RSS to contain %s, got:\n%s", e, rendered)
+		}
+	}
+	if strings.Contains(rendered, `rel="hub"`) {
+		t.Error("Expected no hub without one being set")
+	}
+	if err := xml.Unmarshal(output, &struct{}{}); err != nil {
+		t.Errorf("Expected well-formed XML, got %s", err)
+	}
+
+	f.Hub = "https://hub.example.com"
+	f.Description = "Thoughts & more"
+	output, _ = f.RSS()
+	if !strings.Contains(string(output), `<atom:link href="https://hub.example.com" rel="hub"></atom:link>`) {
+		t.Error("Expected hub link to be included")
+	}
+	if !strings.Contains(string(output), `<description>Thoughts &amp; more</description>`) {
+		t.Error("Expected the description to be included in place of the title")
+	}
+}
+
+func TestFeed_Atom(t *testing.T) {
+	f := testFeed()
+	output, err := f.Atom()
+	if err != nil {
+		t.Fatalf("Expected Atom to render, got %s", err)
+	}
+	rendered := string(output)
+	expected := []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<id>https://example.com/feed.atom</id>`,
+		`<link href="https://example.com/feed.atom" rel="self" type="application/atom+xml"></link>`,
+		`<updated>2018-02-01T00:00:00Z</updated>`,
+		`<id>https://example.com/first</id>`,
+		`<content type="html">&lt;p&gt;Hello ]]&gt; world&lt;/p&gt;</content>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(rendered, e) {
+			t.Errorf("Expected Atom to contain %s, got:\n%s", e, rendered)
+		}
+	}
+	if err := xml.Unmarshal(output, &struct{}{}); err != nil {
+		t.Errorf("Expected well-formed XML, got %s", err)
+	}
+	if strings.Contains(rendered, "<subtitle>") {
+		t.Error("Expected no subtitle without a description")
+	}
+
+	f.Hub = "https://hub.example.com"
+	f.Description = "Thoughts & more"
+	output, _ = f.Atom()
+	if !strings.Contains(string(output), `<link href="https://hub.example.com" rel="hub"></link>`) {
+		t.Error("Expected hub link to be included")
+	}
+	if !strings.Contains(string(output), `<subtitle>Thoughts &amp; more</subtitle>`) {
+		t.Error("Expected the description to be included as the subtitle")
+	}
+}
+
+func TestFeed_JSON(t *testing.T) {
+	f := testFeed()
+	output, err := f.JSON()
+	if err != nil {
+		t.Fatalf("Expected JSON Feed to render, got %s", err)
+	}
+	rendered := string(output)
+	expected := []string{
+		`"version": "https://jsonfeed.org/version/1.1"`,
+		`"title": "Journal & Notes"`,
+		`"home_page_url": "https://example.com/"`,
+		`"feed_url": "https://example.com/feed.atom"`,
+		`"id": "https://example.com/first"`,
+		`"content_html": "<p>Hello ]]> world</p>"`,
+		`"date_published": "2018-02-01T00:00:00Z"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(rendered, e) {
+			t.Errorf("Expected JSON Feed to contain %s, got:\n%s", e, rendered)
+		}
+	}
+	if strings.Contains(rendered, `"hubs"`) || strings.Contains(rendered, `"description"`) {
+		t.Error("Expected no hubs or description without them being set")
+	}
+	f.Description = "Thoughts & more"
+	if output, _ := f.JSON(); !strings.Contains(string(output), `"description": "Thoughts & more"`) {
+		t.Error("Expected the description to be included")
+	}
+
+	f.Hub = "https://hub.example.com"
+	f.Entries = nil
+	output, _ = f.JSON()
+	decoded := struct {
+		Hubs  []map[string]string
+		Items []interface{}
+	}{}
+	if err := json.Unmarshal(output, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %s", err)
+	}
+	if len(decoded.Hubs) != 1 || decoded.Hubs[0]["type"] != "WebSub" || decoded.Hubs[0]["url"] != "https://hub.example.com" {
+		t.Errorf("Expected WebSub hub to be included, got %v", decoded.Hubs)
+	}
+	if decoded.Items == nil {
+		t.Error("Expected an empty list of items rather than null")
+	}
+}
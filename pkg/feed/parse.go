@@ -0,0 +1,240 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned when a document is not an RSS, Atom or JSON feed
+var ErrUnknownFormat = errors.New("The document is not an RSS, Atom or JSON feed")
+
+// dateFormats Formats dates are written in by feeds found in the wild, tried in turn
+var dateFormats = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+type parsedRSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+type parsedRSSChannel struct {
+	Title string          `xml:"title"`
+	Link  string          `xml:"link"`
+	Items []parsedRSSItem `xml:"item"`
+}
+
+type parsedRSS struct {
+	Channel parsedRSSChannel `xml:"channel"`
+	// RSS 1.0 keeps its items alongside the channel rather than within it
+	Items []parsedRSSItem `xml:"item"`
+}
+
+type parsedAtomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+}
+
+type parsedAtom struct {
+	Title   string            `xml:"title"`
+	Links   []atomLink        `xml:"link"`
+	Entries []parsedAtomEntry `xml:"entry"`
+}
+
+type parsedJSONItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+type parsedJSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	Items       []parsedJSONItem `json:"items"`
+}
+
+// Parse Read an RSS, Atom or JSON feed, whichever the document turns out to be, skipping entries without a link
+func Parse(data []byte) (Feed, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSON(trimmed)
+	}
+
+	decoder := newDecoder(trimmed)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return Feed{}, ErrUnknownFormat
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss", "RDF":
+			return parseRSS(decoder, start)
+		case "feed":
+			return parseAtom(decoder, start)
+		default:
+			return Feed{}, ErrUnknownFormat
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	// Most feeds not written in UTF-8 use a Latin encoding close enough to be read as it is
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	return decoder
+}
+
+func parseRSS(decoder *xml.Decoder, start xml.StartElement) (Feed, error) {
+	doc := parsedRSS{}
+	if err := decoder.DecodeElement(&doc, &start); err != nil {
+		return Feed{}, err
+	}
+
+	f := Feed{Title: strings.TrimSpace(doc.Channel.Title), Link: strings.TrimSpace(doc.Channel.Link)}
+	for _, item := range append(doc.Channel.Items, doc.Items...) {
+		link := strings.TrimSpace(item.Link)
+		if link == "" && strings.HasPrefix(item.GUID, "http") {
+			link = strings.TrimSpace(item.GUID)
+		}
+		if link == "" {
+			continue
+		}
+		published := item.PubDate
+		if published == "" {
+			published = item.Date
+		}
+		f.Entries = append(f.Entries, Entry{
+			Title:     strings.TrimSpace(item.Title),
+			Link:      link,
+			Published: parseDate(published),
+			Summary:   strings.TrimSpace(item.Description),
+			Content:   item.Content,
+		})
+	}
+
+	return f, nil
+}
+
+func parseAtom(decoder *xml.Decoder, start xml.StartElement) (Feed, error) {
+	doc := parsedAtom{}
+	if err := decoder.DecodeElement(&doc, &start); err != nil {
+		return Feed{}, err
+	}
+
+	f := Feed{Title: strings.TrimSpace(doc.Title), Link: alternate(doc.Links)}
+	for _, entry := range doc.Entries {
+		link := alternate(entry.Links)
+		if link == "" {
+			continue
+		}
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		f.Entries = append(f.Entries, Entry{
+			Title:     strings.TrimSpace(entry.Title),
+			Link:      link,
+			Published: parseDate(published),
+			Summary:   strings.TrimSpace(entry.Summary),
+			Content:   entry.Content,
+		})
+	}
+
+	return f, nil
+}
+
+// alternate Find the link to the page itself among those of an Atom feed or entry
+func alternate(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+
+	return ""
+}
+
+func parseJSON(data []byte) (Feed, error) {
+	doc := parsedJSONFeed{}
+	if err := json.Unmarshal(data, &doc); err != nil || !strings.HasPrefix(doc.Version, "https://jsonfeed.org/") {
+		return Feed{}, ErrUnknownFormat
+	}
+
+	f := Feed{Title: strings.TrimSpace(doc.Title), Link: doc.HomePageURL}
+	for _, item := range doc.Items {
+		link := item.URL
+		if link == "" && strings.HasPrefix(item.ID, "http") {
+			link = item.ID
+		}
+		if link == "" {
+			continue
+		}
+		published := item.DatePublished
+		if published == "" {
+			published = item.DateModified
+		}
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+		f.Entries = append(f.Entries, Entry{
+			Title:     strings.TrimSpace(item.Title),
+			Link:      link,
+			Published: parseDate(published),
+			Summary:   strings.TrimSpace(item.Summary),
+			Content:   content,
+		})
+	}
+
+	return f, nil
+}
+
+// parseDate Read a date in any of the formats feeds use, leaving it unset when none of them match
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, format := range dateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_RSS(t *testing.T) {
+	f, err := Parse([]byte(`<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Alice's Blog</title>
+    <link>https://alice.example.com/</link>
+    <item>
+      <title>First &amp; foremost</title>
+      <link>https://alice.example.com/first</link>
+      <pubDate>Mon, 2 Jan 2006 15:04:05 -0700</pubDate>
+      <description>A summary</description>
+      <content:encoded><![CDATA[<p>All of it</p>]]></content:encoded>
+    </item>
+    <item>
+      <title>Only a GUID</title>
+      <guid>https://alice.example.com/second</guid>
+      <pubDate>Tue, 03 Jan 2006 10:00:00 GMT</pubDate>
+    </item>
+    <item>
+      <title>No link at all</title>
+    </item>
+  </channel>
+</rss>`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if f.Title != "Alice's Blog" || f.Link != "https://alice.example.com/" || len(f.Entries) != 2 {
+		t.Fatalf("Expected channel with two linked items, got %v", f)
+	}
+	if f.Entries[0].Title != "First & foremost" || f.Entries[0].Content != "<p>All of it</p>" || f.Entries[0].Summary != "A summary" || !f.Entries[0].Published.Equal(time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected first item to be read, got %v", f.Entries[0])
+	}
+	if f.Entries[1].Link != "https://alice.example.com/second" || f.Entries[1].Published.Day() != 3 {
+		t.Errorf("Expected GUID to be used as the link, got %v", f.Entries[1])
+	}
+}
+
+func TestParse_RDF(t *testing.T) {
+	f, err := Parse([]byte(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>Old School</title><link>https://old.example.com/</link></channel>
+  <item><title>Still here</title><link>https://old.example.com/1</link><dc:date>2006-01-02T15:04:05Z</dc:date></item>
+</rdf:RDF>`))
+	if err != nil || f.Title != "Old School" || len(f.Entries) != 1 || f.Entries[0].Published.Year() != 2006 {
+		t.Errorf("Expected RSS 1.0 items to be read, got %v, %v", f, err)
+	}
+}
+
+func TestParse_Atom(t *testing.T) {
+	f, err := Parse([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Bob</title>
+  <link href="https://bob.example.com/" />
+  <link rel="self" href="https://bob.example.com/atom.xml" />
+  <entry>
+    <title>Hello</title>
+    <link rel="alternate" type="text/html" href="https://bob.example.com/hello" />
+    <id>tag:bob.example.com,2006:1</id>
+    <updated>2006-01-02T15:04:05Z</updated>
+    <summary>Hi there</summary>
+  </entry>
+</feed>`))
+	if err != nil || f.Title != "Bob" || f.Link != "https://bob.example.com/" || len(f.Entries) != 1 {
+		t.Fatalf("Expected feed with one entry, got %v, %v", f, err)
+	}
+	if f.Entries[0].Link != "https://bob.example.com/hello" || f.Entries[0].Summary != "Hi there" || f.Entries[0].Published.Year() != 2006 {
+		t.Errorf("Expected entry to be read, got %v", f.Entries[0])
+	}
+
+	// Test what this package renders can be read back
+	rendered, _ := Feed{Title: "Mine", Link: "https://example.com/", Self: "https://example.com/feed.atom", Entries: []Entry{{Title: "A", Link: "https://example.com/a"}}}.Atom()
+	if f, err := Parse(rendered); err != nil || len(f.Entries) != 1 || f.Entries[0].Link != "https://example.com/a" {
+		t.Errorf("Expected rendered Atom feed to be read back, got %v, %v", f, err)
+	}
+}
+
+func TestParse_JSON(t *testing.T) {
+	f, err := Parse([]byte(`{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Carol",
+  "home_page_url": "https://carol.example.com/",
+  "items": [
+    {"id": "1", "url": "https://carol.example.com/1", "title": "One", "content_text": "Plain", "date_published": "2006-01-02T15:04:05Z"},
+    {"id": "https://carol.example.com/2", "content_html": "<p>Two</p>"},
+    {"id": "3", "title": "Nowhere"}
+  ]
+}`))
+	if err != nil || f.Title != "Carol" || len(f.Entries) != 2 {
+		t.Fatalf("Expected feed with two linked items, got %v, %v", f, err)
+	}
+	if f.Entries[0].Content != "Plain" || f.Entries[1].Link != "https://carol.example.com/2" || !f.Entries[1].Published.IsZero() {
+		t.Errorf("Expected items to be read, got %v", f.Entries)
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	for _, data := range []string{"", "<html><body>Not a feed</body></html>", `{"title": "Not a feed"}`, "plain text"} {
+		if _, err := Parse([]byte(data)); err != ErrUnknownFormat {
+			t.Errorf("Expected %q to be refused, got %v", data, err)
+		}
+	}
+}
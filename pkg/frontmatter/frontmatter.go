@@ -0,0 +1,202 @@
+package frontmatter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const delimiter = "---"
+
+// ErrUnterminated The front matter was opened but never closed
+var ErrUnterminated = errors.New("Front matter is not closed by a line of ---")
+
+// Document A file split into the fields of its front matter and the body that follows it. Each field holds either a
+// string or, for lists, a slice of strings.
+type Document struct {
+	Fields map[string]interface{}
+	Body   string
+}
+
+// String Get a field as a string, empty when it is missing or a list
+func (d Document) String(key string) string {
+	value, _ := d.Fields[key].(string)
+
+	return value
+}
+
+// List Get a field as a list, splitting a single string on commas
+func (d Document) List(key string) []string {
+	switch value := d.Fields[key].(type) {
+	case []string:
+		return value
+	case string:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+
+	return []string{}
+}
+
+// Parse Split a file into its YAML front matter and body. Only the subset of YAML used by static site generators is
+// understood: plain and quoted scalars, and lists written either [inline] or as "- item" lines. Anything nested
+// more deeply is ignored. A file without front matter is all body.
+func Parse(source string) (Document, error) {
+	doc := Document{Fields: map[string]interface{}{}, Body: source}
+	source = strings.TrimPrefix(strings.ReplaceAll(source, "\r\n", "\n"), "\ufeff")
+	lines := strings.Split(source, "\n")
+	if strings.TrimSpace(lines[0]) != delimiter {
+		return doc, nil
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if trimmed := strings.TrimSpace(lines[i]); trimmed == delimiter || trimmed == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return doc, ErrUnterminated
+	}
+
+	key := ""
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Items of a list belong to the last key left without a value
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if key != "" {
+				list, _ := doc.Fields[key].([]string)
+				doc.Fields[key] = append(list, scalar(strings.TrimPrefix(trimmed, "-")))
+			}
+			continue
+		}
+		if line != strings.TrimLeft(line, " \t") {
+			continue
+		}
+
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			key = ""
+			continue
+		}
+		key = strings.TrimSpace(line[:colon])
+		value := strings.TrimSpace(line[colon+1:])
+		switch {
+		case value == "":
+			doc.Fields[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			doc.Fields[key] = inlineList(value[1 : len(value)-1])
+			key = ""
+		default:
+			doc.Fields[key] = scalar(value)
+			key = ""
+		}
+	}
+	doc.Body = strings.TrimLeft(strings.Join(lines[end+1:], "\n"), "\n")
+
+	return doc, nil
+}
+
+// inlineList Split the items of a [list], leaving commas inside quotes alone
+func inlineList(value string) []string {
+	list := []string{}
+	item := strings.Builder{}
+	var quote rune
+	for _, r := range value {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == ',':
+			if s := scalar(item.String()); s != "" {
+				list = append(list, s)
+			}
+			item.Reset()
+			continue
+		}
+		item.WriteRune(r)
+	}
+	if s := scalar(item.String()); s != "" {
+		list = append(list, s)
+	}
+
+	return list
+}
+
+// scalar Read a single value, removing its quotes or any comment trailing it
+func scalar(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		replacer := strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n", `\t`, "\t")
+		return replacer.Replace(value[1 : len(value)-1])
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+
+	return value
+}
+
+// Field A single field to write into front matter, holding a string, bool or list of strings
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// plain Strings that can be written without quotes and still be read back as the same string
+var plain = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_./ -]*$|^\d{4}-\d{2}-\d{2}( \d{2}:\d{2}(:\d{2})?)?$`)
+
+// notStrings Plain words YAML reads as something other than a string
+var notStrings = map[string]bool{"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true, "null": true, "y": true, "n": true}
+
+// Format Write fields as YAML front matter ahead of the body, in the order given
+func Format(fields []Field, body string) string {
+	out := strings.Builder{}
+	out.WriteString(delimiter + "\n")
+	for _, field := range fields {
+		out.WriteString(field.Key + ": ")
+		switch value := field.Value.(type) {
+		case bool:
+			out.WriteString(strconv.FormatBool(value))
+		case []string:
+			quoted := []string{}
+			for _, item := range value {
+				quoted = append(quoted, quote(item))
+			}
+			out.WriteString("[" + strings.Join(quoted, ", ") + "]")
+		default:
+			out.WriteString(quote(fmt.Sprint(value)))
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString(delimiter + "\n\n")
+	out.WriteString(strings.TrimSpace(body) + "\n")
+
+	return out.String()
+}
+
+// quote Write a string in double quotes, unless it is plain enough to be read back without them
+func quote(value string) string {
+	if plain.MatchString(value) && strings.TrimSpace(value) == value && !notStrings[strings.ToLower(value)] {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,91 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	source := "---\r\n" +
+		"title: \"Hello: \\\"World\\\"\"\r\n" +
+		"date: 2020-01-02 10:00:00 # written on the train\r\n" +
+		"author: 'Jamie''s notes'\r\n" +
+		"tags: [travel, \"food, drink\"]\r\n" +
+		"categories:\r\n" +
+		"  - Holidays\r\n" +
+		"  - 'Europe'\r\n" +
+		"seo:\r\n" +
+		"  title: Ignored\r\n" +
+		"# A comment\r\n" +
+		"draft: true\r\n" +
+		"---\r\n" +
+		"\r\n" +
+		"Some *content*\r\n"
+
+	doc, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Expected front matter to be parsed, got %s", err)
+	}
+	if doc.String("title") != `Hello: "World"` || doc.String("date") != "2020-01-02 10:00:00" || doc.String("author") != "Jamie's notes" || doc.String("draft") != "true" {
+		t.Errorf("Expected scalars to be read, got %v", doc.Fields)
+	}
+	if !reflect.DeepEqual(doc.List("tags"), []string{"travel", "food, drink"}) || !reflect.DeepEqual(doc.List("categories"), []string{"Holidays", "Europe"}) {
+		t.Errorf("Expected lists to be read, got %v and %v", doc.List("tags"), doc.List("categories"))
+	}
+	if len(doc.List("seo")) != 0 || doc.String("missing") != "" {
+		t.Error("Expected nested and missing fields to be empty")
+	}
+	if doc.Body != "Some *content*\n" {
+		t.Errorf("Expected body to follow the front matter, got %q", doc.Body)
+	}
+}
+
+func TestParse_NoFrontMatter(t *testing.T) {
+	doc, err := Parse("# Heading\n\nText")
+	if err != nil || len(doc.Fields) != 0 || doc.Body != "# Heading\n\nText" {
+		t.Errorf("Expected whole file to be the body, got %v", doc)
+	}
+
+	if _, err := Parse("---\ntitle: Open\n\nText"); err != ErrUnterminated {
+		t.Errorf("Expected unterminated front matter to be refused, got %v", err)
+	}
+}
+
+func TestDocument_List(t *testing.T) {
+	doc := Document{Fields: map[string]interface{}{"tags": "one, two,,three"}}
+	if !reflect.DeepEqual(doc.List("tags"), []string{"one", "two", "three"}) {
+		t.Errorf("Expected single string to be split on commas, got %v", doc.List("tags"))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	fields := []Field{
+		{Key: "title", Value: "Trip to Rome"},
+		{Key: "date", Value: "2019-05-04"},
+		{Key: "subtitle", Value: `Rome: "the eternal city"`},
+		{Key: "answer", Value: "yes"},
+		{Key: "year", Value: "2019"},
+		{Key: "tags", Value: []string{"Travel", "Food & Drink"}},
+		{Key: "draft", Value: true},
+	}
+	expected := "---\n" +
+		"title: Trip to Rome\n" +
+		"date: 2019-05-04\n" +
+		"subtitle: \"Rome: \\\"the eternal city\\\"\"\n" +
+		"answer: \"yes\"\n" +
+		"year: \"2019\"\n" +
+		"tags: [Travel, \"Food & Drink\"]\n" +
+		"draft: true\n" +
+		"---\n\n" +
+		"Body\n"
+	output := Format(fields, "\nBody\n\n")
+	if output != expected {
+		t.Errorf("Expected front matter to be written as:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// What is written can be read back
+	doc, err := Parse(output)
+	if err != nil || doc.String("subtitle") != `Rome: "the eternal city"` || doc.String("answer") != "yes" || !reflect.DeepEqual(doc.List("tags"), []string{"Travel", "Food & Drink"}) || doc.Body != "Body\n" {
+		t.Errorf("Expected front matter to be read back, got %v", doc)
+	}
+}
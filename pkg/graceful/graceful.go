@@ -0,0 +1,279 @@
+package graceful
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jamiefdhurst/journal/pkg/logging"
+)
+
+// ListenersEnv Names the sockets handed to a process by the one it replaces, as name=descriptor pairs
+const ListenersEnv = "J_LISTENERS"
+
+// ReadyEnv Names the descriptor a process writes to once ready to take over from the one it replaces
+const ReadyEnv = "J_READY_FD"
+
+// ErrNotReady The new process stopped, or took too long, before it was ready to take over
+var ErrNotReady = errors.New("The new process was not ready to take over")
+
+// executable, arguments and workingDir are what a restart runs, and where, found as the journal starts. The executable
+// is found by its path rather than the running process so that a binary replaced on disk is the one started.
+var (
+	workingDir, _ = os.Getwd()
+	executable    = find(os.Args[0])
+	arguments     = os.Args[1:]
+	readyOnce     sync.Once
+)
+
+// find Get the absolute path of an executable as it was started
+func find(name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return name
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+
+	return path
+}
+
+// Listeners Sockets opened by name, so that they can be handed to the process replacing this one on a restart. Unnamed
+// names the socket given any socket passed by systemd without a name of its own.
+type Listeners struct {
+	Unnamed   string
+	mu        sync.Mutex
+	names     []string
+	listeners map[string]net.Listener
+	taken     map[int]bool
+}
+
+// Inherited Check whether this process took over its sockets from the one it replaced
+func Inherited() bool {
+	return os.Getenv(ListenersEnv) != ""
+}
+
+// Listen Get a socket listening on a TCP address, taking it over from the process this one replaced when that handed
+// one over by the same name, or from systemd when it passed one
+func (l *Listeners) Listen(name string, addr string) (net.Listener, error) {
+	return l.listen(name, func() (net.Listener, error) {
+		return net.Listen("tcp", addr)
+	})
+}
+
+// ListenUnix Get a socket listening on a unix domain socket at a path, given the permissions of mode, taking it over
+// from the process this one replaced or from systemd as Listen does. A socket left at the path by a process that
+// stopped is replaced, one still being listened on is not. The socket is left at the path once closed, so that closing
+// it after handing it over on a restart does not take it from the new process.
+func (l *Listeners) ListenUnix(name string, path string, mode os.FileMode) (net.Listener, error) {
+	return l.listen(name, func() (net.Listener, error) {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if conn, err := net.Dial("unix", path); err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("The socket %s is already being listened on", path)
+			}
+			os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		listener.(*net.UnixListener).SetUnlinkOnClose(false)
+		if err = os.Chmod(path, mode); err != nil {
+			listener.Close()
+			return nil, err
+		}
+
+		return listener, nil
+	})
+}
+
+// listen Keep the socket taken over by name, or opened otherwise when there is none to take over
+func (l *Listeners) listen(name string, open func() (net.Listener, error)) (net.Listener, error) {
+	listener, err := inherit(name)
+	if err == nil && listener == nil {
+		listener, err = l.activated(name)
+	}
+	if err == nil && listener == nil {
+		listener, err = open()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.listeners == nil {
+		l.listeners = map[string]net.Listener{}
+	}
+	if _, ok := l.listeners[name]; !ok {
+		l.names = append(l.names, name)
+	}
+	l.listeners[name] = listener
+
+	return listener, nil
+}
+
+// inherit Take over the socket handed over by name, giving nil when there is none
+func inherit(name string) (net.Listener, error) {
+	for _, pair := range strings.Split(os.Getenv(ListenersEnv), ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] != name {
+			continue
+		}
+		fd, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid descriptor for %s", ListenersEnv, name)
+		}
+		file := os.NewFile(uintptr(fd), name)
+		defer file.Close()
+
+		return net.FileListener(file)
+	}
+
+	return nil, nil
+}
+
+// Ready Tell the process this one replaced that it can stop, doing nothing when it was not started by a restart
+func Ready() {
+	readyOnce.Do(func() {
+		fd, err := strconv.Atoi(os.Getenv(ReadyEnv))
+		if err != nil {
+			return
+		}
+		file := os.NewFile(uintptr(fd), "ready")
+		file.Write([]byte{1})
+		file.Close()
+	})
+}
+
+// Restart Start a new copy of the journal with the same arguments, handing it every socket, and wait until it is ready
+// to take over or the timeout passes. Requests keep being served here in the meantime.
+func (l *Listeners) Restart(timeout time.Duration) error {
+	l.mu.Lock()
+	files := []*os.File{}
+	pairs := []string{}
+	for i, name := range l.names {
+		listener, ok := l.listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			l.mu.Unlock()
+			closeAll(files)
+			return fmt.Errorf("The %s socket cannot be handed over", name)
+		}
+		file, err := listener.File()
+		if err != nil {
+			l.mu.Unlock()
+			closeAll(files)
+			return err
+		}
+		files = append(files, file)
+		pairs = append(pairs, name+"="+strconv.Itoa(3+i))
+	}
+	l.mu.Unlock()
+	defer closeAll(files)
+
+	ready, readyWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+
+	cmd := exec.Command(executable, arguments...)
+	cmd.Dir = workingDir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, readyWriter)
+	cmd.Env = append(environ(), ListenersEnv+"="+strings.Join(pairs, ","), ReadyEnv+"="+strconv.Itoa(3+len(files)))
+	err = cmd.Start()
+	readyWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	// The pipe closes without a byte when the new process stops before it is ready
+	result := make(chan bool, 1)
+	go func() {
+		buffer := make([]byte, 1)
+		n, _ := ready.Read(buffer)
+		result <- n == 1
+	}()
+	select {
+	case ok := <-result:
+		if ok {
+			return cmd.Process.Release()
+		}
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+	}
+	go cmd.Wait()
+
+	return ErrNotReady
+}
+
+// Signals Start receiving the signals asking the journal to stop, or to restart where that is supported
+func Signals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{os.Interrupt, syscall.SIGTERM}, restartSignals...)...)
+
+	return signals
+}
+
+// Wait Block until a signal asks the journal to stop, or to restart and a new process is ready to take over, or
+// serving fails, giving back the failure. A restart that fails is logged and the journal keeps serving.
+func (l *Listeners) Wait(signals <-chan os.Signal, failed <-chan error, timeout time.Duration) error {
+	for {
+		select {
+		case err := <-failed:
+			return err
+		case received := <-signals:
+			if !isRestart(received) {
+				return nil
+			}
+			err := l.Restart(timeout)
+			if err == nil {
+				return nil
+			}
+			logging.Warn("Could not restart, carrying on serving", "err", err)
+		}
+	}
+}
+
+// isRestart Check whether a signal asks for a restart rather than a stop
+func isRestart(received os.Signal) bool {
+	for _, restart := range restartSignals {
+		if received == restart {
+			return true
+		}
+	}
+
+	return false
+}
+
+// environ Get the environment without anything handed over when this process was itself started by a restart
+func environ() []string {
+	env := []string{}
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, ListenersEnv+"=") && !strings.HasPrefix(variable, ReadyEnv+"=") && !isSystemd(variable) {
+			env = append(env, variable)
+		}
+	}
+
+	return env
+}
+
+func closeAll(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,178 @@
+package graceful
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestListen(t *testing.T) {
+	os.Unsetenv(ListenersEnv)
+	listeners := &Listeners{}
+	listener, err := listeners.Listen("http", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected a socket to be opened, got %s", err)
+	}
+	defer listener.Close()
+	if Inherited() {
+		t.Error("Expected the socket not to have been inherited")
+	}
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected the socket to accept connections, got %s", err)
+	}
+	conn.Close()
+
+	os.Setenv(ListenersEnv, "http=three")
+	defer os.Unsetenv(ListenersEnv)
+	if _, err := listeners.Listen("http", "127.0.0.1:0"); err == nil {
+		t.Error("Expected an invalid descriptor to fail")
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "graceful")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.sock")
+	listeners := &Listeners{}
+	listener, err := listeners.ListenUnix("http", path, 0660)
+	if err != nil {
+		t.Fatalf("Expected a socket to be opened, got %s", err)
+	}
+	if info, _ := os.Stat(path); info == nil || info.Mode().Perm() != 0660 {
+		t.Error("Expected the socket to be given its permissions")
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Expected the socket to accept connections, got %s", err)
+	}
+	conn.Close()
+
+	// Test a socket still listened on is left alone, and one left behind is replaced
+	if _, err := listeners.ListenUnix("http", path, 0660); err == nil {
+		t.Error("Expected a socket in use to be refused")
+	}
+	listener.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Error("Expected the socket to be left once closed")
+	}
+	listener, err = listeners.ListenUnix("http", path, 0600)
+	if err != nil {
+		t.Fatalf("Expected a socket left behind to be replaced, got %s", err)
+	}
+	listener.Close()
+}
+
+func TestListen_Systemd(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	file, _ := listener.(*net.TCPListener).File()
+	defer file.Close()
+
+	// Test sockets passed to another process are ignored
+	os.Setenv(systemdPIDEnv, strconv.Itoa(os.Getpid()+1))
+	os.Setenv(systemdFDsEnv, "1")
+	defer os.Unsetenv(systemdPIDEnv)
+	defer os.Unsetenv(systemdFDsEnv)
+	listeners := &Listeners{Unnamed: "http"}
+	other, err := listeners.Listen("http", "127.0.0.1:0")
+	if err != nil || other.Addr().String() == listener.Addr().String() {
+		t.Error("Expected a socket of its own to be opened")
+	}
+	other.Close()
+
+	for _, names := range []string{"", "journal.socket", "http"} {
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+		cmd.ExtraFiles = []*os.File{file}
+		cmd.Env = append(environ(), "J_HELPER_PROCESS=activated", "J_HELPER_ADDR="+listener.Addr().String(), systemdFDsEnv+"=1", systemdNamesEnv+"="+names)
+		if err := cmd.Run(); err != nil {
+			t.Errorf("Expected the socket passed as '%s' to be taken, got %s", names, err)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	listeners := &Listeners{}
+	signals := make(chan os.Signal, 1)
+	failed := make(chan error, 1)
+
+	failed <- errors.New("Simulated failure")
+	if err := listeners.Wait(signals, failed, time.Second); err == nil || err.Error() != "Simulated failure" {
+		t.Errorf("Expected the serving failure to be returned, got %v", err)
+	}
+
+	signals <- syscall.SIGTERM
+	if err := listeners.Wait(signals, failed, time.Second); err != nil {
+		t.Errorf("Expected a clean stop, got %s", err)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	listeners := &Listeners{}
+	listener, err := listeners.Listen("http", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	defer func(original []string) { arguments = original }(arguments)
+	arguments = []string{"-test.run=TestHelperProcess"}
+	os.Setenv("J_HELPER_ADDR", listener.Addr().String())
+	defer os.Unsetenv("J_HELPER_ADDR")
+	defer os.Unsetenv("J_HELPER_PROCESS")
+
+	os.Setenv("J_HELPER_PROCESS", "ready")
+	if err := listeners.Restart(5 * time.Second); err != nil {
+		t.Errorf("Expected the new process to take over, got %s", err)
+	}
+
+	os.Setenv("J_HELPER_PROCESS", "fail")
+	if err := listeners.Restart(5 * time.Second); err != ErrNotReady {
+		t.Errorf("Expected a new process stopping early not to be ready, got %v", err)
+	}
+
+	os.Setenv("J_HELPER_PROCESS", "hang")
+	if err := listeners.Restart(10 * time.Millisecond); err != ErrNotReady {
+		t.Errorf("Expected a slow new process not to be ready, got %v", err)
+	}
+}
+
+// TestHelperProcess Stands in for the journal when started by a restart
+func TestHelperProcess(t *testing.T) {
+	switch os.Getenv("J_HELPER_PROCESS") {
+	case "ready":
+		listeners := &Listeners{}
+		listener, err := listeners.Listen("http", "127.0.0.1:1")
+		if err != nil || !Inherited() || listener.Addr().String() != os.Getenv("J_HELPER_ADDR") {
+			os.Exit(1)
+		}
+		Ready()
+		os.Exit(0)
+	case "activated":
+		os.Setenv(systemdPIDEnv, strconv.Itoa(os.Getpid()))
+		listeners := &Listeners{Unnamed: "http"}
+		smtp, err := listeners.Listen("smtp", "127.0.0.1:0")
+		if err != nil || smtp.Addr().String() == os.Getenv("J_HELPER_ADDR") {
+			os.Exit(1)
+		}
+		listener, err := listeners.Listen("http", "127.0.0.1:1")
+		if err != nil || listener.Addr().String() != os.Getenv("J_HELPER_ADDR") {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "fail":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
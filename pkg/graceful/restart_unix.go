@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package graceful
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignals SIGUSR2 asks for a restart, handing the sockets to a new process
+var restartSignals = []os.Signal{syscall.SIGUSR2}
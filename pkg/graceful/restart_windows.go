@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package graceful
+
+import "os"
+
+// restartSignals Windows cannot hand sockets to a new process, so the journal can only be stopped
+var restartSignals = []os.Signal{}
@@ -0,0 +1,84 @@
+package graceful
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Variables systemd sets when it passes sockets it listens on to the process it starts, as with socket activation
+const (
+	systemdPIDEnv   = "LISTEN_PID"
+	systemdFDsEnv   = "LISTEN_FDS"
+	systemdNamesEnv = "LISTEN_FDNAMES"
+)
+
+// systemdFirstFD The descriptor of the first socket systemd passes, the others following it
+const systemdFirstFD = 3
+
+// activated Take the socket systemd passed with the name given by its FileDescriptorName=, or the first passed without
+// one when asked for the unnamed socket, giving nil when there is none or systemd passed them to another process
+func (l *Listeners) activated(name string) (net.Listener, error) {
+	if os.Getenv(systemdPIDEnv) != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv(systemdFDsEnv))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv(systemdNamesEnv), ":")
+	nameOf := func(i int) string {
+		if i < len(names) {
+			return names[i]
+		}
+		return ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	found := -1
+	for i := 0; i < count && found < 0; i++ {
+		if !l.taken[i] && nameOf(i) == name {
+			found = i
+		}
+	}
+	for i := 0; i < count && found < 0 && name != "" && name == l.Unnamed; i++ {
+		if !l.taken[i] && isUnnamed(nameOf(i)) {
+			found = i
+		}
+	}
+	if found < 0 {
+		return nil, nil
+	}
+
+	file := os.NewFile(uintptr(systemdFirstFD+found), name)
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, err
+	}
+	if l.taken == nil {
+		l.taken = map[int]bool{}
+	}
+	l.taken[found] = true
+
+	return listener, nil
+}
+
+// isUnnamed Check whether systemd passed a socket without a FileDescriptorName=, in which case it is named after its
+// unit, or without names at all
+func isUnnamed(name string) bool {
+	return name == "" || name == "unknown" || strings.HasSuffix(name, ".socket")
+}
+
+// isSystemd Check whether a variable of the environment was set by systemd for this process' sockets
+func isSystemd(variable string) bool {
+	for _, name := range []string{systemdPIDEnv, systemdFDsEnv, systemdNamesEnv} {
+		if strings.HasPrefix(variable, name+"=") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,411 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Schema The types a GraphQL API serves, starting from its query and, optionally, mutation types
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+}
+
+// Object A type with fields that may be selected, each resolved from the value of its parent
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field A field on an object, returning either a scalar or, when Type is set, an object or list of them to select from
+type Field struct {
+	Type    *Object
+	Args    []string
+	Resolve func(p Params) (interface{}, error)
+}
+
+// Params What a field is resolved from: the value of its parent, its arguments and the context of the request
+type Params struct {
+	Source  interface{}
+	Args    map[string]interface{}
+	Context interface{}
+}
+
+// Int Get an integer argument, accepting whole numbers decoded from JSON variables
+func (p Params) Int(name string) (int, bool) {
+	switch value := p.Args[name].(type) {
+	case int:
+		return value, true
+	case float64:
+		if value == float64(int(value)) {
+			return int(value), true
+		}
+	}
+
+	return 0, false
+}
+
+// String Get a string argument
+func (p Params) String(name string) (string, bool) {
+	value, ok := p.Args[name].(string)
+
+	return value, ok
+}
+
+// Object Get an input object argument
+func (p Params) Object(name string) (map[string]interface{}, bool) {
+	value, ok := p.Args[name].(map[string]interface{})
+
+	return value, ok
+}
+
+// Request A GraphQL request as sent by a client, along with whether mutations may be run by it
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+	QueryOnly     bool                   `json:"-"`
+}
+
+// Response The result of a request, holding its data and any errors raised getting it
+type Response struct {
+	Data   *Result `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Location A line and column in the document, both counted from 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error An error in a request, with where in the document or the result it came from
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Result An object in the response, keeping its fields in the order they were selected
+type Result struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewResult Create an empty result
+func NewResult() *Result {
+	return &Result{values: map[string]interface{}{}}
+}
+
+// Get Get the value of a field in the result
+func (r *Result) Get(key string) interface{} {
+	return r.values[key]
+}
+
+// Set Set the value of a field, adding it after the others if it is new
+func (r *Result) Set(key string, value interface{}) {
+	if _, ok := r.values[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+}
+
+// MarshalJSON Encode the result with its fields in order
+func (r *Result) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(r.values[key]); err != nil {
+			return nil, err
+		}
+		buffer.Truncate(buffer.Len() - 1)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// Do Parse, validate and run a request against the schema, passing context to every resolver
+func (s *Schema) Do(request Request, context interface{}) Response {
+	document, err := Parse(request.Query)
+	if err != nil {
+		return Response{Errors: []Error{*err.(*Error)}}
+	}
+
+	operation, err := document.operation(request.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{*err.(*Error)}}
+	}
+	root := s.Query
+	if operation.Type == "mutation" {
+		if s.Mutation == nil {
+			return Response{Errors: []Error{{Message: "Schema is not configured for mutations."}}}
+		}
+		if request.QueryOnly {
+			return Response{Errors: []Error{{Message: "Mutations can only be sent with POST."}}}
+		}
+		root = s.Mutation
+	}
+
+	variables, errs := operation.coerceVariables(request.Variables)
+	if len(errs) > 0 {
+		return Response{Errors: errs}
+	}
+	v := validator{document: document, source: request.Query, spreading: map[string]bool{}}
+	v.validate(root, operation.Selections)
+	if len(v.errors) > 0 {
+		return Response{Errors: v.errors}
+	}
+
+	e := executor{document: document, variables: variables, context: context}
+	data := e.executeSelections(root, nil, operation.Selections, []interface{}{})
+
+	return Response{Data: data, Errors: e.errors}
+}
+
+// operation Find the operation to run, by name when the document has more than one
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+
+		return d.Operations[0], nil
+	}
+	for _, operation := range d.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+
+	return nil, &Error{Message: "Unknown operation named \"" + name + "\"."}
+}
+
+// coerceVariables Fill in defaults for variables not given, failing for any that are required
+func (o *Operation) coerceVariables(given map[string]interface{}) (map[string]interface{}, []Error) {
+	variables := map[string]interface{}{}
+	errs := []Error{}
+	for _, definition := range o.Variables {
+		if value, ok := given[definition.Name]; ok {
+			if value == nil && definition.Required() {
+				errs = append(errs, Error{Message: "Variable \"$" + definition.Name + "\" of non-null type \"" + definition.Type + "\" must not be null."})
+			}
+			variables[definition.Name] = value
+		} else if definition.Default != nil {
+			variables[definition.Name] = definition.Default.resolve(nil)
+		} else if definition.Required() {
+			errs = append(errs, Error{Message: "Variable \"$" + definition.Name + "\" of required type \"" + definition.Type + "\" was not provided."})
+		}
+	}
+
+	return variables, errs
+}
+
+// validator Check every field selected exists on its type and is selected from correctly, before anything is run
+type validator struct {
+	document  *Document
+	source    string
+	spreading map[string]bool
+	errors    []Error
+}
+
+func (v *validator) validate(object *Object, selections []Selection) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *SelectedField:
+			v.validateField(object, s)
+		case *FragmentSpread:
+			fragment, ok := v.document.Fragments[s.Name]
+			if !ok {
+				v.errorf(s.pos, "Unknown fragment \""+s.Name+"\".")
+				continue
+			}
+			if v.spreading[s.Name] {
+				v.errorf(s.pos, "Cannot spread fragment \""+s.Name+"\" within itself.")
+				continue
+			}
+			if !v.validateOn(object, fragment.On, s.pos) {
+				continue
+			}
+			v.spreading[s.Name] = true
+			v.validate(object, fragment.Selections)
+			v.spreading[s.Name] = false
+		case *InlineFragment:
+			if s.On == "" || v.validateOn(object, s.On, s.pos) {
+				v.validate(object, s.Selections)
+			}
+		}
+	}
+}
+
+func (v *validator) validateField(object *Object, f *SelectedField) {
+	if f.Name == "__typename" {
+		if len(f.Selections) > 0 {
+			v.errorf(f.pos, "Field \"__typename\" must not have a selection since type \"String\" has no subfields.")
+		}
+		return
+	}
+	field, ok := object.Fields[f.Name]
+	if !ok {
+		v.errorf(f.pos, "Cannot query field \""+f.Name+"\" on type \""+object.Name+"\".")
+		return
+	}
+	for _, argument := range f.Arguments {
+		known := false
+		for _, name := range field.Args {
+			known = known || name == argument.Name
+		}
+		if !known {
+			v.errorf(f.pos, "Unknown argument \""+argument.Name+"\" on field \""+object.Name+"."+f.Name+"\".")
+		}
+	}
+	if field.Type == nil && len(f.Selections) > 0 {
+		v.errorf(f.pos, "Field \""+f.Name+"\" must not have a selection since it has no subfields.")
+	} else if field.Type != nil && len(f.Selections) == 0 {
+		v.errorf(f.pos, "Field \""+f.Name+"\" of type \""+field.Type.Name+"\" must have a selection of subfields.")
+	} else if field.Type != nil {
+		v.validate(field.Type, f.Selections)
+	}
+}
+
+// validateOn Check a fragment applies to the type it is spread on, there being no interfaces or unions to widen it
+func (v *validator) validateOn(object *Object, on string, pos int) bool {
+	if on != object.Name {
+		v.errorf(pos, "Fragment cannot be spread here as objects of type \""+object.Name+"\" can never be of type \""+on+"\".")
+		return false
+	}
+
+	return true
+}
+
+func (v *validator) errorf(pos int, message string) {
+	v.errors = append(v.errors, Error{Message: message, Locations: []Location{location(v.source, pos)}})
+}
+
+// executor Resolve the selected fields, collecting errors from resolvers against the path they were raised at
+type executor struct {
+	document  *Document
+	variables map[string]interface{}
+	context   interface{}
+	errors    []Error
+}
+
+// collectFields Gather the fields selected on an object, through any fragments that apply, grouped under the key each
+// is returned as
+func (e *executor) collectFields(object *Object, selections []Selection, keys *[]string, fields map[string][]*SelectedField, visited map[string]bool) {
+	for _, selection := range selections {
+		if !e.included(selection.directives()) {
+			continue
+		}
+		switch s := selection.(type) {
+		case *SelectedField:
+			if _, ok := fields[s.Key()]; !ok {
+				*keys = append(*keys, s.Key())
+			}
+			fields[s.Key()] = append(fields[s.Key()], s)
+		case *FragmentSpread:
+			fragment := e.document.Fragments[s.Name]
+			if visited[s.Name] || fragment.On != object.Name {
+				continue
+			}
+			visited[s.Name] = true
+			e.collectFields(object, fragment.Selections, keys, fields, visited)
+		case *InlineFragment:
+			if s.On != "" && s.On != object.Name {
+				continue
+			}
+			e.collectFields(object, s.Selections, keys, fields, visited)
+		}
+	}
+}
+
+// included Check the @skip and @include directives on a selection
+func (e *executor) included(directives []Directive) bool {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			continue
+		}
+		condition := false
+		for _, argument := range directive.Arguments {
+			if argument.Name == "if" {
+				condition, _ = argument.Value.resolve(e.variables).(bool)
+			}
+		}
+		if (directive.Name == "skip") == condition {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *executor) executeSelections(object *Object, source interface{}, selections []Selection, path []interface{}) *Result {
+	keys := []string{}
+	fields := map[string][]*SelectedField{}
+	e.collectFields(object, selections, &keys, fields, map[string]bool{})
+
+	result := NewResult()
+	for _, key := range keys {
+		result.Set(key, e.executeField(object, source, fields[key], append(path[:len(path):len(path)], key)))
+	}
+
+	return result
+}
+
+func (e *executor) executeField(object *Object, source interface{}, selected []*SelectedField, path []interface{}) interface{} {
+	f := selected[0]
+	if f.Name == "__typename" {
+		return object.Name
+	}
+
+	field := object.Fields[f.Name]
+	args := map[string]interface{}{}
+	for _, argument := range f.Arguments {
+		if value := argument.Value.resolve(e.variables); value != nil {
+			args[argument.Name] = value
+		} else if _, isVariable := argument.Value.(Variable); !isVariable {
+			args[argument.Name] = nil
+		}
+	}
+	value, err := field.Resolve(Params{Source: source, Args: args, Context: e.context})
+	if err != nil {
+		e.errors = append(e.errors, Error{Message: err.Error(), Path: path})
+		return nil
+	}
+	if field.Type == nil || value == nil {
+		return value
+	}
+
+	// Merge the selections made on every field returned under the same key
+	selections := []Selection{}
+	for _, s := range selected {
+		selections = append(selections, s.Selections...)
+	}
+
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Ptr && reflected.IsNil() {
+		return nil
+	}
+	if reflected.Kind() != reflect.Slice {
+		return e.executeSelections(field.Type, value, selections, path)
+	}
+	list := []interface{}{}
+	for i := 0; i < reflected.Len(); i++ {
+		list = append(list, e.executeSelections(field.Type, reflected.Index(i).Interface(), selections, append(path[:len(path):len(path)], i)))
+	}
+
+	return list
+}
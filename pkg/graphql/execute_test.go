@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testBook struct {
+	Title  string
+	Author string
+}
+
+func testSchema() *Schema {
+	books := []testBook{{"Dune", "Herbert"}, {"Emma", "Austen"}}
+	book := &Object{Name: "Book", Fields: map[string]*Field{
+		"title":  {Resolve: func(p Params) (interface{}, error) { return p.Source.(testBook).Title, nil }},
+		"author": {Resolve: func(p Params) (interface{}, error) { return p.Source.(testBook).Author, nil }},
+	}}
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"hello": {Args: []string{"name"}, Resolve: func(p Params) (interface{}, error) {
+			name, ok := p.String("name")
+			if !ok {
+				name = "world"
+			}
+			return "Hello " + name, nil
+		}},
+		"books": {Type: book, Args: []string{"first"}, Resolve: func(p Params) (interface{}, error) {
+			if first, ok := p.Int("first"); ok && first < len(books) {
+				return books[:first], nil
+			}
+			return books, nil
+		}},
+		"missing": {Type: book, Resolve: func(p Params) (interface{}, error) { return nil, nil }},
+		"fail":    {Resolve: func(p Params) (interface{}, error) { return nil, errors.New("Broken") }},
+		"context": {Resolve: func(p Params) (interface{}, error) { return p.Context, nil }},
+	}}
+	mutation := &Object{Name: "Mutation", Fields: map[string]*Field{
+		"add": {Type: book, Args: []string{"input"}, Resolve: func(p Params) (interface{}, error) {
+			input, _ := p.Object("input")
+			b := testBook{Title: input["title"].(string)}
+			books = append(books, b)
+			return b, nil
+		}},
+	}}
+
+	return &Schema{Query: query, Mutation: mutation}
+}
+
+func testDo(t *testing.T, schema *Schema, request Request) string {
+	encoded := bytes.Buffer{}
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(schema.Do(request, "ctx")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return strings.TrimSpace(encoded.String())
+}
+
+func TestSchema_Do(t *testing.T) {
+	schema := testSchema()
+	tables := []struct {
+		request Request
+		output  string
+	}{
+		{Request{Query: `{ hello context __typename }`}, `{"data":{"hello":"Hello world","context":"ctx","__typename":"Query"}}`},
+		{Request{Query: `{ b: books(first: 1) { title } books { author title } }`}, `{"data":{"b":[{"title":"Dune"}],"books":[{"author":"Herbert","title":"Dune"},{"author":"Austen","title":"Emma"}]}}`},
+		{Request{Query: `query ($n: String, $first: Int = 1) { hello(name: $n) books(first: $first) { title } }`, Variables: map[string]interface{}{"n": "<you>"}}, `{"data":{"hello":"Hello <you>","books":[{"title":"Dune"}]}}`},
+		{Request{Query: `query ($first: Int) { books(first: $first) { title } }`, Variables: map[string]interface{}{"first": 1.0}}, `{"data":{"books":[{"title":"Dune"}]}}`},
+		{Request{Query: `{ books(first: 1) { ...parts ... on Book { title } } } fragment parts on Book { author title }`}, `{"data":{"books":[{"author":"Herbert","title":"Dune"}]}}`},
+		{Request{Query: `{ books(first: 1) { title @skip(if: true) author @include(if: false) __typename } }`}, `{"data":{"books":[{"__typename":"Book"}]}}`},
+		{Request{Query: `query ($yes: Boolean) { books(first: 1) { title @include(if: $yes) } }`, Variables: map[string]interface{}{"yes": true}}, `{"data":{"books":[{"title":"Dune"}]}}`},
+		{Request{Query: `{ missing { title } fail hello }`}, `{"data":{"missing":null,"fail":null,"hello":"Hello world"},"errors":[{"message":"Broken","path":["fail"]}]}`},
+		{Request{Query: `query A { hello } query B { hello(name: "B") }`, OperationName: "B"}, `{"data":{"hello":"Hello B"}}`},
+		{Request{Query: `mutation { add(input: {title: "Ulysses"}) { title } }`}, `{"data":{"add":{"title":"Ulysses"}}}`},
+	}
+
+	for _, table := range tables {
+		if actual := testDo(t, schema, table.request); actual != table.output {
+			t.Errorf("Expected %q to give %s, got %s", table.request.Query, table.output, actual)
+		}
+	}
+}
+
+func TestSchema_Do_Errors(t *testing.T) {
+	schema := testSchema()
+	tables := []struct {
+		request Request
+		output  string
+	}{
+		{Request{Query: `{ hello `}, `{"errors":[{"message":"Syntax Error: Unexpected <EOF>","locations":[{"line":1,"column":9}]}]}`},
+		{Request{Query: `query A { hello } query B { hello }`}, `{"errors":[{"message":"Must provide operation name if query contains multiple operations."}]}`},
+		{Request{Query: `{ hello }`, OperationName: "C"}, `{"errors":[{"message":"Unknown operation named \"C\"."}]}`},
+		{Request{Query: `mutation { add(input: {title: "X"}) { title } }`, QueryOnly: true}, `{"errors":[{"message":"Mutations can only be sent with POST."}]}`},
+		{Request{Query: `query ($n: String!) { hello(name: $n) }`}, `{"errors":[{"message":"Variable \"$n\" of required type \"String!\" was not provided."}]}`},
+		{Request{Query: `query ($n: String!) { hello(name: $n) }`, Variables: map[string]interface{}{"n": nil}}, `{"errors":[{"message":"Variable \"$n\" of non-null type \"String!\" must not be null."}]}`},
+		{Request{Query: `{ nope }`}, `{"errors":[{"message":"Cannot query field \"nope\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`},
+		{Request{Query: `{ hello(who: "x") }`}, `{"errors":[{"message":"Unknown argument \"who\" on field \"Query.hello\".","locations":[{"line":1,"column":3}]}]}`},
+		{Request{Query: `{ hello { x } }`}, `{"errors":[{"message":"Field \"hello\" must not have a selection since it has no subfields.","locations":[{"line":1,"column":3}]}]}`},
+		{Request{Query: `{ books }`}, `{"errors":[{"message":"Field \"books\" of type \"Book\" must have a selection of subfields.","locations":[{"line":1,"column":3}]}]}`},
+		{Request{Query: `{ books { ...f } } fragment f on Book { ...f }`}, `{"errors":[{"message":"Cannot spread fragment \"f\" within itself.","locations":[{"line":1,"column":41}]}]}`},
+		{Request{Query: `{ books { ... on Query { hello } } }`}, `{"errors":[{"message":"Fragment cannot be spread here as objects of type \"Book\" can never be of type \"Query\".","locations":[{"line":1,"column":11}]}]}`},
+		{Request{Query: `{ books { ...g } }`}, `{"errors":[{"message":"Unknown fragment \"g\".","locations":[{"line":1,"column":11}]}]}`},
+	}
+
+	for _, table := range tables {
+		if actual := testDo(t, schema, table.request); actual != table.output {
+			t.Errorf("Expected %q to give %s, got %s", table.request.Query, table.output, actual)
+		}
+	}
+
+	noMutations := &Schema{Query: schema.Query}
+	if actual := testDo(t, noMutations, Request{Query: `mutation { add }`}); actual != `{"errors":[{"message":"Schema is not configured for mutations."}]}` {
+		t.Errorf("Expected mutations to be refused, got %s", actual)
+	}
+}
+
+func TestResult_MarshalJSON(t *testing.T) {
+	result := NewResult()
+	result.Set("b", "<1>")
+	result.Set("a", []interface{}{NewResult()})
+	result.Set("b", 2)
+	encoded, _ := json.Marshal(result)
+	if string(encoded) != `{"b":2,"a":[{}]}` || result.Get("b") != 2 {
+		t.Errorf("Expected fields to keep their order, got %s", encoded)
+	}
+}
@@ -0,0 +1,254 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// token A single piece of a GraphQL document, with the byte offset it started at
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	}
+
+	return t.value
+}
+
+// lexer Split a GraphQL document into tokens, skipping whitespace, commas and comments
+type lexer struct {
+	source string
+	pos    int
+}
+
+// next Read the next token from the document
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	start := l.pos
+	if l.pos >= len(l.source) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunctuator, value: "...", pos: start}, nil
+	case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.source) && (l.source[l.pos] == '_' || isLetter(l.source[l.pos]) || isDigit(l.source[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.source[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber()
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		return l.readBlockString()
+	case c == '"':
+		return l.readString()
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+	return token{}, l.errorf(start, "Unexpected character %q", r)
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.source[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) readNumber() (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+	digits := l.pos
+	if !l.readDigits() {
+		return token{}, l.errorf(start, "Invalid number, expected digit")
+	}
+	if l.source[digits] == '0' && l.pos-digits > 1 {
+		return token{}, l.errorf(start, "Invalid number, unexpected digit after 0")
+	}
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.readDigits() {
+			return token{}, l.errorf(start, "Invalid number, expected digit after .")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.readDigits() {
+			return token{}, l.errorf(start, "Invalid number, expected digit in exponent")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == '_' || l.source[l.pos] == '.' || isLetter(l.source[l.pos])) {
+		return token{}, l.errorf(l.pos, "Invalid number, unexpected %q", l.source[l.pos])
+	}
+
+	return token{kind: kind, value: l.source[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) readDigits() bool {
+	start := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+
+	return l.pos > start
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	l.pos++
+	value := strings.Builder{}
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: value.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(start, "Unterminated string")
+		case c == '\\':
+			if l.pos+1 >= len(l.source) {
+				return token{}, l.errorf(start, "Unterminated string")
+			}
+			escapes := map[byte]string{'"': "\"", '\\': "\\", '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}
+			if escaped, ok := escapes[l.source[l.pos+1]]; ok {
+				value.WriteString(escaped)
+				l.pos += 2
+				continue
+			}
+			if l.source[l.pos+1] != 'u' || l.pos+6 > len(l.source) {
+				return token{}, l.errorf(l.pos, "Invalid character escape sequence")
+			}
+			code, err := strconv.ParseUint(l.source[l.pos+2:l.pos+6], 16, 32)
+			if err != nil {
+				return token{}, l.errorf(l.pos, "Invalid character escape sequence")
+			}
+			value.WriteRune(rune(code))
+			l.pos += 6
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return token{}, l.errorf(start, "Unterminated string")
+}
+
+// readBlockString Read a """block string""", removing the indentation its lines share and any blank lines around it
+func (l *lexer) readBlockString() (token, error) {
+	start := l.pos
+	l.pos += 3
+	raw := strings.Builder{}
+	for l.pos < len(l.source) {
+		if strings.HasPrefix(l.source[l.pos:], `\"""`) {
+			raw.WriteString(`"""`)
+			l.pos += 4
+			continue
+		}
+		if strings.HasPrefix(l.source[l.pos:], `"""`) {
+			l.pos += 3
+			return token{kind: tokenString, value: blockStringValue(raw.String()), pos: start}, nil
+		}
+		raw.WriteByte(l.source[l.pos])
+		l.pos++
+	}
+
+	return token{}, l.errorf(start, "Unterminated string")
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(raw), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// errorf Build a syntax error pointing at a position in the document
+func (l *lexer) errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []Location{location(l.source, pos)}}
+}
+
+// location Find the line and column of a byte offset in a document, both counted from 1
+func location(source string, pos int) Location {
+	if pos > len(source) {
+		pos = len(source)
+	}
+	before := source[:pos]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+
+	return Location{Line: line, Column: column}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
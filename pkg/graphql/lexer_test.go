@@ -0,0 +1,59 @@
+package graphql
+
+import "testing"
+
+func TestLexer_Next(t *testing.T) {
+	tables := []struct {
+		input  string
+		kind   tokenKind
+		output string
+	}{
+		{"", tokenEOF, ""},
+		{"  ,\n# comment\n\ufeffname_1", tokenName, "name_1"},
+		{"...", tokenPunctuator, "..."},
+		{"{", tokenPunctuator, "{"},
+		{"-12", tokenInt, "-12"},
+		{"0", tokenInt, "0"},
+		{"1.5e-3", tokenFloat, "1.5e-3"},
+		{`"Say \"hi\"\né"`, tokenString, "Say \"hi\"\né"},
+		{"\"\"\"\n    Block\n      indented \\\"\"\"\n    \"\"\"", tokenString, "Block\n  indented \"\"\""},
+	}
+
+	for _, table := range tables {
+		l := &lexer{source: table.input}
+		actual, err := l.next()
+		if err != nil || actual.kind != table.kind || actual.value != table.output {
+			t.Errorf("Expected %q to give %q, got %q (%v)", table.input, table.output, actual.value, err)
+		}
+	}
+}
+
+func TestLexer_Next_Errors(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"?", "Syntax Error: Unexpected character '?'"},
+		{"007", "Syntax Error: Invalid number, unexpected digit after 0"},
+		{"1.", "Syntax Error: Invalid number, expected digit after ."},
+		{"12abc", "Syntax Error: Invalid number, unexpected 'a'"},
+		{"\"open", "Syntax Error: Unterminated string"},
+		{"\"line\nbreak\"", "Syntax Error: Unterminated string"},
+		{`"\q"`, "Syntax Error: Invalid character escape sequence"},
+		{`"""open`, "Syntax Error: Unterminated string"},
+	}
+
+	for _, table := range tables {
+		l := &lexer{source: table.input}
+		_, err := l.next()
+		if err == nil || err.Error() != table.output {
+			t.Errorf("Expected %q to fail with %q, got %v", table.input, table.output, err)
+		}
+	}
+}
+
+func TestLocation(t *testing.T) {
+	if actual := location("{\n  é bad", 6); actual.Line != 2 || actual.Column != 4 {
+		t.Errorf("Expected line 2 column 4, got %v", actual)
+	}
+}
@@ -0,0 +1,594 @@
+package graphql
+
+import (
+	"strconv"
+)
+
+// Document A parsed GraphQL request, holding its operations and the fragments they may spread
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation A query or mutation, with the variables it accepts
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []VariableDefinition
+	Selections []Selection
+}
+
+// VariableDefinition A variable an operation accepts, its type as written and any default value
+type VariableDefinition struct {
+	Name    string
+	Type    string
+	Default Value
+}
+
+// Required Whether the variable's type is non-null, so it must be given when there is no default
+func (v VariableDefinition) Required() bool {
+	return len(v.Type) > 0 && v.Type[len(v.Type)-1] == '!'
+}
+
+// Fragment A named set of selections that can be spread into others on the same type
+type Fragment struct {
+	Name       string
+	On         string
+	Selections []Selection
+}
+
+// Selection A field, fragment spread or inline fragment within a selection set
+type Selection interface {
+	directives() []Directive
+}
+
+// SelectedField A field to resolve, with any alias, arguments and the selections made on its result
+type SelectedField struct {
+	Alias      string
+	Name       string
+	Arguments  []Argument
+	Directives []Directive
+	Selections []Selection
+	pos        int
+}
+
+// Key The name the field's result is returned under
+func (f *SelectedField) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+func (f *SelectedField) directives() []Directive { return f.Directives }
+
+// FragmentSpread Selections taken from a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []Directive
+	pos        int
+}
+
+func (f *FragmentSpread) directives() []Directive { return f.Directives }
+
+// InlineFragment Selections written in place, applying only to the given type if one is named
+type InlineFragment struct {
+	On         string
+	Directives []Directive
+	Selections []Selection
+	pos        int
+}
+
+func (f *InlineFragment) directives() []Directive { return f.Directives }
+
+// Argument A named value passed to a field or directive
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Directive An annotation such as @skip or @include on a selection
+type Directive struct {
+	Name      string
+	Arguments []Argument
+}
+
+// Value A value written in a document, either a literal or a reference to a variable
+type Value interface {
+	resolve(variables map[string]interface{}) interface{}
+}
+
+// Variable A reference to a variable, taking its value from those supplied with the request
+type Variable string
+
+func (v Variable) resolve(variables map[string]interface{}) interface{} {
+	return variables[string(v)]
+}
+
+// literal A scalar, enum or null value written directly in the document
+type literal struct {
+	value interface{}
+}
+
+func (l literal) resolve(variables map[string]interface{}) interface{} {
+	return l.value
+}
+
+// listValue A list of values written in the document
+type listValue []Value
+
+func (l listValue) resolve(variables map[string]interface{}) interface{} {
+	result := []interface{}{}
+	for _, v := range l {
+		result = append(result, v.resolve(variables))
+	}
+
+	return result
+}
+
+// objectValue An input object written in the document
+type objectValue []Argument
+
+func (o objectValue) resolve(variables map[string]interface{}) interface{} {
+	result := map[string]interface{}{}
+	for _, field := range o {
+		result[field.Name] = field.Value.resolve(variables)
+	}
+
+	return result
+}
+
+// Parse Read a GraphQL document into its operations and fragments
+func Parse(source string) (*Document, error) {
+	p := &parser{lexer: &lexer{source: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	document := &Document{Fragments: map[string]*Fragment{}}
+	for p.token.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, &Operation{Type: "query", Selections: selections})
+		case p.token.kind == tokenName && (p.token.value == "query" || p.token.value == "mutation"):
+			operation, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			document.Operations = append(document.Operations, operation)
+		case p.token.kind == tokenName && p.token.value == "fragment":
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := document.Fragments[fragment.Name]; ok {
+				return nil, &Error{Message: "There can be only one fragment named \"" + fragment.Name + "\"."}
+			}
+			document.Fragments[fragment.Name] = fragment
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(document.Operations) == 0 {
+		return nil, &Error{Message: "Syntax Error: Document must contain an operation"}
+	}
+
+	return document, nil
+}
+
+// parser Build a document from the tokens read by the lexer, looking one token ahead
+type parser struct {
+	lexer *lexer
+	token token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = t
+
+	return nil
+}
+
+// peek Check whether the current token is the given punctuator
+func (p *parser) peek(punctuator string) bool {
+	return p.token.kind == tokenPunctuator && p.token.value == punctuator
+}
+
+// skip Move past the given punctuator if it is next, reporting whether it was
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(punctuator) {
+		return false, nil
+	}
+
+	return true, p.advance()
+}
+
+// expect Move past the given punctuator, failing if something else is next
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(punctuator) {
+		return p.lexer.errorf(p.token.pos, "Expected %q, found %s", punctuator, p.token)
+	}
+
+	return p.advance()
+}
+
+// expectName Read a name, failing if something else is next
+func (p *parser) expectName() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.lexer.errorf(p.token.pos, "Expected Name, found %s", p.token)
+	}
+	name := p.token.value
+
+	return name, p.advance()
+}
+
+// expectKeyword Move past the given keyword, failing if something else is next
+func (p *parser) expectKeyword(keyword string) error {
+	if p.token.kind != tokenName || p.token.value != keyword {
+		return p.lexer.errorf(p.token.pos, "Expected %q, found %s", keyword, p.token)
+	}
+
+	return p.advance()
+}
+
+func (p *parser) unexpected() error {
+	return p.lexer.errorf(p.token.pos, "Unexpected %s", p.token)
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: p.token.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.token.kind == tokenName {
+		operation.Name = p.token.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			definition, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			operation.Variables = append(operation.Variables, definition)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	operation.Selections = selections
+
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinition() (VariableDefinition, error) {
+	definition := VariableDefinition{}
+	if err := p.expect("$"); err != nil {
+		return definition, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return definition, err
+	}
+	definition.Name = name
+	if err := p.expect(":"); err != nil {
+		return definition, err
+	}
+	if definition.Type, err = p.parseType(); err != nil {
+		return definition, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return definition, err
+	} else if ok {
+		if definition.Default, err = p.parseValue(true); err != nil {
+			return definition, err
+		}
+	}
+	_, err = p.parseDirectives()
+
+	return definition, err
+}
+
+// parseType Read a type reference such as String, [Int!] or ID!, returned as written without spaces
+func (p *parser) parseType() (string, error) {
+	written := ""
+	if ok, err := p.skip("["); err != nil {
+		return "", err
+	} else if ok {
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		written = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		written = name
+	}
+	if ok, err := p.skip("!"); err != nil {
+		return "", err
+	} else if ok {
+		written += "!"
+	}
+
+	return written, nil
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	fragment := &Fragment{}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.lexer.errorf(p.token.pos, "Unexpected Name \"on\"")
+	}
+	fragment.Name = name
+	if err := p.expectKeyword("on"); err != nil {
+		return nil, err
+	}
+	if fragment.On, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if fragment.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+
+	return fragment, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	selections := []Selection{}
+	for !p.peek("}") {
+		if p.token.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.lexer.errorf(p.token.pos, "Expected Name, found %s", p.token)
+	}
+
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	pos := p.token.pos
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.parseFragmentSelection(pos)
+	}
+
+	field := &SelectedField{pos: pos}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = name
+		if name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+	if field.Arguments, err = p.parseArguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseFragmentSelection(pos int) (Selection, error) {
+	if p.token.kind == tokenName && p.token.value != "on" {
+		spread := &FragmentSpread{Name: p.token.value, pos: pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		directives, err := p.parseDirectives()
+		spread.Directives = directives
+
+		return spread, err
+	}
+
+	inline := &InlineFragment{pos: pos}
+	if p.token.kind == tokenName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		on, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		inline.On = on
+	}
+	var err error
+	if inline.Directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if inline.Selections, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+
+	return inline, nil
+}
+
+func (p *parser) parseArguments(constant bool) ([]Argument, error) {
+	arguments := []Argument{}
+	if ok, err := p.skip("("); err != nil || !ok {
+		return arguments, err
+	}
+	for !p.peek(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, Argument{Name: name, Value: value})
+	}
+	if len(arguments) == 0 {
+		return nil, p.lexer.errorf(p.token.pos, "Expected Name, found %s", p.token)
+	}
+
+	return arguments, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]Directive, error) {
+	directives := []Directive{}
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.parseArguments(false)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, Directive{Name: name, Arguments: arguments})
+	}
+
+	return directives, nil
+}
+
+// parseValue Read a value, refusing variables where only constants are allowed such as in defaults
+func (p *parser) parseValue(constant bool) (Value, error) {
+	t := p.token
+	switch {
+	case p.peek("$"):
+		if constant {
+			return nil, p.unexpected()
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+
+		return Variable(name), err
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := listValue{}
+		for !p.peek("]") {
+			if p.token.kind == tokenEOF {
+				return nil, p.unexpected()
+			}
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+
+		return list, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		object := objectValue{}
+		for !p.peek("}") {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, Argument{Name: name, Value: value})
+		}
+
+		return object, p.advance()
+	case t.kind == tokenInt:
+		value, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, p.lexer.errorf(t.pos, "Int cannot represent %s", t.value)
+		}
+
+		return literal{value}, p.advance()
+	case t.kind == tokenFloat:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, p.lexer.errorf(t.pos, "Float cannot represent %s", t.value)
+		}
+
+		return literal{value}, p.advance()
+	case t.kind == tokenString:
+		return literal{t.value}, p.advance()
+	case t.kind == tokenName:
+		switch t.value {
+		case "true":
+			return literal{true}, p.advance()
+		case "false":
+			return literal{false}, p.advance()
+		case "null":
+			return literal{nil}, p.advance()
+		}
+
+		// Enum values are passed to resolvers by name
+		return literal{t.value}, p.advance()
+	}
+
+	return nil, p.unexpected()
+}
@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	document, err := Parse(`
+		query Entries($first: Int = 10, $after: String, $slug: String!) @cached {
+			latest: journals(first: $first, after: $after, filter: {tags: ["a", B], draft: false, score: 1.5, none: null}) {
+				...entry @include(if: true)
+				... on Journal { slug }
+				... @skip(if: false) { title }
+			}
+		}
+		fragment entry on Journal { id }
+		mutation { trash(slug: "x") }
+	`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(document.Operations) != 2 || document.Fragments["entry"].On != "Journal" {
+		t.Fatal("Expected 2 operations and a fragment")
+	}
+
+	query := document.Operations[0]
+	if query.Type != "query" || query.Name != "Entries" || len(query.Variables) != 3 {
+		t.Error("Expected named query with variables")
+	}
+	if query.Variables[0].Type != "Int" || query.Variables[0].Default.resolve(nil) != 10 || query.Variables[0].Required() || !query.Variables[2].Required() {
+		t.Error("Expected variable types and defaults to be read")
+	}
+
+	field := query.Selections[0].(*SelectedField)
+	if field.Key() != "latest" || field.Name != "journals" || len(field.Arguments) != 3 || len(field.Selections) != 3 {
+		t.Error("Expected aliased field with arguments and selections")
+	}
+	if field.Arguments[0].Value.resolve(map[string]interface{}{"first": 5}) != 5 {
+		t.Error("Expected variable to be resolved")
+	}
+	filter := field.Arguments[2].Value.resolve(nil)
+	expected := map[string]interface{}{"tags": []interface{}{"a", "B"}, "draft": false, "score": 1.5, "none": nil}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Errorf("Expected input object to be resolved, got %v", filter)
+	}
+	if spread, ok := field.Selections[0].(*FragmentSpread); !ok || spread.Name != "entry" || len(spread.Directives) != 1 {
+		t.Error("Expected fragment spread with directive")
+	}
+	if inline, ok := field.Selections[1].(*InlineFragment); !ok || inline.On != "Journal" {
+		t.Error("Expected inline fragment on type")
+	}
+	if inline, ok := field.Selections[2].(*InlineFragment); !ok || inline.On != "" || inline.Directives[0].Name != "skip" {
+		t.Error("Expected inline fragment without type")
+	}
+
+	if mutation := document.Operations[1]; mutation.Type != "mutation" || mutation.Name != "" {
+		t.Error("Expected anonymous mutation")
+	}
+}
+
+func TestParse_Shorthand(t *testing.T) {
+	document, err := Parse("{ a b { c } }")
+	if err != nil || len(document.Operations) != 1 || document.Operations[0].Type != "query" || len(document.Operations[0].Selections) != 2 {
+		t.Error("Expected shorthand query to be parsed")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"", "Syntax Error: Document must contain an operation"},
+		{"{", "Syntax Error: Unexpected <EOF>"},
+		{"{ }", "Syntax Error: Expected Name, found }"},
+		{"{ a(b) }", "Syntax Error: Expected \":\", found )"},
+		{"{ a() }", "Syntax Error: Expected Name, found )"},
+		{"query ($a: Int = $b) { a }", "Syntax Error: Unexpected $"},
+		{"subscription { a }", "Syntax Error: Unexpected subscription"},
+		{"fragment on on T { a }", "Syntax Error: Unexpected Name \"on\""},
+		{"fragment f on T { a } fragment f on T { b } { a }", "There can be only one fragment named \"f\"."},
+		{"{ a(b: [1, 2) }", "Syntax Error: Unexpected )"},
+	}
+
+	for _, table := range tables {
+		if _, err := Parse(table.input); err == nil || err.Error() != table.output {
+			t.Errorf("Expected %q to fail with %q, got %v", table.input, table.output, err)
+		}
+	}
+}
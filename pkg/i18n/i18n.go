@@ -0,0 +1,231 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message A message as written in a language, with the form used for a single thing apart from the one used for any
+// other number of them when it counts something
+type Message struct {
+	One   string
+	Other string
+}
+
+// UnmarshalJSON Read a message written either as text, or as an object giving its one and other forms
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		m.One, m.Other = text, text
+		return nil
+	}
+	forms := struct {
+		One   string `json:"one"`
+		Other string `json:"other"`
+	}{}
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	if forms.One == "" {
+		forms.One = forms.Other
+	}
+	m.One, m.Other = forms.One, forms.Other
+
+	return nil
+}
+
+// Catalog The messages of one language, kept by the English they translate, along with the name the language is known
+// by to those speaking it
+type Catalog struct {
+	Locale   string             `json:"-"`
+	Name     string             `json:"name"`
+	Messages map[string]Message `json:"messages"`
+}
+
+// Catalogs Catalogs of each language that can be chosen, by their locales such as en or fr
+type Catalogs map[string]*Catalog
+
+// Load Read the catalogs kept as JSON files in a directory, each named after its locale such as fr.json
+func Load(files fs.FS, dir string) (Catalogs, error) {
+	names, err := fs.Glob(files, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	catalogs := Catalogs{}
+	for _, name := range names {
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		catalog := &Catalog{}
+		if err := json.Unmarshal(data, catalog); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		catalog.Locale = strings.ToLower(strings.TrimSuffix(path.Base(name), ".json"))
+		catalogs[catalog.Locale] = catalog
+	}
+
+	return catalogs, nil
+}
+
+// Locales The locales that can be chosen, in order
+func (c Catalogs) Locales() []string {
+	locales := []string{}
+	for locale := range c {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Translate Write a message in a language, filling in any values as fmt does. Messages a catalog has no translation
+// for are written as they are in English.
+func (c Catalogs) Translate(locale string, message string, args ...interface{}) string {
+	return format(c.lookup(locale, message).Other, args)
+}
+
+// Plural Write a message counting something in a language, in its form for one thing or any other number of them as
+// the language has it. The count is filled in, followed by any other values, when none are given, and forms that leave
+// it out, such as "Every day", are written as they are.
+func (c Catalogs) Plural(locale string, count int, message string, args ...interface{}) string {
+	translated := c.lookup(locale, message)
+	if len(args) == 0 {
+		args = []interface{}{count}
+	}
+	text := translated.Other
+	if one(locale, count) {
+		text = translated.One
+	}
+	if !strings.Contains(text, "%") {
+		return text
+	}
+
+	return format(text, args)
+}
+
+// lookup Find a message in the catalog of a language, or in English when it has not been translated
+func (c Catalogs) lookup(locale string, message string) Message {
+	for _, candidate := range []string{locale, English} {
+		if catalog, ok := c[candidate]; ok {
+			if found, ok := catalog.Messages[message]; ok && found.Other != "" {
+				return found
+			}
+		}
+	}
+
+	return Message{One: message, Other: message}
+}
+
+// English The locale messages are written in, and the one chosen when no other is
+const English = "en"
+
+// one Check whether a count takes the form used for a single thing in a language, French using it for none too
+func one(locale string, count int) bool {
+	switch primary(locale) {
+	case "fr":
+		return count == 0 || count == 1
+	}
+
+	return count == 1
+}
+
+// format Fill in the values of a message, leaving it as it is when it has none
+func format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// primary The language of a locale without its region, such as fr for fr-CA
+func primary(locale string) string {
+	if i := strings.IndexAny(locale, "-_"); i != -1 {
+		return locale[:i]
+	}
+
+	return locale
+}
+
+// Negotiate Choose the locale a reader prefers from those that can be chosen, by the languages their browser accepts
+// and how much it prefers each, falling back to a locale when none of them can be
+func Negotiate(acceptLanguage string, locales []string, fallback string) string {
+	type preference struct {
+		tag     string
+		quality float64
+	}
+	preferences := []preference{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				if parsed, err := strconv.ParseFloat(field[2:], 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, preference := range preferences {
+		for _, locale := range locales {
+			if preference.tag == locale || primary(preference.tag) == locale {
+				return locale
+			}
+		}
+		if preference.tag == "*" && len(locales) > 0 {
+			return fallback
+		}
+	}
+
+	return fallback
+}
+
+// dateNames Names of months and days in the layouts of times, the longer taken where both start at the same place so
+// that January is not read as Jan
+var dateNames = []string{"January", "Monday", "Jan", "Mon"}
+
+// FormatTime Write a time in a layout as it is written in a language, the layout and the names of its months and days
+// being translated by the catalog
+func (c Catalogs) FormatTime(locale string, t time.Time, layout string) string {
+	layout = c.Translate(locale, layout)
+	if primary(locale) == English {
+		return t.Format(layout)
+	}
+
+	written := strings.Builder{}
+	for layout != "" {
+		next, name := len(layout), ""
+		for _, candidate := range dateNames {
+			if i := strings.Index(layout, candidate); i != -1 && (i < next || i == next && len(candidate) > len(name)) {
+				next, name = i, candidate
+			}
+		}
+		written.WriteString(t.Format(layout[:next]))
+		if name == "" {
+			break
+		}
+		written.WriteString(c.Translate(locale, t.Format(name)))
+		layout = layout[next+len(name):]
+	}
+
+	return written.String()
+}
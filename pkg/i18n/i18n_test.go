@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testCatalogs(t *testing.T) Catalogs {
+	files := fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"name": "English", "messages": {
+			"%d comments": {"one": "%d comment", "other": "%d comments"},
+			"Every %d days": {"one": "Every day", "other": "Every %d days"}
+		}}`)},
+		"locales/fr.json": {Data: []byte(`{"name": "Français", "messages": {
+			"Save": "Enregistrer",
+			"Hello %s": "Bonjour %s",
+			"%d comments": {"one": "%d commentaire", "other": "%d commentaires"},
+			"Monday January 2, 2006": "Monday 2 January 2006",
+			"Wednesday": "mercredi",
+			"January": "janvier",
+			"Jan": "janv."
+		}}`)},
+		"locales/notes.txt": {Data: []byte("Not a catalog")},
+	}
+	catalogs, err := Load(files, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return catalogs
+}
+
+func TestLoad(t *testing.T) {
+	catalogs := testCatalogs(t)
+	if locales := catalogs.Locales(); len(locales) != 2 || locales[0] != "en" || locales[1] != "fr" {
+		t.Errorf("Expected English and French catalogs, got %v", locales)
+	}
+	if catalogs["fr"].Name != "Français" || catalogs["fr"].Locale != "fr" {
+		t.Error("Expected the name and locale of each catalog to be kept")
+	}
+
+	if _, err := Load(fstest.MapFS{"locales/de.json": {Data: []byte(`{"messages": [`)}}, "locales"); err == nil {
+		t.Error("Expected a broken catalog to be reported")
+	}
+}
+
+func TestCatalogs_Translate(t *testing.T) {
+	catalogs := testCatalogs(t)
+	tables := []struct {
+		locale  string
+		message string
+		args    []interface{}
+		output  string
+	}{
+		{"fr", "Save", nil, "Enregistrer"},
+		{"fr", "Hello %s", []interface{}{"Alice"}, "Bonjour Alice"},
+		{"fr", "Not translated", nil, "Not translated"},
+		{"en", "Save", nil, "Save"},
+		{"de", "Hello %s", []interface{}{"Alice"}, "Hello Alice"},
+		{"fr", "100%", nil, "100%"},
+	}
+
+	for _, table := range tables {
+		if actual := catalogs.Translate(table.locale, table.message, table.args...); actual != table.output {
+			t.Errorf("Expected '%s' in %s to be '%s', got '%s'", table.message, table.locale, table.output, actual)
+		}
+	}
+}
+
+func TestCatalogs_Plural(t *testing.T) {
+	catalogs := testCatalogs(t)
+	tables := []struct {
+		locale string
+		count  int
+		output string
+	}{
+		{"en", 0, "0 comments"},
+		{"en", 1, "1 comment"},
+		{"en", 2, "2 comments"},
+		{"fr", 0, "0 commentaire"},
+		{"fr", 1, "1 commentaire"},
+		{"fr", 2, "2 commentaires"},
+		{"de", 1, "1 comment"},
+	}
+
+	for _, table := range tables {
+		if actual := catalogs.Plural(table.locale, table.count, "%d comments"); actual != table.output {
+			t.Errorf("Expected %d in %s to be '%s', got '%s'", table.count, table.locale, table.output, actual)
+		}
+	}
+	if catalogs.Plural("en", 1, "Every %d days") != "Every day" || catalogs.Plural("en", 3, "Every %d days") != "Every 3 days" {
+		t.Error("Expected a form leaving out the count to be written as it is")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	locales := []string{"en", "fr"}
+	tables := []struct {
+		header string
+		output string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr"},
+		{"de-DE,de;q=0.9,fr;q=0.5", "fr"},
+		{"en;q=0.5, FR", "fr"},
+		{"fr;q=0, en", "en"},
+		{"de, *;q=0.5", "en"},
+		{"de", "en"},
+	}
+
+	for _, table := range tables {
+		if actual := Negotiate(table.header, locales, English); actual != table.output {
+			t.Errorf("Expected '%s' to choose %s, got %s", table.header, table.output, actual)
+		}
+	}
+}
+
+func TestCatalogs_FormatTime(t *testing.T) {
+	catalogs := testCatalogs(t)
+	date := time.Date(2030, time.January, 2, 9, 0, 0, 0, time.UTC)
+	tables := []struct {
+		locale string
+		layout string
+		output string
+	}{
+		{"en", "Monday January 2, 2006", "Wednesday January 2, 2030"},
+		{"fr", "Monday January 2, 2006", "mercredi 2 janvier 2030"},
+		{"fr", "2 Jan 2006 15:04", "2 janv. 2030 09:00"},
+		{"fr", "2006-01-02", "2030-01-02"},
+	}
+
+	for _, table := range tables {
+		if actual := catalogs.FormatTime(table.locale, date, table.layout); actual != table.output {
+			t.Errorf("Expected '%s' in %s to be '%s', got '%s'", table.layout, table.locale, table.output, actual)
+		}
+	}
+}
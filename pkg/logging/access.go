@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FormatCombined Format of access logs written as the combined log of Apache and nginx, read by most log analysers
+const FormatCombined = "combined"
+
+// Request What is logged of each request once served
+type Request struct {
+	ID        string
+	IP        string
+	Method    string
+	Path      string
+	Query     string
+	Proto     string
+	Referer   string
+	UserAgent string
+	Status    int
+	Bytes     int
+	Time      time.Time
+	Duration  time.Duration
+}
+
+// AccessLog Writes a line for each request served, as an entry in logfmt or JSON or in the combined log format
+type AccessLog struct {
+	out    io.Writer
+	format string
+	logger *Logger
+	mu     sync.Mutex
+}
+
+// NewAccessLog Create an access log writing lines in a format, logfmt being used for any format but json or combined
+func NewAccessLog(out io.Writer, format string) *AccessLog {
+	return &AccessLog{out: out, format: format, logger: New(out, format, LevelInfo)}
+}
+
+// Log Write the line for a request served
+func (a *AccessLog) Log(r Request) {
+	if a.format != FormatCombined {
+		logger := *a.logger
+		logger.now = func() time.Time { return r.Time }
+		logger.Info("Served request", "request_id", r.ID, "ip", r.IP, "method", r.Method, "path", r.Path, "status", r.Status,
+			"bytes", r.Bytes, "duration_ms", float64(r.Duration.Microseconds())/1000, "referer", r.Referer, "user_agent", r.UserAgent)
+		return
+	}
+
+	target := r.Path
+	if r.Query != "" {
+		target += "?" + r.Query
+	}
+	line := bytes.Buffer{}
+	line.WriteString(orDash(r.IP) + " - - [" + r.Time.Format("02/Jan/2006:15:04:05 -0700") + "] ")
+	line.WriteString(strconv.Quote(r.Method+" "+target+" "+r.Proto) + " " + strconv.Itoa(r.Status) + " ")
+	if r.Bytes > 0 {
+		line.WriteString(strconv.Itoa(r.Bytes))
+	} else {
+		line.WriteByte('-')
+	}
+	line.WriteString(" " + strconv.Quote(orDash(r.Referer)) + " " + strconv.Quote(orDash(r.UserAgent)) + "\n")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.out.Write(line.Bytes())
+}
+
+// orDash Write nothing as a dash, as the combined log format does
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAccessLog_Log(t *testing.T) {
+	request := Request{ID: "abc", IP: "192.0.2.1", Method: "GET", Path: "/search", Query: "q=a+b", Proto: "HTTP/1.1",
+		UserAgent: `Reader "1.0"`, Status: 200, Bytes: 512, Time: fixed(), Duration: 4200 * time.Microsecond}
+
+	output := &bytes.Buffer{}
+	NewAccessLog(output, FormatCombined).Log(request)
+	expected := `192.0.2.1 - - [02/Jan/2026:03:04:05 +0000] "GET /search?q=a+b HTTP/1.1" 200 512 "-" "Reader \"1.0\""` + "\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	NewAccessLog(output, FormatText).Log(request)
+	expected = `time=2026-01-02T03:04:05Z level=info msg="Served request" request_id=abc ip=192.0.2.1 method=GET path=/search status=200 bytes=512 duration_ms=4.2 referer="" user_agent="Reader \"1.0\""` + "\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	NewAccessLog(output, FormatJSON).Log(request)
+	expected = `{"time":"2026-01-02T03:04:05Z","level":"info","msg":"Served request","request_id":"abc","ip":"192.0.2.1","method":"GET","path":"/search","status":200,"bytes":512,"duration_ms":4.2,"referer":"","user_agent":"Reader \"1.0\""}` + "\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat Time added to the name of a rotated file, such as journal-20200102-150405.log
+const rotatedTimeFormat = "20060102-150405"
+
+// File A log file rotated once writing to it would take it past MaxSize bytes, or once a line is written in a later
+// Interval than it was last written to, renaming it with the time it was rotated and keeping the newest Keep of those.
+// Nothing is rotated for a MaxSize or Interval of 0, and every rotated file is kept for a Keep of 0.
+type File struct {
+	Path     string
+	MaxSize  int64
+	Interval time.Duration
+	Keep     int
+	file     *os.File
+	size     int64
+	written  time.Time
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// OpenFile Open a log file to add to, creating it and its directory when missing
+func OpenFile(path string, maxSize int64, interval time.Duration, keep int) (*File, error) {
+	f := &File{Path: path, MaxSize: maxSize, Interval: interval, Keep: keep, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write Add lines to the file, rotating it first when they would go past its size or are written in a later interval
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if f.size > 0 && (f.MaxSize > 0 && f.size+int64(len(p)) > f.MaxSize ||
+		f.Interval > 0 && now.Truncate(f.Interval).After(f.written.Truncate(f.Interval))) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	f.written = now
+
+	return n, err
+}
+
+// Rotate Rotate the file now, whatever its size
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.rotate(f.now())
+}
+
+// Close Close the file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// Rotated Get the files rotated from the file, oldest first, those rotated within the same second being numbered from 2
+func (f *File) Rotated() []string {
+	ext := filepath.Ext(f.Path)
+	prefix := strings.TrimSuffix(f.Path, ext) + "-"
+	matches, _ := filepath.Glob(prefix + "*" + ext)
+	rotated := []string{}
+	order := map[string]string{}
+	for _, match := range matches {
+		stamp := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext), ".", 2)
+		if _, err := time.Parse(rotatedTimeFormat, stamp[0]); err != nil {
+			continue
+		}
+		number := 1
+		if len(stamp) == 2 {
+			if number, _ = strconv.Atoi(stamp[1]); number < 2 {
+				continue
+			}
+		}
+		rotated = append(rotated, match)
+		order[match] = fmt.Sprintf("%s.%09d", stamp[0], number)
+	}
+	sort.Slice(rotated, func(a, b int) bool {
+		return order[rotated[a]] < order[rotated[b]]
+	})
+
+	return rotated
+}
+
+// open Open the file to add to, carrying on from its size and when it was last written to
+func (f *File) open() error {
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.written = file, info.Size(), info.ModTime()
+
+	return nil
+}
+
+// rotate Rename the file with the time it was rotated, removing the oldest rotated beyond those kept, and start another
+func (f *File) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(f.Path)
+	name := strings.TrimSuffix(f.Path, ext) + "-" + now.Format(rotatedTimeFormat)
+	rotated := name + ext
+	for i := 2; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = name + "." + strconv.Itoa(i) + ext
+	}
+	if err := os.Rename(f.Path, rotated); err != nil {
+		f.open()
+		return err
+	}
+	if all := f.Rotated(); f.Keep > 0 && len(all) > f.Keep {
+		for _, old := range all[:len(all)-f.Keep] {
+			os.Remove(old)
+		}
+	}
+
+	return f.open()
+}
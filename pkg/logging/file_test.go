@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "journal.log")
+	f, err := OpenFile(path, 20, 0, 2)
+	if err != nil {
+		t.Fatalf("Expected log file and its directory to be created, got %s", err)
+	}
+	defer f.Close()
+	now := fixed()
+	f.now = func() time.Time { return now }
+
+	// Test lines are added until the next would go past the size
+	f.Write([]byte("first line\n"))
+	f.Write([]byte("second\n"))
+	if len(f.Rotated()) != 0 {
+		t.Error("Expected file not to be rotated within its size")
+	}
+	f.Write([]byte("third line\n"))
+	rotated := f.Rotated()
+	if len(rotated) != 1 || filepath.Base(rotated[0]) != "journal-"+now.Format(rotatedTimeFormat)+".log" {
+		t.Fatalf("Expected file to be rotated with the time, got %v", rotated)
+	}
+	if content, _ := os.ReadFile(rotated[0]); string(content) != "first line\nsecond\n" {
+		t.Errorf("Expected rotated file to keep earlier lines, got '%s'", content)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "third line\n" {
+		t.Errorf("Expected new file to be started, got '%s'", content)
+	}
+
+	// Test files rotated within the same second are numbered and only the newest are kept
+	f.Rotate()
+	f.Write([]byte("fourth\n"))
+	f.Rotate()
+	rotated = f.Rotated()
+	if len(rotated) != 2 || !strings.HasSuffix(rotated[0], ".2.log") || !strings.HasSuffix(rotated[1], ".3.log") {
+		t.Errorf("Expected the newest 2 rotated files to be kept, got %v", rotated)
+	}
+}
+
+func TestFile_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	os.WriteFile(path, []byte("yesterday\n"), 0644)
+	yesterday := fixed().Add(-24 * time.Hour)
+	os.Chtimes(path, yesterday, yesterday)
+
+	// Test a file last written in an earlier interval is rotated before the first line in this one
+	f, err := OpenFile(path, 0, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.now = fixed
+	f.Write([]byte("today\n"))
+	f.Write([]byte("later today\n"))
+	if rotated := f.Rotated(); len(rotated) != 1 {
+		t.Errorf("Expected file to be rotated once, got %v", rotated)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "today\nlater today\n" {
+		t.Errorf("Expected lines of this interval to be kept together, got '%s'", content)
+	}
+}
@@ -0,0 +1,319 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level How serious an entry is, entries below the level of a logger being left out
+type Level int
+
+// Levels of entries, from the least serious
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Formats entries can be written in
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String Get the name of a level, as written in entries
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+
+	return levelNames[l]
+}
+
+// ParseLevel Get a level from its name, such as warn
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level %s, expected debug, info, warn or error", name)
+}
+
+// Logger Writes entries as lines of logfmt or JSON, each with its time, level, message and fields given as pairs of
+// names and values. Loggers made from another with With share its output.
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	format string
+	level  Level
+	fields []interface{}
+	now    func() time.Time
+}
+
+// New Create a logger writing entries at or above a level in a format, logfmt being used for any format but json
+func New(out io.Writer, format string, level Level) *Logger {
+	return &Logger{out: out, mu: &sync.Mutex{}, format: format, level: level, now: time.Now}
+}
+
+var (
+	std   = New(os.Stderr, FormatText, LevelInfo)
+	stdMu sync.RWMutex
+)
+
+// Default Get the logger used by the functions of this package and by requests not given one of their own
+func Default() *Logger {
+	stdMu.RLock()
+	defer stdMu.RUnlock()
+
+	return std
+}
+
+// SetDefault Replace the logger used by the functions of this package
+func SetDefault(logger *Logger) {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	std = logger
+}
+
+// With Get a logger adding fields, given as pairs of names and values, to each of its entries
+func (l *Logger) With(fields ...interface{}) *Logger {
+	with := *l
+	with.fields = append(append([]interface{}{}, l.fields...), fields...)
+
+	return &with
+}
+
+// Enabled Check whether entries at a level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+// Debug Write an entry only needed while looking into a problem
+func (l *Logger) Debug(msg string, fields ...interface{}) {
+	l.Log(LevelDebug, msg, fields...)
+}
+
+// Info Write an entry about the journal going about its work
+func (l *Logger) Info(msg string, fields ...interface{}) {
+	l.Log(LevelInfo, msg, fields...)
+}
+
+// Warn Write an entry about something that failed without stopping the journal
+func (l *Logger) Warn(msg string, fields ...interface{}) {
+	l.Log(LevelWarn, msg, fields...)
+}
+
+// Error Write an entry about something that failed and needs looking into
+func (l *Logger) Error(msg string, fields ...interface{}) {
+	l.Log(LevelError, msg, fields...)
+}
+
+// Fatal Write an error entry and stop the journal
+func (l *Logger) Fatal(msg string, fields ...interface{}) {
+	l.Log(LevelError, msg, fields...)
+	os.Exit(1)
+}
+
+// Log Write an entry at a level, with fields given as pairs of names and values. A value without a name is given the
+// name !extra, and errors are written as their message.
+func (l *Logger) Log(level Level, msg string, fields ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	all := append([]interface{}{"time", l.now().Format(time.RFC3339), "level", level.String(), "msg", msg}, l.fields...)
+	all = append(all, fields...)
+	line := bytes.Buffer{}
+	if l.format == FormatJSON {
+		writeJSON(&line, all)
+	} else {
+		writeText(&line, all)
+	}
+	line.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line.Bytes())
+}
+
+// Writer Get a writer turning each line written to it into an entry at a level, for code writing to a log.Logger
+func (l *Logger) Writer(level Level) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+			l.Log(level, line)
+		}
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (w writerFunc) Write(p []byte) (int, error) {
+	return w(p)
+}
+
+// pairs Go through fields as pairs of names and values
+func pairs(fields []interface{}, each func(name string, value interface{})) {
+	for i := 0; i < len(fields); i += 2 {
+		name, ok := fields[i].(string)
+		if !ok || i+1 == len(fields) {
+			each("!extra", fields[i])
+			i--
+			continue
+		}
+		each(name, fields[i+1])
+	}
+}
+
+// plainValue Values written in logfmt without quotes
+var plainValue = regexp.MustCompile(`^[^\s"=\\\x00-\x1f]+$`)
+
+func writeText(line *bytes.Buffer, fields []interface{}) {
+	first := true
+	pairs(fields, func(name string, value interface{}) {
+		if !first {
+			line.WriteByte(' ')
+		}
+		first = false
+		line.WriteString(name)
+		line.WriteByte('=')
+		text := format(value)
+		if plainValue.MatchString(text) {
+			line.WriteString(text)
+		} else {
+			line.WriteString(strconv.Quote(text))
+		}
+	})
+}
+
+func writeJSON(line *bytes.Buffer, fields []interface{}) {
+	values := map[string]interface{}{}
+	names := []string{}
+	pairs(fields, func(name string, value interface{}) {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		switch typed := value.(type) {
+		case error:
+			value = typed.Error()
+		case time.Duration:
+			value = typed.String()
+		case fmt.Stringer:
+			value = typed.String()
+		}
+		values[name] = value
+	})
+
+	// The time, level and message come first, then the fields in the order given
+	line.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		encodedName, _ := json.Marshal(name)
+		encoded, err := json.Marshal(values[name])
+		if err != nil {
+			encoded, _ = json.Marshal(fmt.Sprint(values[name]))
+		}
+		line.Write(encodedName)
+		line.WriteByte(':')
+		line.Write(encoded)
+	}
+	line.WriteByte('}')
+}
+
+// format Write a value as text
+func format(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case error:
+		return typed.Error()
+	case []string:
+		return strings.Join(typed, ",")
+	}
+
+	return fmt.Sprint(value)
+}
+
+type contextKey string
+
+const (
+	loggerKey    contextKey = "logger"
+	requestIDKey contextKey = "request-id"
+)
+
+// NewContext Get a context carrying a logger, such as one adding the ID of the request being served to its entries
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext Get the logger carried by a context, or the default one
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return logger
+	}
+
+	return Default()
+}
+
+// WithRequestID Get a context carrying the ID of the request being served
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID Get the ID of the request being served from its context, or nothing outside a request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+
+	return id
+}
+
+// NewRequestID Create a random ID for a request
+func NewRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// Debug Write an entry with the default logger only needed while looking into a problem
+func Debug(msg string, fields ...interface{}) {
+	Default().Debug(msg, fields...)
+}
+
+// Info Write an entry with the default logger about the journal going about its work
+func Info(msg string, fields ...interface{}) {
+	Default().Info(msg, fields...)
+}
+
+// Warn Write an entry with the default logger about something that failed without stopping the journal
+func Warn(msg string, fields ...interface{}) {
+	Default().Warn(msg, fields...)
+}
+
+// Error Write an entry with the default logger about something that failed and needs looking into
+func Error(msg string, fields ...interface{}) {
+	Default().Error(msg, fields...)
+}
+
+// Fatal Write an error entry with the default logger and stop the journal
+func Fatal(msg string, fields ...interface{}) {
+	Default().Fatal(msg, fields...)
+}
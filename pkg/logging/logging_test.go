@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+)
+
+func fixed() time.Time {
+	return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+func TestLogger_Text(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := New(output, FormatText, LevelInfo)
+	logger.now = fixed
+
+	logger.Debug("Hidden")
+	logger.With("request_id", "abc").Warn("Could not send", "to", "https://example.com/a b", "err", errors.New(`said "no"`), "tries", 3, "odd")
+	logger.Info("Done", "hosts", []string{"a.example.com", "b.example.com"}, "empty", "")
+	expected := `time=2026-01-02T03:04:05Z level=warn msg="Could not send" request_id=abc to="https://example.com/a b" err="said \"no\"" tries=3 !extra=odd
+time=2026-01-02T03:04:05Z level=info msg=Done hosts=a.example.com,b.example.com empty=""
+`
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
+
+func TestLogger_JSON(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := New(output, FormatJSON, LevelDebug)
+	logger.now = fixed
+
+	logger.With("request_id", "abc").Debug("Checked", "status", 200, "err", errors.New("none"), "took", time.Second)
+	expected := `{"time":"2026-01-02T03:04:05Z","level":"debug","msg":"Checked","request_id":"abc","status":200,"err":"none","took":"1s"}` + "\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
+
+func TestLogger_Writer(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := New(output, FormatText, LevelInfo)
+	logger.now = fixed
+
+	log.New(logger.Writer(LevelWarn), "", 0).Println("http: TLS handshake error")
+	expected := `time=2026-01-02T03:04:05Z level=warn msg="http: TLS handshake error"` + "\n"
+	if output.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, expected := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warn": LevelWarn, "Error": LevelError} {
+		if level, err := ParseLevel(name); err != nil || level != expected {
+			t.Errorf("Expected %s to be level %s, got %s", name, expected, level)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected unknown levels to be refused")
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != Default() || RequestID(ctx) != "" {
+		t.Error("Expected the default logger and no ID outside a request")
+	}
+
+	logger := New(&bytes.Buffer{}, FormatText, LevelInfo)
+	ctx = WithRequestID(NewContext(ctx, logger), "abc")
+	if FromContext(ctx) != logger || RequestID(ctx) != "abc" {
+		t.Error("Expected the logger and ID of the request")
+	}
+	if id := NewRequestID(); len(id) != 16 || id == NewRequestID() {
+		t.Errorf("Expected random IDs, got %s", id)
+	}
+}
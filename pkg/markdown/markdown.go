@@ -0,0 +1,367 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reRule        = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	reFence       = regexp.MustCompile("^ {0,3}(```+|~~~+)[ \\t]*([^`\\s]*)")
+	reBullet      = regexp.MustCompile(`^ {0,3}([-*+])[ \t]+(.*)$`)
+	reOrdered     = regexp.MustCompile(`^ {0,3}(\d{1,9})[.)][ \t]+(.*)$`)
+	reQuote       = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	reEntity      = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	reAutolink    = regexp.MustCompile(`^<((?:https?://|mailto:)[^\s<>]+)>`)
+	reBareURL     = regexp.MustCompile(`^https?://[^\s<]+`)
+	reSafeScheme  = regexp.MustCompile(`^(?i)(https?|mailto):`)
+	reSchemeLike  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*:`)
+	punctuation   = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+	trailingChars = ".,:;!?)'\""
+)
+
+// Render Convert Markdown into HTML, escaping any HTML within the source so the result is safe to display
+func Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+
+	return renderBlocks(strings.Split(source, "\n"), false)
+}
+
+func renderBlocks(lines []string, tight bool) string {
+	out := []string{}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		if m := reFence.FindStringSubmatch(line); m != nil {
+			fence := m[1]
+			code := []string{}
+			i++
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			class := ""
+			if m[2] != "" {
+				class = " class=\"language-" + html.EscapeString(m[2]) + "\""
+			}
+			out = append(out, "<pre><code"+class+">"+html.EscapeString(strings.Join(code, "\n"))+"</code></pre>")
+			continue
+		}
+
+		if m := reHeading.FindStringSubmatch(line); m != nil {
+			level := strconv.Itoa(len(m[1]))
+			out = append(out, "<h"+level+">"+renderInline(m[2])+"</h"+level+">")
+			i++
+			continue
+		}
+
+		if reRule.MatchString(line) {
+			out = append(out, "<hr />")
+			i++
+			continue
+		}
+
+		if reQuote.MatchString(line) {
+			quoted := []string{}
+			for ; i < len(lines) && reQuote.MatchString(lines[i]); i++ {
+				quoted = append(quoted, reQuote.FindStringSubmatch(lines[i])[1])
+			}
+			out = append(out, "<blockquote>\n"+renderBlocks(quoted, false)+"\n</blockquote>")
+			continue
+		}
+
+		if reBullet.MatchString(line) || reOrdered.MatchString(line) {
+			var list string
+			list, i = renderList(lines, i)
+			out = append(out, list)
+			continue
+		}
+
+		// Paragraphs run until a blank line or the start of another block
+		para := []string{}
+		for ; i < len(lines); i++ {
+			l := lines[i]
+			if strings.TrimSpace(l) == "" || (len(para) > 0 && startsBlock(l)) {
+				break
+			}
+			para = append(para, l)
+		}
+		text := renderParagraph(para)
+		if tight {
+			out = append(out, text)
+		} else {
+			out = append(out, "<p>"+text+"</p>")
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func startsBlock(line string) bool {
+	return reFence.MatchString(line) || reHeading.MatchString(line) || reRule.MatchString(line) ||
+		reQuote.MatchString(line) || reBullet.MatchString(line) || reOrdered.MatchString(line)
+}
+
+func renderParagraph(lines []string) string {
+	parts := []string{}
+	for n, l := range lines {
+		l = strings.TrimLeft(l, " ")
+		hardBreak := n < len(lines)-1 && (strings.HasSuffix(l, "  ") || strings.HasSuffix(l, "\\"))
+		l = strings.TrimRight(l, " ")
+		if hardBreak {
+			l = strings.TrimSuffix(l, "\\")
+			parts = append(parts, renderInline(l)+"<br />")
+		} else {
+			parts = append(parts, renderInline(l))
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// renderList Render a list starting at the given line, returning the HTML and the next line to read
+func renderList(lines []string, i int) (string, int) {
+	ordered := reOrdered.MatchString(lines[i])
+	marker := func(line string) []string {
+		if ordered {
+			return reOrdered.FindStringSubmatch(line)
+		}
+		return reBullet.FindStringSubmatch(line)
+	}
+
+	start := marker(lines[i])[1]
+	items := [][]string{}
+	for i < len(lines) {
+		line := lines[i]
+		if m := marker(line); m != nil && indent(line) < 2 && (ordered || m[1] == start) {
+			items = append(items, []string{m[2]})
+			i++
+			continue
+		}
+
+		// Indented lines belong to the current item, a blank line ends the list unless more follow
+		if strings.TrimSpace(line) == "" {
+			next := i + 1
+			if next < len(lines) && (marker(lines[next]) != nil || strings.HasPrefix(lines[next], "  ")) {
+				i++
+				continue
+			}
+			break
+		}
+		if strings.HasPrefix(line, "  ") {
+			items[len(items)-1] = append(items[len(items)-1], dedent(line))
+			i++
+			continue
+		}
+		break
+	}
+
+	tag, attr := "ul", ""
+	if ordered {
+		tag = "ol"
+		if n, _ := strconv.Atoi(start); n != 1 {
+			attr = " start=\"" + strconv.Itoa(n) + "\""
+		}
+	}
+	out := "<" + tag + attr + ">\n"
+	for _, item := range items {
+		out += "<li>" + renderBlocks(item, true) + "</li>\n"
+	}
+
+	return out + "</" + tag + ">", i
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func dedent(line string) string {
+	for n := 0; n < 4 && strings.HasPrefix(line, " "); n++ {
+		line = line[1:]
+	}
+	return line
+}
+
+func renderInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		rest := s[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			n := len(rest) - len(strings.TrimLeft(rest, "`"))
+			ticks := rest[:n]
+			if end := strings.Index(rest[n:], ticks); end >= 0 {
+				code := rest[n : n+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += n + end + n
+				continue
+			}
+			b.WriteString(ticks)
+			i += n
+			continue
+
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if text, url, length, ok := parseLink(rest[1:]); ok {
+				b.WriteString("<img src=\"" + safeURL(url) + "\" alt=\"" + html.EscapeString(text) + "\" />")
+				i += 1 + length
+				continue
+			}
+
+		case c == '[':
+			if text, url, length, ok := parseLink(rest); ok {
+				b.WriteString("<a href=\"" + safeURL(url) + "\">" + renderInline(text) + "</a>")
+				i += length
+				continue
+			}
+
+		case c == '<':
+			if m := reAutolink.FindStringSubmatch(rest); m != nil {
+				b.WriteString("<a href=\"" + safeURL(m[1]) + "\">" + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+
+		case c == 'h' && (i == 0 || !isWordChar(s[i-1])):
+			if m := reBareURL.FindString(rest); m != "" {
+				url := strings.TrimRight(m, trailingChars)
+				b.WriteString("<a href=\"" + safeURL(url) + "\">" + html.EscapeString(url) + "</a>")
+				i += len(url)
+				continue
+			}
+
+		case c == '&':
+			if m := reEntity.FindString(rest); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			if out, length := emphasis(s, i); length > 0 {
+				b.WriteString(out)
+				i += length
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+
+	return b.String()
+}
+
+// emphasis Match strong, emphasised or struck through text opening at position i
+func emphasis(s string, i int) (string, int) {
+	c := s[i]
+	if c == '_' && i > 0 && isWordChar(s[i-1]) {
+		return "", 0
+	}
+
+	tags := []struct {
+		delim string
+		tag   string
+	}{
+		{strings.Repeat(string(c), 2), "strong"},
+		{string(c), "em"},
+	}
+	if c == '~' {
+		tags = tags[:1]
+		tags[0].tag = "del"
+	}
+
+	for _, t := range tags {
+		if !strings.HasPrefix(s[i:], t.delim) {
+			continue
+		}
+		start := i + len(t.delim)
+		if start >= len(s) || s[start] == ' ' {
+			continue
+		}
+		for end := start + 1; end <= len(s)-len(t.delim); end++ {
+			if s[end:end+len(t.delim)] != t.delim || s[end-1] == ' ' {
+				continue
+			}
+			// A single delimiter must not be half of a double one
+			if len(t.delim) == 1 && end+1 < len(s) && s[end+1] == c {
+				end++
+				continue
+			}
+			if c == '_' && end+len(t.delim) < len(s) && isWordChar(s[end+len(t.delim)]) {
+				continue
+			}
+			return "<" + t.tag + ">" + renderInline(s[start:end]) + "</" + t.tag + ">", end + len(t.delim) - i
+		}
+	}
+
+	return "", 0
+}
+
+// parseLink Read [text](url "title") from the start of s
+func parseLink(s string) (string, string, int, bool) {
+	depth := 0
+	close := -1
+	for n := 0; n < len(s); n++ {
+		if s[n] == '\\' {
+			n++
+			continue
+		}
+		if s[n] == '[' {
+			depth++
+		} else if s[n] == ']' {
+			depth--
+			if depth == 0 {
+				close = n
+				break
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(s) || s[close+1] != '(' {
+		return "", "", 0, false
+	}
+	end := strings.IndexByte(s[close+2:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+	target := strings.TrimSpace(s[close+2 : close+2+end])
+	if space := strings.IndexAny(target, " \t"); space >= 0 {
+		target = target[:space]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+
+	return s[1:close], target, close + 3 + end, true
+}
+
+// safeURL Escape a link target, dropping any that use a scheme other than http, https or mailto
+func safeURL(url string) string {
+	if reSchemeLike.MatchString(url) && !reSafeScheme.MatchString(url) {
+		return "#"
+	}
+
+	return html.EscapeString(url)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"", ""},
+		{"Hello world", "<p>Hello world</p>"},
+		{"First\r\n\r\nSecond", "<p>First</p>\n<p>Second</p>"},
+		{"Line one  \nLine two", "<p>Line one<br />\nLine two</p>"},
+		{"# Title", "<h1>Title</h1>"},
+		{"### Smaller ###", "<h3>Smaller</h3>"},
+		{"#NotAHeading", "<p>#NotAHeading</p>"},
+		{"---", "<hr />"},
+		{"Some **strong**, *em*, __also strong__, _also em_ and ~~gone~~", "<p>Some <strong>strong</strong>, <em>em</em>, <strong>also strong</strong>, <em>also em</em> and <del>gone</del></p>"},
+		{"snake_case_words stay", "<p>snake_case_words stay</p>"},
+		{"2 * 3 * 4", "<p>2 * 3 * 4</p>"},
+		{"Use `<b>` here", "<p>Use <code>&lt;b&gt;</code> here</p>"},
+		{"```go\nfmt.Println(\"<hi>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)</code></pre>"},
+		{"> Quoted\n> text", "<blockquote>\n<p>Quoted\ntext</p>\n</blockquote>"},
+		{"- One\n- Two\n  continued", "<ul>\n<li>One</li>\n<li>Two\ncontinued</li>\n</ul>"},
+		{"3. Three\n4. Four", "<ol start=\"3\">\n<li>Three</li>\n<li>Four</li>\n</ol>"},
+		{"- Outer\n  - Inner", "<ul>\n<li>Outer\n<ul>\n<li>Inner</li>\n</ul></li>\n</ul>"},
+		{"Intro\n- Item", "<p>Intro</p>\n<ul>\n<li>Item</li>\n</ul>"},
+		{"[Link](https://example.com \"Title\")", "<p><a href=\"https://example.com\">Link</a></p>"},
+		{"[**Bold** link](/relative)", "<p><a href=\"/relative\"><strong>Bold</strong> link</a></p>"},
+		{"![Alt \"text\"](https://example.com/a.png)", "<p><img src=\"https://example.com/a.png\" alt=\"Alt &#34;text&#34;\" /></p>"},
+		{"Visit https://example.com/page.", "<p>Visit <a href=\"https://example.com/page\">https://example.com/page</a>.</p>"},
+		{"<https://example.com>", "<p><a href=\"https://example.com\">https://example.com</a></p>"},
+		{"Fish &amp; chips & peas", "<p>Fish &amp; chips &amp; peas</p>"},
+		{"\\*not em\\*", "<p>*not em*</p>"},
+		{":gif:id:abc_def: stays", "<p>:gif:id:abc_def: stays</p>"},
+	}
+
+	for _, table := range tables {
+		actual := Render(table.input)
+		if actual != table.output {
+			t.Errorf("Expected Render(%q) to produce:\n%s\ngot:\n%s", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestRender_Sanitises(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>"},
+		{"[Click](javascript:alert(1))", "<p><a href=\"#\">Click</a>)</p>"},
+		{"[Click](JavaScript:alert%281%29)", "<p><a href=\"#\">Click</a></p>"},
+		{"![x](data:image/svg+xml;base64,AAAA)", "<p><img src=\"#\" alt=\"x\" /></p>"},
+		{"[x](https://example.com/\"onmouseover=\"alert(1))", "<p><a href=\"https://example.com/&#34;onmouseover=&#34;alert(1\">x</a>)</p>"},
+		{"[x](mailto:jamie@example.com)", "<p><a href=\"mailto:jamie@example.com\">x</a></p>"},
+	}
+
+	for _, table := range tables {
+		actual := Render(table.input)
+		if actual != table.output {
+			t.Errorf("Expected Render(%q) to produce:\n%s\ngot:\n%s", table.input, table.output, actual)
+		}
+	}
+}
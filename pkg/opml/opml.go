@@ -0,0 +1,97 @@
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// ErrNotOPML is returned when a document is not an OPML outline
+var ErrNotOPML = errors.New("The document is not an OPML file")
+
+// Outline One line of an outline, which is a feed when it has an XML URL and may hold further outlines beneath it
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Name The name to show for an outline, its title or else its text
+func (o Outline) Name() string {
+	if o.Title != "" {
+		return o.Title
+	}
+
+	return o.Text
+}
+
+// Document An OPML outline, as used to swap lists of feeds between readers
+type Document struct {
+	Title    string
+	Outlines []Outline
+}
+
+type head struct {
+	Title string `xml:"title"`
+}
+
+type body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type opml struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    head     `xml:"head"`
+	Body    body     `xml:"body"`
+}
+
+// Parse Read an OPML document
+func Parse(r io.Reader) (Document, error) {
+	doc := opml{}
+	decoder := xml.NewDecoder(r)
+	// Readers export in all sorts of encodings, so read them as they are rather than refusing the file
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		if _, ok := err.(xml.UnmarshalError); ok {
+			return Document{}, ErrNotOPML
+		}
+		return Document{}, err
+	}
+
+	return Document{Title: doc.Head.Title, Outlines: doc.Body.Outlines}, nil
+}
+
+// Feeds Every outline pointing to a feed, however deeply it was nested within folders
+func (d Document) Feeds() []Outline {
+	feeds := []Outline{}
+	var walk func([]Outline)
+	walk = func(outlines []Outline) {
+		for _, o := range outlines {
+			if o.XMLURL != "" {
+				feed := o
+				feed.Outlines = nil
+				feeds = append(feeds, feed)
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(d.Outlines)
+
+	return feeds
+}
+
+// Render Write the document as OPML 2.0
+func (d Document) Render() ([]byte, error) {
+	output, err := xml.MarshalIndent(opml{Version: "2.0", Head: head{Title: d.Title}, Body: body{Outlines: d.Outlines}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
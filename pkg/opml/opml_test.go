@@ -0,0 +1,73 @@
+package opml
+
+import (
+	"strings"
+	"testing"
+)
+
+const exported = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Friends">
+      <outline text="Alice" title="Alice's Blog" type="rss" xmlUrl="https://alice.example.com/feed" htmlUrl="https://alice.example.com/" />
+      <outline text="Nested">
+        <outline text="Bob" type="rss" xmlUrl="https://bob.example.com/atom.xml" />
+      </outline>
+    </outline>
+    <outline text="Just a note" />
+    <outline text="Carol" xmlUrl="https://carol.example.com/feed.json" />
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if doc.Title != "Subscriptions" || len(doc.Outlines) != 3 {
+		t.Errorf("Expected title and top level outlines, got %v", doc)
+	}
+
+	feeds := doc.Feeds()
+	if len(feeds) != 3 {
+		t.Fatalf("Expected 3 feeds, got %v", feeds)
+	}
+	if feeds[0].Name() != "Alice's Blog" || feeds[0].HTMLURL != "https://alice.example.com/" || feeds[1].Name() != "Bob" || feeds[2].XMLURL != "https://carol.example.com/feed.json" {
+		t.Errorf("Expected feeds in order with their names, got %v", feeds)
+	}
+
+	if _, err := Parse(strings.NewReader(`<rss version="2.0"></rss>`)); err != ErrNotOPML {
+		t.Errorf("Expected other documents to be refused, got %v", err)
+	}
+	if _, err := Parse(strings.NewReader("not xml")); err == nil {
+		t.Error("Expected error for invalid XML")
+	}
+}
+
+func TestDocument_Render(t *testing.T) {
+	doc := Document{Title: "Blogroll", Outlines: []Outline{
+		{Text: "Alice & Co", Type: "rss", XMLURL: "https://alice.example.com/feed", HTMLURL: "https://alice.example.com/"},
+	}}
+	output, err := doc.Render()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := `<opml version="2.0">
+  <head>
+    <title>Blogroll</title>
+  </head>
+  <body>
+    <outline text="Alice &amp; Co" type="rss" xmlUrl="https://alice.example.com/feed" htmlUrl="https://alice.example.com/"></outline>
+  </body>
+</opml>`
+	if !strings.HasPrefix(string(output), "<?xml") || !strings.Contains(string(output), expected) {
+		t.Errorf("Expected OPML document, got:\n%s", string(output))
+	}
+
+	// Test what is written can be read back
+	parsed, err := Parse(strings.NewReader(string(output)))
+	if err != nil || len(parsed.Feeds()) != 1 || parsed.Feeds()[0].Text != "Alice & Co" {
+		t.Errorf("Expected rendered document to be read back, got %v, %v", parsed, err)
+	}
+}
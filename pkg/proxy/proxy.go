@@ -0,0 +1,141 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseProxies Read a comma separated list of IP addresses and CIDR ranges, ignoring any that are not valid
+func ParseProxies(list string) []*net.IPNet {
+	proxies := []*net.IPNet{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+
+	return proxies
+}
+
+// ClientIP Find the address of the client making a request, believing the X-Forwarded-For header only when it was added
+// by a trusted proxy, and then only as far back as the first address that is not one
+func ClientIP(request *http.Request, proxies []*net.IPNet) string {
+	ip := remoteIP(request)
+	if !fromProxy(request, proxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(request.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !Trusted(proxies, hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// Forward Get the request as the client made it when it was passed on by a trusted proxy, taking its address from
+// X-Forwarded-For, its host from X-Forwarded-Host and its scheme from X-Forwarded-Proto. The headers are removed once
+// believed, and requests from anywhere else are left as they are.
+func Forward(request *http.Request, proxies []*net.IPNet) *http.Request {
+	if !fromProxy(request, proxies) {
+		return request
+	}
+
+	forwarded := request.Clone(request.Context())
+	_, port, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		port = "0"
+	}
+	forwarded.RemoteAddr = net.JoinHostPort(ClientIP(request, proxies), port)
+	if host := first(request.Header.Get("X-Forwarded-Host")); host != "" && !strings.ContainsAny(host, "/\\@ ") {
+		forwarded.Host = host
+	}
+	if scheme := strings.ToLower(first(request.Header.Get("X-Forwarded-Proto"))); scheme == "http" || scheme == "https" {
+		forwarded.URL.Scheme = scheme
+	}
+	for _, header := range []string{"X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Proto"} {
+		forwarded.Header.Del(header)
+	}
+
+	return forwarded
+}
+
+// Scheme Get the scheme a request was made with, being HTTPS when it arrived over TLS or a trusted proxy said so
+func Scheme(request *http.Request) string {
+	if Secure(request) {
+		return "https"
+	}
+
+	return "http"
+}
+
+// Secure Whether a request was made over HTTPS, either directly or to a trusted proxy passing it on
+func Secure(request *http.Request) bool {
+	return request.TLS != nil || (request.URL != nil && request.URL.Scheme == "https")
+}
+
+// Trusted Whether an address is one of the proxies, whose headers describing the client may be believed
+func Trusted(proxies []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type localKey struct{}
+
+// ConnContext Mark connections made over a unix domain socket as coming from a trusted proxy, as only something on the
+// same host can make them, for use as the ConnContext of an http.Server
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if conn.LocalAddr().Network() == "unix" {
+		return context.WithValue(ctx, localKey{}, true)
+	}
+
+	return ctx
+}
+
+// fromProxy Whether a request was passed on by a trusted proxy, or over a unix domain socket
+func fromProxy(request *http.Request, proxies []*net.IPNet) bool {
+	return request.Context().Value(localKey{}) != nil || Trusted(proxies, remoteIP(request))
+}
+
+// first Get the first of a comma separated list of values, being the one added by the proxy nearest the client
+func first(values string) string {
+	return strings.TrimSpace(strings.Split(values, ",")[0])
+}
+
+func remoteIP(request *http.Request) string {
+	ip, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		ip = request.RemoteAddr
+	}
+
+	return ip
+}
@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestParseProxies(t *testing.T) {
+	proxies := ParseProxies("10.0.0.0/8, 192.168.1.1,::1,nonsense,")
+	if len(proxies) != 3 || proxies[1].String() != "192.168.1.1/32" || proxies[2].String() != "::1/128" {
+		t.Errorf("Expected addresses and ranges to be read, got %v", proxies)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := ParseProxies("10.0.0.0/8")
+	request, _ := http.NewRequest("POST", "/new", nil)
+
+	// Test address connecting is used
+	request.RemoteAddr = "1.2.3.4:5000"
+	request.Header.Set("X-Forwarded-For", "6.6.6.6")
+	if ip := ClientIP(request, proxies); ip != "1.2.3.4" {
+		t.Errorf("Expected untrusted forwarding to be ignored, got %s", ip)
+	}
+
+	// Test trusted proxies give the client, but not anything the client claimed before reaching them
+	request.RemoteAddr = "10.0.0.1:5000"
+	request.Header.Set("X-Forwarded-For", "6.6.6.6, 1.2.3.4, 10.0.0.2")
+	if ip := ClientIP(request, proxies); ip != "1.2.3.4" {
+		t.Errorf("Expected client forwarded by trusted proxies, got %s", ip)
+	}
+	request.Header.Set("X-Forwarded-For", "garbage")
+	if ip := ClientIP(request, proxies); ip != "10.0.0.1" {
+		t.Errorf("Expected proxy when it forwards nothing valid, got %s", ip)
+	}
+}
+
+func TestForward(t *testing.T) {
+	proxies := ParseProxies("10.0.0.0/8")
+	request, _ := http.NewRequest("GET", "http://internal:3000/test", nil)
+	request.RemoteAddr = "10.0.0.1:5000"
+	request.Header.Set("X-Forwarded-For", "1.2.3.4")
+	request.Header.Set("X-Forwarded-Host", "journal.example.com, internal")
+	request.Header.Set("X-Forwarded-Proto", "HTTPS")
+
+	// Test requests from trusted proxies are seen as the client made them
+	forwarded := Forward(request, proxies)
+	if forwarded.RemoteAddr != "1.2.3.4:5000" || forwarded.Host != "journal.example.com" || Scheme(forwarded) != "https" || !Secure(forwarded) {
+		t.Errorf("Expected client's address, host and scheme, got %s, %s and %s", forwarded.RemoteAddr, forwarded.Host, Scheme(forwarded))
+	}
+	if forwarded.Header.Get("X-Forwarded-For") != "" || ClientIP(forwarded, proxies) != "1.2.3.4" {
+		t.Error("Expected headers to be removed once believed")
+	}
+	if request.RemoteAddr != "10.0.0.1:5000" || request.Host != "internal:3000" || Secure(request) {
+		t.Error("Expected original request to be left as it was")
+	}
+
+	// Test unusable values are ignored
+	request.Header.Set("X-Forwarded-Host", "evil.example.com/path")
+	request.Header.Set("X-Forwarded-Proto", "gopher")
+	forwarded = Forward(request, proxies)
+	if forwarded.Host != "internal:3000" || Scheme(forwarded) != "http" {
+		t.Errorf("Expected unusable host and scheme to be ignored, got %s and %s", forwarded.Host, Scheme(forwarded))
+	}
+
+	// Test requests from anywhere else are left alone
+	request.RemoteAddr = "6.6.6.6:5000"
+	if Forward(request, proxies) != request {
+		t.Error("Expected untrusted request to be left alone")
+	}
+}
+
+func TestSecure(t *testing.T) {
+	if Secure(&http.Request{}) || Scheme(&http.Request{}) != "http" {
+		t.Error("Expected plain request not to be secure")
+	}
+	if !Secure(&http.Request{TLS: &tls.ConnectionState{}}) {
+		t.Error("Expected request over TLS to be secure")
+	}
+}
+
+type addrConn struct {
+	net.Conn
+	local net.Addr
+}
+
+func (c addrConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+func TestConnContext(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/test", nil)
+	request.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	// Test connections over TCP are only trusted from the proxies
+	ctx := ConnContext(context.Background(), addrConn{local: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3000}})
+	request.RemoteAddr = "127.0.0.1:5000"
+	if ip := ClientIP(request.WithContext(ctx), nil); ip != "127.0.0.1" {
+		t.Errorf("Expected forwarding over TCP to be ignored, got %s", ip)
+	}
+
+	// Test connections over a unix domain socket are trusted
+	ctx = ConnContext(context.Background(), addrConn{local: &net.UnixAddr{Name: "/run/journal.sock", Net: "unix"}})
+	request.RemoteAddr = ""
+	forwarded := Forward(request.WithContext(ctx), nil)
+	if forwarded.RemoteAddr != "1.2.3.4:0" {
+		t.Errorf("Expected client forwarded over a unix domain socket, got %s", forwarded.RemoteAddr)
+	}
+}
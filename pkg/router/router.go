@@ -1,13 +1,22 @@
 package router
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net"
 	"net/http"
-	"os"
+	"net/url"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"time"
 
+	"github.com/jamiefdhurst/journal/pkg/assets"
 	"github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
 )
 
 // Server Common interface for HTTP
@@ -15,82 +24,453 @@ type Server interface {
 	ListenAndServe() error
 }
 
-// Route A route contains a method (GET), URI, and a controller
+// Route A route contains a method (GET), the pattern it was registered with, compiled once, the parameters the
+// pattern captures, and a controller, copied afresh for each request it serves
 type Route struct {
 	method     string
-	regexURI   string
+	pattern    string
+	regex      *regexp.Regexp
+	params     []Parameter
 	controller controller.Controller
 }
 
-// Router A router contains routes and links back to the application and implements the ServeHTTP interface
+// Parameter A parameter captured from a path by a route's pattern, and whether its canonical form is lowercase, such as
+// the slug of an entry
+type Parameter struct {
+	Name  string
+	Lower bool
+}
+
+// Middleware Wraps the handling of a request, e.g. to alter it or stop it early
+type Middleware func(next http.Handler) http.Handler
+
+// Router A router contains routes and links back to the application and implements the ServeHTTP interface. When
+// Prepare is set, it adapts the container to each request once middleware has run and before a controller is given it.
+// When ServerErrorController is set, it is run for a controller that panics before writing anything. Requests from
+// TrustedProxies are seen as the client made them, before any middleware runs. Any of Files, such as stylesheets, are
+// served at their own path before a route is matched. Requests served are written to AccessLog when it is set, rather
+// than logged with everything else.
 type Router struct {
-	Container       interface{}
-	Routes          []Route
-	ErrorController controller.Controller
+	AccessLog             *logging.AccessLog
+	Container             interface{}
+	Files                 fs.FS
+	Routes                []Route
+	ErrorController       controller.Controller
+	ServerErrorController controller.Controller
+	TrustedProxies        []*net.IPNet
+	Prepare               func(container interface{}, request *http.Request) interface{}
+	middleware            []Middleware
+	methods               map[string][]int
 }
 
-func (r Router) convertSimpleURIToRegex(uri string) string {
-	uri = strings.Replace(uri, "/", "\\/", -1)
+type contextKey string
+
+const (
+	containerKey contextKey = "container"
+	paramsKey    contextKey = "params"
+)
+
+// MethodOverrideField Field of a posted form naming the method it stands in for, for forms only able to post
+const MethodOverrideField = "_method"
+
+// MethodOverrideHeader Header naming the method a post stands in for, for clients only able to post
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// RequestIDHeader Header carrying the ID of a request, kept from a proxy in front when it gives one and sent back
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern IDs kept from a proxy in front, anything else being replaced
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// overridable Methods a post may stand in for
+var overridable = map[string]bool{http.MethodPut: true, http.MethodDelete: true}
+
+// parameterPattern Matches a parameter within a route's pattern: {name} for a slug, {name:lower} for a slug only ever
+// written in lowercase, {name:int} for an ID, or {name...} for the rest of the path
+var parameterPattern = regexp.MustCompile(`\{(\w+)(:int|:lower|\.\.\.)?\}`)
+
+// WithContainer Return a copy of the request that serves the given container to controllers in place of the router's own
+func WithContainer(request *http.Request, container interface{}) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), containerKey, container))
+}
+
+// Param Get a parameter captured by name from the path of the request being served, or nothing when the route it
+// matched has no parameter of that name
+func Param(request *http.Request, name string) string {
+	params, _ := request.Context().Value(paramsKey).(map[string]string)
+
+	return params[name]
+}
+
+// Compile Turn a pattern, such as /{slug}/history/{id:int}, into the expression matching the paths it describes and the
+// parameters it captures, in order
+func Compile(pattern string) (*regexp.Regexp, []Parameter, error) {
+	expr := strings.Builder{}
+	expr.WriteString("^")
+	params := []Parameter{}
+	seen := map[string]bool{}
+	last := 0
+	for _, match := range parameterPattern.FindAllStringSubmatchIndex(pattern, -1) {
+		literal := pattern[last:match[0]]
+		if strings.ContainsAny(literal, "{}") {
+			return nil, nil, fmt.Errorf("malformed parameter in route %s", pattern)
+		}
+		expr.WriteString(regexp.QuoteMeta(literal))
+
+		name := pattern[match[2]:match[3]]
+		if seen[name] {
+			return nil, nil, fmt.Errorf("parameter %s is repeated in route %s", name, pattern)
+		}
+		seen[name] = true
+
+		kind := ""
+		if match[4] != -1 {
+			kind = pattern[match[4]:match[5]]
+		}
+		params = append(params, Parameter{Name: name, Lower: kind == ":lower"})
+		switch kind {
+		case ":int":
+			expr.WriteString("(\\d+)")
+		case "...":
+			if match[1] != len(pattern) {
+				return nil, nil, fmt.Errorf("parameter %s must end route %s", name, pattern)
+			}
+			expr.WriteString("(.+)")
+		default:
+			expr.WriteString("([\\w\\-]+)")
+		}
+		last = match[1]
+	}
+	if strings.ContainsAny(pattern[last:], "{}") {
+		return nil, nil, fmt.Errorf("malformed parameter in route %s", pattern)
+	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
 
-	// Match slugs
-	uri = strings.Replace(uri, "[%s]", "([\\w\\-]+)", -1)
+	regex, err := regexp.Compile(expr.String())
 
-	// Match IDs
-	uri = strings.Replace(uri, "[%d]", "(\\d+)", -1)
+	return regex, params, err
+}
 
-	// Match anything
-	uri = strings.Replace(uri, "[%a]", "(.+?)", -1)
+// Pattern Get the pattern a route was registered with
+func (r Route) Pattern() string {
+	return r.pattern
+}
 
-	return "^" + uri + "$"
+// Delete Create and add a new route into the router to handle a DELETE request
+func (r *Router) Delete(pattern string, controller controller.Controller) {
+	r.add(http.MethodDelete, pattern, controller)
 }
 
-// Get Create and add a new route into the router to handle a GET request
-func (r *Router) Get(uri string, controller controller.Controller) {
-	r.Routes = append(r.Routes, Route{"GET", r.convertSimpleURIToRegex(uri), controller})
+// Get Create and add a new route into the router to handle a GET request, which also answers HEAD requests
+func (r *Router) Get(pattern string, controller controller.Controller) {
+	r.add(http.MethodGet, pattern, controller)
 }
 
 // Post Create and add a new route into the router to handle a POST request
-func (r *Router) Post(uri string, controller controller.Controller) {
-	r.Routes = append(r.Routes, Route{"POST", r.convertSimpleURIToRegex(uri), controller})
+func (r *Router) Post(pattern string, controller controller.Controller) {
+	r.add(http.MethodPost, pattern, controller)
 }
 
 // Put Create and add a new route into the router to handle a PUT request
-func (r *Router) Put(uri string, controller controller.Controller) {
-	r.Routes = append(r.Routes, Route{"PUT", r.convertSimpleURIToRegex(uri), controller})
+func (r *Router) Put(pattern string, controller controller.Controller) {
+	r.add(http.MethodPut, pattern, controller)
+}
+
+// Use Add a middleware, which runs in the order added before any route is matched
+func (r *Router) Use(middleware Middleware) {
+	r.middleware = append(r.middleware, middleware)
+}
+
+// Mount Serve routes beneath a path prefix, such as /journal, by taking it off the path of each request before
+// routes are matched. The root is sent to the prefix and anything else outside it is not found.
+func Mount(prefix string) Middleware {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(next http.Handler) http.Handler {
+		if prefix == "" {
+			return next
+		}
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			path := strings.TrimPrefix(request.URL.Path, prefix)
+			if path == request.URL.Path || (path != "" && path[0] != '/') {
+				if request.URL.Path == "/" {
+					http.Redirect(response, request, prefix+"/", http.StatusFound)
+					return
+				}
+				http.NotFound(response, request)
+				return
+			}
+			if path == "" {
+				path = "/"
+			}
+
+			mounted := request.WithContext(request.Context())
+			mounted.URL = &url.URL{}
+			*mounted.URL = *request.URL
+			mounted.URL.Path = path
+			mounted.URL.RawPath = ""
+			next.ServeHTTP(response, mounted)
+		})
+	}
 }
 
 // ServeHTTP Serve a given HTTP request
 func (r *Router) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 
-	// Debug output into the console
-	log.Printf("%s: %s", request.Method, request.URL.Path)
+	request = proxy.Forward(request, r.TrustedProxies)
+
+	// Give each request an ID, carried by everything it logs, and log it once served
+	id := request.Header.Get(RequestIDHeader)
+	if !requestIDPattern.MatchString(id) {
+		id = logging.NewRequestID()
+	}
+	response.Header().Set(RequestIDHeader, id)
+	logger := logging.Default().With("request_id", id)
+	request = request.WithContext(logging.NewContext(logging.WithRequestID(request.Context(), id), logger))
+	tracked := &trackedResponse{ResponseWriter: response}
+	start := time.Now()
+	defer func() {
+		status := tracked.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if r.AccessLog != nil {
+			r.AccessLog.Log(logging.Request{ID: id, IP: proxy.ClientIP(request, nil), Method: request.Method, Path: request.URL.Path,
+				Query: request.URL.RawQuery, Proto: request.Proto, Referer: request.Referer(), UserAgent: request.UserAgent(),
+				Status: status, Bytes: tracked.bytes, Time: start, Duration: time.Since(start)})
+			return
+		}
+		logger.Info("Served request", "ip", proxy.ClientIP(request, nil), "method", request.Method, "path", request.URL.Path,
+			"status", status, "bytes", tracked.bytes, "duration_ms", float64(time.Since(start).Microseconds())/1000)
+	}()
+
+	var handler http.Handler = http.HandlerFunc(r.serve)
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	handler.ServeHTTP(tracked, request)
+}
+
+// OverrideMethod Get the request as the method a post stands in for, named by the override header or, for forms that
+// are not uploads, the override field, so that forms can reach PUT and DELETE routes. Uploads are left to controllers
+// limiting their size to read.
+func OverrideMethod(request *http.Request) *http.Request {
+	if request.Method != http.MethodPost {
+		return request
+	}
+	method := request.Header.Get(MethodOverrideHeader)
+	if mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type")); method == "" && mediaType == "application/x-www-form-urlencoded" {
+		method = request.PostFormValue(MethodOverrideField)
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if !overridable[method] {
+		return request
+	}
+
+	overridden := request.WithContext(request.Context())
+	overridden.Method = method
+
+	return overridden
+}
+
+// ContainerFor Get the container serving a request, being any set by middleware or otherwise the router's own
+func (r *Router) ContainerFor(request *http.Request) interface{} {
+	if override := request.Context().Value(containerKey); override != nil {
+		return override
+	}
+
+	return r.Container
+}
+
+func (r *Router) serve(response http.ResponseWriter, request *http.Request) {
+	request = OverrideMethod(request)
+	container := r.ContainerFor(request)
+	if r.Prepare != nil {
+		container = r.Prepare(container, request)
+	}
 
 	// Attempt to serve a file first
-	if request.URL.Path != "/" {
-		file := "web/static" + request.URL.Path
-		_, err := os.Stat(file)
-		if !os.IsNotExist(err) {
-			http.ServeFile(response, request, file)
+	if r.Files != nil && (request.Method == http.MethodGet || request.Method == http.MethodHead) {
+		if (assets.Assets{Files: r.Files}).Serve(response, request, request.URL.Path) {
 			return
 		}
 	}
 
-	// Go through each route and attempt to match
-	for _, route := range r.Routes {
-		matched, _ := regexp.MatchString(route.regexURI, request.URL.Path)
-		if matched && (request.Method == route.method || (request.Method == "" && route.method == "GET")) {
-			re := regexp.MustCompile(route.regexURI)
-			route.controller.Init(r.Container, re.FindStringSubmatch(request.URL.Path))
-			route.controller.Run(response, request)
+	// Go through the routes for the method, in the order added, and attempt to match
+	method := request.Method
+	if method == "" || method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if method == http.MethodGet {
+		if canonical := r.canonical(request.URL.Path); canonical != request.URL.Path {
+			redirect(response, request, canonical)
 			return
 		}
 	}
+	for _, i := range r.methods[method] {
+		route := r.Routes[i]
+		matches := route.regex.FindStringSubmatch(request.URL.Path)
+		if matches == nil {
+			continue
+		}
+		params := map[string]string{}
+		for n, param := range route.params {
+			params[param.Name] = matches[n+1]
+		}
+		tracked := &trackedResponse{ResponseWriter: response}
+		defer r.recover(tracked, request, container)
+		serving := controller.New(route.controller)
+		serving.Init(container, matches)
+		serving.Run(tracked, request.WithContext(context.WithValue(request.Context(), paramsKey, params)))
+		return
+	}
+
+	// Refuse methods a path is not served with, rather than not finding it
+	if allowed := r.allowed(request.URL.Path); len(allowed) > 0 {
+		response.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(response, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
 
-	r.ErrorController.Init(r.Container, []string{})
-	r.ErrorController.Run(response, request)
+	notFound := controller.New(r.ErrorController)
+	notFound.Init(container, []string{})
+	notFound.Run(response, request)
 }
 
 // StartAndServe Start the HTTP server and listen for connections
 func (r *Router) StartAndServe(server Server) error {
 	return server.ListenAndServe()
 }
+
+// add Compile a route's pattern once, as it is registered, and index it by method, failing loudly on a malformed
+// pattern so that it cannot go unnoticed until a request reaches it
+func (r *Router) add(method string, pattern string, controller controller.Controller) {
+	regex, params, err := Compile(pattern)
+	if err != nil {
+		panic("router: " + err.Error())
+	}
+	if r.methods == nil {
+		r.methods = map[string][]int{}
+	}
+	r.Routes = append(r.Routes, Route{method, pattern, regex, params, controller})
+	r.methods[method] = append(r.methods[method], len(r.Routes)-1)
+}
+
+// allowed Get the methods a path is served with, in a stable order, with HEAD alongside GET
+func (r *Router) allowed(path string) []string {
+	allowed := []string{}
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		for _, i := range r.methods[method] {
+			if r.Routes[i].regex.MatchString(path) {
+				allowed = append(allowed, method)
+				if method == http.MethodGet {
+					allowed = append(allowed, http.MethodHead)
+				}
+				break
+			}
+		}
+	}
+
+	return allowed
+}
+
+// recover Log the stack of a controller that panicked and answer with the server error page, or abort the response
+// when the controller had already started writing it, rather than sending what looks like a whole page
+func (r *Router) recover(response *trackedResponse, request *http.Request, container interface{}) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+	logging.FromContext(request.Context()).Error("Panic serving request", "method", request.Method, "path", request.URL.Path,
+		"panic", fmt.Sprint(err), "stack", string(debug.Stack()))
+
+	if response.written {
+		panic(http.ErrAbortHandler)
+	}
+	if r.ServerErrorController == nil {
+		http.Error(response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	serverError := controller.New(r.ServerErrorController)
+	serverError.Init(container, []string{})
+	serverError.Run(response, request)
+}
+
+// trackedResponse Notes whether a response has been started, with what status, and how much of its body was written
+type trackedResponse struct {
+	http.ResponseWriter
+	written bool
+	status  int
+	bytes   int
+}
+
+// Write Write to the response, noting it as started
+func (t *trackedResponse) Write(b []byte) (int, error) {
+	if !t.written {
+		t.status = http.StatusOK
+	}
+	t.written = true
+	n, err := t.ResponseWriter.Write(b)
+	t.bytes += n
+
+	return n, err
+}
+
+// WriteHeader Write the status code, noting the response as started
+func (t *trackedResponse) WriteHeader(statusCode int) {
+	if !t.written {
+		t.status = statusCode
+	}
+	t.written = true
+	t.ResponseWriter.WriteHeader(statusCode)
+}
+
+// canonical Get the path a page is served at when asked for with a trailing slash or with capitals in a slug only ever
+// written in lowercase, or the path as it is when it is already canonical or no page is served at it
+func (r *Router) canonical(path string) string {
+	trimmed := path
+	if path != "/" {
+		trimmed = strings.TrimRight(path, "/")
+	}
+	if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+		return path
+	}
+
+	for _, i := range r.methods[http.MethodGet] {
+		route := r.Routes[i]
+		matches := route.regex.FindStringSubmatchIndex(trimmed)
+		if matches == nil {
+			continue
+		}
+		canonical := strings.Builder{}
+		last := 0
+		for n, param := range route.params {
+			start, end := matches[2*n+2], matches[2*n+3]
+			if !param.Lower {
+				continue
+			}
+			canonical.WriteString(trimmed[last:start])
+			canonical.WriteString(strings.ToLower(trimmed[start:end]))
+			last = end
+		}
+		canonical.WriteString(trimmed[last:])
+
+		return canonical.String()
+	}
+
+	return path
+}
+
+// redirect Permanently send a request to another path within the journal, keeping its query and any prefix middleware
+// took from its path, such as the name of the journal being hosted
+func redirect(response http.ResponseWriter, request *http.Request, path string) {
+	if original, err := url.ParseRequestURI(request.RequestURI); err == nil && strings.HasSuffix(original.Path, request.URL.Path) {
+		path = strings.TrimSuffix(original.Path, request.URL.Path) + path
+	}
+	target := url.URL{Path: path, RawQuery: request.URL.RawQuery}
+
+	http.Redirect(response, request, target.String(), http.StatusMovedPermanently)
+}
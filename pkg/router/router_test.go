@@ -1,30 +1,47 @@
 package router
 
 import (
+	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
-	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	pkgcontroller "github.com/jamiefdhurst/journal/pkg/controller"
+	"github.com/jamiefdhurst/journal/pkg/logging"
+	"github.com/jamiefdhurst/journal/pkg/proxy"
 	"github.com/jamiefdhurst/journal/test/mocks/controller"
 	mockrouter "github.com/jamiefdhurst/journal/test/mocks/router"
 )
 
 type BlankContainer struct{}
 
+func TestDelete(t *testing.T) {
+	ctrl := &controller.MockController{}
+	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: ctrl}
+
+	// Test normal route
+	router.Delete("/testing", ctrl)
+	if router.Routes[0].controller != ctrl || router.Routes[0].method != "DELETE" || router.Routes[0].pattern != "/testing" || router.Routes[0].regex.String() != "^/testing$" {
+		t.Errorf("DELETE Route added was not as expected")
+	}
+}
+
 func TestGet(t *testing.T) {
 	ctrl := &controller.MockController{}
 	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: ctrl}
 
 	// Test normal route
 	router.Get("/testing", ctrl)
-	if router.Routes[0].controller != ctrl || router.Routes[0].method != "GET" || router.Routes[0].regexURI != "^\\/testing$" {
+	if router.Routes[0].controller != ctrl || router.Routes[0].method != "GET" || router.Routes[0].pattern != "/testing" || router.Routes[0].regex.String() != "^/testing$" {
 		t.Errorf("GET Route added was not as expected")
 	}
 
 	// Test paramterised route
-	router.Get("/[%s]/[%d]/[%a]", ctrl)
-	if router.Routes[1].regexURI != "^\\/([\\w\\-]+)\\/(\\d+)\\/(.+?)$" {
+	router.Get("/{slug}/{id:int}/{path...}", ctrl)
+	if router.Routes[1].regex.String() != "^/([\\w\\-]+)/(\\d+)/(.+)$" || router.Routes[1].params[2].Name != "path" {
 		t.Errorf("GET Route added was not as expected")
 	}
 }
@@ -35,13 +52,13 @@ func TestPost(t *testing.T) {
 
 	// Test normal route
 	router.Post("/testing", ctrl)
-	if router.Routes[0].controller != ctrl || router.Routes[0].method != "POST" || router.Routes[0].regexURI != "^\\/testing$" {
+	if router.Routes[0].controller != ctrl || router.Routes[0].method != "POST" || router.Routes[0].pattern != "/testing" || router.Routes[0].regex.String() != "^/testing$" {
 		t.Errorf("GET Route added was not as expected")
 	}
 
 	// Test paramterised route
-	router.Post("/[%s]/[%d]/[%a]", ctrl)
-	if router.Routes[1].regexURI != "^\\/([\\w\\-]+)\\/(\\d+)\\/(.+?)$" {
+	router.Post("/{slug}/{id:int}/{path...}", ctrl)
+	if router.Routes[1].regex.String() != "^/([\\w\\-]+)/(\\d+)/(.+)$" || router.Routes[1].params[2].Name != "path" {
 		t.Errorf("GET Route added was not as expected")
 	}
 }
@@ -52,13 +69,13 @@ func TestPut(t *testing.T) {
 
 	// Test normal route
 	router.Put("/testing", ctrl)
-	if router.Routes[0].controller != ctrl || router.Routes[0].method != "PUT" || router.Routes[0].regexURI != "^\\/testing$" {
+	if router.Routes[0].controller != ctrl || router.Routes[0].method != "PUT" || router.Routes[0].pattern != "/testing" || router.Routes[0].regex.String() != "^/testing$" {
 		t.Errorf("GET Route added was not as expected")
 	}
 
 	// Test paramterised route
-	router.Put("/[%s]/[%d]/[%a]", ctrl)
-	if router.Routes[1].regexURI != "^\\/([\\w\\-]+)\\/(\\d+)\\/(.+?)$" {
+	router.Put("/{slug}/{id:int}/{path...}", ctrl)
+	if router.Routes[1].regex.String() != "^/([\\w\\-]+)/(\\d+)/(.+)$" || router.Routes[1].params[2].Name != "path" {
 		t.Errorf("GET Route added was not as expected")
 	}
 }
@@ -69,19 +86,17 @@ func TestServeHTTP(t *testing.T) {
 	standardController := &controller.MockController{}
 	paramController := &controller.MockController{}
 	response := controller.NewMockResponse()
-	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: errorController}
+	files := fstest.MapFS{"css/default.min.css": &fstest.MapFile{Data: []byte("body{color:red}")}}
+	router := Router{Container: &BlankContainer{}, Files: files, Routes: []Route{}, ErrorController: errorController}
 	router.Get("/standard", standardController)
-	router.Get("/param/[%s]", paramController)
+	router.Get("/param/{slug}", paramController)
 	router.Get("/", indexController)
 
-	// Set CWD
-	os.Chdir(os.Getenv("GOPATH") + "/src/github.com/jamiefdhurst/journal")
-
 	// Serve static file
 	staticURL := &url.URL{Path: "/css/default.min.css"}
-	staticRequest := &http.Request{URL: staticURL, Method: "GET"}
+	staticRequest := &http.Request{URL: staticURL, Method: "GET", Header: http.Header{}}
 	router.ServeHTTP(response, staticRequest)
-	if errorController.HasRun {
+	if errorController.HasRun || response.Content != "body{color:red}" {
 		t.Errorf("Expected static file to have been served but error controller was run")
 	}
 
@@ -109,6 +124,20 @@ func TestServeHTTP(t *testing.T) {
 		t.Errorf("Expected param controller to have been served but error controller was run")
 	}
 
+	// HEAD is answered by GET routes
+	standardController.HasRun = false
+	router.ServeHTTP(response, &http.Request{URL: standardURL, Method: "HEAD"})
+	if !standardController.HasRun || errorController.HasRun {
+		t.Errorf("Expected standard controller to have answered a HEAD request")
+	}
+
+	// Wrong method
+	response.Reset()
+	router.ServeHTTP(response, &http.Request{URL: standardURL, Method: "POST"})
+	if response.StatusCode != http.StatusMethodNotAllowed || response.Headers.Get("Allow") != "GET, HEAD" || errorController.HasRun {
+		t.Errorf("Expected method to be refused with the methods allowed, got %d and '%s'", response.StatusCode, response.Headers.Get("Allow"))
+	}
+
 	// Not found route
 	notFoundURL := &url.URL{Path: "/random"}
 	notFoundRequest := &http.Request{URL: notFoundURL, Method: "GET"}
@@ -118,6 +147,289 @@ func TestServeHTTP(t *testing.T) {
 	}
 }
 
+func TestCompile(t *testing.T) {
+	tables := []struct {
+		pattern string
+		path    string
+		params  []string
+	}{
+		{"/", "/", []string{}},
+		{"/robots.txt", "/robots.txt", []string{}},
+		{"/robots.txt", "/robotsxtxt", nil},
+		{"/{slug}", "/an-entry_1", []string{"an-entry_1"}},
+		{"/{slug}", "/an/entry", nil},
+		{"/{key}.txt", "/abc123.txt", []string{"abc123"}},
+		{"/{slug}/history/{id:int}", "/entry/history/12", []string{"entry", "12"}},
+		{"/{slug}/history/{id:int}", "/entry/history/twelve", nil},
+		{"/media/{path...}", "/media/2024/01/image.png", []string{"2024/01/image.png"}},
+	}
+
+	for _, table := range tables {
+		regex, _, err := Compile(table.pattern)
+		if err != nil {
+			t.Fatalf("Expected %s to compile, got %s", table.pattern, err)
+		}
+		matches := regex.FindStringSubmatch(table.path)
+		if table.params == nil {
+			if matches != nil {
+				t.Errorf("Expected %s not to match %s", table.pattern, table.path)
+			}
+			continue
+		}
+		if matches == nil || strings.Join(matches[1:], ",") != strings.Join(table.params, ",") {
+			t.Errorf("Expected %s to match %s with %v, got %v", table.pattern, table.path, table.params, matches)
+		}
+	}
+
+	for _, pattern := range []string{"/{slug}/{slug}", "/{path...}/edit", "/{slug", "/{bad-name}"} {
+		if _, _, err := Compile(pattern); err == nil {
+			t.Errorf("Expected %s to be refused", pattern)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a malformed route to be refused when added")
+		}
+	}()
+	router := Router{}
+	router.Get("/{slug", &controller.MockController{})
+}
+
+type paramController struct {
+	controller.MockController
+	slug string
+	id   string
+}
+
+func (c *paramController) Clone() pkgcontroller.Controller {
+	return c
+}
+
+func (c *paramController) Run(response http.ResponseWriter, request *http.Request) {
+	c.slug = Param(request, "slug")
+	c.id = Param(request, "id")
+}
+
+func TestParam(t *testing.T) {
+	ctrl := &paramController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/{slug}/history/{id:int}", ctrl)
+	router.ServeHTTP(controller.NewMockResponse(), &http.Request{URL: &url.URL{Path: "/entry/history/3"}, Method: "GET"})
+	if ctrl.slug != "entry" || ctrl.id != "3" {
+		t.Errorf("Expected named parameters to be given to the controller, got '%s' and '%s'", ctrl.slug, ctrl.id)
+	}
+
+	if Param(&http.Request{URL: &url.URL{Path: "/"}}, "slug") != "" {
+		t.Error("Expected no parameter outside a route")
+	}
+}
+
+type panicController struct {
+	controller.MockController
+	write bool
+}
+
+func (c *panicController) Clone() pkgcontroller.Controller {
+	return c
+}
+
+func (c *panicController) Run(response http.ResponseWriter, request *http.Request) {
+	if c.write {
+		response.Write([]byte("Partial"))
+	}
+	var template *struct{ Name string }
+	_ = template.Name
+}
+
+func TestServeHTTP_Panic(t *testing.T) {
+	serverError := &controller.MockController{}
+	ctrl := &panicController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/", ctrl)
+	request := &http.Request{URL: &url.URL{Path: "/"}, Method: "GET"}
+
+	// Without a page of its own, a plain error is given
+	response := controller.NewMockResponse()
+	router.ServeHTTP(response, request)
+	if response.StatusCode != http.StatusInternalServerError || !strings.Contains(response.Content, "Internal Server Error") {
+		t.Errorf("Expected plain server error, got %d", response.StatusCode)
+	}
+
+	// The server error controller is given the request's container
+	router.ServerErrorController = serverError
+	router.ServeHTTP(controller.NewMockResponse(), request)
+	if !serverError.HasRun || serverError.Container != router.Container {
+		t.Error("Expected server error controller to have been run")
+	}
+
+	// A response already started is aborted rather than completed
+	serverError.HasRun = false
+	ctrl.write = true
+	defer func() {
+		if recover() != http.ErrAbortHandler || serverError.HasRun {
+			t.Error("Expected partly written response to be aborted")
+		}
+	}()
+	router.ServeHTTP(controller.NewMockResponse(), request)
+}
+
+type freshController struct {
+	pkgcontroller.Super
+	served chan *freshController
+}
+
+func (c *freshController) Run(response http.ResponseWriter, request *http.Request) {
+	c.served <- c
+}
+
+func TestServeHTTP_Fresh(t *testing.T) {
+	registered := &freshController{served: make(chan *freshController, 2)}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/{slug}", registered)
+
+	done := make(chan bool)
+	for _, slug := range []string{"first", "second"} {
+		go func(slug string) {
+			router.ServeHTTP(controller.NewMockResponse(), &http.Request{URL: &url.URL{Path: "/" + slug}, Method: "GET"})
+			done <- true
+		}(slug)
+	}
+	<-done
+	<-done
+	first, second := <-registered.served, <-registered.served
+	if first == second || first == registered || second == registered || registered.Params != nil {
+		t.Error("Expected each request to be served by its own controller, leaving the registered one untouched")
+	}
+	if first.Params[1] == second.Params[1] {
+		t.Errorf("Expected each controller to keep its own parameters, got %v and %v", first.Params, second.Params)
+	}
+}
+
+func TestOverrideMethod(t *testing.T) {
+	tables := []struct {
+		method      string
+		header      string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{"POST", "", "application/x-www-form-urlencoded", "_method=delete&title=Test", "DELETE"},
+		{"POST", "PUT", "application/json", "{}", "PUT"},
+		{"POST", "", "multipart/form-data; boundary=x", "_method=DELETE", "POST"},
+		{"POST", "", "application/x-www-form-urlencoded", "_method=GET", "POST"},
+		{"POST", "", "application/x-www-form-urlencoded", "title=Test", "POST"},
+		{"GET", "DELETE", "", "", "GET"},
+	}
+
+	for _, table := range tables {
+		request, _ := http.NewRequest(table.method, "/test", strings.NewReader(table.body))
+		request.Header.Set("Content-Type", table.contentType)
+		if table.header != "" {
+			request.Header.Set(MethodOverrideHeader, table.header)
+		}
+		if actual := OverrideMethod(request).Method; actual != table.expected {
+			t.Errorf("Expected %s with %s to be served as %s, got %s", table.method, table.body, table.expected, actual)
+		}
+		if request.Method != table.method {
+			t.Error("Expected original request to be left as it was")
+		}
+	}
+
+	// Overridden posts reach routes for the method they stand in for, keeping the rest of their form
+	deleteController := &controller.MockController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Delete("/test", deleteController)
+	request, _ := http.NewRequest("POST", "/test", strings.NewReader("_method=DELETE&title=Test"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(controller.NewMockResponse(), request)
+	if !deleteController.HasRun || deleteController.Request.PostFormValue("title") != "Test" {
+		t.Error("Expected overridden post to reach the DELETE route with its form")
+	}
+}
+
+func TestServeHTTP_Canonical(t *testing.T) {
+	ctrl := &controller.MockController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/author/{username}", ctrl)
+	router.Get("/{slug:lower}/history", ctrl)
+	router.Post("/{slug:lower}/edit", ctrl)
+	router.Get("/{slug:lower}", ctrl)
+
+	tables := []struct {
+		method     string
+		requestURI string
+		path       string
+		location   string
+	}{
+		{"GET", "/test/", "/test/", "/test"},
+		{"GET", "/Test/History?page=2", "/Test/History", ""},
+		{"GET", "/Test/history?page=2", "/Test/history", "/test/history?page=2"},
+		{"HEAD", "/TEST//", "/TEST//", "/test"},
+		{"GET", "/jamie/Test/", "/Test/", "/jamie/test"},
+		{"GET", "/author/Jamie", "/author/Jamie", ""},
+		{"GET", "/test", "/test", ""},
+		{"GET", "/", "/", ""},
+		{"GET", "//example.com/", "//example.com/", ""},
+		{"POST", "/Test/edit", "/Test/edit", ""},
+	}
+
+	for _, table := range tables {
+		response := controller.NewMockResponse()
+		request := &http.Request{Method: table.method, RequestURI: table.requestURI, URL: &url.URL{Path: table.path}}
+		if parsed, err := url.ParseRequestURI(table.requestURI); err == nil {
+			request.URL.RawQuery = parsed.RawQuery
+		}
+		router.ServeHTTP(response, request)
+		location := response.Headers.Get("Location")
+		if location != table.location || (location != "" && response.StatusCode != http.StatusMovedPermanently) {
+			t.Errorf("Expected %s %s to be sent to '%s', got %d and '%s'", table.method, table.requestURI, table.location, response.StatusCode, location)
+		}
+	}
+}
+
+type requestController struct {
+	controller.MockController
+	request *http.Request
+}
+
+func (c *requestController) Clone() pkgcontroller.Controller {
+	return c
+}
+
+func (c *requestController) Run(response http.ResponseWriter, request *http.Request) {
+	c.request = request
+}
+
+func TestServeHTTP_TrustedProxies(t *testing.T) {
+	ctrl := &requestController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}, TrustedProxies: proxy.ParseProxies("10.0.0.0/8")}
+	router.Get("/", ctrl)
+	var seen *http.Request
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = r
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	request, _ := http.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "10.0.0.1:5000"
+	request.Header.Set("X-Forwarded-For", "1.2.3.4")
+	request.Header.Set("X-Forwarded-Host", "journal.example.com")
+	request.Header.Set("X-Forwarded-Proto", "https")
+	router.ServeHTTP(controller.NewMockResponse(), request)
+	if seen == nil || seen.RemoteAddr != "1.2.3.4:5000" || ctrl.request.Host != "journal.example.com" || !proxy.Secure(ctrl.request) {
+		t.Error("Expected middleware and controller to see the request as the client made it")
+	}
+
+	request.RemoteAddr = "6.6.6.6:5000"
+	router.ServeHTTP(controller.NewMockResponse(), request)
+	if seen.RemoteAddr != "6.6.6.6:5000" || ctrl.request.Host == "journal.example.com" {
+		t.Error("Expected forwarded headers from anywhere else to be ignored")
+	}
+}
+
 func TestStartAndServe(t *testing.T) {
 	ctrl := &controller.MockController{}
 	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: ctrl}
@@ -128,3 +440,208 @@ func TestStartAndServe(t *testing.T) {
 		t.Errorf("Expected some routes to have been defined but none were found")
 	}
 }
+
+func TestUse(t *testing.T) {
+	errorController := &controller.MockController{}
+	indexController := &controller.MockController{}
+	response := controller.NewMockResponse()
+	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: errorController}
+	router.Get("/", indexController)
+
+	// Middleware run in order and can swap the container
+	order := ""
+	override := &struct{ Name string }{"override"}
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order += "a"
+			next.ServeHTTP(w, WithContainer(r, override))
+		})
+	})
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order += "b"
+			if router.ContainerFor(r) != override {
+				t.Error("Expected container set by earlier middleware to be found")
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	request := &http.Request{URL: &url.URL{Path: "/"}, Method: "GET"}
+	router.ServeHTTP(response, request)
+	if order != "ab" || !indexController.HasRun {
+		t.Errorf("Expected middleware to run in order before the controller, got '%s'", order)
+	}
+	if indexController.Container != override {
+		t.Error("Expected container from middleware to have been passed to the controller")
+	}
+
+	// Middleware can stop the request
+	indexController.HasRun = false
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+	})
+	router.ServeHTTP(response, request)
+	if indexController.HasRun || response.StatusCode != http.StatusForbidden {
+		t.Error("Expected middleware to have stopped the request")
+	}
+}
+
+func TestMount(t *testing.T) {
+	ctrl := &requestController{}
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/", ctrl)
+	router.Get("/{slug:lower}", ctrl)
+	router.Use(Mount("/journal/"))
+
+	tables := []struct {
+		requestURI string
+		status     int
+		path       string
+		location   string
+	}{
+		{"/journal", http.StatusOK, "/", ""},
+		{"/journal/", http.StatusOK, "/", ""},
+		{"/journal/test", http.StatusOK, "/test", ""},
+		{"/journal/Test/?page=2", http.StatusMovedPermanently, "", "/journal/test?page=2"},
+		{"/", http.StatusFound, "", "/journal/"},
+		{"/test", http.StatusNotFound, "", ""},
+		{"/journalist", http.StatusNotFound, "", ""},
+	}
+
+	for _, table := range tables {
+		ctrl.request = nil
+		response := controller.NewMockResponse()
+		request, _ := http.NewRequest("GET", table.requestURI, nil)
+		request.RequestURI = table.requestURI
+		router.ServeHTTP(response, request)
+		status := response.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status != table.status || response.Headers.Get("Location") != table.location {
+			t.Errorf("Expected %s to give %d and '%s', got %d and '%s'", table.requestURI, table.status, table.location, status, response.Headers.Get("Location"))
+		}
+		if table.path != "" && (ctrl.request == nil || ctrl.request.URL.Path != table.path) {
+			t.Errorf("Expected %s to be served as %s", table.requestURI, table.path)
+		}
+		if request.URL.Path != strings.Split(table.requestURI, "?")[0] {
+			t.Errorf("Expected the original request to be left as it was, got %s", request.URL.Path)
+		}
+	}
+
+	// Without a prefix requests pass straight through
+	var seen *http.Request
+	request, _ := http.NewRequest("GET", "/test", nil)
+	Mount("/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+	})).ServeHTTP(controller.NewMockResponse(), request)
+	if seen != request {
+		t.Error("Expected requests to pass straight through without a prefix")
+	}
+}
+
+func TestContainerFor(t *testing.T) {
+	container := &BlankContainer{}
+	router := Router{Container: container}
+	request := &http.Request{URL: &url.URL{Path: "/"}, Method: "GET"}
+	if router.ContainerFor(request) != container {
+		t.Error("Expected router's own container without an override")
+	}
+
+	override := &struct{ Name string }{"override"}
+	if router.ContainerFor(WithContainer(request, override)) != override {
+		t.Error("Expected overriding container to be returned")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	indexController := &controller.MockController{}
+	response := controller.NewMockResponse()
+	router := Router{Container: &BlankContainer{}, Routes: []Route{}, ErrorController: &controller.MockController{}}
+	router.Get("/", indexController)
+
+	prepared := &struct{ Name string }{"prepared"}
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, WithContainer(r, &struct{ Name string }{"override"}))
+		})
+	})
+	router.Prepare = func(container interface{}, request *http.Request) interface{} {
+		if container.(*struct{ Name string }).Name != "override" {
+			t.Error("Expected container to be prepared after middleware has run")
+		}
+		return prepared
+	}
+	router.ServeHTTP(response, &http.Request{URL: &url.URL{Path: "/"}, Method: "GET"})
+	if indexController.Container != prepared {
+		t.Error("Expected prepared container to have been passed to the controller")
+	}
+}
+
+type loggingController struct {
+	pkgcontroller.Super
+}
+
+func (c *loggingController) Run(response http.ResponseWriter, request *http.Request) {
+	logging.FromContext(request.Context()).Info("Serving", "id", logging.RequestID(request.Context()))
+	response.WriteHeader(http.StatusAccepted)
+	response.Write([]byte("Hello"))
+}
+
+func TestServeHTTP_RequestID(t *testing.T) {
+	output := &bytes.Buffer{}
+	original := logging.Default()
+	logging.SetDefault(logging.New(output, logging.FormatText, logging.LevelInfo))
+	defer logging.SetDefault(original)
+
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}}
+	router.Get("/", &loggingController{})
+
+	// Test an ID is given to each request, carried by what it logs, and logged with it once served
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	id := response.Header().Get(RequestIDHeader)
+	if len(id) != 16 {
+		t.Fatalf("Expected an ID to be given, got '%s'", id)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `msg=Serving request_id=`+id+` id=`+id) ||
+		!strings.Contains(lines[1], `msg="Served request" request_id=`+id+` ip=192.0.2.1 method=GET path=/ status=202 bytes=5 duration_ms=`) {
+		t.Errorf("Expected the request to be logged with its ID, got %v", lines)
+	}
+
+	// Test an ID given by a proxy in front is kept, unless it is not fit to log
+	for given, kept := range map[string]bool{"abc-123.def": true, "bad id\n": false} {
+		response = httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(RequestIDHeader, given)
+		router.ServeHTTP(response, request)
+		if (response.Header().Get(RequestIDHeader) == given) != kept {
+			t.Errorf("Expected ID '%s' to be kept: %v, got '%s'", given, kept, response.Header().Get(RequestIDHeader))
+		}
+	}
+}
+
+func TestServeHTTP_AccessLog(t *testing.T) {
+	output := &bytes.Buffer{}
+	access := &bytes.Buffer{}
+	original := logging.Default()
+	logging.SetDefault(logging.New(output, logging.FormatText, logging.LevelInfo))
+	defer logging.SetDefault(original)
+
+	router := Router{Container: &BlankContainer{}, ErrorController: &controller.MockController{}, AccessLog: logging.NewAccessLog(access, logging.FormatCombined)}
+	router.Get("/", &loggingController{})
+
+	// Test requests are written to the access log rather than with everything else
+	request := httptest.NewRequest("GET", "/?page=2", nil)
+	request.Header.Set("Referer", "https://example.com/")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	if !strings.HasPrefix(access.String(), `192.0.2.1 - - [`) || !strings.HasSuffix(access.String(), `] "GET /?page=2 HTTP/1.1" 202 5 "https://example.com/" "-"`+"\n") {
+		t.Errorf("Expected request to be written to the access log, got '%s'", access.String())
+	}
+	if strings.Contains(output.String(), "Served request") || !strings.Contains(output.String(), "msg=Serving") {
+		t.Errorf("Expected only what the request logs itself with everything else, got '%s'", output.String())
+	}
+}
@@ -0,0 +1,306 @@
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+// Policy Which elements, attributes and link schemes are kept when sanitizing HTML, dropping everything else
+type Policy struct {
+	Elements   map[string][]string
+	Attributes []string
+	Schemes    []string
+	Rel        string
+}
+
+// Entry Policy for the content of entries, keeping the formatting Markdown and the editor produce along with tables,
+// figures and images
+var Entry = Policy{
+	Elements: map[string][]string{
+		"a": {"href", "rel"}, "abbr": {}, "b": {}, "blockquote": {"cite"}, "br": {}, "caption": {}, "cite": {},
+		"code": {}, "dd": {}, "del": {}, "details": {"open"}, "dfn": {}, "div": {}, "dl": {}, "dt": {}, "em": {},
+		"figcaption": {}, "figure": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {}, "hr": {},
+		"i": {}, "img": {"src", "alt", "width", "height", "loading"}, "ins": {}, "kbd": {}, "li": {}, "mark": {},
+		"ol": {"start", "reversed"}, "p": {}, "pre": {}, "q": {"cite"}, "s": {}, "samp": {}, "small": {}, "span": {},
+		"strong": {}, "sub": {}, "summary": {}, "sup": {}, "table": {}, "tbody": {}, "td": {"colspan", "rowspan"},
+		"tfoot": {}, "th": {"colspan", "rowspan", "scope"}, "thead": {}, "time": {"datetime"}, "tr": {}, "u": {},
+		"ul": {}, "var": {},
+	},
+	Attributes: []string{"class", "dir", "lang", "title"},
+	Schemes:    []string{"http", "https", "mailto", "tel"},
+}
+
+// Comment Policy for comments, keeping simple formatting and links, which are marked as left by readers
+var Comment = Policy{
+	Elements: map[string][]string{
+		"a": {"href"}, "b": {}, "blockquote": {}, "br": {}, "code": {}, "del": {}, "em": {}, "i": {}, "li": {},
+		"ol": {}, "p": {}, "pre": {}, "q": {}, "s": {}, "strong": {}, "ul": {},
+	},
+	Schemes: []string{"http", "https", "mailto"},
+	Rel:     "nofollow ugc noopener",
+}
+
+// voidElements Elements that never have content or an end tag
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true, "wbr": true}
+
+// rawElements Elements whose content is not markup, so is skipped over whole when they are dropped
+var rawElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true, "plaintext": true, "script": true,
+	"style": true, "textarea": true, "title": true, "xmp": true,
+}
+
+// urlAttributes Attributes holding an address, which must be relative or use one of the policy's schemes
+var urlAttributes = map[string]bool{"cite": true, "href": true, "src": true}
+
+type attribute struct {
+	name  string
+	value string
+}
+
+// Sanitize Keep only the elements and attributes the policy allows, escaping all text and closing every element left
+// open, so the result can be placed within a page without running scripts or escaping the element it is placed in
+func (p Policy) Sanitize(s string) string {
+	var b strings.Builder
+	open := []string{}
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			end := strings.IndexByte(s[i:], '<')
+			if end == -1 {
+				end = len(s) - i
+			}
+			b.WriteString(escapeText(s[i : i+end]))
+			i += end
+			continue
+		}
+
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end == -1 {
+				return closeAll(&b, open)
+			}
+			i += 4 + end + 3
+			continue
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end == -1 {
+				return closeAll(&b, open)
+			}
+			i += end + 1
+			continue
+		}
+
+		name, attributes, closing, length := readTag(rest)
+		if length == 0 {
+			b.WriteString("&lt;")
+			i++
+			continue
+		}
+		i += length
+
+		allowed, ok := p.Elements[name]
+		switch {
+		case !ok && !closing && rawElements[name]:
+			i += skipRaw(s[i:], name)
+		case !ok:
+		case closing:
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == name {
+					for k := len(open) - 1; k >= j; k-- {
+						b.WriteString("</" + open[k] + ">")
+					}
+					open = open[:j]
+					break
+				}
+			}
+		default:
+			b.WriteString("<" + name)
+			for _, a := range attributes {
+				if a.name == "rel" && p.Rel != "" {
+					continue
+				}
+				if p.allows(a, allowed) {
+					b.WriteString(" " + a.name + "=\"" + html.EscapeString(a.value) + "\"")
+				}
+			}
+			if name == "a" && p.Rel != "" {
+				b.WriteString(" rel=\"" + p.Rel + "\"")
+			}
+			if voidElements[name] {
+				b.WriteString(" />")
+			} else {
+				b.WriteString(">")
+				open = append(open, name)
+			}
+		}
+	}
+
+	return closeAll(&b, open)
+}
+
+// allows Whether an attribute may be kept on an element allowing the given attributes, as long as any address it holds
+// uses an allowed scheme
+func (p Policy) allows(a attribute, allowed []string) bool {
+	if !contains(allowed, a.name) && !contains(p.Attributes, a.name) {
+		return false
+	}
+	if !urlAttributes[a.name] {
+		return true
+	}
+
+	return p.allowsURL(a.value)
+}
+
+// allowsURL Whether an address is relative or uses an allowed scheme, ignoring the characters browsers ignore in one
+func (p Policy) allowsURL(address string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, address)
+	colon := strings.IndexByte(cleaned, ':')
+	if colon == -1 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return true
+	}
+
+	return contains(p.Schemes, strings.ToLower(cleaned[:colon]))
+}
+
+// readTag Read the start or end tag at the start of a string, giving its lower case name, its attributes, whether it
+// is an end tag and how long it is, which is 0 when the string does not start with a complete tag
+func readTag(s string) (string, []attribute, bool, int) {
+	i := 1
+	closing := false
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	if i == start || !isLetter(s[start]) {
+		return "", nil, false, 0
+	}
+	name := strings.ToLower(s[start:i])
+
+	attributes := []attribute{}
+	for i < len(s) {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return name, attributes, closing, i + 1
+		}
+
+		start = i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a := attribute{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end == -1 {
+					break
+				}
+				a.value = html.UnescapeString(s[i+1 : i+1+end])
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				a.value = html.UnescapeString(s[start:i])
+			}
+		}
+		if a.name != "" && !hasAttribute(attributes, a.name) {
+			attributes = append(attributes, a)
+		}
+	}
+
+	return "", nil, false, 0
+}
+
+// skipRaw Find how far the content of a dropped element whose content is not markup runs, past its end tag
+func skipRaw(s string, name string) int {
+	end := 0
+	for {
+		next := strings.Index(s[end:], "</")
+		if next == -1 {
+			return len(s)
+		}
+		end += next
+		if len(s) >= end+2+len(name) && strings.EqualFold(s[end+2:end+2+len(name)], name) {
+			break
+		}
+		end += 2
+	}
+	gt := strings.IndexByte(s[end:], '>')
+	if gt == -1 {
+		return len(s)
+	}
+
+	return end + gt + 1
+}
+
+// escapeText Escape text so that it cannot start any markup, keeping the characters its entities stand for
+func escapeText(text string) string {
+	text = html.UnescapeString(text)
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+
+	return strings.ReplaceAll(text, ">", "&gt;")
+}
+
+func closeAll(b *strings.Builder, open []string) string {
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+
+	return b.String()
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAttribute(attributes []attribute, name string) bool {
+	for _, a := range attributes {
+		if a.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,50 @@
+package sanitize
+
+import "testing"
+
+func TestPolicy_Sanitize(t *testing.T) {
+	tables := []struct {
+		input  string
+		output string
+	}{
+		{"", ""},
+		{"Plain text", "Plain text"},
+		{"<p>Fish &amp; chips &copy; 2 &lt; 3</p>", "<p>Fish &amp; chips © 2 &lt; 3</p>"},
+		{"<p>Hello <script>alert(1)</script>world</p>", "<p>Hello world</p>"},
+		{"<SCRIPT>alert('</p>')</SCRIPT >after", "after"},
+		{"<style>p{color:red}</style><p>Red</p>", "<p>Red</p>"},
+		{"<p onclick=\"alert(1)\" class='lead' style=\"color:red\">Hi</p>", "<p class=\"lead\">Hi</p>"},
+		{"<img src=x onerror=alert(1)>", "<img src=\"x\" />"},
+		{"<a href=\"javascript:alert(1)\">Click</a>", "<a>Click</a>"},
+		{"<a href=\"java&#x09;script:alert(1)\">Click</a>", "<a>Click</a>"},
+		{"<a href=\" JAVASCRIPT:alert(1)\">Click</a>", "<a>Click</a>"},
+		{"<img src=\"data:image/svg+xml,<svg onload=alert(1)>\" alt=\"x\">", "<img alt=\"x\" />"},
+		{"<a href=\"/relative?a=1&amp;b=2\" title='Say \"hi\"'>Link</a>", "<a href=\"/relative?a=1&amp;b=2\" title=\"Say &#34;hi&#34;\">Link</a>"},
+		{"<a href=\"https://example.com/a:b\">Link</a>", "<a href=\"https://example.com/a:b\">Link</a>"},
+		{"<a href=\"page:1\">Link</a>", "<a>Link</a>"},
+		{"<iframe src=\"https://evil.example\"><p>inside</p></iframe>after", "after"},
+		{"<svg><g>Text</g></svg>", "Text"},
+		{"<!-- comment --><p>Kept</p><!DOCTYPE html>", "<p>Kept</p>"},
+		{"<div><p>Unclosed", "<div><p>Unclosed</p></div>"},
+		{"</div></p>Stray", "Stray"},
+		{"<ul><li>One<li>Two</ul>", "<ul><li>One<li>Two</li></li></ul>"},
+		{"1 < 2 and 3 > 2", "1 &lt; 2 and 3 &gt; 2"},
+		{"<a href=\"x", "&lt;a href=\"x"},
+		{"<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)</code></pre>", "<pre><code class=\"language-go\">fmt.Println(\"&lt;hi&gt;\")</code></pre>"},
+		{"<p>Line<br />next<hr></p>", "<p>Line<br />next<hr /></p>"},
+	}
+
+	for _, table := range tables {
+		if actual := Entry.Sanitize(table.input); actual != table.output {
+			t.Errorf("Expected Sanitize(%q) to produce %q, got %q", table.input, table.output, actual)
+		}
+	}
+}
+
+func TestPolicy_Sanitize_Comment(t *testing.T) {
+	input := `<h1>Big</h1><p>Nice <a href="https://example.com" rel="me" target="_blank">post</a> <img src="https://example.com/a.png"></p>`
+	expected := `Big<p>Nice <a href="https://example.com" rel="nofollow ugc noopener">post</a> </p>`
+	if actual := Comment.Sanitize(input); actual != expected {
+		t.Errorf("Expected comment to keep simple formatting and mark links, got %q", actual)
+	}
+}
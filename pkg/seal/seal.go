@@ -0,0 +1,88 @@
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Prefix Marks a sealed value, so values written before sealing began can be told apart and read as they are
+const Prefix = "sealed:v1:"
+
+// SaltSize Length of the random salt each key is derived with
+const SaltSize = 16
+
+// ErrCorrupt A sealed value that could not be opened, as it was sealed with another key or has been changed
+var ErrCorrupt = errors.New("Sealed value could not be opened")
+
+// Sealer Encrypts values with AES-256-GCM under a key derived from a passphrase with scrypt
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// New Derive the key for a passphrase and salt
+func New(passphrase string, salt []byte) (*Sealer, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// NewSalt Generate a random salt to derive a new key with
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	_, err := rand.Read(salt)
+
+	return salt, err
+}
+
+// IsSealed Whether a value has been sealed
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Seal Encrypt a value under a fresh nonce, leaving empty and already sealed values as they are
+func (s *Sealer) Seal(value string) string {
+	if value == "" || IsSealed(value) {
+		return value
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+
+	return Prefix + base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, []byte(value), nil))
+}
+
+// Open Decrypt a sealed value, returning any other value as it is
+func (s *Sealer) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", ErrCorrupt
+	}
+	size := s.aead.NonceSize()
+	plain, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", ErrCorrupt
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,59 @@
+package seal
+
+import (
+	"strings"
+	"testing"
+)
+
+func sealer(t *testing.T, passphrase string, salt []byte) *Sealer {
+	s, err := New(passphrase, salt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return s
+}
+
+func TestSealer(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil || len(salt) != SaltSize {
+		t.Fatalf("Expected a salt to be generated, got %v", err)
+	}
+	s := sealer(t, "correct horse", salt)
+
+	sealed := s.Seal("Dear diary")
+	if !IsSealed(sealed) || strings.Contains(sealed, "diary") {
+		t.Errorf("Expected value to be sealed, got %s", sealed)
+	}
+	if again := s.Seal("Dear diary"); again == sealed {
+		t.Error("Expected each value to be sealed under a fresh nonce")
+	}
+	if s.Seal(sealed) != sealed || s.Seal("") != "" {
+		t.Error("Expected sealed and empty values to be left alone")
+	}
+	if opened, err := s.Open(sealed); err != nil || opened != "Dear diary" {
+		t.Errorf("Expected value to be opened again, got %s and %v", opened, err)
+	}
+	if opened, err := s.Open("Written before sealing"); err != nil || opened != "Written before sealing" {
+		t.Error("Expected value that was never sealed to be read as it is")
+	}
+}
+
+func TestSealer_Open(t *testing.T) {
+	salt, _ := NewSalt()
+	sealed := sealer(t, "correct horse", salt).Seal("Dear diary")
+
+	if _, err := sealer(t, "wrong horse", salt).Open(sealed); err != ErrCorrupt {
+		t.Errorf("Expected another passphrase not to open the value, got %v", err)
+	}
+	other, _ := NewSalt()
+	if _, err := sealer(t, "correct horse", other).Open(sealed); err != ErrCorrupt {
+		t.Errorf("Expected another salt not to open the value, got %v", err)
+	}
+	s := sealer(t, "correct horse", salt)
+	for _, corrupt := range []string{Prefix + "not base64!", Prefix + "c2hvcnQ=", sealed[:len(sealed)-4] + "AAAA"} {
+		if _, err := s.Open(corrupt); err != ErrCorrupt {
+			t.Errorf("Expected %s to be refused, got %v", corrupt, err)
+		}
+	}
+}
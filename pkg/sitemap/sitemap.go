@@ -0,0 +1,51 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// ContentType The content type a sitemap is served as
+const ContentType = "application/xml; charset=utf-8"
+
+// MaxURLs Most pages a single sitemap may list
+const MaxURLs = 50000
+
+// URL A page listed in the sitemap, with when it last changed
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name  `xml:"urlset"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	URLs    []urlItem `xml:"url"`
+}
+
+type urlItem struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Render Write the pages as a sitemap, keeping to the most a single sitemap may list
+func Render(urls []URL) ([]byte, error) {
+	doc := urlSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: []urlItem{}}
+	for n, u := range urls {
+		if n >= MaxURLs {
+			break
+		}
+		item := urlItem{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			item.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		doc.URLs = append(doc.URLs, item)
+	}
+
+	output, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
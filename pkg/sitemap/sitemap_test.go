@@ -0,0 +1,39 @@
+package sitemap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	output, err := Render([]URL{
+		{Loc: "https://example.com/", LastMod: time.Date(2018, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{Loc: "https://example.com/fish?a=1&b=2"},
+	})
+	if err != nil {
+		t.Fatalf("Expected sitemap to render, got %s", err)
+	}
+	rendered := string(output)
+	expected := []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		`<loc>https://example.com/</loc>`,
+		`<lastmod>2018-03-01T12:30:00Z</lastmod>`,
+		`<url>
+    <loc>https://example.com/fish?a=1&amp;b=2</loc>
+  </url>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(rendered, e) {
+			t.Errorf("Expected sitemap to contain %s, got:\n%s", e, rendered)
+		}
+	}
+
+	// Test the pages are kept to the most allowed
+	urls := make([]URL, MaxURLs+1)
+	output, _ = Render(urls)
+	if strings.Count(string(output), "<url>") != MaxURLs {
+		t.Error("Expected sitemap to be kept to the most pages allowed")
+	}
+}
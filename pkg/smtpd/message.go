@@ -0,0 +1,126 @@
+package smtpd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+)
+
+// maxParts Most parts read from a message, however deeply they are nested
+const maxParts = 100
+
+// File A file attached to a message
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Message The parts of an email that matter once it has arrived
+type Message struct {
+	From        string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []File
+}
+
+// ParseMessage Read the sender, subject, body and attachments from a raw message, preferring the first plain text and
+// HTML bodies found and treating any other part with a name or marked as an attachment as a file
+func ParseMessage(data []byte) (Message, error) {
+	raw, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return Message{}, err
+	}
+
+	decoder := mime.WordDecoder{}
+	m := Message{}
+	if from, err := mail.ParseAddress(raw.Header.Get("From")); err == nil {
+		m.From = strings.ToLower(from.Address)
+	}
+	m.Subject = raw.Header.Get("Subject")
+	if subject, err := decoder.DecodeHeader(m.Subject); err == nil {
+		m.Subject = subject
+	}
+	m.Subject = strings.TrimSpace(m.Subject)
+
+	parts := 0
+	err = m.readPart(raw.Header, raw.Body, &parts)
+
+	return m, err
+}
+
+func (m *Message) readPart(header map[string][]string, body io.Reader, parts *int) error {
+	*parts++
+	if *parts > maxParts {
+		return nil
+	}
+	get := func(key string) string {
+		if values := header[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := m.readPart(part.Header, part, parts); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := ioutil.ReadAll(decode(body, get("Content-Transfer-Encoding")))
+	if err != nil {
+		return err
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(get("Content-Disposition"))
+	name := dispositionParams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	if decoded, err := (&mime.WordDecoder{}).DecodeHeader(name); err == nil {
+		name = decoded
+	}
+
+	switch {
+	case disposition != "attachment" && name == "" && mediaType == "text/plain" && m.Text == "":
+		m.Text = strings.TrimSpace(string(data))
+	case disposition != "attachment" && name == "" && mediaType == "text/html" && m.HTML == "":
+		m.HTML = strings.TrimSpace(string(data))
+	case disposition == "attachment" || name != "":
+		m.Attachments = append(m.Attachments, File{Name: name, ContentType: mediaType, Data: data})
+	}
+
+	return nil
+}
+
+// decode Undo the transfer encoding of a part
+func decode(body io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+
+	return body
+}
@@ -0,0 +1,66 @@
+package smtpd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	message := strings.Join([]string{
+		"From: Jamie <Jamie@Example.com>",
+		"Subject: =?UTF-8?Q?Caf=C3=A9_trip?=",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Had a *coffee* at the caf=C3=A9.",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>Had a <em>coffee</em></p>",
+		"--inner--",
+		"--outer",
+		`Content-Type: image/png; name="cup.png"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		"aGVs",
+		"bG8=",
+		"--outer",
+		"Content-Type: application/pdf",
+		`Content-Disposition: attachment; filename="menu.pdf"`,
+		"",
+		"%PDF",
+		"--outer--",
+		"",
+	}, "\r\n")
+
+	m, err := ParseMessage([]byte(message))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if m.From != "jamie@example.com" || m.Subject != "Café trip" {
+		t.Errorf("Expected sender and decoded subject, got %s and %s", m.From, m.Subject)
+	}
+	if m.Text != "Had a *coffee* at the café." || m.HTML != "<p>Had a <em>coffee</em></p>" {
+		t.Errorf("Expected text and HTML bodies, got %q and %q", m.Text, m.HTML)
+	}
+	if len(m.Attachments) != 2 || m.Attachments[0].Name != "cup.png" || string(m.Attachments[0].Data) != "hello" || m.Attachments[1].Name != "menu.pdf" || m.Attachments[1].ContentType != "application/pdf" {
+		t.Errorf("Expected two attachments, got %v", m.Attachments)
+	}
+
+	// Test plain message
+	m, err = ParseMessage([]byte("From: me@example.com\r\nSubject: Note\r\n\r\nJust text\r\n"))
+	if err != nil || m.Text != "Just text" || len(m.Attachments) != 0 {
+		t.Errorf("Expected plain body, got %v, %v", m, err)
+	}
+
+	if _, err := ParseMessage([]byte("not a message")); err == nil {
+		t.Error("Expected error for an invalid message")
+	}
+}
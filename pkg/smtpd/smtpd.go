@@ -0,0 +1,257 @@
+package smtpd
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the largest message, in bytes, accepted when no other limit is set
+const DefaultMaxSize = 25 << 20
+
+// Timeout How long a client may stay silent before it is disconnected
+const Timeout = 5 * time.Minute
+
+// maxRecipients Most recipients accepted for a single message
+const maxRecipients = 100
+
+// ErrServerClosed is returned by Serve once the server has been closed
+var ErrServerClosed = errors.New("smtpd: Server closed")
+
+// deferred An error refusing a message for now, which the client should keep and deliver again later
+type deferred struct {
+	error
+}
+
+// Defer Wrap an error refusing a message for now, so the client tries again later rather than returning it to the sender
+func Defer(err error) error {
+	return deferred{err}
+}
+
+// Envelope A message received, with the sender and recipients the client gave for it
+type Envelope struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Server Receives messages over SMTP and passes each one to a handler, which may refuse it by returning an error, or
+// defer it by returning one wrapped with Defer
+type Server struct {
+	Hostname string
+	MaxSize  int64
+	Handler  func(Envelope) error
+
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+// ListenAndServe Listen on a TCP address and serve clients connecting to it
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve Accept clients from a listener until the server is closed
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close Stop accepting clients, leaving those connected to finish
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+
+	return nil
+}
+
+func (s *Server) hostname() string {
+	if s.Hostname != "" {
+		return s.Hostname
+	}
+
+	return "localhost"
+}
+
+func (s *Server) maxSize() int64 {
+	if s.MaxSize > 0 {
+		return s.MaxSize
+	}
+
+	return DefaultMaxSize
+}
+
+// serveConn Hold a conversation with one client, accepting any number of messages until it quits
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	reply := func(code int, message string) {
+		conn.SetWriteDeadline(time.Now().Add(Timeout))
+		text.PrintfLine("%d %s", code, message)
+	}
+
+	reply(220, s.hostname()+" ESMTP Journal")
+	var from string
+	var to []string
+	greeted := false
+	for {
+		conn.SetReadDeadline(time.Now().Add(Timeout))
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			verb, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			greeted = true
+			from, to = "", nil
+			reply(250, s.hostname())
+		case "EHLO":
+			greeted = true
+			from, to = "", nil
+			text.PrintfLine("250-%s", s.hostname())
+			text.PrintfLine("250-SIZE %d", s.maxSize())
+			reply(250, "8BITMIME")
+		case "MAIL":
+			address, ok := parsePath(arg, "FROM:")
+			switch {
+			case !greeted:
+				reply(503, "Send HELO or EHLO first")
+			case from != "":
+				reply(503, "Sender already given")
+			case !ok:
+				reply(501, "Syntax: MAIL FROM:<address>")
+			default:
+				from = address
+				if from == "" {
+					// The null sender, used for bounces, is kept distinct from no sender at all
+					from = "<>"
+				}
+				reply(250, "OK")
+			}
+		case "RCPT":
+			address, ok := parsePath(arg, "TO:")
+			switch {
+			case from == "":
+				reply(503, "Send MAIL first")
+			case !ok || address == "":
+				reply(501, "Syntax: RCPT TO:<address>")
+			case len(to) >= maxRecipients:
+				reply(452, "Too many recipients")
+			default:
+				to = append(to, address)
+				reply(250, "OK")
+			}
+		case "DATA":
+			if len(to) == 0 {
+				reply(503, "Send RCPT first")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+			conn.SetReadDeadline(time.Now().Add(Timeout))
+			dot := text.DotReader()
+			data, err := ioutil.ReadAll(io.LimitReader(dot, s.maxSize()+1))
+			if err != nil {
+				return
+			}
+			sender := from
+			if sender == "<>" {
+				sender = ""
+			}
+			envelope := Envelope{From: sender, To: to, Data: data}
+			from, to = "", nil
+			if int64(len(data)) > s.maxSize() {
+				// Read what is left so the conversation can carry on after the refusal
+				if _, err := io.Copy(ioutil.Discard, dot); err != nil {
+					return
+				}
+				reply(552, "Message is larger than "+strconv.FormatInt(s.maxSize(), 10)+" bytes")
+				continue
+			}
+			if s.Handler != nil {
+				if err := s.Handler(envelope); err != nil {
+					if _, ok := err.(deferred); ok {
+						reply(451, "Try again later: "+err.Error())
+						continue
+					}
+					reply(550, "Message refused: "+err.Error())
+					continue
+				}
+			}
+			reply(250, "OK")
+		case "RSET":
+			from, to = "", nil
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "VRFY":
+			reply(252, "Cannot verify the user")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// parsePath Read the address from a MAIL or RCPT argument, such as FROM:<someone@example.com> SIZE=100
+func parsePath(arg string, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	path := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(path, "<") {
+		return "", false
+	}
+	end := strings.IndexByte(path, '>')
+	if end < 0 {
+		return "", false
+	}
+
+	return path[1:end], true
+}
@@ -0,0 +1,122 @@
+package smtpd
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func startServer(t *testing.T, server *Server) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	go server.Serve(listener)
+
+	return listener.Addr().String()
+}
+
+func TestServer(t *testing.T) {
+	received := []Envelope{}
+	server := &Server{Hostname: "journal.example.com", MaxSize: 1024, Handler: func(e Envelope) error {
+		if strings.Contains(string(e.Data), "spam") {
+			return errors.New("Not wanted")
+		}
+		if strings.Contains(string(e.Data), "later") {
+			return Defer(errors.New("Busy"))
+		}
+		received = append(received, e)
+		return nil
+	}}
+	addr := startServer(t, server)
+	defer server.Close()
+
+	// Test message delivered
+	message := "From: me@example.com\r\nSubject: Hello\r\n\r\nHello there\r\n.leading dot\r\n"
+	if err := smtp.SendMail(addr, nil, "me@example.com", []string{"journal@example.com"}, []byte(message)); err != nil {
+		t.Fatalf("Expected message to be sent, got %s", err)
+	}
+	if len(received) != 1 || received[0].From != "me@example.com" || received[0].To[0] != "journal@example.com" || string(received[0].Data) != "From: me@example.com\nSubject: Hello\n\nHello there\n.leading dot\n" {
+		t.Errorf("Expected message to be received, got %v", received)
+	}
+
+	// Test message refused by the handler
+	if err := smtp.SendMail(addr, nil, "me@example.com", []string{"journal@example.com"}, []byte("Subject: spam\r\n\r\nspam\r\n")); err == nil || !strings.Contains(err.Error(), "Not wanted") {
+		t.Errorf("Expected message to be refused, got %v", err)
+	}
+
+	// Test message deferred by the handler
+	if err := smtp.SendMail(addr, nil, "me@example.com", []string{"journal@example.com"}, []byte("Subject: later\r\n\r\nlater\r\n")); err == nil || !strings.HasPrefix(err.Error(), "451") || !strings.Contains(err.Error(), "Busy") {
+		t.Errorf("Expected message to be deferred, got %v", err)
+	}
+
+	// Test message too large
+	if err := smtp.SendMail(addr, nil, "me@example.com", []string{"journal@example.com"}, []byte(strings.Repeat("a", 2048))); err == nil || !strings.HasPrefix(err.Error(), "552") {
+		t.Errorf("Expected large message to be refused, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Error("Expected refused messages not to be received")
+	}
+}
+
+func TestServer_Commands(t *testing.T) {
+	server := &Server{}
+	addr := startServer(t, server)
+	defer server.Close()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	send := func(command string) string {
+		if command != "" {
+			conn.Write([]byte(command + "\r\n"))
+		}
+		line, _ := reader.ReadString('\n')
+		return line
+	}
+
+	tables := []struct {
+		command string
+		reply   string
+	}{
+		{"", "220 localhost"},
+		{"MAIL FROM:<me@example.com>", "503"},
+		{"HELO client", "250 localhost"},
+		{"RCPT TO:<journal@example.com>", "503"},
+		{"MAIL FROM:me@example.com", "501"},
+		{"MAIL FROM:<me@example.com> SIZE=10", "250"},
+		{"MAIL FROM:<me@example.com>", "503"},
+		{"DATA", "503"},
+		{"RCPT TO:<>", "501"},
+		{"RSET", "250"},
+		{"NOOP", "250"},
+		{"TURN", "502"},
+		{"QUIT", "221"},
+	}
+	for _, table := range tables {
+		if reply := send(table.command); !strings.HasPrefix(reply, table.reply) {
+			t.Errorf("Expected %s to be answered with %s, got %s", table.command, table.reply, reply)
+		}
+	}
+}
+
+func TestServer_Close(t *testing.T) {
+	server := &Server{}
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	done := make(chan error)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+	server.Close()
+	if err := <-done; err != ErrServerClosed {
+		t.Errorf("Expected server to stop once closed, got %v", err)
+	}
+	if err := server.ListenAndServe("127.0.0.1:0"); err != ErrServerClosed {
+		t.Errorf("Expected closed server not to serve again, got %v", err)
+	}
+}
@@ -3,19 +3,31 @@ package controller
 import (
 	"net/http"
 	"strings"
+
+	"github.com/jamiefdhurst/journal/pkg/controller"
 )
 
 // MockController Mock the controller interface
 type MockController struct {
-	HasRun bool
+	Container interface{}
+	HasRun    bool
+	Request   *http.Request
+}
+
+// Clone Share the mock between requests, so that tests can see what it was given
+func (m *MockController) Clone() controller.Controller {
+	return m
 }
 
 // Init Mock the init method
-func (m *MockController) Init(app interface{}, params []string) {}
+func (m *MockController) Init(app interface{}, params []string) {
+	m.Container = app
+}
 
 // Run Mock the run method
 func (m *MockController) Run(response http.ResponseWriter, request *http.Request) {
 	m.HasRun = true
+	m.Request = request
 }
 
 // MockResponse Mock for http.ResponseWriter
@@ -0,0 +1,65 @@
+package database
+
+// MockAttachment_MultipleRows Mock two files attached to a Journal
+type MockAttachment_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+	File      string
+}
+
+// Next Mock 2 rows
+func (m *MockAttachment_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockAttachment_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*int) = 1
+	*dest[3].(*string) = m.File
+	*dest[6].(*string) = "2018-02-02 10:00:00"
+	if m.RowNumber == 1 {
+		*dest[2].(*string) = "Report <1>.pdf"
+		*dest[4].(*string) = "application/pdf"
+		*dest[5].(*int) = 2 << 20
+	} else if m.RowNumber == 2 {
+		*dest[2].(*string) = "notes.txt"
+		*dest[4].(*string) = "text/plain; charset=utf-8"
+		*dest[5].(*int) = 300
+	}
+	return nil
+}
+
+// MockAttachment_SingleRow Mock a single file attached to a Journal
+type MockAttachment_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	File      string
+}
+
+// Next Mock 1 row
+func (m *MockAttachment_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockAttachment_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "Report <1>.pdf"
+		*dest[3].(*string) = m.File
+		*dest[4].(*string) = "application/pdf"
+		*dest[5].(*int) = 15
+		*dest[6].(*string) = "2018-02-02 10:00:00"
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package database
+
+// MockCategory_MultipleRows Mock three categories, ordered by name, with Europe nested under Travel
+type MockCategory_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 3 rows
+func (m *MockCategory_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 4 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockCategory_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 3
+		*dest[1].(*string) = "cooking"
+		*dest[2].(*string) = "Cooking"
+		*dest[3].(*int) = 0
+	} else if m.RowNumber == 2 {
+		*dest[0].(*int) = 2
+		*dest[1].(*string) = "europe"
+		*dest[2].(*string) = "Europe"
+		*dest[3].(*int) = 1
+	} else if m.RowNumber == 3 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "travel"
+		*dest[2].(*string) = "Travel"
+		*dest[3].(*int) = 0
+	}
+	return nil
+}
+
+// MockCategory_SingleRow Mock a single category
+type MockCategory_SingleRow struct {
+	MockRowsEmpty
+	ParentID  int
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockCategory_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockCategory_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "travel"
+		*dest[2].(*string) = "Travel"
+		*dest[3].(*int) = m.ParentID
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package database
+
+// MockComment_MultipleRows Mock two comments returned for a Journal
+type MockComment_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockComment_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockComment_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*int) = 1
+	*dest[6].(*string) = "approved"
+	*dest[10].(*string) = "slug"
+	*dest[11].(*string) = "Title"
+	if m.RowNumber == 1 {
+		*dest[2].(*string) = "Reader"
+		*dest[4].(*string) = "https://reader.example.com"
+		*dest[5].(*string) = "Great post"
+		*dest[9].(*string) = "2018-02-02 10:00:00"
+	} else if m.RowNumber == 2 {
+		*dest[2].(*string) = "<b>Another</b>"
+		*dest[5].(*string) = "Thanks\n<script>alert(1)</script>"
+		*dest[9].(*string) = "2018-02-03 10:00:00"
+	}
+	return nil
+}
+
+// MockComment_SingleRow Mock a single comment awaiting moderation
+type MockComment_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	Status    string
+}
+
+// Next Mock 1 row
+func (m *MockComment_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockComment_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 4
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "Reader"
+		*dest[3].(*string) = "reader@example.com"
+		*dest[5].(*string) = "Great post"
+		*dest[6].(*string) = "pending"
+		if m.Status != "" {
+			*dest[6].(*string) = m.Status
+		}
+		*dest[7].(*string) = "127.0.0.1"
+		*dest[9].(*string) = "2018-02-02 10:00:00"
+		*dest[10].(*string) = "slug"
+		*dest[11].(*string) = "Title"
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/jamiefdhurst/journal/pkg/database/rows"
 )
@@ -99,7 +100,20 @@ func (m *MockJournal_MultipleRows) Scan(dest ...interface{}) error {
 // MockJournal_SingleRow Mock single row returned for a Journal
 type MockJournal_SingleRow struct {
 	MockRowsEmpty
-	RowNumber int
+	AuthorID     int
+	CategoryID   int
+	Comments     string
+	Content      string
+	DeletedAt    string
+	Excerpt      string
+	PasswordHash string
+	Pinned       bool
+	PublishAt    string
+	ReadingTime  int
+	RowNumber    int
+	Status       string
+	Visibility   string
+	WordCount    int
 }
 
 // Next Mock 1 row
@@ -119,6 +133,43 @@ func (m *MockJournal_SingleRow) Scan(dest ...interface{}) error {
 		*dest[2].(*string) = "Title"
 		*dest[3].(*string) = "2018-02-01"
 		*dest[4].(*string) = "Content"
+		if m.Content != "" {
+			*dest[4].(*string) = m.Content
+		}
+		if m.Status != "" && len(dest) > 5 {
+			*dest[5].(*string) = m.Status
+		}
+		if m.DeletedAt != "" && len(dest) > 6 {
+			*dest[6].(*string) = m.DeletedAt
+		}
+		if m.Comments != "" && len(dest) > 7 {
+			*dest[7].(*string) = m.Comments
+		}
+		if m.PublishAt != "" && len(dest) > 8 {
+			*dest[8].(*string) = m.PublishAt
+		}
+		if m.CategoryID != 0 && len(dest) > 9 {
+			*dest[9].(*int) = m.CategoryID
+		}
+		if m.Excerpt != "" && len(dest) > 10 {
+			*dest[10].(*string) = m.Excerpt
+		}
+		if m.Pinned && len(dest) > 11 {
+			*dest[11].(*bool) = m.Pinned
+		}
+		if m.Visibility != "" && len(dest) > 12 {
+			*dest[12].(*string) = m.Visibility
+		}
+		if m.PasswordHash != "" && len(dest) > 13 {
+			*dest[13].(*string) = m.PasswordHash
+		}
+		if m.WordCount != 0 && len(dest) > 15 {
+			*dest[14].(*int) = m.WordCount
+			*dest[15].(*int) = m.ReadingTime
+		}
+		if m.AuthorID != 0 && len(dest) > 16 {
+			*dest[16].(*int) = m.AuthorID
+		}
 	}
 	return nil
 }
@@ -148,7 +199,9 @@ func (m *MockPagination_Result) Scan(dest ...interface{}) error {
 }
 
 // MockResult Mock the result for a saved Journal
-type MockResult struct{}
+type MockResult struct {
+	Affected int64
+}
 
 // LastInsertId Mock the last inserted ID
 func (m *MockResult) LastInsertId() (int64, error) {
@@ -157,7 +210,7 @@ func (m *MockResult) LastInsertId() (int64, error) {
 
 // RowsAffected Mock the rows affected
 func (m *MockResult) RowsAffected() (int64, error) {
-	return 0, nil
+	return m.Affected, nil
 }
 
 // MockRowsEmpty An empty row set
@@ -248,7 +301,7 @@ func (m *MockSqlite) Query(sql string, args ...interface{}) (rows.Rows, error) {
 
 func (m *MockSqlite) inArgs(slice []interface{}) bool {
 	for _, v := range slice {
-		if v.(string) == m.ExpectedArgument {
+		if fmt.Sprint(v) == m.ExpectedArgument {
 			return true
 		}
 	}
@@ -265,3 +318,29 @@ func (m *MockSqlite) popResult() rows.Rows {
 
 	return result
 }
+
+// MockJournalContent_MultipleRows Mock the ID and content of two entries waiting for their words to be counted
+type MockJournalContent_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJournalContent_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJournalContent_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	if m.RowNumber == 1 {
+		*dest[1].(*string) = "Some *counted* words"
+	} else if m.RowNumber == 2 {
+		*dest[1].(*string) = "---"
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package database
+
+// MockEncryption_SingleRow Mock the salt and verifier kept once encryption has been turned on
+type MockEncryption_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	Salt      string
+	Verifier  string
+}
+
+// Next Mock 1 row
+func (m *MockEncryption_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockEncryption_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*string) = m.Salt
+		*dest[1].(*string) = m.Verifier
+	}
+	return nil
+}
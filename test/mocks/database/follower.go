@@ -0,0 +1,63 @@
+package database
+
+// MockFollower_MultipleRows Mock three followers, the first two sharing an inbox
+type MockFollower_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 3 rows
+func (m *MockFollower_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 4
+}
+
+// Scan Return the data
+func (m *MockFollower_MultipleRows) Scan(dest ...interface{}) error {
+	inbox := "https://social.example.com/inbox"
+	if m.RowNumber == 3 {
+		inbox = "https://other.example.com/users/reader/inbox"
+	}
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*string) = "https://social.example.com/users/reader" + string(rune('0'+m.RowNumber))
+	*dest[2].(*string) = inbox
+	*dest[3].(*string) = "2018-02-01T00:00:00Z"
+	return nil
+}
+
+// MockActorKey_SingleRow Mock the stored private key of the journal's actor
+type MockActorKey_SingleRow struct {
+	MockRowsEmpty
+	PrivateKey string
+	RowNumber  int
+}
+
+// Next Mock 1 row
+func (m *MockActorKey_SingleRow) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 2
+}
+
+// Scan Return the data
+func (m *MockActorKey_SingleRow) Scan(dest ...interface{}) error {
+	*dest[0].(*string) = m.PrivateKey
+	return nil
+}
+
+// MockFederatedEntry_SingleRow Mock an entry already sent to followers
+type MockFederatedEntry_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockFederatedEntry_SingleRow) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 2
+}
+
+// Scan Return the data
+func (m *MockFederatedEntry_SingleRow) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = 1
+	return nil
+}
@@ -0,0 +1,47 @@
+package database
+
+// MockIntegrity_Messages Mock the messages reported by SQLite when checking the database
+type MockIntegrity_Messages struct {
+	MockRowsEmpty
+	Messages  []string
+	RowNumber int
+}
+
+// Next Mock a row for each message
+func (m *MockIntegrity_Messages) Next() bool {
+	m.RowNumber++
+	return m.RowNumber <= len(m.Messages)
+}
+
+// Scan Return the message
+func (m *MockIntegrity_Messages) Scan(dest ...interface{}) error {
+	if m.RowNumber > 0 && m.RowNumber <= len(m.Messages) {
+		*dest[0].(*string) = m.Messages[m.RowNumber-1]
+	}
+	return nil
+}
+
+// MockIntegrity_Entries Mock the ID, slug and date of three entries, the second sharing the slug of the first and
+// with a date that cannot be read
+type MockIntegrity_Entries struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 3 rows
+func (m *MockIntegrity_Entries) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 4
+}
+
+// Scan Return the data
+func (m *MockIntegrity_Entries) Scan(dest ...interface{}) error {
+	entries := [][]string{{"slug", "2018-02-01"}, {"slug", "01/02/2018"}, {"other", "2018-02-03T00:00:00Z"}}
+	if m.RowNumber < 1 || m.RowNumber > len(entries) {
+		return nil
+	}
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*string) = entries[m.RowNumber-1][0]
+	*dest[2].(*string) = entries[m.RowNumber-1][1]
+	return nil
+}
@@ -0,0 +1,75 @@
+package database
+
+// MockJob_SingleRow Mock single row returned for a Job
+type MockJob_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	Type      string
+}
+
+// Next Mock 1 row
+func (m *MockJob_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJob_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		jobType := m.Type
+		if jobType == "" {
+			jobType = "test"
+		}
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = jobType
+		*dest[2].(*string) = "{\"name\":\"example\"}"
+		*dest[3].(*string) = "pending"
+		*dest[4].(*int) = 0
+		*dest[5].(*string) = ""
+		*dest[6].(*string) = "2018-02-01T00:00:00Z"
+		*dest[7].(*string) = "2018-02-01T00:00:00Z"
+	}
+	return nil
+}
+
+// MockJob_MultipleRows Mock multiple rows returned for a Job
+type MockJob_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJob_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJob_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "test"
+		*dest[2].(*string) = "{}"
+		*dest[3].(*string) = "done"
+		*dest[4].(*int) = 1
+		*dest[5].(*string) = ""
+		*dest[6].(*string) = "2018-02-01T00:00:00Z"
+		*dest[7].(*string) = "2018-02-01T00:00:00Z"
+	} else if m.RowNumber == 2 {
+		*dest[0].(*int) = 2
+		*dest[1].(*string) = "export"
+		*dest[2].(*string) = "{}"
+		*dest[3].(*string) = "failed"
+		*dest[4].(*int) = 3
+		*dest[5].(*string) = "Simulated failure"
+		*dest[6].(*string) = "2018-03-01T00:00:00Z"
+		*dest[7].(*string) = "2018-03-01T00:00:00Z"
+	}
+	return nil
+}
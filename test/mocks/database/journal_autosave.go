@@ -0,0 +1,30 @@
+package database
+
+// MockJournalAutosave_SingleRow Mock work in progress returned for a Journal
+type MockJournalAutosave_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockJournalAutosave_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJournalAutosave_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 2
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "Unsaved Title"
+		*dest[3].(*string) = "2018-02-01"
+		*dest[4].(*string) = "Unsaved content"
+		*dest[5].(*string) = ""
+		*dest[6].(*string) = "2018-02-02 10:00:00"
+	}
+	return nil
+}
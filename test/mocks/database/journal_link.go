@@ -0,0 +1,30 @@
+package database
+
+// MockJournalLink_MultipleRows Mock a canonical and a syndication link returned for a Journal
+type MockJournalLink_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJournalLink_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJournalLink_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*int) = 1
+	if m.RowNumber == 1 {
+		*dest[2].(*string) = "canonical"
+		*dest[3].(*string) = "https://example.com/original"
+	} else if m.RowNumber == 2 {
+		*dest[2].(*string) = "syndication"
+		*dest[3].(*string) = "https://mastodon.example/@jamie/1"
+	}
+	return nil
+}
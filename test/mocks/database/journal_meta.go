@@ -0,0 +1,32 @@
+package database
+
+// MockJournalMeta_MultipleRows Mock the mood and weather custom fields returned for a Journal
+type MockJournalMeta_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJournalMeta_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data, a name and value or only the value when a single field is asked for
+func (m *MockJournalMeta_MultipleRows) Scan(dest ...interface{}) error {
+	fields := [][]string{{"mood", "Happy"}, {"weather", "Sunny"}}
+	if m.RowNumber < 1 || m.RowNumber > len(fields) {
+		return nil
+	}
+	field := fields[m.RowNumber-1]
+	if len(dest) == 1 {
+		*dest[0].(*string) = field[1]
+		return nil
+	}
+	*dest[0].(*string) = field[0]
+	*dest[1].(*string) = field[1]
+	return nil
+}
@@ -0,0 +1,86 @@
+package database
+
+// MockJournalRevision_MultipleRows Mock two earlier versions returned for a Journal
+type MockJournalRevision_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJournalRevision_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data, newest first
+func (m *MockJournalRevision_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = 3 - m.RowNumber
+	*dest[1].(*int) = 1
+	if m.RowNumber == 1 {
+		*dest[2].(*string) = "Title"
+		*dest[3].(*string) = "2018-02-01"
+		*dest[4].(*string) = "Content\nSecond draft"
+		*dest[5].(*string) = "2018-02-03 10:00:00"
+	} else if m.RowNumber == 2 {
+		*dest[2].(*string) = "First Title"
+		*dest[3].(*string) = "2018-02-01"
+		*dest[4].(*string) = "First draft"
+		*dest[5].(*string) = "2018-02-02 10:00:00"
+	}
+	return nil
+}
+
+// MockJournalRevision_SingleRow Mock a single earlier version returned for a Journal
+type MockJournalRevision_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockJournalRevision_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJournalRevision_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 2
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "Old Title"
+		*dest[3].(*string) = "2018-02-01"
+		*dest[4].(*string) = "Old content\n<script>alert(1)</script>"
+		*dest[5].(*string) = "2018-02-02 10:00:00"
+	}
+	return nil
+}
+
+// MockJournalRevision_LastModified Mock when the latest revision of some entries was kept
+type MockJournalRevision_LastModified struct {
+	MockRowsEmpty
+	CreatedAt string
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockJournalRevision_LastModified) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockJournalRevision_LastModified) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*string) = m.CreatedAt
+	}
+	return nil
+}
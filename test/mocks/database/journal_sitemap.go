@@ -0,0 +1,27 @@
+package database
+
+// MockSitemap_MultipleRows Mock two published entries for the sitemap, the newest changed since it was written
+type MockSitemap_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockSitemap_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 3
+}
+
+// Scan Return the data, newest first
+func (m *MockSitemap_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*string) = "slug-2"
+		*dest[1].(*string) = "2018-03-01"
+		*dest[2].(*string) = "2018-03-04 10:00:00"
+	} else {
+		*dest[0].(*string) = "slug"
+		*dest[1].(*string) = "2018-02-01"
+		*dest[2].(*string) = ""
+	}
+	return nil
+}
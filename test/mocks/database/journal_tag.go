@@ -0,0 +1,62 @@
+package database
+
+// MockJournalTag_MultipleRows Mock the road trip and travel tags returned for a Journal
+type MockJournalTag_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockJournalTag_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data, a name and slug along with how many entries are given the tag when asked for
+func (m *MockJournalTag_MultipleRows) Scan(dest ...interface{}) error {
+	tags := []struct {
+		name  string
+		slug  string
+		count int
+	}{{"Road Trip", "road-trip", 3}, {"Travel", "travel", 1}}
+	if m.RowNumber < 1 || m.RowNumber > len(tags) {
+		return nil
+	}
+	tag := tags[m.RowNumber-1]
+	*dest[0].(*string) = tag.name
+	*dest[1].(*string) = tag.slug
+	if len(dest) > 2 {
+		*dest[2].(*int) = tag.count
+	}
+	return nil
+}
+
+// MockJournalTagged_MultipleRows Mock the tags of two entries fetched at once, the first given road trip and travel
+// and the second travel
+type MockJournalTagged_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 3 rows
+func (m *MockJournalTagged_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 4
+}
+
+// Scan Return the data, the ID of the entry and the name of its tag
+func (m *MockJournalTagged_MultipleRows) Scan(dest ...interface{}) error {
+	tags := []struct {
+		journalID int
+		name      string
+	}{{1, "Road Trip"}, {1, "Travel"}, {2, "Travel"}}
+	if m.RowNumber < 1 || m.RowNumber > len(tags) {
+		return nil
+	}
+	*dest[0].(*int) = tags[m.RowNumber-1].journalID
+	*dest[1].(*string) = tags[m.RowNumber-1].name
+	return nil
+}
@@ -0,0 +1,72 @@
+package database
+
+// MockScheduledTask_SingleRow Mock single row returned for a ScheduledTask
+type MockScheduledTask_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	Name      string
+	LastRunAt string
+	NextRunAt string
+}
+
+// Next Mock 1 row
+func (m *MockScheduledTask_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockScheduledTask_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		name := m.Name
+		if name == "" {
+			name = "backup"
+		}
+		nextRunAt := m.NextRunAt
+		if nextRunAt == "" {
+			nextRunAt = "2018-02-01 00:00:00"
+		}
+		*dest[0].(*string) = name
+		*dest[1].(*int) = 3600
+		*dest[2].(*string) = m.LastRunAt
+		*dest[3].(*string) = ""
+		*dest[4].(*string) = nextRunAt
+	}
+	return nil
+}
+
+// MockScheduledTask_MultipleRows Mock multiple rows returned for a ScheduledTask
+type MockScheduledTask_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockScheduledTask_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockScheduledTask_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*string) = "publish"
+		*dest[1].(*int) = 60
+		*dest[2].(*string) = "2018-02-01 00:00:00"
+		*dest[3].(*string) = ""
+		*dest[4].(*string) = "2018-02-01 00:01:00"
+	} else if m.RowNumber == 2 {
+		*dest[0].(*string) = "backup"
+		*dest[1].(*int) = 86400
+		*dest[2].(*string) = "2018-01-31 00:00:00"
+		*dest[3].(*string) = "disk full"
+		*dest[4].(*string) = "2018-02-01 00:00:00"
+	}
+	return nil
+}
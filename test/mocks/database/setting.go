@@ -0,0 +1,25 @@
+package database
+
+// MockSetting_MultipleRows Mock the settings saved in the database
+type MockSetting_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockSetting_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockSetting_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*string) = "title"
+		*dest[1].(*string) = "Alice's Journal"
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+// MockShortcode_MultipleRows Mock two custom shortcodes, ordered by name
+type MockShortcode_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockShortcode_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockShortcode_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 2
+		*dest[1].(*string) = "company"
+		*dest[2].(*string) = "Acme & Sons"
+	} else if m.RowNumber == 2 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "shipit"
+		*dest[2].(*string) = "🐿️"
+	}
+	return nil
+}
+
+// MockShortcode_SingleRow Mock a single custom shortcode
+type MockShortcode_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockShortcode_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockShortcode_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "shipit"
+		*dest[2].(*string) = "🐿️"
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import "strings"
+
+// MockStatsTotals_Row Mock the total entries and words
+type MockStatsTotals_Row struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockStatsTotals_Row) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 2
+}
+
+// Scan Return the data
+func (m *MockStatsTotals_Row) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = 4
+	*dest[1].(*int) = 1000
+	return nil
+}
+
+// MockStatsCount_Rows Mock grouped counts for a report
+type MockStatsCount_Rows struct {
+	MockRowsEmpty
+	Labels    []string
+	RowNumber int
+}
+
+// Next Mock a row per label
+func (m *MockStatsCount_Rows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber <= len(m.Labels)
+}
+
+// Scan Return the data, with each bucket busier than the last and, for tags, the slug of its label
+func (m *MockStatsCount_Rows) Scan(dest ...interface{}) error {
+	*dest[0].(*string) = m.Labels[m.RowNumber-1]
+	*dest[1].(*int) = m.RowNumber
+	*dest[2].(*int) = m.RowNumber * 100
+	if len(dest) > 3 {
+		*dest[3].(*string) = strings.ToLower(strings.ReplaceAll(m.Labels[m.RowNumber-1], " ", "-"))
+	}
+	return nil
+}
+
+// MockStatsDays_Rows Mock the distinct days with entries
+type MockStatsDays_Rows struct {
+	MockRowsEmpty
+	Days      []string
+	RowNumber int
+}
+
+// Next Mock a row per day
+func (m *MockStatsDays_Rows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber <= len(m.Days)
+}
+
+// Scan Return the data
+func (m *MockStatsDays_Rows) Scan(dest ...interface{}) error {
+	*dest[0].(*string) = m.Days[m.RowNumber-1]
+	return nil
+}
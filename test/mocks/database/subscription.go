@@ -0,0 +1,97 @@
+package database
+
+// MockSubscription_MultipleRows Mock two subscriptions, the second not fetched yet so without a title or site
+type MockSubscription_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockSubscription_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 3
+}
+
+// Scan Return the data
+func (m *MockSubscription_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	if m.RowNumber == 1 {
+		*dest[1].(*string) = "https://alice.example.com/feed"
+		*dest[2].(*string) = "Alice's Blog"
+		*dest[3].(*string) = "https://alice.example.com/"
+		*dest[4].(*string) = "2018-02-01 10:00:00"
+		*dest[5].(*string) = ""
+	} else {
+		*dest[1].(*string) = "https://bob.example.com/atom.xml"
+		*dest[2].(*string) = ""
+		*dest[3].(*string) = ""
+		*dest[4].(*string) = ""
+		*dest[5].(*string) = ""
+	}
+	*dest[6].(*string) = "2018-02-01 09:00:00"
+	return nil
+}
+
+// MockSubscription_SingleRow Mock a single subscription to the given feed
+type MockSubscription_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	URL       string
+}
+
+// Next Mock 1 row
+func (m *MockSubscription_SingleRow) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 2
+}
+
+// Scan Return the data
+func (m *MockSubscription_SingleRow) Scan(dest ...interface{}) error {
+	url := m.URL
+	if url == "" {
+		url = "https://alice.example.com/feed"
+	}
+	*dest[0].(*int) = 1
+	*dest[1].(*string) = url
+	*dest[2].(*string) = "Alice's Blog"
+	*dest[3].(*string) = "https://alice.example.com/"
+	*dest[4].(*string) = "2018-02-01 10:00:00"
+	*dest[5].(*string) = ""
+	*dest[6].(*string) = "2018-02-01 09:00:00"
+	return nil
+}
+
+// MockSubscriptionItem_MultipleRows Mock two items fetched from followed feeds, newest first
+type MockSubscriptionItem_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockSubscriptionItem_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 3
+}
+
+// Scan Return the data
+func (m *MockSubscriptionItem_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*int) = m.RowNumber
+	if m.RowNumber == 1 {
+		*dest[2].(*string) = "Alice's Post"
+		*dest[3].(*string) = "https://alice.example.com/post"
+		*dest[5].(*string) = "2018-02-15 12:00:00"
+		*dest[6].(*string) = "Alice's Blog"
+		*dest[7].(*string) = "https://alice.example.com/"
+		*dest[8].(*string) = "https://alice.example.com/feed"
+	} else {
+		*dest[2].(*string) = "Bob's Post"
+		*dest[3].(*string) = "https://bob.example.com/post"
+		*dest[5].(*string) = "2018-01-15 12:00:00"
+		*dest[6].(*string) = ""
+		*dest[7].(*string) = ""
+		*dest[8].(*string) = "https://bob.example.com/atom.xml"
+	}
+	*dest[4].(*string) = "A summary"
+	return nil
+}
@@ -0,0 +1,27 @@
+package database
+
+// MockTenant_SingleRow Mock single row returned for a Tenant
+type MockTenant_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockTenant_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockTenant_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "alice"
+		*dest[2].(*string) = "Alice's Journal"
+		*dest[3].(*string) = "2018-02-01T00:00:00Z"
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package database
+
+// MockUser_SingleRow Mock single row returned for a User
+type MockUser_SingleRow struct {
+	MockRowsEmpty
+	PasswordHash string
+	Role         string
+	RowNumber    int
+}
+
+// Next Mock 1 row
+func (m *MockUser_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockUser_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		role := m.Role
+		if role == "" {
+			role = "admin"
+		}
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "jamie"
+		*dest[2].(*string) = "jamie@example.com"
+		*dest[3].(*string) = m.PasswordHash
+		*dest[4].(*string) = role
+		*dest[5].(*string) = "2018-02-01T00:00:00Z"
+	}
+	return nil
+}
+
+// MockUser_MultipleRows Mock multiple rows returned for a User
+type MockUser_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockUser_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockUser_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "jamie"
+		*dest[2].(*string) = "jamie@example.com"
+		*dest[3].(*string) = "hash"
+		*dest[4].(*string) = "admin"
+		*dest[5].(*string) = "2018-02-01T00:00:00Z"
+	} else if m.RowNumber == 2 {
+		*dest[0].(*int) = 2
+		*dest[1].(*string) = "friend"
+		*dest[2].(*string) = "friend@example.com"
+		*dest[3].(*string) = "hash"
+		*dest[4].(*string) = "editor"
+		*dest[5].(*string) = "2018-03-01T00:00:00Z"
+	}
+	return nil
+}
+
+// MockToken_SingleRow Mock single row returned for a Token
+type MockToken_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	Scopes    string
+}
+
+// Next Mock 1 row
+func (m *MockToken_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockToken_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		scopes := m.Scopes
+		if scopes == "" {
+			scopes = "read,write"
+		}
+		*dest[0].(*int) = 1
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "Script"
+		*dest[3].(*string) = "hash"
+		*dest[4].(*string) = scopes
+		*dest[5].(*string) = ""
+		*dest[6].(*string) = "2018-02-01T00:00:00Z"
+	}
+	return nil
+}
+
+// MockSession_SingleRow Mock single row returned for a Session
+type MockSession_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockSession_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockSession_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "hash"
+		*dest[3].(*string) = "2099-01-01 00:00:00"
+		*dest[4].(*string) = "2018-02-01 00:00:00"
+	}
+	return nil
+}
+
+// MockIdentity_SingleRow Mock single row returned for an Identity
+type MockIdentity_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 1 row
+func (m *MockIdentity_SingleRow) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 2 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockIdentity_SingleRow) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 1
+		*dest[1].(*int) = 1
+		*dest[2].(*string) = "google"
+		*dest[3].(*string) = "248289761001"
+		*dest[4].(*string) = "2018-02-01 00:00:00"
+	}
+	return nil
+}
+
+// MockSecurityEvent_MultipleRows Mock a failed sign in followed by the lockout it caused
+type MockSecurityEvent_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockSecurityEvent_MultipleRows) Next() bool {
+	m.RowNumber++
+	if m.RowNumber < 3 {
+		return true
+	}
+	return false
+}
+
+// Scan Return the data
+func (m *MockSecurityEvent_MultipleRows) Scan(dest ...interface{}) error {
+	if m.RowNumber == 1 {
+		*dest[0].(*int) = 2
+		*dest[1].(*string) = "lockout"
+		*dest[2].(*int) = 1
+		*dest[3].(*string) = "jamie"
+		*dest[4].(*string) = "192.0.2.1"
+		*dest[5].(*string) = "account"
+		*dest[6].(*string) = "2018-02-01 00:00:01"
+	} else if m.RowNumber == 2 {
+		*dest[0].(*int) = 1
+		*dest[1].(*string) = "login_failed"
+		*dest[2].(*int) = 0
+		*dest[3].(*string) = "<nobody>"
+		*dest[4].(*string) = "192.0.2.1"
+		*dest[5].(*string) = ""
+		*dest[6].(*string) = "2018-02-01 00:00:00"
+	}
+	return nil
+}
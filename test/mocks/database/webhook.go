@@ -0,0 +1,81 @@
+package database
+
+// MockWebhook_MultipleRows Mock two webhooks, the first told about every event and the second only about publishing
+type MockWebhook_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockWebhook_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 3
+}
+
+// Scan Return the data
+func (m *MockWebhook_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = m.RowNumber
+	*dest[1].(*string) = "https://hooks.example.com/" + string(rune('0'+m.RowNumber))
+	*dest[2].(*string) = "secret"
+	*dest[3].(*string) = ""
+	if m.RowNumber == 2 {
+		*dest[3].(*string) = "entry.published"
+	}
+	*dest[4].(*string) = "2018-02-01T00:00:00Z"
+	return nil
+}
+
+// MockWebhook_SingleRow Mock a single webhook, sending to the given URL
+type MockWebhook_SingleRow struct {
+	MockRowsEmpty
+	RowNumber int
+	URL       string
+}
+
+// Next Mock 1 row
+func (m *MockWebhook_SingleRow) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 2
+}
+
+// Scan Return the data
+func (m *MockWebhook_SingleRow) Scan(dest ...interface{}) error {
+	url := m.URL
+	if url == "" {
+		url = "https://hooks.example.com/1"
+	}
+	*dest[0].(*int) = 1
+	*dest[1].(*string) = url
+	*dest[2].(*string) = "secret"
+	*dest[3].(*string) = ""
+	*dest[4].(*string) = "2018-02-01T00:00:00Z"
+	return nil
+}
+
+// MockWebhookDelivery_MultipleRows Mock two deliveries to a webhook, the latest refused
+type MockWebhookDelivery_MultipleRows struct {
+	MockRowsEmpty
+	RowNumber int
+}
+
+// Next Mock 2 rows
+func (m *MockWebhookDelivery_MultipleRows) Next() bool {
+	m.RowNumber++
+	return m.RowNumber < 3
+}
+
+// Scan Return the data
+func (m *MockWebhookDelivery_MultipleRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = 3 - m.RowNumber
+	*dest[1].(*int) = 1
+	*dest[2].(*string) = "entry.created"
+	*dest[3].(*string) = "{}"
+	*dest[4].(*int) = 200
+	*dest[5].(*string) = ""
+	if m.RowNumber == 1 {
+		*dest[4].(*int) = 500
+	}
+	*dest[6].(*int) = 1
+	*dest[7].(*string) = "2018-02-01T00:00:00Z"
+	return nil
+}
@@ -1,3 +1,103 @@
-var medium = require('medium-editor');
+// Entries are written in Markdown, so the content field is left as a plain textarea
 
-new medium('textarea')
+// Work in progress on the new and edit forms is saved every so often, and offered back
+// when the form is next opened if it was never saved
+(function () {
+    var form = document.querySelector('form[data-autosave]');
+    if (!form || !window.fetch) {
+        return;
+    }
+    var url = form.getAttribute('data-autosave');
+    var fields = ['title', 'date', 'content', 'excerpt'];
+
+    var current = function () {
+        var values = {};
+        fields.forEach(function (name) {
+            values[name] = form.elements[name] ? form.elements[name].value : '';
+        });
+        return values;
+    };
+    var last = JSON.stringify(current());
+
+    var save = function () {
+        var values = current();
+        var body = JSON.stringify(values);
+        if (body === last || (!values.title.trim() && !values.content.trim())) {
+            return;
+        }
+        fetch(url, {method: 'POST', headers: {'Content-Type': 'application/json'}, body: body, credentials: 'same-origin'})
+            .then(function (response) {
+                if (response.ok) {
+                    last = body;
+                }
+            })
+            .catch(function () {});
+    };
+
+    var button = function (label, action) {
+        var b = document.createElement('button');
+        b.type = 'button';
+        b.className = 'button-outline';
+        b.textContent = label;
+        b.addEventListener('click', action);
+        return b;
+    };
+
+    var offer = function (autosave) {
+        var notice = document.createElement('div');
+        notice.className = 'draft autosave-notice';
+        notice.appendChild(document.createTextNode('There is unsaved work on this entry from ' + autosave.saved_at + ' UTC. '));
+        notice.appendChild(button('Restore it', function () {
+            fields.forEach(function (name) {
+                if (form.elements[name]) {
+                    form.elements[name].value = autosave[name] || '';
+                }
+            });
+            last = JSON.stringify(current());
+            notice.parentNode.removeChild(notice);
+        }));
+        notice.appendChild(button('Discard it', function () {
+            fetch(url, {method: 'DELETE', credentials: 'same-origin'}).catch(function () {});
+            notice.parentNode.removeChild(notice);
+        }));
+        form.parentNode.insertBefore(notice, form);
+    };
+
+    fetch(url, {credentials: 'same-origin'})
+        .then(function (response) {
+            return response.ok ? response.json() : null;
+        })
+        .then(function (autosave) {
+            var values = current();
+            if (autosave && fields.some(function (name) { return (autosave[name] || '') !== values[name]; })) {
+                offer(autosave);
+            }
+        })
+        .catch(function () {});
+
+    var timer = setInterval(save, 30000);
+    form.addEventListener('submit', function () {
+        clearInterval(timer);
+    });
+})();
+
+// Lists managed in bulk have a checkbox selecting every entry on the page at once
+(function () {
+    Array.prototype.forEach.call(document.querySelectorAll('input[data-select-all]'), function (all) {
+        var boxes = all.form.querySelectorAll('input[type=checkbox][name="' + all.getAttribute('data-select-all') + '"]');
+        all.addEventListener('change', function () {
+            Array.prototype.forEach.call(boxes, function (box) {
+                box.checked = all.checked;
+            });
+        });
+    });
+})();
+
+// Read only fields holding something to copy, such as the Markdown for an uploaded image, select it all when clicked
+(function () {
+    Array.prototype.forEach.call(document.querySelectorAll('input[data-select-on-click]'), function (input) {
+        input.addEventListener('click', function () {
+            input.select();
+        });
+    });
+})();
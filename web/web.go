@@ -0,0 +1,94 @@
+package web
+
+import "embed"
+
+// Files Templates of pages beneath templates/, the stylesheets and scripts they load beneath static/ and the catalogs
+// of their messages in each language beneath locales/, built into the binary so that the journal runs from any directory
+//
+//go:embed templates/*.tmpl templates/_layout templates/_partial templates/admin static locales
+var Files embed.FS
+
+// Templates Templates beneath templates/ that pages are rendered with, each theme being rendered with any of its own
+// and the ones built in for those it leaves out
+var Templates = []string{
+	"_layout/default.tmpl",
+	"_partial/form.tmpl",
+	"admin/blogroll.tmpl",
+	"admin/categories.tmpl",
+	"admin/comments.tmpl",
+	"admin/entries.tmpl",
+	"admin/jobs.tmpl",
+	"admin/maintenance.tmpl",
+	"admin/security.tmpl",
+	"admin/settings.tmpl",
+	"admin/shortcodes.tmpl",
+	"admin/stats.tmpl",
+	"admin/users.tmpl",
+	"attachments.tmpl",
+	"author.tmpl",
+	"category.tmpl",
+	"drafts.tmpl",
+	"duplicate.tmpl",
+	"edit.tmpl",
+	"error.tmpl",
+	"forbidden.tmpl",
+	"history.tmpl",
+	"index.tmpl",
+	"login.tmpl",
+	"media.tmpl",
+	"new.tmpl",
+	"reading.tmpl",
+	"register.tmpl",
+	"revision.tmpl",
+	"search.tmpl",
+	"servererror.tmpl",
+	"setup.tmpl",
+	"tag.tmpl",
+	"tokens.tmpl",
+	"trash.tmpl",
+	"unauthorised.tmpl",
+	"unavailable.tmpl",
+	"unlock.tmpl",
+	"view.tmpl",
+}
+
+// Pages Templates each page is rendered with, parsed together as the journal starts
+var Pages = [][]string{
+	{"_layout/default.tmpl", "admin/blogroll.tmpl"},
+	{"_layout/default.tmpl", "admin/categories.tmpl"},
+	{"_layout/default.tmpl", "admin/comments.tmpl"},
+	{"_layout/default.tmpl", "admin/entries.tmpl"},
+	{"_layout/default.tmpl", "admin/jobs.tmpl"},
+	{"_layout/default.tmpl", "admin/maintenance.tmpl"},
+	{"_layout/default.tmpl", "admin/security.tmpl"},
+	{"_layout/default.tmpl", "admin/settings.tmpl"},
+	{"_layout/default.tmpl", "admin/shortcodes.tmpl"},
+	{"_layout/default.tmpl", "admin/stats.tmpl"},
+	{"_layout/default.tmpl", "admin/users.tmpl"},
+	{"_layout/default.tmpl", "attachments.tmpl"},
+	{"_layout/default.tmpl", "author.tmpl"},
+	{"_layout/default.tmpl", "category.tmpl"},
+	{"_layout/default.tmpl", "drafts.tmpl"},
+	{"_layout/default.tmpl", "duplicate.tmpl"},
+	{"_layout/default.tmpl", "edit.tmpl", "_partial/form.tmpl"},
+	{"_layout/default.tmpl", "error.tmpl"},
+	{"_layout/default.tmpl", "forbidden.tmpl"},
+	{"_layout/default.tmpl", "history.tmpl"},
+	{"_layout/default.tmpl", "index.tmpl"},
+	{"_layout/default.tmpl", "login.tmpl"},
+	{"_layout/default.tmpl", "media.tmpl"},
+	{"_layout/default.tmpl", "new.tmpl", "_partial/form.tmpl"},
+	{"_layout/default.tmpl", "reading.tmpl"},
+	{"_layout/default.tmpl", "register.tmpl"},
+	{"_layout/default.tmpl", "revision.tmpl"},
+	{"_layout/default.tmpl", "search.tmpl"},
+	{"_layout/default.tmpl", "servererror.tmpl"},
+	{"_layout/default.tmpl", "setup.tmpl"},
+	{"_layout/default.tmpl", "tag.tmpl"},
+	{"_layout/default.tmpl", "tokens.tmpl"},
+	{"_layout/default.tmpl", "trash.tmpl"},
+	{"_layout/default.tmpl", "unauthorised.tmpl"},
+	{"_layout/default.tmpl", "unavailable.tmpl"},
+	{"_layout/default.tmpl", "unlock.tmpl"},
+	{"_layout/default.tmpl", "view.tmpl"},
+}